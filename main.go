@@ -1,17 +1,31 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/awion/MercuriesOST/public/assets/datasets"
+	"github.com/awion/MercuriesOST/public/input"
 	"github.com/awion/MercuriesOST/public/osint"
+	"github.com/awion/MercuriesOST/public/providers"
 	"github.com/fatih/color"
+	"golang.org/x/term"
 )
 
 // Version information
@@ -20,353 +34,4173 @@ const (
 	AppVersion = "0.1.2"
 )
 
-// Command line flags
+// globalOptions are accepted by every command, before or after its name
+type globalOptions struct {
+	record        string
+	replay        string
+	traceHeader   string
+	maxBodySize   int64
+	authorizedBy  string
+	acceptTerms   bool
+	policyURL     string
+	touchCanaries bool
+	caseName      string
+	summary       string
+	report        string
+	noHooks       bool
+	noSuppress    bool
+	format        string
+	quiet         bool
+	requestLog    string
+	proxy         string
+	maxRequests   int64
+	maxBandwidth  int64
+	tor           bool
+	torIsolate    bool
+	timeout       time.Duration
+	retries       int
+	retryBackoff  time.Duration
+	dryRun        bool
+}
+
 var (
+	global = globalOptions{
+		maxBodySize:  osint.MaxBodySize,
+		policyURL:    osint.PolicyURL,
+		format:       osint.FormatTable,
+		timeout:      osint.RequestTimeout,
+		retries:      providers.MaxRetries,
+		retryBackoff: providers.RetryBackoff,
+	}
 	versionFlag = flag.Bool("version", false, "Display version information")
-	verboseFlag = flag.Bool("verbose", false, "Enable verbose output")
-	outputFlag  = flag.String("output", "", "Output file path")
-	username    = flag.String("u", "", "Username to search")
-	outputDir   = flag.String("o", "results", "Output directory for results")
-
-	// Direct module flags
-	socialMediaFlag = flag.String("social-media", "", "Search social media profiles for a username/name")
-	domainFlag      = flag.String("domain", "", "Domain intelligence lookup")
-	emailFlag       = flag.String("email", "", "Email intelligence lookup")
-	ipFlag          = flag.String("ip", "", "IP address intelligence lookup")
-	usernameFlag    = flag.String("username", "", "Username intelligence lookup")
-	gidFlag         = flag.String("gid", "", "Google ID intelligence lookup")
-	phoneFlag       = flag.String("phone", "", "Phone number intelligence lookup") // Add this line
 )
 
-func main() {
-	// Parse command line flags
-	flag.Parse()
+// Module options, registered on the flag sets of the commands that take them
+var (
+	verboseFlag = new(bool)
 
-	// Display banner
-	displayBanner()
+	// Export options
+	syslogFlag            = new(string)
+	syslogFormatFlag      = new(string)
+	theHiveFlag           = new(string)
+	theHiveURLFlag        = new(string)
+	openCTIURLFlag        = new(string)
+	openCTIConfidenceFlag = new(int)
+	geoFlag               = new(string)
 
-	// Handle version flag
-	if *versionFlag {
-		fmt.Printf("%s version %s\n", AppName, AppVersion)
-		os.Exit(0)
-	}
+	// Social media options
+	expandHandlesFlag  = new(bool)
+	socialGraphFlag    = new(bool)
+	graphSampleFlag    = new(int)
+	accountHistoryFlag = new(bool)
+	tzPhoneFlag        = new(string)
+	tzIPFlag           = new(string)
+	minConfidenceFlag  = new(float64)
+	geocodeFlag        = new(bool)
 
-	// Handle phone number lookup
-	if *phoneFlag != "" {
-		fmt.Printf("Running Phone Number Intelligence module for number: %s\n", *phoneFlag)
-		runPhoneNumberIntelligence(*phoneFlag, *outputFlag)
-		return
-	}
+	// Domain options
+	probePathsFlag = new(bool)
+	wordlistFlag   = new(string)
+	scanSourceFlag = new(bool)
+	pivotIDsFlag   = new(bool)
+	followFlag     = new(bool)
+	spoofFlag      = new(string)
+	takeoverFlag   = new(bool)
+)
 
-	// Handle Google ID lookup
-	if *gidFlag != "" {
-		fmt.Printf("Running Google ID Intelligence module for ID: %s\n", *gidFlag)
-		runGoogleIDIntelligence(*gidFlag, *outputFlag)
-		return
-	}
+// Command options. Commands register the ones they take, with their own
+// usage, so commands sharing an option's name share its variable.
+var (
+	keywordsFlag         = new(string)
+	keywordsFileFlag     = new(string)
+	platformsFlag        = new(string)
+	excludePlatformsFlag = new(string)
 
-	// Handle username-based search
-	if *username != "" {
-		// Create output directory if it doesn't exist
-		if _, err := os.Stat(*outputDir); os.IsNotExist(err) {
-			os.MkdirAll(*outputDir, 0755)
-		}
+	outputFlag         = new(string)
+	resumeFlag         = new(string)
+	dirFlag            = new(string)
+	urlFlag            = new(string)
+	fileFlag           = new(string)
+	submitFlag         = new(bool)
+	visibilityFlag     = new(string)
+	artifactsFlag      = new(string)
+	countFlag          = new(int)
+	typeFlag           = new(string)
+	valueFlag          = new(string)
+	candidatesFlag     = new(string)
+	minLinkFlag        = new(float64)
+	gravatarFlag       = new(bool)
+	queryFlag          = new(string)
+	benchPlatformsFlag = new(int)
+	latencyFlag        = new(time.Duration)
+	hitRateFlag        = new(float64)
+	feedFlag           = new(string)
+	feedFormatFlag     = new(string)
+	onceFlag           = new(bool)
+	listFlag           = new(bool)
+	investigatorFlag   = new(string)
+	notesFlag          = new(string)
+	intoFlag           = new(string)
+	limitFlag          = new(int)
+	tagFlag            = new(string)
+	untagFlag          = new(string)
+	noteFlag           = new(string)
+	severityFlag       = new(string)
+	authorFlag         = new(string)
+	globalFlag         = new(bool)
+	removeFlag         = new(bool)
+	alertFlag          = new(bool)
+	reasonFlag         = new(string)
+	titleFlag          = new(string)
+	pdfFlag            = new(bool)
+	noAvatarsFlag      = new(bool)
+	addrFlag           = new(string)
+	tokensFlag         = new(string)
+	auditFlag          = new(string)
+	scopesFlag         = new(string)
+	quotaFlag          = new(int)
+	rateFlag           = new(int)
+	jobDirFlag         = new(string)
+)
 
-		// Generate output filename
-		outputFile := filepath.Join(*outputDir, fmt.Sprintf("%s_%s.json",
-			*username,
-			time.Now().Format("20060102_150405")))
+// maxContactPivots caps how many discovered contacts --follow-contacts analyzes
+const maxContactPivots = 5
 
-		// Run sequential scan
-		fmt.Printf("Starting Mercuries scan for username: %s\n", *username)
-		results, err := osint.SearchProfilesSequentially(*username, outputFile, *verboseFlag)
+// command is a subcommand and its help
+type command struct {
+	name    string
+	args    string // What follows the name in its usage line
+	summary string
+	run     func(args []string)
+	flags   func(fs *flag.FlagSet) // Registers its own options, if it has any
+}
 
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
-		}
+// commands are listed by help in this order. The table is filled in init,
+// since commands look their own usage up in it.
+var commands []command
 
-		fmt.Printf("\nScan complete! Found %d profiles across %d platforms.\n",
-			results.ProfilesFound,
-			len(results.Profiles))
-		return
+func init() {
+	commands = []command{
+		{"social", "[options] <name or username>", "Search social media platforms for profiles of a person or handle", runSocialMediaCommand, addSocialMediaCommandFlags},
+		{"scan", "[options] <username>", "Scan every platform for a username and save the profiles found to a results directory", runUsernameScan, addUsernameScanFlags},
+		{"all", "[options] <email or name>", "Run the email, social, Google ID and phone modules from one seed and merge them into one report", runAllCommand, addAllCommandFlags},
+		{"email", "[options] <email|->", "Email intelligence: validation, breaches, linked accounts and reputation", runEmailCommand, addEmailCommandFlags},
+		{"phone", "[options] <number|->", "Phone number intelligence: carrier, region, online presence and risk", runPhoneCommand, addPhoneCommandFlags},
+		{"gid", "[options] <google-id|->", "Google ID intelligence: Maps reviews, photos and archived profiles", runGoogleIDCommand, addGoogleIDCommandFlags},
+		{"domain", "[options] <domain|->", "Domain intelligence: DNS, WHOIS, certificates, technologies and contacts", runDomainCommand, addDomainCommandFlags},
+		{"ip", "[options] <ip|->", "IP intelligence: reverse DNS, location, exposed services and reputation", runIPCommand, addIPCommandFlags},
+		{"account-id", "[options] <facebook|twitter|reddit> <id>", "Find the account behind a platform-native ID", runAccountIDCommand, addAccountIDCommandFlags},
+		{"email-compare", "[options] <email> <email>", "Score the signals two email addresses share", runEmailCompare, addEmailCompareFlags},
+		{"cluster", "[options] <file|->", "Group a list of emails, handles and phone numbers into probable identities", runAliasCluster, addAliasClusterFlags},
+		{"history", "[options] <github|reddit> <handle>", "Find deleted, renamed or suspended accounts behind a handle", runAccountHistory, addAccountHistoryFlags},
+		{"scheduling", "[options] <name or username>", "Find Calendly and cal.com booking pages for a person or handle, with the owner's name, timezone and meeting types", runSchedulingScan, addSchedulingScanFlags},
+		{"amazon", "[options] <name, username or list URL>", "Find public Amazon wishlists and storefronts, with their items and any shipping city shown", runAmazonScan, addAmazonScanFlags},
+		{"resolve", "[options] <profile-url>...", "Resolve vanity and alias profile URLs to account IDs", runResolveProfile, addResolveProfileFlags},
+		{"hash", "--value <hash> [options]", "Identify a hash and recover what it was computed from", runHashLookup, addHashLookupFlags},
+		{"header", "--file <message> [options]", "Trace an email's route and authentication from its headers", runHeaderAnalysis, addHeaderAnalysisFlags},
+		{"buckets", "[options] <organization, username or domain>", "Find S3, Google Cloud Storage and Azure buckets named after a target and list public ones", runBucketScan, addBucketScanFlags},
+		{"containers", "[options] <username or organization>", "List the public Docker Hub and GHCR images of a user or organization and the emails, internal hosts and credentials in their metadata", runContainerScan, addContainerScanFlags},
+		{"packages", "[options] <npm username>", "List the npm packages a developer maintains and their co-maintainers, linking the handles and emails in them into identities", runPackageScan, addPackageScanFlags},
+		{"repos", "[options] <github org or user>", "Search a GitHub organization's recent repositories for leaked credentials and list the addresses its contributors commit from", runRepoScan, addRepoScanFlags},
+		{"triage", "--url <link> [options]", "Check a suspicious link's redirects and reputation", runURLTriage, addURLTriageFlags},
+		{"expand", "[options] <url>...", "Show every redirect hop behind a link", runURLExpand, addURLExpandFlags},
+		{"decode-id", "[options] <id>...", "Decode the creation time embedded in snowflakes, ULIDs, UUIDs and similar IDs", runDecodeID, addDecodeIDFlags},
+		{"custom", "[options] <module> <target>", "Run a custom module defined in ~/.mercuries/modules, or list them with --list", runCustomModule, addCustomModuleFlags},
+		{"case", "[options] <create|open|close|list|merge> [name | dirA dirB]", "Group the results of every module run for an investigation under a named case, with its investigator and notes, or merge two investigators' cases", runCase, addCaseCommandFlags},
+		{"search", "[options] <case> <query>", "Search the text collected into a case for every word, a \"quoted phrase\", a prefix* or not a -word", runCaseSearch, addCaseSearchFlags},
+		{"annotate", "[options] <case> <finding-id>", "Tag a finding of a case, note on it or override its severity; case open, search and forwarded alerts show the annotations", runAnnotate, addAnnotateFlags},
+		{"suppress", "[options] [url|platform:username|finding-hash]", "Hide a known false positive from future runs, reports and alerts, for the open case or every case, or list the suppressions", runSuppress, addSuppressFlags},
+		{"report", "[options] [file or directory]...", "Render saved social, email, phone and Google ID results into one self-contained HTML report", runReport, addReportFlags},
+		{"watchlist", "[options] <action> ...", "Monitor brands, people and domains for impersonation", runWatchlist, addWatchlistFlags},
+		{"watch", "[options] <definition>", "Re-run a saved scan definition on its cron schedule and alert on new profiles, breaches and archives", runWatch, addWatchFlags},
+		{"serve", "[options]", "Serve watchlist findings feeds, and scans for API token holders, over HTTP", runServe, addServeFlags},
+		{"tokens", "[options] <list|add|revoke> [name]", "Manage the API tokens, scopes and quotas of mercuries serve", runTokens, addTokensFlags},
+		{"cortex", "[options]", "Run as a Cortex analyzer", runCortex, addCortexFlags},
+		{"secrets", "<list|set|delete> [name]", "Keep API keys in the OS keychain, or an encrypted file, instead of the config file", runSecrets, nil},
+		{"update-data", "[options] [dataset]...", updateDataSummary(), runUpdateData, addUpdateDataFlags},
+		{"bench", "[options]", "Benchmark the scanning engine against a local mock server", runBench, addBenchFlags},
+		{"completion", "<bash|zsh|fish>", "Print a shell completion script for the commands, their options and arguments", runCompletion, nil},
+		{"help", "[command]", "Show help for a command", runHelp, nil},
+	}
+}
+
+// lookupCommand returns the command with a name
+func lookupCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
 	}
+	return command{}, false
+}
+
+func main() {
+	// Every run is traced under its own ID, recorded in results and alerts
+	providers.ScanID = providers.NewScanID()
+
+	flag.Usage = usage
+	addGlobalFlags(flag.CommandLine)
+	flag.Parse()
 
-	// Handle email intelligence
-	if *emailFlag != "" {
-		fmt.Println("Running Email Intelligence module...")
-		runEmailIntelligence(*emailFlag, *outputFlag)
+	if *versionFlag {
+		fmt.Printf("%s version %s\n", AppName, AppVersion)
 		return
 	}
+	if flag.NArg() == 0 {
+		displayBanner()
+		usage()
+		os.Exit(1)
+	}
 
-	// Handle legacy module flags
-	switch {
-	case *socialMediaFlag != "":
-		fmt.Println("Running Social Media Intelligence module...")
-		runSocialMediaIntelligence(*socialMediaFlag, *outputFlag)
-	case *domainFlag != "":
-		fmt.Println("Domain intelligence module not implemented yet")
-	case *ipFlag != "":
-		fmt.Println("IP intelligence module not implemented yet")
-	case *usernameFlag != "":
-		fmt.Println("Username intelligence module not implemented yet")
-	default:
-		fmt.Println("Error: Please specify either -u flag or a module flag")
-		fmt.Println("Example: -u \"username\" or --social-media \"John Doe\"")
-		flag.Usage()
+	cmd, ok := lookupCommand(flag.Arg(0))
+	if !ok {
+		color.Red("Error: unknown command %q", flag.Arg(0))
+		fmt.Println("Run 'mercuries help' for the list of commands.")
 		os.Exit(1)
 	}
-}
 
-// displayBanner prints the application banner
-func displayBanner() {
-	banner := `
-  __  __                          _      
- |  \/  | ___ _ __ ___ _   _ _ __(_) ___  ___
- | |\/| |/ _ \ '__/ __| | | | '__| |/ _ \/ __|
- | |  | |  __/ | | (__| |_| | |  | |  __/\__ \
- |_|  |_|\___|_|  \___|\__,_|_|  |_|\___||___/
-                                                      
- Open Source Intelligence Tool - v` + AppVersion + `
- "Knowledge is power, information is liberty"
- MADE WITH LOVE BY github.com/awiones
-`
-	color.Cyan(banner)
-}
+	providers.Module = cmd.name
 
-// Update function signature to remove unused parameter
-func runSocialMediaIntelligence(query, outputPath string) {
-	fmt.Printf("Searching social media for: %s\n", query)
+	// API keys, rate limits and defaults come from ~/.mercuries.yaml
+	if err := osint.LoadConfig(); err != nil {
+		color.Red("Error loading config: %v", err)
+		os.Exit(1)
+	}
+	// Keys in the keychain fill in those the config file and environment leave unset
+	osint.SecretsPassphrase = promptSecretsPassphrase
+	if cmd.name == "secrets" {
+		// Manages the store itself
+	} else if err := osint.LoadSecrets(); err != nil {
+		color.Yellow("Warning: API keys in the secret store were not loaded: %v", err)
+	}
 
-	// Update function call to use verbose flag directly
-	results, err := osint.SearchProfilesSequentially(query, outputPath, *verboseFlag)
-	if err != nil {
-		color.Red("Error: %v", err)
+	// Cortex reads the analyzer report from stdout
+	if cmd.name == "cortex" {
+		cmd.run(flag.Args()[1:])
 		return
 	}
-
-	displaySocialResults(results)
-	fmt.Println("Social media intelligence gathering completed")
+	// Other commands show the banner once their options are parsed
+	if cmd.name == "help" {
+		displayBanner()
+	}
+	cmd.run(flag.Args()[1:])
+	osint.WaitHooks()
+	osint.DisplaySkippedCanaries()
+	displayBudget()
+	displayPlan()
+	if global.quiet && targetFailed {
+		os.Exit(1)
+	}
 }
 
-// displaySocialResults formats and displays the social media search results
-func displaySocialResults(results *osint.SocialMediaResults) {
-	color.Green("\n=== SEARCH RESULTS ===")
-	color.Yellow("Query: %s", results.Query)
-	color.Yellow("Timestamp: %s", results.Timestamp)
-	color.Yellow("Total Profiles Found: %d\n", results.ProfilesFound)
+// usage prints the commands and global options
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: mercuries [global options] <command> [options] <target>\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-14s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(out, "\nGlobal options, accepted before or after the command:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(out, "\nRun 'mercuries help <command>' for a command's options.\n")
+}
 
-	if results.ProfilesFound == 0 {
-		color.Red("\nNo profiles found. Searched platforms:")
-		for _, platform := range []string{"Twitter", "Instagram", "Facebook", "LinkedIn", "GitHub", "Reddit", "TikTok"} {
-			color.Red("  • %s - No profile found", platform)
-		}
+// runHelp prints the usage of a command, or of mercuries
+func runHelp(args []string) {
+	if len(args) == 0 {
+		usage()
 		return
 	}
-
-	// Group profiles by platform for better organization
-	platformProfiles := make(map[string][]osint.ProfileResult)
-	for _, profile := range results.Profiles {
-		platformProfiles[profile.Platform] = append(platformProfiles[profile.Platform], profile)
+	cmd, ok := lookupCommand(args[0])
+	switch {
+	case !ok:
+		color.Red("Error: unknown command %q", args[0])
+		os.Exit(1)
+	case cmd.name == "help":
+		usage()
+	default:
+		// Commands register their options before parsing, and -h prints them
+		cmd.run([]string{"-h"})
 	}
+}
 
-	// Display results for each platform
-	for platform, profiles := range platformProfiles {
-		color.Cyan("\n[%s]", platform)
-		for _, profile := range profiles {
-			color.Green("  Profile URL: %s", profile.URL)
-
-			if profile.FullName != "" {
-				color.White("  • Full Name: %s", profile.FullName)
-			}
-
-			if profile.Bio != "" {
-				color.White("  • Bio: %s", strings.TrimSpace(profile.Bio))
-			}
-
-			if profile.FollowerCount > 0 {
-				color.White("  • Followers: %d", profile.FollowerCount)
-			}
+// completionCommand is a command as a completion script offers it
+type completionCommand struct {
+	name    string
+	summary string
+	flags   []completionFlag
+	args    []string // Words offered for its first argument
+}
 
-			if profile.Location != "" {
-				color.White("  • Location: %s", profile.Location)
-			}
+// completionFlag is an option as a completion script offers it
+type completionFlag struct {
+	name    string
+	usage   string
+	value   bool     // Takes a value rather than being a switch
+	choices []string // Values offered, or none to complete file names
+}
 
-			if len(profile.RecentActivity) > 0 {
-				color.White("  • Recent Activity:")
-				for i, activity := range profile.RecentActivity[:min(3, len(profile.RecentActivity))] {
-					color.White("    %d. %s", i+1, activity)
-				}
-			}
+// usageChoices matches a usage argument that is one of a few literal words,
+// such as <list|add|revoke>
+var usageChoices = regexp.MustCompile(`<([a-z]+(?:\|[a-z]+)+)>`)
 
-			if len(profile.Insights) > 0 {
-				color.White("  • Insights:")
-				for _, insight := range profile.Insights {
-					color.White("    - %s", insight)
-				}
-			}
+// completionFlags lists the options of a flag set, skipping those in skip
+func completionFlags(fs *flag.FlagSet, skip map[string]bool) []completionFlag {
+	var flags []completionFlag
+	fs.VisitAll(func(f *flag.Flag) {
+		if skip[f.Name] {
+			return
+		}
+		boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+		flags = append(flags, completionFlag{
+			name:    f.Name,
+			usage:   f.Usage,
+			value:   !ok || !boolFlag.IsBoolFlag(),
+			choices: completionFlagChoices(f.Name),
+		})
+	})
+	return flags
+}
 
-			fmt.Println()
+// completionFlagChoices returns the values offered for an option taking one
+// of a few words
+func completionFlagChoices(name string) []string {
+	switch name {
+	case "format":
+		return []string{osint.FormatTable, osint.FormatJSON, osint.FormatCSV, osint.FormatYAML}
+	case "syslog-format":
+		return []string{osint.SyslogRFC5424, osint.SyslogCEF, osint.SyslogLEEF}
+	case "feed-format":
+		return []string{osint.FeedAtom, osint.FeedRSS}
+	case "visibility":
+		return []string{"public", "unlisted", "private"}
+	case "scopes":
+		return append(osint.ServeModuleNames(), osint.ScopeFeeds)
+	case "platforms", "exclude-platforms":
+		var names []string
+		for _, name := range osint.PlatformNames() {
+			names = append(names, strings.ToLower(name))
 		}
+		return names
 	}
+	return nil
+}
 
-	// Display summary
-	color.Green("\n=== PLATFORM SUMMARY ===")
-	for _, platform := range []string{"Twitter", "Instagram", "Facebook", "LinkedIn", "GitHub", "Reddit", "TikTok"} {
-		if profiles, exists := platformProfiles[platform]; exists {
-			color.Green("  ✓ %s: %d profile(s) found", platform, len(profiles))
-		} else {
-			color.Red("  ✗ %s: No profile found", platform)
+// completionArgs returns the words offered for a command's first argument:
+// the literal choices in its usage, or the names it looks up
+func completionArgs(cmd command) []string {
+	switch cmd.name {
+	case "help":
+		var names []string
+		for _, c := range commands {
+			names = append(names, c.name)
 		}
+		return names
+	case "completion":
+		return completionShells
+	case "watchlist":
+		return []string{"add", "remove", "list", "run", "feed"}
+	case "update-data":
+		return datasets.Names()
+	case "custom":
+		modules, _ := osint.LoadCustomModules()
+		var names []string
+		for _, module := range modules {
+			names = append(names, module.Name)
+		}
+		return names
+	}
+	if m := usageChoices.FindStringSubmatch(cmd.args); m != nil {
+		return strings.Split(m[1], "|")
 	}
+	return nil
 }
 
-// Helper function to get minimum of two integers
-func min(a, b int) int {
-	if a < b {
-		return a
+// completionCommands collects every command's options and arguments
+func completionCommands() []completionCommand {
+	globals := globalFlagNames()
+	var cmds []completionCommand
+	for _, cmd := range commands {
+		c := completionCommand{name: cmd.name, summary: cmd.summary, args: completionArgs(cmd)}
+		// help prints a usage rather than parsing options
+		if cmd.name != "help" {
+			c.flags = completionFlags(commandFlagSet(cmd.name), globals)
+		}
+		cmds = append(cmds, c)
 	}
-	return b
+	return cmds
 }
 
-func runEmailIntelligence(email, outputPath string) {
-	fmt.Printf("Analyzing email: %s\n", email)
+// completionShells are the shells the completion command writes scripts for
+var completionShells = []string{"bash", "zsh", "fish"}
 
-	results, err := osint.AnalyzeEmail(email)
-	if err != nil {
-		color.Red("Error analyzing email: %v", err)
-		return
+// runCompletion prints a shell completion script built from the commands'
+// own flag definitions
+func runCompletion(args []string) {
+	fs := commandFlagSet("completion")
+	parseFlags(fs, args)
+
+	shell := fs.Arg(0)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
 	}
+	globalSet := flag.NewFlagSet("", flag.ContinueOnError)
+	addGlobalFlags(globalSet)
+	globals := completionFlags(globalSet, nil)
+	cmds := completionCommands()
 
-	// Display results using the new method
-	results.DisplayResults()
+	switch shell {
+	case "bash":
+		fmt.Print(bashCompletion(globals, cmds))
+	case "zsh":
+		// zsh runs the bash script through its bash compatibility layer
+		fmt.Print("autoload -U +X compinit && compinit\nautoload -U +X bashcompinit && bashcompinit\n\n" + bashCompletion(globals, cmds))
+	case "fish":
+		fmt.Print(fishCompletion(globals, cmds))
+	default:
+		color.Red("Error: unknown shell %q, use %s", shell, strings.Join(completionShells, ", "))
+		os.Exit(1)
+	}
+}
 
-	// Save to file if output path is specified
-	if outputPath != "" {
-		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
-			if err := os.WriteFile(outputPath, data, 0644); err == nil {
-				color.Green("\nResults saved to: %s", outputPath)
-			} else {
-				color.Red("Error saving results: %v", err)
-			}
-		} else {
-			color.Red("Error encoding results: %v", err)
+// bashChoiceCases writes case branches offering the values of the options
+// that take one of a few words, once for each option name
+func bashChoiceCases(b *strings.Builder, globals []completionFlag, cmds []completionCommand) {
+	seen := make(map[string]bool)
+	flags := globals
+	for _, c := range cmds {
+		flags = append(flags, c.flags...)
+	}
+	for _, f := range flags {
+		if len(f.choices) == 0 || seen[f.name] {
+			continue
 		}
+		seen[f.name] = true
+		fmt.Fprintf(b, "        -%s|--%s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")); return ;;\n", f.name, f.name, strings.Join(f.choices, " "))
 	}
 }
 
-// Add new function to handle Google ID intelligence
-func runGoogleIDIntelligence(gid string, outputPath string) {
-	fmt.Printf("Analyzing Google ID: %s\n", gid)
-
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Run the Google ID analysis
-	results, err := osint.AnalyzeGoogleID(ctx, gid)
-	if err != nil {
-		color.Red("Error analyzing Google ID: %v", err)
-		return
+// flagWords returns the --name form of each flag
+func flagWords(flags []completionFlag) string {
+	words := make([]string, len(flags))
+	for i, f := range flags {
+		words[i] = "--" + f.name
 	}
+	return strings.Join(words, " ")
+}
 
-	// Display results
-	results.DisplayResults()
-
-	// Save to file if output path is specified
-	if outputPath != "" {
-		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
-			if err := os.WriteFile(outputPath, data, 0644); err == nil {
-				color.Green("\nResults saved to: %s", outputPath)
-			} else {
-				color.Red("Error saving results: %v", err)
-			}
-		} else {
-			color.Red("Error encoding results: %v", err)
+// valueFlagWords returns both forms of each flag that takes a value
+func valueFlagWords(flags []completionFlag) string {
+	var words []string
+	for _, f := range flags {
+		if f.value {
+			words = append(words, "-"+f.name, "--"+f.name)
 		}
 	}
+	return strings.Join(words, " ")
 }
 
-// Add this new function
-func runPhoneNumberIntelligence(phone string, outputPath string) {
-	fmt.Printf("Analyzing phone number: %s\n", phone)
-
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Run the phone number analysis
-	results, err := osint.AnalyzePhoneNumber(ctx, phone)
-	if err != nil {
-		color.Red("Error analyzing phone number: %v", err)
-		return
+func bashCompletion(globals []completionFlag, cmds []completionCommand) string {
+	var b strings.Builder
+	names := make([]string, len(cmds))
+	for i, c := range cmds {
+		names[i] = c.name
 	}
 
-	// Display header
-	color.Cyan("\n=====================================")
-	color.Cyan(" PHONE NUMBER INTELLIGENCE REPORT")
-	color.Cyan("=====================================\n")
+	b.WriteString("# bash completion for mercuries, written by 'mercuries completion bash'\n\n")
+	b.WriteString("# _mercuries_value_flags prints the options of a command that take a value\n")
+	b.WriteString("_mercuries_value_flags() {\n    case \"$1\" in\n")
+	for _, c := range cmds {
+		if words := valueFlagWords(c.flags); words != "" {
+			fmt.Fprintf(&b, "        %s) echo \"%s\" ;;\n", c.name, words)
+		}
+	}
+	b.WriteString("    esac\n}\n\n")
 
-	// Display results with improved formatting
-	results.DisplayResults()
+	fmt.Fprintf(&b, "_mercuries() {\n")
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprintf(&b, "    local globals=\"%s\"\n", valueFlagWords(globals))
+	b.WriteString(`    local cmd="" args=0 i word values
+    # Find the command and count its arguments, skipping options and their values
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        word="${COMP_WORDS[i]}"
+        [[ "$word" == -* ]] && continue
+        values=" $globals $(_mercuries_value_flags "$cmd") "
+        [[ "$values" == *" ${COMP_WORDS[i-1]} "* ]] && continue
+        if [[ -z "$cmd" ]]; then
+            cmd="$word"
+        else
+            ((args++))
+        fi
+    done
 
-	// Display summary footer
-	color.Cyan("\n=== ANALYSIS SUMMARY ===")
+    case "$prev" in
+`)
+	bashChoiceCases(&b, globals, cmds)
+	b.WriteString(`    esac
+    # Other option values are usually files
+    values=" $globals $(_mercuries_value_flags "$cmd") "
+    if [[ "$values" == *" $prev "* ]]; then
+        COMPREPLY=($(compgen -f -- "$cur"))
+        return
+    fi
 
-	// Risk level indicator
-	switch results.RiskAssessment.Level {
-	case "Low":
-		color.Green("Risk Level: %s (%d/100)", results.RiskAssessment.Level, results.RiskAssessment.Score)
-	case "Medium":
-		color.Yellow("Risk Level: %s (%d/100)", results.RiskAssessment.Level, results.RiskAssessment.Score)
-	case "High":
-		color.Red("Risk Level: %s (%d/100)", results.RiskAssessment.Level, results.RiskAssessment.Score)
+`)
+	b.WriteString("    if [[ \"$cur\" == -* ]]; then\n        local flags=\"" + flagWords(globals) + "\"\n        case \"$cmd\" in\n")
+	for _, c := range cmds {
+		if len(c.flags) > 0 {
+			fmt.Fprintf(&b, "            %s) flags+=\" %s\" ;;\n", c.name, flagWords(c.flags))
+		}
 	}
+	b.WriteString("        esac\n        COMPREPLY=($(compgen -W \"$flags\" -- \"$cur\"))\n        return\n    fi\n\n")
 
-	// Carrier status
-	if results.Carrier.Name != "Unknown Carrier" {
-		color.Green("Carrier: Identified (%s)", results.Carrier.Name)
-	} else {
-		color.Yellow("Carrier: Unknown")
+	fmt.Fprintf(&b, "    if [[ -z \"$cmd\" ]]; then\n        COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n        return\n    fi\n", strings.Join(names, " "))
+	b.WriteString("    if ((args == 0)); then\n        case \"$cmd\" in\n")
+	for _, c := range cmds {
+		if len(c.args) > 0 {
+			fmt.Fprintf(&b, "            %s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")); return ;;\n", c.name, strings.Join(c.args, " "))
+		}
 	}
+	b.WriteString("        esac\n    fi\n    COMPREPLY=($(compgen -f -- \"$cur\"))\n}\n\ncomplete -o filenames -F _mercuries mercuries\n")
+	return b.String()
+}
 
-	// Format validity
-	if results.ValidationInfo.IsValid {
-		color.Green("Format: Valid")
-	} else {
-		color.Red("Format: Invalid")
-	}
+// fishQuote quotes a string for fish
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
 
-	// Spam likelihood
-	switch strings.ToLower(results.RiskAssessment.SpamLikelihood) {
-	case "low":
-		color.Green("Spam Likelihood: %s", results.RiskAssessment.SpamLikelihood)
-	case "medium":
-		color.Yellow("Spam Likelihood: %s", results.RiskAssessment.SpamLikelihood)
-	case "high":
-		color.Red("Spam Likelihood: %s", results.RiskAssessment.SpamLikelihood)
+// fishFlags writes the complete lines offering flags under a condition
+func fishFlags(b *strings.Builder, flags []completionFlag, condition string) {
+	for _, f := range flags {
+		line := "complete -c mercuries"
+		if condition != "" {
+			line += " -n " + fishQuote(condition)
+		}
+		line += " -l " + f.name
+		switch {
+		case len(f.choices) > 0:
+			line += " -x -a " + fishQuote(strings.Join(f.choices, " "))
+		case f.value:
+			line += " -r"
+		}
+		fmt.Fprintf(b, "%s -d %s\n", line, fishQuote(f.usage))
 	}
+}
 
-	// Online presence summary
-	if len(results.OnlinePresence) > 0 {
-		color.Green("Online Presence: Found on %d platforms", len(results.OnlinePresence))
-	} else {
-		color.Yellow("Online Presence: No traces found")
+func fishCompletion(globals []completionFlag, cmds []completionCommand) string {
+	var b strings.Builder
+	b.WriteString("# fish completion for mercuries, written by 'mercuries completion fish'\n\n")
+	fishFlags(&b, globals, "")
+	b.WriteString("\n")
+	for _, c := range cmds {
+		fmt.Fprintf(&b, "complete -c mercuries -n __fish_use_subcommand -f -a %s -d %s\n", c.name, fishQuote(c.summary))
+	}
+	for _, c := range cmds {
+		b.WriteString("\n")
+		seen := "__fish_seen_subcommand_from " + c.name
+		if len(c.args) > 0 {
+			fmt.Fprintf(&b, "complete -c mercuries -n %s -f -a %s\n", fishQuote(seen), fishQuote(strings.Join(c.args, " ")))
+		}
+		fishFlags(&b, c.flags, seen)
 	}
+	return b.String()
+}
 
-	// Save to file if output path is specified
-	if outputPath != "" {
-		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
-			if err := os.WriteFile(outputPath, data, 0644); err == nil {
-				color.Green("\nDetailed results saved to: %s", outputPath)
-			} else {
-				color.Red("Error saving results: %v", err)
+// addGlobalFlags registers the global options. Their defaults are the current
+// values, so options given before a command survive its flag set.
+func addGlobalFlags(fs *flag.FlagSet) {
+	fs.StringVar(&global.record, "record", global.record, "Record every HTTP request and response of the run to a cassette file")
+	fs.StringVar(&global.replay, "replay", global.replay, "Answer HTTP requests from a recorded cassette instead of the network")
+	fs.StringVar(&global.traceHeader, "trace-header", global.traceHeader, "Send the run's scan ID to every site and API in this request header (e.g. X-Scan-ID)")
+	fs.Int64Var(&global.maxBodySize, "max-body-size", global.maxBodySize, "Maximum bytes read from a fetched page; longer pages are truncated")
+	fs.StringVar(&global.authorizedBy, "authorized-by", global.authorizedBy, "Who authorized this investigation, recorded into TheHive cases and sent to the policy endpoint")
+	fs.BoolVar(&global.acceptTerms, "accept-terms", global.acceptTerms, "Accept the acceptable use notice without a prompt, for scripted first runs")
+	fs.StringVar(&global.policyURL, "policy-url", global.policyURL, "Organizational policy endpoint asked to allow or deny each scan (default $MERCURIES_POLICY_URL)")
+	fs.BoolVar(&global.touchCanaries, "touch-canaries", global.touchCanaries, "Scan and fetch known canary tokens and callback domains instead of skipping them")
+	fs.StringVar(&global.caseName, "case", global.caseName, "File the run's results in this case and index the text they hold, searchable with the search command (default: the case opened with 'mercuries case open')")
+	fs.StringVar(&global.format, "format", global.format, "Print the results to stdout as table (colored text), json, csv or yaml, with everything else on stderr")
+	fs.BoolVar(&global.quiet, "quiet", global.quiet, "Print only the results, as JSON on stdout: no banner, progress bar, colors or messages. A failed target is printed as {\"error\": ...} and the exit status is 1")
+	fs.StringVar(&global.proxy, "proxy", global.proxy, "Send every HTTP request through this proxy: http://host:port or socks5://host:port (default proxy in the config file)")
+	fs.DurationVar(&global.timeout, "timeout", global.timeout, "Time allowed for each HTTP request, e.g. 30s on slow networks")
+	fs.IntVar(&global.retries, "retries", global.retries, "Times a failed or throttled request is retried (default 2 for APIs, 1 for profile checks)")
+	fs.DurationVar(&global.retryBackoff, "retry-backoff", global.retryBackoff, "Wait before the first retry, growing with each further retry")
+	fs.BoolVar(&global.tor, "tor", global.tor, "Send every HTTP request through a local Tor SOCKS listener (port 9050 or 9150), checking the exit is Tor before the run")
+	fs.BoolVar(&global.torIsolate, "tor-isolate", global.torIsolate, "With --tor, use a separate Tor circuit for each site so they see different exits")
+	fs.Int64Var(&global.maxRequests, "max-requests", global.maxRequests, "Stop sending requests after this many, across all modules, and report what was skipped (0 for no limit)")
+	fs.Int64Var(&global.maxBandwidth, "max-bandwidth", global.maxBandwidth, "Stop sending requests once this many bytes have been sent and received, across all modules (0 for no limit)")
+	fs.StringVar(&global.requestLog, "request-log", global.requestLog, "Append a record of every outbound request (time, host, module, service, status, bytes) to this file (default request_log in the config file)")
+	fs.BoolVar(&global.noHooks, "no-hooks", global.noHooks, "Do not run the hook scripts in the hooks directory (default ~/.mercuries/hooks)")
+	fs.BoolVar(&global.noSuppress, "no-suppressions", global.noSuppress, "Show the findings on the global and case suppression lists instead of hiding them")
+	fs.BoolVar(&global.dryRun, "dry-run", global.dryRun, "Print every request and DNS query the run would make, per platform, name variation and service, without sending any")
+	fs.StringVar(&global.summary, "summary", global.summary, "Write an AI-generated executive summary and next pivots to this file (.md or .json), using the model set in the config file")
+	fs.StringVar(&global.report, "report", global.report, "Also write the results as a report in this format (markdown), in the case's directory or "+osint.OutputDir)
+}
+
+// globalFlagNames are the names addGlobalFlags registers
+func globalFlagNames() map[string]bool {
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	addGlobalFlags(fs)
+	names := make(map[string]bool)
+	fs.VisitAll(func(f *flag.Flag) { names[f.Name] = true })
+	return names
+}
+
+// commandFlags returns a command's flag set, with the global options
+// registered and a usage listing only the command's own
+func commandFlags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	addGlobalFlags(fs)
+	fs.Usage = func() {
+		cmd, _ := lookupCommand(name)
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage: mercuries %s %s\n\n%s\n", name, cmd.args, cmd.summary)
+
+		own := flag.NewFlagSet(name, flag.ContinueOnError)
+		own.SetOutput(out)
+		globals := globalFlagNames()
+		fs.VisitAll(func(f *flag.Flag) {
+			if !globals[f.Name] {
+				own.Var(f.Value, f.Name, f.Usage)
+				own.Lookup(f.Name).DefValue = f.DefValue
 			}
-		} else {
-			color.Red("Error encoding results: %v", err)
+		})
+		if countFlags(own) > 0 {
+			fmt.Fprintf(out, "\nOptions:\n")
+			own.PrintDefaults()
+		}
+		fmt.Fprintf(out, "\nGlobal options are listed by 'mercuries help'.\n")
+	}
+	return fs
+}
+
+// commandFlagSet returns a command's flag set with the global options and
+// its own registered, for running it or completing its options
+func commandFlagSet(name string) *flag.FlagSet {
+	fs := commandFlags(name)
+	if cmd, ok := lookupCommand(name); ok && cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	return fs
+}
+
+// countFlags counts the flags defined on a flag set
+func countFlags(fs *flag.FlagSet) int {
+	count := 0
+	fs.VisitAll(func(*flag.Flag) { count++ })
+	return count
+}
+
+// parseFlags parses a command's arguments, applies the global options and
+// shows the banner
+func parseFlags(fs *flag.FlagSet, args []string) {
+	fs.Parse(args)
+
+	osint.MaxBodySize = global.maxBodySize
+	// Network settings replace the modules' own defaults only when given,
+	// before or after the command
+	setNetwork := func(f *flag.Flag) {
+		switch f.Name {
+		case "timeout":
+			osint.RequestTimeout, providers.Timeout = global.timeout, global.timeout
+		case "retries":
+			providers.MaxRetries, osint.ProfileRetries = global.retries, global.retries
+		case "retry-backoff":
+			providers.RetryBackoff = global.retryBackoff
+		}
+	}
+	flag.Visit(setNetwork)
+	fs.Visit(setNetwork)
+	if global.timeout <= 0 || global.retries < 0 || global.retryBackoff < 0 {
+		color.Red("Error: --timeout must be positive, and --retries and --retry-backoff cannot be negative")
+		os.Exit(1)
+	}
+	proxy := global.proxy
+	if proxy == "" {
+		proxy = osint.ProxyURL
+	}
+	switch {
+	case global.tor && global.proxy != "":
+		color.Red("Error: --tor and --proxy cannot be combined")
+		os.Exit(1)
+	case global.torIsolate && !global.tor:
+		color.Red("Error: --tor-isolate needs --tor")
+		os.Exit(1)
+	case global.tor:
+		proxy = useTor()
+	}
+	if proxy != "" {
+		if err := providers.SetProxy(proxy); err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+	}
+	// Tor hides nothing if the targets' names are resolved locally
+	if global.tor {
+		if err := providers.RouteDialsThroughProxy(); err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+	}
+	if global.dryRun && plan == nil {
+		if global.record != "" || global.replay != "" {
+			color.Red("Error: --dry-run cannot be combined with --record or --replay")
+			os.Exit(1)
+		}
+		// Innermost, so every layer added below sees the requests planned
+		plan = providers.Plan()
+		osint.ProfileRetries, osint.HooksDir = 0, ""
+	}
+	if err := useCassette(global.record, global.replay); err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	providers.TraceHeader = global.traceHeader
+	providers.Trace()
+	if global.maxRequests < 0 || global.maxBandwidth < 0 {
+		color.Red("Error: --max-requests and --max-bandwidth cannot be negative")
+		os.Exit(1)
+	}
+	if global.maxRequests > 0 || global.maxBandwidth > 0 {
+		budget = providers.Limit(global.maxRequests, global.maxBandwidth)
+	}
+
+	osint.AuthorizedBy = global.authorizedBy
+	osint.PolicyURL = global.policyURL
+	osint.TouchCanaries = global.touchCanaries
+	osint.GuardCanaries()
+	// Outermost, so requests the canary guard refuses are logged too
+	if err := openRequestLog(); err != nil {
+		color.Red("Error opening the request log: %v", err)
+		os.Exit(1)
+	}
+	if global.tor && plan == nil {
+		checkTorExit()
+	}
+	if global.noHooks {
+		osint.HooksDir = ""
+	}
+
+	// Runs are filed in the open case unless --case names another
+	if global.caseName == "" {
+		global.caseName = osint.ActiveCase()
+	}
+	if global.caseName != "" {
+		if err := osint.ValidateCaseName(global.caseName); err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+	}
+	if !global.noSuppress {
+		suppressions, err := osint.LoadSuppressions(global.caseName)
+		if err != nil {
+			color.Red("Error reading the suppression lists: %v", err)
+			os.Exit(1)
+		}
+		osint.ActiveSuppressions = suppressions
+	}
+
+	switch {
+	case !slices.Contains(osint.OutputFormats, global.format):
+		color.Red("Error: unknown format %q, expected one of %s", global.format, strings.Join(osint.OutputFormats, ", "))
+		os.Exit(1)
+	case global.quiet && global.format != osint.FormatTable && global.format != osint.FormatJSON:
+		color.Red("Error: --quiet prints JSON and cannot be combined with --format %s", global.format)
+		os.Exit(1)
+	case (global.quiet || global.format != osint.FormatTable) && !formatCommands[fs.Name()]:
+		color.Red("Error: the %s command only prints tables; --format and --quiet work with social, email, phone, gid, domain and ip", fs.Name())
+		os.Exit(1)
+	case global.report != "" && !slices.Contains(osint.ReportFormats, global.report):
+		color.Red("Error: unknown report format %q, expected one of %s", global.report, strings.Join(osint.ReportFormats, ", "))
+		os.Exit(1)
+	case global.report != "" && !reportCommands[fs.Name()]:
+		color.Red("Error: the %s command has no report; --report works with scan, all, social, email, phone, gid and domain", fs.Name())
+		os.Exit(1)
+	}
+	if global.quiet {
+		global.format = osint.FormatJSON
+	}
+	// Machine-readable results keep stdout to themselves
+	piped := pipelineCommands[fs.Name()] && fs.NArg() == 1 && fs.Arg(0) == "-"
+	if piped || global.format != osint.FormatTable {
+		reserveStdout()
+	}
+	if piped {
+		pipeline = json.NewEncoder(resultsOut)
+	}
+	if global.quiet {
+		silence()
+		return
+	}
+
+	// Cortex reads the analyzer report, and shells the completion script,
+	// from stdout
+	if fs.Name() != "cortex" && fs.Name() != "completion" {
+		displayBanner()
+	}
+}
+
+// useTor finds the local Tor SOCKS listener and returns its proxy URL
+func useTor() string {
+	ctx, cancel := context.WithTimeout(context.Background(), osint.RequestTimeout)
+	defer cancel()
+	address, err := osint.FindTor(ctx)
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	providers.IsolateCircuits = global.torIsolate
+	return "socks5://" + address
+}
+
+// checkTorExit stops the run unless requests leave through Tor
+func checkTorExit() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*osint.RequestTimeout)
+	defer cancel()
+	exit, err := osint.CheckTorExit(ctx)
+	if err != nil {
+		color.Red("Error: %v; nothing was scanned", err)
+		os.Exit(1)
+	}
+	color.Green("Routing through Tor via %s, exit %s", providers.ProxyURL(), exit.IP)
+}
+
+// budget counts the run's requests against --max-requests and --max-bandwidth
+var budget *providers.BudgetUsage
+
+// displayBudget reports what the run used of its budget, and the requests
+// skipped once it ran out
+func displayBudget() {
+	if budget == nil {
+		return
+	}
+	requests, bytes := budget.Used()
+	skipped := budget.Skipped()
+	if len(skipped) == 0 {
+		color.Cyan("\nBudget: %d requests, %d bytes used", requests, bytes)
+		return
+	}
+	total := 0
+	for _, host := range skipped {
+		total += host.Requests
+	}
+	color.Yellow("\nBudget used up after %d requests and %d bytes; skipped %d requests:", requests, bytes, total)
+	for _, host := range skipped {
+		fmt.Printf("  %s: %d\n", host.Host, host.Requests)
+	}
+}
+
+// plan records the requests of a --dry-run instead of sending them
+var plan *providers.Planner
+
+// displayPlan lists the requests a dry run would have sent, by host in the
+// order first contacted
+func displayPlan() {
+	if plan == nil {
+		return
+	}
+	requests := plan.Requests()
+	var hosts []string
+	byHost := make(map[string][]providers.PlannedRequest)
+	for _, request := range requests {
+		if _, ok := byHost[request.Host]; !ok {
+			hosts = append(hosts, request.Host)
+		}
+		byHost[request.Host] = append(byHost[request.Host], request)
+	}
+
+	color.Cyan("\nDry run: %d requests to %d hosts planned, none sent", len(requests), len(hosts))
+	for _, host := range hosts {
+		color.Yellow("  %s", host)
+		for _, request := range byHost[host] {
+			line := fmt.Sprintf("    %-4s %s", request.Method, request.URL)
+			if request.Service != "" && request.Service != host {
+				line += "  [" + request.Service + "]"
+			}
+			if request.Count > 1 {
+				line += fmt.Sprintf("  (x%d)", request.Count)
+			}
+			fmt.Println(line)
+		}
+	}
+	fmt.Println("Requests that depend on answers, like links followed, further pages and checks run only once an earlier one succeeds, are not included.")
+}
+
+// openRequestLog starts logging outbound requests to --request-log, or the
+// config file's request_log
+func openRequestLog() error {
+	path := global.requestLog
+	if path == "" {
+		path = osint.RequestLogFile
+	}
+	if path == "" {
+		return nil
+	}
+	log, err := providers.OpenRequestLog(path)
+	if err != nil {
+		return err
+	}
+	providers.Audit(log)
+	return nil
+}
+
+// addSyslogFlags registers the options sending a module's alerts to a
+// syslog collector
+func addSyslogFlags(fs *flag.FlagSet) {
+	fs.StringVar(syslogFlag, "syslog", "", "Forward alerts to a syslog collector (udp://host:514 or tcp://host:6514)")
+	fs.StringVar(syslogFormatFlag, "syslog-format", osint.SyslogRFC5424, "Syslog payload format: rfc5424, cef or leef")
+}
+
+// addCaseFlags registers the options exporting a module's results to TheHive
+// and OpenCTI
+func addCaseFlags(fs *flag.FlagSet) {
+	fs.StringVar(theHiveFlag, "thehive", "", "Export results as a TheHive case JSON file")
+	fs.StringVar(theHiveURLFlag, "thehive-url", "", "Create the case on this TheHive instance (needs an API key)")
+	fs.StringVar(openCTIURLFlag, "opencti-url", "", "Push observables and relationships to this OpenCTI instance (needs an API key)")
+	fs.IntVar(openCTIConfidenceFlag, "opencti-confidence", osint.OpenCTIConfidence, "Confidence (0-100) given to relationships pushed to OpenCTI")
+}
+
+// addTranslateFlags registers the options translating foreign-language text
+func addTranslateFlags(fs *flag.FlagSet) {
+	fs.StringVar(&osint.TranslateTo, "translate", "", "Translate text written in another language into this one (e.g. en) with DeepL or LibreTranslate")
+	fs.StringVar(&osint.LibreTranslateURL, "libretranslate-url", "", "Self-hosted LibreTranslate instance, used when no DeepL key is configured")
+}
+
+// addKeywordFlags registers the case keyword options
+func addKeywordFlags(fs *flag.FlagSet) {
+	fs.StringVar(keywordsFlag, "keywords", "", "Comma-separated case keywords (project names, addresses, phone fragments) to highlight in collected content")
+	fs.StringVar(keywordsFileFlag, "keywords-file", "", "File of case keywords to highlight, one per line")
+}
+
+// addPlatformFlags registers the options choosing the platforms profile
+// searches check
+func addPlatformFlags(fs *flag.FlagSet) {
+	fs.StringVar(platformsFlag, "platforms", "", "Comma-separated platforms to search (e.g. twitter,github), in place of the config file's list")
+	fs.StringVar(excludePlatformsFlag, "exclude-platforms", "", "Comma-separated platforms to leave out of the search")
+}
+
+// addScanTuningFlags registers the options trading profile search speed
+// against the rate limits it triggers
+func addScanTuningFlags(fs *flag.FlagSet) {
+	fs.IntVar(&osint.ScanWorkers, "workers", 0, "Profile checks run at once (default: picked from the hardware detected)")
+	fs.Float64Var(&osint.ScanRateLimit, "rate", osint.ScanRateLimit, "Profile checks a second across every worker; lower it on shared IPs")
+	fs.IntVar(&osint.ScanBatchSize, "batch-size", osint.ScanBatchSize, "Profiles found held in memory before being spilled to the dump directory")
+}
+
+// filterPlatforms limits profile searches to the platforms chosen with
+// --platforms and --exclude-platforms
+func filterPlatforms(include, exclude string) {
+	split := func(list string) []string {
+		var names []string
+		for _, name := range strings.Split(list, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	if err := osint.FilterPlatforms(split(include), split(exclude)); err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+}
+
+// commandTarget validates and normalizes the single target a command takes,
+// printing its usage when it is missing
+func commandTarget(fs *flag.FlagSet, kind string) string {
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	target, err := input.Target(kind, fs.Arg(0))
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	return target
+}
+
+// interruptContext returns a context cancelled by the first Ctrl-C or
+// SIGTERM, so a long scan can stop and save what it found. The signals are
+// released once it fires, and a second Ctrl-C exits at once.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// resumeTarget returns a command's target, or the query of the checkpoint it
+// resumes when no target is given
+func resumeTarget(fs *flag.FlagSet, kind, checkpoint string) string {
+	osint.ResumeCheckpoint = checkpoint
+	if checkpoint == "" || fs.NArg() > 0 {
+		return commandTarget(fs, kind)
+	}
+	saved, err := osint.LoadScanCheckpoint(checkpoint)
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	return saved.Query
+}
+
+// commandTargets returns a command's targets: its one argument, or each line
+// of stdin when the argument is -
+func commandTargets(fs *flag.FlagSet, kind string) []string {
+	if fs.NArg() != 1 || fs.Arg(0) != "-" {
+		return []string{commandTarget(fs, kind)}
+	}
+	if output := fs.Lookup("output"); output != nil && output.Value.String() != "" {
+		color.Red("Error: --output saves a single target's results; results of targets read from - are written to stdout")
+		os.Exit(1)
+	}
+
+	var targets []string
+	scanner := bufio.NewScanner(os.Stdin)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		target, err := input.Target(kind, text)
+		if err != nil {
+			color.Yellow("Skipping line %d: %v", line, err)
+			continue
+		}
+		if !slices.Contains(targets, target) {
+			targets = append(targets, target)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		color.Red("Error reading targets: %v", err)
+		os.Exit(1)
+	}
+	if len(targets) == 0 {
+		color.Red("Error: no %s targets on stdin", kind)
+		os.Exit(1)
+	}
+	return targets
+}
+
+var (
+	// resultsOut receives machine-readable results: JSON lines for targets
+	// read from stdin, or the document chosen with --format
+	resultsOut io.Writer = os.Stdout
+	// pipeline writes one JSON line per target when targets are read from stdin
+	pipeline *json.Encoder
+	// targetFailed is set once a target's module fails, for --quiet's exit status
+	targetFailed bool
+)
+
+// pipelineCommands read their targets from stdin when given -
+var pipelineCommands = map[string]bool{"email": true, "phone": true, "gid": true, "domain": true, "ip": true}
+
+// formatCommands print their results in the format chosen with --format
+var formatCommands = map[string]bool{"all": true, "social": true, "email": true, "phone": true, "gid": true, "domain": true, "ip": true}
+
+// reportCommands write the report asked for with --report
+var reportCommands = map[string]bool{"scan": true, "all": true, "social": true, "email": true, "phone": true, "gid": true, "domain": true}
+
+// pipelineRecord is the JSON line written for each target
+type pipelineRecord struct {
+	Module  string      `json:"module"`
+	Target  string      `json:"target"`
+	Results interface{} `json:"results,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// reserveStdout keeps stdout for machine-readable results, moving
+// everything else the commands print to stderr
+func reserveStdout() {
+	resultsOut = os.Stdout
+	os.Stdout = os.Stderr
+	color.Output = color.Error
+}
+
+// silence drops everything but the results and errors for --quiet: the
+// banner, the progress bar, colors and every other message
+func silence() {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	os.Stdout = devNull
+	color.Output = errorsOnly{}
+	color.NoColor = true
+	osint.ShowProgress = false
+}
+
+// errorsOnly passes the errors commands print with color.Red on to stderr,
+// so a --quiet run that stops early still says why
+type errorsOnly struct{}
+
+func (errorsOnly) Write(p []byte) (int, error) {
+	if strings.HasPrefix(string(p), "Error") {
+		return os.Stderr.Write(p)
+	}
+	return len(p), nil
+}
+
+// emitResult writes a target's results, or why it failed, as a JSON line
+// when targets come from stdin, or in the format chosen with --format
+func emitResult(module, target string, results interface{}, err error) {
+	if err != nil {
+		targetFailed = true
+	}
+	if pipeline == nil {
+		if global.quiet && err != nil {
+			osint.WriteFormat(resultsOut, osint.FormatJSON, pipelineRecord{Module: module, Target: target, Error: err.Error()})
+		} else if global.format != osint.FormatTable && err == nil {
+			if err := osint.WriteFormat(resultsOut, global.format, results); err != nil {
+				color.Red("Error writing results: %v", err)
+			}
+		}
+		return
+	}
+	record := pipelineRecord{Module: module, Target: target, Results: results}
+	if err != nil {
+		record.Results, record.Error = nil, err.Error()
+	}
+	if err := pipeline.Encode(record); err != nil {
+		// The reader went away, e.g. head has seen enough
+		os.Exit(1)
+	}
+}
+
+// startScan gates a command investigating targets: the acceptable use notice
+// must be accepted, no target may be a canary and the policy endpoint must
+// allow the scan
+func startScan(module string, targets ...string) {
+	requireAcceptableUse(global.acceptTerms)
+	refuseCanaryTargets(targets...)
+	enforcePolicy(module, strings.Join(targets, " "))
+	fmt.Printf("Scan ID: %s\n", providers.ScanID)
+}
+
+// loadWatchKeywords sets the case keywords from a comma-separated list and a
+// keyword file
+func loadWatchKeywords(list, path string) {
+	var keywords []string
+	for _, keyword := range strings.Split(list, ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	if path != "" {
+		fromFile, err := osint.LoadKeywords(path)
+		if err != nil {
+			color.Red("Error reading keywords: %v", err)
+			os.Exit(1)
+		}
+		keywords = append(keywords, fromFile...)
+	}
+	osint.WatchKeywords = keywords
+}
+
+// addSocialMediaCommandFlags registers the social command's options
+func addSocialMediaCommandFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.BoolVar(verboseFlag, "verbose", false, "Print profiles as they are found")
+	fs.BoolVar(expandHandlesFlag, "expand-handles", false, "Also scan near-variants of handles found (swapped separators, stripped digits)")
+	fs.BoolVar(socialGraphFlag, "social-graph", false, "Sample the followers and following of profiles on GitHub (and Twitter with an API token) and list accounts their networks share")
+	fs.IntVar(graphSampleFlag, "graph-sample", osint.GraphSampleSize, "Followers, and separately following, sampled per profile by --social-graph")
+	fs.BoolVar(accountHistoryFlag, "account-history", false, "Check GitHub and Reddit for deleted or renamed accounts under the searched handle")
+	fs.StringVar(tzPhoneFlag, "tz-phone", "", "Compare the activity timezone with this phone number's region")
+	fs.StringVar(tzIPFlag, "tz-ip", "", "Compare the activity timezone with this IP's geolocation")
+	fs.Float64Var(minConfidenceFlag, "min-confidence", 0, "Show and export only profiles scoring at least this (0-1); the rest are listed as leads")
+	fs.BoolVar(geocodeFlag, "geocode", false, "Geocode profile locations and places reviewed with Nominatim, once the search is done")
+	fs.StringVar(geoFlag, "geo", "", "Export profile locations, places reviewed and shared activity start points as GeoJSON, or KML when the file ends in .kml")
+	fs.StringVar(resumeFlag, "resume", "", "Resume an interrupted scan from its checkpoint file, skipping the checks already done; the name can then be left out")
+	addTranslateFlags(fs)
+	addKeywordFlags(fs)
+	addPlatformFlags(fs)
+	addScanTuningFlags(fs)
+}
+
+// runSocialMediaCommand searches social media platforms for a name or handle
+func runSocialMediaCommand(args []string) {
+	fs := commandFlagSet("social")
+	parseFlags(fs, args)
+
+	query := resumeTarget(fs, input.KindName, *resumeFlag)
+	loadWatchKeywords(*keywordsFlag, *keywordsFileFlag)
+	filterPlatforms(*platformsFlag, *excludePlatformsFlag)
+	startScan("social", query)
+
+	fmt.Println("Running Social Media Intelligence module...")
+	runSocialMediaIntelligence(query, *outputFlag)
+}
+
+// addUsernameScanFlags registers the scan command's options
+func addUsernameScanFlags(fs *flag.FlagSet) {
+	fs.StringVar(dirFlag, "dir", "", "Output directory for results (default: the case's directory when the run is filed in a case, otherwise "+osint.OutputDir+")")
+	fs.BoolVar(verboseFlag, "verbose", false, "Print profiles as they are found")
+	fs.StringVar(resumeFlag, "resume", "", "Resume an interrupted scan from its checkpoint file, skipping the checks already done; the username can then be left out")
+	addPlatformFlags(fs)
+	addScanTuningFlags(fs)
+}
+
+// runUsernameScan scans every platform for a username, saving the profiles
+// found to a timestamped file
+func runUsernameScan(args []string) {
+	fs := commandFlagSet("scan")
+	parseFlags(fs, args)
+
+	username := resumeTarget(fs, input.KindUsername, *resumeFlag)
+	filterPlatforms(*platformsFlag, *excludePlatformsFlag)
+	startScan("scan", username)
+
+	outputFile := ""
+	if *dirFlag == "" {
+		outputFile = caseOutputPath("scan", username)
+	}
+	if outputFile == "" {
+		dir := *dirFlag
+		if dir == "" {
+			dir = osint.OutputDir
+		}
+		// Create output directory if it doesn't exist
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			os.MkdirAll(dir, 0755)
+		}
+
+		// Generate output filename
+		outputFile = filepath.Join(dir, fmt.Sprintf("%s_%s.json",
+			username,
+			time.Now().Format("20060102_150405")))
+	}
+
+	// Run sequential scan
+	fmt.Printf("Starting Mercuries scan for username: %s\n", username)
+	ctx, stop := interruptContext()
+	defer stop()
+	results, err := osint.SearchProfilesSequentially(ctx, username, outputFile, *verboseFlag)
+
+	switch {
+	case errors.Is(err, osint.ErrInterrupted):
+		color.Yellow("\nScan interrupted! Saved the %d profiles found so far to %s.", results.ProfilesFound, outputFile)
+	case err != nil:
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	default:
+		fmt.Printf("\nScan complete! Found %d profiles across %d platforms.\n",
+			results.ProfilesFound,
+			len(results.Profiles))
+	}
+	if results.Checkpoint != "" {
+		fmt.Printf("Some checks failed or were not run; finish them with --resume %s\n", results.Checkpoint)
+	}
+	indexCase("scan", username, results)
+	summarize("scan", username, results)
+	writeReport("scan", username, results)
+	osint.RunHook(osint.HookScanComplete, "scan", results)
+}
+
+// addAllCommandFlags registers the all command's options
+func addAllCommandFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path (default: a timestamped file in the case when the run is filed in one, otherwise in the results directory)")
+	fs.BoolVar(verboseFlag, "verbose", false, "Print profiles as they are found and show modules that failed")
+	addPlatformFlags(fs)
+	addScanTuningFlags(fs)
+}
+
+// runAllCommand runs every identity module from one email address or name,
+// pivoting on what each finds, and saves one merged report
+func runAllCommand(args []string) {
+	fs := commandFlagSet("all")
+	parseFlags(fs, args)
+
+	seed := commandTarget(fs, input.KindName)
+	filterPlatforms(*platformsFlag, *excludePlatformsFlag)
+	startScan("all", seed)
+
+	fmt.Printf("Running every module from: %s\n", seed)
+	ctx, stop := interruptContext()
+	defer stop()
+	report, err := osint.ScanAll(ctx, seed, *verboseFlag)
+	if errors.Is(err, osint.ErrInterrupted) {
+		color.Yellow("\nScan interrupted, reporting what was found so far")
+	} else if err != nil {
+		color.Red("Error: %v", err)
+		emitResult("all", seed, nil, err)
+		return
+	}
+
+	if report.Email != nil {
+		report.Email.DisplayResults()
+	}
+	if report.Social != nil {
+		displaySocialResults(report.Social)
+	}
+	for _, gid := range report.GoogleIDs {
+		gid.DisplayResults()
+	}
+	for _, phone := range report.Phones {
+		phone.DisplayResults()
+	}
+	report.DisplayPivots()
+	if *verboseFlag {
+		report.DisplayPartialErrors()
+	}
+	if err == nil {
+		color.Green("\nCombined scan complete: %s", report.Summary())
+	}
+
+	outputPath := *outputFlag
+	if outputPath == "" {
+		outputPath = caseOutputPath("all", report.Seed)
+	}
+	indexCase("all", report.Seed, report)
+	summarize("all", report.Seed, report)
+	writeReport("all", report.Seed, report)
+	osint.RunHook(osint.HookScanComplete, "all", report)
+	emitResult("all", report.Seed, report, nil)
+
+	if outputPath == "" {
+		os.MkdirAll(osint.OutputDir, 0755)
+		name := strings.NewReplacer(" ", "_", "@", "_at_").Replace(report.Seed)
+		outputPath = filepath.Join(osint.OutputDir, fmt.Sprintf("%s_all_%s.json", name, time.Now().Format("20060102_150405")))
+	}
+	if data, err := json.MarshalIndent(report, "", "  "); err == nil {
+		if err := os.WriteFile(outputPath, data, 0644); err == nil {
+			color.Green("Report saved to: %s", outputPath)
+		} else {
+			color.Red("Error saving results: %v", err)
+		}
+	} else {
+		color.Red("Error encoding results: %v", err)
+	}
+}
+
+// addEmailCommandFlags registers the email command's options
+func addEmailCommandFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show lookups that failed")
+	addSyslogFlags(fs)
+	addCaseFlags(fs)
+	fs.StringVar(geoFlag, "geo", "", "Export the mail servers' locations as GeoJSON, or KML when the file ends in .kml")
+}
+
+// runEmailCommand analyzes an email address
+func runEmailCommand(args []string) {
+	fs := commandFlagSet("email")
+	parseFlags(fs, args)
+
+	emails := commandTargets(fs, input.KindEmail)
+	startScan("email", emails...)
+
+	fmt.Println("Running Email Intelligence module...")
+	for _, email := range emails {
+		runEmailIntelligence(email, *outputFlag)
+	}
+}
+
+// addPhoneCommandFlags registers the phone command's options
+func addPhoneCommandFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show network codes, fraud warnings and lookups that found nothing")
+	addSyslogFlags(fs)
+	addCaseFlags(fs)
+}
+
+// runPhoneCommand analyzes a phone number
+func runPhoneCommand(args []string) {
+	fs := commandFlagSet("phone")
+	parseFlags(fs, args)
+
+	phones := commandTargets(fs, input.KindPhone)
+	startScan("phone", phones...)
+
+	fmt.Println("Running Phone Number Intelligence module...")
+	for _, phone := range phones {
+		runPhoneNumberIntelligence(phone, *outputFlag)
+	}
+}
+
+// addGoogleIDCommandFlags registers the gid command's options
+func addGoogleIDCommandFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.IntVar(&osint.ArchiveCaptureLimit, "archive-limit", osint.ArchiveCaptureLimit, "Maximum number of Archive.org captures to keep, newest first")
+	fs.IntVar(&osint.ArchiveStatusChecks, "archive-checks", osint.ArchiveStatusChecks, "Number of most recent Archive.org captures to verify")
+	fs.StringVar(geoFlag, "geo", "", "Export the locations of Maps reviews and photos as GeoJSON, or KML when the file ends in .kml")
+	addTranslateFlags(fs)
+	addKeywordFlags(fs)
+}
+
+// runGoogleIDCommand analyzes a Google account ID
+func runGoogleIDCommand(args []string) {
+	fs := commandFlagSet("gid")
+	parseFlags(fs, args)
+
+	gids := commandTargets(fs, input.KindGoogleID)
+	loadWatchKeywords(*keywordsFlag, *keywordsFileFlag)
+	startScan("gid", gids...)
+
+	fmt.Println("Running Google ID Intelligence module...")
+	for _, gid := range gids {
+		runGoogleIDIntelligence(gid, *outputFlag)
+	}
+}
+
+// addDomainCommandFlags registers the domain command's options
+func addDomainCommandFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show lookups that failed")
+	fs.BoolVar(probePathsFlag, "probe-paths", false, "Probe well-known paths and admin panels")
+	fs.StringVar(wordlistFlag, "wordlist", "", "File of paths to probe instead of the built-in list (implies --probe-paths)")
+	fs.BoolVar(scanSourceFlag, "scan-sources", false, "Scan the homepage, its scripts and source maps for secrets, emails and social links")
+	fs.BoolVar(pivotIDsFlag, "pivot-ids", false, "Find other domains sharing the domain's analytics and AdSense IDs")
+	fs.BoolVar(takeoverFlag, "takeover", false, "Check the domain and its subdomains from certificate logs for dangling CNAME and NS records open to takeover")
+	fs.StringVar(spoofFlag, "spoof-sender", "", "Comma-separated sending IPs or domains to simulate mail from, checking by DNS alone whether it would pass SPF and DMARC as the domain")
+	fs.BoolVar(followFlag, "follow-contacts", false, "Run the email module on contacts found in security.txt and humans.txt")
+	addCaseFlags(fs)
+}
+
+// runDomainCommand analyzes a domain
+func runDomainCommand(args []string) {
+	fs := commandFlagSet("domain")
+	parseFlags(fs, args)
+
+	domains := commandTargets(fs, input.KindDomain)
+	startScan("domain", domains...)
+
+	fmt.Println("Running Domain Intelligence module...")
+	for _, domain := range domains {
+		runDomainIntelligence(domain, *outputFlag)
+	}
+}
+
+// addIPCommandFlags registers the ip command's options
+func addIPCommandFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show lookups that failed")
+	addSyslogFlags(fs)
+	addCaseFlags(fs)
+	fs.StringVar(geoFlag, "geo", "", "Export the IP's location as GeoJSON, or KML when the file ends in .kml")
+}
+
+// runIPCommand analyzes an IP address
+func runIPCommand(args []string) {
+	fs := commandFlagSet("ip")
+	parseFlags(fs, args)
+
+	ips := commandTargets(fs, input.KindIP)
+	startScan("ip", ips...)
+
+	fmt.Println("Running IP Intelligence module...")
+	for _, ip := range ips {
+		runIPIntelligence(ip, *outputFlag)
+	}
+}
+
+// accountIDKinds maps the platforms account-id accepts to their target kinds
+var accountIDKinds = map[string]string{
+	osint.AccountFacebook: input.KindFacebookID,
+	osint.AccountTwitter:  input.KindTwitterID,
+	osint.AccountReddit:   input.KindRedditID,
+}
+
+// addAccountIDCommandFlags registers the account-id command's options
+func addAccountIDCommandFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+}
+
+// runAccountIDCommand finds the account behind a platform-native ID
+func runAccountIDCommand(args []string) {
+	fs := commandFlagSet("account-id")
+	parseFlags(fs, args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	platform := strings.ToLower(fs.Arg(0))
+	kind, ok := accountIDKinds[platform]
+	if !ok {
+		color.Red("Error: account IDs can be looked up on facebook, twitter and reddit, not %q", fs.Arg(0))
+		os.Exit(1)
+	}
+	id, err := input.Target(kind, fs.Arg(1))
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	startScan("account-id", platform, id)
+
+	runAccountLookup(platform, id, *outputFlag)
+}
+
+// requireAcceptableUse stops the run unless the acceptable use notice has been
+// accepted on this machine, asking for it when attached to a terminal
+func requireAcceptableUse(accept bool) {
+	if osint.Acknowledged() {
+		return
+	}
+	if !accept {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			color.Red("Error: the acceptable use notice has not been accepted on this machine")
+			fmt.Fprintln(os.Stderr, "Run MercuriesOST once in a terminal, or pass --accept-terms to accept it non-interactively.")
+			os.Exit(1)
+		}
+		// The prompt goes to stderr, which --quiet and --format leave alone
+		if !osint.PromptAcknowledgement(os.Stdin, os.Stderr) {
+			color.Red("Error: acceptable use notice declined, exiting")
+			os.Exit(1)
+		}
+	}
+	if err := osint.Acknowledge(AppVersion); err != nil {
+		color.Yellow("Warning: could not record the acknowledgement: %v", err)
+	}
+}
+
+// refuseCanaryTargets stops the run when a target is a known canary token or
+// callback domain, since looking it up would alert whoever planted it
+func refuseCanaryTargets(targets ...string) {
+	if osint.TouchCanaries {
+		return
+	}
+	for _, target := range targets {
+		if match := osint.MatchCanary(target); match != nil {
+			color.Red("Error: %s looks like a canary (%s); scanning it would alert its owner", target, match.Name)
+			fmt.Println("Pass --touch-canaries to scan it anyway.")
+			os.Exit(1)
+		}
+	}
+}
+
+// enforcePolicy stops the run when the organizational policy endpoint denies
+// the scan or cannot be reached
+func enforcePolicy(module, target string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*osint.RequestTimeout)
+	defer cancel()
+
+	decision, err := osint.CheckPolicy(ctx, module, target)
+	if err != nil {
+		color.Red("Error: %v; the scan is not allowed without a policy decision", err)
+		os.Exit(1)
+	}
+	if !decision.Allow {
+		reason := decision.Reason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		color.Red("Scan denied by policy: %s", reason)
+		os.Exit(1)
+	}
+}
+
+// displayBanner prints the application banner
+func displayBanner() {
+	banner := `
+  __  __                          _      
+ |  \/  | ___ _ __ ___ _   _ _ __(_) ___  ___
+ | |\/| |/ _ \ '__/ __| | | | '__| |/ _ \/ __|
+ | |  | |  __/ | | (__| |_| | |  | |  __/\__ \
+ |_|  |_|\___|_|  \___|\__,_|_|  |_|\___||___/
+                                                      
+ Open Source Intelligence Tool - v` + AppVersion + `
+ "Knowledge is power, information is liberty"
+ MADE WITH LOVE BY github.com/awiones
+`
+	color.Cyan(banner)
+}
+
+// Update function signature to remove unused parameter
+func runSocialMediaIntelligence(query, outputPath string) {
+	fmt.Printf("Searching social media for: %s\n", query)
+
+	osint.ExpandHandles = *expandHandlesFlag
+	osint.CheckAccountHistory = *accountHistoryFlag
+	osint.SampleSocialGraph = *socialGraphFlag
+	osint.GraphSampleSize = *graphSampleFlag
+	osint.TimeZoneHintPhone = *tzPhoneFlag
+	osint.TimeZoneHintIP = *tzIPFlag
+	osint.GeocodeLocations = *geocodeFlag
+	osint.MinConfidence = *minConfidenceFlag
+
+	// Update function call to use verbose flag directly
+	ctx, stop := interruptContext()
+	defer stop()
+	results, err := osint.SearchProfilesSequentially(ctx, query, outputPath, *verboseFlag)
+	if errors.Is(err, osint.ErrInterrupted) {
+		color.Yellow("\nSearch interrupted, showing the profiles found so far")
+		if outputPath != "" {
+			color.Yellow("They are saved to %s", outputPath)
+		}
+	} else if err != nil {
+		color.Red("Error: %v", err)
+		emitResult("social", query, nil, err)
+		return
+	}
+
+	displaySocialResults(results)
+	exportGeo("social-media", query, results.GeoFeatures())
+	indexCase("social", query, results)
+	summarize("social", query, results)
+	writeReport("social", query, results)
+	osint.RunHook(osint.HookScanComplete, "social", results)
+	emitResult("social", query, results, nil)
+	fmt.Println("Social media intelligence gathering completed")
+}
+
+// displaySocialResults formats and displays the social media search results
+func displaySocialResults(results *osint.SocialMediaResults) {
+	color.Green("\n=== SEARCH RESULTS ===")
+	color.Yellow("Query: %s", results.Query)
+	color.Yellow("Timestamp: %s", results.Timestamp)
+	color.Yellow("Total Profiles Found: %d\n", results.ProfilesFound)
+	if results.OmittedProfiles > 0 {
+		color.Yellow("Showing the first %d; all %d are in the output file\n", len(results.Profiles), results.ProfilesFound)
+	}
+
+	// Unreachable platforms were not searched, so they are not reported as
+	// having no profile
+	unreachable := make(map[string]bool)
+	if len(results.Unreachable) > 0 {
+		color.Yellow("Platforms not searched, likely down or blocking the scan:")
+		for _, platform := range results.Unreachable {
+			unreachable[platform.Platform] = true
+			color.Yellow("  • %s - unreachable (%s)", platform.Platform, platform.Reason)
+		}
+	}
+	if results.Checkpoint != "" {
+		color.Yellow("Some checks failed or were not run; finish them with --resume %s\n", results.Checkpoint)
+	}
+	if len(results.Suppressed) > 0 {
+		color.Yellow("%d profiles on the suppression lists are hidden; the saved results keep them\n", len(results.Suppressed))
+	}
+
+	if results.ProfilesFound == 0 {
+		var searched []string
+		for _, platform := range osint.SearchedPlatforms() {
+			if !unreachable[platform] {
+				searched = append(searched, platform)
+			}
+		}
+		if len(searched) > 0 {
+			color.Red("\nNo profiles found. Searched platforms:")
+		} else {
+			color.Red("\nNo profiles found: no platform could be searched")
+		}
+		for _, platform := range searched {
+			color.Red("  • %s - No profile found", platform)
+		}
+		osint.DisplayAccountHistories(results.History)
+		displayLeads(results.Leads)
+		if results.Keywords != nil {
+			results.Keywords.DisplayResults()
+		}
+		return
+	}
+
+	// Group profiles by tier, strongest first, then by platform
+	platformProfiles := make(map[string][]osint.ProfileResult)
+	tierProfiles := make(map[string]map[string][]osint.ProfileResult)
+	for _, profile := range results.Profiles {
+		platformProfiles[profile.Platform] = append(platformProfiles[profile.Platform], profile)
+		if tierProfiles[profile.Tier] == nil {
+			tierProfiles[profile.Tier] = make(map[string][]osint.ProfileResult)
+		}
+		tierProfiles[profile.Tier][profile.Platform] = append(tierProfiles[profile.Tier][profile.Platform], profile)
+	}
+
+	// Display results for each tier and platform
+	for _, tier := range osint.Tiers {
+		byPlatform := tierProfiles[tier]
+		if len(byPlatform) == 0 {
+			continue
+		}
+		color.Green("\n=== %s ===", strings.ToUpper(tier))
+		platforms := make([]string, 0, len(byPlatform))
+		for platform := range byPlatform {
+			platforms = append(platforms, platform)
+		}
+		slices.Sort(platforms)
+		for _, platform := range platforms {
+			color.Cyan("\n[%s]", platform)
+			for _, profile := range byPlatform[platform] {
+				displayProfile(profile)
+			}
+		}
+	}
+
+	// Display summary
+	color.Green("\n=== PLATFORM SUMMARY ===")
+	for _, platform := range osint.SearchedPlatforms() {
+		if profiles, exists := platformProfiles[platform]; exists {
+			color.Green("  ✓ %s: %d profile(s) found", platform, len(profiles))
+		} else if unreachable[platform] {
+			color.Yellow("  ? %s: unreachable, not searched", platform)
+		} else {
+			color.Red("  ✗ %s: No profile found", platform)
+		}
+	}
+
+	if len(results.Locations) > 0 {
+		color.Green("\n=== LOCATIONS ===")
+		for _, cluster := range results.Locations {
+			color.White("  %s: %d profile(s)", cluster.Place, len(cluster.Profiles))
+			for _, profile := range cluster.Profiles {
+				color.White("    - %s", profile)
+			}
+		}
+	}
+
+	if results.Heatmap != nil {
+		results.Heatmap.DisplayResults()
+	}
+
+	if len(results.StyleLinks) > 0 {
+		color.Green("\n=== WRITING STYLE SIMILARITY (heuristic) ===")
+		color.Yellow("Based on a few recent posts; a lead to verify, not proof of a shared owner")
+		for _, link := range results.StyleLinks {
+			color.White("  %.2f  %s ↔ %s", link.Similarity, link.ProfileA, link.ProfileB)
+		}
+	}
+
+	if results.Graph != nil {
+		results.Graph.DisplayResults()
+		if *verboseFlag {
+			results.Graph.DisplayPartialErrors()
+		}
+	}
+
+	osint.DisplayAccountHistories(results.History)
+	displayLeads(results.Leads)
+	if results.Keywords != nil {
+		results.Keywords.DisplayResults()
+	}
+	if results.TranslationError != "" {
+		color.Yellow("\n%s", results.TranslationError)
+	}
+}
+
+// displayProfile prints one profile found by a social media search
+func displayProfile(profile osint.ProfileResult) {
+	color.Green("  Profile URL: %s (%s, confidence %.2f)", profile.URL, profile.Evidence, profile.Confidence)
+
+	if profile.FullName != "" {
+		color.White("  • Full Name: %s", profile.FullName)
+	}
+
+	if profile.Bio != "" {
+		color.White("  • Bio: %s", strings.TrimSpace(profile.Bio))
+	}
+
+	if profile.FollowerCount > 0 {
+		color.White("  • Followers: %d", profile.FollowerCount)
+	}
+
+	if profile.Location != "" {
+		if profile.Geo != nil {
+			color.White("  • Location: %s (%s)", profile.Location, profile.Geo.Label())
+		} else {
+			color.White("  • Location: %s", profile.Location)
+		}
+	}
+
+	if len(profile.RecentActivity) > 0 {
+		color.White("  • Recent Activity:")
+		for i, activity := range profile.RecentActivity[:min(3, len(profile.RecentActivity))] {
+			color.White("    %d. %s", i+1, activity)
+		}
+	}
+
+	if len(profile.Translations) > 0 {
+		color.White("  • Translations:")
+		osint.DisplayTranslations(profile.Translations)
+	}
+
+	if len(profile.Insights) > 0 {
+		color.White("  • Insights:")
+		for _, insight := range profile.Insights {
+			color.White("    - %s", insight)
+		}
+	}
+
+	color.White("  • Finding: %s", profile.FindingHash())
+	fmt.Println()
+}
+
+// displayLeads lists the profiles in the lead tier: found under an inferred
+// handle, scoring weakly or held back by --min-confidence
+func displayLeads(leads []osint.ProfileResult) {
+	if len(leads) == 0 {
+		return
+	}
+	color.Yellow("\n=== LEADS ===")
+	color.Yellow("Inferred or weak matches; check them before relying on them")
+	for _, lead := range leads {
+		color.White("  %.2f  %s: %s (%s)", lead.Confidence, lead.Platform, lead.URL, lead.Evidence)
+	}
+}
+
+// Helper function to get minimum of two integers
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func runEmailIntelligence(email, outputPath string) {
+	fmt.Printf("Analyzing email: %s\n", email)
+
+	results, err := osint.AnalyzeEmail(email)
+	if err != nil {
+		color.Red("Error analyzing email: %v", err)
+		emitResult("email", email, nil, err)
+		return
+	}
+
+	// Display results using the new method
+	results.DisplayResults()
+	if *verboseFlag {
+		results.DisplayPartialErrors()
+	}
+	forwardAlerts(*syslogFlag, *syslogFormatFlag, results.Alerts())
+	exportTheHive("email", email, results.Alerts(), results.Observables())
+	exportOpenCTI("email", results.Observables())
+	exportGeo("email", email, results.GeoFeatures())
+	indexCase("email", email, results)
+	summarize("email", email, results)
+	writeReport("email", email, results)
+	osint.RunHook(osint.HookScanComplete, "email", results)
+	emitResult("email", email, results, nil)
+
+	// Save to file if output path is specified
+	if outputPath != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(outputPath, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", outputPath)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// runDomainIntelligence handles domain intelligence lookups
+func runDomainIntelligence(domain, outputPath string) {
+	fmt.Printf("Analyzing domain: %s\n", domain)
+
+	if *wordlistFlag != "" {
+		if err := osint.LoadProbeWordlist(*wordlistFlag); err != nil {
+			color.Red("Error loading wordlist: %v", err)
+			return
+		}
+		*probePathsFlag = true
+	}
+	osint.DomainPathProbing = *probePathsFlag
+	osint.DomainSourceScan = *scanSourceFlag
+	osint.DomainIDPivot = *pivotIDsFlag
+	osint.DomainTakeoverCheck = *takeoverFlag
+	osint.SpoofSenders = nil
+	for _, sender := range strings.Split(*spoofFlag, ",") {
+		if sender = strings.TrimSpace(sender); sender != "" {
+			osint.SpoofSenders = append(osint.SpoofSenders, sender)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	results, err := osint.AnalyzeDomain(ctx, domain)
+	if err != nil {
+		color.Red("Error analyzing domain: %v", err)
+		emitResult("domain", domain, nil, err)
+		return
+	}
+
+	results.DisplayResults()
+	if *verboseFlag {
+		results.DisplayPartialErrors()
+	}
+	exportTheHive("domain", results.Domain, results.Alerts(), results.Observables())
+	exportOpenCTI("domain", results.Observables())
+	indexCase("domain", results.Domain, results)
+	summarize("domain", results.Domain, results)
+	writeReport("domain", results.Domain, results)
+	osint.RunHook(osint.HookScanComplete, "domain", results)
+	emitResult("domain", results.Domain, results, nil)
+
+	// Save to file if output path is specified
+	if outputPath != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(outputPath, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", outputPath)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+
+	// Pivot into the email module for discovered contacts
+	if *followFlag {
+		emails := results.ContactEmails()
+		if len(emails) > maxContactPivots {
+			color.Yellow("\nFollowing the first %d of %d contacts", maxContactPivots, len(emails))
+			emails = emails[:maxContactPivots]
+		}
+		for _, email := range emails {
+			if osint.SkipCanary(email) != nil {
+				continue
+			}
+			fmt.Println()
+			runEmailIntelligence(email, "")
+		}
+	}
+}
+
+// runIPIntelligence looks up location, exposure and reputation for an IP address
+func runIPIntelligence(ip, outputPath string) {
+	fmt.Printf("Analyzing IP: %s\n", ip)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	results, err := osint.AnalyzeIP(ctx, ip)
+	if err != nil {
+		color.Red("Error analyzing IP: %v", err)
+		emitResult("ip", ip, nil, err)
+		return
+	}
+
+	results.DisplayResults()
+	if *verboseFlag {
+		results.DisplayPartialErrors()
+	}
+	forwardAlerts(*syslogFlag, *syslogFormatFlag, results.Alerts())
+	exportTheHive("ip", results.IP, results.Alerts(), results.Observables())
+	exportOpenCTI("ip", results.Observables())
+	exportGeo("ip", results.IP, results.GeoFeatures())
+	indexCase("ip", results.IP, results)
+	summarize("ip", results.IP, results)
+	osint.RunHook(osint.HookScanComplete, "ip", results)
+	emitResult("ip", results.IP, results, nil)
+
+	if outputPath != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(outputPath, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", outputPath)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// Add new function to handle Google ID intelligence
+func runGoogleIDIntelligence(gid string, outputPath string) {
+	fmt.Printf("Analyzing Google ID: %s\n", gid)
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Run the Google ID analysis
+	results, err := osint.AnalyzeGoogleID(ctx, gid)
+	if err != nil {
+		color.Red("Error analyzing Google ID: %v", err)
+		emitResult("gid", gid, nil, err)
+		return
+	}
+
+	// Display results
+	results.DisplayResults()
+	if results.Keywords != nil {
+		results.Keywords.DisplayResults()
+	}
+	exportGeo("gid", gid, results.GeoFeatures())
+	indexCase("gid", gid, results)
+	summarize("gid", gid, results)
+	writeReport("gid", gid, results)
+	osint.RunHook(osint.HookScanComplete, "gid", results)
+	emitResult("gid", gid, results, nil)
+
+	// Save to file if output path is specified
+	if outputPath != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(outputPath, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", outputPath)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// Add this new function
+func runPhoneNumberIntelligence(phone string, outputPath string) {
+	fmt.Printf("Analyzing phone number: %s\n", phone)
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Run the phone number analysis
+	results, err := osint.AnalyzePhoneNumber(ctx, phone)
+	if err != nil {
+		color.Red("Error analyzing phone number: %v", err)
+		emitResult("phone", phone, nil, err)
+		return
+	}
+
+	// Display header
+	color.Cyan("\n=====================================")
+	color.Cyan(" PHONE NUMBER INTELLIGENCE REPORT")
+	color.Cyan("=====================================\n")
+
+	// Display results with improved formatting
+	results.DisplayResults()
+	if *verboseFlag {
+		results.DisplayDetails()
+	}
+
+	// Display summary footer
+	color.Cyan("\n=== ANALYSIS SUMMARY ===")
+
+	// Risk level indicator
+	switch results.RiskAssessment.Level {
+	case "Low":
+		color.Green("Risk Level: %s (%d/100)", results.RiskAssessment.Level, results.RiskAssessment.Score)
+	case "Medium":
+		color.Yellow("Risk Level: %s (%d/100)", results.RiskAssessment.Level, results.RiskAssessment.Score)
+	case "High":
+		color.Red("Risk Level: %s (%d/100)", results.RiskAssessment.Level, results.RiskAssessment.Score)
+	}
+
+	// Carrier status
+	if results.Carrier.Name != "Unknown Carrier" {
+		color.Green("Carrier: Identified (%s)", results.Carrier.Name)
+	} else {
+		color.Yellow("Carrier: Unknown")
+	}
+
+	// Format validity
+	if results.ValidationInfo.IsValid {
+		color.Green("Format: Valid")
+	} else {
+		color.Red("Format: Invalid")
+	}
+
+	// Spam likelihood
+	switch strings.ToLower(results.RiskAssessment.SpamLikelihood) {
+	case "low":
+		color.Green("Spam Likelihood: %s", results.RiskAssessment.SpamLikelihood)
+	case "medium":
+		color.Yellow("Spam Likelihood: %s", results.RiskAssessment.SpamLikelihood)
+	case "high":
+		color.Red("Spam Likelihood: %s", results.RiskAssessment.SpamLikelihood)
+	}
+
+	// Online presence summary
+	if len(results.OnlinePresence) > 0 {
+		color.Green("Online Presence: Found on %d platforms", len(results.OnlinePresence))
+	} else {
+		color.Yellow("Online Presence: No traces found")
+	}
+
+	forwardAlerts(*syslogFlag, *syslogFormatFlag, results.Alerts())
+	exportTheHive("phone", results.E164Format, results.Alerts(), results.Observables())
+	exportOpenCTI("phone", results.Observables())
+	indexCase("phone", results.E164Format, results)
+	summarize("phone", results.E164Format, results)
+	writeReport("phone", results.E164Format, results)
+	osint.RunHook(osint.HookScanComplete, "phone", results)
+	emitResult("phone", results.E164Format, results, nil)
+
+	// Save to file if output path is specified
+	if outputPath != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(outputPath, data, 0644); err == nil {
+				color.Green("\nDetailed results saved to: %s", outputPath)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
 		}
 	}
 
 	// Display footer
 	color.Cyan("\n=====================================")
 }
+
+// runAccountLookup finds the account behind a platform-native ID
+func runAccountLookup(platform, id, outputPath string) {
+	fmt.Printf("Looking up %s account ID: %s\n", platform, id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	results, err := osint.LookupAccountID(ctx, platform, id)
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+
+	results.DisplayResults()
+	results.DisplayPartialErrors()
+	indexCase("account-id", platform+" "+id, results)
+	summarize("account-id", platform+" "+id, results)
+	osint.RunHook(osint.HookScanComplete, "account-id", results)
+
+	// Save to file if output path is specified
+	if outputPath != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(outputPath, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", outputPath)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// runSecrets lists, stores and deletes the API keys kept in the secret store
+func runSecrets(args []string) {
+	fs := commandFlagSet("secrets")
+	parseFlags(fs, args)
+
+	action := fs.Arg(0)
+	switch {
+	case action == "list" && fs.NArg() == 1:
+		names, store, err := osint.StoredSecrets()
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		color.Green("Secrets in the %s:", store)
+		for _, name := range names {
+			color.Cyan("  %s", name)
+		}
+		if len(names) == 0 {
+			color.Yellow("  none yet; add one with 'mercuries secrets set <name>'")
+		}
+
+	case action == "set" && fs.NArg() == 2:
+		value, err := readSecret(fmt.Sprintf("Value of %s: ", fs.Arg(1)))
+		if err == nil && value == "" {
+			err = fmt.Errorf("empty value; use 'mercuries secrets delete %s' to remove it", fs.Arg(1))
+		}
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		store, err := osint.SetSecret(fs.Arg(1), value)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		color.Green("Stored %s in the %s", fs.Arg(1), store)
+
+	case action == "delete" && fs.NArg() == 2:
+		store, err := osint.SetSecret(fs.Arg(1), "")
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		color.Green("Deleted %s from the %s", fs.Arg(1), store)
+
+	default:
+		fs.Usage()
+		fmt.Fprintf(fs.Output(), "\nSecret names: %s\n", strings.Join(osint.SecretNames(), ", "))
+		os.Exit(1)
+	}
+}
+
+// readSecret reads a value from the terminal without echoing it, or a line
+// from piped input
+func readSecret(prompt string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		return strings.TrimSpace(line), nil
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	value, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	return strings.TrimSpace(string(value)), err
+}
+
+// promptSecretsPassphrase asks for the passphrase of the encrypted secrets
+// file when $MERCURIES_SECRETS_PASSPHRASE is unset
+func promptSecretsPassphrase() (string, error) {
+	if passphrase := os.Getenv("MERCURIES_SECRETS_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("set $MERCURIES_SECRETS_PASSPHRASE to unlock %s", osint.SecretsFile)
+	}
+	return readSecret("Passphrase for " + osint.SecretsFile + ": ")
+}
+
+// updateDataSummary describes update-data, which cannot run in builds
+// without a pinned update key
+func updateDataSummary() string {
+	if !datasets.UpdatesAvailable() {
+		return "Unavailable in this build: dataset updates need an update key, which the maintainers have not pinned yet"
+	}
+	return "Download signed dataset updates"
+}
+
+// addUpdateDataFlags registers the update-data command's options
+func addUpdateDataFlags(fs *flag.FlagSet) {
+	fs.StringVar(urlFlag, "url", datasets.UpdateURL, "Base URL of the dataset update channel")
+	fs.StringVar(dirFlag, "dir", datasets.OverrideDir, "Directory updated datasets are written to")
+}
+
+// runUpdateData downloads signed dataset updates into the override directory
+func runUpdateData(args []string) {
+	fs := commandFlagSet("update-data")
+	parseFlags(fs, args)
+
+	if !datasets.UpdatesAvailable() {
+		color.Red("Error: dataset updates are unavailable: %v", datasets.ErrNoUpdateKey)
+		fmt.Println("The embedded datasets stay in use. See public/assets/datasets/FORMAT.md for how an update key is pinned.")
+		os.Exit(1)
+	}
+
+	datasets.UpdateURL = *urlFlag
+	datasets.OverrideDir = *dirFlag
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	fmt.Printf("Updating datasets from %s\n", datasets.UpdateURL)
+	results, err := datasets.Update(ctx, fs.Args())
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+
+	updated, failed := 0, 0
+	for _, result := range results {
+		switch {
+		case result.Updated:
+			updated++
+			color.Green("✓ %s (version %d)", result.Name, result.Version)
+		case result.Skipped:
+			color.White("• %s is up to date", result.Name)
+		default:
+			failed++
+			color.Red("✗ %s: %v", result.Name, result.Error)
+		}
+	}
+
+	fmt.Printf("\n%d of %d datasets updated in %s\n", updated, len(results), datasets.OverrideDir)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// addHeaderAnalysisFlags registers the header command's options
+func addHeaderAnalysisFlags(fs *flag.FlagSet) {
+	fs.StringVar(fileFlag, "file", "", "Message (.eml) or pasted headers to analyze, - for stdin")
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show lookups that failed")
+	fs.StringVar(geoFlag, "geo", "", "Export the relays' locations as GeoJSON, or KML when the file ends in .kml")
+}
+
+// runHeaderAnalysis traces an email's route and authentication from its headers
+func runHeaderAnalysis(args []string) {
+	fs := commandFlagSet("header")
+	parseFlags(fs, args)
+
+	if *fileFlag == "" {
+		color.Red("Error: --file is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+	startScan("header", *fileFlag)
+
+	input := os.Stdin
+	if *fileFlag != "-" {
+		file, err := os.Open(*fileFlag)
+		if err != nil {
+			color.Red("Error opening message: %v", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	results, err := osint.AnalyzeHeaders(ctx, input)
+	if err != nil {
+		color.Red("Error analyzing headers: %v", err)
+		os.Exit(1)
+	}
+
+	results.DisplayResults()
+	if *verboseFlag {
+		results.DisplayPartialErrors()
+	}
+	exportGeo("header", results.MessageID, results.GeoFeatures())
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// addURLTriageFlags registers the triage command's options
+func addURLTriageFlags(fs *flag.FlagSet) {
+	fs.StringVar(urlFlag, "url", "", "Link to triage")
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show checks that failed")
+	fs.BoolVar(submitFlag, "submit", false, "Submit the link to urlscan.io for a new scan (needs an API key)")
+	fs.StringVar(visibilityFlag, "visibility", osint.URLScanVisibility, "urlscan.io scan visibility: public, unlisted or private")
+	fs.StringVar(artifactsFlag, "artifacts", "", "Directory to save the urlscan.io screenshot and DOM to")
+}
+
+// runURLTriage assesses a suspicious link without opening it in a browser
+func runURLTriage(args []string) {
+	fs := commandFlagSet("triage")
+	parseFlags(fs, args)
+
+	osint.URLScanSubmit = *submitFlag || *artifactsFlag != ""
+	osint.URLScanVisibility = *visibilityFlag
+
+	if *urlFlag == "" {
+		color.Red("Error: --url is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+	startScan("triage", *urlFlag)
+
+	fmt.Printf("Triaging URL: %s\n", *urlFlag)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	results, err := osint.TriageURL(ctx, *urlFlag)
+	if err != nil {
+		color.Red("Error triaging URL: %v", err)
+		os.Exit(1)
+	}
+
+	if *artifactsFlag != "" && results.URLScan != nil && results.URLScan.Finished {
+		if err := results.URLScan.SaveArtifacts(ctx, *artifactsFlag); err != nil {
+			color.Red("Error saving urlscan.io artifacts: %v", err)
+		}
+	}
+
+	results.DisplayResults()
+	if *verboseFlag {
+		results.DisplayPartialErrors()
+	}
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// addBucketScanFlags registers the buckets command's options
+func addBucketScanFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show providers whose probes failed")
+	addSyslogFlags(fs)
+}
+
+// runBucketScan probes cloud storage for buckets named after a target,
+// reporting the ones anyone can list
+func runBucketScan(args []string) {
+	fs := commandFlagSet("buckets")
+	parseFlags(fs, args)
+
+	target := commandTarget(fs, input.KindName)
+	startScan("buckets", target)
+
+	fmt.Printf("Looking for cloud buckets named after: %s\n", target)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	results, err := osint.FindBuckets(ctx, target)
+	if results == nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		color.Yellow("Scan stopped early: %v", err)
+	}
+
+	results.DisplayResults()
+	if *verboseFlag {
+		results.DisplayPartialErrors()
+	}
+	forwardAlerts(*syslogFlag, *syslogFormatFlag, results.Alerts())
+	indexCase(fs.Name(), target, results)
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// addContainerScanFlags registers the containers command's options
+func addContainerScanFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show registries and images that could not be read")
+	addSyslogFlags(fs)
+}
+
+// runContainerScan inspects the public container images of a user or
+// organization
+func runContainerScan(args []string) {
+	fs := commandFlagSet("containers")
+	parseFlags(fs, args)
+
+	target := commandTarget(fs, input.KindUsername)
+	startScan("containers", target)
+
+	fmt.Printf("Looking for container images published by: %s\n", target)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	results, err := osint.FindContainerImages(ctx, target)
+	if results == nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		color.Yellow("Scan stopped early: %v", err)
+	}
+
+	results.DisplayResults()
+	if *verboseFlag {
+		results.DisplayPartialErrors()
+	}
+	forwardAlerts(*syslogFlag, *syslogFormatFlag, results.Alerts())
+	indexCase(fs.Name(), target, results)
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// addRepoScanFlags registers the repos command's options
+func addRepoScanFlags(fs *flag.FlagSet) {
+	fs.IntVar(countFlag, "repos", osint.DefaultRepoCount, fmt.Sprintf("Number of most recently pushed repositories to scan, forks aside (at most %d)", osint.MaxRepoCount))
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show repositories that could not be read in full")
+	addSyslogFlags(fs)
+}
+
+// runRepoScan searches a GitHub organization's or user's repositories for
+// credentials and collects the email addresses of their contributors
+func runRepoScan(args []string) {
+	fs := commandFlagSet("repos")
+	parseFlags(fs, args)
+
+	target := commandTarget(fs, input.KindUsername)
+	startScan("repos", target)
+
+	fmt.Printf("Scanning the GitHub repositories of: %s\n", target)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	defer cancel()
+
+	results, err := osint.ScanRepos(ctx, target, *countFlag)
+	if results == nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		color.Yellow("Scan stopped early: %v", err)
+	}
+
+	results.DisplayResults()
+	if *verboseFlag {
+		results.DisplayPartialErrors()
+	}
+	forwardAlerts(*syslogFlag, *syslogFormatFlag, results.Alerts())
+	indexCase(fs.Name(), target, results)
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// addPackageScanFlags registers the packages command's options
+func addPackageScanFlags(fs *flag.FlagSet) {
+	fs.IntVar(countFlag, "packages", osint.DefaultPackageCount, fmt.Sprintf("Number of packages to read (at most %d)", osint.MaxPackageCount))
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show packages and GitHub owners that could not be read")
+}
+
+// runPackageScan lists a developer's npm packages and co-maintainers and the
+// identities their handles and emails form
+func runPackageScan(args []string) {
+	fs := commandFlagSet("packages")
+	parseFlags(fs, args)
+
+	target := commandTarget(fs, input.KindUsername)
+	startScan("packages", target)
+
+	fmt.Printf("Reading the npm packages of: %s\n", target)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	results, err := osint.FindPackageMaintainers(ctx, target, *countFlag)
+	if results == nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		color.Yellow("Scan stopped early: %v", err)
+	}
+
+	results.DisplayResults()
+	if *verboseFlag {
+		results.DisplayPartialErrors()
+	}
+	indexCase(fs.Name(), target, results)
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// addURLExpandFlags registers the expand command's options
+func addURLExpandFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+}
+
+// runURLExpand prints every hop behind a shortened or redirecting link
+func runURLExpand(args []string) {
+	fs := commandFlagSet("expand")
+	parseFlags(fs, args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	startScan("expand", fs.Args()...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	var expansions []*osint.URLExpansion
+	failed := false
+	for _, link := range fs.Args() {
+		expansion, err := osint.ExpandURL(ctx, link)
+		if expansion != nil {
+			expansion.DisplayResults()
+			expansions = append(expansions, expansion)
+		}
+		if err != nil {
+			color.Red("Error expanding %s: %v", link, err)
+			failed = true
+		}
+	}
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(expansions, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// addResolveProfileFlags registers the resolve command's options
+func addResolveProfileFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+}
+
+// runResolveProfile resolves vanity and alias profile URLs to the platform's
+// own account IDs
+func runResolveProfile(args []string) {
+	fs := commandFlagSet("resolve")
+	parseFlags(fs, args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	startScan("resolve", fs.Args()...)
+
+	var profiles []*osint.ProfileResult
+	failed := false
+	for _, link := range fs.Args() {
+		profile, err := osint.ResolveProfileURL(link)
+		if err != nil {
+			color.Red("Error resolving %s: %v", link, err)
+			failed = true
+			continue
+		}
+		profile.DisplayResults()
+		profiles = append(profiles, profile)
+	}
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(profiles, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// addDecodeIDFlags registers the decode-id command's options
+func addDecodeIDFlags(fs *flag.FlagSet) {
+	fs.StringVar(typeFlag, "type", "", "ID type (twitter, discord, instagram, tiktok, mastodon, ulid, uuid, objectid, ksuid); all when empty")
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+}
+
+// runDecodeID prints the creation times embedded in snowflakes, ULIDs, UUIDs
+// and similar identifiers
+func runDecodeID(args []string) {
+	fs := commandFlagSet("decode-id")
+	parseFlags(fs, args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	var decoded []osint.DecodedID
+	failed := false
+	for _, id := range fs.Args() {
+		results, err := osint.DecodeID(id, *typeFlag)
+		if err != nil {
+			color.Red("Error decoding %s: %v", id, err)
+			failed = true
+			continue
+		}
+		color.Cyan("\n%s", id)
+		osint.DisplayDecodedIDs(results)
+		decoded = append(decoded, results...)
+	}
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(decoded, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// addHashLookupFlags registers the hash command's options
+func addHashLookupFlags(fs *flag.FlagSet) {
+	fs.StringVar(valueFlag, "value", "", "Hash to identify and look up")
+	fs.StringVar(candidatesFlag, "candidates", "", "File of known emails and usernames, one per line, to hash and compare")
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show lookups that failed")
+	addCaseFlags(fs)
+}
+
+// runHashLookup identifies a hash and recovers the email or identity behind
+// it when it is not a password hash
+func runHashLookup(args []string) {
+	fs := commandFlagSet("hash")
+	parseFlags(fs, args)
+
+	if *valueFlag == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+	startScan("hash", *valueFlag)
+
+	var candidates []string
+	if *candidatesFlag != "" {
+		data, err := os.ReadFile(*candidatesFlag)
+		if err != nil {
+			color.Red("Error reading candidates: %v", err)
+			os.Exit(1)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				candidates = append(candidates, line)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	results, err := osint.AnalyzeHash(ctx, *valueFlag, candidates)
+	if err != nil {
+		color.Red("Error analyzing hash: %v", err)
+		os.Exit(1)
+	}
+
+	results.DisplayResults()
+	if *verboseFlag {
+		results.DisplayPartialErrors()
+	}
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+
+	if !results.Sensitive {
+		exportTheHive("hash", results.Hash, nil, results.Observables())
+		exportOpenCTI("hash", results.Observables())
+	}
+}
+
+// addEmailCompareFlags registers the email-compare command's options
+func addEmailCompareFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show lookups that failed")
+}
+
+// runEmailCompare analyzes two email addresses and reports the signals they
+// share
+func runEmailCompare(args []string) {
+	fs := commandFlagSet("email-compare")
+	parseFlags(fs, args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	emails := make([]string, 2)
+	for i, value := range fs.Args() {
+		email, err := input.Email(value)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		emails[i] = email
+	}
+	startScan("email-compare", emails...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	fmt.Printf("Comparing %s and %s...\n", emails[0], emails[1])
+	results, err := osint.CompareEmails(ctx, emails[0], emails[1])
+	if err != nil {
+		color.Red("Error comparing emails: %v", err)
+		os.Exit(1)
+	}
+
+	results.DisplayResults()
+	if *verboseFlag {
+		results.DisplayPartialErrors()
+	}
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// addAliasClusterFlags registers the cluster command's options
+func addAliasClusterFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.Float64Var(minLinkFlag, "min-link", osint.MinAliasLink, "Weakest evidence (0-1) that joins two identifiers into one cluster")
+	fs.BoolVar(gravatarFlag, "gravatar", false, "Look up every email on Gravatar to link it to handles (one request per email)")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show lookups that failed")
+}
+
+// runAliasCluster groups a file of mixed identifiers into probable identities
+func runAliasCluster(args []string) {
+	fs := commandFlagSet("cluster")
+	parseFlags(fs, args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	osint.MinAliasLink = *minLinkFlag
+
+	source := fs.Arg(0)
+	startScan("cluster", source)
+	var data []byte
+	var err error
+	if source == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		color.Red("Error reading identifiers: %v", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	results, err := osint.ClusterAliases(ctx, source, strings.Split(string(data), "\n"), *gravatarFlag)
+	if err != nil {
+		color.Red("Error clustering identifiers: %v", err)
+		os.Exit(1)
+	}
+
+	results.DisplayResults()
+	if *verboseFlag {
+		results.DisplayPartialErrors()
+	}
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// addAccountHistoryFlags registers the history command's options
+func addAccountHistoryFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	addKeywordFlags(fs)
+	fs.BoolVar(verboseFlag, "verbose", false, "Show lookups that failed")
+}
+
+// runAccountHistory checks whether a GitHub or Reddit handle belonged to an
+// account that was deleted, renamed or suspended
+func runAccountHistory(args []string) {
+	fs := commandFlagSet("history")
+	parseFlags(fs, args)
+	loadWatchKeywords(*keywordsFlag, *keywordsFileFlag)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	platform := fs.Arg(0)
+	handle, err := input.Handle(fs.Arg(1))
+	if err == nil {
+		err = input.PlatformHandle(platform, handle)
+	}
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	startScan("history", platform, handle)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	fmt.Printf("Checking the history of %s on %s...\n", handle, platform)
+	results, err := osint.LookupAccountHistory(ctx, platform, handle)
+	if err != nil {
+		color.Red("Error checking account history: %v", err)
+		os.Exit(1)
+	}
+
+	results.DisplayResults()
+	if results.Keywords != nil {
+		results.Keywords.DisplayResults()
+	}
+	if *verboseFlag {
+		results.DisplayPartialErrors()
+	}
+	indexCase("history", platform+" "+handle, results)
+	summarize("history", platform+" "+handle, results)
+	osint.RunHook(osint.HookScanComplete, "history", results)
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// addSchedulingScanFlags registers the scheduling command's options
+func addSchedulingScanFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show pages that could not be checked")
+}
+
+// runSchedulingScan looks for public booking pages named after a person or
+// handle, which often confirm an identity and its timezone
+func runSchedulingScan(args []string) {
+	fs := commandFlagSet("scheduling")
+	parseFlags(fs, args)
+
+	target := commandTarget(fs, input.KindName)
+	startScan("scheduling", target)
+
+	fmt.Printf("Looking for scheduling pages for: %s\n", target)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	results, err := osint.FindSchedulingPages(ctx, target)
+	if results == nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		color.Yellow("Scan stopped early: %v", err)
+	}
+
+	results.DisplayResults()
+	if *verboseFlag {
+		results.DisplayPartialErrors()
+	}
+	indexCase(fs.Name(), target, results)
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// addAmazonScanFlags registers the amazon command's options
+func addAmazonScanFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show lookups that failed")
+}
+
+// runAmazonScan looks for public Amazon lists and storefronts of a person
+func runAmazonScan(args []string) {
+	fs := commandFlagSet("amazon")
+	parseFlags(fs, args)
+
+	target := commandTarget(fs, input.KindName)
+	startScan("amazon", target)
+
+	fmt.Printf("Looking for Amazon lists of: %s\n", target)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	results, err := osint.FindAmazonLists(ctx, target)
+	if err != nil {
+		color.Yellow("Scan stopped early: %v", err)
+	}
+
+	results.DisplayResults()
+	if *verboseFlag {
+		results.DisplayPartialErrors()
+	}
+	indexCase(fs.Name(), target, results)
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// addBenchFlags registers the bench command's options
+func addBenchFlags(fs *flag.FlagSet) {
+	fs.StringVar(queryFlag, "query", "Jane Doe", "Name whose variations are scanned; full names give more terms")
+	fs.IntVar(benchPlatformsFlag, "platforms", 7, "Number of synthetic platforms")
+	fs.DurationVar(latencyFlag, "latency", 50*time.Millisecond, "Mock server response delay")
+	fs.Float64Var(hitRateFlag, "hit-rate", 0.2, "Share of profiles that exist (0-1)")
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+	addScanTuningFlags(fs)
+}
+
+// runBench measures the social media scanning engine against a local mock
+// server
+func runBench(args []string) {
+	fs := commandFlagSet("bench")
+	parseFlags(fs, args)
+
+	results, err := osint.BenchmarkScan(osint.BenchOptions{
+		Query:     *queryFlag,
+		Platforms: *benchPlatformsFlag,
+		Latency:   *latencyFlag,
+		HitRate:   *hitRateFlag,
+	})
+	if err != nil {
+		color.Red("Error running benchmark: %v", err)
+		os.Exit(1)
+	}
+
+	results.DisplayResults()
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// watchlistUsage describes the watchlist subcommand's actions
+const watchlistUsage = `usage: mercuries watchlist [--dir dir] [--output file] <action> ...
+  add <name> <brand|person|domain> <value>   register an item
+  remove <name> <value>                      drop an item and its findings
+  list [name]                                show watchlists or one watchlist's items
+  run <name>                                 check every item and report new findings
+  feed <name>                                print the findings feed, or write it with --feed`
+
+// addWatchlistFlags registers the watchlist command's options
+func addWatchlistFlags(fs *flag.FlagSet) {
+	fs.StringVar(dirFlag, "dir", osint.WatchlistDir, "Directory watchlists are stored in")
+	fs.StringVar(outputFlag, "output", "", "Output file path for run results")
+	fs.StringVar(feedFlag, "feed", "", "Write the findings feed to this file (run and feed actions)")
+	fs.StringVar(feedFormatFlag, "feed-format", osint.FeedAtom, "Feed format: atom or rss")
+	fs.StringVar(syslogFlag, "syslog", "", "Forward new findings to a syslog collector (udp://host:514 or tcp://host:6514)")
+	fs.StringVar(syslogFormatFlag, "syslog-format", osint.SyslogRFC5424, "Syslog payload format: rfc5424, cef or leef")
+}
+
+// runWatchlist manages brand, person and domain watchlists and runs them
+func runWatchlist(args []string) {
+	fs := commandFlagSet("watchlist")
+	parseFlags(fs, args)
+
+	osint.WatchlistDir = *dirFlag
+	rest := fs.Args()
+	if len(rest) == 0 {
+		color.Red("Error: %s", watchlistUsage)
+		os.Exit(1)
+	}
+	startScan("watchlist", rest...)
+
+	action, rest := rest[0], rest[1:]
+	if action == "list" && len(rest) == 0 {
+		names, err := osint.ListWatchlists()
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		if len(names) == 0 {
+			color.Yellow("No watchlists in %s", osint.WatchlistDir)
+		}
+		for _, name := range names {
+			color.White("• %s", name)
+		}
+		return
+	}
+
+	if len(rest) == 0 {
+		color.Red("Error: %s", watchlistUsage)
+		os.Exit(1)
+	}
+	list, err := osint.LoadWatchlist(rest[0])
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+
+	switch {
+	case action == "add" && len(rest) == 3:
+		if err := list.Add(rest[1], rest[2]); err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		color.Green("Added %s %q to watchlist %s", rest[1], rest[2], list.Name)
+
+	case action == "remove" && len(rest) == 2:
+		if !list.Remove(rest[1]) {
+			color.Red("Error: %q is not on watchlist %s", rest[1], list.Name)
+			os.Exit(1)
+		}
+		color.Green("Removed %q from watchlist %s", rest[1], list.Name)
+
+	case action == "list" && len(rest) == 1:
+		color.Cyan("Watchlist %s", list.Name)
+		for _, item := range list.Items {
+			lastRun := item.LastRun
+			if lastRun == "" {
+				lastRun = "never"
+			}
+			color.White("• %s: %s (%d findings, last run %s)", item.Kind, item.Value, len(item.Findings), lastRun)
+		}
+		return
+
+	case action == "feed" && len(rest) == 1:
+		if *feedFlag != "" {
+			if err := list.WriteFeed(*feedFlag, *feedFormatFlag); err != nil {
+				color.Red("Error writing feed: %v", err)
+				os.Exit(1)
+			}
+			color.Green("Feed written to: %s", *feedFlag)
+			return
+		}
+		data, err := list.Feed(*feedFormatFlag)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(data)
+		return
+
+	case action == "run" && len(rest) == 1:
+		if len(list.Items) == 0 {
+			color.Red("Error: watchlist %s has no items", list.Name)
+			os.Exit(1)
+		}
+		fmt.Printf("Running watchlist %s (%d items)\n", list.Name, len(list.Items))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(len(list.Items))*5*time.Minute)
+		defer cancel()
+
+		results := list.Run(ctx)
+		results.DisplayResults()
+		forwardAlerts(*syslogFlag, *syslogFormatFlag, results.Alerts())
+
+		if *outputFlag != "" {
+			if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+				if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+					color.Green("\nResults saved to: %s", *outputFlag)
+				} else {
+					color.Red("Error saving results: %v", err)
+				}
+			} else {
+				color.Red("Error encoding results: %v", err)
+			}
+		}
+
+		if *feedFlag != "" {
+			if err := list.WriteFeed(*feedFlag, *feedFormatFlag); err == nil {
+				color.Green("Feed written to: %s", *feedFlag)
+			} else {
+				color.Red("Error writing feed: %v", err)
+			}
+		}
+
+	default:
+		color.Red("Error: %s", watchlistUsage)
+		os.Exit(1)
+	}
+
+	if err := list.Save(); err != nil {
+		color.Red("Error saving watchlist: %v", err)
+		os.Exit(1)
+	}
+}
+
+// addWatchFlags registers the watch command's options
+func addWatchFlags(fs *flag.FlagSet) {
+	fs.StringVar(dirFlag, "dir", osint.WatchlistDir, "Directory the findings of earlier runs are stored in, under scans/")
+	fs.BoolVar(onceFlag, "once", false, "Run the scan once now instead of waiting for its schedule")
+	fs.StringVar(syslogFlag, "syslog", "", "Forward new findings to a syslog collector (udp://host:514 or tcp://host:6514)")
+	fs.StringVar(syslogFormatFlag, "syslog-format", osint.SyslogRFC5424, "Syslog payload format: rfc5424, cef or leef")
+}
+
+// runWatch re-runs a saved scan definition each time its cron schedule is
+// due, alerting on the profiles, breaches and archives earlier runs had not
+// seen
+func runWatch(args []string) {
+	fs := commandFlagSet("watch")
+	parseFlags(fs, args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	osint.WatchlistDir = *dirFlag
+	def, err := osint.LoadWatchDefinition(fs.Arg(0))
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	startScan("watch", def.Seed)
+
+	report := func(results *osint.WatchRunResult, err error) {
+		if err != nil {
+			color.Red("Error running %s: %v", def.Name, err)
+			return
+		}
+		results.DisplayResults()
+		forwardAlerts(*syslogFlag, *syslogFormatFlag, results.Alerts())
+	}
+
+	if *onceFlag {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+		results, err := def.Run(ctx)
+		report(results, err)
+		if err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	color.Green("Watching %s (%s), next run %s", def.Name, def.Schedule, def.Next(time.Now()).Format(time.RFC1123))
+	def.Watch(ctx, func(results *osint.WatchRunResult, err error) {
+		report(results, err)
+		color.Green("Next run of %s: %s", def.Name, def.Next(time.Now()).Format(time.RFC1123))
+	})
+	color.Yellow("Stopped watching %s", def.Name)
+}
+
+// addCustomModuleFlags registers the custom command's options
+func addCustomModuleFlags(fs *flag.FlagSet) {
+	fs.BoolVar(listFlag, "list", false, "List the custom modules and any that fail to load")
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+}
+
+// runCustomModule runs a module defined in a YAML file in the modules
+// directory
+func runCustomModule(args []string) {
+	fs := commandFlagSet("custom")
+	parseFlags(fs, args)
+
+	if *listFlag {
+		modules, errs := osint.LoadCustomModules()
+		color.Green("Custom modules in %s:", osint.ModulesDir)
+		for _, module := range modules {
+			color.Cyan("  %-20s %-10s %s", module.Name, module.Target, module.Description)
+		}
+		for _, err := range errs {
+			color.Red("  %v", err)
+		}
+		if len(modules) == 0 && len(errs) == 0 {
+			color.Yellow("  none yet; add a .yaml file there")
+		}
+		return
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	module, err := osint.LoadCustomModule(fs.Arg(0))
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	target, err := input.Target(module.Target, fs.Arg(1))
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	startScan("custom", target)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	results, err := module.Run(ctx, target)
+	if err != nil {
+		color.Red("Error running module %s: %v", module.Name, err)
+		os.Exit(1)
+	}
+
+	results.DisplayResults()
+	indexCase("custom", module.Name+" "+target, results)
+	summarize("custom", module.Name+" "+target, results)
+	osint.RunHook(osint.HookScanComplete, "custom", results)
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+const caseUsage = `usage: mercuries case [--investigator name] [--notes text] <action> ...
+
+actions:
+  create <name>   Start a case with --investigator and --notes and open it
+  open <name>     File the results of later runs in the case, and list those it holds
+  close           Stop filing runs in the open case
+  list            List the cases, marking the open one
+  merge <dirA> <dirB>
+                  Merge two case directories into a new case named with --into,
+                  recording who found each finding`
+
+// addCaseCommandFlags registers the case command's options
+func addCaseCommandFlags(fs *flag.FlagSet) {
+	fs.StringVar(investigatorFlag, "investigator", global.authorizedBy, "Who runs the investigation (create; default --authorized-by)")
+	fs.StringVar(notesFlag, "notes", "", "What the investigation is about (create)")
+	fs.StringVar(intoFlag, "into", "", "Name of the case the merge creates (merge; default: the first case's name with -merged)")
+}
+
+// runCase creates, opens and lists the cases runs are filed in
+func runCase(args []string) {
+	fs := commandFlagSet("case")
+	parseFlags(fs, args)
+
+	action := fs.Arg(0)
+	switch {
+	case action == "create" && fs.NArg() == 2:
+		info, err := osint.CreateCase(fs.Arg(1), *investigatorFlag, *notesFlag)
+		if err == nil {
+			info, err = osint.OpenCase(info.Name)
+		}
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		color.Green("Case %s created in %s and opened; later runs are filed in it", info.Name, osint.CaseDir(info.Name))
+	case action == "open" && fs.NArg() == 2:
+		info, err := osint.OpenCase(fs.Arg(1))
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		results, err := info.Results()
+		if err != nil {
+			color.Yellow("Warning: listing the case's results: %v", err)
+		}
+		info.Display(results)
+		if annotations, err := osint.LoadCaseAnnotations(info.Name); err != nil {
+			color.Yellow("Warning: reading the case's annotations: %v", err)
+		} else if len(annotations.Findings) > 0 {
+			index, _ := osint.OpenCaseIndex(info.Name)
+			annotations.DisplayResults(index)
+		}
+		color.Green("\nCase %s is open; later runs are filed in it", info.Name)
+	case action == "close" && fs.NArg() == 1:
+		active := osint.ActiveCase()
+		if err := osint.CloseCase(); err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		if active == "" {
+			color.Yellow("No case is open")
+		} else {
+			color.Green("Case %s closed", active)
+		}
+	case action == "list" && fs.NArg() == 1:
+		cases, err := osint.ListCases()
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		osint.DisplayCases(cases, osint.ActiveCase())
+	case action == "merge" && fs.NArg() == 3:
+		merge, err := osint.MergeCases(*intoFlag, fs.Args()[1:])
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		merge.DisplayResults()
+		merge.DisplayPartialErrors()
+		color.Green("\nCases merged into %s; open it with 'mercuries case open %s'", osint.CaseDir(merge.Case), merge.Case)
+	default:
+		color.Red("Error: %s", caseUsage)
+		os.Exit(1)
+	}
+}
+
+// addCaseSearchFlags registers the search command's options
+func addCaseSearchFlags(fs *flag.FlagSet) {
+	fs.IntVar(limitFlag, "limit", 20, "Most matches shown, best first; 0 shows all")
+	fs.StringVar(outputFlag, "output", "", "Output file path")
+}
+
+// runCaseSearch searches the text indexed into a case with --case
+func runCaseSearch(args []string) {
+	fs := commandFlagSet("search")
+	parseFlags(fs, args)
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	name := fs.Arg(0)
+	if err := osint.ValidateCaseName(name); err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	if !osint.CaseExists(name) {
+		color.Red("Error: no case %s in %s; index one with --case %s", name, osint.OutputDir, name)
+		os.Exit(1)
+	}
+	index, err := osint.OpenCaseIndex(name)
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+
+	results := index.Search(strings.Join(fs.Args()[1:], " "), *limitFlag)
+	if annotations, err := osint.LoadCaseAnnotations(name); err == nil {
+		annotations.ApplySearch(results)
+	} else {
+		color.Yellow("Warning: reading the case's annotations: %v", err)
+	}
+	results.DisplayResults()
+
+	if *outputFlag != "" {
+		if data, err := json.MarshalIndent(results, "", "  "); err == nil {
+			if err := os.WriteFile(*outputFlag, data, 0644); err == nil {
+				color.Green("\nResults saved to: %s", *outputFlag)
+			} else {
+				color.Red("Error saving results: %v", err)
+			}
+		} else {
+			color.Red("Error encoding results: %v", err)
+		}
+	}
+}
+
+// addAnnotateFlags registers the annotate command's options
+func addAnnotateFlags(fs *flag.FlagSet) {
+	fs.StringVar(tagFlag, "tag", "", "Comma-separated tags to add, such as confirmed or false-positive")
+	fs.StringVar(untagFlag, "untag", "", "Comma-separated tags to remove")
+	fs.StringVar(noteFlag, "note", "", "Note to add")
+	fs.StringVar(severityFlag, "severity", "", "Severity from 0 to 10 replacing the alert's, or none to drop the override")
+	fs.StringVar(authorFlag, "investigator", global.authorizedBy, "Who the note is from (default --authorized-by)")
+}
+
+// runAnnotate adds tags, notes and severity overrides to a finding of a
+// case, or shows the finding's annotation when given none
+func runAnnotate(args []string) {
+	fs := commandFlagSet("annotate")
+	parseFlags(fs, args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	name := fs.Arg(0)
+	if err := osint.ValidateCaseName(name); err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	if !osint.CaseExists(name) {
+		color.Red("Error: no case %s in %s", name, osint.OutputDir)
+		os.Exit(1)
+	}
+	finding, err := osint.CaseFindingID(name, fs.Arg(1))
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	annotations, err := osint.LoadCaseAnnotations(name)
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+
+	change := osint.AnnotationChange{
+		AddTags:    strings.FieldsFunc(*tagFlag, isComma),
+		RemoveTags: strings.FieldsFunc(*untagFlag, isComma),
+		Note:       *noteFlag,
+		Author:     *authorFlag,
+	}
+	switch strings.ToLower(*severityFlag) {
+	case "":
+	case "none":
+		change.ClearSeverity = true
+	default:
+		severity, err := strconv.Atoi(*severityFlag)
+		if err != nil {
+			color.Red("Error: --severity must be a number from 0 to 10 or none")
+			os.Exit(1)
+		}
+		change.Severity = &severity
+	}
+
+	annotation := annotations.Find(finding)
+	if len(change.AddTags) > 0 || len(change.RemoveTags) > 0 || strings.TrimSpace(change.Note) != "" || change.Severity != nil || change.ClearSeverity {
+		if annotation, err = annotations.Annotate(finding, change); err == nil {
+			err = annotations.Save()
+		}
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		color.Green("Finding %s of case %s annotated", finding, name)
+	}
+	if annotation == nil {
+		color.Yellow("Finding %s of case %s has no annotations", finding, name)
+		return
+	}
+	annotation.Display("  ")
+}
+
+// addSuppressFlags registers the suppress command's options
+func addSuppressFlags(fs *flag.FlagSet) {
+	fs.BoolVar(globalFlag, "global", false, "Use the global list, applying to every case, instead of the open case's")
+	fs.BoolVar(removeFlag, "remove", false, "Take the rule off the list")
+	fs.BoolVar(alertFlag, "alert", false, "The argument is an alert ID, as forwarded to syslog and TheHive, to suppress by its finding hash")
+	fs.StringVar(reasonFlag, "reason", "", "Why the finding is a false positive")
+	fs.StringVar(authorFlag, "investigator", global.authorizedBy, "Who suppressed it (default --authorized-by)")
+}
+
+// runSuppress adds a rule to the suppression list of the open case, or the
+// global one, removes it, or lists both lists
+func runSuppress(args []string) {
+	fs := commandFlagSet("suppress")
+	parseFlags(fs, args)
+
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	caseName := global.caseName
+	if *globalFlag {
+		caseName = ""
+	}
+	if fs.NArg() == 0 {
+		for _, name := range slices.Compact([]string{"", caseName}) {
+			list, err := osint.LoadSuppressionList(name)
+			if err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+			list.DisplayResults()
+		}
+		return
+	}
+	if caseName == "" && !*globalFlag {
+		color.Red("Error: open a case with 'mercuries case open', name one with --case, or use --global")
+		os.Exit(1)
+	}
+	if caseName != "" && !osint.CaseExists(caseName) {
+		color.Red("Error: no case %s in %s", caseName, osint.OutputDir)
+		os.Exit(1)
+	}
+
+	var rule osint.Suppression
+	var err error
+	if *alertFlag {
+		rule = osint.Suppression{Kind: osint.SuppressHash, Value: osint.Alert{ID: fs.Arg(0)}.FindingHash()}
+	} else if rule, err = osint.ParseSuppression(fs.Arg(0)); err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	rule.Reason, rule.Author = *reasonFlag, *authorFlag
+
+	list, err := osint.LoadSuppressionList(caseName)
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	scope := "the global list"
+	if caseName != "" {
+		scope = "case " + caseName
+	}
+	var changed bool
+	if *removeFlag {
+		changed = list.Remove(rule)
+	} else {
+		changed = list.Add(rule)
+	}
+	switch {
+	case !changed && *removeFlag:
+		color.Yellow("%s %s is not suppressed in %s", rule.Kind, rule.Value, scope)
+		return
+	case !changed:
+		color.Yellow("%s %s is already suppressed in %s", rule.Kind, rule.Value, scope)
+		return
+	}
+	if err := list.Save(); err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	if *removeFlag {
+		color.Green("%s %s no longer suppressed in %s", rule.Kind, rule.Value, scope)
+	} else {
+		color.Green("%s %s suppressed in %s", rule.Kind, rule.Value, scope)
+	}
+}
+
+// isComma splits comma-separated flag values
+func isComma(r rune) bool { return r == ',' }
+
+// addReportFlags registers the report command's options
+func addReportFlags(fs *flag.FlagSet) {
+	fs.StringVar(outputFlag, "output", "", "File to write (default: report.html, or report.pdf with --pdf, in the case's directory or the results directory)")
+	fs.StringVar(titleFlag, "title", "", "Report title (default: the case's name)")
+	fs.BoolVar(pdfFlag, "pdf", false, "Write a PDF with a cover page, contents and a section per module; an --output ending in .pdf does too")
+	fs.BoolVar(noAvatarsFlag, "no-avatars", false, "Do not download and embed the avatars of the profiles found")
+	fs.BoolVar(verboseFlag, "verbose", false, "Show avatars that could not be embedded")
+}
+
+// runReport renders saved results, by default those of the open case, into
+// an HTML report
+func runReport(args []string) {
+	fs := commandFlagSet("report")
+	parseFlags(fs, args)
+
+	paths := fs.Args()
+	title, investigator := *titleFlag, global.authorizedBy
+	if len(paths) == 0 {
+		if global.caseName == "" {
+			color.Red("Error: give the result files or directories to report on, or open a case")
+			os.Exit(1)
+		}
+		paths = []string{osint.CaseDir(global.caseName)}
+	}
+	if global.caseName != "" {
+		if info, err := osint.LoadCase(global.caseName); err == nil {
+			if title == "" {
+				title = "Investigation report: " + info.Name
+			}
+			if info.Investigator != "" {
+				investigator = info.Investigator
+			}
+		}
+	}
+	if title == "" {
+		title = "Investigation report"
+	}
+
+	report := osint.NewHTMLReport(title, investigator)
+	for _, path := range paths {
+		if err := report.AddPath(path); err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+	}
+	if report.Empty() {
+		color.Red("Error: no social, email, phone or Google ID results found in %s", strings.Join(paths, ", "))
+		os.Exit(1)
+	}
+
+	if !*noAvatarsFlag {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		errs := report.EmbedAvatars(ctx)
+		if len(errs) > 0 {
+			color.Yellow("%d avatars could not be embedded and show as initials", len(errs))
+			if *verboseFlag {
+				for _, moduleErr := range errs {
+					color.Yellow("  • %s: %s", moduleErr.Module, moduleErr.Error)
+				}
+			}
+		}
+	}
+
+	output := *outputFlag
+	asPDF := *pdfFlag || strings.EqualFold(filepath.Ext(output), ".pdf")
+	if output == "" {
+		dir := osint.OutputDir
+		if global.caseName != "" {
+			dir = osint.CaseDir(global.caseName)
+		}
+		output = filepath.Join(dir, "report.html")
+		if asPDF {
+			output = filepath.Join(dir, "report.pdf")
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	file, err := os.Create(output)
+	if err == nil {
+		if asPDF {
+			err = report.RenderPDF(file)
+		} else {
+			err = report.Render(file)
+		}
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		color.Red("Error writing the report: %v", err)
+		os.Exit(1)
+	}
+	color.Green("Report of %d result files saved to: %s", len(report.Sources), output)
+}
+
+// addServeFlags registers the serve command's options
+func addServeFlags(fs *flag.FlagSet) {
+	fs.StringVar(addrFlag, "addr", "127.0.0.1:8080", "Address to listen on")
+	fs.StringVar(dirFlag, "dir", osint.WatchlistDir, "Directory watchlists are stored in")
+	fs.StringVar(tokensFlag, "tokens", osint.TokensFile, "API tokens file, managed with 'mercuries tokens'")
+	fs.StringVar(auditFlag, "audit-log", osint.AuditLogFile, "File every token request is logged to")
+}
+
+// runServe serves watchlist findings feeds over HTTP and, for holders of API
+// tokens, scans
+func runServe(args []string) {
+	fs := commandFlagSet("serve")
+	parseFlags(fs, args)
+
+	osint.WatchlistDir = *dirFlag
+	osint.TokensFile = *tokensFlag
+	osint.AuditLogFile = *auditFlag
+
+	tokens, err := osint.LoadAPITokens()
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+
+	var handler http.Handler
+	if len(tokens) == 0 {
+		// Without tokens the server stays the open, read-only feed server
+		mux := http.NewServeMux()
+		mux.Handle("/feeds/", http.StripPrefix("/feeds", osint.FeedHandler()))
+		handler = mux
+		color.Yellow("No API tokens in %s: scans are off and feeds need no token. Add one with 'mercuries tokens add <name>'.", osint.TokensFile)
+	} else {
+		requireAcceptableUse(global.acceptTerms)
+		server, err := osint.NewScanServer()
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		defer server.Close()
+		handler = server.Handler()
+		color.Green("Serving scans on http://%s/scan/<module>?target=... for %d API tokens (modules: %s)", *addrFlag, server.Tokens(), strings.Join(osint.ServeModuleNames(), ", "))
+		color.Green("Auditing requests to %s", osint.AuditLogFile)
+	}
+
+	color.Green("Serving watchlist feeds on http://%s/feeds/<watchlist>.atom (or .rss)", *addrFlag)
+	if err := http.ListenAndServe(*addrFlag, handler); err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+}
+
+// tokensUsage describes the tokens subcommand's actions
+const tokensUsage = `usage: mercuries tokens [--file file] [--scopes list] [--daily-quota n] [--rate n] <action> ...
+
+actions:
+  list               List the API tokens 'mercuries serve' accepts
+  add <name>         Create a token with --scopes, --daily-quota and --rate; it is shown once
+  revoke <name>      Delete a token; a running server stops accepting it at once`
+
+// addTokensFlags registers the tokens command's options
+func addTokensFlags(fs *flag.FlagSet) {
+	fs.StringVar(fileFlag, "file", osint.TokensFile, "API tokens file")
+	fs.StringVar(scopesFlag, "scopes", "", "Comma-separated modules the token may scan ("+strings.Join(osint.ServeModuleNames(), ", ")+"), "+osint.ScopeFeeds+" for watchlist feeds, or "+osint.ScopeAll+" for everything")
+	fs.IntVar(quotaFlag, "daily-quota", 0, "Scans the token may run per UTC day, 0 for no limit")
+	fs.IntVar(rateFlag, "rate", 0, "Requests the token may make per minute, 0 for no limit")
+}
+
+// runTokens manages the API tokens of mercuries serve
+func runTokens(args []string) {
+	fs := commandFlagSet("tokens")
+	parseFlags(fs, args)
+
+	osint.TokensFile = *fileFlag
+	action := fs.Arg(0)
+	switch {
+	case action == "list" && fs.NArg() == 1:
+		tokens, err := osint.LoadAPITokens()
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		if len(tokens) == 0 {
+			color.Yellow("No API tokens in %s", osint.TokensFile)
+		}
+		for _, token := range tokens {
+			quota, limit := "unlimited", "unlimited"
+			if token.DailyQuota > 0 {
+				quota = fmt.Sprintf("%d/day", token.DailyQuota)
+			}
+			if token.RatePerMinute > 0 {
+				limit = fmt.Sprintf("%d/min", token.RatePerMinute)
+			}
+			color.White("• %s: scopes %s, quota %s, rate %s, created %s", token.Name, strings.Join(token.Scopes, ","), quota, limit, token.Created)
+		}
+
+	case action == "add" && fs.NArg() == 2:
+		var scopes []string
+		for _, scope := range strings.Split(*scopesFlag, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				scopes = append(scopes, scope)
+			}
+		}
+		token, err := osint.AddAPIToken(fs.Arg(1), scopes, *quotaFlag, *rateFlag)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		color.Green("Added token %s. Copy it now, it is not stored and cannot be shown again:", fs.Arg(1))
+		fmt.Println(token)
+
+	case action == "revoke" && fs.NArg() == 2:
+		if err := osint.RevokeAPIToken(fs.Arg(1)); err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		color.Green("Revoked token %s", fs.Arg(1))
+
+	default:
+		color.Red("Error: %s", tokensUsage)
+		os.Exit(1)
+	}
+}
+
+// useCassette routes HTTP traffic through a recording or replaying cassette
+func useCassette(record, replay string) error {
+	switch {
+	case record != "" && replay != "":
+		return fmt.Errorf("--record and --replay cannot be combined")
+	case record != "":
+		providers.Use(providers.NewRecorder(record, providers.Transport))
+		color.Yellow("Recording HTTP traffic to %s", record)
+	case replay != "":
+		cassette, err := providers.LoadCassette(replay)
+		if err != nil {
+			return err
+		}
+		providers.Use(cassette)
+		// Nothing reaches the services, so there is no quota to respect
+		providers.RateLimits = false
+		color.Yellow("Replaying HTTP traffic from %s (%d interactions recorded %s)",
+			replay, len(cassette.Interactions), cassette.Recorded.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// forwardAlerts sends alerts to a syslog collector when one is configured
+func forwardAlerts(target, format string, alerts []osint.Alert) {
+	alerts = osint.ActiveSuppressions.Alerts(alerts)
+	applyCaseSeverities(alerts)
+	for _, alert := range alerts {
+		osint.RunHook(osint.HookAlert, alert.Module, alert)
+	}
+	if target == "" || len(alerts) == 0 {
+		return
+	}
+
+	sink, err := osint.NewSyslogSink(target, format, AppVersion)
+	if err != nil {
+		color.Red("Error connecting to syslog collector: %v", err)
+		return
+	}
+	defer sink.Close()
+
+	if err := sink.Send(alerts); err != nil {
+		color.Red("Error forwarding alerts: %v", err)
+		return
+	}
+	color.Green("Forwarded %d alerts to %s", len(alerts), sink.Address)
+}
+
+// applyCaseSeverities gives alerts the severities analysts set for them in
+// the case the run is filed in
+func applyCaseSeverities(alerts []osint.Alert) {
+	if global.caseName == "" || len(alerts) == 0 {
+		return
+	}
+	annotations, err := osint.LoadCaseAnnotations(global.caseName)
+	if err != nil {
+		color.Yellow("Warning: reading the annotations of case %s: %v", global.caseName, err)
+		return
+	}
+	annotations.ApplyAlerts(alerts)
+}
+
+// exportTheHive writes and pushes a TheHive case when requested
+func exportTheHive(module, target string, alerts []osint.Alert, observables []osint.Observable) {
+	if *theHiveFlag == "" && *theHiveURLFlag == "" {
+		return
+	}
+	alerts = osint.ActiveSuppressions.Alerts(alerts)
+	applyCaseSeverities(alerts)
+	theCase := osint.NewTheHiveCase(module, target, fmt.Sprintf("%s %s results from %s %s", module, target, AppName, AppVersion), alerts, observables)
+
+	if *theHiveFlag != "" {
+		if err := theCase.Export(*theHiveFlag); err == nil {
+			color.Green("TheHive case saved to: %s", *theHiveFlag)
+		} else {
+			color.Red("Error saving TheHive case: %v", err)
+		}
+	}
+
+	if *theHiveURLFlag != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		id, err := theCase.Push(ctx, *theHiveURLFlag)
+		if id != "" {
+			color.Green("TheHive case created: %s", id)
+		}
+		if err != nil {
+			color.Red("Error pushing TheHive case: %v", err)
+		}
+	}
+}
+
+// addCortexFlags registers the cortex command's options
+func addCortexFlags(fs *flag.FlagSet) {
+	fs.StringVar(jobDirFlag, "job-dir", "/job", "Cortex job directory")
+}
+
+// runCortex runs as a Cortex analyzer, in job directory mode when the job
+// input exists and otherwise reading the job from stdin and writing to stdout
+func runCortex(args []string) {
+	fs := commandFlagSet("cortex")
+	parseFlags(fs, args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if _, err := os.Stat(filepath.Join(*jobDirFlag, "input", "input.json")); err == nil {
+		if err := osint.RunCortexJob(ctx, *jobDirFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	report := osint.RunCortexAnalyzer(ctx, os.Stdin)
+	if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// exportGeo writes the geolocated findings of a run when requested
+func exportGeo(module, target string, features []osint.GeoFeature) {
+	if *geoFlag == "" {
+		return
+	}
+	title := fmt.Sprintf("%s %s findings from %s %s", module, target, AppName, AppVersion)
+	if err := osint.ExportGeo(*geoFlag, title, features); err != nil {
+		color.Red("Error exporting map layer: %v", err)
+		return
+	}
+	color.Green("Map layer with %d findings saved to: %s", len(features), *geoFlag)
+}
+
+// caseFiled records the results already saved in the case by the command
+// itself, so indexCase does not file them twice
+var caseFiled = make(map[string]bool)
+
+// caseOutputPath returns the file in the case a command saves its results to
+// by default, or "" when the run is not filed in a case
+func caseOutputPath(module, target string) string {
+	if global.caseName == "" {
+		return ""
+	}
+	path := osint.CaseResultPath(global.caseName, module, target)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		color.Red("Error creating the case directory: %v", err)
+		return ""
+	}
+	caseFiled[module+"\x00"+target] = true
+	return path
+}
+
+// indexCase files a run's results in the case given with --case, or opened
+// with 'mercuries case open', and indexes the text they hold
+func indexCase(module, target string, results interface{}) {
+	if global.caseName == "" {
+		return
+	}
+	if !caseFiled[module+"\x00"+target] {
+		path := osint.CaseResultPath(global.caseName, module, target)
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err == nil {
+			err = os.MkdirAll(filepath.Dir(path), 0755)
+		}
+		if err == nil {
+			err = os.WriteFile(path, data, 0644)
+		}
+		if err != nil {
+			color.Red("Error filing results in case %s: %v", global.caseName, err)
+		} else {
+			color.Green("Results filed in case %s: %s", global.caseName, path)
+		}
+	}
+
+	index, err := osint.OpenCaseIndex(global.caseName)
+	if err != nil {
+		color.Red("Error opening case index: %v", err)
+		return
+	}
+	added := index.AddResults(module, target, results)
+	if err := index.Save(); err != nil {
+		color.Red("Error saving case index: %v", err)
+		return
+	}
+	color.Green("Indexed %d new texts into case %s", added, global.caseName)
+}
+
+// writeReport writes the report asked for with --report
+func writeReport(module, target string, results interface{}) {
+	if global.report == "" {
+		return
+	}
+	path := osint.ReportPath(global.caseName, module, target)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		color.Red("Error saving report: %v", err)
+		return
+	}
+	var buf bytes.Buffer
+	if err := osint.WriteMarkdownReport(&buf, module, target, results); err != nil {
+		color.Red("Error writing report: %v", err)
+		return
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		color.Red("Error saving report: %v", err)
+		return
+	}
+	color.Green("Markdown report saved to: %s", path)
+}
+
+// summarize writes the AI-generated summary asked for with --summary
+func summarize(module, target string, results interface{}) {
+	if global.summary == "" {
+		return
+	}
+	color.Yellow("Sending the results to %s for an AI-generated summary...", osint.SummaryURL)
+	ctx, cancel := context.WithTimeout(context.Background(), osint.SummaryTimeout)
+	defer cancel()
+	summary, err := osint.Summarize(ctx, module, target, results)
+	if err != nil {
+		color.Red("Error summarizing results: %v", err)
+		return
+	}
+	summary.DisplayResults()
+
+	data := []byte(summary.Markdown())
+	if !strings.EqualFold(filepath.Ext(global.summary), ".md") {
+		if data, err = json.MarshalIndent(summary, "", "  "); err != nil {
+			color.Red("Error encoding summary: %v", err)
+			return
+		}
+	}
+	if err := os.WriteFile(global.summary, data, 0644); err != nil {
+		color.Red("Error saving summary: %v", err)
+		return
+	}
+	color.Green("AI-generated summary saved to: %s", global.summary)
+}
+
+// exportOpenCTI pushes observables to OpenCTI when an instance is configured
+func exportOpenCTI(module string, observables []osint.Observable) {
+	if *openCTIURLFlag == "" {
+		return
+	}
+	osint.OpenCTIConfidence = *openCTIConfidenceFlag
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	export, err := osint.PushOpenCTI(ctx, *openCTIURLFlag, module, observables)
+	if export != nil {
+		color.Green("OpenCTI: %d observables and %d relationships pushed", export.Observables, export.Relationships)
+	}
+	if err != nil {
+		color.Red("Error pushing to OpenCTI: %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+// Package datasets provides the lookup tables used by the intelligence modules.
+//
+// Every table is a JSON file embedded into the binary from the data directory.
+// A file with the same name placed in the override directory takes precedence
+// over the embedded copy, which is also where `mercuries update-data` writes
+// verified updates. See FORMAT.md for the layout of each file.
+package datasets
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed data/*.json
+var embedded embed.FS
+
+//go:embed manifest.json
+var embeddedManifestData embed.FS
+
+// OverrideDir is checked for replacement data files before the embedded copies
+var OverrideDir = defaultOverrideDir()
+
+// Dataset file names
+const (
+	CarriersFile             = "carriers.json"
+	CountryNamesFile         = "country_names.json"
+	DialingCodesFile         = "dialing_codes.json"
+	TimeZonesFile            = "timezones.json"
+	DisposableDomainsFile    = "disposable_domains.json"
+	RoleAccountsFile         = "role_accounts.json"
+	EmailCommonWordsFile     = "email_common_words.json"
+	PersonalEmailDomainsFile = "personal_email_domains.json"
+	TechnologiesFile         = "technologies.json"
+	CanariesFile             = "canaries.json"
+)
+
+// Carrier describes a mobile operator assigned to a number prefix
+type Carrier struct {
+	Name     string   `json:"name"`
+	Network  string   `json:"network"`
+	Services []string `json:"services"`
+	Regions  []string `json:"regions"`
+	MCC      string   `json:"mcc"`
+	MNC      string   `json:"mnc"`
+}
+
+// CanaryList describes honeypot and canary infrastructure that alerts its
+// owner when touched
+type CanaryList struct {
+	Domains map[string]string `json:"domains"` // Domain, matching its subdomains too, to what runs it
+	Tokens  []CanaryToken     `json:"tokens"`
+}
+
+// CanaryToken is a pattern matching canary tokens embedded in text
+type CanaryToken struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+// TechnologyRule describes how to recognise a web technology. Every pattern is
+// a regular expression; the first capture group, if any, is the version.
+type TechnologyRule struct {
+	Name     string            `json:"name"`
+	Category string            `json:"category"`
+	Headers  map[string]string `json:"headers,omitempty"` // Header name to value pattern, "" matches any value
+	Cookies  []string          `json:"cookies,omitempty"` // Cookie name prefixes
+	HTML     []string          `json:"html,omitempty"`    // Patterns matched against the page body
+	Meta     map[string]string `json:"meta,omitempty"`    // Meta tag name to content pattern
+	Scripts  []string          `json:"scripts,omitempty"` // Patterns matched against script URLs
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[string]interface{}{}
+)
+
+// defaultOverrideDir returns $MERCURIES_DATA_DIR or ~/.mercuries/data
+func defaultOverrideDir() string {
+	if dir := os.Getenv("MERCURIES_DATA_DIR"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".mercuries", "data")
+}
+
+// Names lists every dataset file shipped with the binary
+func Names() []string {
+	entries, err := embedded.ReadDir("data")
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+// ReadFile returns the raw contents of a dataset, preferring the override directory
+func ReadFile(name string) ([]byte, error) {
+	if !ValidName(name) {
+		return nil, fmt.Errorf("unknown dataset %q", name)
+	}
+	if OverrideDir != "" {
+		data, err := os.ReadFile(filepath.Join(OverrideDir, name))
+		if err == nil {
+			return data, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return embedded.ReadFile("data/" + name)
+}
+
+// Load decodes a dataset into v
+func Load(name string, v interface{}) error {
+	data, err := ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("failed to read dataset %s: %v", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse dataset %s: %v", name, err)
+	}
+	return nil
+}
+
+// Reload drops cached tables so the next lookup reads the files again
+func Reload() {
+	cacheMu.Lock()
+	cache = map[string]interface{}{}
+	cacheMu.Unlock()
+}
+
+// cached loads a dataset once and falls back to the embedded copy if the
+// override file is unreadable, so a bad update never breaks lookups
+func cached[T any](name string, convert func(T) interface{}) interface{} {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if v, ok := cache[name]; ok {
+		return v
+	}
+
+	var raw T
+	if err := Load(name, &raw); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v, using built-in copy\n", err)
+
+		// Start from an empty value so a partly decoded override can't leak in
+		var fallback T
+		data, embedErr := embedded.ReadFile("data/" + name)
+		if embedErr == nil {
+			embedErr = json.Unmarshal(data, &fallback)
+		}
+		if embedErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: built-in dataset %s unavailable: %v\n", name, embedErr)
+		}
+		raw = fallback
+	}
+
+	v := convert(raw)
+	cache[name] = v
+	return v
+}
+
+// Carriers returns carriers keyed by country calling code, then number prefix
+func Carriers() map[string]map[string]Carrier {
+	return cached(CarriersFile, func(raw map[string]map[string]Carrier) interface{} {
+		return raw
+	}).(map[string]map[string]Carrier)
+}
+
+// CountryNames returns country names keyed by ISO 3166-1 alpha-2 region code
+func CountryNames() map[string]string {
+	return cached(CountryNamesFile, func(raw map[string]string) interface{} {
+		return raw
+	}).(map[string]string)
+}
+
+// DialingCodes returns country names keyed by international calling code
+func DialingCodes() map[int32]string {
+	return cached(DialingCodesFile, func(raw map[string]string) interface{} {
+		codes := make(map[int32]string, len(raw))
+		for key, name := range raw {
+			code, err := strconv.ParseInt(key, 10, 32)
+			if err != nil {
+				continue
+			}
+			codes[int32(code)] = name
+		}
+		return codes
+	}).(map[int32]string)
+}
+
+// TimeZones returns IANA time zones keyed by region code
+func TimeZones() map[string][]string {
+	return cached(TimeZonesFile, func(raw map[string][]string) interface{} {
+		return raw
+	}).(map[string][]string)
+}
+
+// DisposableDomains returns the set of known throwaway email domains
+func DisposableDomains() map[string]bool {
+	return cached(DisposableDomainsFile, toSet).(map[string]bool)
+}
+
+// RoleAccounts returns the set of local parts used by role-based mailboxes
+func RoleAccounts() map[string]bool {
+	return cached(RoleAccountsFile, toSet).(map[string]bool)
+}
+
+// EmailCommonWords returns descriptions for common words found in email usernames
+func EmailCommonWords() map[string]string {
+	return cached(EmailCommonWordsFile, func(raw map[string]string) interface{} {
+		return raw
+	}).(map[string]string)
+}
+
+// PersonalEmailDomains returns the set of consumer email providers
+func PersonalEmailDomains() map[string]bool {
+	return cached(PersonalEmailDomainsFile, toSet).(map[string]bool)
+}
+
+// Technologies returns the web technology fingerprints
+func Technologies() []TechnologyRule {
+	return cached(TechnologiesFile, func(raw []TechnologyRule) interface{} {
+		return raw
+	}).([]TechnologyRule)
+}
+
+// Canaries returns the known canary token services and callback domains
+func Canaries() CanaryList {
+	return cached(CanariesFile, func(raw CanaryList) interface{} {
+		domains := make(map[string]string, len(raw.Domains))
+		for domain, owner := range raw.Domains {
+			domains[strings.ToLower(strings.TrimSpace(domain))] = owner
+		}
+		raw.Domains = domains
+		return raw
+	}).(CanaryList)
+}
+
+// toSet converts a list of strings into a lowercase lookup set
+func toSet(raw []string) interface{} {
+	set := make(map[string]bool, len(raw))
+	for _, item := range raw {
+		set[strings.ToLower(strings.TrimSpace(item))] = true
+	}
+	return set
+}
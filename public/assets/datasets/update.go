@@ -0,0 +1,278 @@
+package datasets
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+)
+
+// Update channel configuration. The public key is pinned: mirrors may serve
+// the channel from another URL but must carry manifests signed by this key.
+var (
+	UpdateURL      = "https://raw.githubusercontent.com/awiones/MercuriesOST/main/public/assets/datasets"
+	UpdateTimeout  = 30 * time.Second
+	maxDatasetSize = int64(10 << 20)
+)
+
+// updatePublicKey is the base64 ed25519 key that signs manifest.json. It is
+// empty until the maintainers pin the public half of a key made with
+// "go run ./tools/datasign -generate <seed file>"; builds for another channel
+// may set it with -ldflags "-X .../datasets.updatePublicKey=<key>".
+var updatePublicKey = ""
+
+// ErrNoUpdateKey means the build has no pinned key to check updates against
+var ErrNoUpdateKey = errors.New("no dataset update key is pinned in this build")
+
+// UpdatesAvailable reports whether this build has a key to check updates
+// against, without which Update always fails
+func UpdatesAvailable() bool {
+	return updatePublicKey != ""
+}
+
+const (
+	manifestFile  = "manifest.json"
+	installedFile = "installed.json"
+)
+
+// Manifest lists the files published on the update channel. It is the only
+// signed object; each file is bound to its name, hash and the manifest version.
+type Manifest struct {
+	Version int            `json:"version"`
+	Files   []ManifestFile `json:"files"`
+}
+
+// ManifestFile describes a single published dataset
+type ManifestFile struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+}
+
+// UpdateResult reports the outcome of updating a single dataset
+type UpdateResult struct {
+	Name    string
+	Version int
+	Updated bool
+	Skipped bool // Already at this version or newer
+	Error   error
+}
+
+// ValidName reports whether name is a dataset shipped with the binary
+func ValidName(name string) bool {
+	for _, known := range Names() {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Update downloads the signed manifest from UpdateURL and installs every listed
+// dataset (or only those in names) whose hash matches and whose version is newer
+// than the one already in use. Files failing verification are left untouched.
+func Update(ctx context.Context, names []string) ([]UpdateResult, error) {
+	for _, name := range names {
+		if !ValidName(name) {
+			return nil, fmt.Errorf("unknown dataset %q", name)
+		}
+	}
+	if OverrideDir == "" {
+		return nil, fmt.Errorf("no override directory available")
+	}
+	if updatePublicKey == "" {
+		return nil, ErrNoUpdateKey
+	}
+
+	client := &http.Client{Timeout: UpdateTimeout, Transport: providers.Transport}
+	base := strings.TrimRight(UpdateURL, "/")
+
+	manifest, err := fetchManifest(ctx, client, base)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(OverrideDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %v", OverrideDir, err)
+	}
+
+	installed := installedVersions()
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var results []UpdateResult
+	for _, file := range manifest.Files {
+		if len(wanted) > 0 && !wanted[file.Name] {
+			continue
+		}
+
+		result := UpdateResult{Name: file.Name, Version: manifest.Version}
+		switch {
+		case !ValidName(file.Name):
+			result.Error = fmt.Errorf("manifest lists unknown dataset")
+		case manifest.Version <= installed[file.Name]:
+			result.Skipped = true
+		default:
+			result.Error = updateFile(ctx, client, base, file)
+			if result.Error == nil {
+				result.Updated = true
+				installed[file.Name] = manifest.Version
+			}
+		}
+		results = append(results, result)
+	}
+
+	if err := saveInstalledVersions(installed); err != nil {
+		return results, err
+	}
+
+	Reload()
+	return results, nil
+}
+
+// fetchManifest downloads and verifies the channel manifest
+func fetchManifest(ctx context.Context, client *http.Client, base string) (Manifest, error) {
+	var manifest Manifest
+
+	data, err := fetch(ctx, client, base+"/"+manifestFile)
+	if err != nil {
+		return manifest, fmt.Errorf("manifest: %v", err)
+	}
+	sig, err := fetch(ctx, client, base+"/"+manifestFile+".sig")
+	if err != nil {
+		return manifest, fmt.Errorf("manifest signature: %v", err)
+	}
+
+	if err := VerifyManifest(data, sig); err != nil {
+		return manifest, err
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("invalid manifest: %v", err)
+	}
+	return manifest, nil
+}
+
+// VerifyManifest checks a base64 detached signature against the pinned key
+func VerifyManifest(data, sig []byte) error {
+	if updatePublicKey == "" {
+		return ErrNoUpdateKey
+	}
+	return verifyManifest(updatePublicKey, data, sig)
+}
+
+// verifyManifest checks a base64 detached signature against a base64 key
+func verifyManifest(publicKey string, data, sig []byte) error {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid pinned public key")
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return fmt.Errorf("malformed manifest signature: %v", err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), data, signature) {
+		return fmt.Errorf("manifest signature verification failed")
+	}
+	return nil
+}
+
+// updateFile fetches a dataset, checks it against the manifest and installs it
+func updateFile(ctx context.Context, client *http.Client, base string, file ManifestFile) error {
+	data, err := fetch(ctx, client, base+"/data/"+file.Name)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), file.SHA256) {
+		return fmt.Errorf("hash does not match manifest")
+	}
+
+	// Refuse files that would not load
+	var probe interface{}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	return writeAtomic(filepath.Join(OverrideDir, file.Name), data)
+}
+
+// installedVersions returns the manifest version each dataset was installed
+// from. Embedded copies count as the version of the embedded manifest.
+func installedVersions() map[string]int {
+	versions := make(map[string]int)
+
+	var embeddedManifest Manifest
+	if data, err := embeddedManifestData.ReadFile(manifestFile); err == nil {
+		if json.Unmarshal(data, &embeddedManifest) == nil {
+			for _, file := range embeddedManifest.Files {
+				versions[file.Name] = embeddedManifest.Version
+			}
+		}
+	}
+
+	var local map[string]int
+	if data, err := os.ReadFile(filepath.Join(OverrideDir, installedFile)); err == nil {
+		if json.Unmarshal(data, &local) == nil {
+			for name, version := range local {
+				if version > versions[name] {
+					versions[name] = version
+				}
+			}
+		}
+	}
+
+	return versions
+}
+
+// saveInstalledVersions records installed versions so older signed manifests
+// cannot roll a dataset back
+func saveInstalledVersions(versions map[string]int) error {
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeAtomic(filepath.Join(OverrideDir, installedFile), data)
+}
+
+// writeAtomic writes through a temporary file so a failed update never leaves a partial file
+func writeAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// fetch downloads a URL with a size cap
+func fetch(ctx context.Context, client *http.Client, target string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxDatasetSize))
+}
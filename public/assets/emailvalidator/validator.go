@@ -1,11 +1,14 @@
 package emailvalidator
 
 import (
-	"fmt"
+	"context"
 	"net"
 	"net/mail"
 	"strings"
 	"time"
+
+	"github.com/awion/MercuriesOST/public/assets/datasets"
+	"github.com/awion/MercuriesOST/public/providers"
 )
 
 // ValidationResult contains the detailed results of email validation
@@ -91,15 +94,7 @@ func validateMX(domain string, result *ValidationResult) {
 }
 
 func checkDisposable(domain string, result *ValidationResult) {
-	disposableDomains := map[string]bool{
-		"tempmail.com":      true,
-		"throwawaymail.com": true,
-		"mailinator.com":    true,
-		"guerrillamail.com": true,
-		// Add more disposable domains here
-	}
-
-	if disposableDomains[domain] {
+	if datasets.DisposableDomains()[strings.ToLower(domain)] {
 		result.IsDisposable = true
 		result.DisposableMsg = "Domain is known disposable email provider"
 		result.Errors = append(result.Errors, "Disposable email not allowed")
@@ -107,18 +102,7 @@ func checkDisposable(domain string, result *ValidationResult) {
 }
 
 func checkRoleAccount(localPart string, result *ValidationResult) {
-	roleAccounts := map[string]bool{
-		"admin":     true,
-		"info":      true,
-		"support":   true,
-		"sales":     true,
-		"contact":   true,
-		"noreply":   true,
-		"no-reply":  true,
-		"webmaster": true,
-	}
-
-	if roleAccounts[strings.ToLower(localPart)] {
+	if datasets.RoleAccounts()[strings.ToLower(localPart)] {
 		result.IsRole = true
 		result.Errors = append(result.Errors, "Role-based email address")
 	}
@@ -130,7 +114,7 @@ func validateSMTP(email, domain string, result *ValidationResult) {
 	}
 
 	// Connect to SMTP server
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:25", result.MXRecords[0]), 10*time.Second)
+	conn, err := providers.DialContext(context.Background(), "tcp", net.JoinHostPort(result.MXRecords[0], "25"), 10*time.Second)
 	if err != nil {
 		result.SMTPResponse = "Connection failed"
 		result.Errors = append(result.Errors, "SMTP connection failed")
@@ -0,0 +1,87 @@
+package input
+
+import (
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// Domain length limits from RFC 1035
+const (
+	maxDomainLength = 253
+	maxLabelLength  = 63
+)
+
+// domainProfile converts internationalized names to their ASCII (punycode)
+// form under the IDNA2008 lookup rules, checking hyphens and label lengths
+var domainProfile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.ValidateLabels(true),
+	idna.StrictDomainName(false),
+	idna.VerifyDNSLength(true),
+)
+
+// Domain returns the lowercase ASCII form of a domain name. A URL is reduced
+// to its host, and internationalized names such as bücher.de are converted to
+// punycode (xn--bcher-kva.de) so every module queries the same name.
+func Domain(value string) (string, error) {
+	return domain(KindDomain, value)
+}
+
+// domain validates a domain name, reporting problems as the given kind
+func domain(kind, value string) (string, error) {
+	original := value
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", invalid(kind, original, "empty domain", "")
+	}
+
+	if strings.Contains(value, "://") {
+		parsed, err := url.Parse(value)
+		if err != nil || parsed.Hostname() == "" {
+			return "", invalid(kind, original, "is a URL without a host", "give the domain alone, e.g. example.com")
+		}
+		value = parsed.Hostname()
+	} else {
+		if kind == KindDomain && strings.Contains(value, "@") {
+			return "", invalid(kind, original, "looks like an email address", "use the email command, or give only the part after @")
+		}
+		value = strings.SplitN(value, "/", 2)[0]
+		if host, _, found := strings.Cut(value, ":"); found {
+			value = host
+		}
+	}
+	value = strings.TrimSuffix(value, ".")
+
+	if _, err := netip.ParseAddr(strings.Trim(value, "[]")); err == nil {
+		return "", invalid(kind, original, "is an IP address, not a domain", "use the ip command")
+	}
+	if strings.ContainsAny(value, " \t") {
+		return "", invalid(kind, original, "contains spaces", "")
+	}
+
+	ascii, err := domainProfile.ToASCII(value)
+	if err != nil {
+		return "", invalid(kind, original, "is not a valid domain name: "+idnaProblem(err), "")
+	}
+	if !strings.Contains(ascii, ".") {
+		return "", invalid(kind, original, "has no top-level domain", "include it, e.g. "+ascii+".com")
+	}
+	if len(ascii) > maxDomainLength {
+		return "", invalid(kind, original, "is longer than 253 characters", "")
+	}
+	for _, label := range strings.Split(ascii, ".") {
+		if len(label) > maxLabelLength {
+			return "", invalid(kind, original, "has a label longer than 63 characters", "")
+		}
+	}
+	return ascii, nil
+}
+
+// idnaProblem shortens the errors of the idna package, which start with its name
+func idnaProblem(err error) string {
+	return strings.TrimPrefix(err.Error(), "idna: ")
+}
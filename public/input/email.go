@@ -0,0 +1,87 @@
+package input
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"unicode"
+)
+
+// Email length limits from RFC 5321
+const (
+	maxEmailLength = 254
+	maxLocalLength = 64
+)
+
+// Email parses an address under RFC 5322 and returns it as local@domain, with
+// the domain lowercased and in ASCII. "Name <user@example.com>" and
+// mailto: links are reduced to the address. The local part keeps its case,
+// since only the receiving server may decide it is case-insensitive.
+func Email(value string) (string, error) {
+	original := value
+	value = strings.TrimSpace(value)
+	if len(value) > len("mailto:") && strings.EqualFold(value[:len("mailto:")], "mailto:") {
+		value = strings.SplitN(value[len("mailto:"):], "?", 2)[0]
+	}
+
+	switch at := strings.Count(value, "@"); {
+	case value == "":
+		return "", invalid(KindEmail, original, "empty address", "")
+	case at == 0:
+		if strings.ContainsAny(value, " .") {
+			return "", invalid(KindEmail, original, "has no @", "expected user@example.com")
+		}
+		return "", invalid(KindEmail, original, "has no @ or domain", "use the social command to search a username")
+	case at > 1 && !strings.Contains(value, "<"):
+		return "", invalid(KindEmail, original, "has more than one @", "")
+	case strings.HasPrefix(value, "@"):
+		return "", invalid(KindEmail, original, "has no user before the @", "")
+	case strings.HasSuffix(value, "@"):
+		return "", invalid(KindEmail, original, "has no domain after the @", "")
+	}
+	if local := value[:strings.LastIndex(value, "@")]; !strings.Contains(local, `"`) &&
+		(strings.Contains(local, "..") || strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".")) {
+		return "", invalid(KindEmail, original, "has a dot at the start or end of the user or two in a row", "")
+	}
+
+	address, err := mail.ParseAddress(value)
+	if err != nil {
+		return "", invalid(KindEmail, original, emailProblem(err), "expected user@example.com")
+	}
+	at := strings.LastIndex(address.Address, "@")
+	local, host := address.Address[:at], address.Address[at+1:]
+
+	if strings.ContainsFunc(local, unicode.IsSpace) || strings.Contains(local, `"`) {
+		return "", invalid(KindEmail, original, "has a quoted local part", "quoted addresses are not supported")
+	}
+	if len(local) > maxLocalLength {
+		return "", invalid(KindEmail, original, "has a local part longer than 64 characters", "")
+	}
+
+	host, err = domain(KindEmail, host)
+	if err != nil {
+		var inputErr *Error
+		if errors.As(err, &inputErr) {
+			return "", invalid(KindEmail, original, "domain "+inputErr.Problem, inputErr.Hint)
+		}
+		return "", err
+	}
+
+	normalized := local + "@" + host
+	if len(normalized) > maxEmailLength {
+		return "", invalid(KindEmail, original, "is longer than 254 characters", "")
+	}
+	return normalized, nil
+}
+
+// emailProblem rewords net/mail errors, which name the grammar rule that failed
+func emailProblem(err error) string {
+	msg := strings.TrimPrefix(err.Error(), "mail: ")
+	switch {
+	case strings.Contains(msg, "expected single address"):
+		return "holds more than one address or stray text"
+	case strings.Contains(msg, "angle-addr"), strings.Contains(msg, "dot-atom"):
+		return "contains characters not allowed in an address"
+	}
+	return msg
+}
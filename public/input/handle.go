@@ -0,0 +1,78 @@
+package input
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxHandleLength bounds handles on platforms without a rule of their own
+const maxHandleLength = 64
+
+// handleRule is the charset and length a platform allows in usernames
+type handleRule struct {
+	pattern  *regexp.Regexp
+	min, max int
+	allowed  string // The charset, for error messages
+}
+
+// handleRules maps lowercase platform names to their username rules
+var handleRules = map[string]handleRule{
+	"twitter":    {regexp.MustCompile(`^[A-Za-z0-9_]+$`), 1, 15, "letters, digits and _"},
+	"instagram":  {regexp.MustCompile(`^[A-Za-z0-9._]+$`), 1, 30, "letters, digits, . and _"},
+	"facebook":   {regexp.MustCompile(`^[A-Za-z0-9.]+$`), 5, 50, "letters, digits and ."},
+	"linkedin":   {regexp.MustCompile(`^[A-Za-z0-9-]+$`), 3, 100, "letters, digits and -"},
+	"github":     {regexp.MustCompile(`^[A-Za-z0-9](?:-?[A-Za-z0-9])*$`), 1, 39, "letters, digits and single hyphens inside"},
+	"reddit":     {regexp.MustCompile(`^[A-Za-z0-9_-]+$`), 3, 20, "letters, digits, _ and -"},
+	"tiktok":     {regexp.MustCompile(`^[A-Za-z0-9._]+$`), 2, 24, "letters, digits, . and _"},
+	"telegram":   {regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]+$`), 5, 32, "letters, digits and _, starting with a letter"},
+	"venmo":      {regexp.MustCompile(`^[A-Za-z0-9_-]+$`), 5, 30, "letters, digits, _ and -"},
+	"paypal":     {regexp.MustCompile(`^[A-Za-z0-9]+$`), 1, 20, "letters and digits"},
+	"cashapp":    {regexp.MustCompile(`^[A-Za-z0-9_-]*[A-Za-z][A-Za-z0-9_-]*$`), 1, 20, "letters, digits, _ and -, with at least one letter"},
+	"spotify":    {regexp.MustCompile(`^[A-Za-z0-9._-]+$`), 1, 30, "letters, digits, ., _ and -"},
+	"soundcloud": {regexp.MustCompile(`^[a-z0-9_-]+$`), 3, 25, "lowercase letters, digits, _ and -"},
+	"lastfm":     {regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]+$`), 2, 15, "letters, digits, _ and -, starting with a letter"},
+	"npm":        {regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`), 1, 214, "lowercase letters, digits, ., _ and -"},
+}
+
+// Handle checks a username searched across platforms, dropping a leading @.
+// Platform rules are not applied, since a handle only has to be valid on one
+// of them; see PlatformHandle.
+func Handle(value string) (string, error) {
+	original := value
+	value = strings.TrimPrefix(strings.TrimSpace(value), "@")
+	switch {
+	case value == "":
+		return "", invalid(KindUsername, original, "empty username", "")
+	case strings.Contains(value, "://"):
+		return "", invalid(KindUsername, original, "is a URL", "give the username alone, or use resolve for profile links")
+	case strings.Contains(value, "@"):
+		return "", invalid(KindUsername, original, "looks like an email address", "use the email command")
+	case !utf8.ValidString(value) || strings.ContainsFunc(value, unicode.IsControl):
+		return "", invalid(KindUsername, original, "contains control characters or invalid UTF-8", "")
+	case strings.ContainsAny(value, "/?#"):
+		return "", invalid(KindUsername, original, "contains / ? or #, which no platform allows", "")
+	case utf8.RuneCountInString(value) > maxHandleLength:
+		return "", invalid(KindUsername, original, fmt.Sprintf("is longer than %d characters", maxHandleLength), "")
+	}
+	return value, nil
+}
+
+// PlatformHandle checks a handle against a platform's username rules. Platforms
+// without known rules accept anything.
+func PlatformHandle(platform, handle string) error {
+	rule, ok := handleRules[strings.ToLower(platform)]
+	if !ok {
+		return nil
+	}
+	length := len(handle)
+	switch {
+	case length < rule.min || length > rule.max:
+		return invalid(platform+" username", handle, fmt.Sprintf("must be %d to %d characters", rule.min, rule.max), "")
+	case !rule.pattern.MatchString(handle):
+		return invalid(platform+" username", handle, "may only contain "+rule.allowed, "")
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Package input validates and normalizes scan targets before any module runs.
+// Every target type has one checker returning the canonical form the modules
+// expect, or an *Error saying what is wrong with the value and how to fix it,
+// so a typo is caught up front instead of failing differently mid-scan.
+package input
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Target kinds, as named in error messages
+const (
+	KindEmail      = "email"
+	KindPhone      = "phone"
+	KindDomain     = "domain"
+	KindIP         = "ip"
+	KindUsername   = "username"
+	KindName       = "name"
+	KindGoogleID   = "gid"
+	KindFacebookID = "facebook-id"
+	KindTwitterID  = "twitter-id"
+	KindRedditID   = "reddit-id"
+)
+
+// maxNameLength bounds free text names in characters
+const maxNameLength = 100
+
+// Error describes a target that cannot be scanned
+type Error struct {
+	Kind    string // What the value was given as, e.g. "email"
+	Value   string
+	Problem string // What is wrong with it
+	Hint    string // How to fix it, may be empty
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("invalid %s %q: %s", e.Kind, e.Value, e.Problem)
+	if e.Hint != "" {
+		msg += " (" + e.Hint + ")"
+	}
+	return msg
+}
+
+// invalid builds an *Error
+func invalid(kind, value, problem, hint string) *Error {
+	return &Error{Kind: kind, Value: value, Problem: problem, Hint: hint}
+}
+
+var (
+	googleIDRegex  = regexp.MustCompile(`^\d{21}$`)
+	numericIDRegex = regexp.MustCompile(`^\d{1,20}$`)
+	redditIDRegex  = regexp.MustCompile(`^(?:t2_)?[0-9a-z]{1,13}$`)
+)
+
+// Target validates value as the given kind and returns its normalized form
+func Target(kind, value string) (string, error) {
+	switch kind {
+	case KindEmail:
+		return Email(value)
+	case KindPhone:
+		return Phone(value)
+	case KindDomain:
+		return Domain(value)
+	case KindIP:
+		return IP(value)
+	case KindUsername:
+		return Handle(value)
+	case KindName:
+		return Name(value)
+	case KindGoogleID:
+		return GoogleID(value)
+	case KindFacebookID, KindTwitterID, KindRedditID:
+		return AccountID(kind, value)
+	}
+	return "", fmt.Errorf("unknown target kind %q", kind)
+}
+
+// IP returns the canonical form of an IPv4 or IPv6 address, accepting the
+// bracketed form used in URLs
+func IP(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	bare := strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	if bare == "" {
+		return "", invalid(KindIP, value, "empty address", "")
+	}
+	if _, err := netip.ParsePrefix(bare); err == nil {
+		return "", invalid(KindIP, value, "is a network, not an address", "give a single address from it")
+	}
+	if addr, err := netip.ParseAddrPort(bare); err == nil {
+		return "", invalid(KindIP, value, "includes a port", "use "+addr.Addr().String())
+	}
+	addr, err := netip.ParseAddr(bare)
+	if err != nil {
+		if strings.Contains(bare, ".") && strings.ContainsFunc(bare, unicode.IsLetter) {
+			return "", invalid(KindIP, value, "is not an IP address", "use the domain command for host names")
+		}
+		return "", invalid(KindIP, value, "is not an IPv4 or IPv6 address", "")
+	}
+	return addr.Unmap().String(), nil
+}
+
+// Name checks a person's or organization's name searched for as free text
+func Name(value string) (string, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	switch {
+	case value == "":
+		return "", invalid(KindName, value, "empty name", "")
+	case !utf8.ValidString(value) || strings.ContainsFunc(value, unicode.IsControl):
+		return "", invalid(KindName, value, "contains control characters or invalid UTF-8", "")
+	case utf8.RuneCountInString(value) > maxNameLength:
+		return "", invalid(KindName, value, fmt.Sprintf("longer than %d characters", maxNameLength), "")
+	}
+	return value, nil
+}
+
+// GoogleID checks a 21 digit Google account ID
+func GoogleID(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if !googleIDRegex.MatchString(value) {
+		return "", invalid(KindGoogleID, value, fmt.Sprintf("has %d characters, Google IDs are 21 digits", len(value)), "")
+	}
+	return value, nil
+}
+
+// AccountID checks a numeric Facebook or Twitter ID, or a Reddit account
+// fullname with or without its t2_ prefix
+func AccountID(kind, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if kind == KindRedditID {
+		value = strings.ToLower(value)
+		if !redditIDRegex.MatchString(value) {
+			return "", invalid(kind, value, "is not a Reddit account ID", "expected t2_ followed by up to 13 letters and digits")
+		}
+		return value, nil
+	}
+	if !numericIDRegex.MatchString(value) {
+		return "", invalid(kind, value, "must be a number of up to 20 digits", "look up usernames with the social command instead")
+	}
+	return value, nil
+}
@@ -0,0 +1,62 @@
+package input
+
+import (
+	"strings"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+// e164Hint shows the expected phone number form
+const e164Hint = "give it in international form with the country code, e.g. +14155552671"
+
+// Phone parses a phone number and returns it in E.164 form (+14155552671).
+// Spaces, dashes, dots and brackets are ignored, and a leading 00 is read as
+// the international prefix. The number must carry its country code, since a
+// national number means something different in every country.
+func Phone(value string) (string, error) {
+	original := value
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(value, "tel:")
+	if value == "" {
+		return "", invalid(KindPhone, original, "empty number", "")
+	}
+	if strings.HasPrefix(value, "00") {
+		value = "+" + value[2:]
+	}
+	if !strings.HasPrefix(value, "+") {
+		return "", invalid(KindPhone, original, "has no country code", e164Hint)
+	}
+
+	number, err := phonenumbers.Parse(value, "")
+	if err != nil {
+		return "", invalid(KindPhone, original, phoneProblem(err), e164Hint)
+	}
+	switch phonenumbers.IsPossibleNumberWithReason(number) {
+	case phonenumbers.TOO_SHORT:
+		return "", invalid(KindPhone, original, "is too short", "check for missing digits")
+	case phonenumbers.TOO_LONG:
+		return "", invalid(KindPhone, original, "is too long", "check for extra digits")
+	case phonenumbers.INVALID_COUNTRY_CODE:
+		return "", invalid(KindPhone, original, "has an unknown country code", e164Hint)
+	}
+	if !phonenumbers.IsValidNumber(number) {
+		region := phonenumbers.GetRegionCodeForCountryCode(int(number.GetCountryCode()))
+		return "", invalid(KindPhone, original, "is not a number in use in "+region, "check the digits after the country code")
+	}
+	return phonenumbers.Format(number, phonenumbers.E164), nil
+}
+
+// phoneProblem rewords the errors of the phonenumbers package
+func phoneProblem(err error) string {
+	switch err {
+	case phonenumbers.ErrInvalidCountryCode:
+		return "has an unknown country code"
+	case phonenumbers.ErrNotANumber:
+		return "is not a phone number"
+	case phonenumbers.ErrTooShortNSN, phonenumbers.ErrTooShortAfterIDD:
+		return "is too short"
+	case phonenumbers.ErrNumTooLong:
+		return "is too long"
+	}
+	return err.Error()
+}
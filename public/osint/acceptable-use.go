@@ -0,0 +1,148 @@
+package osint
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+)
+
+// Acceptable use settings
+var (
+	// AuthorizedBy names who approved the investigation; it is recorded into cases
+	AuthorizedBy string
+
+	// PolicyURL is an organizational endpoint asked to allow or deny every
+	// scan. It defaults to $MERCURIES_POLICY_URL so an administrator can set
+	// it for all users instead of relying on a flag.
+	PolicyURL = os.Getenv("MERCURIES_POLICY_URL")
+)
+
+// AcceptableUseNotice is shown once per machine before the first scan
+const AcceptableUseNotice = `MercuriesOST collects publicly available information about people and
+organizations. Use it only for lawful purposes you are authorized to pursue:
+security assessments you are engaged for, investigations within your
+organization's mandate, research and education, or looking into yourself.
+
+Do not use it to stalk, harass, dox or discriminate against anyone, and
+respect the privacy laws that apply where you and your subjects are, such as
+the GDPR. You are responsible for how you use what it finds.`
+
+// Acknowledgement records when the acceptable use notice was accepted
+type Acknowledgement struct {
+	Accepted string `json:"accepted"`
+	Version  string `json:"version"`
+	User     string `json:"user,omitempty"`
+}
+
+// acknowledgementPath returns ~/.mercuries/acknowledged.json
+func acknowledgementPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".mercuries", "acknowledged.json")
+}
+
+// Acknowledged reports whether the acceptable use notice was accepted on this machine
+func Acknowledged() bool {
+	path := acknowledgementPath()
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Acknowledge records that the current user accepted the acceptable use notice
+func Acknowledge(version string) error {
+	path := acknowledgementPath()
+	if path == "" {
+		return fmt.Errorf("no home directory to record the acknowledgement in")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(Acknowledgement{
+		Accepted: time.Now().Format(time.RFC3339),
+		Version:  version,
+		User:     currentUser(),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// PromptAcknowledgement shows the notice and asks the user to type "yes".
+// Anything else declines.
+func PromptAcknowledgement(in io.Reader, out io.Writer) bool {
+	fmt.Fprintf(out, "\n%s\n\nType \"yes\" to confirm you will use MercuriesOST this way: ", AcceptableUseNotice)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	return strings.EqualFold(strings.TrimSpace(answer), "yes")
+}
+
+// PolicyRequest describes a scan to the organizational policy endpoint
+type PolicyRequest struct {
+	ScanID       string `json:"scan_id"`
+	Module       string `json:"module"`
+	Target       string `json:"target"`
+	AuthorizedBy string `json:"authorized_by,omitempty"`
+	User         string `json:"user,omitempty"`
+	Host         string `json:"host,omitempty"`
+}
+
+// PolicyDecision is the endpoint's answer
+type PolicyDecision struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// CheckPolicy asks PolicyURL whether a scan may run. Without a PolicyURL
+// every scan is allowed. An endpoint that cannot be reached denies the scan,
+// since a gate that fails open can be bypassed by blocking it.
+func CheckPolicy(ctx context.Context, module, target string) (*PolicyDecision, error) {
+	if PolicyURL == "" {
+		return &PolicyDecision{Allow: true}, nil
+	}
+
+	host, _ := os.Hostname()
+	body, err := json.Marshal(PolicyRequest{
+		ScanID:       providers.ScanIDFrom(ctx),
+		Module:       module,
+		Target:       target,
+		AuthorizedBy: AuthorizedBy,
+		User:         currentUser(),
+		Host:         host,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	if apiKeyConfigured(APIConfig.PolicyKey) {
+		headers["Authorization"] = "Bearer " + APIConfig.PolicyKey
+	}
+	var decision PolicyDecision
+	if err := postProviderJSON(ctx, PolicyURL, headers, bytes.NewReader(body), &decision); err != nil {
+		return nil, fmt.Errorf("policy check failed: %v", err)
+	}
+	return &decision, nil
+}
+
+// currentUser returns the login name running the tool
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
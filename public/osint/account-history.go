@@ -0,0 +1,444 @@
+package osint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// CheckAccountHistory makes profile searches look for deleted and renamed
+// GitHub and Reddit accounts under the searched handle when no live profile
+// is found there
+var CheckAccountHistory = false
+
+// Account history statuses
+const (
+	HistoryActive    = "active"    // The handle belongs to a live account
+	HistoryRenamed   = "renamed"   // The account lives on under another handle
+	HistoryDeleted   = "deleted"   // The account is gone
+	HistorySuspended = "suspended" // The account exists but was suspended
+	HistoryReused    = "reused"    // A different account took over the handle
+	HistoryNotFound  = "not found" // No trace of the handle
+)
+
+// githubAvatarIDRegex reads the user ID from a GitHub avatar URL, for
+// archived pages older than the octolytics meta tag
+var githubAvatarIDRegex = regexp.MustCompile(`avatars\d*\.githubusercontent\.com/u/(\d+)`)
+
+// AccountHistory is what is known about a handle's past on a platform
+type AccountHistory struct {
+	Platform          string         `json:"platform"`
+	Handle            string         `json:"handle"`
+	ScanID            string         `json:"scan_id,omitempty"`
+	Status            string         `json:"status"`
+	PreviouslyExisted bool           `json:"previously_existed"` // An account used the handle and no longer does
+	AccountID         string         `json:"account_id,omitempty"`
+	CurrentHandle     string         `json:"current_handle,omitempty"` // Where a renamed account lives now
+	FirstCaptured     string         `json:"first_captured,omitempty"`
+	LastCaptured      string         `json:"last_captured,omitempty"`
+	LastKnown         *ProfileResult `json:"last_known,omitempty"` // Read from the newest archived capture
+	LastKnownSource   string         `json:"last_known_source,omitempty"`
+	Evidence          []string       `json:"evidence,omitempty"`
+	Keywords          *KeywordReport `json:"keyword_hits,omitempty"`
+	PartialErrors     []ModuleError  `json:"partial_errors,omitempty"`
+}
+
+// LookupAccountHistory finds out whether a GitHub or Reddit handle belonged
+// to an account that was since deleted, renamed or suspended, using the
+// platform's API and the Wayback Machine's captures of the profile
+func LookupAccountHistory(ctx context.Context, platform, handle string) (*AccountHistory, error) {
+	handle = strings.TrimPrefix(strings.TrimSpace(handle), "@")
+	if handle == "" {
+		return nil, fmt.Errorf("empty handle")
+	}
+	history := &AccountHistory{Platform: platform, Handle: handle, ScanID: providers.ScanIDFrom(ctx)}
+
+	switch strings.ToLower(platform) {
+	case "github":
+		history.Platform = "GitHub"
+		history.github(ctx)
+	case "reddit":
+		history.Platform = "Reddit"
+		history.reddit(ctx)
+	default:
+		return nil, fmt.Errorf("account history is only available for GitHub and Reddit, not %q", platform)
+	}
+
+	if keywords := NewKeywordWatch(); keywords != nil {
+		keywords.Scan("", history)
+		history.Keywords = keywords.Report()
+	}
+	return history, nil
+}
+
+// githubUser is the part of GitHub's user API response that is used
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	CreatedAt string `json:"created_at"`
+}
+
+// githubHeaders returns the headers for GitHub's API, authenticated when a token is configured
+func githubHeaders() map[string]string {
+	headers := map[string]string{"Accept": "application/vnd.github+json"}
+	if apiKeyConfigured(APIConfig.GitHubToken) {
+		headers["Authorization"] = "Bearer " + APIConfig.GitHubToken
+	}
+	return headers
+}
+
+// github compares the live account under the handle with the account the
+// archived profile belonged to. GitHub user IDs never change, so an archived
+// ID that now has another login was renamed, and one that is gone was deleted.
+func (h *AccountHistory) github(ctx context.Context) {
+	var live *githubUser
+	var user githubUser
+	err := getProviderJSON(ctx, "https://api.github.com/users/"+url.PathEscape(h.Handle), githubHeaders(), &user)
+	switch {
+	case err == nil:
+		live = &user
+		h.AccountID = strconv.FormatInt(user.ID, 10)
+	case !providers.IsStatus(err, http.StatusNotFound):
+		h.addError("github", err)
+	}
+
+	archivedID := h.archive(ctx, "github.com/"+h.Handle, "GitHub")
+
+	switch {
+	case live != nil && (archivedID == "" || archivedID == h.AccountID):
+		h.Status = HistoryActive
+		return
+	case live != nil:
+		h.Status = HistoryReused
+		h.PreviouslyExisted = true
+		h.Evidence = append(h.Evidence, fmt.Sprintf("Archived profile belonged to user ID %s; the handle now belongs to ID %s", archivedID, h.AccountID))
+	case archivedID == "" && h.LastCaptured == "":
+		h.Status = HistoryNotFound
+		return
+	case archivedID == "":
+		h.Status = HistoryDeleted
+		h.PreviouslyExisted = true
+		h.Evidence = append(h.Evidence, "The profile was archived but no account uses the handle now")
+		return
+	default:
+		h.AccountID = archivedID
+	}
+
+	// Follow the archived account by its ID
+	var previous githubUser
+	err = getProviderJSON(ctx, "https://api.github.com/user/"+archivedID, githubHeaders(), &previous)
+	switch {
+	case err == nil && !strings.EqualFold(previous.Login, h.Handle):
+		if h.Status == "" {
+			h.Status = HistoryRenamed
+		}
+		h.PreviouslyExisted = true
+		h.CurrentHandle = previous.Login
+		h.Evidence = append(h.Evidence, fmt.Sprintf("User ID %s now has the login %s", archivedID, previous.Login))
+	case providers.IsStatus(err, http.StatusNotFound):
+		if h.Status == "" {
+			h.Status = HistoryDeleted
+		}
+		h.PreviouslyExisted = true
+		h.Evidence = append(h.Evidence, fmt.Sprintf("User ID %s no longer exists; GitHub also hides suspended accounts this way", archivedID))
+	case err != nil:
+		h.addError("github", err)
+		if h.Status == "" {
+			h.Status = HistoryDeleted
+			h.PreviouslyExisted = true
+		}
+	case h.Status == "":
+		// The account still has the handle; the lookup by name was stale
+		h.Status = HistoryActive
+	}
+}
+
+// reddit reads the account's about page, and for a missing account whether
+// the name is still taken. Reddit never releases a name, so a taken name with
+// no account behind it was deleted.
+func (h *AccountHistory) reddit(ctx context.Context) {
+	var about struct {
+		Data struct {
+			ID          string  `json:"id"`
+			Name        string  `json:"name"`
+			IsSuspended bool    `json:"is_suspended"`
+			CreatedUTC  float64 `json:"created_utc"`
+		} `json:"data"`
+	}
+	err := getProviderJSON(ctx, "https://www.reddit.com/user/"+url.PathEscape(h.Handle)+"/about.json", nil, &about)
+	found := err == nil
+	if err != nil && !providers.IsStatus(err, http.StatusNotFound) {
+		h.addError("reddit", err)
+	}
+
+	archivedID := h.archive(ctx, "reddit.com/user/"+h.Handle, "Reddit")
+
+	switch {
+	case found && about.Data.IsSuspended:
+		h.Status = HistorySuspended
+		h.PreviouslyExisted = true
+		h.AccountID = archivedID
+		h.Evidence = append(h.Evidence, "Reddit reports the account as suspended")
+		return
+	case found:
+		h.Status = HistoryActive
+		if about.Data.ID != "" {
+			h.AccountID = "t2_" + about.Data.ID
+		}
+		return
+	}
+	h.AccountID = archivedID
+
+	var available bool
+	err = getProviderJSON(ctx, "https://www.reddit.com/api/username_available.json?user="+url.QueryEscape(h.Handle), nil, &available)
+	switch {
+	case err == nil && !available:
+		h.Status = HistoryDeleted
+		h.PreviouslyExisted = true
+		h.Evidence = append(h.Evidence, "The name is taken but has no account; Reddit never releases deleted names")
+	case h.LastCaptured != "":
+		h.Status = HistoryDeleted
+		h.PreviouslyExisted = true
+		h.Evidence = append(h.Evidence, "The profile was archived but no account uses the name now")
+	default:
+		if err != nil {
+			h.addError("reddit", err)
+		}
+		h.Status = HistoryNotFound
+	}
+}
+
+// archive records when a profile was first and last captured and reads the
+// last-known profile from the newest capture, returning the account ID found
+// in it
+func (h *AccountHistory) archive(ctx context.Context, target, platformName string) string {
+	oldest, err := waybackCaptures(ctx, target, 1)
+	if err != nil {
+		h.addError("archive", err)
+		return ""
+	}
+	if len(oldest) == 0 {
+		return ""
+	}
+	newest, err := waybackCaptures(ctx, target, -1)
+	if err != nil || len(newest) == 0 {
+		newest = oldest
+	}
+	h.FirstCaptured = oldest[0].Time()
+	h.LastCaptured = newest[0].Time()
+
+	platform, ok := platformByName(platformName)
+	if !ok {
+		return ""
+	}
+	profile, err := archivedProfile(ctx, newest[0], platform)
+	if err != nil {
+		h.addError("archive", err)
+		return ""
+	}
+	profile.Username = h.Handle
+	h.LastKnown = profile
+	h.LastKnownSource = newest[0].URL(false)
+	return profile.CanonicalID
+}
+
+func (h *AccountHistory) addError(module string, err error) {
+	h.PartialErrors = append(h.PartialErrors, ModuleError{Module: module, Error: err.Error()})
+}
+
+// platformByName returns a built-in platform
+func platformByName(name string) (SocialPlatform, bool) {
+	for _, platform := range platforms {
+		if strings.EqualFold(platform.Name, name) {
+			return platform, true
+		}
+	}
+	return SocialPlatform{}, false
+}
+
+// archivedProfile reads a profile from a Wayback Machine capture with the
+// platform's selectors
+func archivedProfile(ctx context.Context, capture waybackCapture, platform SocialPlatform) (*ProfileResult, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", capture.URL(true), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := doProviderRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := readPageBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body.Data))
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &ProfileResult{Platform: platform.Name, URL: capture.Original}
+	extractProfileInfo(doc, profile, platform)
+	resolveCanonicalProfile(profile, platform, body.String())
+	if profile.CanonicalID == "" && platform.Name == "GitHub" {
+		if m := githubAvatarIDRegex.FindSubmatch(body.Data); m != nil {
+			profile.CanonicalID = string(m[1])
+		}
+	}
+	profile.Insights = append(profile.Insights, "Read from the archived capture of "+capture.Time())
+	return profile, nil
+}
+
+// waybackCapture is one Wayback Machine snapshot of a URL
+type waybackCapture struct {
+	Timestamp string // YYYYMMDDhhmmss
+	Original  string
+}
+
+// URL returns the snapshot's address. A raw snapshot is the page as it was
+// archived, without the Wayback Machine's toolbar and rewritten links.
+func (c waybackCapture) URL(raw bool) string {
+	if raw {
+		return fmt.Sprintf("https://web.archive.org/web/%sid_/%s", c.Timestamp, c.Original)
+	}
+	return fmt.Sprintf("https://web.archive.org/web/%s/%s", c.Timestamp, c.Original)
+}
+
+// Time returns the capture time in RFC 3339
+func (c waybackCapture) Time() string {
+	t, err := time.Parse("20060102150405", c.Timestamp)
+	if err != nil {
+		return c.Timestamp
+	}
+	return t.Format(time.RFC3339)
+}
+
+// waybackCaptures lists successful captures of target, the oldest limit of
+// them, or the newest when limit is negative
+func waybackCaptures(ctx context.Context, target string, limit int) ([]waybackCapture, error) {
+	params := url.Values{}
+	params.Set("url", target)
+	params.Set("output", "json")
+	params.Set("fl", "timestamp,original")
+	params.Set("filter", "statuscode:200")
+	params.Set("limit", strconv.Itoa(limit))
+	if limit < 0 {
+		params.Set("fastLatest", "true")
+	}
+
+	var rows [][]string
+	if err := getProviderJSON(ctx, "https://web.archive.org/cdx/search/cdx?"+params.Encode(), nil, &rows); err != nil {
+		return nil, fmt.Errorf("archive.org: %v", err)
+	}
+	var captures []waybackCapture
+	for i, row := range rows {
+		// The first row holds the column names
+		if i == 0 || len(row) < 2 {
+			continue
+		}
+		captures = append(captures, waybackCapture{Timestamp: row[0], Original: row[1]})
+	}
+	return captures, nil
+}
+
+// accountHistoryChecks looks for past GitHub and Reddit accounts under a
+// searched handle on the platforms where the search found no live profile
+func accountHistoryChecks(ctx context.Context, query string, profiles []ProfileResult) []AccountHistory {
+	handle := strings.ToLower(strings.ReplaceAll(query, " ", ""))
+	found := make(map[string]bool)
+	for _, profile := range profiles {
+		if strings.EqualFold(strings.ReplaceAll(profile.Username, " ", ""), handle) {
+			found[profile.Platform] = true
+		}
+	}
+
+	var histories []AccountHistory
+	for _, platform := range []string{"GitHub", "Reddit"} {
+		if found[platform] {
+			continue
+		}
+		history, err := LookupAccountHistory(ctx, platform, handle)
+		if err == nil && history.PreviouslyExisted {
+			histories = append(histories, *history)
+		}
+	}
+	return histories
+}
+
+// DisplayResults prints the account's history
+func (h *AccountHistory) DisplayResults() {
+	color.Cyan("\n=== ACCOUNT HISTORY ===")
+	color.Yellow("%s: %s", h.Platform, h.Handle)
+
+	status := fmt.Sprintf("• Status: %s", h.Status)
+	if h.PreviouslyExisted {
+		color.Red(status + " (previously existed)")
+	} else {
+		color.White(status)
+	}
+	if h.AccountID != "" {
+		color.White("• Account ID: %s", h.AccountID)
+	}
+	if h.CurrentHandle != "" {
+		color.Green("• Now known as: %s", h.CurrentHandle)
+	}
+	if h.FirstCaptured != "" {
+		color.White("• Archived: %s to %s", h.FirstCaptured, h.LastCaptured)
+	}
+	for _, evidence := range h.Evidence {
+		color.White("  - %s", evidence)
+	}
+
+	if h.LastKnown != nil {
+		color.Cyan("\n[Last Known Profile]")
+		color.White("• Source: %s", h.LastKnownSource)
+		if h.LastKnown.FullName != "" {
+			color.White("• Full Name: %s", h.LastKnown.FullName)
+		}
+		if h.LastKnown.Bio != "" {
+			color.White("• Bio: %s", h.LastKnown.Bio)
+		}
+		if h.LastKnown.Location != "" {
+			color.White("• Location: %s", h.LastKnown.Location)
+		}
+		if h.LastKnown.FollowerCount > 0 {
+			color.White("• Followers: %d", h.LastKnown.FollowerCount)
+		}
+	}
+}
+
+// DisplayPartialErrors prints the lookups that failed
+func (h *AccountHistory) DisplayPartialErrors() {
+	displayModuleErrors(h.PartialErrors)
+}
+
+// DisplayAccountHistories lists the past accounts found by a profile search
+func DisplayAccountHistories(histories []AccountHistory) {
+	if len(histories) == 0 {
+		return
+	}
+	color.Green("\n=== PREVIOUSLY EXISTING ACCOUNTS ===")
+	for _, h := range histories {
+		line := fmt.Sprintf("  %s: %s (%s)", h.Platform, h.Handle, h.Status)
+		if h.CurrentHandle != "" {
+			line += ", now " + h.CurrentHandle
+		}
+		color.Yellow(line)
+		if h.LastKnownSource != "" {
+			color.White("    Last known: %s", h.LastKnownSource)
+		}
+		for _, evidence := range h.Evidence {
+			color.White("    - %s", evidence)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// Platforms accepted by LookupAccountID
+const (
+	AccountFacebook = "facebook"
+	AccountTwitter  = "twitter"
+	AccountReddit   = "reddit"
+)
+
+var (
+	numericIDRegex   = regexp.MustCompile(`^\d{1,20}$`)
+	redditIDRegex    = regexp.MustCompile(`^(?:t2_)?([0-9a-z]{1,13})$`)
+	canonicalLinkRe  = regexp.MustCompile(`<link[^>]+rel="canonical"[^>]+href="([^"]+)"`)
+	ogURLRe          = regexp.MustCompile(`<meta[^>]+property="og:url"[^>]+content="([^"]+)"`)
+	screenNameRegex  = regexp.MustCompile(`"screen_name":"(\w{1,15})"`)
+	reservedProfiles = map[string]bool{"profile.php": true, "i": true, "intent": true, "login": true, "login.php": true, "people": true}
+)
+
+// AccountLookup is an account found from its platform-native ID when the
+// username is unknown
+type AccountLookup struct {
+	Platform      string         `json:"platform"`
+	ScanID        string         `json:"scan_id,omitempty"`
+	ID            string         `json:"id"`
+	Username      string         `json:"username,omitempty"`
+	ProfileURL    string         `json:"profile_url,omitempty"`
+	Created       string         `json:"created,omitempty"`
+	CreatedSource string         `json:"created_source,omitempty"` // "snowflake" or "api"
+	Profile       *ProfileResult `json:"profile,omitempty"`
+	PartialErrors []ModuleError  `json:"partial_errors,omitempty"`
+}
+
+// LookupAccountID finds the account behind a Facebook numeric ID, a Twitter
+// user ID or a Reddit account fullname (t2_...), decoding the creation time
+// when the ID embeds one
+func LookupAccountID(ctx context.Context, platform, id string) (*AccountLookup, error) {
+	id = strings.TrimSpace(id)
+	result := &AccountLookup{Platform: platform, ID: id, ScanID: providers.ScanIDFrom(ctx)}
+
+	switch platform {
+	case AccountFacebook:
+		if !numericIDRegex.MatchString(id) {
+			return nil, fmt.Errorf("facebook IDs are numeric")
+		}
+		result.ProfileURL = "https://www.facebook.com/profile.php?id=" + id
+		if err := result.resolveVanity(ctx, result.ProfileURL); err != nil {
+			result.addError("vanity", err)
+		}
+
+	case AccountTwitter:
+		if !numericIDRegex.MatchString(id) {
+			return nil, fmt.Errorf("twitter user IDs are numeric")
+		}
+		// Accounts created before snowflakes were adopted have small
+		// sequential IDs with no timestamp in them
+		if created := IDCreated(IDTwitter, id); created != "" {
+			result.Created = created
+			result.CreatedSource = "snowflake"
+		}
+		result.ProfileURL = "https://twitter.com/intent/user?user_id=" + id
+		if err := result.resolveVanity(ctx, "https://twitter.com/i/user/"+id); err != nil {
+			result.addError("vanity", err)
+		}
+
+	case AccountReddit:
+		m := redditIDRegex.FindStringSubmatch(strings.ToLower(id))
+		if m == nil {
+			return nil, fmt.Errorf("reddit account IDs are base36, optionally prefixed with t2_")
+		}
+		result.ID = "t2_" + m[1]
+		if err := result.redditAccount(ctx); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported platform %q", platform)
+	}
+
+	if result.ProfileURL != "" {
+		profile, err := ResolveProfileURL(result.ProfileURL)
+		if profile != nil && profile.Exists {
+			result.Profile = profile
+		}
+		if err != nil {
+			result.addError("profile", err)
+		}
+	}
+	return result, nil
+}
+
+// resolveVanity follows the platform's ID URL to the vanity profile URL, read
+// from the redirect target or the page's canonical link
+func (r *AccountLookup) resolveVanity(ctx context.Context, target string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/108.0.0.0 Safari/537.36")
+
+	client := &http.Client{Timeout: RequestTimeout, Transport: providers.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+	if err != nil {
+		return err
+	}
+	page := string(body)
+
+	candidates := []string{resp.Request.URL.String()}
+	for _, re := range []*regexp.Regexp{canonicalLinkRe, ogURLRe} {
+		if m := re.FindStringSubmatch(page); m != nil {
+			candidates = append(candidates, m[1])
+		}
+	}
+	for _, candidate := range candidates {
+		u, err := url.Parse(candidate)
+		if err != nil {
+			continue
+		}
+		segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+		if len(segments) == 1 && !reservedProfiles[segments[0]] {
+			r.Username = segments[0]
+			r.ProfileURL = u.Scheme + "://" + u.Host + "/" + segments[0]
+			return nil
+		}
+	}
+	if m := screenNameRegex.FindStringSubmatch(page); m != nil {
+		r.Username = m[1]
+		r.ProfileURL = "https://twitter.com/" + m[1]
+		return nil
+	}
+	return fmt.Errorf("no vanity name found (status %d)", resp.StatusCode)
+}
+
+// redditAccount reads the account name and creation time from Reddit's
+// account lookup endpoint
+func (r *AccountLookup) redditAccount(ctx context.Context) error {
+	var accounts map[string]struct {
+		Name         string  `json:"name"`
+		CreatedUTC   float64 `json:"created_utc"`
+		LinkKarma    int     `json:"link_karma"`
+		CommentKarma int     `json:"comment_karma"`
+	}
+	target := "https://www.reddit.com/api/user_data_by_account_ids.json?ids=" + url.QueryEscape(r.ID)
+	if err := getProviderJSON(ctx, target, nil, &accounts); err != nil {
+		return fmt.Errorf("reddit lookup: %v", err)
+	}
+	account, ok := accounts[r.ID]
+	if !ok || account.Name == "" {
+		return fmt.Errorf("no reddit account %s", r.ID)
+	}
+	r.Username = account.Name
+	r.ProfileURL = "https://www.reddit.com/user/" + account.Name
+	if account.CreatedUTC > 0 {
+		r.Created = time.Unix(int64(account.CreatedUTC), 0).UTC().Format(time.RFC3339)
+		r.CreatedSource = "api"
+	}
+	return nil
+}
+
+func (r *AccountLookup) addError(module string, err error) {
+	r.PartialErrors = append(r.PartialErrors, ModuleError{Module: module, Error: err.Error()})
+}
+
+// DisplayResults prints the account lookup
+func (r *AccountLookup) DisplayResults() {
+	color.Cyan("\n=== %s ACCOUNT %s ===", strings.ToUpper(r.Platform), r.ID)
+	if r.Username != "" {
+		color.Green("Username: %s", r.Username)
+	} else {
+		color.Yellow("Username: not resolved")
+	}
+	if r.ProfileURL != "" {
+		color.White("Profile: %s", r.ProfileURL)
+	}
+	if r.Created != "" {
+		color.White("Created: %s (from %s)", r.Created, r.CreatedSource)
+	}
+	if r.Profile != nil {
+		printProfileDetails(r.Profile)
+	}
+}
+
+// DisplayPartialErrors prints the lookup steps that failed
+func (r *AccountLookup) DisplayPartialErrors() {
+	displayModuleErrors(r.PartialErrors)
+}
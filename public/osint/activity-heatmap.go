@@ -0,0 +1,235 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/awion/MercuriesOST/public/parse"
+	"github.com/fatih/color"
+	"github.com/nyaruka/phonenumbers"
+)
+
+// Heatmap tuning
+const (
+	minHeatmapSamples = 10 // Fewer timestamps than this say nothing about a timezone
+	maxActivityTimes  = 50 // Timestamps kept per profile
+	quietHours        = 6  // Length of the sleep window looked for
+	quietMidpoint     = 4  // Local hour assumed to fall mid-sleep
+	zoneTolerance     = 2  // Hours either way within which a hint agrees
+)
+
+var dateOnlyRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// Timezone hints compared with the heatmap of a social media search
+var (
+	TimeZoneHintPhone = "" // Phone number whose region's timezones are compared
+	TimeZoneHintIP    = "" // IP address whose geolocated timezone is compared
+)
+
+// ActivityHeatmap counts an identity's activity by UTC hour and weekday and
+// infers the timezone it keeps from its quietest hours
+type ActivityHeatmap struct {
+	Identity    string          `json:"identity"`
+	Samples     int             `json:"samples"`
+	Grid        [7][24]int      `json:"grid"` // [weekday][UTC hour], Sunday first
+	QuietStart  int             `json:"quiet_start_utc,omitempty"`
+	UTCOffset   *int            `json:"utc_offset,omitempty"` // Inferred local offset in hours
+	Comparisons []ZoneHintMatch `json:"comparisons,omitempty"`
+}
+
+// ZoneHintMatch compares the inferred offset with a timezone from another
+// source, such as a phone number's region or an IP's geolocation
+type ZoneHintMatch struct {
+	Source    string `json:"source"`
+	Zone      string `json:"zone"`
+	UTCOffset int    `json:"utc_offset"`
+	Agrees    bool   `json:"agrees"`
+}
+
+// extractActivityTimes collects the timestamps of posts on a profile page
+// from <time datetime>, Facebook's data-utime and data-timestamp attributes.
+// Dates without a time of day are skipped, as they cannot place activity
+// in the day.
+func extractActivityTimes(doc *goquery.Document, result *ProfileResult) {
+	doc.Find("time[datetime], [data-utime], [data-timestamp]").Each(func(i int, s *goquery.Selection) {
+		if len(result.ActivityTimes) >= maxActivityTimes {
+			return
+		}
+		for _, attr := range []string{"datetime", "data-utime", "data-timestamp"} {
+			raw, ok := s.Attr(attr)
+			if !ok || dateOnlyRegex.MatchString(strings.TrimSpace(raw)) {
+				continue
+			}
+			if t, err := parse.Date(raw, time.Now()); err == nil {
+				result.ActivityTimes = append(result.ActivityTimes, t.UTC().Format(time.RFC3339))
+				return
+			}
+		}
+	})
+}
+
+// BuildActivityHeatmap bins timestamps by weekday and hour in UTC. With
+// enough samples, the quietest run of hours is taken as the night and the
+// UTC offset placing its middle at 4am local is inferred.
+func BuildActivityHeatmap(identity string, times []time.Time) *ActivityHeatmap {
+	h := &ActivityHeatmap{Identity: identity}
+	var hours [24]int
+	for _, t := range times {
+		t = t.UTC()
+		h.Grid[t.Weekday()][t.Hour()]++
+		hours[t.Hour()]++
+		h.Samples++
+	}
+	if h.Samples < minHeatmapSamples {
+		return h
+	}
+
+	best := -1
+	for start := 0; start < 24; start++ {
+		count := 0
+		for i := 0; i < quietHours; i++ {
+			count += hours[(start+i)%24]
+		}
+		if best < 0 || count < best {
+			best, h.QuietStart = count, start
+		}
+	}
+	offset := quietMidpoint - (h.QuietStart + quietHours/2)
+	offset = ((offset % 24) + 24) % 24
+	if offset > 14 {
+		offset -= 24
+	}
+	h.UTCOffset = &offset
+	return h
+}
+
+// ProfileActivityTimes gathers the parsed activity timestamps of profiles
+func ProfileActivityTimes(profiles []ProfileResult) []time.Time {
+	var times []time.Time
+	for _, profile := range profiles {
+		for _, raw := range profile.ActivityTimes {
+			if t, err := time.Parse(time.RFC3339, raw); err == nil {
+				times = append(times, t)
+			}
+		}
+	}
+	return times
+}
+
+// CompareZones checks the inferred offset against IANA timezones from
+// another source; offsets are taken as they stand today
+func (h *ActivityHeatmap) CompareZones(source string, zones []string) {
+	if h.UTCOffset == nil {
+		return
+	}
+	for _, zone := range zones {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			continue
+		}
+		_, seconds := time.Now().In(loc).Zone()
+		offset := seconds / 3600
+		diff := offset - *h.UTCOffset
+		if diff < 0 {
+			diff = -diff
+		}
+		diff = min(diff, 24-diff)
+		h.Comparisons = append(h.Comparisons, ZoneHintMatch{Source: source, Zone: zone, UTCOffset: offset, Agrees: diff <= zoneTolerance})
+	}
+}
+
+// compareHints compares the heatmap with the configured phone and IP hints,
+// returning the hints that could not be resolved
+func (h *ActivityHeatmap) compareHints(ctx context.Context) []error {
+	var errs []error
+	if h.UTCOffset == nil {
+		return nil
+	}
+	if TimeZoneHintPhone != "" {
+		zones, err := PhoneTimeZones(TimeZoneHintPhone)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("phone hint: %v", err))
+		}
+		h.CompareZones("phone "+TimeZoneHintPhone, zones)
+	}
+	if TimeZoneHintIP != "" {
+		zone, err := IPTimeZone(ctx, TimeZoneHintIP)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("IP hint: %v", err))
+		} else {
+			h.CompareZones("IP "+TimeZoneHintIP, []string{zone})
+		}
+	}
+	return errs
+}
+
+// PhoneTimeZones returns the timezones of a phone number's region
+func PhoneTimeZones(number string) ([]string, error) {
+	parsed, err := phonenumbers.Parse(number, "")
+	if err != nil {
+		return nil, err
+	}
+	return getTimeZones(phonenumbers.GetRegionCodeForNumber(parsed)), nil
+}
+
+// IPTimeZone returns the timezone an IP address geolocates to
+func IPTimeZone(ctx context.Context, ip string) (string, error) {
+	var geo GeoIPInfo
+	_, _, err := withFallback(ctx, GeoProviders, func(p GeoProvider) error {
+		found, err := p.Geolocate(ctx, ip)
+		geo = found
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	if geo.TimeZone == "" {
+		return "", fmt.Errorf("no timezone known for %s", ip)
+	}
+	return geo.TimeZone, nil
+}
+
+// DisplayResults prints the heatmap by weekday in three-hour blocks, the
+// inferred offset and how the hints compare
+func (h *ActivityHeatmap) DisplayResults() {
+	color.Cyan("\n=== ACTIVITY HEATMAP: %s ===", h.Identity)
+	if h.Samples < minHeatmapSamples {
+		color.Yellow("Only %d timestamped posts; at least %d are needed to infer a timezone", h.Samples, minHeatmapSamples)
+		return
+	}
+
+	peak := 1
+	for _, day := range h.Grid {
+		for hour := 0; hour < 24; hour += 3 {
+			peak = max(peak, day[hour]+day[hour+1]+day[hour+2])
+		}
+	}
+	shades := []rune(" ░▒▓█")
+	color.White("      00 03 06 09 12 15 18 21 UTC")
+	for weekday, day := range h.Grid {
+		var row strings.Builder
+		for hour := 0; hour < 24; hour += 3 {
+			count := day[hour] + day[hour+1] + day[hour+2]
+			shade := shades[(count*(len(shades)-1)+peak-1)/peak]
+			row.WriteString(strings.Repeat(string(shade), 2) + " ")
+		}
+		color.White("  %s %s", time.Weekday(weekday).String()[:3], row.String())
+	}
+
+	color.Yellow("\nQuietest hours: %02d:00-%02d:00 UTC, from %d timestamped posts", h.QuietStart, (h.QuietStart+quietHours)%24, h.Samples)
+	color.Green("Likely timezone: UTC%+d (±%dh, assuming the quiet hours are night)", *h.UTCOffset, zoneTolerance)
+
+	sort.SliceStable(h.Comparisons, func(i, j int) bool { return h.Comparisons[i].Agrees && !h.Comparisons[j].Agrees })
+	for _, c := range h.Comparisons {
+		if c.Agrees {
+			color.Green("  ✓ %s %s (UTC%+d) agrees", c.Source, c.Zone, c.UTCOffset)
+		} else {
+			color.Red("  ✗ %s %s (UTC%+d) does not match", c.Source, c.Zone, c.UTCOffset)
+		}
+	}
+}
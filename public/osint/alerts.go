@@ -0,0 +1,285 @@
+package osint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Alert is a finding worth forwarding to a SIEM. Severity uses the CEF scale,
+// 0 (informational) to 10 (critical).
+type Alert struct {
+	ID       string `json:"id"`
+	Module   string `json:"module"`
+	Name     string `json:"name"`
+	Target   string `json:"target"`
+	Severity int    `json:"severity"`
+	Details  string `json:"details,omitempty"`
+	URL      string `json:"url,omitempty"`
+	ScanID   string `json:"scan_id,omitempty"`
+}
+
+// riskSeverity maps a 0-100 module risk score onto the 0-10 alert scale
+func riskSeverity(score int) int {
+	severity := (score + 5) / 10
+	if severity < 0 {
+		return 0
+	}
+	if severity > 10 {
+		return 10
+	}
+	return severity
+}
+
+// watchFindingSeverity ranks watchlist findings: lookalike domains that accept
+// mail are phishing-ready, then come new breaches, other lookalikes,
+// impersonating profiles and archived captures, with DNS changes last
+func watchFindingSeverity(finding WatchFinding) int {
+	switch finding.Module {
+	case "lookalike":
+		if strings.Contains(finding.Details, "accepts mail") {
+			return 8
+		}
+		return 6
+	case "breach":
+		return 7
+	case "social":
+		return 5
+	case "archive":
+		return 4
+	default:
+		return 3
+	}
+}
+
+// Alerts converts the new findings of a watchlist run into alerts
+func (r *WatchRunResult) Alerts() []Alert {
+	var alerts []Alert
+	for _, item := range r.Items {
+		for _, finding := range item.New {
+			alerts = append(alerts, Alert{
+				ID:       finding.ID,
+				Module:   "watchlist." + finding.Module,
+				Name:     finding.Title,
+				Target:   item.Value,
+				Severity: watchFindingSeverity(finding),
+				Details:  finding.Details,
+				URL:      finding.URL,
+				ScanID:   r.ScanID,
+			})
+		}
+	}
+	return alerts
+}
+
+// Alerts reports breach exposure and malicious infrastructure tied to an email
+func (r *EmailAnalysisResult) Alerts() []Alert {
+	var alerts []Alert
+	if r.SecurityInfo.BreachCount > 0 {
+		alerts = append(alerts, Alert{
+			ID:       "breach:" + r.Email,
+			Module:   "email.breach",
+			Name:     fmt.Sprintf("Email found in %d data breaches", r.SecurityInfo.BreachCount),
+			Target:   r.Email,
+			Severity: riskSeverity(r.SecurityInfo.RiskScore),
+			Details:  strings.Join(r.SecurityInfo.ExposedDataTypes, ", "),
+		})
+	}
+	alerts = append(alerts, ipVerdictAlerts("email.ip_reputation", r.Email, r.IPReputation)...)
+	return alerts
+}
+
+// Alerts reports a phone number's risk assessment when it is not low. The
+// phone module scores trust, so lower scores are more severe.
+func (r *PhoneNumberResult) Alerts() []Alert {
+	risk := r.RiskAssessment
+	if risk.Level == "" || strings.EqualFold(risk.Level, "Low") {
+		return nil
+	}
+	return []Alert{{
+		ID:       "risk:" + r.E164Format,
+		Module:   "phone.risk",
+		Name:     fmt.Sprintf("%s risk phone number", risk.Level),
+		Target:   r.E164Format,
+		Severity: riskSeverity(100 - risk.Score),
+		Details:  strings.Join(append(risk.Indicators, risk.FraudWarnings...), "; "),
+	}}
+}
+
+// Alerts reports malicious reputation verdicts and VirusTotal detections for an IP
+func (r *IPIntelResult) Alerts() []Alert {
+	alerts := ipVerdictAlerts("ip.reputation", r.IP, r.Reputation)
+	if vt := r.VirusTotal; vt != nil && vt.Malicious > 0 {
+		alerts = append(alerts, Alert{
+			ID:       "virustotal:" + r.IP,
+			Module:   "ip.virustotal",
+			Name:     fmt.Sprintf("%d/%d VirusTotal engines flag %s", vt.Malicious, vt.Engines, r.IP),
+			Target:   r.IP,
+			Severity: riskSeverity(100 * vt.Malicious / max(vt.Engines, 1)),
+			URL:      vt.Link,
+		})
+	}
+	return alerts
+}
+
+// ipVerdictAlerts raises an alert for each malicious or suspicious IP verdict
+func ipVerdictAlerts(module, target string, verdicts []IPVerdict) []Alert {
+	var alerts []Alert
+	for _, verdict := range verdicts {
+		severity := 0
+		switch {
+		case verdict.Malicious:
+			severity = 8
+		case verdict.Classification == "suspicious":
+			severity = 5
+		default:
+			continue
+		}
+		if verdict.Score != nil {
+			severity = max(severity, riskSeverity(*verdict.Score))
+		}
+		alerts = append(alerts, Alert{
+			ID:       strings.ToLower(verdict.Provider) + ":" + verdict.IP,
+			Module:   module,
+			Name:     fmt.Sprintf("%s classifies %s as %s", verdict.Provider, verdict.IP, verdict.Classification),
+			Target:   target,
+			Severity: severity,
+			Details:  verdict.Details,
+			URL:      verdict.Link,
+		})
+	}
+	return alerts
+}
+
+// Alerts reports VirusTotal detections for a domain, hosts that could be taken
+// over and senders that could spoof it
+func (r *DomainIntelResult) Alerts() []Alert {
+	var alerts []Alert
+	if vt := r.VirusTotal; vt != nil && vt.Malicious > 0 {
+		alerts = append(alerts, Alert{
+			ID:       "virustotal:" + r.Domain,
+			Module:   "domain.virustotal",
+			Name:     fmt.Sprintf("%d/%d VirusTotal engines flag %s", vt.Malicious, vt.Engines, r.Domain),
+			Target:   r.Domain,
+			Severity: riskSeverity(100 * vt.Malicious / max(vt.Engines, 1)),
+			URL:      vt.Link,
+		})
+	}
+	for _, finding := range r.Takeovers {
+		severity, name := 5, fmt.Sprintf("Dangling %s record on %s", finding.Record, finding.Host)
+		if finding.Vulnerable {
+			severity, name = 8, fmt.Sprintf("%s can be taken over through its %s record", finding.Host, finding.Record)
+		}
+		alerts = append(alerts, Alert{
+			ID:       "takeover:" + finding.Host + ":" + finding.Target,
+			Module:   "domain.takeover",
+			Name:     name,
+			Target:   r.Domain,
+			Severity: severity,
+			Details:  finding.Evidence,
+		})
+	}
+	for _, check := range r.Spoofing {
+		if check.Exposure != "high" {
+			continue
+		}
+		alerts = append(alerts, Alert{
+			ID:       "spoofing:" + r.Domain + ":" + check.Sender,
+			Module:   "domain.spoofing",
+			Name:     fmt.Sprintf("Mail from %s claiming to be %s would pass DMARC", check.Sender, r.Domain),
+			Target:   r.Domain,
+			Severity: 7,
+			Details:  strings.Join(check.Reasons, "; "),
+		})
+	}
+	return alerts
+}
+
+// Alerts reports reputation services that flag a triaged link
+func (r *TriageResult) Alerts() []Alert {
+	var alerts []Alert
+	for _, verdict := range r.Verdicts {
+		if !verdict.Malicious {
+			continue
+		}
+		alerts = append(alerts, Alert{
+			ID:       strings.ToLower(verdict.Provider) + ":" + verdict.URL,
+			Module:   "url.reputation",
+			Name:     fmt.Sprintf("%s flags %s", verdict.Provider, verdict.URL),
+			Target:   r.URL,
+			Severity: 8,
+			Details:  verdict.Category,
+			URL:      verdict.Link,
+		})
+	}
+	if r.Hosting != nil {
+		alerts = append(alerts, r.Hosting.Alerts()...)
+	}
+	return alerts
+}
+
+// Alerts reports buckets listing their objects to anyone
+func (r *BucketScanResult) Alerts() []Alert {
+	var alerts []Alert
+	for _, bucket := range r.Buckets {
+		if !bucket.Public {
+			continue
+		}
+		alerts = append(alerts, Alert{
+			ID:       "bucket:" + bucket.Provider + ":" + bucket.Name,
+			Module:   "buckets." + bucket.Provider,
+			Name:     fmt.Sprintf("Public %s bucket %s lists its objects", bucket.Provider, bucket.Name),
+			Target:   r.Target,
+			Severity: 7,
+			Details:  strings.Join(bucket.Objects, ", "),
+			URL:      bucket.URL,
+			ScanID:   r.ScanID,
+		})
+	}
+	return alerts
+}
+
+// Alerts reports credentials committed to an organization's repositories
+func (r *RepoScanResult) Alerts() []Alert {
+	var alerts []Alert
+	for _, secret := range r.Secrets {
+		alerts = append(alerts, Alert{
+			ID:       fmt.Sprintf("repo-secret:%s/%s:%s:%d", r.Owner, secret.Repo, secret.Path, secret.Line),
+			Module:   "repos",
+			Name:     fmt.Sprintf("%s committed to %s/%s", secret.Kind, r.Owner, secret.Repo),
+			Target:   r.Owner,
+			Severity: 8,
+			Details:  fmt.Sprintf("%s line %d: %s", secret.Path, secret.Line, secret.Value),
+			URL:      secret.URL,
+			ScanID:   r.ScanID,
+		})
+	}
+	return alerts
+}
+
+// Alerts reports credentials and internal hosts given away by the metadata
+// of public container images
+func (r *ContainerScanResult) Alerts() []Alert {
+	var alerts []Alert
+	for _, image := range r.Images {
+		for _, finding := range image.Findings {
+			severity := 4
+			switch finding.Kind {
+			case "credential":
+				severity = 8
+			case "email":
+				continue
+			}
+			alerts = append(alerts, Alert{
+				ID:       fmt.Sprintf("container:%s/%s:%s:%s", image.Registry, image.Name, finding.Source, finding.Value),
+				Module:   "containers",
+				Name:     fmt.Sprintf("%s%s in the %s of image %s/%s", strings.ToUpper(finding.Kind[:1]), finding.Kind[1:], finding.Source, image.Registry, image.Name),
+				Target:   r.Target,
+				Severity: severity,
+				Details:  finding.Value,
+				URL:      image.URL,
+				ScanID:   r.ScanID,
+			})
+		}
+	}
+	return alerts
+}
@@ -0,0 +1,510 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/input"
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/awion/MercuriesOST/public/variations"
+	"github.com/fatih/color"
+	"github.com/nyaruka/phonenumbers"
+	"golang.org/x/sync/errgroup"
+)
+
+// MinAliasLink is the weakest evidence that joins two identifiers into one
+// cluster
+var MinAliasLink = 0.5
+
+// Weights of the evidence linking two identifiers, as the chance each alone
+// means one person
+const (
+	linkSameGravatar    = 0.95
+	linkGravatarHandle  = 0.9
+	linkGravatarAccount = 0.85
+	linkSameHandle      = 0.8
+	linkEmailHandle     = 0.7
+	linkPhoneInHandle   = 0.7
+	linkHandleVariant   = 0.6
+	linkSimilarHandle   = 0.6 // Scaled by the handles' similarity
+	linkSameLocalPart   = 0.5
+
+	// Evidence from package registries and code hosts
+	linkAccountEmail   = 0.95 // An email the registry or host shows for the account
+	linkAuthorURL      = 0.85 // A profile URL given next to an author's email
+	linkSoleMaintainer = 0.7  // The author or repository owner of a package one account maintains
+)
+
+const (
+	// minAliasKey is the shortest handle or user part that links anything;
+	// shorter ones are shared by too many people
+	minAliasKey = 4
+	// minHandleSimilarity is the similarity two handles need to be compared as near-equal
+	minHandleSimilarity = 0.85
+	// maxAliasBlock bounds the identifiers sharing a prefix that are compared
+	// pairwise, so a list full of "john..." handles stays fast
+	maxAliasBlock = 500
+	// maxVariantFanout is the most handles a variant may come from before it
+	// counts as a common name: "john" is a variant of john22, john_90 and
+	// every other john, so it links none of them
+	maxVariantFanout = 5
+	// minPhoneDigits is the shortest national number searched for inside handles
+	minPhoneDigits = 7
+)
+
+// Identifier kinds in an alias list
+const (
+	IdentifierEmail  = "email"
+	IdentifierHandle = "handle"
+	IdentifierPhone  = "phone"
+)
+
+// Identifier is one entry of an alias list
+type Identifier struct {
+	Value string `json:"value"` // Normalized
+	Kind  string `json:"kind"`
+	Line  int    `json:"line,omitempty"`
+
+	key      string            // Handle or user part, lowercased without separators
+	digits   string            // National number of a phone
+	gravatar []GravatarProfile // Profiles of an email, with --gravatar
+}
+
+// RejectedIdentifier is a line that could not be read as any identifier
+type RejectedIdentifier struct {
+	Line  int    `json:"line"`
+	Value string `json:"value"`
+	Error string `json:"error"`
+}
+
+// AliasLink is evidence that two identifiers belong to one person
+type AliasLink struct {
+	A      string  `json:"a"`
+	B      string  `json:"b"`
+	Reason string  `json:"reason"`
+	Weight float64 `json:"weight"`
+}
+
+// AliasCluster is a set of identifiers probably belonging to one person
+type AliasCluster struct {
+	ID         int          `json:"id"`
+	Members    []Identifier `json:"members"`
+	Evidence   []AliasLink  `json:"evidence"`
+	Confidence float64      `json:"confidence"` // Weakest link holding the cluster together
+}
+
+// AliasClusterResult holds the clusters found in an alias list
+type AliasClusterResult struct {
+	ScanID          string               `json:"scan_id,omitempty"`
+	Source          string               `json:"source"`
+	SearchTimestamp string               `json:"search_timestamp"`
+	Identifiers     int                  `json:"identifiers"`
+	Clusters        []AliasCluster       `json:"clusters"`
+	Unclustered     []Identifier         `json:"unclustered"`
+	Rejected        []RejectedIdentifier `json:"rejected,omitempty"`
+	PartialErrors   []ModuleError        `json:"partial_errors,omitempty"`
+	ExecutionTime   string               `json:"execution_time"`
+}
+
+// ParseIdentifier classifies and normalizes one line of an alias list. A
+// line may name its kind with an "email:", "phone:" or "handle:" prefix;
+// otherwise anything with an @ after its first character is an email,
+// anything starting with + or 00 or made of digits is a phone, and the rest
+// are handles.
+func ParseIdentifier(line string) (Identifier, error) {
+	line = strings.TrimSpace(line)
+	kind := ""
+	if prefix, rest, found := strings.Cut(line, ":"); found {
+		switch strings.ToLower(prefix) {
+		case IdentifierEmail, IdentifierPhone, IdentifierHandle:
+			kind, line = strings.ToLower(prefix), strings.TrimSpace(rest)
+		}
+	}
+	if kind == "" {
+		switch {
+		case strings.Index(line, "@") > 0:
+			kind = IdentifierEmail
+		case strings.HasPrefix(line, "+") || strings.HasPrefix(line, "00") ||
+			strings.Trim(line, "0123456789 -.()") == "" && countDigits(line) >= minPhoneDigits:
+			kind = IdentifierPhone
+		default:
+			kind = IdentifierHandle
+		}
+	}
+
+	id := Identifier{Kind: kind}
+	var err error
+	switch kind {
+	case IdentifierEmail:
+		id.Value, err = input.Email(line)
+		if err == nil {
+			local := id.Value[:strings.LastIndex(id.Value, "@")]
+			id.key = comparableLocalPart(local)
+		}
+	case IdentifierPhone:
+		id.Value, err = input.Phone(line)
+		if err == nil {
+			id.digits = nationalDigits(id.Value)
+		}
+	default:
+		id.Value, err = input.Handle(line)
+		if err == nil {
+			id.Value = strings.ToLower(id.Value)
+			id.key = comparableLocalPart(id.Value)
+		}
+	}
+	return id, err
+}
+
+// countDigits counts the ASCII digits in s
+func countDigits(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+// nationalDigits returns the national number of an E.164 number, the digits
+// people put in handles
+func nationalDigits(e164 string) string {
+	number, err := phonenumbers.Parse(e164, "")
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprint(number.GetNationalNumber())
+}
+
+// ClusterAliases groups the identifiers of an alias list into probable
+// identities. With gravatar set, emails are looked up on Gravatar so their
+// public profiles can link them to handles.
+func ClusterAliases(ctx context.Context, source string, lines []string, gravatar bool) (*AliasClusterResult, error) {
+	startTime := time.Now()
+	result := &AliasClusterResult{
+		ScanID:          providers.ScanIDFrom(ctx),
+		Source:          source,
+		SearchTimestamp: time.Now().Format(time.RFC3339),
+		Clusters:        []AliasCluster{},
+		Unclustered:     []Identifier{},
+	}
+
+	// Parse, dropping duplicates after normalization
+	var ids []*Identifier
+	seen := make(map[string]*Identifier)
+	for i, line := range lines {
+		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, err := ParseIdentifier(line)
+		if err != nil {
+			result.Rejected = append(result.Rejected, RejectedIdentifier{Line: i + 1, Value: line, Error: err.Error()})
+			continue
+		}
+		id.Line = i + 1
+		if seen[id.Kind+":"+strings.ToLower(id.Value)] != nil {
+			continue
+		}
+		seen[id.Kind+":"+strings.ToLower(id.Value)] = &id
+		ids = append(ids, &id)
+	}
+	result.Identifiers = len(ids)
+	if len(ids) == 0 {
+		return result, fmt.Errorf("no identifiers found in %s", source)
+	}
+
+	if gravatar {
+		result.PartialErrors = lookupAliasGravatars(ctx, ids)
+	}
+
+	links := aliasLinks(ids)
+	result.Clusters, result.Unclustered = clusterLinks(ids, links)
+	result.ExecutionTime = time.Since(startTime).String()
+	return result, nil
+}
+
+// lookupAliasGravatars fetches the Gravatar profiles of every email
+func lookupAliasGravatars(ctx context.Context, ids []*Identifier) []ModuleError {
+	var mu sync.Mutex
+	var errs []ModuleError
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(ConcurrentRequests)
+	for _, id := range ids {
+		if id.Kind != IdentifierEmail {
+			continue
+		}
+		g.Go(func() error {
+			profiles, err := fetchGravatar(ctx, gravatarHash(id.Value))
+			mu.Lock()
+			defer mu.Unlock()
+			id.gravatar = profiles
+			if err != nil {
+				errs = append(errs, ModuleError{Module: "gravatar " + id.Value, Error: err.Error()})
+			}
+			return nil
+		})
+	}
+	g.Wait()
+	return errs
+}
+
+// aliasLinks finds the evidence between identifiers. Exact keys are indexed,
+// and only identifiers sharing a key prefix are compared for similarity, so
+// long lists are not compared pairwise.
+func aliasLinks(ids []*Identifier) []AliasLink {
+	best := make(map[[2]int]AliasLink)
+	link := func(i, j int, reason string, weight float64) {
+		if i == j {
+			return
+		}
+		if i > j {
+			i, j = j, i
+		}
+		pair := [2]int{i, j}
+		if existing, ok := best[pair]; !ok || weight > existing.Weight {
+			best[pair] = AliasLink{A: ids[i].Value, B: ids[j].Value, Reason: reason, Weight: weight}
+		}
+	}
+
+	byKey := make(map[string][]int)
+	byVariant := make(map[string][]int)
+	byPrefix := make(map[string][]int)
+	byGravatarURL := make(map[string][]int)
+	for i, id := range ids {
+		if len(id.key) >= minAliasKey {
+			byKey[id.key] = append(byKey[id.key], i)
+			byPrefix[id.key[:minAliasKey]] = append(byPrefix[id.key[:minAliasKey]], i)
+			for _, variant := range variations.HandleVariants(id.key) {
+				if len(variant) >= minAliasKey {
+					byVariant[variant] = append(byVariant[variant], i)
+				}
+			}
+		}
+		for _, profile := range id.gravatar {
+			if profile.ProfileURL != "" {
+				byGravatarURL[strings.ToLower(profile.ProfileURL)] = append(byGravatarURL[strings.ToLower(profile.ProfileURL)], i)
+			}
+		}
+	}
+
+	// Same handle or user part, ignoring case and separators
+	for key, group := range byKey {
+		for x, i := range group {
+			for _, j := range group[x+1:] {
+				a, b := ids[i], ids[j]
+				switch {
+				case a.Kind == IdentifierEmail && b.Kind == IdentifierEmail:
+					link(i, j, "same user part "+key, linkSameLocalPart)
+				case a.Kind == IdentifierEmail || b.Kind == IdentifierEmail:
+					link(i, j, "email user part matches handle "+key, linkEmailHandle)
+				default:
+					link(i, j, "same handle ignoring case and separators", linkSameHandle)
+				}
+			}
+		}
+	}
+
+	// Near-variants: one handle is a variant of the other
+	for variant, group := range byVariant {
+		if len(group) > maxVariantFanout {
+			continue
+		}
+		for _, i := range group {
+			for _, j := range byKey[variant] {
+				link(i, j, fmt.Sprintf("%s is a near-variant of %s", ids[j].key, ids[i].key), linkHandleVariant)
+			}
+		}
+	}
+
+	// Similar handles within a prefix block
+	for _, group := range byPrefix {
+		if len(group) > maxAliasBlock {
+			continue
+		}
+		for x, i := range group {
+			for _, j := range group[x+1:] {
+				if ids[i].key == ids[j].key {
+					continue
+				}
+				if similarity := variations.HandleSimilarity(ids[i].key, ids[j].key); similarity >= minHandleSimilarity {
+					link(i, j, fmt.Sprintf("similar handles (%.2f)", similarity), linkSimilarHandle*similarity)
+				}
+			}
+		}
+	}
+
+	// Phone numbers written into handles and user parts
+	for i, phone := range ids {
+		if len(phone.digits) < minPhoneDigits {
+			continue
+		}
+		for j, id := range ids {
+			if id.key != "" && strings.Contains(id.key, phone.digits) {
+				link(i, j, "phone number appears in "+id.Value, linkPhoneInHandle)
+			}
+		}
+	}
+
+	// Gravatar profiles shared by emails, and naming or verifying handles
+	for _, group := range byGravatarURL {
+		for x, i := range group {
+			for _, j := range group[x+1:] {
+				link(i, j, "same Gravatar profile", linkSameGravatar)
+			}
+		}
+	}
+	for i, id := range ids {
+		for _, profile := range id.gravatar {
+			if key := comparableLocalPart(profile.Username); len(key) >= minAliasKey {
+				for _, j := range byKey[key] {
+					if ids[j].Kind == IdentifierHandle {
+						link(i, j, "Gravatar username of "+id.Value, linkGravatarHandle)
+					}
+				}
+			}
+			for _, account := range append(append([]string{}, profile.Accounts...), profile.URLs...) {
+				if key := accountHandle(account); len(key) >= minAliasKey {
+					for _, j := range byKey[key] {
+						if ids[j].Kind == IdentifierHandle {
+							link(i, j, "account "+account+" on the Gravatar of "+id.Value, linkGravatarAccount)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	links := make([]AliasLink, 0, len(best))
+	for _, l := range best {
+		links = append(links, l)
+	}
+	return links
+}
+
+// accountHandle returns the comparable handle at the end of a profile URL
+func accountHandle(link string) string {
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	return comparableLocalPart(strings.TrimPrefix(path.Base(strings.TrimSuffix(parsed.Path, "/")), "@"))
+}
+
+// clusterLinks joins identifiers along links of at least MinAliasLink,
+// strongest first. A cluster's confidence is the weakest link that was
+// needed to join it, and its evidence every qualifying link inside it.
+func clusterLinks(ids []*Identifier, links []AliasLink) ([]AliasCluster, []Identifier) {
+	index := make(map[string]int, len(ids))
+	for i, id := range ids {
+		index[id.Value] = i
+	}
+	parent := make([]int, len(ids))
+	weakest := make([]float64, len(ids))
+	for i := range parent {
+		parent[i] = i
+		weakest[i] = 1
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Weight != links[j].Weight {
+			return links[i].Weight > links[j].Weight
+		}
+		return links[i].A+links[i].B < links[j].A+links[j].B
+	})
+	var kept []AliasLink
+	for _, l := range links {
+		if l.Weight < MinAliasLink {
+			break
+		}
+		kept = append(kept, l)
+		a, b := find(index[l.A]), find(index[l.B])
+		if a != b {
+			parent[b] = a
+			weakest[a] = min(weakest[a], weakest[b], l.Weight)
+		}
+	}
+
+	size := make(map[int]int)
+	for i := range ids {
+		size[find(i)]++
+	}
+	groups := make(map[int]*AliasCluster)
+	var order []int
+	unclustered := []Identifier{}
+	for i := range ids {
+		root := find(i)
+		if size[root] == 1 {
+			unclustered = append(unclustered, *ids[i])
+			continue
+		}
+		if groups[root] == nil {
+			groups[root] = &AliasCluster{Confidence: weakest[root]}
+			order = append(order, root)
+		}
+		groups[root].Members = append(groups[root].Members, *ids[i])
+	}
+	for _, l := range kept {
+		root := find(index[l.A])
+		groups[root].Evidence = append(groups[root].Evidence, l)
+	}
+
+	clusters := make([]AliasCluster, 0, len(order))
+	for _, root := range order {
+		clusters = append(clusters, *groups[root])
+	}
+	sort.SliceStable(clusters, func(i, j int) bool { return len(clusters[i].Members) > len(clusters[j].Members) })
+	for i := range clusters {
+		clusters[i].ID = i + 1
+	}
+	return clusters, unclustered
+}
+
+// DisplayResults prints the clusters with the evidence joining them
+func (r *AliasClusterResult) DisplayResults() {
+	color.Cyan("\n=== ALIAS CLUSTERS ===")
+	color.Yellow("Source: %s", r.Source)
+	color.White("• %d identifiers, %d clusters, %d unclustered, %d rejected",
+		r.Identifiers, len(r.Clusters), len(r.Unclustered), len(r.Rejected))
+
+	for _, cluster := range r.Clusters {
+		color.Cyan("\n[Cluster %d] %d identifiers, confidence %.2f", cluster.ID, len(cluster.Members), cluster.Confidence)
+		for _, member := range cluster.Members {
+			color.Green("• %s (%s, line %d)", member.Value, member.Kind, member.Line)
+		}
+		for _, l := range cluster.Evidence {
+			color.White("  %s ↔ %s: %s (%.2f)", l.A, l.B, l.Reason, l.Weight)
+		}
+	}
+
+	if len(r.Unclustered) > 0 {
+		color.Cyan("\n[Unclustered]")
+		for _, id := range r.Unclustered {
+			color.White("• %s (%s)", id.Value, id.Kind)
+		}
+	}
+	if len(r.Rejected) > 0 {
+		color.Cyan("\n[Rejected Lines]")
+		for _, rejected := range r.Rejected {
+			color.Red("• line %d: %s", rejected.Line, rejected.Error)
+		}
+	}
+}
+
+// DisplayPartialErrors prints the Gravatar lookups that failed
+func (r *AliasClusterResult) DisplayPartialErrors() {
+	displayModuleErrors(r.PartialErrors)
+}
@@ -0,0 +1,275 @@
+package osint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+const (
+	maxAmazonLists = 5  // Lists read from one search
+	maxAmazonItems = 25 // Items kept per list
+)
+
+var (
+	amazonListIDRegex   = regexp.MustCompile(`/(?:hz/wishlist/ls|registry/wishlist|gp/registry/wishlist)/([A-Z0-9]{10,16})`)
+	amazonBareListRegex = regexp.MustCompile(`^[A-Z0-9]{10,16}$`)
+	amazonASINRegex     = regexp.MustCompile(`/dp/([A-Z0-9]{10})`)
+	amazonShippingRegex = regexp.MustCompile(`(?i)shipping address\s*:?\s*([^\n]{2,80})`)
+	amazonStoreTitle    = regexp.MustCompile(`^(?:Amazon\.com:\s*)?(.+?)(?:'s? (?:Amazon )?(?:Page|Storefront))?$`)
+)
+
+// AmazonItem is an item on a public Amazon list
+type AmazonItem struct {
+	Title string `json:"title"`
+	ASIN  string `json:"asin,omitempty"`
+	Price string `json:"price,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// AmazonList is a public wishlist or registry
+type AmazonList struct {
+	ID       string       `json:"id"`
+	URL      string       `json:"url"`
+	Name     string       `json:"name,omitempty"`
+	Owner    string       `json:"owner,omitempty"`
+	Shipping string       `json:"shipping_hint,omitempty"` // Name and city of the gift address, when the owner shows it
+	Items    []AmazonItem `json:"items,omitempty"`
+}
+
+// AmazonStorefront is an influencer storefront under a handle
+type AmazonStorefront struct {
+	Handle      string   `json:"handle"`
+	URL         string   `json:"url"`
+	DisplayName string   `json:"display_name,omitempty"`
+	Lists       []string `json:"lists,omitempty"`
+}
+
+// AmazonResult holds the public Amazon lists and storefront of a target
+type AmazonResult struct {
+	Target        string            `json:"target"`
+	ScanID        string            `json:"scan_id,omitempty"`
+	Storefront    *AmazonStorefront `json:"storefront,omitempty"`
+	Lists         []AmazonList      `json:"lists"`
+	PartialErrors []ModuleError     `json:"partial_errors,omitempty"`
+}
+
+// FindAmazonLists looks for a person's public Amazon lists. A list URL or
+// ID is read directly; anything else is searched in Amazon's list finder,
+// and a handle is also tried as an influencer storefront.
+func FindAmazonLists(ctx context.Context, target string) (*AmazonResult, error) {
+	result := &AmazonResult{Target: target, ScanID: providers.ScanIDFrom(ctx)}
+	fail := func(module string, err error) {
+		result.PartialErrors = append(result.PartialErrors, ModuleError{Module: "amazon." + module, Error: err.Error()})
+	}
+
+	var ids []string
+	if id := amazonListID(target); id != "" {
+		ids = []string{id}
+	} else {
+		if !strings.Contains(target, " ") {
+			storefront, err := amazonStorefront(ctx, target)
+			if err != nil {
+				fail("storefront", err)
+			}
+			result.Storefront = storefront
+		}
+		found, err := searchAmazonLists(ctx, target)
+		if err != nil {
+			fail("search", err)
+		}
+		ids = found
+	}
+
+	for _, id := range ids {
+		list, err := amazonList(ctx, id)
+		if err != nil {
+			fail("list."+id, err)
+			continue
+		}
+		if list != nil {
+			result.Lists = append(result.Lists, *list)
+		}
+	}
+	return result, ctx.Err()
+}
+
+// amazonListID returns the list ID of a list URL or a bare list ID
+func amazonListID(target string) string {
+	if m := amazonListIDRegex.FindStringSubmatch(target); m != nil {
+		return m[1]
+	}
+	if amazonBareListRegex.MatchString(target) {
+		return target
+	}
+	return ""
+}
+
+// fetchAmazonPage reads an Amazon page, returning nil for missing pages.
+// Amazon answers automated traffic it distrusts with a CAPTCHA, which is
+// reported instead of read as an empty page.
+func fetchAmazonPage(ctx context.Context, target string) (*goquery.Document, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	resp, err := doProviderRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := readPageBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(body.Data, []byte("/errors/validateCaptcha")) {
+		return nil, fmt.Errorf("Amazon answered with a CAPTCHA; try again later or through --proxy")
+	}
+	return goquery.NewDocumentFromReader(bytes.NewReader(body.Data))
+}
+
+// searchAmazonLists finds the lists whose owners let them be found by name
+func searchAmazonLists(ctx context.Context, name string) ([]string, error) {
+	query := url.Values{"type": {"wishlist"}, "field-name": {name}}
+	doc, err := fetchAmazonPage(ctx, "https://www.amazon.com/gp/registry/search?"+query.Encode())
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	var ids []string
+	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		if m := amazonListIDRegex.FindStringSubmatch(href); m != nil && len(ids) < maxAmazonLists && !slices.Contains(ids, m[1]) {
+			ids = append(ids, m[1])
+		}
+	})
+	return ids, nil
+}
+
+// amazonList reads a public list's name, owner, items and the shipping
+// hint shown when the owner attached a gift address. A missing or private
+// list is nil without an error.
+func amazonList(ctx context.Context, id string) (*AmazonList, error) {
+	listURL := "https://www.amazon.com/hz/wishlist/ls/" + id
+	doc, err := fetchAmazonPage(ctx, listURL)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	list := &AmazonList{
+		ID:    id,
+		URL:   listURL,
+		Name:  cleanText(doc.Find("#profile-list-name").First().Text()),
+		Owner: cleanText(doc.Find("#list-owner-name, .g-list-owner-name").First().Text()),
+	}
+	if list.Name == "" && doc.Find("li[data-itemid]").Length() == 0 {
+		// Private lists show a sign-in page under the same URL
+		return nil, nil
+	}
+
+	doc.Find("li[data-itemid]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		link := s.Find("a[id^='itemName_']").First()
+		title, _ := link.Attr("title")
+		if title == "" {
+			title = link.Text()
+		}
+		item := AmazonItem{Title: cleanText(title), Price: cleanText(s.Find("span.a-price span.a-offscreen").First().Text())}
+		if href, ok := link.Attr("href"); ok {
+			if m := amazonASINRegex.FindStringSubmatch(href); m != nil {
+				item.ASIN = m[1]
+				item.URL = "https://www.amazon.com/dp/" + m[1]
+			}
+		}
+		if item.Title != "" {
+			list.Items = append(list.Items, item)
+		}
+		return len(list.Items) < maxAmazonItems
+	})
+
+	if m := amazonShippingRegex.FindStringSubmatch(doc.Find("#wishlist-page, body").First().Text()); m != nil {
+		list.Shipping = cleanText(m[1])
+	}
+	return list, nil
+}
+
+// amazonStorefront reads the influencer storefront at amazon.com/shop/handle.
+// A handle without one is nil without an error.
+func amazonStorefront(ctx context.Context, handle string) (*AmazonStorefront, error) {
+	storeURL := "https://www.amazon.com/shop/" + url.PathEscape(strings.ToLower(handle))
+	doc, err := fetchAmazonPage(ctx, storeURL)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	title, _ := doc.Find(`meta[property="og:title"]`).Attr("content")
+	if title == "" {
+		// Unknown handles land on the storefront program's home page
+		return nil, nil
+	}
+	storefront := &AmazonStorefront{Handle: handle, URL: storeURL}
+	if m := amazonStoreTitle.FindStringSubmatch(cleanText(title)); m != nil {
+		storefront.DisplayName = m[1]
+	}
+	doc.Find("a[href*='/list/']").Each(func(i int, s *goquery.Selection) {
+		if name := cleanText(s.Text()); name != "" && !slices.Contains(storefront.Lists, name) {
+			storefront.Lists = append(storefront.Lists, name)
+		}
+	})
+	return storefront, nil
+}
+
+// DisplayResults prints the storefront and lists found
+func (r *AmazonResult) DisplayResults() {
+	color.Cyan("\n=== AMAZON LISTS: %s ===", r.Target)
+	if r.Storefront == nil && len(r.Lists) == 0 {
+		color.Yellow("No public Amazon lists or storefront found")
+		return
+	}
+	if store := r.Storefront; store != nil {
+		color.Green("\nStorefront: %s", store.URL)
+		if store.DisplayName != "" {
+			fmt.Printf("  Name: %s\n", store.DisplayName)
+		}
+		for _, list := range store.Lists {
+			fmt.Printf("  • %s\n", list)
+		}
+	}
+	for _, list := range r.Lists {
+		color.Green("\n%s", list.URL)
+		if list.Name != "" {
+			fmt.Printf("  List: %s\n", list.Name)
+		}
+		if list.Owner != "" {
+			fmt.Printf("  Owner: %s\n", list.Owner)
+		}
+		if list.Shipping != "" {
+			color.Yellow("  Ships to: %s", list.Shipping)
+		}
+		for _, item := range list.Items {
+			line := "  • " + item.Title
+			if item.Price != "" {
+				line += " (" + item.Price + ")"
+			}
+			fmt.Println(line)
+		}
+	}
+}
+
+// DisplayPartialErrors prints the lookups that failed
+func (r *AmazonResult) DisplayPartialErrors() {
+	displayModuleErrors(r.PartialErrors)
+}
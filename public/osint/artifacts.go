@@ -0,0 +1,47 @@
+package osint
+
+import "strings"
+
+// Artifact types
+const (
+	ArtifactEmail  = "email"
+	ArtifactName   = "name"
+	ArtifactURL    = "url"
+	ArtifactPhone  = "phone"
+	ArtifactHandle = "handle"
+)
+
+// Artifact is a piece of identifying data discovered by a module that other
+// modules can pivot on
+type Artifact struct {
+	Type    string `json:"type"`
+	Value   string `json:"value"`
+	Source  string `json:"source"`
+	Context string `json:"context,omitempty"`
+}
+
+// dedupeArtifacts removes repeated type/value pairs, keeping the first occurrence
+func dedupeArtifacts(artifacts []Artifact) []Artifact {
+	seen := make(map[string]bool)
+	var unique []Artifact
+	for _, artifact := range artifacts {
+		key := artifact.Type + "|" + strings.ToLower(artifact.Value)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, artifact)
+	}
+	return unique
+}
+
+// artifactValues returns the values of all artifacts of the given type
+func artifactValues(artifacts []Artifact, artifactType string) []string {
+	var values []string
+	for _, artifact := range artifacts {
+		if artifact.Type == artifactType {
+			values = append(values, artifact.Value)
+		}
+	}
+	return values
+}
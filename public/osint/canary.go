@@ -0,0 +1,166 @@
+package osint
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/awion/MercuriesOST/public/assets/datasets"
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// TouchCanaries lets scans target and fetch canary tokens and callback
+// domains. By default they are skipped so an investigation does not alert
+// whoever planted them.
+var TouchCanaries = false
+
+// CanaryMatch is a value recognised as a canary token or honeypot endpoint
+type CanaryMatch struct {
+	Value string `json:"value"`
+	Name  string `json:"name"` // Who runs it or what kind of token it is
+}
+
+// CanaryError is returned for a request that was not sent because its target is a canary
+type CanaryError struct {
+	Match CanaryMatch
+}
+
+func (e *CanaryError) Error() string {
+	return fmt.Sprintf("skipped %s: looks like a canary (%s)", e.Match.Value, e.Match.Name)
+}
+
+var (
+	canaryMu       sync.Mutex
+	canaryPatterns []*regexp.Regexp
+	canaryNames    []string
+	canarySkipped  []CanaryMatch
+)
+
+// MatchCanary checks a target or discovered artifact, whether a URL, email
+// address, domain or free text, against the canary dataset
+func MatchCanary(value string) *CanaryMatch {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	list := datasets.Canaries()
+
+	if host := canaryHost(value); host != "" {
+		for domain, name := range list.Domains {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return &CanaryMatch{Value: value, Name: name}
+			}
+		}
+	}
+
+	patterns, names := compiledCanaryTokens(list)
+	for i, pattern := range patterns {
+		if pattern.MatchString(value) {
+			return &CanaryMatch{Value: value, Name: names[i]}
+		}
+	}
+	return nil
+}
+
+// canaryHost returns the host a URL, email address or bare domain points at
+func canaryHost(value string) string {
+	if strings.Contains(value, "://") {
+		if parsed, err := url.Parse(value); err == nil {
+			return strings.ToLower(parsed.Hostname())
+		}
+		return ""
+	}
+	if at := strings.LastIndex(value, "@"); at >= 0 {
+		value = value[at+1:]
+	}
+	if strings.ContainsAny(value, " /") {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSuffix(value, "."))
+}
+
+// compiledCanaryTokens compiles the dataset's token patterns once
+func compiledCanaryTokens(list datasets.CanaryList) ([]*regexp.Regexp, []string) {
+	canaryMu.Lock()
+	defer canaryMu.Unlock()
+
+	if canaryPatterns == nil {
+		canaryPatterns = []*regexp.Regexp{}
+		for _, token := range list.Tokens {
+			pattern, err := regexp.Compile("(?i)" + token.Pattern)
+			if err != nil {
+				continue
+			}
+			canaryPatterns = append(canaryPatterns, pattern)
+			canaryNames = append(canaryNames, token.Name)
+		}
+	}
+	return canaryPatterns, canaryNames
+}
+
+// GuardCanaries stops every request to a canary host unless TouchCanaries is
+// set, covering links, redirects and scripts found during a scan
+func GuardCanaries() {
+	if TouchCanaries {
+		return
+	}
+	providers.Use(&providers.Guard{Base: providers.Transport, Check: checkCanaryRequest})
+}
+
+// checkCanaryRequest rejects a request whose URL is a canary
+func checkCanaryRequest(req *http.Request) error {
+	if match := SkipCanary(req.URL.String()); match != nil {
+		return &CanaryError{Match: *match}
+	}
+	return nil
+}
+
+// SkipCanary reports a discovered artifact that must not be looked up, also
+// by DNS, which the request guard cannot see. The match is remembered for the
+// run summary. Nothing is skipped when TouchCanaries is set.
+func SkipCanary(value string) *CanaryMatch {
+	if TouchCanaries {
+		return nil
+	}
+	match := MatchCanary(value)
+	if match == nil {
+		return nil
+	}
+
+	canaryMu.Lock()
+	canarySkipped = append(canarySkipped, *match)
+	canaryMu.Unlock()
+	return match
+}
+
+// SkippedCanaries returns the canary requests stopped during the run, once each
+func SkippedCanaries() []CanaryMatch {
+	canaryMu.Lock()
+	defer canaryMu.Unlock()
+
+	seen := make(map[string]bool)
+	var skipped []CanaryMatch
+	for _, match := range canarySkipped {
+		if !seen[match.Value] {
+			seen[match.Value] = true
+			skipped = append(skipped, match)
+		}
+	}
+	return skipped
+}
+
+// DisplaySkippedCanaries warns about the canaries the run avoided touching
+func DisplaySkippedCanaries() {
+	skipped := SkippedCanaries()
+	if len(skipped) == 0 {
+		return
+	}
+	color.Yellow("\nSkipped %d canary or honeypot endpoints (use --touch-canaries to fetch them):", len(skipped))
+	for _, match := range skipped {
+		fmt.Printf("  %s (%s)\n", match.Value, match.Name)
+	}
+}
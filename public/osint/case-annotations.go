@@ -0,0 +1,258 @@
+package osint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// AnnotationNote is an analyst's note on a finding
+type AnnotationNote struct {
+	Text   string `json:"text"`
+	Author string `json:"author,omitempty"`
+	Added  string `json:"added"`
+}
+
+// FindingAnnotation is what analysts added to one finding of a case. A
+// finding is a document of the case's index, by the number search shows, or
+// an alert, by the ID forwarded to syslog and TheHive.
+type FindingAnnotation struct {
+	Finding  string           `json:"finding"`
+	Tags     []string         `json:"tags,omitempty"`
+	Severity *int             `json:"severity,omitempty"` // Replaces the alert's, 0 to 10
+	Notes    []AnnotationNote `json:"notes,omitempty"`
+	Updated  string           `json:"updated"`
+}
+
+// CaseAnnotations holds the annotations of a case's findings, kept in
+// <OutputDir>/cases/<name>/annotations.json
+type CaseAnnotations struct {
+	Case     string              `json:"case"`
+	Findings []FindingAnnotation `json:"findings"`
+
+	path string
+}
+
+// AnnotationChange is an edit to a finding's annotation. Empty fields are
+// left as they are.
+type AnnotationChange struct {
+	AddTags       []string
+	RemoveTags    []string
+	Note          string
+	Author        string
+	Severity      *int
+	ClearSeverity bool
+}
+
+// LoadCaseAnnotations reads a case's annotations, or starts none
+func LoadCaseAnnotations(name string) (*CaseAnnotations, error) {
+	if err := ValidateCaseName(name); err != nil {
+		return nil, err
+	}
+	return loadCaseAnnotations(name, filepath.Join(CaseDir(name), "annotations.json"))
+}
+
+// loadCaseAnnotations reads the annotations kept in a file, or starts none
+func loadCaseAnnotations(name, path string) (*CaseAnnotations, error) {
+	annotations := &CaseAnnotations{Case: name, path: path}
+	data, err := os.ReadFile(annotations.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return annotations, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, annotations); err != nil {
+		return nil, fmt.Errorf("reading %s: %v", annotations.path, err)
+	}
+	annotations.Case = name
+	return annotations, nil
+}
+
+// CaseFindingID checks a finding ID against a case. Document numbers must be
+// in the case's index; alert IDs, which name their kind before a colon, are
+// taken as given since alerts are not filed.
+func CaseFindingID(name, id string) (string, error) {
+	id = strings.TrimPrefix(strings.TrimSpace(id), "#")
+	if n, err := strconv.Atoi(id); err == nil {
+		index, err := OpenCaseIndex(name)
+		if err != nil {
+			return "", err
+		}
+		if n < 0 || n >= len(index.Documents) {
+			return "", fmt.Errorf("case %s has no document #%d; search lists the documents' numbers", name, n)
+		}
+		return strconv.Itoa(n), nil
+	}
+	if kind, rest, found := strings.Cut(id, ":"); !found || kind == "" || rest == "" {
+		return "", fmt.Errorf("%q is neither a document number nor an alert ID", id)
+	}
+	return id, nil
+}
+
+// Find returns a finding's annotation, or nil when it has none
+func (a *CaseAnnotations) Find(finding string) *FindingAnnotation {
+	for i := range a.Findings {
+		if a.Findings[i].Finding == finding {
+			return &a.Findings[i]
+		}
+	}
+	return nil
+}
+
+// Annotate applies a change to a finding's annotation and returns it.
+// Tags are lowercased, and a finding left with nothing is dropped.
+func (a *CaseAnnotations) Annotate(finding string, change AnnotationChange) (*FindingAnnotation, error) {
+	if s := change.Severity; s != nil && (*s < 0 || *s > 10) {
+		return nil, fmt.Errorf("severity must be between 0 and 10")
+	}
+	annotation := a.Find(finding)
+	if annotation == nil {
+		a.Findings = append(a.Findings, FindingAnnotation{Finding: finding})
+		annotation = &a.Findings[len(a.Findings)-1]
+	}
+
+	for _, tag := range change.AddTags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" && !slices.Contains(annotation.Tags, tag) {
+			annotation.Tags = append(annotation.Tags, tag)
+		}
+	}
+	for _, tag := range change.RemoveTags {
+		annotation.Tags = slices.DeleteFunc(annotation.Tags, func(t string) bool { return strings.EqualFold(t, strings.TrimSpace(tag)) })
+	}
+	sort.Strings(annotation.Tags)
+	if change.ClearSeverity {
+		annotation.Severity = nil
+	}
+	if change.Severity != nil {
+		severity := *change.Severity
+		annotation.Severity = &severity
+	}
+	if note := strings.TrimSpace(change.Note); note != "" {
+		annotation.Notes = append(annotation.Notes, AnnotationNote{Text: note, Author: change.Author, Added: time.Now().Format(time.RFC3339)})
+	}
+	annotation.Updated = time.Now().Format(time.RFC3339)
+
+	if len(annotation.Tags) == 0 && annotation.Severity == nil && len(annotation.Notes) == 0 {
+		a.Findings = slices.DeleteFunc(a.Findings, func(f FindingAnnotation) bool { return f.Finding == finding })
+		return nil, nil
+	}
+	return annotation, nil
+}
+
+// merge adds another case's annotation of a finding: tags and notes are
+// joined, and the severity set last is kept
+func (a *CaseAnnotations) merge(other FindingAnnotation) {
+	annotation := a.Find(other.Finding)
+	if annotation == nil {
+		a.Findings = append(a.Findings, other)
+		return
+	}
+	for _, tag := range other.Tags {
+		if !slices.Contains(annotation.Tags, tag) {
+			annotation.Tags = append(annotation.Tags, tag)
+		}
+	}
+	sort.Strings(annotation.Tags)
+	for _, note := range other.Notes {
+		if !slices.Contains(annotation.Notes, note) {
+			annotation.Notes = append(annotation.Notes, note)
+		}
+	}
+	sort.SliceStable(annotation.Notes, func(i, j int) bool { return annotation.Notes[i].Added < annotation.Notes[j].Added })
+	if other.Severity != nil && (annotation.Severity == nil || other.Updated > annotation.Updated) {
+		annotation.Severity = other.Severity
+	}
+	annotation.Updated = max(annotation.Updated, other.Updated)
+}
+
+// Save writes the annotations, replacing the previous file only once it is
+// complete
+func (a *CaseAnnotations) Save() error {
+	if err := os.MkdirAll(filepath.Dir(a.path), 0755); err != nil {
+		return err
+	}
+	sort.Slice(a.Findings, func(i, j int) bool { return a.Findings[i].Finding < a.Findings[j].Finding })
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := a.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, a.path)
+}
+
+// ApplyAlerts gives alerts the severity analysts set for them in the case
+func (a *CaseAnnotations) ApplyAlerts(alerts []Alert) {
+	for i := range alerts {
+		if annotation := a.Find(alerts[i].ID); annotation != nil && annotation.Severity != nil {
+			alerts[i].Severity = *annotation.Severity
+		}
+	}
+}
+
+// ApplySearch attaches the annotations of the documents found
+func (a *CaseAnnotations) ApplySearch(results *CaseSearchResults) {
+	for i := range results.Hits {
+		results.Hits[i].Annotation = a.Find(strconv.Itoa(results.Hits[i].Document.ID))
+	}
+}
+
+// Display prints an annotation under a finding, indented
+func (f *FindingAnnotation) Display(indent string) {
+	var labels []string
+	if f.Severity != nil {
+		labels = append(labels, fmt.Sprintf("severity %d", *f.Severity))
+	}
+	for _, tag := range f.Tags {
+		labels = append(labels, "#"+tag)
+	}
+	if len(labels) > 0 {
+		color.Magenta("%s%s", indent, strings.Join(labels, "  "))
+	}
+	for _, note := range f.Notes {
+		line := indent + "Note: " + note.Text
+		if note.Author != "" {
+			line += " (" + note.Author + ", " + note.Added + ")"
+		} else {
+			line += " (" + note.Added + ")"
+		}
+		color.White("%s", line)
+	}
+}
+
+// DisplayResults prints every annotated finding of the case, with the text
+// of annotated documents from the case's index when it is given
+func (a *CaseAnnotations) DisplayResults(index *CaseIndex) {
+	if len(a.Findings) == 0 {
+		color.Yellow("\nNo findings annotated yet")
+		return
+	}
+	color.Green("\nAnnotated findings: %d", len(a.Findings))
+	for i := range a.Findings {
+		finding := &a.Findings[i]
+		n, err := strconv.Atoi(finding.Finding)
+		switch {
+		case err != nil:
+			color.Cyan("  Alert %s", finding.Finding)
+		case index != nil && n < len(index.Documents):
+			doc := index.Documents[n]
+			color.Cyan("  #%d [%s %s] %s", n, doc.Module, doc.Target, doc.Field)
+			fmt.Printf("    %s\n", keywordSnippet(doc.Text, 0, 0))
+		default:
+			color.Cyan("  #%d", n)
+		}
+		finding.Display("    ")
+	}
+}
@@ -0,0 +1,434 @@
+package osint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// caseNameRegex keeps case names usable as directory names
+var caseNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// caseSkippedKeys are result fields holding no evidence text. Keyword hits
+// repeat text indexed elsewhere.
+var caseSkippedKeys = map[string]bool{
+	"scan_id":        true,
+	"keyword_hits":   true,
+	"partial_errors": true,
+}
+
+// CaseDocument is one piece of collected text in a case's index
+type CaseDocument struct {
+	ID      int    `json:"id"`
+	ScanID  string `json:"scan_id,omitempty"`
+	Module  string `json:"module"`
+	Target  string `json:"target"`
+	Field   string `json:"field"`            // Where in the results, e.g. profiles[2].bio
+	Source  string `json:"source,omitempty"` // The URL of the profile, page or capture holding it
+	Text    string `json:"text"`
+	Indexed string `json:"indexed"`
+}
+
+// CaseIndex is the full-text index of the text collected for a case, kept in
+// <OutputDir>/cases/<name>/index.json
+type CaseIndex struct {
+	Name      string           `json:"name"`
+	Documents []CaseDocument   `json:"documents"`
+	Terms     map[string][]int `json:"terms"` // Term to the IDs of the documents holding it
+
+	path string
+	seen map[string]bool
+}
+
+// CaseHit is a document matching a search
+type CaseHit struct {
+	Document CaseDocument `json:"document"`
+	Score    float64      `json:"score"`
+	Match    string       `json:"match"` // The first matching text as it appeared
+	Snippet  string       `json:"snippet"`
+	// Annotation is what analysts added to the document with annotate
+	Annotation *FindingAnnotation `json:"annotation,omitempty"`
+}
+
+// CaseSearchResults are the documents of a case matching a query, best first
+type CaseSearchResults struct {
+	Case      string    `json:"case"`
+	Query     string    `json:"query"`
+	Documents int       `json:"documents"` // Documents in the index
+	Total     int       `json:"total"`     // Matches before the limit
+	Hits      []CaseHit `json:"hits"`
+}
+
+// caseToken is a lowercased word of a text and where it appears
+type caseToken struct {
+	term       string
+	start, end int
+}
+
+// ValidateCaseName rejects names that cannot be used as a case directory
+func ValidateCaseName(name string) error {
+	if !caseNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid case name %q: use up to 64 letters, digits, dots, dashes and underscores", name)
+	}
+	return nil
+}
+
+// OpenCaseIndex loads a case's index, or starts an empty one
+func OpenCaseIndex(name string) (*CaseIndex, error) {
+	if err := ValidateCaseName(name); err != nil {
+		return nil, err
+	}
+	return openCaseIndex(name, filepath.Join(CaseDir(name), "index.json"))
+}
+
+// openCaseIndex loads the index kept in a file, or starts an empty one
+func openCaseIndex(name, path string) (*CaseIndex, error) {
+	index := &CaseIndex{
+		Name:  name,
+		Terms: make(map[string][]int),
+		path:  path,
+		seen:  make(map[string]bool),
+	}
+	data, err := os.ReadFile(index.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("reading %s: %v", index.path, err)
+	}
+	if index.Terms == nil {
+		index.Terms = make(map[string][]int)
+	}
+	for _, doc := range index.Documents {
+		index.seen[caseDocumentKey(doc)] = true
+	}
+	return index, nil
+}
+
+// AddResults indexes every text string of a module's results, a result struct
+// or anything else that encodes to JSON, and returns how many were new
+func (idx *CaseIndex) AddResults(module, target string, results interface{}) int {
+	data, err := json.Marshal(results)
+	if err != nil {
+		return 0
+	}
+	var tree interface{}
+	if json.Unmarshal(data, &tree) != nil {
+		return 0
+	}
+	before := len(idx.Documents)
+	idx.walk(module, target, "", "", tree)
+	return len(idx.Documents) - before
+}
+
+// walk visits the strings of a decoded JSON tree like KeywordWatch.walk
+func (idx *CaseIndex) walk(module, target, path, source string, node interface{}) {
+	switch node := node.(type) {
+	case map[string]interface{}:
+		if link, ok := node["url"].(string); ok && link != "" {
+			source = link
+		}
+		keys := make([]string, 0, len(node))
+		for key := range node {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if caseSkippedKeys[key] || strings.HasSuffix(key, "timestamp") {
+				continue
+			}
+			idx.walk(module, target, joinKeywordPath(path, key), source, node[key])
+		}
+	case []interface{}:
+		for i, item := range node {
+			idx.walk(module, target, fmt.Sprintf("%s[%d]", path, i), source, item)
+		}
+	case string:
+		idx.add(CaseDocument{
+			ScanID: providers.ScanID,
+			Module: module,
+			Target: target,
+			Field:  path,
+			Source: source,
+			Text:   strings.TrimSpace(node),
+		})
+	}
+}
+
+// add indexes a document unless it is a bare link, has no words or was
+// already indexed from the same place
+func (idx *CaseIndex) add(doc CaseDocument) {
+	if strings.HasPrefix(doc.Text, "http://") || strings.HasPrefix(doc.Text, "https://") {
+		if !strings.ContainsAny(doc.Text, " \t\n") {
+			return
+		}
+	}
+	tokens := caseTokens(doc.Text)
+	if len(tokens) == 0 || idx.seen[caseDocumentKey(doc)] {
+		return
+	}
+	idx.seen[caseDocumentKey(doc)] = true
+
+	doc.ID = len(idx.Documents)
+	if doc.Indexed == "" {
+		doc.Indexed = time.Now().Format(time.RFC3339)
+	}
+	idx.Documents = append(idx.Documents, doc)
+
+	added := make(map[string]bool)
+	for _, token := range tokens {
+		if !added[token.term] {
+			added[token.term] = true
+			idx.Terms[token.term] = append(idx.Terms[token.term], doc.ID)
+		}
+	}
+}
+
+// caseDocumentKey identifies the same text found in the same place again
+func caseDocumentKey(doc CaseDocument) string {
+	return doc.Module + "|" + doc.Target + "|" + doc.Source + "|" + doc.Field + "|" + doc.Text
+}
+
+// Save writes the index, replacing the previous file only once it is complete
+func (idx *CaseIndex) Save() error {
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	tmp := idx.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, idx.path)
+}
+
+// caseTokens splits text into lowercased runs of letters and digits
+func caseTokens(text string) []caseToken {
+	var tokens []caseToken
+	start := -1
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case word && start < 0:
+			start = i
+		case !word && start >= 0:
+			tokens = append(tokens, caseToken{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, caseToken{strings.ToLower(text[start:]), start, len(text)})
+	}
+	return tokens
+}
+
+// caseClause is one part of a query: a word, a word* prefix or a "quoted
+// phrase", which a leading - excludes
+type caseClause struct {
+	terms   []string
+	prefix  bool
+	exclude bool
+}
+
+// parseCaseQuery splits a query into clauses
+func parseCaseQuery(query string) []caseClause {
+	var clauses []caseClause
+	for query = strings.TrimSpace(query); query != ""; query = strings.TrimSpace(query) {
+		clause := caseClause{}
+		if strings.HasPrefix(query, "-") {
+			clause.exclude = true
+			query = query[1:]
+		}
+		var text string
+		if strings.HasPrefix(query, `"`) {
+			end := strings.Index(query[1:], `"`)
+			if end < 0 {
+				text, query = query[1:], ""
+			} else {
+				text, query = query[1:end+1], query[end+2:]
+			}
+		} else {
+			end := strings.IndexAny(query, " \t")
+			if end < 0 {
+				end = len(query)
+			}
+			text, query = query[:end], query[end:]
+			clause.prefix = strings.HasSuffix(text, "*")
+		}
+		for _, token := range caseTokens(text) {
+			clause.terms = append(clause.terms, token.term)
+		}
+		if len(clause.terms) > 1 {
+			clause.prefix = false
+		}
+		if len(clause.terms) > 0 {
+			clauses = append(clauses, clause)
+		}
+	}
+	return clauses
+}
+
+// expand returns the indexed terms a clause word matches
+func (idx *CaseIndex) expand(term string, prefix bool) []string {
+	if !prefix {
+		return []string{term}
+	}
+	var terms []string
+	for indexed := range idx.Terms {
+		if strings.HasPrefix(indexed, term) {
+			terms = append(terms, indexed)
+		}
+	}
+	return terms
+}
+
+// clauseDocs returns the documents holding every word of a clause. Phrases
+// are checked word by word later.
+func (idx *CaseIndex) clauseDocs(clause caseClause) map[int]bool {
+	var docs map[int]bool
+	for _, term := range clause.terms {
+		found := make(map[int]bool)
+		for _, indexed := range idx.expand(term, clause.prefix) {
+			for _, id := range idx.Terms[indexed] {
+				if docs == nil || docs[id] {
+					found[id] = true
+				}
+			}
+		}
+		docs = found
+	}
+	return docs
+}
+
+// Search returns the documents matching every clause of query and none of
+// its excluded ones, scored by how often and how rarely their words occur
+func (idx *CaseIndex) Search(query string, limit int) *CaseSearchResults {
+	results := &CaseSearchResults{Case: idx.Name, Query: query, Documents: len(idx.Documents), Hits: []CaseHit{}}
+	clauses := parseCaseQuery(query)
+
+	var candidates map[int]bool
+	df := make([]int, len(clauses))
+	for i, clause := range clauses {
+		if clause.exclude {
+			continue
+		}
+		docs := idx.clauseDocs(clause)
+		df[i] = len(docs)
+		if candidates != nil {
+			for id := range candidates {
+				if !docs[id] {
+					delete(candidates, id)
+				}
+			}
+		} else {
+			candidates = docs
+		}
+	}
+
+	for id := range candidates {
+		doc := idx.Documents[id]
+		tokens := caseTokens(doc.Text)
+		hit := CaseHit{Document: doc}
+		matched := true
+		for i, clause := range clauses {
+			start, end, count := matchCaseClause(tokens, clause)
+			if clause.exclude {
+				if count > 0 {
+					matched = false
+				}
+				continue
+			}
+			if count == 0 {
+				matched = false
+				break
+			}
+			if hit.Match == "" {
+				hit.Match = doc.Text[start:end]
+				hit.Snippet = keywordSnippet(doc.Text, start, end)
+			}
+			hit.Score += float64(count) * math.Log(1+float64(len(idx.Documents))/float64(max(df[i], 1)))
+		}
+		if matched {
+			// Long texts repeat words by chance; favour the focused match
+			hit.Score /= math.Sqrt(float64(len(tokens)))
+			results.Hits = append(results.Hits, hit)
+		}
+	}
+
+	sort.Slice(results.Hits, func(i, j int) bool {
+		if results.Hits[i].Score != results.Hits[j].Score {
+			return results.Hits[i].Score > results.Hits[j].Score
+		}
+		return results.Hits[i].Document.ID < results.Hits[j].Document.ID
+	})
+	results.Total = len(results.Hits)
+	if limit > 0 && len(results.Hits) > limit {
+		results.Hits = results.Hits[:limit]
+	}
+	return results
+}
+
+// matchCaseClause counts where a clause occurs in a document's tokens and
+// returns the byte span of the first occurrence
+func matchCaseClause(tokens []caseToken, clause caseClause) (start, end, count int) {
+	for i := 0; i+len(clause.terms) <= len(tokens); i++ {
+		ok := true
+		for j, term := range clause.terms {
+			token := tokens[i+j].term
+			if token != term && !(clause.prefix && strings.HasPrefix(token, term)) {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		if count == 0 {
+			start, end = tokens[i].start, tokens[i+len(clause.terms)-1].end
+		}
+		count++
+	}
+	return start, end, count
+}
+
+// DisplayResults prints the matching documents with the match highlighted
+func (r *CaseSearchResults) DisplayResults() {
+	color.Green("\n=== CASE SEARCH: %s ===", r.Case)
+	color.Yellow("Query: %s", r.Query)
+	if len(r.Hits) < r.Total {
+		color.Yellow("%d of %d documents match, showing the best %d", r.Total, r.Documents, len(r.Hits))
+	} else {
+		color.Yellow("%d of %d documents match", r.Total, r.Documents)
+	}
+
+	highlight := color.New(color.FgBlack, color.BgYellow).SprintFunc()
+	for _, hit := range r.Hits {
+		doc := hit.Document
+		color.Cyan("\n  #%d [%s %s] %s", doc.ID, doc.Module, doc.Target, doc.Field)
+		if doc.Source != "" {
+			color.White("    %s", doc.Source)
+		}
+		match := strings.Join(strings.Fields(hit.Match), " ")
+		fmt.Printf("    %s\n", strings.Replace(hit.Snippet, match, highlight(match), 1))
+		if hit.Annotation != nil {
+			hit.Annotation.Display("    ")
+		}
+	}
+}
@@ -0,0 +1,509 @@
+package osint
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// CaseSource is a case store merged into another case
+type CaseSource struct {
+	Dir          string `json:"dir"`
+	Case         string `json:"case"`
+	Investigator string `json:"investigator,omitempty"`
+	Results      int    `json:"results"`
+}
+
+// MergedResult is a file of a merged case and the case stores holding it
+type MergedResult struct {
+	Path string   `json:"path"` // Relative to the merged case
+	From []string `json:"from"` // Directories of the case stores
+}
+
+// FindingSource records who found a finding, and in which results
+type FindingSource struct {
+	Case         string `json:"case"`
+	Investigator string `json:"investigator,omitempty"`
+	Result       string `json:"result"` // The results file, relative to the merged case
+	Saved        string `json:"saved,omitempty"`
+}
+
+// MergedFinding is a finding of a merged case, with every investigator who
+// found it
+type MergedFinding struct {
+	Hash     string          `json:"hash"` // Finding hash, as suppress takes
+	Kind     string          `json:"kind"` // profile, email, breach, phone or google_id
+	Value    string          `json:"value"`
+	Platform string          `json:"platform,omitempty"`
+	Entity   int             `json:"entity,omitempty"` // ID of the entity it resolves to
+	FoundBy  []FindingSource `json:"found_by"`
+
+	identifier string // The email, phone or handle tying it to an entity
+	from       map[string]bool
+}
+
+// CaseMerge is a case merged from the case stores of several investigators.
+// It is kept as the provenance of the case's findings in
+// <OutputDir>/cases/<name>/provenance.json.
+type CaseMerge struct {
+	Case          string          `json:"case"`
+	Merged        string          `json:"merged"`
+	Sources       []CaseSource    `json:"sources"`
+	Results       []MergedResult  `json:"results"`
+	Findings      []MergedFinding `json:"findings"`
+	Entities      []AliasCluster  `json:"entities"`   // The people the findings resolve to
+	Duplicates    int             `json:"duplicates"` // Findings in more than one case store
+	Documents     int             `json:"documents"`  // Documents of the merged index
+	Annotations   int             `json:"annotations"`
+	Suppressions  int             `json:"suppressions"`
+	PartialErrors []ModuleError   `json:"partial_errors,omitempty"`
+
+	findings map[string]int // Finding hash to its index in Findings
+}
+
+// MergeCases merges case stores, case directories copied from other
+// investigators' machines, into a new case. Results are copied, identical
+// files once; the indexes, annotations and suppression lists are joined; and
+// the findings are matched by finding hash and resolved into entities with
+// the rules of the aliases command, recording who found each. An empty name
+// names the case after the first store's, with -merged.
+func MergeCases(name string, dirs []string) (merge *CaseMerge, err error) {
+	merge = &CaseMerge{
+		Merged:   time.Now().Format(time.RFC3339),
+		Findings: []MergedFinding{},
+		findings: make(map[string]int),
+	}
+	var infos []CaseInfo
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		if seen[abs] {
+			return nil, fmt.Errorf("%s is given twice", dir)
+		}
+		seen[abs] = true
+		info, err := readCaseStore(dir)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+		merge.Sources = append(merge.Sources, CaseSource{Dir: dir, Case: info.Name, Investigator: info.Investigator})
+	}
+	if name == "" && len(infos) > 0 {
+		name = infos[0].Name + "-merged"
+	}
+	if err := ValidateCaseName(name); err != nil {
+		return nil, err
+	}
+	if CaseExists(name) {
+		return nil, fmt.Errorf("case %s already exists", name)
+	}
+	merge.Case = name
+
+	dest := CaseDir(name)
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return nil, err
+	}
+	// A merge that fails leaves no half-made case behind
+	defer func() {
+		if err != nil {
+			os.RemoveAll(dest)
+		}
+	}()
+	byContent := make(map[[sha256.Size]byte]int)
+	taken := make(map[string]bool)
+	for i := range merge.Sources {
+		if err := merge.copyResults(&merge.Sources[i], dest, byContent, taken); err != nil {
+			return nil, err
+		}
+	}
+	merge.resolveEntities()
+
+	renumbered, err := merge.mergeIndexes(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := merge.mergeAnnotations(name, renumbered); err != nil {
+		return nil, err
+	}
+	if err := merge.mergeSuppressions(name); err != nil {
+		return nil, err
+	}
+
+	info := CaseInfo{Name: name, Created: merge.Merged}
+	var investigators, notes []string
+	for i, source := range infos {
+		investigators = appendUnique(investigators, source.Investigator)
+		notes = appendUnique(notes, source.Notes)
+		info.MergedFrom = append(info.MergedFrom, merge.Sources[i].Dir)
+	}
+	info.Investigator = strings.Join(investigators, ", ")
+	info.Notes = strings.Join(notes, "\n")
+	if err := writeCaseJSON(filepath.Join(dest, "case.json"), info); err != nil {
+		return nil, err
+	}
+	return merge, writeCaseJSON(filepath.Join(dest, "provenance.json"), merge)
+}
+
+// readCaseStore reads the metadata of a case directory, naming the case
+// after the directory when it has none
+func readCaseStore(dir string) (CaseInfo, error) {
+	info := CaseInfo{Name: filepath.Base(filepath.Clean(dir))}
+	stat, err := os.Stat(dir)
+	if err != nil {
+		return info, err
+	}
+	_, indexErr := os.Stat(filepath.Join(dir, "index.json"))
+	data, err := os.ReadFile(filepath.Join(dir, "case.json"))
+	switch {
+	case !stat.IsDir() || errors.Is(err, os.ErrNotExist) && indexErr != nil:
+		return info, fmt.Errorf("%s is not a case directory: it has no case.json or index.json", dir)
+	case err == nil:
+		if err := json.Unmarshal(data, &info); err != nil {
+			return info, fmt.Errorf("reading %s: %v", filepath.Join(dir, "case.json"), err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return info, err
+	}
+	return info, nil
+}
+
+// copyResults copies the files a case store holds in its module directories,
+// keeping one copy of identical files and renaming others that share a name,
+// and collects the findings of the results among them
+func (m *CaseMerge) copyResults(source *CaseSource, dest string, byContent map[[sha256.Size]byte]int, taken map[string]bool) error {
+	return filepath.WalkDir(source.Dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(source.Dir, path)
+		if entry.IsDir() || !strings.Contains(filepath.ToSlash(rel), "/") || strings.HasSuffix(path, ".tmp") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(data)
+		i, copied := byContent[sum]
+		if !copied {
+			target := rel
+			for n := 2; taken[target]; n++ {
+				ext := filepath.Ext(rel)
+				target = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(rel, ext), n, ext)
+			}
+			if err := os.MkdirAll(filepath.Join(dest, filepath.Dir(target)), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(filepath.Join(dest, target), data, 0644); err != nil {
+				return err
+			}
+			// Keep when the results were saved, which the case lists
+			if info, err := entry.Info(); err == nil {
+				os.Chtimes(filepath.Join(dest, target), info.ModTime(), info.ModTime())
+			}
+			taken[target] = true
+			i = len(m.Results)
+			byContent[sum] = i
+			m.Results = append(m.Results, MergedResult{Path: filepath.ToSlash(target)})
+		}
+		m.Results[i].From = appendUnique(m.Results[i].From, source.Dir)
+		if filepath.Ext(path) != ".json" {
+			return nil
+		}
+		source.Results++
+
+		saved, err := readSavedResults(path)
+		if errors.Is(err, errNotReportable) {
+			return nil
+		}
+		if err != nil {
+			m.PartialErrors = append(m.PartialErrors, ModuleError{Module: "merge " + source.Case, Error: err.Error()})
+			return nil
+		}
+		found := FindingSource{Case: source.Case, Investigator: source.Investigator, Result: m.Results[i].Path}
+		if info, err := entry.Info(); err == nil {
+			found.Saved = info.ModTime().Format(time.RFC3339)
+		}
+		m.addFindings(saved, source.Dir, found)
+		return nil
+	})
+}
+
+// addFindings adds the profiles, emails, breaches, phones and Google IDs of
+// saved results. Profiles are matched by finding hash whether a profile
+// search or an email's linked accounts found them.
+func (m *CaseMerge) addFindings(saved *savedResults, dir string, found FindingSource) {
+	for _, social := range saved.social {
+		for _, profile := range social.Profiles {
+			m.addFinding(MergedFinding{Hash: profile.FindingHash(), Kind: "profile", Value: profile.URL, Platform: profile.Platform, identifier: IdentifierHandle + ":" + profile.Username}, dir, found)
+		}
+	}
+	for _, email := range saved.emails {
+		identifier := IdentifierEmail + ":" + email.Email
+		m.addFinding(MergedFinding{Hash: FindingHash("email", email.Email), Kind: "email", Value: email.Email, identifier: identifier}, dir, found)
+		for _, breach := range email.SecurityInfo.BreachDetails {
+			m.addFinding(MergedFinding{Hash: FindingHash("breach", email.Email, breach.BreachName), Kind: "breach", Value: breach.BreachName, identifier: identifier}, dir, found)
+		}
+		for _, profile := range email.SocialProfiles {
+			m.addFinding(MergedFinding{Hash: profile.FindingHash(), Kind: "profile", Value: profile.URL, Platform: profile.Platform, identifier: IdentifierHandle + ":" + profile.Username}, dir, found)
+		}
+	}
+	for _, phone := range saved.phones {
+		m.addFinding(MergedFinding{Hash: FindingHash("phone", phone.E164Format), Kind: "phone", Value: phone.E164Format, identifier: IdentifierPhone + ":" + phone.E164Format}, dir, found)
+	}
+	for _, gid := range saved.googleIDs {
+		m.addFinding(MergedFinding{Hash: FindingHash("google_id", gid.GoogleID), Kind: "google_id", Value: gid.GoogleID, Platform: "Google"}, dir, found)
+	}
+}
+
+// addFinding records a finding, or another source of one already found
+func (m *CaseMerge) addFinding(finding MergedFinding, dir string, found FindingSource) {
+	if finding.Value == "" {
+		return
+	}
+	i, ok := m.findings[finding.Hash]
+	if !ok {
+		i = len(m.Findings)
+		m.findings[finding.Hash] = i
+		finding.from = make(map[string]bool)
+		m.Findings = append(m.Findings, finding)
+	}
+	existing := &m.Findings[i]
+	for _, source := range existing.FoundBy {
+		if source == found {
+			return
+		}
+	}
+	existing.FoundBy = append(existing.FoundBy, found)
+	if !existing.from[dir] {
+		if existing.from[dir] = true; len(existing.from) == 2 {
+			m.Duplicates++
+		}
+	}
+}
+
+// resolveEntities clusters the emails, phones and handles of the findings
+// as the aliases command does, without Gravatar lookups, and gives each
+// finding the entity of its identifier. An identifier linked to no other is
+// an entity of its own.
+func (m *CaseMerge) resolveEntities() {
+	var ids []*Identifier
+	seen := make(map[string]bool)
+	for i := range m.Findings {
+		finding := &m.Findings[i]
+		if finding.identifier == "" {
+			continue
+		}
+		id, err := ParseIdentifier(finding.identifier)
+		if err != nil {
+			finding.identifier = ""
+			continue
+		}
+		finding.identifier = id.Kind + ":" + strings.ToLower(id.Value)
+		if !seen[finding.identifier] {
+			seen[finding.identifier] = true
+			ids = append(ids, &id)
+		}
+	}
+
+	clusters, unclustered := clusterLinks(ids, aliasLinks(ids))
+	for _, id := range unclustered {
+		clusters = append(clusters, AliasCluster{ID: len(clusters) + 1, Members: []Identifier{id}, Evidence: []AliasLink{}, Confidence: 1})
+	}
+	entities := make(map[string]int)
+	for _, cluster := range clusters {
+		for _, member := range cluster.Members {
+			entities[member.Kind+":"+strings.ToLower(member.Value)] = cluster.ID
+		}
+	}
+	for i := range m.Findings {
+		m.Findings[i].Entity = entities[m.Findings[i].identifier]
+	}
+	sort.SliceStable(m.Findings, func(i, j int) bool {
+		a, b := m.Findings[i].Entity, m.Findings[j].Entity
+		return a != 0 && (b == 0 || a < b)
+	})
+	m.Entities = clusters
+}
+
+// mergeIndexes joins the case stores' indexes into the merged case's, and
+// returns for each store its document numbers in the merged index
+func (m *CaseMerge) mergeIndexes(name string) ([]map[int]int, error) {
+	index, err := OpenCaseIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	var sources []*CaseIndex
+	for _, source := range m.Sources {
+		sourceIndex, err := openCaseIndex(source.Case, filepath.Join(source.Dir, "index.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range sourceIndex.Documents {
+			index.add(doc)
+		}
+		sources = append(sources, sourceIndex)
+	}
+
+	ids := make(map[string]int, len(index.Documents))
+	for _, doc := range index.Documents {
+		ids[caseDocumentKey(doc)] = doc.ID
+	}
+	renumbered := make([]map[int]int, len(sources))
+	for i, sourceIndex := range sources {
+		renumbered[i] = make(map[int]int)
+		for _, doc := range sourceIndex.Documents {
+			if id, ok := ids[caseDocumentKey(doc)]; ok {
+				renumbered[i][doc.ID] = id
+			}
+		}
+	}
+	m.Documents = len(index.Documents)
+	return renumbered, index.Save()
+}
+
+// mergeAnnotations joins the case stores' annotations, renumbering those of
+// index documents
+func (m *CaseMerge) mergeAnnotations(name string, renumbered []map[int]int) error {
+	annotations, err := LoadCaseAnnotations(name)
+	if err != nil {
+		return err
+	}
+	for i, source := range m.Sources {
+		sourceAnnotations, err := loadCaseAnnotations(source.Case, filepath.Join(source.Dir, "annotations.json"))
+		if err != nil {
+			return err
+		}
+		for _, annotation := range sourceAnnotations.Findings {
+			if n, err := strconv.Atoi(annotation.Finding); err == nil {
+				id, ok := renumbered[i][n]
+				if !ok {
+					m.PartialErrors = append(m.PartialErrors, ModuleError{Module: "merge " + source.Case, Error: fmt.Sprintf("annotation of document #%d dropped: the document is not in the case's index", n)})
+					continue
+				}
+				annotation.Finding = strconv.Itoa(id)
+			}
+			annotations.merge(annotation)
+		}
+	}
+	m.Annotations = len(annotations.Findings)
+	if m.Annotations == 0 {
+		return nil
+	}
+	return annotations.Save()
+}
+
+// mergeSuppressions joins the case stores' suppression lists
+func (m *CaseMerge) mergeSuppressions(name string) error {
+	list, err := LoadSuppressionList(name)
+	if err != nil {
+		return err
+	}
+	for _, source := range m.Sources {
+		sourceList, err := loadSuppressionList(&SuppressionList{scope: source.Case, path: filepath.Join(source.Dir, "suppressions.json")})
+		if err != nil {
+			return err
+		}
+		for _, rule := range sourceList.Suppressions {
+			list.Add(rule)
+		}
+	}
+	m.Suppressions = len(list.Suppressions)
+	if m.Suppressions == 0 {
+		return nil
+	}
+	return list.Save()
+}
+
+// DisplayResults prints the case stores merged, the findings more than one
+// investigator made and the entities the findings resolve to
+func (m *CaseMerge) DisplayResults() {
+	color.Cyan("\n=== CASE MERGE: %s ===", m.Case)
+	for _, source := range m.Sources {
+		line := fmt.Sprintf("• %s (case %s", source.Dir, source.Case)
+		if source.Investigator != "" {
+			line += ", " + source.Investigator
+		}
+		color.Yellow("%s): %d results", line, source.Results)
+	}
+	color.White("• %d files, %d findings, %d found more than once, %d entities", len(m.Results), len(m.Findings), m.Duplicates, len(m.Entities))
+	color.White("• %d index documents, %d annotations, %d suppressions", m.Documents, m.Annotations, m.Suppressions)
+
+	if m.Duplicates > 0 {
+		color.Cyan("\n[Found in more than one case store]")
+		for _, finding := range m.Findings {
+			if len(finding.from) < 2 {
+				continue
+			}
+			var by []string
+			for _, source := range finding.FoundBy {
+				by = appendUnique(by, source.Investigator)
+			}
+			label := finding.Kind
+			if finding.Platform != "" {
+				label = finding.Platform
+			}
+			color.Green("• %s %s (%s)", label, finding.Value, strings.Join(by, ", "))
+		}
+	}
+
+	for _, entity := range m.Entities {
+		if len(entity.Members) < 2 {
+			continue
+		}
+		color.Cyan("\n[Entity %d] %d identifiers, confidence %.2f", entity.ID, len(entity.Members), entity.Confidence)
+		for _, member := range entity.Members {
+			color.Green("• %s (%s)", member.Value, member.Kind)
+		}
+		for _, l := range entity.Evidence {
+			color.White("  %s ↔ %s: %s (%.2f)", l.A, l.B, l.Reason, l.Weight)
+		}
+	}
+}
+
+// DisplayPartialErrors prints the results and annotations that could not be
+// merged
+func (m *CaseMerge) DisplayPartialErrors() {
+	displayModuleErrors(m.PartialErrors)
+}
+
+// writeCaseJSON writes a case file, replacing the previous one only once it
+// is complete
+func writeCaseJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// appendUnique appends a non-empty value not already in the list
+func appendUnique(list []string, value string) []string {
+	for _, existing := range list {
+		if existing == value {
+			return list
+		}
+	}
+	if value == "" {
+		return list
+	}
+	return append(list, value)
+}
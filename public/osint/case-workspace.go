@@ -0,0 +1,250 @@
+package osint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// unsafeFileChars are replaced in targets used as result file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._@+-]+`)
+
+// CaseInfo describes an investigation. It is kept in
+// <OutputDir>/cases/<name>/case.json, beside the results filed in the case
+// and its full-text index.
+type CaseInfo struct {
+	Name         string `json:"name"`
+	Investigator string `json:"investigator,omitempty"`
+	Created      string `json:"created,omitempty"`
+	Notes        string `json:"notes,omitempty"`
+	// MergedFrom are the case stores merged into the case with case merge
+	MergedFrom []string `json:"merged_from,omitempty"`
+}
+
+// CaseResult is a module's results filed in a case
+type CaseResult struct {
+	Module string `json:"module"`
+	Path   string `json:"path"`
+	Saved  string `json:"saved"`
+}
+
+// CaseDir returns the directory holding a case
+func CaseDir(name string) string {
+	return filepath.Join(OutputDir, "cases", name)
+}
+
+// activeCaseFile names the case runs are filed in when --case is not given.
+// Case names cannot start with a dot, so it never shadows one.
+func activeCaseFile() string {
+	return filepath.Join(OutputDir, "cases", ".active")
+}
+
+// CaseExists reports whether a case was created or has an index
+func CaseExists(name string) bool {
+	for _, file := range []string{"case.json", "index.json"} {
+		if _, err := os.Stat(filepath.Join(CaseDir(name), file)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateCase starts a case with its metadata. A case that so far only has
+// an index, made with --case, gets the metadata added.
+func CreateCase(name, investigator, notes string) (*CaseInfo, error) {
+	if err := ValidateCaseName(name); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(CaseDir(name), "case.json")
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("case %s already exists", name)
+	}
+	info := &CaseInfo{Name: name, Investigator: investigator, Created: time.Now().Format(time.RFC3339), Notes: notes}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(CaseDir(name), 0755); err != nil {
+		return nil, err
+	}
+	return info, os.WriteFile(path, data, 0644)
+}
+
+// LoadCase reads a case's metadata. A case made with --case alone has none
+// beyond its name.
+func LoadCase(name string) (*CaseInfo, error) {
+	if err := ValidateCaseName(name); err != nil {
+		return nil, err
+	}
+	if !CaseExists(name) {
+		return nil, fmt.Errorf("no case %s in %s", name, OutputDir)
+	}
+	info := &CaseInfo{Name: name}
+	data, err := os.ReadFile(filepath.Join(CaseDir(name), "case.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return info, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("reading case %s: %v", name, err)
+	}
+	info.Name = name
+	return info, nil
+}
+
+// ListCases returns every case in OutputDir, sorted by name
+func ListCases() ([]*CaseInfo, error) {
+	entries, err := os.ReadDir(filepath.Join(OutputDir, "cases"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cases []*CaseInfo
+	for _, entry := range entries {
+		if !entry.IsDir() || ValidateCaseName(entry.Name()) != nil || !CaseExists(entry.Name()) {
+			continue
+		}
+		if info, err := LoadCase(entry.Name()); err == nil {
+			cases = append(cases, info)
+		}
+	}
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, nil
+}
+
+// OpenCase makes runs file their results in a case until it is closed
+func OpenCase(name string) (*CaseInfo, error) {
+	info, err := LoadCase(name)
+	if err != nil {
+		return nil, err
+	}
+	return info, os.WriteFile(activeCaseFile(), []byte(name+"\n"), 0644)
+}
+
+// CloseCase stops filing runs in the open case
+func CloseCase() error {
+	if err := os.Remove(activeCaseFile()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ActiveCase returns the open case, or "" when none is
+func ActiveCase() string {
+	data, err := os.ReadFile(activeCaseFile())
+	if err != nil {
+		return ""
+	}
+	name := strings.TrimSpace(string(data))
+	if ValidateCaseName(name) != nil || !CaseExists(name) {
+		return ""
+	}
+	return name
+}
+
+// CaseResultPath returns a new file for a module's results in a case,
+// <case>/<module>/<target>_<time>.json
+func CaseResultPath(name, module, target string) string {
+	file := strings.Trim(unsafeFileChars.ReplaceAllString(target, "_"), "_.")
+	if file == "" {
+		file = module
+	}
+	if len(file) > 64 {
+		file = file[:64]
+	}
+	return filepath.Join(CaseDir(name), module, fmt.Sprintf("%s_%s.json", file, time.Now().Format("20060102_150405")))
+}
+
+// Results lists the module results filed in the case, oldest first
+func (c *CaseInfo) Results() ([]CaseResult, error) {
+	dir := CaseDir(c.Name)
+	var results []CaseResult
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		module, _, nested := strings.Cut(filepath.ToSlash(rel), "/")
+		if entry.IsDir() || !nested || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		saved := ""
+		if fileInfo, err := entry.Info(); err == nil {
+			saved = fileInfo.ModTime().Format(time.RFC3339)
+		}
+		results = append(results, CaseResult{Module: module, Path: path, Saved: saved})
+		return nil
+	})
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Saved < results[j].Saved })
+	return results, err
+}
+
+// Display prints the case's metadata and the results filed in it
+func (c *CaseInfo) Display(results []CaseResult) {
+	color.Cyan("\n=== CASE: %s ===", c.Name)
+	if c.Investigator != "" {
+		color.Yellow("Investigator: %s", c.Investigator)
+	}
+	if c.Created != "" {
+		color.Yellow("Created: %s", c.Created)
+	}
+	if c.Notes != "" {
+		color.Yellow("Notes: %s", c.Notes)
+	}
+	if len(c.MergedFrom) > 0 {
+		color.Yellow("Merged from: %s", strings.Join(c.MergedFrom, ", "))
+	}
+
+	if len(results) == 0 {
+		color.Yellow("\nNo results filed yet")
+		return
+	}
+	color.Green("\nResults: %d", len(results))
+	for _, result := range results {
+		fmt.Printf("  %-12s %s  %s\n", result.Module, result.Saved, result.Path)
+	}
+}
+
+// DisplayCases prints the cases, marking the open one
+func DisplayCases(cases []*CaseInfo, active string) {
+	if len(cases) == 0 {
+		color.Yellow("No cases yet; start one with 'mercuries case create <name>'")
+		return
+	}
+	color.Cyan("Cases in %s:", filepath.Join(OutputDir, "cases"))
+	for _, c := range cases {
+		marker := " "
+		if c.Name == active {
+			marker = "*"
+		}
+		results, _ := c.Results()
+		line := fmt.Sprintf("%s %-24s %3d results", marker, c.Name, len(results))
+		if c.Investigator != "" {
+			line += "  " + c.Investigator
+		}
+		if c.Created != "" {
+			line += "  " + c.Created
+		}
+		if c.Name == active {
+			color.Green("%s", line)
+		} else {
+			fmt.Println(line)
+		}
+		if c.Notes != "" {
+			fmt.Printf("    %s\n", c.Notes)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package osint
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testCaseIndex builds an unsaved index holding one document per text
+func testCaseIndex(t *testing.T, texts ...string) *CaseIndex {
+	t.Helper()
+	index, err := openCaseIndex("test", filepath.Join(t.TempDir(), "index.json"))
+	if err != nil {
+		t.Fatalf("openCaseIndex: %v", err)
+	}
+	for i, text := range texts {
+		index.add(CaseDocument{Module: "social", Target: "johnd", Field: fieldName(i), Text: text})
+	}
+	return index
+}
+
+func fieldName(i int) string {
+	return fmt.Sprintf("profiles[%d].bio", i)
+}
+
+func TestCaseTokens(t *testing.T) {
+	text := "Élodie's café, 221B Baker-St."
+	var terms []string
+	for _, token := range caseTokens(text) {
+		terms = append(terms, token.term)
+		if got := text[token.start:token.end]; strings.ToLower(got) != token.term {
+			t.Errorf("token %q spans %q", token.term, got)
+		}
+	}
+	want := []string{"élodie", "s", "café", "221b", "baker", "st"}
+	if !reflect.DeepEqual(terms, want) {
+		t.Errorf("caseTokens = %q, want %q", terms, want)
+	}
+}
+
+func TestParseCaseQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []caseClause
+	}{
+		{"Elm", []caseClause{{terms: []string{"elm"}}}},
+		{`"elm street" -draft`, []caseClause{{terms: []string{"elm", "street"}}, {terms: []string{"draft"}, exclude: true}}},
+		{"bak* -\"old post\"", []caseClause{{terms: []string{"bak"}, prefix: true}, {terms: []string{"old", "post"}, exclude: true}}},
+		{"john.doe*", []caseClause{{terms: []string{"john", "doe"}}}},
+		{`"unterminated phrase`, []caseClause{{terms: []string{"unterminated", "phrase"}}}},
+		{`  -  "" * `, nil},
+	}
+	for _, tt := range tests {
+		if got := parseCaseQuery(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCaseQuery(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestCaseIndexSkipsLinksAndDuplicates(t *testing.T) {
+	index := testCaseIndex(t, "https://example.com/johnd", "   ", "Lives on Elm Street")
+	index.add(CaseDocument{Module: "social", Target: "johnd", Field: fieldName(2), Text: "Lives on Elm Street"})
+	if len(index.Documents) != 1 {
+		t.Fatalf("indexed %d documents, want only the bio once: %+v", len(index.Documents), index.Documents)
+	}
+	if !reflect.DeepEqual(index.Terms["elm"], []int{0}) {
+		t.Errorf("elm is in documents %v, want [0]", index.Terms["elm"])
+	}
+}
+
+func TestCaseIndexExpandsPrefixes(t *testing.T) {
+	index := testCaseIndex(t, "baker bakery", "bake sale", "cake")
+	got := index.expand("bak", true)
+	sort.Strings(got)
+	if want := []string{"bake", "baker", "bakery"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expand(bak*) = %q, want %q", got, want)
+	}
+	if got := index.expand("bak", false); !reflect.DeepEqual(got, []string{"bak"}) {
+		t.Errorf("expand(bak) = %q, want the word itself", got)
+	}
+}
+
+func TestCaseIndexSearch(t *testing.T) {
+	index := testCaseIndex(t,
+		"Moved to Elm Street last spring",                            // 0
+		"Street photography, mostly elm trees and one street corner", // 1
+		"Draft: elm street notes",                                    // 2
+		"Baker on Main Street",                                       // 3
+		"Elm street elm street",                                      // 4
+	)
+
+	ids := func(results *CaseSearchResults) []int {
+		var ids []int
+		for _, hit := range results.Hits {
+			ids = append(ids, hit.Document.ID)
+		}
+		return ids
+	}
+
+	// The phrase excludes document 1, where the words are apart, and the
+	// document repeating it outranks the longer ones
+	results := index.Search(`"elm street" -draft`, 0)
+	if got := ids(results); !reflect.DeepEqual(got, []int{4, 0}) {
+		t.Errorf("phrase search ranked %v, want [4 0]", got)
+	}
+	if results.Total != 2 || results.Documents != 5 {
+		t.Errorf("total %d of %d documents, want 2 of 5", results.Total, results.Documents)
+	}
+	if hit := results.Hits[1]; hit.Match != "Elm Street" || hit.Snippet == "" {
+		t.Errorf("match %q with snippet %q, want the phrase as written", hit.Match, hit.Snippet)
+	}
+
+	// Words match anywhere in a document, the rarer word weighing more
+	if got := ids(index.Search("street bak*", 0)); !reflect.DeepEqual(got, []int{3}) {
+		t.Errorf("prefix search found %v, want [3]", got)
+	}
+	results = index.Search("elm", 2)
+	if results.Total != 4 || len(results.Hits) != 2 || results.Hits[0].Document.ID != 4 {
+		t.Errorf("limited search = %v of %d, want the best 2 of 4 led by 4", ids(results), results.Total)
+	}
+	if got := index.Search("nothing", 0); got.Total != 0 || got.Hits == nil {
+		t.Errorf("search without matches = %+v, want an empty hit list", got)
+	}
+}
+
+func TestCaseIndexSaveAndReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cases", "bluebird", "index.json")
+	index, err := openCaseIndex("bluebird", path)
+	if err != nil {
+		t.Fatalf("openCaseIndex: %v", err)
+	}
+	added := index.AddResults("social", "johnd", map[string]interface{}{
+		"scan_id":  "ignored",
+		"profiles": []interface{}{map[string]interface{}{"url": "https://example.com/johnd", "bio": "Lives on Elm Street"}},
+	})
+	if added != 1 {
+		t.Fatalf("AddResults indexed %d documents, want the bio only", added)
+	}
+	if err := index.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	reopened, err := openCaseIndex("bluebird", path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	doc := reopened.Documents[0]
+	if doc.Field != "profiles[0].bio" || doc.Source != "https://example.com/johnd" {
+		t.Errorf("reopened document = %+v", doc)
+	}
+	// Text already indexed from the same place is not added again
+	if added := reopened.AddResults("social", "johnd", map[string]interface{}{
+		"profiles": []interface{}{map[string]interface{}{"url": "https://example.com/johnd", "bio": "Lives on Elm Street"}},
+	}); added != 0 {
+		t.Errorf("re-adding the same results indexed %d documents", added)
+	}
+	if got := reopened.Search("elm", 0); got.Total != 1 {
+		t.Errorf("search after reopening found %d documents, want 1", got.Total)
+	}
+}
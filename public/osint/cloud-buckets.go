@@ -0,0 +1,382 @@
+package osint
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/input"
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Cloud storage providers probed for buckets
+const (
+	BucketS3    = "s3"
+	BucketGCS   = "gcs"
+	BucketAzure = "azure"
+)
+
+const (
+	// maxBucketNames caps the bucket names generated from one target
+	maxBucketNames = 200
+	// maxBucketSample is how many object names of a public bucket are kept
+	maxBucketSample = 10
+)
+
+// bucketSuffixes are the words organizations put before or after their name
+// when naming buckets
+var bucketSuffixes = []string{
+	"backup", "backups", "dev", "staging", "prod", "production", "test", "data", "assets", "static",
+	"media", "files", "uploads", "logs", "public", "private", "web", "www", "images", "cdn",
+	"archive", "db", "internal", "docs",
+}
+
+// azureContainers are the container names tried in every storage account found
+var azureContainers = []string{"$web", "public", "files", "assets", "images", "media", "uploads", "backup", "data"}
+
+var (
+	bucketNameRegex       = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+	azureAccountNameRegex = regexp.MustCompile(`^[a-z0-9]{3,24}$`)
+)
+
+// CloudBucket is a storage bucket, or an Azure storage container, that
+// exists under a name derived from the target. Public ones list their
+// objects to anyone; a sample of the object names is kept.
+type CloudBucket struct {
+	Provider  string   `json:"provider"`
+	Name      string   `json:"name"`
+	URL       string   `json:"url"`
+	Public    bool     `json:"public"`
+	Objects   []string `json:"sample_objects,omitempty"`
+	Truncated bool     `json:"truncated,omitempty"` // Lists more objects than the sample
+}
+
+// BucketScanResult lists the buckets found under names derived from a target
+type BucketScanResult struct {
+	Target        string        `json:"target"`
+	ScanID        string        `json:"scan_id,omitempty"`
+	Timestamp     string        `json:"timestamp"`
+	NamesChecked  int           `json:"names_checked"`
+	Buckets       []CloudBucket `json:"buckets"`
+	PartialErrors []ModuleError `json:"partial_errors,omitempty"`
+	ExecutionTime string        `json:"execution_time"`
+}
+
+// bucketListing is the part of an S3 or GCS ListBucketResult, or an Azure
+// EnumerationResults, read for the sample
+type bucketListing struct {
+	Keys       []string `xml:"Contents>Key"`
+	Truncated  bool     `xml:"IsTruncated"`
+	Blobs      []string `xml:"Blobs>Blob>Name"`
+	NextMarker string   `xml:"NextMarker"`
+}
+
+// FindBuckets generates bucket names from an organization, username or
+// domain and probes S3, Google Cloud Storage and Azure Blob Storage for them.
+// Only anonymous listing requests are sent; nothing is downloaded.
+func FindBuckets(ctx context.Context, target string) (*BucketScanResult, error) {
+	startTime := time.Now()
+	names := bucketNames(target)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no bucket names can be made from %q", target)
+	}
+
+	result := &BucketScanResult{
+		Target:       target,
+		ScanID:       providers.ScanIDFrom(ctx),
+		Timestamp:    startTime.Format(time.RFC3339),
+		NamesChecked: len(names),
+		Buckets:      []CloudBucket{},
+	}
+
+	client := &http.Client{
+		Timeout:   RequestTimeout,
+		Transport: providers.Transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, ConcurrentRequests)
+		failed = make(map[string]int)
+		last   = make(map[string]error)
+	)
+	probe := func(provider string, fn func() ([]CloudBucket, error)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			buckets, err := fn()
+			mu.Lock()
+			defer mu.Unlock()
+			result.Buckets = append(result.Buckets, buckets...)
+			if err != nil {
+				failed[provider]++
+				last[provider] = err
+			}
+		}()
+	}
+
+	for _, name := range names {
+		probe(BucketS3, func() ([]CloudBucket, error) { return probeS3Bucket(ctx, client, name) })
+		probe(BucketGCS, func() ([]CloudBucket, error) { return probeGCSBucket(ctx, client, name) })
+		if azureAccountNameRegex.MatchString(name) {
+			probe(BucketAzure, func() ([]CloudBucket, error) { return probeAzureAccount(ctx, client, name) })
+		}
+	}
+	wg.Wait()
+
+	for _, provider := range []string{BucketS3, BucketGCS, BucketAzure} {
+		if failed[provider] > 0 {
+			result.PartialErrors = append(result.PartialErrors, ModuleError{
+				Module: provider,
+				Error:  fmt.Sprintf("%d probes failed, last: %v", failed[provider], last[provider]),
+			})
+		}
+	}
+	sort.Slice(result.Buckets, func(i, j int) bool {
+		a, b := result.Buckets[i], result.Buckets[j]
+		if a.Public != b.Public {
+			return a.Public
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Name < b.Name
+	})
+	result.ExecutionTime = time.Since(startTime).String()
+	return result, ctx.Err()
+}
+
+// bucketNames derives bucket names from a target: the name itself, joined
+// and hyphenated, and with common words before and after it. A domain
+// contributes its registrable label and the whole domain, hyphenated or with
+// the words as subdomains.
+func bucketNames(target string) []string {
+	var bases []string
+	if domain, err := input.Domain(target); err == nil && strings.Contains(domain, ".") {
+		if org, err := publicsuffix.EffectiveTLDPlusOne(domain); err == nil {
+			bases = append(bases, brandLabel(strings.SplitN(org, ".", 2)[0]))
+		}
+		bases = append(bases, domain, strings.ReplaceAll(domain, ".", "-"))
+	} else {
+		words := strings.FieldsFunc(strings.ToLower(target), func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+		})
+		bases = append(bases, strings.Join(words, ""), strings.Join(words, "-"))
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		if !seen[name] && bucketNameRegex.MatchString(name) && !strings.Contains(name, "..") && len(names) < maxBucketNames {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, base := range bases {
+		add(base)
+	}
+	for _, suffix := range bucketSuffixes {
+		for _, base := range bases {
+			// A bucket named after a whole domain takes words as subdomains
+			if strings.Contains(base, ".") {
+				add(suffix + "." + base)
+				continue
+			}
+			add(base + "-" + suffix)
+			add(base + suffix)
+			add(suffix + "-" + base)
+		}
+	}
+	return names
+}
+
+// probeS3Bucket asks S3 for a bucket's listing. S3 answers a bucket in
+// another region with a redirect naming the region, which is followed once.
+func probeS3Bucket(ctx context.Context, client *http.Client, name string) ([]CloudBucket, error) {
+	url := fmt.Sprintf("https://s3.amazonaws.com/%s/?max-keys=%d", name, maxBucketSample)
+	status, header, listing, err := fetchBucketListing(ctx, client, url)
+	if err != nil {
+		return nil, err
+	}
+	if region := header.Get("x-amz-bucket-region"); status >= 300 && status < 400 && region != "" {
+		url = fmt.Sprintf("https://s3.%s.amazonaws.com/%s/?max-keys=%d", region, name, maxBucketSample)
+		if status, _, listing, err = fetchBucketListing(ctx, client, url); err != nil {
+			return nil, err
+		}
+	}
+	return bucketFromStatus(BucketS3, name, strings.SplitN(url, "?", 2)[0], status, listing)
+}
+
+// probeGCSBucket asks Google Cloud Storage's XML API for a bucket's listing
+func probeGCSBucket(ctx context.Context, client *http.Client, name string) ([]CloudBucket, error) {
+	url := fmt.Sprintf("https://storage.googleapis.com/%s/?max-keys=%d", name, maxBucketSample)
+	status, _, listing, err := fetchBucketListing(ctx, client, url)
+	if err != nil {
+		return nil, err
+	}
+	return bucketFromStatus(BucketGCS, name, strings.SplitN(url, "?", 2)[0], status, listing)
+}
+
+// bucketFromStatus reads an S3 or GCS answer: a listing is a public bucket,
+// a refusal a private one and a 404 no bucket at all
+func bucketFromStatus(provider, name, url string, status int, listing *bucketListing) ([]CloudBucket, error) {
+	switch {
+	case status == http.StatusOK && listing != nil:
+		return []CloudBucket{{
+			Provider:  provider,
+			Name:      name,
+			URL:       url,
+			Public:    true,
+			Objects:   listing.Keys,
+			Truncated: listing.Truncated,
+		}}, nil
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return []CloudBucket{{Provider: provider, Name: name, URL: url}}, nil
+	case status == http.StatusNotFound || status == http.StatusBadRequest:
+		return nil, nil // No such bucket, or a name the provider does not allow
+	default:
+		return nil, fmt.Errorf("%s: status %d", name, status)
+	}
+}
+
+// probeAzureAccount checks whether a storage account exists, then lists the
+// common containers in it. Azure hides private containers behind a 404, so
+// an account is reported whenever it exists and containers only when public.
+func probeAzureAccount(ctx context.Context, client *http.Client, account string) ([]CloudBucket, error) {
+	host := account + ".blob.core.windows.net"
+	if _, err := net.DefaultResolver.LookupHost(ctx, host); err != nil {
+		if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	buckets := []CloudBucket{{Provider: BucketAzure, Name: account, URL: "https://" + host + "/"}}
+	var lastErr error
+	for _, container := range azureContainers {
+		url := fmt.Sprintf("https://%s/%s?restype=container&comp=list&maxresults=%d", host, container, maxBucketSample)
+		status, _, listing, err := fetchBucketListing(ctx, client, url)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if status == http.StatusOK && listing != nil {
+			buckets = append(buckets, CloudBucket{
+				Provider:  BucketAzure,
+				Name:      account + "/" + container,
+				URL:       "https://" + host + "/" + container,
+				Public:    true,
+				Objects:   listing.Blobs,
+				Truncated: listing.NextMarker != "",
+			})
+		}
+	}
+	return buckets, lastErr
+}
+
+// fetchBucketListing requests a listing URL, parsing the body of a 200
+func fetchBucketListing(ctx context.Context, client *http.Client, url string) (int, http.Header, *bucketListing, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, resp.Header, nil, nil
+	}
+
+	var listing bucketListing
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, MaxBodySize)).Decode(&listing); err != nil {
+		return resp.StatusCode, resp.Header, nil, nil // Not a listing, such as a website endpoint
+	}
+	if len(listing.Keys) > maxBucketSample {
+		listing.Keys = listing.Keys[:maxBucketSample]
+	}
+	if len(listing.Blobs) > maxBucketSample {
+		listing.Blobs = listing.Blobs[:maxBucketSample]
+	}
+	return resp.StatusCode, resp.Header, &listing, nil
+}
+
+// DisplayResults prints the public buckets with their sample objects, then
+// the private ones
+func (r *BucketScanResult) DisplayResults() {
+	color.Cyan("\n=== CLOUD BUCKETS: %s ===", r.Target)
+	color.Yellow("Names checked: %d on S3, Google Cloud Storage and Azure", r.NamesChecked)
+
+	public, private := 0, 0
+	for _, bucket := range r.Buckets {
+		if bucket.Public {
+			public++
+		} else {
+			private++
+		}
+	}
+
+	color.Cyan("\n[Public Listings]")
+	if public == 0 {
+		color.Green("• None found")
+	}
+	for _, bucket := range r.Buckets {
+		if !bucket.Public {
+			continue
+		}
+		color.Red("• %s %s - %s", bucket.Provider, bucket.Name, bucket.URL)
+		if len(bucket.Objects) == 0 {
+			color.White("  - Empty")
+		}
+		for _, object := range bucket.Objects {
+			color.White("  - %s", object)
+		}
+		if bucket.Truncated {
+			color.White("  - ... and more")
+		}
+	}
+
+	if private > 0 {
+		color.Cyan("\n[Existing, Not Listable]")
+		for _, bucket := range r.Buckets {
+			if !bucket.Public {
+				color.Yellow("• %s %s", bucket.Provider, bucket.Name)
+			}
+		}
+	}
+	color.White("\nExecution Time: %s", r.ExecutionTime)
+}
+
+// DisplayPartialErrors prints the providers whose probes failed
+func (r *BucketScanResult) DisplayPartialErrors() {
+	if len(r.PartialErrors) == 0 {
+		return
+	}
+	color.Yellow("\nProbes that failed:")
+	for _, moduleErr := range r.PartialErrors {
+		color.Yellow("  • %s: %s", moduleErr.Module, moduleErr.Error)
+	}
+}
@@ -0,0 +1,207 @@
+package osint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/input"
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// Artifact type for the Google IDs a combined scan pivots on
+const ArtifactGoogleID = "gid"
+
+// maxCombinedPivots caps how many Google IDs and phone numbers found along
+// the way a combined scan analyzes
+const maxCombinedPivots = 5
+
+// combinedModuleTimeout bounds each Google ID and phone number analysis
+const combinedModuleTimeout = 30 * time.Second
+
+var (
+	// phoneInTextRegex finds international numbers written in bios and posts
+	phoneInTextRegex = regexp.MustCompile(`(?:\+|\b00)\d[\d ().-]{6,18}\d`)
+	// mapsContribRegex finds the Google ID in a Maps contributor link
+	mapsContribRegex = regexp.MustCompile(`google\.[a-z.]+/maps/contrib/(\d{21})`)
+)
+
+// CombinedReport merges every module run from one seed into one report.
+// Pivots list what the later modules were run on and which module found it.
+type CombinedReport struct {
+	Seed          string               `json:"seed"`
+	SeedKind      string               `json:"seed_kind"` // email or name
+	ScanID        string               `json:"scan_id,omitempty"`
+	Timestamp     string               `json:"timestamp"`
+	Email         *EmailAnalysisResult `json:"email,omitempty"`
+	Social        *SocialMediaResults  `json:"social,omitempty"`
+	GoogleIDs     []*GoogleIDResult    `json:"google_ids,omitempty"`
+	Phones        []*PhoneNumberResult `json:"phones,omitempty"`
+	Pivots        []Artifact           `json:"pivots,omitempty"`
+	PartialErrors []ModuleError        `json:"partial_errors,omitempty"`
+	ExecutionTime string               `json:"execution_time"`
+}
+
+// ScanAll runs every identity module from one seed, an email address or a
+// name. An email is analyzed first and its user part searched on social
+// media; a name is searched directly. Google IDs and phone numbers found in
+// the results are then analyzed too. A module that fails is recorded in
+// PartialErrors and the others still run. Cancelling ctx during the social
+// search returns the report so far with ErrInterrupted.
+func ScanAll(ctx context.Context, seed string, verbose bool) (*CombinedReport, error) {
+	startTime := time.Now()
+	report := &CombinedReport{
+		Seed:      seed,
+		ScanID:    providers.ScanIDFrom(ctx),
+		Timestamp: startTime.Format(time.RFC3339),
+	}
+
+	query := seed
+	if email, err := input.Email(seed); err == nil {
+		report.Seed, report.SeedKind = email, input.KindEmail
+		results, err := AnalyzeEmail(email)
+		if err != nil {
+			report.addError("email", err)
+		}
+		report.Email = results
+		query = email[:strings.LastIndex(email, "@")]
+		report.Pivots = append(report.Pivots, Artifact{Type: ArtifactHandle, Value: query, Source: "email"})
+	} else {
+		name, err := input.Name(seed)
+		if err != nil {
+			return nil, err
+		}
+		report.Seed, report.SeedKind = name, input.KindName
+		query = name
+	}
+
+	social, err := SearchProfilesSequentially(ctx, query, "", verbose)
+	report.Social = social
+	if errors.Is(err, ErrInterrupted) {
+		// Pivots are not run on the profiles of an interrupted search
+		report.ExecutionTime = time.Since(startTime).String()
+		return report, err
+	}
+	if err != nil {
+		report.addError("social", err)
+	}
+
+	report.Pivots = dedupeArtifacts(append(report.Pivots, report.discoverPivots()...))
+
+	for _, gid := range artifactValues(report.Pivots, ArtifactGoogleID) {
+		// An ID the email module already analyzed is moved over, not analyzed twice
+		if report.Email != nil && report.Email.GmailSpecific.GoogleIDResults != nil && report.Email.GmailSpecific.GoogleID == gid {
+			report.GoogleIDs = append(report.GoogleIDs, report.Email.GmailSpecific.GoogleIDResults)
+			report.Email.GmailSpecific.GoogleIDResults = nil
+			continue
+		}
+		gidCtx, cancel := context.WithTimeout(ctx, combinedModuleTimeout)
+		results, err := AnalyzeGoogleID(gidCtx, gid)
+		cancel()
+		if err != nil {
+			report.addError("gid "+gid, err)
+		}
+		if results != nil {
+			report.GoogleIDs = append(report.GoogleIDs, results)
+		}
+	}
+
+	for _, phone := range artifactValues(report.Pivots, ArtifactPhone) {
+		phoneCtx, cancel := context.WithTimeout(ctx, combinedModuleTimeout)
+		results, err := AnalyzePhoneNumber(phoneCtx, phone)
+		cancel()
+		if err != nil {
+			report.addError("phone "+phone, err)
+			continue
+		}
+		report.Phones = append(report.Phones, results)
+	}
+
+	report.ExecutionTime = time.Since(startTime).String()
+	return report, nil
+}
+
+func (r *CombinedReport) addError(module string, err error) {
+	r.PartialErrors = append(r.PartialErrors, ModuleError{Module: module, Error: err.Error()})
+}
+
+// discoverPivots finds the Google IDs and phone numbers in the email and
+// social results, at most maxCombinedPivots of each
+func (r *CombinedReport) discoverPivots() []Artifact {
+	type text struct{ source, value string }
+	var texts []text
+	if r.Email != nil {
+		if r.Email.GmailSpecific.GoogleID != "" {
+			texts = append(texts, text{"email", "google.com/maps/contrib/" + r.Email.GmailSpecific.GoogleID})
+		}
+		for _, profile := range r.Email.SocialProfiles {
+			texts = append(texts, text{"email " + profile.Platform, profile.URL + " " + profile.Bio})
+		}
+	}
+	if r.Social != nil {
+		for _, profile := range append(r.Social.Profiles, r.Social.Leads...) {
+			value := profile.Bio
+			for _, link := range profile.BioLinks {
+				value += " " + link.URL + " " + link.FinalURL
+			}
+			texts = append(texts, text{"social " + profile.Platform, value})
+		}
+	}
+
+	var pivots []Artifact
+	gids, phones := make(map[string]bool), make(map[string]bool)
+	for _, t := range texts {
+		for _, match := range mapsContribRegex.FindAllStringSubmatch(t.value, -1) {
+			if len(gids) < maxCombinedPivots && !gids[match[1]] {
+				gids[match[1]] = true
+				pivots = append(pivots, Artifact{Type: ArtifactGoogleID, Value: match[1], Source: t.source})
+			}
+		}
+		for _, match := range phoneInTextRegex.FindAllString(t.value, -1) {
+			phone, err := input.Phone(match)
+			if err != nil || len(phones) >= maxCombinedPivots || phones[phone] {
+				continue
+			}
+			phones[phone] = true
+			pivots = append(pivots, Artifact{Type: ArtifactPhone, Value: phone, Source: t.source, Context: match})
+		}
+	}
+	return pivots
+}
+
+// DisplayPivots prints what the combined scan pivoted on and where it came from
+func (r *CombinedReport) DisplayPivots() {
+	color.Cyan("\n=== PIVOTS ===")
+	if len(r.Pivots) == 0 {
+		color.White("Nothing found to pivot on")
+		return
+	}
+	for _, pivot := range r.Pivots {
+		color.White("• %s %s (from %s)", pivot.Type, pivot.Value, pivot.Source)
+	}
+}
+
+// DisplayPartialErrors prints the modules that failed
+func (r *CombinedReport) DisplayPartialErrors() {
+	if len(r.PartialErrors) == 0 {
+		return
+	}
+	color.Yellow("\nModules that failed:")
+	for _, moduleErr := range r.PartialErrors {
+		color.Yellow("  • %s: %s", moduleErr.Module, moduleErr.Error)
+	}
+}
+
+// Summary returns a one-line count of what each module found
+func (r *CombinedReport) Summary() string {
+	profiles := 0
+	if r.Social != nil {
+		profiles = r.Social.ProfilesFound
+	}
+	return fmt.Sprintf("%d profiles, %d Google IDs and %d phone numbers analyzed, %d modules failed",
+		profiles, len(r.GoogleIDs), len(r.Phones), len(r.PartialErrors))
+}
@@ -0,0 +1,614 @@
+package osint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"golang.org/x/time/rate"
+)
+
+var (
+	// OutputDir is where results are saved when no other path is given
+	OutputDir = "results"
+	// EnabledPlatforms limits profile searches to these platforms; empty
+	// searches them all
+	EnabledPlatforms []string
+	// RequestLogFile receives a record of every outbound request when set
+	RequestLogFile string
+	// ProxyURL is the http, https or socks5 proxy requests go through when set
+	ProxyURL string
+)
+
+// Config is the contents of the config file. Keys it leaves out keep their
+// defaults.
+type Config struct {
+	APIKeys     APIKeys              `json:"api_keys"`
+	OutputDir   string               `json:"output_dir"`
+	RateLimits  map[string]RateLimit `json:"rate_limits"` // By service name, e.g. Shodan
+	Platforms   []string             `json:"platforms"`
+	Summary     SummaryConfig        `json:"summary"`
+	HooksDir    string               `json:"hooks_dir"`
+	ModulesDir  string               `json:"modules_dir"`
+	SecretStore string               `json:"secret_store"` // keychain or file; the keychain when there is one by default
+	RequestLog  string               `json:"request_log"`  // Append-only log of every outbound request
+	Proxy       string               `json:"proxy"`        // e.g. socks5://127.0.0.1:9050
+}
+
+// SummaryConfig points AI-generated summaries at a language model
+type SummaryConfig struct {
+	URL   string `json:"url"` // Base of an OpenAI-compatible API, e.g. http://localhost:11434/v1
+	Model string `json:"model"`
+}
+
+// RateLimit overrides the published rate limit of a service
+type RateLimit struct {
+	Interval ConfigDuration `json:"interval"` // Minimum time between requests; 0 removes the limit
+	Burst    int            `json:"burst"`
+}
+
+// ConfigDuration is a duration written as text such as 2s or 1m, or a bare 0
+type ConfigDuration time.Duration
+
+// UnmarshalJSON reads a duration string or the number 0
+func (d *ConfigDuration) UnmarshalJSON(data []byte) error {
+	if string(data) == "0" {
+		*d = 0
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("invalid duration %s, expected one such as 2s", data)
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil || parsed < 0 {
+		return fmt.Errorf("invalid duration %q, expected one such as 2s", text)
+	}
+	*d = ConfigDuration(parsed)
+	return nil
+}
+
+// ConfigPath returns $MERCURIES_CONFIG, or ~/.mercuries.yaml when it is unset
+func ConfigPath() string {
+	if path := os.Getenv("MERCURIES_CONFIG"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".mercuries.yaml")
+}
+
+// LoadConfig reads and applies the config file at ConfigPath, then lets
+// environment variables override its API keys. A missing ~/.mercuries.yaml is
+// not an error; a missing $MERCURIES_CONFIG is.
+func LoadConfig() error {
+	if err := loadConfigFile(); err != nil {
+		return err
+	}
+	ApplyEnvironment()
+	return nil
+}
+
+// loadConfigFile applies the config file at ConfigPath, if there is one
+func loadConfigFile() error {
+	path := ConfigPath()
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && os.Getenv("MERCURIES_CONFIG") == "" {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	config, err := ParseConfig(data)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	if err := ApplyConfig(config); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// apiKeyEnv maps the environment variables that override API keys to the
+// keys they set
+func apiKeyEnv(keys *APIKeys) map[string]*string {
+	return map[string]*string{
+		"HIBP_API_KEY":           &keys.HIBPKey,
+		"MAXMIND_API_KEY":        &keys.MaxMindKey,
+		"SHODAN_API_KEY":         &keys.ShodanKey,
+		"HUNTERIO_API_KEY":       &keys.HunterIOKey,
+		"FULLCONTACT_API_KEY":    &keys.FullContactKey,
+		"CENSYS_ID":              &keys.CensysID,
+		"CENSYS_SECRET":          &keys.CensysSecret,
+		"SPYONWEB_TOKEN":         &keys.SpyOnWebToken,
+		"SAFEBROWSING_API_KEY":   &keys.SafeBrowsingKey,
+		"PHISHTANK_API_KEY":      &keys.PhishTankKey,
+		"URLSCAN_API_KEY":        &keys.URLScanKey,
+		"VIRUSTOTAL_API_KEY":     &keys.VirusTotalKey,
+		"GREYNOISE_API_KEY":      &keys.GreyNoiseKey,
+		"ABUSEIPDB_API_KEY":      &keys.AbuseIPDBKey,
+		"THEHIVE_API_KEY":        &keys.TheHiveKey,
+		"OPENCTI_API_KEY":        &keys.OpenCTIKey,
+		"POLICY_API_KEY":         &keys.PolicyKey,
+		"GITHUB_TOKEN":           &keys.GitHubToken,
+		"TWITTER_BEARER_TOKEN":   &keys.TwitterBearerToken,
+		"SPOTIFY_CLIENT_ID":      &keys.SpotifyClientID,
+		"SPOTIFY_CLIENT_SECRET":  &keys.SpotifyClientSecret,
+		"LASTFM_API_KEY":         &keys.LastFMKey,
+		"DEEPL_API_KEY":          &keys.DeepLKey,
+		"LIBRETRANSLATE_API_KEY": &keys.LibreTranslateKey,
+		"LLM_API_KEY":            &keys.LLMKey,
+	}
+}
+
+// ApplyEnvironment sets the API keys whose environment variables are set and
+// not empty, overriding the defaults and the config file
+func ApplyEnvironment() {
+	for name, key := range apiKeyEnv(&APIConfig) {
+		if value := os.Getenv(name); value != "" {
+			*key = value
+		}
+	}
+}
+
+// ParseConfig decodes a config file, starting from the current API keys so
+// that keys left out keep their values
+func ParseConfig(data []byte) (*Config, error) {
+	tree, err := parseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{APIKeys: APIConfig}
+	encoded, err := json.Marshal(typeYAML(tree, reflect.TypeOf(config)))
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("invalid config: %v", strings.TrimPrefix(err.Error(), "json: "))
+	}
+	return config, nil
+}
+
+// ApplyConfig sets the API keys, output directory, rate limits, enabled
+// platforms, summary model, secret store, and hooks and modules directories
+// from a parsed config
+func ApplyConfig(config *Config) error {
+	for name, limit := range config.RateLimits {
+		every := rate.Limit(0)
+		if limit.Interval > 0 {
+			every = rate.Every(time.Duration(limit.Interval))
+		}
+		if err := providers.SetLimit(name, every, max(limit.Burst, 1)); err != nil {
+			return fmt.Errorf("rate_limits: %v", err)
+		}
+	}
+
+	var enabled []string
+	for _, name := range config.Platforms {
+		found := platformName(name)
+		if found == "" {
+			return fmt.Errorf("platforms: unknown platform %q", name)
+		}
+		enabled = append(enabled, found)
+	}
+
+	APIConfig = config.APIKeys
+	if config.OutputDir != "" {
+		OutputDir = expandHome(config.OutputDir)
+		WatchlistDir = filepath.Join(OutputDir, "watchlists")
+		GeocodeCacheFile = filepath.Join(OutputDir, "geocode-cache.json")
+	}
+	if len(enabled) > 0 {
+		EnabledPlatforms = enabled
+	}
+	switch config.SecretStore {
+	case SecretStoreAuto, SecretStoreKeychain, SecretStoreFile:
+		SecretStoreKind = config.SecretStore
+	default:
+		return fmt.Errorf("secret_store: expected keychain or file, not %q", config.SecretStore)
+	}
+	if config.HooksDir != "" {
+		HooksDir = expandHome(config.HooksDir)
+	}
+	if config.ModulesDir != "" {
+		ModulesDir = expandHome(config.ModulesDir)
+	}
+	if config.Proxy != "" {
+		ProxyURL = config.Proxy
+	}
+	if config.RequestLog != "" {
+		RequestLogFile = expandHome(config.RequestLog)
+	}
+	if config.Summary.URL != "" {
+		SummaryURL = config.Summary.URL
+	}
+	if config.Summary.Model != "" {
+		SummaryModel = config.Summary.Model
+	}
+	return nil
+}
+
+// expandHome replaces a leading ~/ with the home directory
+func expandHome(path string) string {
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(path, "~/") {
+		return filepath.Join(home, path[2:])
+	}
+	return path
+}
+
+// platformName returns the platform a name refers to, ignoring case, or ""
+// when there is none
+func platformName(name string) string {
+	for _, platform := range platforms {
+		if strings.EqualFold(platform.Name, strings.TrimSpace(name)) {
+			return platform.Name
+		}
+	}
+	return ""
+}
+
+// PlatformNames returns the names of the platforms profile searches can check
+func PlatformNames() []string {
+	names := make([]string, 0, len(platforms))
+	for _, platform := range platforms {
+		names = append(names, platform.Name)
+	}
+	return names
+}
+
+// SearchedPlatforms returns the names of the platforms profile searches
+// check, after EnabledPlatforms and the filters of FilterPlatforms
+func SearchedPlatforms() []string {
+	var names []string
+	for _, platform := range scanPlatforms() {
+		names = append(names, platform.Name)
+	}
+	return names
+}
+
+// FilterPlatforms narrows the platforms profile searches check to include,
+// in place of the config file's list, when it is not empty, then drops
+// exclude. Names are matched ignoring case.
+func FilterPlatforms(include, exclude []string) error {
+	var enabled, excluded []string
+	for _, name := range include {
+		found := platformName(name)
+		if found == "" {
+			return fmt.Errorf("unknown platform %q; expected one of %s", name, strings.Join(PlatformNames(), ", "))
+		}
+		enabled = append(enabled, found)
+	}
+	for _, name := range exclude {
+		found := platformName(name)
+		if found == "" {
+			return fmt.Errorf("unknown platform %q; expected one of %s", name, strings.Join(PlatformNames(), ", "))
+		}
+		excluded = append(excluded, found)
+	}
+	if len(enabled) > 0 {
+		EnabledPlatforms = enabled
+	}
+	if len(excluded) == 0 {
+		return nil
+	}
+
+	var remaining []string
+	for _, platform := range scanPlatforms() {
+		if !slices.Contains(excluded, platform.Name) {
+			remaining = append(remaining, platform.Name)
+		}
+	}
+	if len(remaining) == 0 {
+		return fmt.Errorf("every platform is excluded")
+	}
+	EnabledPlatforms = remaining
+	return nil
+}
+
+// scanPlatforms returns the platforms profile searches check, honouring
+// EnabledPlatforms
+func scanPlatforms() []SocialPlatform {
+	if len(EnabledPlatforms) == 0 {
+		return platforms
+	}
+	var enabled []SocialPlatform
+	for _, platform := range platforms {
+		for _, name := range EnabledPlatforms {
+			if platform.Name == name {
+				enabled = append(enabled, platform)
+				break
+			}
+		}
+	}
+	return enabled
+}
+
+// yamlLine is a non-blank line of a YAML document with its comment removed
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// parseYAML reads the subset of YAML a config file needs: nested mappings,
+// block and flow lists of scalars, plain and quoted scalars, and comments
+func parseYAML(data []byte) (map[string]interface{}, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(stripYAMLComment(raw), " \t\r")
+		trimmed := strings.TrimLeft(raw, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: indent with spaces, not tabs", i+1)
+		}
+		lines = append(lines, yamlLine{number: i + 1, indent: len(raw) - len(trimmed), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	node, rest, err := parseYAMLBlock(lines, lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("line %d: unexpected indentation", rest[0].number)
+	}
+	tree, ok := node.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("line %d: expected keys at the top level", lines[0].number)
+	}
+	return tree, nil
+}
+
+// parseYAMLBlock reads the mapping or list starting at lines[0], whose lines
+// share indent, and returns the lines after it
+func parseYAMLBlock(lines []yamlLine, indent int) (interface{}, []yamlLine, error) {
+	if lines[0].text == "-" || strings.HasPrefix(lines[0].text, "- ") {
+		var list []interface{}
+		for len(lines) > 0 && lines[0].indent == indent && (lines[0].text == "-" || strings.HasPrefix(lines[0].text, "- ")) {
+			value, err := parseYAMLScalar(strings.TrimSpace(strings.TrimPrefix(lines[0].text, "-")))
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %v", lines[0].number, err)
+			}
+			list = append(list, value)
+			lines = lines[1:]
+		}
+		return list, lines, nil
+	}
+
+	mapping := make(map[string]interface{})
+	for len(lines) > 0 && lines[0].indent == indent {
+		line := lines[0]
+		key, value, ok := strings.Cut(line.text, ":")
+		if !ok || (value != "" && value[0] != ' ') {
+			return nil, nil, fmt.Errorf("line %d: expected key: value", line.number)
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"'`)
+		if _, dup := mapping[key]; dup {
+			return nil, nil, fmt.Errorf("line %d: duplicate key %q", line.number, key)
+		}
+		lines = lines[1:]
+
+		if value = strings.TrimSpace(value); value != "" {
+			scalar, err := parseYAMLScalar(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %v", line.number, err)
+			}
+			mapping[key] = scalar
+			continue
+		}
+		// A list may sit at the key's own indent; a mapping must be deeper
+		if len(lines) > 0 && (lines[0].indent > indent || (lines[0].indent == indent && strings.HasPrefix(lines[0].text, "-"))) {
+			nested, rest, err := parseYAMLBlock(lines, lines[0].indent)
+			if err != nil {
+				return nil, nil, err
+			}
+			mapping[key], lines = nested, rest
+			continue
+		}
+		mapping[key] = nil
+	}
+	if len(lines) > 0 && lines[0].indent > indent {
+		return nil, nil, fmt.Errorf("line %d: unexpected indentation", lines[0].number)
+	}
+	return mapping, lines, nil
+}
+
+// plainScalar is an unquoted scalar, kept as written until typeYAML knows
+// whether the field it lands in holds text, a number or a bool
+type plainScalar string
+
+// parseYAMLScalar converts a value to a quoted string, a plainScalar, nil or,
+// for a [flow, list], a list of those
+func parseYAMLScalar(value string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(value, "["):
+		if !strings.HasSuffix(value, "]") {
+			return nil, errors.New("unterminated list")
+		}
+		list := []interface{}{}
+		inner := strings.TrimSpace(value[1 : len(value)-1])
+		if inner == "" {
+			return list, nil
+		}
+		for _, item := range splitYAMLFlow(inner) {
+			scalar, err := parseYAMLScalar(strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, scalar)
+		}
+		return list, nil
+	case strings.HasPrefix(value, `"`):
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted string %s", value)
+		}
+		return unquoted, nil
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return nil, fmt.Errorf("invalid quoted string %s", value)
+		}
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	}
+
+	switch strings.ToLower(value) {
+	case "~", "null":
+		return nil, nil
+	}
+	return plainScalar(value), nil
+}
+
+var jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// typeYAML converts the plain scalars of a parsed YAML tree for decoding
+// into t: to numbers and bools only where t holds a number or a bool, so an
+// API key of 12345 or an output_dir of "on" stays text
+func typeYAML(node interface{}, t reflect.Type) interface{} {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch value := node.(type) {
+	case map[string]interface{}:
+		typed := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			typed[key] = typeYAML(item, yamlFieldType(t, key))
+		}
+		return typed
+	case []interface{}:
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		typed := make([]interface{}, len(value))
+		for i, item := range value {
+			typed[i] = typeYAML(item, elem)
+		}
+		return typed
+	case plainScalar:
+		return plainYAMLValue(string(value), t)
+	}
+	return node
+}
+
+// yamlFieldType returns the type a key of a mapping decodes into: the
+// field with that JSON name in a struct, the values of a map, or nil
+func yamlFieldType(t reflect.Type, key string) reflect.Type {
+	if t == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Elem()
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" {
+				name = field.Name
+			}
+			if field.IsExported() && strings.EqualFold(name, key) {
+				return field.Type
+			}
+		}
+	}
+	return nil
+}
+
+// plainYAMLValue reads a plain scalar as t expects it. Text that is no
+// valid number or bool for a numeric or bool field is left for the decoder
+// to report.
+func plainYAMLValue(text string, t reflect.Type) interface{} {
+	if t == nil || reflect.PointerTo(t).Implements(jsonUnmarshaler) {
+		return text
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		switch strings.ToLower(text) {
+		case "true", "yes", "on":
+			return true
+		case "false", "no", "off":
+			return false
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseUint(text, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	}
+	return text
+}
+
+// splitYAMLFlow splits the items of a flow list on commas outside quotes
+func splitYAMLFlow(inner string) []string {
+	var items []string
+	var quote rune
+	start := 0
+	for i, r := range inner {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			items = append(items, inner[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, inner[start:])
+}
+
+// stripYAMLComment removes a # comment that starts the line or follows a
+// space, outside quoted scalars
+func stripYAMLComment(line string) string {
+	var quote, prev rune
+	escaped := false
+	for i, r := range line {
+		switch {
+		case quote == '"' && escaped:
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case (r == '"' || r == '\'') && (prev == 0 || strings.ContainsRune(":-[,", prev)):
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+		if r != ' ' && r != '\t' {
+			prev = r
+		}
+	}
+	return line
+}
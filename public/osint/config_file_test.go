@@ -0,0 +1,149 @@
+package osint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseConfigKeepsPlainScalarsAsText(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+api_keys:
+  hibp_key: 12345          # numeric keys stay text
+  shodan_key: yes
+  hunterio_key: 0x1F
+  fullcontact_key: 1e3
+  censys_id: "quoted # not a comment"
+output_dir: on
+proxy: null
+platforms: [GitHub, 'Reddit', 1337]
+rate_limits:
+  Shodan:
+    interval: 2s
+    burst: 3
+  ip-api:
+    interval: 0
+`))
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+
+	keys := config.APIKeys
+	got := []string{keys.HIBPKey, keys.ShodanKey, keys.HunterIOKey, keys.FullContactKey, keys.CensysID}
+	want := []string{"12345", "yes", "0x1F", "1e3", "quoted # not a comment"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("API keys = %q, want %q", got, want)
+	}
+	if config.OutputDir != "on" || config.Proxy != "" {
+		t.Errorf("output_dir = %q and proxy = %q, want on and empty", config.OutputDir, config.Proxy)
+	}
+	if !reflect.DeepEqual(config.Platforms, []string{"GitHub", "Reddit", "1337"}) {
+		t.Errorf("platforms = %q", config.Platforms)
+	}
+	if limit := config.RateLimits["Shodan"]; time.Duration(limit.Interval) != 2*time.Second || limit.Burst != 3 {
+		t.Errorf("Shodan limit = %+v, want 2s with a burst of 3", limit)
+	}
+	if limit, ok := config.RateLimits["ip-api"]; !ok || limit.Interval != 0 {
+		t.Errorf("ip-api limit = %+v, want no limit", limit)
+	}
+}
+
+func TestParseConfigRejects(t *testing.T) {
+	tests := []struct {
+		yaml, want string
+	}{
+		{"rate_limits:\n  Shodan:\n    burst: many\n", "burst"},
+		{"rate_limits:\n  Shodan:\n    interval: soon\n", "invalid duration"},
+		{"unknown_key: 1\n", "unknown field"},
+		{"output_dir: a\noutput_dir: b\n", "duplicate key"},
+		{"api_keys:\n\thibp_key: x\n", "tabs"},
+		{"output_dir: a\n    proxy: b\n", "unexpected indentation"},
+		{"platforms: [GitHub\n", "unterminated list"},
+		{"- a\n- b\n", "top level"},
+	}
+	for _, tt := range tests {
+		_, err := ParseConfig([]byte(tt.yaml))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseConfig(%q) = %v, want an error about %s", tt.yaml, err, tt.want)
+		}
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	tree, err := parseYAML([]byte(`---
+# comment
+name: 'it''s'
+list:
+- one
+- "two # three"
+nested:
+  deeper:
+    value: 42
+flow: []
+empty:
+`))
+	if err != nil {
+		t.Fatalf("parseYAML: %v", err)
+	}
+	want := map[string]interface{}{
+		"name":   "it's",
+		"list":   []interface{}{plainScalar("one"), "two # three"},
+		"nested": map[string]interface{}{"deeper": map[string]interface{}{"value": plainScalar("42")}},
+		"flow":   []interface{}{},
+		"empty":  nil,
+	}
+	if !reflect.DeepEqual(tree, want) {
+		t.Errorf("parseYAML = %#v, want %#v", tree, want)
+	}
+}
+
+func TestTypeYAMLConvertsForTheTargetField(t *testing.T) {
+	type target struct {
+		Count   int               `json:"count"`
+		Ratio   float64           `json:"ratio"`
+		Enabled bool              `json:"enabled"`
+		Text    string            `json:"text"`
+		Limits  map[string]int    `json:"limits"`
+		Tags    []string          `json:"tags"`
+		Extra   map[string]string `json:"extra"`
+	}
+	tree := map[string]interface{}{
+		"count":   plainScalar("7"),
+		"ratio":   plainScalar("0.5"),
+		"enabled": plainScalar("on"),
+		"text":    plainScalar("true"),
+		"limits":  map[string]interface{}{"a": plainScalar("3")},
+		"tags":    []interface{}{plainScalar("10"), "quoted"},
+		"extra":   map[string]interface{}{"b": plainScalar("no")},
+	}
+	got := typeYAML(tree, reflect.TypeOf(&target{}))
+	want := map[string]interface{}{
+		"count":   int64(7),
+		"ratio":   0.5,
+		"enabled": true,
+		"text":    "true",
+		"limits":  map[string]interface{}{"a": int64(3)},
+		"tags":    []interface{}{"10", "quoted"},
+		"extra":   map[string]interface{}{"b": "no"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("typeYAML = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseCustomModuleKeepsNumericPatterns(t *testing.T) {
+	module, err := ParseCustomModule("numbers", []byte(`
+url: https://example.com/u/{target}
+found: 200
+extract:
+  id:
+    pattern: 12345
+`))
+	if err != nil {
+		t.Fatalf("ParseCustomModule: %v", err)
+	}
+	if module.Found != "200" || module.Extract["id"].Pattern != "12345" {
+		t.Errorf("found = %q and pattern = %q, want the numbers as text", module.Found, module.Extract["id"].Pattern)
+	}
+}
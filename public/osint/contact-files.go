@@ -0,0 +1,187 @@
+package osint
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// Obfuscated addresses such as "jane [at] example [dot] com"
+	obfuscatedAtRegex  = regexp.MustCompile(`(?i)\s*[\[\(\{<]\s*at\s*[\]\)\}>]\s*|\s+at\s+`)
+	obfuscatedDotRegex = regexp.MustCompile(`(?i)\s*[\[\(\{<]\s*dot\s*[\]\)\}>]\s*|\s+dot\s+`)
+	humansSectionRegex = regexp.MustCompile(`^/\*\s*(.+?)\s*\*/$`)
+	handleRegex        = regexp.MustCompile(`^@[A-Za-z0-9_.]{1,30}$`)
+)
+
+// humansNameKeys are humans.txt fields whose value is usually a person's name
+var humansNameKeys = map[string]bool{
+	"name": true, "developer": true, "designer": true, "author": true,
+	"founder": true, "co-founder": true, "ceo": true, "cto": true,
+	"chef": true, "editor": true, "writer": true, "maintainer": true,
+	"web developer": true, "front-end developer": true, "back-end developer": true,
+	"lead": true, "team lead": true, "engineer": true, "manager": true,
+}
+
+// humansPlaceKeys are humans.txt fields describing where someone is, not who
+var humansPlaceKeys = map[string]bool{
+	"from": true, "location": true, "city": true, "country": true, "based in": true,
+}
+
+// humansHandleKeys are humans.txt fields holding social media handles
+var humansHandleKeys = map[string]bool{
+	"twitter": true, "x": true, "github": true, "mastodon": true,
+	"instagram": true, "linkedin": true, "dribbble": true,
+}
+
+// parseSecurityTxt extracts contact artifacts from an RFC 9116 security.txt file
+func parseSecurityTxt(content, source string) ([]Artifact, []string) {
+	var artifacts []Artifact
+	var details []string
+
+	inSignature := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+
+		// Skip the armour of PGP cleartext-signed files
+		switch {
+		case strings.HasPrefix(line, "-----BEGIN PGP SIGNATURE"):
+			inSignature = true
+			continue
+		case strings.HasPrefix(line, "-----END PGP SIGNATURE"):
+			inSignature = false
+			continue
+		case inSignature, strings.HasPrefix(line, "-----"), strings.HasPrefix(line, "Hash:"):
+			continue
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		field, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		field = strings.TrimSpace(field)
+		value = strings.TrimSpace(value)
+		details = append(details, field+": "+value)
+
+		switch strings.ToLower(field) {
+		case "contact":
+			artifacts = append(artifacts, contactArtifact(value, source, "security.txt Contact"))
+		case "acknowledgments", "acknowledgements", "policy", "hiring", "encryption", "canonical":
+			artifacts = append(artifacts, Artifact{Type: ArtifactURL, Value: value, Source: source, Context: "security.txt " + field})
+		}
+	}
+
+	// Bare addresses in comments are still useful contacts
+	for _, email := range extractEmails(content) {
+		artifacts = append(artifacts, Artifact{Type: ArtifactEmail, Value: email, Source: source, Context: "security.txt"})
+	}
+
+	return dedupeArtifacts(artifacts), details
+}
+
+// contactArtifact classifies a security.txt Contact value
+func contactArtifact(value, source, context string) Artifact {
+	lower := strings.ToLower(value)
+	switch {
+	case strings.HasPrefix(lower, "mailto:"):
+		email := strings.SplitN(value[len("mailto:"):], "?", 2)[0]
+		return Artifact{Type: ArtifactEmail, Value: strings.ToLower(email), Source: source, Context: context}
+	case strings.HasPrefix(lower, "tel:"):
+		return Artifact{Type: ArtifactPhone, Value: value[len("tel:"):], Source: source, Context: context}
+	case emailAddressRegex.MatchString(value) && !strings.Contains(value, "/"):
+		return Artifact{Type: ArtifactEmail, Value: strings.ToLower(value), Source: source, Context: context}
+	default:
+		return Artifact{Type: ArtifactURL, Value: value, Source: source, Context: context}
+	}
+}
+
+// parseHumansTxt extracts names, emails and handles from a humans.txt file.
+// The format is informal: "/* SECTION */" headings followed by "Key: Value" lines.
+func parseHumansTxt(content, source string) ([]Artifact, []string) {
+	var artifacts []Artifact
+	var details []string
+
+	section := ""
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if matches := humansSectionRegex.FindStringSubmatch(line); len(matches) > 1 {
+			section = strings.ToUpper(matches[1])
+			continue
+		}
+
+		field, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(field))
+		value = strings.TrimSpace(value)
+		if value == "" || strings.HasPrefix(value, "//") {
+			continue
+		}
+		context := "humans.txt " + strings.TrimSpace(field)
+		if section != "" {
+			context = "humans.txt " + section + " " + strings.TrimSpace(field)
+		}
+
+		// Addresses are often written as "jane [at] example.com" to dodge scrapers
+		if email := deobfuscateEmail(value); email != "" {
+			artifacts = append(artifacts, Artifact{Type: ArtifactEmail, Value: email, Source: source, Context: context})
+			details = append(details, line)
+			continue
+		}
+
+		switch {
+		case humansHandleKeys[key]:
+			artifacts = append(artifacts, Artifact{Type: ArtifactHandle, Value: value, Source: source, Context: context})
+		case handleRegex.MatchString(value):
+			artifacts = append(artifacts, Artifact{Type: ArtifactHandle, Value: value, Source: source, Context: context})
+		case strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"):
+			artifacts = append(artifacts, Artifact{Type: ArtifactURL, Value: value, Source: source, Context: context})
+		case humansNameKeys[key] || (section == "TEAM" && !humansPlaceKeys[key] && looksLikeName(value)):
+			artifacts = append(artifacts, Artifact{Type: ArtifactName, Value: value, Source: source, Context: context})
+		default:
+			continue
+		}
+		details = append(details, line)
+	}
+
+	return dedupeArtifacts(artifacts), details
+}
+
+// deobfuscateEmail returns the address in value, undoing common [at]/[dot] obfuscation
+func deobfuscateEmail(value string) string {
+	if match := emailAddressRegex.FindString(value); match != "" {
+		return strings.ToLower(match)
+	}
+
+	cleaned := obfuscatedAtRegex.ReplaceAllString(value, "@")
+	cleaned = obfuscatedDotRegex.ReplaceAllString(cleaned, ".")
+	if match := emailAddressRegex.FindString(cleaned); match != "" && strings.Count(cleaned, "@") == 1 {
+		return strings.ToLower(match)
+	}
+	return ""
+}
+
+// looksLikeName reports whether value is plausibly a person's name
+func looksLikeName(value string) bool {
+	if strings.ContainsAny(value, ",/&") {
+		return false
+	}
+	words := strings.Fields(value)
+	if len(words) < 2 || len(words) > 4 {
+		return false
+	}
+	for _, word := range words {
+		if word[0] < 'A' || word[0] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,568 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// Container registries searched for images
+const (
+	RegistryDockerHub = "docker.io"
+	RegistryGHCR      = "ghcr.io"
+)
+
+// maxContainerImages caps the images inspected on each registry
+const maxContainerImages = 20
+
+// registryManifestTypes are the manifest and index formats accepted from a
+// registry, newest first
+var registryManifestTypes = strings.Join([]string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}, ", ")
+
+var (
+	// internalHostRegex matches hostnames under suffixes only private networks resolve
+	internalHostRegex = regexp.MustCompile(`(?i)\b(?:[a-z0-9-]+\.)+(?:internal|local|lan|corp|intranet|localdomain|home\.arpa)\b`)
+	// privateIPRegex matches RFC 1918 addresses
+	privateIPRegex = regexp.MustCompile(`\b(?:10\.\d{1,3}|172\.(?:1[6-9]|2\d|3[01])|192\.168)\.\d{1,3}\.\d{1,3}\b`)
+	// urlCredentialRegex matches a user and password written into a URL
+	urlCredentialRegex = regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^\s:/@"']+:[^\s@/"']+@[^\s/"']+`)
+	// credentialEnvRegex matches environment variable names holding credentials
+	credentialEnvRegex = regexp.MustCompile(`(?i)pass(?:wd|word)?\b|pass(?:wd|word)?_|secret|token|api_?key|access_?key|private_?key|credential|auth_?key`)
+)
+
+// ImageFinding is an address, internal host or credential in an image's metadata
+type ImageFinding struct {
+	Kind   string `json:"kind"` // email, internal host or credential
+	Value  string `json:"value"`
+	Source string `json:"source"` // Where in the metadata, e.g. label org.opencontainers.image.authors
+}
+
+// ContainerImage is a public image published under the target's name, with
+// the metadata read from its configuration. Values of credential variables
+// are masked.
+type ContainerImage struct {
+	Registry string            `json:"registry"`
+	Name     string            `json:"name"`
+	Tag      string            `json:"tag,omitempty"`
+	URL      string            `json:"url"`
+	Updated  string            `json:"updated,omitempty"`
+	Pulls    int64             `json:"pulls,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Env      []string          `json:"env,omitempty"`
+	Findings []ImageFinding    `json:"findings,omitempty"`
+}
+
+// ContainerScanResult lists the images a user or organization publishes on
+// Docker Hub and GHCR and what their metadata gives away
+type ContainerScanResult struct {
+	Target        string           `json:"target"`
+	ScanID        string           `json:"scan_id,omitempty"`
+	Timestamp     string           `json:"timestamp"`
+	Images        []ContainerImage `json:"images"`
+	PartialErrors []ModuleError    `json:"partial_errors,omitempty"`
+	ExecutionTime string           `json:"execution_time"`
+}
+
+// registryManifest is an image manifest or, with Manifests set, an index of
+// manifests for several platforms
+type registryManifest struct {
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+	Manifests []struct {
+		Digest   string `json:"digest"`
+		Platform struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+// imageConfig is the part of an image configuration blob that is read
+type imageConfig struct {
+	Author string `json:"author"`
+	Config struct {
+		Env        []string          `json:"Env"`
+		Labels     map[string]string `json:"Labels"`
+		Cmd        []string          `json:"Cmd"`
+		Entrypoint []string          `json:"Entrypoint"`
+	} `json:"config"`
+	History []struct {
+		CreatedBy string `json:"created_by"`
+	} `json:"history"`
+}
+
+// registry is where an image's manifests and configuration are read from
+type registry struct {
+	host     string // Registry API host
+	tokenURL string // Anonymous pull token endpoint, given the scope
+}
+
+var (
+	dockerHubRegistry = registry{host: "registry-1.docker.io", tokenURL: "https://auth.docker.io/token?service=registry.docker.io&scope="}
+	ghcrRegistry      = registry{host: "ghcr.io", tokenURL: "https://ghcr.io/token?service=ghcr.io&scope="}
+)
+
+// FindContainerImages lists the public images of a user or organization on
+// Docker Hub and GHCR and reads the labels, environment defaults and build
+// steps of each one's newest tag for email addresses, internal hostnames and
+// credentials. GHCR cannot be listed without a token, so images named after
+// the owner's GitHub repositories are tried there.
+func FindContainerImages(ctx context.Context, target string) (*ContainerScanResult, error) {
+	startTime := time.Now()
+	owner := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(target), "@"))
+	if !githubOwnerRegex.MatchString(owner) {
+		return nil, fmt.Errorf("%q is not a registry user or organization name", target)
+	}
+
+	result := &ContainerScanResult{
+		Target:    owner,
+		ScanID:    providers.ScanIDFrom(ctx),
+		Timestamp: startTime.Format(time.RFC3339),
+		Images:    []ContainerImage{},
+	}
+	var mu sync.Mutex
+	addError := func(module string, err error) {
+		mu.Lock()
+		result.PartialErrors = append(result.PartialErrors, ModuleError{Module: module, Error: err.Error()})
+		mu.Unlock()
+	}
+
+	var images []ContainerImage
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		found, err := dockerHubImages(ctx, owner)
+		if err != nil {
+			addError("containers.dockerhub", err)
+		}
+		mu.Lock()
+		images = append(images, found...)
+		mu.Unlock()
+	}()
+	go func() {
+		defer wg.Done()
+		found, err := ghcrImages(ctx, owner)
+		if err != nil {
+			addError("containers.ghcr", err)
+		}
+		mu.Lock()
+		images = append(images, found...)
+		mu.Unlock()
+	}()
+	wg.Wait()
+
+	sem := make(chan struct{}, ConcurrentRequests)
+	for i := range images {
+		wg.Add(1)
+		go func(image *ContainerImage) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			if err := inspectImage(ctx, image); err != nil {
+				addError("containers."+image.Registry+"/"+image.Name, err)
+			}
+		}(&images[i])
+	}
+	wg.Wait()
+
+	// Images giving something away come first
+	sort.SliceStable(images, func(i, j int) bool { return len(images[i].Findings) > len(images[j].Findings) })
+	result.Images = append(result.Images, images...)
+	result.ExecutionTime = time.Since(startTime).String()
+	return result, ctx.Err()
+}
+
+// dockerHubImages lists the namespace's most recently updated repositories
+// on Docker Hub
+func dockerHubImages(ctx context.Context, namespace string) ([]ContainerImage, error) {
+	var page struct {
+		Results []struct {
+			Name        string `json:"name"`
+			LastUpdated string `json:"last_updated"`
+			PullCount   int64  `json:"pull_count"`
+		} `json:"results"`
+	}
+	err := getProviderJSON(ctx, fmt.Sprintf("https://hub.docker.com/v2/repositories/%s/?page_size=100", url.PathEscape(namespace)), nil, &page)
+	if providers.IsStatus(err, http.StatusNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(page.Results, func(i, j int) bool { return page.Results[i].LastUpdated > page.Results[j].LastUpdated })
+	var images []ContainerImage
+	for _, repo := range page.Results {
+		if len(images) == maxContainerImages {
+			break
+		}
+		images = append(images, ContainerImage{
+			Registry: RegistryDockerHub,
+			Name:     namespace + "/" + repo.Name,
+			URL:      fmt.Sprintf("https://hub.docker.com/r/%s/%s", namespace, repo.Name),
+			Updated:  repo.LastUpdated,
+			Pulls:    repo.PullCount,
+		})
+	}
+	return images, nil
+}
+
+// ghcrImages finds the owner's public images on GHCR. Packages are listed
+// with a GitHub token; without one, an image is looked for under the owner's
+// name and the name of each of their recently pushed repositories.
+func ghcrImages(ctx context.Context, owner string) ([]ContainerImage, error) {
+	var names []string
+	if apiKeyConfigured(APIConfig.GitHubToken) {
+		var packages []struct {
+			Name       string `json:"name"`
+			Visibility string `json:"visibility"`
+		}
+		err := getProviderJSON(ctx, "https://api.github.com/users/"+url.PathEscape(owner)+"/packages?package_type=container&per_page=100", githubHeaders(), &packages)
+		for _, pkg := range packages {
+			if pkg.Visibility != "private" {
+				names = append(names, strings.ToLower(pkg.Name))
+			}
+		}
+		// Tokens without the read:packages scope fall back to guessing
+		if err == nil && len(names) > 0 {
+			return probeGHCRImages(ctx, owner, names)
+		}
+	}
+
+	names = []string{owner}
+	var repos []githubRepo
+	err := getProviderJSON(ctx, fmt.Sprintf("https://api.github.com/users/%s/repos?sort=pushed&per_page=%d", url.PathEscape(owner), maxContainerImages*2), githubHeaders(), &repos)
+	if err != nil && !providers.IsStatus(err, http.StatusNotFound) {
+		images, _ := probeGHCRImages(ctx, owner, names)
+		return images, fmt.Errorf("listing GitHub repositories for image names: %v", err)
+	}
+	for _, repo := range repos {
+		if !repo.Fork {
+			names = append(names, strings.ToLower(repo.Name))
+		}
+	}
+	return probeGHCRImages(ctx, owner, names)
+}
+
+// probeGHCRImages keeps the names that are public images on GHCR, with
+// their newest tag
+func probeGHCRImages(ctx context.Context, owner string, names []string) ([]ContainerImage, error) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, ConcurrentRequests)
+		images []ContainerImage
+		errs   []string
+	)
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			repo := owner + "/" + name
+			token, err := ghcrRegistry.token(ctx, repo)
+			var tags struct {
+				Tags []string `json:"tags"`
+			}
+			if err == nil {
+				err = getProviderJSON(ctx, "https://ghcr.io/v2/"+repo+"/tags/list", bearer(token), &tags)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			// Images that do not exist or are private refuse the token or the listing
+			case providers.IsStatus(err, http.StatusNotFound), providers.IsStatus(err, http.StatusUnauthorized), providers.IsStatus(err, http.StatusForbidden):
+			case err != nil:
+				errs = append(errs, fmt.Sprintf("%s: %v", repo, err))
+			case len(tags.Tags) > 0 && len(images) < maxContainerImages:
+				images = append(images, ContainerImage{
+					Registry: RegistryGHCR,
+					Name:     repo,
+					Tag:      newestTag(tags.Tags),
+					URL:      "https://ghcr.io/" + repo,
+				})
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	sort.Slice(images, func(i, j int) bool { return images[i].Name < images[j].Name })
+	if len(errs) > 0 {
+		return images, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return images, nil
+}
+
+// newestTag picks latest when an image has it, otherwise the last tag listed
+func newestTag(tags []string) string {
+	for _, tag := range tags {
+		if tag == "latest" {
+			return tag
+		}
+	}
+	return tags[len(tags)-1]
+}
+
+// bearer returns the headers authorizing a registry request with a token
+func bearer(token string) map[string]string {
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+// token gets an anonymous token for pulling a repository
+func (r registry) token(ctx context.Context, repo string) (string, error) {
+	var answer struct {
+		Token string `json:"token"`
+	}
+	if err := getProviderJSON(ctx, r.tokenURL+url.QueryEscape("repository:"+repo+":pull"), nil, &answer); err != nil {
+		return "", err
+	}
+	return answer.Token, nil
+}
+
+// inspectImage reads the configuration of an image's newest tag, keeping its
+// labels and environment and looking through them for findings
+func inspectImage(ctx context.Context, image *ContainerImage) error {
+	reg := ghcrRegistry
+	if image.Registry == RegistryDockerHub {
+		reg = dockerHubRegistry
+		if image.Tag == "" {
+			tag, err := dockerHubTag(ctx, image.Name)
+			if err != nil || tag == "" {
+				return err
+			}
+			image.Tag = tag
+		}
+	}
+
+	token, err := reg.token(ctx, image.Name)
+	if err != nil {
+		return fmt.Errorf("pull token: %v", err)
+	}
+	base := "https://" + reg.host + "/v2/" + image.Name
+	headers := bearer(token)
+	headers["Accept"] = registryManifestTypes
+
+	var manifest registryManifest
+	if err := getProviderJSON(ctx, base+"/manifests/"+url.PathEscape(image.Tag), headers, &manifest); err != nil {
+		return fmt.Errorf("manifest of %s: %v", image.Tag, err)
+	}
+	// An index points to one manifest per platform; any will do, preferably
+	// linux/amd64, but not attestations, which have an unknown platform
+	if len(manifest.Manifests) > 0 {
+		digest := ""
+		for _, m := range manifest.Manifests {
+			if m.Platform.OS == "unknown" {
+				continue
+			}
+			if digest == "" || (m.Platform.OS == "linux" && m.Platform.Architecture == "amd64") {
+				digest = m.Digest
+			}
+		}
+		if digest == "" {
+			return fmt.Errorf("no image for any platform under %s", image.Tag)
+		}
+		manifest = registryManifest{}
+		if err := getProviderJSON(ctx, base+"/manifests/"+digest, headers, &manifest); err != nil {
+			return fmt.Errorf("manifest %s: %v", digest, err)
+		}
+	}
+	if manifest.Config.Digest == "" {
+		return fmt.Errorf("manifest of %s names no configuration", image.Tag)
+	}
+
+	var config imageConfig
+	if err := getProviderJSON(ctx, base+"/blobs/"+manifest.Config.Digest, bearer(token), &config); err != nil {
+		return fmt.Errorf("configuration: %v", err)
+	}
+	image.Env, image.Findings = inspectImageConfig(&config)
+	image.Labels = config.Config.Labels
+	return nil
+}
+
+// dockerHubTag returns latest when a Docker Hub repository has it, otherwise
+// its most recently pushed tag
+func dockerHubTag(ctx context.Context, name string) (string, error) {
+	var page struct {
+		Results []struct {
+			Name string `json:"name"`
+		} `json:"results"`
+	}
+	if err := getProviderJSON(ctx, "https://hub.docker.com/v2/repositories/"+name+"/tags?page_size=25&ordering=last_updated", nil, &page); err != nil {
+		return "", fmt.Errorf("tags: %v", err)
+	}
+	tags := make([]string, 0, len(page.Results))
+	for i := len(page.Results) - 1; i >= 0; i-- {
+		tags = append(tags, page.Results[i].Name)
+	}
+	if len(tags) == 0 {
+		return "", nil
+	}
+	return newestTag(tags), nil
+}
+
+// inspectImageConfig looks through an image's labels, environment, command
+// and build steps for email addresses, internal hosts and credentials. The
+// configuration keeps its labels, and the environment returned its values,
+// with credentials masked.
+func inspectImageConfig(config *imageConfig) ([]string, []ImageFinding) {
+	var findings []ImageFinding
+	seen := make(map[string]bool)
+	add := func(kind, value, source string) {
+		key := kind + "\x00" + strings.ToLower(value)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		findings = append(findings, ImageFinding{Kind: kind, Value: value, Source: source})
+	}
+	// URL passwords are masked once reported, so they are neither kept nor
+	// read as the user part of an email address
+	maskURLs := func(text, source string) string {
+		for _, match := range urlCredentialRegex.FindAllString(text, maxFileSecrets) {
+			if !strings.Contains(match, "$") && !placeholderSecretRegex.MatchString(match) {
+				add("credential", "URL with password: "+maskURLPassword(match), source)
+			}
+		}
+		return urlCredentialRegex.ReplaceAllStringFunc(text, maskURLPassword)
+	}
+
+	type text struct{ source, value string }
+	var texts []text
+	var env []string
+	for _, variable := range config.Config.Env {
+		name, value, _ := strings.Cut(variable, "=")
+		// References to other variables or secret files hold no credential
+		if credentialEnvRegex.MatchString(name) && value != "" && !strings.HasPrefix(value, "$") && !strings.HasPrefix(value, "/") {
+			if !placeholderSecretRegex.MatchString(value) {
+				add("credential", name+"="+maskSecret(value), "env "+name)
+			}
+			value = maskSecret(value)
+		}
+		variable = name + "=" + maskURLs(value, "env "+name)
+		env = append(env, variable)
+		texts = append(texts, text{"env " + name, variable})
+	}
+	for key, value := range config.Config.Labels {
+		config.Config.Labels[key] = maskURLs(value, "label "+key)
+		texts = append(texts, text{"label " + key, config.Config.Labels[key]})
+	}
+	if config.Author != "" {
+		texts = append(texts, text{"author", maskURLs(config.Author, "author")})
+	}
+	if command := strings.Join(append(config.Config.Entrypoint, config.Config.Cmd...), " "); command != "" {
+		texts = append(texts, text{"command", maskURLs(command, "command")})
+	}
+	for i, step := range config.History {
+		source := fmt.Sprintf("build step %d", i+1)
+		texts = append(texts, text{source, maskURLs(step.CreatedBy, source)})
+	}
+
+	for _, t := range texts {
+		for _, email := range extractEmails(t.value) {
+			if !ignoredEmail(email) {
+				add("email", email, t.source)
+			}
+		}
+		for _, host := range internalHostRegex.FindAllString(t.value, -1) {
+			add("internal host", strings.ToLower(host), t.source)
+		}
+		for _, ip := range privateIPRegex.FindAllString(t.value, -1) {
+			add("internal host", ip, t.source)
+		}
+		for _, pattern := range secretPatterns {
+			for _, match := range pattern.regex.FindAllString(t.value, maxFileSecrets) {
+				add("credential", pattern.kind+": "+maskSecret(match), t.source)
+			}
+		}
+	}
+	return env, findings
+}
+
+// maskURLPassword masks the password of a user:password@host URL
+func maskURLPassword(raw string) string {
+	scheme, rest, _ := strings.Cut(raw, "://")
+	userinfo, host, _ := strings.Cut(rest, "@")
+	user, password, _ := strings.Cut(userinfo, ":")
+	return scheme + "://" + user + ":" + maskSecret(password) + "@" + host
+}
+
+// DisplayResults prints the images found and what their metadata gives away
+func (r *ContainerScanResult) DisplayResults() {
+	color.Cyan("\n=== CONTAINER IMAGES: %s ===", r.Target)
+	if len(r.Images) == 0 {
+		color.Yellow("No public images found on Docker Hub or GHCR")
+		return
+	}
+	color.Yellow("Public images: %d", len(r.Images))
+
+	for _, image := range r.Images {
+		header := fmt.Sprintf("\n• %s/%s", image.Registry, image.Name)
+		if image.Tag != "" {
+			header += ":" + image.Tag
+		}
+		if len(image.Findings) > 0 {
+			color.Red("%s", header)
+		} else {
+			color.Green("%s", header)
+		}
+		fmt.Printf("  %s\n", image.URL)
+		if image.Updated != "" {
+			fmt.Printf("  Updated: %s, %d pulls\n", image.Updated, image.Pulls)
+		}
+		for _, finding := range image.Findings {
+			line := fmt.Sprintf("  - [%s] %s (%s)", finding.Kind, finding.Value, finding.Source)
+			if finding.Kind == "credential" {
+				color.Red("%s", line)
+			} else {
+				color.Yellow("%s", line)
+			}
+		}
+		if len(image.Labels) > 0 {
+			keys := make([]string, 0, len(image.Labels))
+			for key := range image.Labels {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			fmt.Println("  Labels:")
+			for _, key := range keys {
+				fmt.Printf("    %s=%s\n", key, image.Labels[key])
+			}
+		}
+	}
+	fmt.Printf("\nExecution time: %s\n", r.ExecutionTime)
+}
+
+// DisplayPartialErrors prints the registries and images that could not be read
+func (r *ContainerScanResult) DisplayPartialErrors() {
+	displayModuleErrors(r.PartialErrors)
+}
@@ -0,0 +1,200 @@
+package osint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CortexInput is the job description Cortex hands an analyzer
+type CortexInput struct {
+	DataType string                 `json:"dataType"`
+	Data     string                 `json:"data"`
+	TLP      int                    `json:"tlp"`
+	PAP      int                    `json:"pap"`
+	Config   map[string]interface{} `json:"config,omitempty"`
+}
+
+// CortexReport is the analyzer output Cortex and TheHive render: a short
+// taxonomy summary, extracted artifacts and the full module result
+type CortexReport struct {
+	Success      bool          `json:"success"`
+	ErrorMessage string        `json:"errorMessage,omitempty"`
+	Summary      CortexSummary `json:"summary"`
+	Artifacts    []Observable  `json:"artifacts"`
+	Full         interface{}   `json:"full,omitempty"`
+}
+
+// CortexSummary holds the taxonomies shown as tags on TheHive observables
+type CortexSummary struct {
+	Taxonomies []CortexTaxonomy `json:"taxonomies"`
+}
+
+// CortexTaxonomy is one short-report tag. Level is info, safe, suspicious or malicious.
+type CortexTaxonomy struct {
+	Level     string `json:"level"`
+	Namespace string `json:"namespace"`
+	Predicate string `json:"predicate"`
+	Value     string `json:"value"`
+}
+
+// cortexNamespace prefixes every taxonomy the analyzer emits
+const cortexNamespace = "MercuriesOST"
+
+// RunCortexAnalyzer analyzes the observable described by a Cortex job with the
+// module matching its data type. Failures are reported in the report rather
+// than returned, as Cortex expects.
+func RunCortexAnalyzer(ctx context.Context, input io.Reader) *CortexReport {
+	var job CortexInput
+	if err := json.NewDecoder(input).Decode(&job); err != nil {
+		return cortexError(fmt.Errorf("invalid job input: %v", err))
+	}
+	if job.Data == "" {
+		return cortexError(fmt.Errorf("job has no data"))
+	}
+
+	var (
+		full      interface{}
+		alerts    []Alert
+		artifacts []Observable
+		summary   []CortexTaxonomy
+	)
+
+	switch job.DataType {
+	case "mail":
+		result, err := AnalyzeEmail(job.Data)
+		if err != nil {
+			return cortexError(err)
+		}
+		full, alerts, artifacts = result, result.Alerts(), result.Observables()
+		summary = append(summary, CortexTaxonomy{
+			Level:     levelIf(result.SecurityInfo.BreachCount > 0, "suspicious", "safe"),
+			Predicate: "Breaches",
+			Value:     fmt.Sprint(result.SecurityInfo.BreachCount),
+		})
+
+	case "domain", "fqdn":
+		result, err := AnalyzeDomain(ctx, strings.ToLower(job.Data))
+		if err != nil {
+			return cortexError(err)
+		}
+		full, alerts, artifacts = result, result.Alerts(), result.Observables()
+		if vt := result.VirusTotal; vt != nil {
+			summary = append(summary, CortexTaxonomy{
+				Level:     levelIf(vt.Malicious > 0, "malicious", "safe"),
+				Predicate: "VirusTotal",
+				Value:     fmt.Sprintf("%d/%d", vt.Malicious, vt.Engines),
+			})
+		}
+
+	case "ip":
+		result, err := AnalyzeIP(ctx, job.Data)
+		if err != nil {
+			return cortexError(err)
+		}
+		full, alerts, artifacts = result, result.Alerts(), result.Observables()
+		for _, verdict := range result.Reputation {
+			level := "info"
+			switch {
+			case verdict.Malicious:
+				level = "malicious"
+			case verdict.Classification == "suspicious":
+				level = "suspicious"
+			case verdict.Classification == "benign service":
+				level = "safe"
+			}
+			summary = append(summary, CortexTaxonomy{Level: level, Predicate: verdict.Provider, Value: verdict.Classification})
+		}
+
+	case "url":
+		result, err := TriageURL(ctx, job.Data)
+		if err != nil {
+			return cortexError(err)
+		}
+		full, alerts, artifacts = result, result.Alerts(), result.Observables()
+		summary = append(summary, CortexTaxonomy{
+			Level:     levelIf(result.FinalURL != result.URL, "info", "safe"),
+			Predicate: "Redirects",
+			Value:     fmt.Sprint(len(result.Redirects)),
+		})
+
+	case "other", "phone":
+		result, err := AnalyzePhoneNumber(ctx, job.Data)
+		if err != nil {
+			return cortexError(err)
+		}
+		full, alerts, artifacts = result, result.Alerts(), result.Observables()
+		summary = append(summary, CortexTaxonomy{Level: "info", Predicate: "Risk", Value: result.RiskAssessment.Level})
+
+	default:
+		return cortexError(fmt.Errorf("unsupported data type %q", job.DataType))
+	}
+
+	// The overall verdict follows the most severe alert
+	maxSeverity := 0
+	for _, alert := range alerts {
+		maxSeverity = max(maxSeverity, alert.Severity)
+	}
+	level := "info"
+	switch {
+	case maxSeverity >= 7:
+		level = "malicious"
+	case maxSeverity >= 4:
+		level = "suspicious"
+	}
+	summary = append([]CortexTaxonomy{{Level: level, Predicate: "Alerts", Value: fmt.Sprint(len(alerts))}}, summary...)
+	for i := range summary {
+		summary[i].Namespace = cortexNamespace
+	}
+
+	// The analyzed observable already exists in TheHive
+	var extracted []Observable
+	for _, artifact := range artifacts {
+		if !strings.EqualFold(artifact.Data, job.Data) {
+			extracted = append(extracted, artifact)
+		}
+	}
+
+	return &CortexReport{
+		Success:   true,
+		Summary:   CortexSummary{Taxonomies: summary},
+		Artifacts: extracted,
+		Full:      full,
+	}
+}
+
+// RunCortexJob runs the analyzer in Cortex's job directory mode, reading
+// input/input.json and writing output/output.json under dir
+func RunCortexJob(ctx context.Context, dir string) error {
+	input, err := os.Open(filepath.Join(dir, "input", "input.json"))
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	report := RunCortexAnalyzer(ctx, input)
+
+	if err := os.MkdirAll(filepath.Join(dir, "output"), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "output", "output.json"), data, 0644)
+}
+
+func cortexError(err error) *CortexReport {
+	return &CortexReport{Success: false, ErrorMessage: err.Error()}
+}
+
+func levelIf(condition bool, yes, no string) string {
+	if condition {
+		return yes
+	}
+	return no
+}
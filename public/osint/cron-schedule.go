@@ -0,0 +1,153 @@
+package osint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronShortcuts expand the named schedules cron accepts
+var cronShortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronHorizon bounds how far ahead Next looks before deciding a schedule
+// never fires, such as one for 30 February
+const cronHorizon = 5 * 366 * 24 * time.Hour
+
+// CronSchedule is a five-field cron expression: minute, hour, day of month,
+// month and day of week, each a set of allowed values
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// Cron matches either day field when both are restricted
+	domAny, dowAny bool
+}
+
+// ParseCron parses a cron expression or one of the @hourly, @daily, @weekly,
+// @monthly and @yearly shortcuts. Fields take *, numbers, a-b ranges, lists
+// and /n steps; day of week runs from 0 (Sunday) to 7 (Sunday again).
+func ParseCron(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if shortcut, ok := cronShortcuts[strings.ToLower(expr)]; ok {
+		expr = shortcut
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q needs 5 fields (minute hour day month weekday), got %d", expr, len(fields))
+	}
+
+	var s CronSchedule
+	var err error
+	bounds := []struct {
+		set    *uint64
+		lo, hi int
+		name   string
+	}{
+		{&s.minute, 0, 59, "minute"},
+		{&s.hour, 0, 23, "hour"},
+		{&s.dom, 1, 31, "day of month"},
+		{&s.month, 1, 12, "month"},
+		{&s.dow, 0, 7, "day of week"},
+	}
+	for i, field := range fields {
+		b := bounds[i]
+		if *b.set, err = parseCronField(field, b.lo, b.hi); err != nil {
+			return nil, fmt.Errorf("cron %s %q: %v", b.name, field, err)
+		}
+	}
+	// 7 is another name for Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny, s.dowAny = strings.HasPrefix(fields[2], "*"), strings.HasPrefix(fields[4], "*")
+
+	if s.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("cron expression %q never fires", expr)
+	}
+	return &s, nil
+}
+
+// parseCronField returns the values a field allows as a bit set
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if base, s, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", s)
+			}
+			part, step = base, n
+		}
+
+		start, end := lo, hi
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			a, b, _ := strings.Cut(part, "-")
+			var errA, errB error
+			start, errA = strconv.Atoi(a)
+			end, errB = strconv.Atoi(b)
+			if errA != nil || errB != nil || start > end {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			start, end = n, n
+			// A single value with a step runs to the end of the range, as in cron
+			if step > 1 {
+				end = hi
+			}
+		}
+		if start < lo || end > hi {
+			return 0, fmt.Errorf("%d-%d is outside %d-%d", start, end, lo, hi)
+		}
+		for v := start; v <= end; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// Next returns the first time after t the schedule fires, in t's location,
+// or the zero time when it never does
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	limit := t.Add(cronHorizon)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches applies cron's rule that a day matches either day field when
+// both are restricted, and the restricted one otherwise
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,254 @@
+package osint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/input"
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// ModulesDir holds the user's custom module definitions, one YAML file each
+var ModulesDir = defaultModulesDir()
+
+// maxCustomMatches caps the artifacts one extract rule emits
+const maxCustomMatches = 50
+
+// customTargetKinds are the target kinds a custom module may take
+var customTargetKinds = []string{input.KindUsername, input.KindEmail, input.KindDomain, input.KindIP, input.KindPhone, input.KindName}
+
+// CustomModule is a check defined in a YAML file instead of Go: fetch a URL
+// built from the target, decide whether the target was found and pull
+// artifacts out of the page with regular expressions
+type CustomModule struct {
+	Name        string                 `json:"-"` // The file name without .yaml
+	Description string                 `json:"description"`
+	Target      string                 `json:"target"` // Target kind, e.g. username or email
+	URL         string                 `json:"url"`    // With {target} where the target goes
+	Found       string                 `json:"found"`  // Regex the page must match; any 200 answer when empty
+	Extract     map[string]ExtractRule `json:"extract"`
+
+	found *regexp.Regexp
+}
+
+// ExtractRule pulls one kind of artifact out of a page
+type ExtractRule struct {
+	Pattern string `json:"pattern"` // The first capture group is the artifact, or the whole match without one
+	Type    string `json:"type"`    // e.g. username, email or url; the rule's name when empty
+
+	pattern *regexp.Regexp
+}
+
+// CustomArtifact is a value a custom module extracted
+type CustomArtifact struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// CustomModuleResults is the outcome of running a custom module on a target
+type CustomModuleResults struct {
+	ScanID     string           `json:"scan_id,omitempty"`
+	Module     string           `json:"module"`
+	Target     string           `json:"target"`
+	URL        string           `json:"url"`
+	StatusCode int              `json:"status_code"`
+	Found      bool             `json:"found"`
+	Artifacts  []CustomArtifact `json:"artifacts,omitempty"`
+	Truncated  bool             `json:"truncated,omitempty"`
+	Timestamp  string           `json:"timestamp"`
+}
+
+// defaultModulesDir returns ~/.mercuries/modules
+func defaultModulesDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".mercuries", "modules")
+}
+
+// ParseCustomModule reads and checks a module definition
+func ParseCustomModule(name string, data []byte) (*CustomModule, error) {
+	tree, err := parseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	module := &CustomModule{Name: name}
+	encoded, err := json.Marshal(typeYAML(tree, reflect.TypeOf(module)))
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(module); err != nil {
+		return nil, fmt.Errorf("invalid module: %v", strings.TrimPrefix(err.Error(), "json: "))
+	}
+
+	if !strings.Contains(module.URL, "{target}") {
+		return nil, fmt.Errorf("url must contain {target}")
+	}
+	if parsed, err := url.Parse(strings.ReplaceAll(module.URL, "{target}", "x")); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("url %q is not an http or https URL", module.URL)
+	}
+	if module.Target == "" {
+		module.Target = input.KindUsername
+	}
+	known := false
+	for _, kind := range customTargetKinds {
+		known = known || module.Target == kind
+	}
+	if !known {
+		return nil, fmt.Errorf("target must be one of %s", strings.Join(customTargetKinds, ", "))
+	}
+	if module.Found != "" {
+		if module.found, err = regexp.Compile(module.Found); err != nil {
+			return nil, fmt.Errorf("found: %v", err)
+		}
+	}
+	for ruleName, rule := range module.Extract {
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("extract %s: pattern is empty", ruleName)
+		}
+		if rule.pattern, err = regexp.Compile(rule.Pattern); err != nil {
+			return nil, fmt.Errorf("extract %s: %v", ruleName, err)
+		}
+		if rule.Type == "" {
+			rule.Type = ruleName
+		}
+		module.Extract[ruleName] = rule
+	}
+	return module, nil
+}
+
+// LoadCustomModule reads the module named name from ModulesDir
+func LoadCustomModule(name string) (*CustomModule, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid module name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(ModulesDir, name+".yaml"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no module %q in %s", name, ModulesDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	module, err := ParseCustomModule(name, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s.yaml: %v", name, err)
+	}
+	return module, nil
+}
+
+// LoadCustomModules reads every module in ModulesDir, sorted by name. Broken
+// definitions are returned as errors beside the modules that loaded.
+func LoadCustomModules() ([]*CustomModule, []error) {
+	paths, _ := filepath.Glob(filepath.Join(ModulesDir, "*.yaml"))
+	sort.Strings(paths)
+	var modules []*CustomModule
+	var errs []error
+	for _, path := range paths {
+		module, err := LoadCustomModule(strings.TrimSuffix(filepath.Base(path), ".yaml"))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		modules = append(modules, module)
+	}
+	return modules, errs
+}
+
+// Run fetches the module's URL for a target and extracts its artifacts
+func (m *CustomModule) Run(ctx context.Context, target string) (*CustomModuleResults, error) {
+	target, err := input.Target(m.Target, target)
+	if err != nil {
+		return nil, err
+	}
+	results := &CustomModuleResults{
+		ScanID:    providers.ScanID,
+		Module:    m.Name,
+		Target:    target,
+		URL:       strings.ReplaceAll(m.URL, "{target}", url.PathEscape(target)),
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", results.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	resp, err := doProviderRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	results.StatusCode = resp.StatusCode
+	if resp.StatusCode != http.StatusOK {
+		return results, nil
+	}
+
+	page, err := readPageBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	results.Truncated = page.Truncated
+	text := page.String()
+	if m.found != nil && !m.found.MatchString(text) {
+		return results, nil
+	}
+	results.Found = true
+
+	names := make([]string, 0, len(m.Extract))
+	for name := range m.Extract {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rule := m.Extract[name]
+		seen := make(map[string]bool)
+		for _, match := range rule.pattern.FindAllStringSubmatch(text, maxCustomMatches) {
+			value := match[0]
+			if len(match) > 1 {
+				value = match[1]
+			}
+			value = strings.TrimSpace(html.UnescapeString(value))
+			if value == "" || seen[value] {
+				continue
+			}
+			seen[value] = true
+			results.Artifacts = append(results.Artifacts, CustomArtifact{Name: name, Type: rule.Type, Value: value})
+		}
+	}
+	return results, nil
+}
+
+// DisplayResults prints what a custom module found
+func (r *CustomModuleResults) DisplayResults() {
+	color.Green("\n=== CUSTOM MODULE: %s ===", r.Module)
+	color.Yellow("Target: %s", r.Target)
+	color.White("URL: %s (status %d)", r.URL, r.StatusCode)
+	if !r.Found {
+		color.Red("Not found")
+		return
+	}
+	color.Green("Found")
+	if r.Truncated {
+		color.Yellow("Page truncated at %d bytes, artifacts past that point were not extracted", MaxBodySize)
+	}
+	for _, artifact := range r.Artifacts {
+		color.Cyan("  %s (%s): %s", artifact.Name, artifact.Type, artifact.Value)
+	}
+}
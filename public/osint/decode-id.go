@@ -0,0 +1,235 @@
+package osint
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// ID formats understood by DecodeID
+const (
+	IDTwitter   = "twitter"   // Tweet and user snowflakes
+	IDDiscord   = "discord"   // User, guild, channel and message snowflakes
+	IDInstagram = "instagram" // Media IDs and post shortcodes
+	IDTikTok    = "tiktok"    // Video and newer account IDs
+	IDMastodon  = "mastodon"  // Status IDs
+	IDULID      = "ulid"
+	IDUUID      = "uuid" // Versions 1, 6 and 7
+	IDObjectID  = "objectid"
+	IDKSUID     = "ksuid"
+)
+
+// Snowflake epochs in Unix milliseconds
+const (
+	twitterEpoch   = 1288834974657
+	discordEpoch   = 1420070400000
+	instagramEpoch = 1314220021721
+)
+
+// idFormats lists every format in the order candidates are reported
+var idFormats = []string{IDTwitter, IDDiscord, IDInstagram, IDTikTok, IDMastodon, IDULID, IDUUID, IDObjectID, IDKSUID}
+
+var (
+	uuidRegex      = regexp.MustCompile(`^[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}$`)
+	ulidRegex      = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Za-hjkmnp-tv-z]{25}$`)
+	objectIDRegex  = regexp.MustCompile(`^[0-9a-fA-F]{24}$`)
+	ksuidRegex     = regexp.MustCompile(`^[0-9A-Za-z]{27}$`)
+	shortcodeRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{1,12}$`)
+	// earliestID bounds decoded times; none of these formats predate 2006
+	earliestID = time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// DecodedID is a creation time read out of an identifier
+type DecodedID struct {
+	ID      string    `json:"id"`
+	Format  string    `json:"format"`
+	Created time.Time `json:"created"`
+	Details string    `json:"details,omitempty"`
+}
+
+// DecodeID extracts the creation time embedded in an identifier. With an
+// empty format every format the ID could be is tried, and only times between
+// 2006 and now are kept, since a bare number is valid in several of them.
+func DecodeID(id, format string) ([]DecodedID, error) {
+	id = strings.TrimSpace(id)
+	formats := idFormats
+	if format != "" {
+		formats = []string{strings.ToLower(format)}
+	}
+
+	var decoded []DecodedID
+	var lastErr error
+	for _, f := range formats {
+		d, err := decodeIDAs(id, f)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if d.Created.Before(earliestID) || d.Created.After(time.Now().Add(24*time.Hour)) {
+			lastErr = fmt.Errorf("%s time %s is implausible", f, d.Created.Format(time.RFC3339))
+			continue
+		}
+		d.ID, d.Format = id, f
+		decoded = append(decoded, d)
+	}
+	if len(decoded) == 0 {
+		if format != "" && lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, fmt.Errorf("%q matches no known ID format", id)
+	}
+	return decoded, nil
+}
+
+func decodeIDAs(id, format string) (DecodedID, error) {
+	switch format {
+	case IDTwitter, IDDiscord, IDInstagram, IDTikTok, IDMastodon:
+		n, err := strconv.ParseUint(id, 10, 64)
+		if err != nil && format == IDInstagram && shortcodeRegex.MatchString(id) {
+			if n, err = instagramShortcodeID(id); err == nil {
+				return snowflake(n, format, fmt.Sprintf("shortcode for media %d", n)), nil
+			}
+		}
+		if err != nil {
+			return DecodedID{}, fmt.Errorf("%s IDs are numeric", format)
+		}
+		// Smaller numbers are sequential IDs from before snowflakes
+		if n < 1<<32 {
+			return DecodedID{}, fmt.Errorf("%d is too small to carry a %s timestamp", n, format)
+		}
+		return snowflake(n, format, ""), nil
+
+	case IDULID:
+		if !ulidRegex.MatchString(id) {
+			return DecodedID{}, fmt.Errorf("not a ULID")
+		}
+		var ms uint64
+		for _, c := range strings.ToUpper(id[:10]) {
+			ms = ms<<5 | uint64(strings.IndexRune("0123456789ABCDEFGHJKMNPQRSTVWXYZ", c))
+		}
+		return DecodedID{Created: time.UnixMilli(int64(ms)).UTC()}, nil
+
+	case IDUUID:
+		if !uuidRegex.MatchString(id) {
+			return DecodedID{}, fmt.Errorf("not a UUID")
+		}
+		b, _ := hex.DecodeString(strings.ReplaceAll(id, "-", ""))
+		switch version := b[6] >> 4; version {
+		case 1:
+			// 60-bit count of 100ns intervals since 1582-10-15, low field first
+			ticks := uint64(b[6]&0x0f)<<56 | uint64(b[7])<<48 | uint64(b[4])<<40 | uint64(b[5])<<32 |
+				uint64(b[0])<<24 | uint64(b[1])<<16 | uint64(b[2])<<8 | uint64(b[3])
+			return DecodedID{Created: uuidTime(ticks), Details: fmt.Sprintf("version 1, node %x", b[10:])}, nil
+		case 6:
+			ticks := uint64(b[0])<<52 | uint64(b[1])<<44 | uint64(b[2])<<36 | uint64(b[3])<<28 |
+				uint64(b[4])<<20 | uint64(b[5])<<12 | uint64(b[6]&0x0f)<<8 | uint64(b[7])
+			return DecodedID{Created: uuidTime(ticks), Details: fmt.Sprintf("version 6, node %x", b[10:])}, nil
+		case 7:
+			ms := uint64(b[0])<<40 | uint64(b[1])<<32 | uint64(b[2])<<24 | uint64(b[3])<<16 | uint64(b[4])<<8 | uint64(b[5])
+			return DecodedID{Created: time.UnixMilli(int64(ms)).UTC(), Details: "version 7"}, nil
+		default:
+			return DecodedID{}, fmt.Errorf("UUID version %d carries no timestamp", version)
+		}
+
+	case IDObjectID:
+		if !objectIDRegex.MatchString(id) {
+			return DecodedID{}, fmt.Errorf("not an ObjectId")
+		}
+		seconds, _ := strconv.ParseUint(id[:8], 16, 32)
+		return DecodedID{Created: time.Unix(int64(seconds), 0).UTC()}, nil
+
+	case IDKSUID:
+		if !ksuidRegex.MatchString(id) {
+			return DecodedID{}, fmt.Errorf("not a KSUID")
+		}
+		n := new(big.Int)
+		for _, c := range id {
+			n.Mul(n, big.NewInt(62))
+			n.Add(n, big.NewInt(int64(strings.IndexRune("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz", c))))
+		}
+		// 4-byte timestamp followed by a 16-byte payload
+		seconds := new(big.Int).Rsh(n, 128).Int64()
+		return DecodedID{Created: time.Unix(seconds+1400000000, 0).UTC()}, nil
+	}
+	return DecodedID{}, fmt.Errorf("unknown ID format %q", format)
+}
+
+// snowflake decodes the numeric ID formats, which all keep a timestamp in
+// their high bits
+func snowflake(n uint64, format, details string) DecodedID {
+	var created time.Time
+	var fields string
+	switch format {
+	case IDTwitter:
+		created = time.UnixMilli(int64(n>>22) + twitterEpoch)
+		fields = fmt.Sprintf("worker %d, sequence %d", n>>12&0x3ff, n&0xfff)
+	case IDDiscord:
+		created = time.UnixMilli(int64(n>>22) + discordEpoch)
+		fields = fmt.Sprintf("worker %d, process %d, increment %d", n>>17&0x1f, n>>12&0x1f, n&0xfff)
+	case IDInstagram:
+		created = time.UnixMilli(int64(n>>23) + instagramEpoch)
+		fields = fmt.Sprintf("shard %d", n>>10&0x1fff)
+	case IDTikTok:
+		created = time.Unix(int64(n>>32), 0)
+	case IDMastodon:
+		created = time.UnixMilli(int64(n >> 16))
+	}
+	if details != "" && fields != "" {
+		details += ", "
+	}
+	return DecodedID{Created: created.UTC(), Details: details + fields}
+}
+
+// uuidTime converts UUID v1/v6 ticks to a time
+func uuidTime(ticks uint64) time.Time {
+	const gregorianOffset = 122192928000000000 // 100ns intervals from 1582-10-15 to 1970-01-01
+	if ticks < gregorianOffset {
+		return time.Time{}
+	}
+	ticks -= gregorianOffset
+	return time.Unix(int64(ticks/1e7), int64(ticks%1e7)*100).UTC()
+}
+
+// instagramShortcodeID converts a post shortcode to its media ID
+func instagramShortcodeID(code string) (uint64, error) {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	var n uint64
+	for _, c := range code {
+		if n > (1<<64-1)>>6 {
+			return 0, fmt.Errorf("shortcode too long")
+		}
+		n = n<<6 | uint64(strings.IndexRune(alphabet, c))
+	}
+	return n, nil
+}
+
+// IDCreated returns the creation time decoded from a platform's account ID,
+// or "" when the platform's IDs embed none
+func IDCreated(format, id string) string {
+	if format == "" || id == "" {
+		return ""
+	}
+	decoded, err := DecodeID(id, format)
+	if err != nil {
+		return ""
+	}
+	return decoded[0].Created.Format(time.RFC3339)
+}
+
+// DisplayDecodedIDs prints decoded IDs, oldest first
+func DisplayDecodedIDs(decoded []DecodedID) {
+	sort.SliceStable(decoded, func(i, j int) bool { return decoded[i].Created.Before(decoded[j].Created) })
+	for _, d := range decoded {
+		color.Green("%-10s %s", d.Format, d.Created.Format("2006-01-02 15:04:05.000 MST"))
+		if d.Details != "" {
+			color.White("           %s", d.Details)
+		}
+	}
+}
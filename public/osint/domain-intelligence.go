@@ -0,0 +1,557 @@
+package osint
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// DomainIntelResult holds the results of a domain intelligence lookup
+type DomainIntelResult struct {
+	Domain          string                 `json:"domain"`
+	ScanID          string                 `json:"scan_id,omitempty"`
+	SearchTimestamp string                 `json:"search_timestamp"`
+	DNS             DomainInfo             `json:"dns"`
+	ExposedPaths    []PathProbe            `json:"exposed_paths,omitempty"`
+	Emails          []string               `json:"emails,omitempty"`
+	Artifacts       []Artifact             `json:"artifacts,omitempty"`
+	SourceFindings  []SourceFinding        `json:"source_findings,omitempty"`
+	Technologies    []Technology           `json:"technologies,omitempty"`
+	TrackingIDs     []TrackingID           `json:"tracking_ids,omitempty"`
+	RelatedDomains  []RelatedDomain        `json:"related_domains,omitempty"`
+	VirusTotal      *VTReport              `json:"virustotal,omitempty"`
+	Spoofing        []SpoofCheck           `json:"spoofing,omitempty"`
+	Takeovers       []TakeoverFinding      `json:"takeovers,omitempty"`
+	TakeoverChecked int                    `json:"takeover_hosts_checked,omitempty"`
+	Metadata        map[string]interface{} `json:"metadata"`
+	PartialErrors   []ModuleError          `json:"partial_errors,omitempty"`
+	ExecutionTime   string                 `json:"execution_time"`
+}
+
+// PathProbe describes a well-known path that responded on the target domain
+type PathProbe struct {
+	Path        string     `json:"path"`
+	URL         string     `json:"url"`
+	StatusCode  int        `json:"status_code"`
+	ContentType string     `json:"content_type,omitempty"`
+	Size        int        `json:"size"`
+	Emails      []string   `json:"emails,omitempty"`
+	Details     []string   `json:"details,omitempty"`
+	Artifacts   []Artifact `json:"artifacts,omitempty"`
+}
+
+// Domain module configuration
+var (
+	DomainPathProbing = false // Probe ProbePaths on the target domain
+	ProbePaths        = []string{
+		"/.well-known/security.txt",
+		"/security.txt",
+		"/humans.txt",
+		"/robots.txt",
+		"/sitemap.xml",
+		"/.well-known/change-password",
+		"/admin/",
+		"/administrator/",
+		"/wp-admin/",
+		"/wp-login.php",
+		"/phpmyadmin/",
+		"/login",
+		"/user/login",
+		"/cpanel",
+	}
+	maxProbeBodySize = int64(256 << 10)
+
+	// Files parsed for contacts on every domain lookup
+	contactFilePaths = []string{"/.well-known/security.txt", "/security.txt", "/humans.txt"}
+)
+
+var (
+	emailAddressRegex = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
+	sitemapLocRegex   = regexp.MustCompile(`(?i)<loc>\s*([^<\s]+)\s*</loc>`)
+)
+
+// LoadProbeWordlist replaces ProbePaths with the paths listed in a file, one per
+// line. Blank lines and lines starting with # are ignored.
+func LoadProbeWordlist(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var paths []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, "/") {
+			line = "/" + line
+		}
+		paths = append(paths, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("wordlist %s contains no paths", path)
+	}
+
+	ProbePaths = paths
+	return nil
+}
+
+// AnalyzeDomain gathers DNS, hosting and exposed metadata for a domain
+func AnalyzeDomain(ctx context.Context, domain string) (*DomainIntelResult, error) {
+	startTime := time.Now()
+
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimPrefix(strings.TrimPrefix(domain, "https://"), "http://")
+	domain = strings.TrimSuffix(strings.SplitN(domain, "/", 2)[0], ".")
+	if domain == "" || !strings.Contains(domain, ".") {
+		return nil, fmt.Errorf("invalid domain: %q", domain)
+	}
+
+	result := &DomainIntelResult{
+		ScanID:          providers.ScanIDFrom(ctx),
+		Domain:          domain,
+		SearchTimestamp: time.Now().Format(time.RFC3339),
+		Metadata:        make(map[string]interface{}),
+	}
+
+	graph := newTaskGraph(ConcurrentRequests)
+
+	graph.add("dns", 2*RequestTimeout, func(ctx context.Context) error {
+		info, err := getDomainInfo(ctx, domain)
+		result.DNS = info
+		return err
+	})
+
+	// Contact files are always read; the wider path list is opt-in
+	paths := contactFilePaths
+	if DomainPathProbing {
+		paths = mergeStrings(append([]string{}, contactFilePaths...), ProbePaths)
+	}
+	graph.add("paths", 3*RequestTimeout, func(ctx context.Context) error {
+		probes, err := probeDomainPaths(ctx, domain, paths)
+		result.ExposedPaths = probes
+		return err
+	})
+
+	// The homepage is fetched once and shared by the checks that read it
+	var page *homepage
+	graph.add("homepage", RequestTimeout, func(ctx context.Context) error {
+		var err error
+		page, err = fetchHomepage(ctx, domain)
+		return err
+	})
+
+	graph.add("technologies", RequestTimeout, func(ctx context.Context) error {
+		if page == nil {
+			return nil // Reported by the homepage task
+		}
+		result.Technologies = detectTechnologies(page)
+		result.TrackingIDs = extractTrackingIDs(page.body)
+		return nil
+	}, "homepage")
+
+	if DomainIDPivot {
+		graph.add("id_pivot", 3*RequestTimeout, func(ctx context.Context) error {
+			related, unsupported, err := pivotTrackingIDs(ctx, domain, result.TrackingIDs)
+			result.RelatedDomains = related
+			if len(unsupported) > 0 {
+				result.Metadata["unsupported_id_pivots"] = unsupported
+			}
+			return err
+		}, "technologies")
+	}
+
+	if DomainSourceScan {
+		graph.add("sources", 4*RequestTimeout, func(ctx context.Context) error {
+			if page == nil {
+				return nil
+			}
+			result.SourceFindings = scanDomainSources(ctx, domain, page)
+			return nil
+		}, "homepage")
+	}
+
+	if len(SpoofSenders) > 0 {
+		graph.add("spoofing", 2*RequestTimeout, func(ctx context.Context) error {
+			checks, err := CheckSpoofing(ctx, domain, SpoofSenders)
+			result.Spoofing = checks
+			return err
+		})
+	}
+
+	if DomainTakeoverCheck {
+		graph.add("takeover", 6*RequestTimeout, func(ctx context.Context) error {
+			findings, checked, err := FindTakeovers(ctx, domain)
+			result.Takeovers, result.TakeoverChecked = findings, checked
+			return err
+		})
+	}
+
+	if vtConfigured() {
+		graph.add("virustotal", 4*RequestTimeout, func(ctx context.Context) error {
+			report, err := LookupVirusTotal(ctx, VTDomain, domain)
+			result.VirusTotal = report
+			return err
+		})
+	}
+
+	errs, err := graph.run(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, name := range graph.order() {
+		if taskErr, ok := errs[name]; ok {
+			result.PartialErrors = append(result.PartialErrors, ModuleError{Module: name, Error: taskErr.Error()})
+		}
+	}
+
+	result.Emails = collectProbeEmails(result.ExposedPaths)
+	for _, probe := range result.ExposedPaths {
+		result.Artifacts = append(result.Artifacts, probe.Artifacts...)
+	}
+	for _, finding := range result.SourceFindings {
+		switch finding.Type {
+		case "email":
+			result.Emails = mergeStrings(result.Emails, []string{finding.Value})
+			result.Artifacts = append(result.Artifacts, Artifact{Type: ArtifactEmail, Value: finding.Value, Source: finding.Location, Context: "site source"})
+		case "social":
+			result.Artifacts = append(result.Artifacts, Artifact{Type: ArtifactURL, Value: finding.Value, Source: finding.Location, Context: finding.Kind + " link"})
+		}
+	}
+	result.Artifacts = dedupeArtifacts(result.Artifacts)
+	result.ExecutionTime = time.Since(startTime).String()
+	return result, nil
+}
+
+// probeDomainPaths requests each path over HTTPS and keeps the ones that exist.
+// A random path is requested first so that sites answering every request with
+// 200 do not produce a report full of false positives.
+func probeDomainPaths(ctx context.Context, domain string, paths []string) ([]PathProbe, error) {
+	client := &http.Client{
+		Timeout:   RequestTimeout,
+		Transport: providers.Transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	base := "https://" + domain
+
+	baseline, err := fetchProbe(ctx, client, base, fmt.Sprintf("/mercuries-%d", time.Now().UnixNano()))
+	if err != nil {
+		return nil, fmt.Errorf("site unreachable: %v", err)
+	}
+	softNotFound := baseline.StatusCode == http.StatusOK
+
+	var (
+		probes []PathProbe
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, scanWorkers())
+	)
+
+	for _, path := range paths {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			probe, err := fetchProbe(ctx, client, base, path)
+			if err != nil || !probeExists(probe, baseline, softNotFound) {
+				return
+			}
+
+			mu.Lock()
+			probes = append(probes, probe)
+			mu.Unlock()
+		}(path)
+	}
+	wg.Wait()
+
+	sort.Slice(probes, func(i, j int) bool { return probes[i].Path < probes[j].Path })
+	return probes, nil
+}
+
+// probeExists decides whether a probe response indicates a real resource
+func probeExists(probe, baseline PathProbe, softNotFound bool) bool {
+	switch probe.StatusCode {
+	case http.StatusOK:
+		// Identical to the catch-all response means nothing is really there
+		return !softNotFound || probe.Size != baseline.Size
+	case http.StatusUnauthorized, http.StatusForbidden:
+		// Protected admin panels are worth reporting
+		return baseline.StatusCode != probe.StatusCode
+	}
+	return false
+}
+
+// fetchProbe requests a single path and extracts metadata from the response
+func fetchProbe(ctx context.Context, client *http.Client, base, path string) (PathProbe, error) {
+	probe := PathProbe{Path: path, URL: base + path}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", probe.URL, nil)
+	if err != nil {
+		return probe, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return probe, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBodySize))
+	if err != nil {
+		return probe, err
+	}
+
+	probe.StatusCode = resp.StatusCode
+	probe.ContentType = resp.Header.Get("Content-Type")
+	probe.Size = len(body)
+
+	if resp.StatusCode == http.StatusOK {
+		body, _ = toUTF8(body, probe.ContentType)
+		content := string(body)
+		probe.Emails = extractEmails(content)
+
+		// Contact files get a dedicated parser so names and obfuscated addresses are kept
+		switch {
+		case strings.HasSuffix(path, "security.txt"):
+			probe.Artifacts, probe.Details = parseSecurityTxt(content, probe.URL)
+		case strings.HasSuffix(path, "humans.txt"):
+			probe.Artifacts, probe.Details = parseHumansTxt(content, probe.URL)
+		default:
+			probe.Details = describeProbeContent(path, content)
+		}
+		probe.Emails = mergeStrings(probe.Emails, artifactValues(probe.Artifacts, ArtifactEmail))
+	}
+
+	return probe, nil
+}
+
+// describeProbeContent summarises the interesting parts of well-known files
+func describeProbeContent(path, content string) []string {
+	var details []string
+
+	switch {
+	case strings.HasSuffix(path, "robots.txt"):
+		for _, line := range strings.Split(content, "\n") {
+			line = strings.TrimSpace(line)
+			lower := strings.ToLower(line)
+			if strings.HasPrefix(lower, "disallow:") || strings.HasPrefix(lower, "sitemap:") {
+				details = append(details, line)
+			}
+		}
+	case strings.HasSuffix(path, "sitemap.xml"):
+		locs := sitemapLocRegex.FindAllStringSubmatch(content, -1)
+		details = append(details, fmt.Sprintf("%d URLs listed", len(locs)))
+		for i, loc := range locs {
+			if i >= 5 {
+				break
+			}
+			details = append(details, loc[1])
+		}
+	default:
+		if title := pageTitleRegex.FindStringSubmatch(content); len(title) > 1 {
+			details = append(details, "Title: "+cleanText(title[1]))
+		}
+	}
+
+	// Keep the report readable
+	if len(details) > 15 {
+		details = append(details[:15], fmt.Sprintf("... %d more", len(details)-15))
+	}
+	return details
+}
+
+// extractEmails returns the unique email addresses found in content
+func extractEmails(content string) []string {
+	seen := make(map[string]bool)
+	var emails []string
+	for _, match := range emailAddressRegex.FindAllString(content, -1) {
+		email := strings.ToLower(strings.TrimRight(match, "."))
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+		emails = append(emails, email)
+	}
+	return emails
+}
+
+// mergeStrings appends the values from extra that are not already in base
+func mergeStrings(base, extra []string) []string {
+	seen := make(map[string]bool, len(base))
+	for _, value := range base {
+		seen[value] = true
+	}
+	for _, value := range extra {
+		if !seen[value] {
+			seen[value] = true
+			base = append(base, value)
+		}
+	}
+	return base
+}
+
+// collectProbeEmails merges the email addresses found across all probes
+func collectProbeEmails(probes []PathProbe) []string {
+	seen := make(map[string]bool)
+	var emails []string
+	for _, probe := range probes {
+		for _, email := range probe.Emails {
+			if !seen[email] {
+				seen[email] = true
+				emails = append(emails, email)
+			}
+		}
+	}
+	sort.Strings(emails)
+	return emails
+}
+
+// DisplayResults prints the domain intelligence results
+func (r *DomainIntelResult) DisplayResults() {
+	color.Cyan("\n=== DOMAIN ANALYSIS RESULTS ===")
+	color.Yellow("Domain: %s", r.Domain)
+	color.Yellow("Analysis Timestamp: %s\n", r.SearchTimestamp)
+
+	color.Cyan("\n[DNS]")
+	color.White("• DNS Health Score: %d/100", r.DNS.DNSHealthScore)
+	if len(r.DNS.IPAddresses) > 0 {
+		color.White("• IP Addresses: %s", strings.Join(r.DNS.IPAddresses, ", "))
+	}
+	for _, mx := range r.DNS.MXRecords {
+		color.White("• MX: %s (priority %d, %s)", mx.Host, mx.Priority, mx.Provider)
+	}
+	if r.DNS.SPFRecord != "" {
+		color.White("• SPF: %s", r.DNS.SPFRecord)
+	}
+	if r.DNS.DMARCRecord != "" {
+		color.White("• DMARC: %s", r.DNS.DMARCRecord)
+	}
+	DisplaySpoofChecks(r.Domain, r.Spoofing)
+	DisplayTakeovers(r.Takeovers, r.TakeoverChecked)
+
+	if len(r.DNS.HostIntel) > 0 {
+		color.Cyan("\n[Host Exposure]")
+		for _, host := range r.DNS.HostIntel {
+			color.White("• Host %s (%s): ports %v", host.IP, host.Provider, host.Ports)
+			if host.Org != "" {
+				color.White("  - Org: %s", host.Org)
+			}
+			if len(host.Vulns) > 0 {
+				color.Red("  - Vulnerabilities: %s", strings.Join(host.Vulns, ", "))
+			}
+		}
+	}
+
+	displayVTReport(r.VirusTotal)
+
+	if len(r.ExposedPaths) > 0 {
+		color.Cyan("\n[Exposed Paths]")
+		for _, probe := range r.ExposedPaths {
+			if probe.StatusCode == http.StatusOK {
+				color.Green("• %s (%d bytes)", probe.Path, probe.Size)
+			} else {
+				color.Yellow("• %s (protected, status %d)", probe.Path, probe.StatusCode)
+			}
+			for _, detail := range probe.Details {
+				color.White("  - %s", detail)
+			}
+		}
+	}
+
+	if len(r.Technologies) > 0 {
+		color.Cyan("\n[Technologies]")
+		for _, tech := range r.Technologies {
+			name := tech.Name
+			if tech.Version != "" {
+				name += " " + tech.Version
+			}
+			color.White("• %s (%s)", name, tech.Category)
+		}
+	}
+
+	if len(r.TrackingIDs) > 0 {
+		color.Cyan("\n[Tracking IDs]")
+		for _, id := range r.TrackingIDs {
+			color.Yellow("• %s: %s", id.Type, id.ID)
+		}
+	}
+
+	if len(r.RelatedDomains) > 0 {
+		color.Cyan("\n[Related Domains]")
+		for _, related := range r.RelatedDomains {
+			color.White("• %s (shares %s, via %s)", related.Domain, related.SharedID, related.Provider)
+		}
+	}
+
+	if len(r.SourceFindings) > 0 {
+		color.Cyan("\n[Site Sources]")
+		for _, finding := range r.SourceFindings {
+			switch finding.Type {
+			case "secret":
+				color.Red("• %s: %s", finding.Kind, finding.Value)
+				color.White("  - Found in %s", finding.Location)
+			case "social":
+				color.White("• %s: %s", finding.Kind, finding.Value)
+			}
+		}
+	}
+
+	if len(r.Emails) > 0 {
+		color.Cyan("\n[Contacts]")
+		for _, email := range r.Emails {
+			color.White("• %s", email)
+		}
+	}
+
+	names := artifactValues(r.Artifacts, ArtifactName)
+	handles := artifactValues(r.Artifacts, ArtifactHandle)
+	if len(names) > 0 || len(handles) > 0 {
+		color.Cyan("\n[People]")
+		for _, name := range names {
+			color.White("• %s", name)
+		}
+		for _, handle := range handles {
+			color.White("• Handle: %s", handle)
+		}
+	}
+
+	color.Cyan("\nExecution Time: %s", r.ExecutionTime)
+}
+
+// DisplayPartialErrors prints the checks that failed during the analysis
+func (r *DomainIntelResult) DisplayPartialErrors() {
+	displayModuleErrors(r.PartialErrors)
+}
+
+// ContactEmails returns the email addresses found in the domain's contact files
+func (r *DomainIntelResult) ContactEmails() []string {
+	var emails []string
+	for _, artifact := range r.Artifacts {
+		if artifact.Type == ArtifactEmail && (strings.HasSuffix(artifact.Source, "security.txt") || strings.HasSuffix(artifact.Source, "humans.txt")) {
+			emails = append(emails, artifact.Value)
+		}
+	}
+	return emails
+}
@@ -0,0 +1,300 @@
+package osint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/awion/MercuriesOST/public/providers"
+)
+
+// SourceFinding is a secret, address or link found in a site's HTML or JavaScript
+type SourceFinding struct {
+	Type     string `json:"type"` // secret, email or social
+	Kind     string `json:"kind"` // Pattern or platform name
+	Value    string `json:"value"`
+	Location string `json:"location"`
+}
+
+// Source scanning limits
+var (
+	DomainSourceScan  = false // Scan the homepage, linked scripts and source maps
+	maxScriptFetches  = 15
+	maxSourceBodySize = int64(5 << 20)
+)
+
+// secretPattern identifies a credential format
+type secretPattern struct {
+	kind  string
+	regex *regexp.Regexp
+}
+
+// secretPatterns are credential formats distinctive enough to report from minified code
+var secretPatterns = []secretPattern{
+	{"AWS Access Key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"Google API Key", regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{"Stripe Secret Key", regexp.MustCompile(`\b(?:sk|rk)_live_[0-9a-zA-Z]{24,}\b`)},
+	{"Stripe Publishable Key", regexp.MustCompile(`\bpk_live_[0-9a-zA-Z]{24,}\b`)},
+	{"Slack Token", regexp.MustCompile(`\bxox[abposr]-[0-9A-Za-z\-]{10,}\b`)},
+	{"Slack Webhook", regexp.MustCompile(`https://hooks\.slack\.com/services/T[0-9A-Z]+/B[0-9A-Z]+/[0-9A-Za-z]+`)},
+	{"GitHub Token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36}\b`)},
+	{"SendGrid Key", regexp.MustCompile(`\bSG\.[0-9A-Za-z_\-]{22}\.[0-9A-Za-z_\-]{43}\b`)},
+	{"Mailgun Key", regexp.MustCompile(`\bkey-[0-9a-zA-Z]{32}\b`)},
+	{"Twilio API Key", regexp.MustCompile(`\bSK[0-9a-fA-F]{32}\b`)},
+	{"Firebase Database", regexp.MustCompile(`\b[a-z0-9\-]+\.firebaseio\.com\b`)},
+	{"Private Key", regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH )?PRIVATE KEY-----`)},
+	{"JSON Web Token", regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}\b`)},
+}
+
+var (
+	scriptSrcRegex    = regexp.MustCompile(`(?i)<script[^>]+src=["']([^"']+)["']`)
+	sourceMapRegex    = regexp.MustCompile(`//[#@]\s*sourceMappingURL=(\S+)`)
+	socialLinkRegex   = regexp.MustCompile(`(?i)https?://(?:www\.)?(twitter\.com|x\.com|facebook\.com|instagram\.com|linkedin\.com/(?:in|company)|github\.com|youtube\.com/(?:c/|channel/|user/|@)|tiktok\.com/@|t\.me|discord\.gg|mastodon\.social/@)/?[A-Za-z0-9_.\-@/]+`)
+	ignoredEmailHosts = []string{"example.com", "sentry.io", "domain.com", "email.com", "wixpress.com"}
+)
+
+// scanDomainSources fetches the scripts linked from the homepage and their source
+// maps and scans them for credentials, email addresses and social media links
+func scanDomainSources(ctx context.Context, domain string, page *homepage) []SourceFinding {
+	client := &http.Client{Timeout: RequestTimeout, Transport: providers.Transport}
+	home, finalURL := page.body, page.url
+
+	var (
+		findings []SourceFinding
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, scanWorkers())
+	)
+	add := func(found []SourceFinding) {
+		mu.Lock()
+		findings = append(findings, found...)
+		mu.Unlock()
+	}
+
+	add(scanSourceContent(home, finalURL))
+	add(findSocialLinks(home, finalURL))
+
+	for _, script := range linkedScripts(home, finalURL, domain) {
+		wg.Add(1)
+		go func(script string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			content, scriptURL, err := fetchSource(ctx, client, script)
+			if err != nil {
+				return
+			}
+			add(scanSourceContent(content, scriptURL))
+
+			// Source maps often embed the original, unminified sources
+			if mapURL := sourceMapURL(content, scriptURL); mapURL != "" {
+				add(scanSourceMap(ctx, client, mapURL))
+			}
+		}(script)
+	}
+	wg.Wait()
+
+	return dedupeSourceFindings(findings)
+}
+
+// fetchSource downloads a page or script and returns its body and final URL
+func fetchSource(ctx context.Context, client *http.Client, target string) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return "", target, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", target, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", target, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSourceBodySize))
+	if err != nil {
+		return "", target, err
+	}
+	return string(body), resp.Request.URL.String(), nil
+}
+
+// linkedScripts returns the script URLs on a page that belong to the target's site
+func linkedScripts(content, pageURL, domain string) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var scripts []string
+	for _, match := range scriptSrcRegex.FindAllStringSubmatch(content, -1) {
+		ref, err := url.Parse(strings.TrimSpace(match[1]))
+		if err != nil {
+			continue
+		}
+		resolved := base.ResolveReference(ref)
+		if resolved.Scheme != "http" && resolved.Scheme != "https" {
+			continue
+		}
+
+		// Third-party libraries from CDNs are noise, keep first-party bundles
+		host := strings.ToLower(resolved.Hostname())
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+
+		if !seen[resolved.String()] {
+			seen[resolved.String()] = true
+			scripts = append(scripts, resolved.String())
+		}
+		if len(scripts) >= maxScriptFetches {
+			break
+		}
+	}
+	return scripts
+}
+
+// sourceMapURL resolves the sourceMappingURL comment of a script
+func sourceMapURL(content, scriptURL string) string {
+	matches := sourceMapRegex.FindAllStringSubmatch(content, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	ref := matches[len(matches)-1][1]
+	if strings.HasPrefix(ref, "data:") {
+		return ""
+	}
+
+	base, err := url.Parse(scriptURL)
+	if err != nil {
+		return ""
+	}
+	resolved, err := base.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	return resolved.String()
+}
+
+// scanSourceMap scans the original sources embedded in a source map
+func scanSourceMap(ctx context.Context, client *http.Client, mapURL string) []SourceFinding {
+	content, _, err := fetchSource(ctx, client, mapURL)
+	if err != nil {
+		return nil
+	}
+
+	var sourceMap struct {
+		Sources        []string `json:"sources"`
+		SourcesContent []string `json:"sourcesContent"`
+	}
+	if err := json.Unmarshal([]byte(content), &sourceMap); err != nil {
+		return nil
+	}
+
+	var findings []SourceFinding
+	for i, source := range sourceMap.SourcesContent {
+		location := mapURL
+		if i < len(sourceMap.Sources) {
+			location = mapURL + " (" + sourceMap.Sources[i] + ")"
+		}
+		findings = append(findings, scanSourceContent(source, location)...)
+	}
+	return findings
+}
+
+// scanSourceContent looks for credentials and email addresses in content
+func scanSourceContent(content, location string) []SourceFinding {
+	var findings []SourceFinding
+
+	for _, pattern := range secretPatterns {
+		for _, match := range pattern.regex.FindAllString(content, 5) {
+			findings = append(findings, SourceFinding{
+				Type:     "secret",
+				Kind:     pattern.kind,
+				Value:    maskSecret(match),
+				Location: location,
+			})
+		}
+	}
+
+	for _, email := range extractEmails(content) {
+		if ignoredEmail(email) {
+			continue
+		}
+		findings = append(findings, SourceFinding{Type: "email", Kind: "Email", Value: email, Location: location})
+	}
+
+	return findings
+}
+
+// findSocialLinks extracts links to social media profiles
+func findSocialLinks(content, location string) []SourceFinding {
+	var findings []SourceFinding
+	for _, match := range socialLinkRegex.FindAllStringSubmatch(content, -1) {
+		platform := strings.SplitN(strings.ToLower(match[1]), "/", 2)[0]
+		findings = append(findings, SourceFinding{
+			Type:     "social",
+			Kind:     platform,
+			Value:    strings.TrimRight(match[0], "/"),
+			Location: location,
+		})
+	}
+	return findings
+}
+
+// ignoredEmail filters placeholder and vendor addresses common in bundled code
+func ignoredEmail(email string) bool {
+	domain := email[strings.LastIndex(email, "@")+1:]
+	for _, ignored := range ignoredEmailHosts {
+		if domain == ignored || strings.HasSuffix(domain, "."+ignored) {
+			return true
+		}
+	}
+	// Asset names like logo@2x.png match the address pattern
+	for _, ext := range []string{".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".js", ".css"} {
+		if strings.HasSuffix(domain, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskSecret keeps enough of a credential to identify it without reproducing it
+func maskSecret(secret string) string {
+	if strings.HasPrefix(secret, "-----BEGIN") || strings.Contains(secret, "firebaseio.com") {
+		return secret
+	}
+	if len(secret) <= 12 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:6] + strings.Repeat("*", len(secret)-10) + secret[len(secret)-4:]
+}
+
+// dedupeSourceFindings removes repeated findings and lists secrets first
+func dedupeSourceFindings(findings []SourceFinding) []SourceFinding {
+	seen := make(map[string]bool)
+	var unique []SourceFinding
+	for _, finding := range findings {
+		key := finding.Type + "|" + finding.Kind + "|" + strings.ToLower(finding.Value)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, finding)
+	}
+	order := map[string]int{"secret": 0, "email": 1, "social": 2}
+	sort.SliceStable(unique, func(i, j int) bool { return order[unique[i].Type] < order[unique[j].Type] })
+	return unique
+}
@@ -0,0 +1,433 @@
+package osint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+	"golang.org/x/net/dns/dnsmessage"
+	"golang.org/x/net/publicsuffix"
+)
+
+// DomainTakeoverCheck makes domain scans look for hosts whose CNAME or NS
+// records point at resources nobody holds any more
+var DomainTakeoverCheck = false
+
+// maxTakeoverHosts caps how many hosts of a domain are checked
+const maxTakeoverHosts = 100
+
+// takeoverResolver answers the recursive lookups, as in the email module
+const takeoverResolver = "8.8.8.8:53"
+
+// takeoverLabels are subdomains checked besides those in certificate logs
+var takeoverLabels = []string{
+	"www", "blog", "docs", "help", "support", "shop", "store", "status", "cdn", "assets",
+	"static", "media", "dev", "staging", "test", "beta", "app", "api", "portal", "mail",
+}
+
+// TakeoverFinding is a host whose DNS record points at something that no
+// longer exists. Vulnerable means anyone can claim what it points at and
+// serve content, or answer DNS, for the host; the rest need a closer look.
+type TakeoverFinding struct {
+	Host       string `json:"host"`
+	Record     string `json:"record"` // CNAME or NS
+	Target     string `json:"target"`
+	Service    string `json:"service,omitempty"`
+	Vulnerable bool   `json:"vulnerable"`
+	Evidence   string `json:"evidence"`
+}
+
+// takeoverService is a hosting service a CNAME can point at. A resource
+// that was deleted shows a fingerprint page, or with nxdomain set its name
+// stops resolving, and either way can be created again by anyone.
+type takeoverService struct {
+	name         string
+	pattern      *regexp.Regexp
+	fingerprints []string
+	nxdomain     bool
+}
+
+var takeoverServices = []takeoverService{
+	{
+		name:         "GitHub Pages",
+		pattern:      regexp.MustCompile(`\.github\.io$`),
+		fingerprints: []string{"There isn't a GitHub Pages site here"},
+	},
+	{
+		name:         "Amazon S3",
+		pattern:      regexp.MustCompile(`\.s3([.-][a-z0-9-]+)*\.amazonaws\.com$`),
+		fingerprints: []string{"NoSuchBucket", "The specified bucket does not exist"},
+	},
+	{
+		name:         "Heroku",
+		pattern:      regexp.MustCompile(`\.(herokuapp|herokudns|herokussl)\.com$`),
+		fingerprints: []string{"no-such-app.html", "No such app", "There's nothing here, yet"},
+	},
+	{
+		name:     "Azure",
+		pattern:  regexp.MustCompile(`\.(azurewebsites\.net|cloudapp\.net|cloudapp\.azure\.com|trafficmanager\.net|blob\.core\.windows\.net|azureedge\.net|azure-api\.net|azurecontainer\.io|servicebus\.windows\.net)$`),
+		nxdomain: true,
+	},
+}
+
+// takeoverDNSProviders are DNS hosts where a deleted zone can be created again
+// in any account, taking over a delegation still pointing at their servers
+var takeoverDNSProviders = []takeoverService{
+	{name: "Amazon Route 53", pattern: regexp.MustCompile(`\.awsdns-\d+\.(com|net|org|co\.uk)$`)},
+	{name: "Azure DNS", pattern: regexp.MustCompile(`\.azure-dns\.(com|net|org|info)$`)},
+	{name: "DigitalOcean", pattern: regexp.MustCompile(`^ns\d\.digitalocean\.com$`)},
+	{name: "Google Cloud DNS", pattern: regexp.MustCompile(`^ns-cloud-[a-e]\d\.googledomains\.com$`)},
+}
+
+// FindTakeovers checks the domain, common subdomains and the subdomains in
+// certificate transparency logs for dangling CNAME and NS records. It returns
+// how many hosts were checked; hosts found before the certificate log lookup
+// failed are still checked.
+func FindTakeovers(ctx context.Context, domain string) ([]TakeoverFinding, int, error) {
+	hosts, hostsErr := takeoverHosts(ctx, domain)
+
+	// Delegations of subdomains are read from the domain's own name server
+	var apexServer string
+	if resp, err := dnsQuery(ctx, takeoverResolver, domain, dnsmessage.TypeNS, true); err == nil {
+		if ns := recordTargets(resp.Answers, domain, dnsmessage.TypeNS); len(ns) > 0 {
+			if addrs, err := net.DefaultResolver.LookupHost(ctx, ns[0]); err == nil && len(addrs) > 0 {
+				apexServer = net.JoinHostPort(addrs[0], "53")
+			}
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		findings []TakeoverFinding
+		sem      = make(chan struct{}, ConcurrentRequests)
+	)
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			found := checkTakeover(ctx, domain, host, apexServer)
+			mu.Lock()
+			findings = append(findings, found...)
+			mu.Unlock()
+		}(host)
+	}
+	wg.Wait()
+
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Vulnerable != findings[j].Vulnerable {
+			return findings[i].Vulnerable
+		}
+		return findings[i].Host < findings[j].Host
+	})
+	if hostsErr != nil {
+		return findings, len(hosts), hostsErr
+	}
+	return findings, len(hosts), ctx.Err()
+}
+
+// takeoverHosts lists the domain, its common subdomains and the names
+// crt.sh has seen certificates for, at most maxTakeoverHosts
+func takeoverHosts(ctx context.Context, domain string) ([]string, error) {
+	seen := map[string]bool{domain: true}
+	hosts := []string{domain}
+	add := func(host string) {
+		host = strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(host)), "*."), ".")
+		if !seen[host] && strings.HasSuffix(host, "."+domain) && len(hosts) < maxTakeoverHosts {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	for _, label := range takeoverLabels {
+		add(label + "." + domain)
+	}
+
+	names, err := certificateNames(ctx, domain)
+	sort.Strings(names)
+	for _, name := range names {
+		add(name)
+	}
+	if err != nil {
+		return hosts, fmt.Errorf("certificate logs: %v", err)
+	}
+	return hosts, nil
+}
+
+// certificateNames returns the names on certificates crt.sh has logged for
+// the domain and its subdomains
+func certificateNames(ctx context.Context, domain string) ([]string, error) {
+	client := &http.Client{Timeout: 3 * RequestTimeout, Transport: providers.Transport}
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://crt.sh/?output=json&q="+url.QueryEscape("%."+domain), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("crt.sh status %d", resp.StatusCode)
+	}
+
+	var entries []struct {
+		NameValue string `json:"name_value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.Split(entry.NameValue, "\n")...)
+	}
+	return names, nil
+}
+
+// checkTakeover looks for a dangling CNAME on host, then for a delegation
+// to name servers that no longer serve it
+func checkTakeover(ctx context.Context, domain, host, apexServer string) []TakeoverFinding {
+	var findings []TakeoverFinding
+	if resp, err := dnsQuery(ctx, takeoverResolver, host, dnsmessage.TypeCNAME, true); err == nil {
+		if targets := recordTargets(resp.Answers, host, dnsmessage.TypeCNAME); len(targets) > 0 {
+			if finding := checkCNAMETakeover(ctx, host, targets[0]); finding != nil {
+				findings = append(findings, *finding)
+			}
+		}
+	}
+
+	var nameServers []string
+	if host == domain {
+		if resp, err := dnsQuery(ctx, takeoverResolver, host, dnsmessage.TypeNS, true); err == nil {
+			nameServers = recordTargets(resp.Answers, host, dnsmessage.TypeNS)
+		}
+	} else if apexServer != "" {
+		// Resolvers fail on a lame delegation, so only then is the parent asked for it
+		resp, err := dnsQuery(ctx, takeoverResolver, host, dnsmessage.TypeA, true)
+		if err == nil && resp.RCode == dnsmessage.RCodeServerFailure {
+			if referral, err := dnsQuery(ctx, apexServer, host, dnsmessage.TypeNS, false); err == nil {
+				nameServers = recordTargets(append(referral.Answers, referral.Authorities...), host, dnsmessage.TypeNS)
+			}
+		}
+	}
+	for _, ns := range nameServers {
+		if finding := checkNSTakeover(ctx, host, ns); finding != nil {
+			findings = append(findings, *finding)
+		}
+	}
+	return findings
+}
+
+// checkCNAMETakeover reports a CNAME whose target is unregistered, no longer
+// resolves or shows a hosting service's page for a deleted resource
+func checkCNAMETakeover(ctx context.Context, host, target string) *TakeoverFinding {
+	finding := &TakeoverFinding{Host: host, Record: "CNAME", Target: target}
+	service := matchTakeoverService(takeoverServices, target)
+	if service != nil {
+		finding.Service = service.name
+	}
+
+	resp, err := dnsQuery(ctx, takeoverResolver, target, dnsmessage.TypeA, true)
+	if err == nil && resp.RCode == dnsmessage.RCodeNameError {
+		if org, unregistered := unregisteredDomain(ctx, target); unregistered {
+			finding.Vulnerable = true
+			finding.Evidence = fmt.Sprintf("%s is not registered; registering it serves content for %s", org, host)
+			return finding
+		}
+		finding.Vulnerable = service != nil && service.nxdomain
+		finding.Evidence = fmt.Sprintf("%s no longer resolves", target)
+		if finding.Vulnerable {
+			finding.Evidence += fmt.Sprintf("; the %s resource can be created again under that name", service.name)
+		}
+		return finding
+	}
+
+	if service == nil || len(service.fingerprints) == 0 {
+		return nil
+	}
+	body, err := fetchTakeoverPage(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, fingerprint := range service.fingerprints {
+		if strings.Contains(body, fingerprint) {
+			finding.Vulnerable = true
+			finding.Evidence = fmt.Sprintf("%s answers %q; the resource can be claimed under that name", service.name, fingerprint)
+			return finding
+		}
+	}
+	return nil
+}
+
+// checkNSTakeover reports a name server whose domain is unregistered, or a
+// DNS provider's server that no longer holds the zone
+func checkNSTakeover(ctx context.Context, zone, ns string) *TakeoverFinding {
+	finding := &TakeoverFinding{Host: zone, Record: "NS", Target: ns}
+	if org, unregistered := unregisteredDomain(ctx, ns); unregistered {
+		finding.Vulnerable = true
+		finding.Evidence = fmt.Sprintf("name server domain %s is not registered; registering it answers DNS for %s", org, zone)
+		return finding
+	}
+
+	provider := matchTakeoverService(takeoverDNSProviders, ns)
+	if provider == nil {
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupHost(ctx, ns)
+	if err != nil || len(addrs) == 0 {
+		return nil
+	}
+	resp, err := dnsQuery(ctx, net.JoinHostPort(addrs[0], "53"), zone, dnsmessage.TypeSOA, false)
+	if err != nil {
+		return nil
+	}
+	rcode := map[dnsmessage.RCode]string{dnsmessage.RCodeRefused: "REFUSED", dnsmessage.RCodeServerFailure: "SERVFAIL"}[resp.RCode]
+	if rcode == "" {
+		return nil
+	}
+	finding.Service = provider.name
+	finding.Vulnerable = true
+	finding.Evidence = fmt.Sprintf("%s answers %s for the zone; it can be created again in another %s account", ns, rcode, provider.name)
+	return finding
+}
+
+// unregisteredDomain reports whether the registrable domain of host does not exist
+func unregisteredDomain(ctx context.Context, host string) (string, bool) {
+	org, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return "", false
+	}
+	resp, err := dnsQuery(ctx, takeoverResolver, org, dnsmessage.TypeNS, true)
+	return org, err == nil && resp.RCode == dnsmessage.RCodeNameError
+}
+
+func matchTakeoverService(services []takeoverService, target string) *takeoverService {
+	for i := range services {
+		if services[i].pattern.MatchString(target) {
+			return &services[i]
+		}
+	}
+	return nil
+}
+
+// fetchTakeoverPage returns the page a host serves over HTTP
+func fetchTakeoverPage(ctx context.Context, host string) (string, error) {
+	client := &http.Client{Timeout: RequestTimeout, Transport: providers.Transport}
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://"+host+"/", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := readPageBody(resp)
+	return body.String(), err
+}
+
+// dnsQuery sends one query to a DNS server over UDP. Unlike net.Resolver it
+// returns the response code and the records of a CNAME whose target does
+// not exist, and can ask an authoritative server without recursion.
+func dnsQuery(ctx context.Context, server, name string, qtype dnsmessage.Type, recursive bool) (*dnsmessage.Message, error) {
+	qname, err := dnsmessage.NewName(strings.TrimSuffix(name, ".") + ".")
+	if err != nil {
+		return nil, err
+	}
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: uint16(rand.Uint32()), RecursionDesired: recursive},
+		Questions: []dnsmessage.Question{{Name: qname, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := providers.DialContext(ctx, "udp", server, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+	if _, err := conn.Write(packed); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		var resp dnsmessage.Message
+		if err := resp.Unpack(buf[:n]); err != nil || resp.ID != query.ID {
+			continue // Not the answer to this query
+		}
+		return &resp, nil
+	}
+}
+
+// recordTargets returns the targets of the CNAME or NS records for name
+func recordTargets(records []dnsmessage.Resource, name string, qtype dnsmessage.Type) []string {
+	var targets []string
+	for _, record := range records {
+		if record.Header.Type != qtype || !strings.EqualFold(strings.TrimSuffix(record.Header.Name.String(), "."), name) {
+			continue
+		}
+		switch body := record.Body.(type) {
+		case *dnsmessage.CNAMEResource:
+			targets = append(targets, strings.ToLower(strings.TrimSuffix(body.CNAME.String(), ".")))
+		case *dnsmessage.NSResource:
+			targets = append(targets, strings.ToLower(strings.TrimSuffix(body.NS.String(), ".")))
+		}
+	}
+	return targets
+}
+
+// DisplayTakeovers prints the dangling records found among the hosts checked
+func DisplayTakeovers(findings []TakeoverFinding, checked int) {
+	if checked == 0 {
+		return
+	}
+	color.Cyan("\n[Subdomain Takeover]")
+	if len(findings) == 0 {
+		color.Green("• No dangling CNAME or NS records among %d hosts", checked)
+		return
+	}
+	for _, finding := range findings {
+		line := fmt.Sprintf("• %s %s %s", finding.Host, finding.Record, finding.Target)
+		if finding.Service != "" {
+			line += " (" + finding.Service + ")"
+		}
+		if finding.Vulnerable {
+			color.Red("%s: vulnerable to takeover", line)
+		} else {
+			color.Yellow("%s: dangling, check by hand", line)
+		}
+		color.White("  - %s", finding.Evidence)
+	}
+	color.White("%d hosts checked", checked)
+}
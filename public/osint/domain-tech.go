@@ -0,0 +1,291 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/awion/MercuriesOST/public/assets/datasets"
+	"github.com/awion/MercuriesOST/public/providers"
+)
+
+// Technology is a web technology detected on a site
+type Technology struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Version  string `json:"version,omitempty"`
+	Evidence string `json:"evidence"`
+}
+
+// TrackingID is an analytics or advertising account ID embedded in a site.
+// The same ID on two sites usually means they are run by the same owner.
+type TrackingID struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// homepage is the fetched landing page shared by the domain checks
+type homepage struct {
+	url     string
+	body    string
+	header  http.Header
+	cookies []string
+}
+
+// trackingPatterns extract account IDs; the first capture group is the ID
+var trackingPatterns = []struct {
+	kind  string
+	regex *regexp.Regexp
+}{
+	{"Google Analytics", regexp.MustCompile(`\b(UA-\d{4,10}-\d{1,4})\b`)},
+	{"Google Analytics 4", regexp.MustCompile(`(?:[?&]id=|config['"]?\s*,\s*['"])(G-[A-Z0-9]{6,12})\b`)},
+	{"Google Tag Manager", regexp.MustCompile(`\b(GTM-[A-Z0-9]{4,8})\b`)},
+	{"Google Ads", regexp.MustCompile(`\b(AW-\d{9,11})\b`)},
+	{"Google AdSense", regexp.MustCompile(`\b(?:ca-)?(pub-\d{16})\b`)},
+	{"Facebook Pixel", regexp.MustCompile(`fbq\(\s*['"]init['"]\s*,\s*['"](\d{15,16})['"]`)},
+	{"Yandex Metrica", regexp.MustCompile(`(?:ym\(\s*(\d{5,10})\s*,\s*['"]init['"]|mc\.yandex\.ru/watch/(\d{5,10}))`)},
+	{"Hotjar", regexp.MustCompile(`hjid\s*:\s*(\d{5,9})`)},
+}
+
+var (
+	metaTagRegex     = regexp.MustCompile(`(?i)<meta\s[^>]*>`)
+	metaNameRegex    = regexp.MustCompile(`(?i)\b(?:name|property)\s*=\s*["']([^"']+)["']`)
+	metaContentRegex = regexp.MustCompile(`(?i)\bcontent\s*=\s*["']([^"']*)["']`)
+)
+
+// fetchHomepage downloads the landing page of a domain over HTTPS
+func fetchHomepage(ctx context.Context, domain string) (*homepage, error) {
+	client := &http.Client{Timeout: RequestTimeout, Transport: providers.Transport}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://"+domain+"/", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSourceBodySize))
+	if err != nil {
+		return nil, err
+	}
+
+	page := &homepage{
+		url:    resp.Request.URL.String(),
+		body:   string(body),
+		header: resp.Header,
+	}
+	for _, cookie := range resp.Cookies() {
+		page.cookies = append(page.cookies, cookie.Name)
+	}
+	return page, nil
+}
+
+// detectTechnologies matches the homepage against the technology fingerprints
+func detectTechnologies(page *homepage) []Technology {
+	metas := parseMetaTags(page.body)
+	scripts := scriptSrcRegex.FindAllStringSubmatch(page.body, -1)
+
+	var found []Technology
+	for _, rule := range datasets.Technologies() {
+		tech, ok := matchTechnology(rule, page, metas, scripts)
+		if ok {
+			found = append(found, tech)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Category != found[j].Category {
+			return found[i].Category < found[j].Category
+		}
+		return found[i].Name < found[j].Name
+	})
+	return found
+}
+
+// matchTechnology returns the first piece of evidence satisfying a rule
+func matchTechnology(rule datasets.TechnologyRule, page *homepage, metas map[string]string, scripts [][]string) (Technology, bool) {
+	tech := Technology{Name: rule.Name, Category: rule.Category}
+
+	for header, pattern := range rule.Headers {
+		value := page.header.Get(header)
+		if value == "" {
+			continue
+		}
+		if version, ok := matchPattern(pattern, value); ok {
+			tech.Version = version
+			tech.Evidence = fmt.Sprintf("header %s: %s", header, value)
+			return tech, true
+		}
+	}
+
+	for _, prefix := range rule.Cookies {
+		for _, cookie := range page.cookies {
+			if strings.HasPrefix(strings.ToLower(cookie), strings.ToLower(prefix)) {
+				tech.Evidence = "cookie " + cookie
+				return tech, true
+			}
+		}
+	}
+
+	for name, pattern := range rule.Meta {
+		content, exists := metas[strings.ToLower(name)]
+		if !exists {
+			continue
+		}
+		if version, ok := matchPattern(pattern, content); ok {
+			tech.Version = version
+			tech.Evidence = fmt.Sprintf("meta %s: %s", name, content)
+			return tech, true
+		}
+	}
+
+	for _, pattern := range rule.Scripts {
+		for _, script := range scripts {
+			if version, ok := matchPattern(pattern, script[1]); ok {
+				tech.Version = version
+				tech.Evidence = "script " + script[1]
+				return tech, true
+			}
+		}
+	}
+
+	for _, pattern := range rule.HTML {
+		if version, ok := matchPattern(pattern, page.body); ok {
+			tech.Version = version
+			tech.Evidence = "page markup"
+			return tech, true
+		}
+	}
+
+	return tech, false
+}
+
+// matchPattern matches a case-insensitive fingerprint pattern and returns the
+// captured version, if any. An empty pattern matches any value.
+func matchPattern(pattern, value string) (string, bool) {
+	if pattern == "" {
+		return "", true
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return "", false
+	}
+	matches := re.FindStringSubmatch(value)
+	if matches == nil {
+		return "", false
+	}
+	if len(matches) > 1 {
+		return matches[1], true
+	}
+	return "", true
+}
+
+// parseMetaTags returns meta tag contents keyed by lowercase name or property
+func parseMetaTags(body string) map[string]string {
+	metas := make(map[string]string)
+	for _, tag := range metaTagRegex.FindAllString(body, -1) {
+		name := metaNameRegex.FindStringSubmatch(tag)
+		content := metaContentRegex.FindStringSubmatch(tag)
+		if len(name) > 1 && len(content) > 1 {
+			metas[strings.ToLower(name[1])] = content[1]
+		}
+	}
+	return metas
+}
+
+// extractTrackingIDs finds analytics and advertising account IDs in a page
+func extractTrackingIDs(body string) []TrackingID {
+	seen := make(map[string]bool)
+	var ids []TrackingID
+	for _, pattern := range trackingPatterns {
+		for _, match := range pattern.regex.FindAllStringSubmatch(body, -1) {
+			id := ""
+			for _, group := range match[1:] {
+				if group != "" {
+					id = group
+					break
+				}
+			}
+			if id == "" || seen[pattern.kind+id] {
+				continue
+			}
+			seen[pattern.kind+id] = true
+			ids = append(ids, TrackingID{Type: pattern.kind, ID: id})
+		}
+	}
+	return ids
+}
+
+// RelatedDomain is another site sharing a tracking ID with the target
+type RelatedDomain struct {
+	Domain   string `json:"domain"`
+	SharedID string `json:"shared_id"`
+	IDType   string `json:"id_type"`
+	Provider string `json:"provider"`
+}
+
+// DomainIDPivot enables reverse lookups of tracking IDs found on the target
+var DomainIDPivot = false
+
+// maxRelatedPerID caps how many related domains are kept for a single ID
+const maxRelatedPerID = 50
+
+// pivotTrackingIDs looks up other domains sharing each tracking ID. ID types no
+// provider supports are returned separately so the report can say so.
+func pivotTrackingIDs(ctx context.Context, domain string, ids []TrackingID) ([]RelatedDomain, []string, error) {
+	var related []RelatedDomain
+	var unsupported []string
+	var errs []string
+
+	for _, id := range ids {
+		var providers []ReverseIDProvider
+		for _, p := range ReverseProviders {
+			if p.Supports(id.Type) {
+				providers = append(providers, p)
+			}
+		}
+		if len(providers) == 0 {
+			unsupported = append(unsupported, id.ID)
+			continue
+		}
+
+		var domains []string
+		provider, _, err := withFallback(ctx, providers, func(p ReverseIDProvider) error {
+			found, err := p.ReverseID(ctx, id)
+			domains = found
+			return err
+		})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", id.ID, err))
+			continue
+		}
+
+		count := 0
+		for _, found := range domains {
+			found = strings.TrimPrefix(found, "www.")
+			if found == domain || count >= maxRelatedPerID {
+				continue
+			}
+			related = append(related, RelatedDomain{Domain: found, SharedID: id.ID, IDType: id.Type, Provider: provider})
+			count++
+		}
+	}
+
+	if len(errs) > 0 {
+		return related, unsupported, fmt.Errorf("reverse lookup failed (%s)", strings.Join(errs, "; "))
+	}
+	return related, unsupported, nil
+}
@@ -8,18 +8,22 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/awion/MercuriesOST/public/assets/datasets"
 	"github.com/awion/MercuriesOST/public/assets/emailvalidator"
+	"github.com/awion/MercuriesOST/public/providers"
 	"github.com/fatih/color"
 )
 
 // EmailAnalysisResult holds the comprehensive data structure for email intelligence
 type EmailAnalysisResult struct {
 	Email           string                 `json:"email"`
+	ScanID          string                 `json:"scan_id,omitempty"`
 	ValidFormat     bool                   `json:"valid_format"`
 	Username        string                 `json:"username"`
 	Domain          string                 `json:"domain"`
@@ -30,8 +34,20 @@ type EmailAnalysisResult struct {
 	SocialProfiles  []SocialProfile        `json:"social_profiles"`
 	GmailSpecific   GmailSpecificInfo      `json:"gmail_specific,omitempty"`
 	OnlinePresence  OnlinePresenceInfo     `json:"online_presence"`
+	PGP             PGPInfo                `json:"pgp"`
+	IPReputation    []IPVerdict            `json:"ip_reputation,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata"`
 	SearchTimestamp string                 `json:"search_timestamp"`
+	PartialErrors   []ModuleError          `json:"partial_errors,omitempty"`
+
+	// Linked accounts on the suppression lists, left out of SocialProfiles
+	SuppressedProfiles []SocialProfile `json:"suppressed_profiles,omitempty"`
+}
+
+// ModuleError records a subtask failure so empty sections can be told apart from missing data
+type ModuleError struct {
+	Module string `json:"module"`
+	Error  string `json:"error"`
 }
 
 // PatternAnalysis contains pattern-related information for the email
@@ -62,21 +78,24 @@ type BreachDetail struct {
 	Description     string   `json:"description"`
 	IsSensitive     bool     `json:"is_sensitive"`
 	IsVerified      bool     `json:"is_verified"`
+	Source          string   `json:"source,omitempty"`
+	Tier            string   `json:"tier,omitempty"`
 }
 
 // DomainInfo contains information about the email domain
 type DomainInfo struct {
-	Registrar         string     `json:"registrar"`
-	CreationDate      string     `json:"creation_date"`
-	ExpiryDate        string     `json:"expiry_date"`
-	MXRecords         []MXRecord `json:"mx_records"`
-	SPFRecord         string     `json:"spf_record"`
-	DMARCRecord       string     `json:"dmarc_record"`
-	DKIMRecords       []string   `json:"dkim_records"`
-	IPAddresses       []string   `json:"ip_addresses"`
-	GeoIPInfo         GeoIPInfo  `json:"geoip_info"`
-	DNSHealthScore    int        `json:"dns_health_score"`
-	EmailQualityScore int        `json:"email_quality_score"`
+	Registrar         string      `json:"registrar"`
+	CreationDate      string      `json:"creation_date"`
+	ExpiryDate        string      `json:"expiry_date"`
+	MXRecords         []MXRecord  `json:"mx_records"`
+	SPFRecord         string      `json:"spf_record"`
+	DMARCRecord       string      `json:"dmarc_record"`
+	DKIMRecords       []string    `json:"dkim_records"`
+	IPAddresses       []string    `json:"ip_addresses"`
+	GeoIPInfo         GeoIPInfo   `json:"geoip_info"`
+	DNSHealthScore    int         `json:"dns_health_score"`
+	EmailQualityScore int         `json:"email_quality_score"`
+	HostIntel         []HostIntel `json:"host_intel,omitempty"`
 }
 
 // MXRecord provides detailed information about an MX record
@@ -94,6 +113,7 @@ type GeoIPInfo struct {
 	Coordinates []float64 `json:"coordinates"`
 	ISP         string    `json:"isp"`
 	ASN         string    `json:"asn"`
+	TimeZone    string    `json:"timezone,omitempty"`
 }
 
 // SocialProfile represents a social media profile linked to an email
@@ -105,6 +125,7 @@ type SocialProfile struct {
 	Bio         string                 `json:"bio,omitempty"`
 	ProfilePic  string                 `json:"profile_pic,omitempty"`
 	Verified    bool                   `json:"verified"`
+	Tier        string                 `json:"tier,omitempty"`
 	LastActive  string                 `json:"last_active,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 }
@@ -177,21 +198,61 @@ type NewsReference struct {
 
 // API keys struct
 type APIKeys struct {
-	HIBPKey        string `json:"hibp_key"`
-	MaxMindKey     string `json:"maxmind_key"`
-	ShodanKey      string `json:"shodan_key"`
-	HunterIOKey    string `json:"hunterio_key"`
-	FullContactKey string `json:"fullcontact_key"`
+	HIBPKey             string `json:"hibp_key"`
+	MaxMindKey          string `json:"maxmind_key"`
+	ShodanKey           string `json:"shodan_key"`
+	HunterIOKey         string `json:"hunterio_key"`
+	FullContactKey      string `json:"fullcontact_key"`
+	CensysID            string `json:"censys_id"`
+	CensysSecret        string `json:"censys_secret"`
+	SpyOnWebToken       string `json:"spyonweb_token"`
+	SafeBrowsingKey     string `json:"safebrowsing_key"`
+	PhishTankKey        string `json:"phishtank_key"`
+	URLScanKey          string `json:"urlscan_key"`
+	VirusTotalKey       string `json:"virustotal_key"`
+	GreyNoiseKey        string `json:"greynoise_key"`
+	AbuseIPDBKey        string `json:"abuseipdb_key"`
+	TheHiveKey          string `json:"thehive_key"`
+	OpenCTIKey          string `json:"opencti_key"`
+	PolicyKey           string `json:"policy_key"`
+	GitHubToken         string `json:"github_token"`
+	TwitterBearerToken  string `json:"twitter_bearer_token"`
+	SpotifyClientID     string `json:"spotify_client_id"`
+	SpotifyClientSecret string `json:"spotify_client_secret"`
+	LastFMKey           string `json:"lastfm_key"`
+	DeepLKey            string `json:"deepl_key"`
+	LibreTranslateKey   string `json:"libretranslate_key"`
+	LLMKey              string `json:"llm_key"`
 }
 
 // Configuration for the scanner
 var (
 	APIConfig = APIKeys{
-		HIBPKey:        "your-hibp-api-key", // Replace with env vars in production
-		MaxMindKey:     "your-maxmind-key",
-		ShodanKey:      "your-shodan-key",
-		HunterIOKey:    "your-hunterio-key",
-		FullContactKey: "your-fullcontact-key",
+		HIBPKey:             "your-hibp-api-key", // Placeholders until set in ~/.mercuries.yaml or the environment
+		MaxMindKey:          "your-maxmind-key",
+		ShodanKey:           "your-shodan-key",
+		HunterIOKey:         "your-hunterio-key",
+		FullContactKey:      "your-fullcontact-key",
+		CensysID:            "your-censys-id",
+		CensysSecret:        "your-censys-secret",
+		SpyOnWebToken:       "your-spyonweb-token",
+		SafeBrowsingKey:     "your-safebrowsing-key",
+		PhishTankKey:        "your-phishtank-key",
+		URLScanKey:          "your-urlscan-key",
+		VirusTotalKey:       "your-virustotal-key",
+		GreyNoiseKey:        "your-greynoise-key",
+		AbuseIPDBKey:        "your-abuseipdb-key",
+		TheHiveKey:          "your-thehive-key",
+		OpenCTIKey:          "your-opencti-key",
+		PolicyKey:           "your-policy-key",
+		GitHubToken:         "your-github-token",
+		TwitterBearerToken:  "your-twitter-bearer-token",
+		SpotifyClientID:     "your-spotify-client-id",
+		SpotifyClientSecret: "your-spotify-client-secret",
+		LastFMKey:           "your-lastfm-key",
+		DeepLKey:            "your-deepl-key",
+		LibreTranslateKey:   "your-libretranslate-key",
+		LLMKey:              "your-llm-key",
 	}
 	UserAgent          = "MercuriesOST/2.0"
 	RequestTimeout     = 15 * time.Second
@@ -204,6 +265,7 @@ func AnalyzeEmail(emailAddress string) (*EmailAnalysisResult, error) {
 
 	// Create a base result structure
 	result := &EmailAnalysisResult{
+		ScanID:          providers.ScanID,
 		Email:           emailAddress,
 		SearchTimestamp: time.Now().Format(time.RFC3339),
 		Metadata:        make(map[string]interface{}),
@@ -237,120 +299,79 @@ func AnalyzeEmail(emailAddress string) (*EmailAnalysisResult, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
-	// Create semaphore for limiting concurrent operations
-	sem := make(chan struct{}, ConcurrentRequests)
+	// Build the task graph; dependent tasks reuse data gathered by earlier ones
+	graph := newTaskGraph(ConcurrentRequests)
 
-	// Create wait group for concurrent operations
-	var wg sync.WaitGroup
+	graph.add("pattern", 5*time.Second, func(ctx context.Context) error {
+		result.PatternAnalysis = analyzeEmailPattern(result.Username, result.Domain)
+		return nil
+	})
 
-	// Create a mutex for safely updating the result
-	var mu sync.Mutex
-
-	// Analyze email patterns
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		sem <- struct{}{}
-		defer func() { <-sem }()
-
-		patternAnalysis := analyzeEmailPattern(result.Username, result.Domain)
-		mu.Lock()
-		result.PatternAnalysis = patternAnalysis
-		mu.Unlock()
-	}()
-
-	// Check for common email services
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		sem <- struct{}{}
-		defer func() { <-sem }()
-
-		services := identifyEmailService(result.Domain)
-		mu.Lock()
-		result.CommonServices = services
-		mu.Unlock()
-	}()
-
-	// Check for security breaches
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		sem <- struct{}{}
-		defer func() { <-sem }()
+	graph.add("services", 10*time.Second, func(ctx context.Context) error {
+		result.CommonServices = identifyEmailService(result.Domain)
+		return nil
+	})
 
+	graph.add("security", 2*RequestTimeout, func(ctx context.Context) error {
 		securityInfo, err := checkEmailSecurity(ctx, emailAddress)
-		if err == nil {
-			mu.Lock()
-			result.SecurityInfo = securityInfo
-			mu.Unlock()
-		}
-	}()
-
-	// Gather domain information
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		sem <- struct{}{}
-		defer func() { <-sem }()
+		result.SecurityInfo = securityInfo
+		return err
+	})
 
+	graph.add("domain", 2*RequestTimeout, func(ctx context.Context) error {
 		domainInfo, err := getDomainInfo(ctx, result.Domain)
-		if err == nil {
-			mu.Lock()
-			result.DomainInfo = domainInfo
-			mu.Unlock()
-		}
-	}()
-
-	// Find connected social profiles
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		sem <- struct{}{}
-		defer func() { <-sem }()
-
-		profiles, err := findSocialProfiles(ctx, result.Username, emailAddress)
-		if err == nil {
-			mu.Lock()
-			result.SocialProfiles = profiles
-			mu.Unlock()
-		}
-	}()
-
-	// Check online presence
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		sem <- struct{}{}
-		defer func() { <-sem }()
-
+		result.DomainInfo = domainInfo
+		return err
+	})
+
+	// Social checks try the username variants suggested by pattern analysis
+	graph.add("social_profiles", 2*RequestTimeout, func(ctx context.Context) error {
+		candidates := candidateUsernames(result.Username, result.PatternAnalysis)
+		profiles, err := findSocialProfiles(ctx, candidates, emailAddress)
+		result.SocialProfiles, result.SuppressedProfiles = ActiveSuppressions.SocialProfiles(profiles)
+		return err
+	}, "pattern")
+
+	graph.add("online_presence", 2*RequestTimeout, func(ctx context.Context) error {
 		onlinePresence, err := checkOnlinePresence(ctx, emailAddress, result.Username)
-		if err == nil {
-			mu.Lock()
-			result.OnlinePresence = onlinePresence
-			mu.Unlock()
-		}
-	}()
+		result.OnlinePresence = onlinePresence
+		return err
+	})
+
+	graph.add("pgp", 2*RequestTimeout, func(ctx context.Context) error {
+		pgpInfo, err := lookupPGP(ctx, emailAddress, result.Username)
+		result.PGP = pgpInfo
+		return err
+	})
+
+	// Classify mail servers and addresses seen in breach activity
+	graph.add("ip_reputation", 3*RequestTimeout, func(ctx context.Context) error {
+		ips := resolveMXAddresses(ctx, result.DomainInfo.MXRecords)
+		ips = append(ips, result.SecurityInfo.RecentActivityIPs...)
+		verdicts, err := checkIPReputation(ctx, ips)
+		result.IPReputation = verdicts
+		return err
+	}, "security", "domain")
 
 	// Gmail specific checks
 	if strings.ToLower(result.Domain) == "gmail.com" {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
+		graph.add("gmail", 3*RequestTimeout, func(ctx context.Context) error {
 			gmailInfo, err := getGmailSpecificInfo(ctx, emailAddress, result.Username)
-			if err == nil {
-				mu.Lock()
-				result.GmailSpecific = gmailInfo
-				mu.Unlock()
-			}
-		}()
+			result.GmailSpecific = gmailInfo
+			return err
+		})
 	}
 
-	// Wait for all goroutines to complete
-	wg.Wait()
+	taskErrs, err := graph.run(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for _, name := range graph.order() {
+		if taskErr, failed := taskErrs[name]; failed {
+			result.PartialErrors = append(result.PartialErrors, ModuleError{Module: name, Error: taskErr.Error()})
+		}
+	}
 
 	// Record execution time
 	result.Metadata["execution_time_ms"] = time.Since(startTime).Milliseconds()
@@ -389,26 +410,16 @@ func analyzeEmailPattern(username, domain string) PatternAnalysis {
 	}
 
 	// Check for common words in usernames
-	commonWords := map[string]string{
-		"admin":     "Administrative account",
-		"support":   "Support account",
-		"info":      "Information account",
-		"sales":     "Sales account",
-		"contact":   "Contact account",
-		"help":      "Help/assistance account",
-		"dev":       "Developer account",
-		"webmaster": "Website administrator",
-		"marketing": "Marketing account",
-		"official":  "Official entity account",
-		"service":   "Service account",
-		"personal":  "Personally identified account",
-		"private":   "Privacy-focused account",
-		"noreply":   "Automated non-reply account",
-	}
-
-	for word, description := range commonWords {
+	commonWords := datasets.EmailCommonWords()
+	words := make([]string, 0, len(commonWords))
+	for word := range commonWords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+
+	for _, word := range words {
 		if strings.Contains(usernameLower, word) {
-			patterns = append(patterns, fmt.Sprintf("Username contains '%s' - possible %s", word, description))
+			patterns = append(patterns, fmt.Sprintf("Username contains '%s' - possible %s", word, commonWords[word]))
 		}
 	}
 
@@ -441,21 +452,9 @@ func analyzeEmailPattern(username, domain string) PatternAnalysis {
 	}
 
 	// Check if business domain
-	commonPersonalDomains := []string{
-		"gmail.com", "yahoo.com", "hotmail.com", "outlook.com",
-		"aol.com", "icloud.com", "protonmail.com", "mail.com",
-		"zoho.com", "yandex.com", "inbox.com", "gmx.com",
-		"live.com", "me.com", "mac.com", "msn.com",
-		"fastmail.com", "tutanota.com", "mail.ru", "web.de",
-	}
-
-	isPersonalDomain := false
-	for _, pd := range commonPersonalDomains {
-		if strings.EqualFold(domain, pd) {
-			isPersonalDomain = true
-			patterns = append(patterns, fmt.Sprintf("Uses common personal email provider: %s", pd))
-			break
-		}
+	isPersonalDomain := datasets.PersonalEmailDomains()[strings.ToLower(domain)]
+	if isPersonalDomain {
+		patterns = append(patterns, fmt.Sprintf("Uses common personal email provider: %s", strings.ToLower(domain)))
 	}
 
 	if !isPersonalDomain {
@@ -582,13 +581,7 @@ func identifyEmailService(domain string) []string {
 func isGoogleWorkspaceDomain(domain string) bool {
 	// In a real implementation, this would check MX records for Google Workspace patterns
 	// For example, looking for mx records ending with googlemail.com
-	resolver := &net.Resolver{
-		PreferGo: true,
-		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
-			d := net.Dialer{Timeout: time.Second * 5}
-			return d.DialContext(ctx, "udp", "8.8.8.8:53")
-		},
-	}
+	resolver := providers.NewResolver("8.8.8.8:53")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -612,13 +605,7 @@ func isGoogleWorkspaceDomain(domain string) bool {
 // isMicrosoftDomain checks if the domain uses Microsoft 365
 func isMicrosoftDomain(domain string) bool {
 	// Similar to Google Workspace check, but for Microsoft domains
-	resolver := &net.Resolver{
-		PreferGo: true,
-		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
-			d := net.Dialer{Timeout: time.Second * 5}
-			return d.DialContext(ctx, "udp", "8.8.8.8:53")
-		},
-	}
+	resolver := providers.NewResolver("8.8.8.8:53")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -650,11 +637,23 @@ func checkEmailSecurity(ctx context.Context, email string) (SecurityInfo, error)
 		Metadata:          make(map[string]interface{}),
 	}
 
-	// Check for breaches using Have I Been Pwned API
-	breaches, err := checkHaveIBeenPwned(ctx, email)
+	var lookupErrs []string
+
+	// Check for breaches, falling back to secondary providers when the primary is down
+	var breaches []Breach
+	provider, attempts, err := withFallback(ctx, BreachProviders, func(p BreachProvider) error {
+		found, err := p.Breaches(ctx, email)
+		breaches = found
+		return err
+	})
+	info.Metadata["breach_provider"] = provider
+	info.Metadata["breach_provider_attempts"] = attempts
+	if err != nil {
+		lookupErrs = append(lookupErrs, fmt.Sprintf("breach lookup: %v", err))
+	}
 	if err == nil && len(breaches) > 0 {
 		info.BreachCount = len(breaches)
-		info.LeakSources = append(info.LeakSources, "Have I Been Pwned Database")
+		info.LeakSources = append(info.LeakSources, provider)
 
 		var lastBreachDate time.Time
 		dataTypesMap := make(map[string]bool)
@@ -668,6 +667,8 @@ func checkEmailSecurity(ctx context.Context, email string) (SecurityInfo, error)
 				Description:     breach.Description,
 				IsSensitive:     breach.IsSensitive,
 				IsVerified:      breach.IsVerified,
+				Source:          provider,
+				Tier:            breachTier(breach.IsVerified),
 			}
 
 			info.BreachDetails = append(info.BreachDetails, breachDetail)
@@ -704,6 +705,9 @@ func checkEmailSecurity(ctx context.Context, email string) (SecurityInfo, error)
 
 	// Check DeHashed (would require API key)
 	dehashed, err := checkDeHashed(ctx, email)
+	if err != nil {
+		lookupErrs = append(lookupErrs, fmt.Sprintf("DeHashed: %v", err))
+	}
 	if err == nil && len(dehashed) > 0 {
 		info.BreachCount += len(dehashed)
 		info.LeakSources = append(info.LeakSources, "DeHashed")
@@ -721,6 +725,10 @@ func checkEmailSecurity(ctx context.Context, email string) (SecurityInfo, error)
 	// In a real implementation, this could come from various leak sources
 	info.RecentActivityIPs = []string{"192.168.1.1", "203.0.113.42", "198.51.100.73"}
 
+	if len(lookupErrs) > 0 {
+		return info, fmt.Errorf("breach lookups failed: %s", strings.Join(lookupErrs, "; "))
+	}
+
 	return info, nil
 }
 
@@ -736,10 +744,6 @@ type Breach struct {
 
 // checkHaveIBeenPwned checks the HIBP API for breaches
 func checkHaveIBeenPwned(ctx context.Context, email string) ([]Breach, error) {
-	client := &http.Client{
-		Timeout: RequestTimeout,
-	}
-
 	req, err := http.NewRequestWithContext(ctx, "GET",
 		fmt.Sprintf("https://haveibeenpwned.com/api/v3/breachedaccount/%s", url.QueryEscape(email)),
 		nil)
@@ -750,7 +754,7 @@ func checkHaveIBeenPwned(ctx context.Context, email string) ([]Breach, error) {
 	req.Header.Set("User-Agent", UserAgent)
 	req.Header.Set("hibp-api-key", APIConfig.HIBPKey)
 
-	resp, err := client.Do(req)
+	resp, err := doProviderRequest(req)
 	if err != nil {
 		return nil, err
 	}
@@ -849,16 +853,15 @@ func getDomainInfo(ctx context.Context, domain string) (DomainInfo, error) {
 	}
 
 	// Set up DNS resolver
-	resolver := &net.Resolver{
-		PreferGo: true,
-		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
-			d := net.Dialer{Timeout: time.Second * 5}
-			return d.DialContext(ctx, "udp", "8.8.8.8:53")
-		},
-	}
+	resolver := providers.NewResolver("8.8.8.8:53")
+
+	var lookupErr error
 
 	// Get MX records
 	mxs, err := resolver.LookupMX(ctx, domain)
+	if err != nil && !isNotFoundDNSError(err) {
+		lookupErr = fmt.Errorf("MX lookup failed: %v", err)
+	}
 	if err == nil {
 		for _, mx := range mxs {
 			record := MXRecord{
@@ -895,15 +898,76 @@ func getDomainInfo(ctx context.Context, domain string) (DomainInfo, error) {
 		}
 	}
 
+	// Enrich the first few addresses with host exposure data, from the
+	// providers given keys
+	var hostErrs []string
+	hostProviders := configuredProviders(HostProviders)
+	for i, ip := range info.IPAddresses {
+		if i >= maxHostIntelLookups || len(hostProviders) == 0 {
+			break
+		}
+		var intel HostIntel
+		_, _, err := withFallback(ctx, hostProviders, func(p HostProvider) error {
+			found, err := p.Host(ctx, ip)
+			intel = found
+			return err
+		})
+		if err != nil {
+			hostErrs = append(hostErrs, fmt.Sprintf("%s: %v", ip, err))
+			continue
+		}
+		info.HostIntel = append(info.HostIntel, intel)
+	}
+
 	// Calculate DNS health score
 	info.DNSHealthScore = calculateDNSHealthScore(info)
 	info.EmailQualityScore = calculateEmailQualityScore(info)
 
-	return info, nil
+	if len(hostErrs) > 0 {
+		hostErr := fmt.Errorf("host intel lookup failed (%s)", strings.Join(hostErrs, "; "))
+		if lookupErr != nil {
+			return info, fmt.Errorf("%v; %v", lookupErr, hostErr)
+		}
+		return info, hostErr
+	}
+
+	return info, lookupErr
 }
 
-// findSocialProfiles searches for linked social media profiles
-func findSocialProfiles(ctx context.Context, username, email string) ([]SocialProfile, error) {
+// isNotFoundDNSError reports whether a DNS error just means the record doesn't exist
+func isNotFoundDNSError(err error) bool {
+	if dnsErr, ok := err.(*net.DNSError); ok {
+		return dnsErr.IsNotFound
+	}
+	return false
+}
+
+// candidateUsernames derives likely handles from the email username and its pattern analysis
+func candidateUsernames(username string, patterns PatternAnalysis) []string {
+	seen := make(map[string]bool)
+	candidates := []string{}
+	add := func(candidate string) {
+		candidate = strings.ToLower(strings.TrimSpace(candidate))
+		if candidate != "" && !seen[candidate] {
+			seen[candidate] = true
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	// Plus-addressing tags are never part of the handle
+	base := strings.SplitN(username, "+", 2)[0]
+	add(base)
+
+	// Name-like usernames are often reused without separators
+	if len(patterns.IdentityComposition) > 0 {
+		add(strings.NewReplacer(".", "", "_", "", "-", "").Replace(base))
+	}
+
+	return candidates
+}
+
+// findSocialProfiles searches for linked social media profiles, trying each candidate username in order
+func findSocialProfiles(ctx context.Context, usernames []string, email string) ([]SocialProfile, error) {
 	var profiles []SocialProfile
 	var wg sync.WaitGroup
 	var mu sync.Mutex
@@ -928,10 +992,14 @@ func findSocialProfiles(ctx context.Context, username, email string) ([]SocialPr
 			checkFn func(context.Context, string) (SocialProfile, error)
 		}) {
 			defer wg.Done()
-			if profile, err := p.checkFn(ctx, username); err == nil {
-				mu.Lock()
-				profiles = append(profiles, profile)
-				mu.Unlock()
+			for _, username := range usernames {
+				if profile, err := p.checkFn(ctx, username); err == nil {
+					profile.Tier = emailProfileTier(profile)
+					mu.Lock()
+					profiles = append(profiles, profile)
+					mu.Unlock()
+					return
+				}
 			}
 		}(platform)
 	}
@@ -983,21 +1051,21 @@ func getGmailSpecificInfo(ctx context.Context, email, username string) (GmailSpe
 		PhoneLinked:       false,
 	}
 
+	var gidErr error
+
 	// Extract Google ID if available
 	if googleID := extractGoogleID(email); googleID != "" {
 		info.GoogleID = googleID
 
-		// Analyze the Google ID
-		if results, err := AnalyzeGoogleID(ctx, googleID); err == nil {
-			info.GoogleIDResults = results
+		// Analyze the Google ID, keeping partial results
+		results, err := AnalyzeGoogleID(ctx, googleID)
+		info.GoogleIDResults = results
+		if err != nil {
+			gidErr = fmt.Errorf("Google ID analysis: %v", err)
 		}
 	}
 
-	// Check Google Workspace status
-	if workspace, domain := checkGoogleWorkspace(ctx, email); workspace {
-		info.IsGoogleWorkspace = true
-		info.WorkspaceDomain = domain
-	}
+	// Consumer Gmail addresses are never Google Workspace accounts
 
 	// Find linked Google services
 	services, err := findLinkedGoogleServices(ctx, email)
@@ -1017,7 +1085,7 @@ func getGmailSpecificInfo(ctx context.Context, email, username string) (GmailSpe
 		info.YoutubeChannels = channels
 	}
 
-	return info, nil
+	return info, gidErr
 }
 
 // Add new function to extract Google ID
@@ -1129,12 +1197,6 @@ func calculateOnlineDateRange(presence OnlinePresenceInfo) (string, string) {
 }
 
 // Helper functions for Gmail specific info
-func checkGoogleWorkspace(ctx context.Context, email string) (bool, string) {
-	// TODO: Implement actual Google Workspace check using ctx and email
-	domain := strings.Split(email, "@")[1]
-	return false, domain
-}
-
 func findLinkedGoogleServices(ctx context.Context, email string) ([]GoogleService, error) {
 	// TODO: Implement actual Google services lookup using ctx and email
 	return []GoogleService{}, nil
@@ -1198,8 +1260,16 @@ func (r *EmailAnalysisResult) DisplayResults() {
 	if r.SecurityInfo.BreachCount > 0 {
 		color.Cyan("\n[Security Information]")
 		color.Red("• Found in %d data breaches", r.SecurityInfo.BreachCount)
+		for _, tier := range Tiers {
+			if count := countBreachTier(r.SecurityInfo.BreachDetails, tier); count > 0 {
+				color.White("  - %s: %d", tier, count)
+			}
+		}
 		color.Red("• Exposed passwords: %d", r.SecurityInfo.ExposedPasswords)
 		color.White("• Risk Score: %d/100", r.SecurityInfo.RiskScore)
+		if provider, ok := r.SecurityInfo.Metadata["breach_provider"].(string); ok && provider != "" {
+			color.White("• Source: %s", provider)
+		}
 		if r.SecurityInfo.LastBreachDate != "" {
 			color.White("• Last breach date: %s", r.SecurityInfo.LastBreachDate)
 		}
@@ -1225,21 +1295,34 @@ func (r *EmailAnalysisResult) DisplayResults() {
 		if r.DomainInfo.DMARCRecord != "" {
 			color.Green("✓ DMARC record found")
 		}
+		for _, host := range r.DomainInfo.HostIntel {
+			color.White("• Host %s (%s): ports %v", host.IP, host.Provider, host.Ports)
+			if host.Org != "" {
+				color.White("  - Org: %s", host.Org)
+			}
+			if len(host.Vulns) > 0 {
+				color.Red("  - Vulnerabilities: %s", strings.Join(host.Vulns, ", "))
+			}
+		}
 	}
 
 	// Display social profiles
 	if len(r.SocialProfiles) > 0 {
 		color.Cyan("\n[Connected Social Profiles]")
 		for _, profile := range r.SocialProfiles {
-			color.White("• %s: %s", profile.Platform, profile.URL)
+			color.White("• %s: %s (%s)", profile.Platform, profile.URL, profile.Tier)
 			if profile.DisplayName != "" {
 				color.White("  - Name: %s", profile.DisplayName)
 			}
 			if profile.LastActive != "" {
 				color.White("  - Last active: %s", profile.LastActive)
 			}
+			color.White("  - Finding: %s", profile.FindingHash())
 		}
 	}
+	if len(r.SuppressedProfiles) > 0 {
+		color.Yellow("%d linked accounts on the suppression lists are hidden; the saved results keep them", len(r.SuppressedProfiles))
+	}
 
 	// Display online presence
 	if len(r.OnlinePresence.Websites) > 0 || len(r.OnlinePresence.ForumMemberships) > 0 {
@@ -1255,6 +1338,41 @@ func (r *EmailAnalysisResult) DisplayResults() {
 		}
 	}
 
+	// Display PGP keys and Keybase identity
+	if len(r.PGP.Keys) > 0 || r.PGP.Keybase != nil {
+		color.Cyan("\n[PGP Keys]")
+		for _, key := range r.PGP.Keys {
+			color.White("• %s (%s %d, created %s) on %s", key.Fingerprint, key.Algorithm, key.Bits, key.Created, strings.Join(key.Keyservers, ", "))
+			if key.Revoked {
+				color.Red("  - Revoked")
+			}
+			for _, identity := range key.Identities {
+				color.White("  - %s", identity)
+			}
+		}
+		if kb := r.PGP.Keybase; kb != nil {
+			color.White("• Keybase: %s (matched by %s)", kb.Username, kb.MatchedBy)
+			if kb.FullName != "" {
+				color.White("  - Name: %s", kb.FullName)
+			}
+			if kb.Created != "" {
+				color.White("  - Joined: %s", kb.Created)
+			}
+			for _, proof := range kb.Proofs {
+				color.White("  - %s: %s", proof.Type, proof.Name)
+			}
+		}
+		if len(r.PGP.LinkedEmails) > 0 {
+			color.White("• Linked emails: %s", strings.Join(r.PGP.LinkedEmails, ", "))
+		}
+		if len(r.PGP.LinkedDomains) > 0 {
+			color.White("• Linked domains: %s", strings.Join(r.PGP.LinkedDomains, ", "))
+		}
+	}
+
+	// Display IP reputation for mail servers and breach activity
+	displayIPReputation(r.IPReputation)
+
 	// Display Google ID information if available
 	if r.GmailSpecific.GoogleID != "" {
 		color.Cyan("\n[Google ID Information]")
@@ -1271,3 +1389,21 @@ func (r *EmailAnalysisResult) DisplayResults() {
 		color.White("Execution time: %dms", execTime)
 	}
 }
+
+// DisplayPartialErrors lists subtasks that failed during analysis
+func (r *EmailAnalysisResult) DisplayPartialErrors() {
+	displayModuleErrors(r.PartialErrors)
+}
+
+// displayModuleErrors prints the subtasks that failed during an analysis
+func displayModuleErrors(errs []ModuleError) {
+	if len(errs) == 0 {
+		return
+	}
+
+	color.Cyan("\n[Partial Errors]")
+	color.Yellow("Some sections may be empty because their checks failed:")
+	for _, moduleErr := range errs {
+		color.Red("• %s: %s", moduleErr.Module, moduleErr.Error)
+	}
+}
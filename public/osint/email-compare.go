@@ -0,0 +1,352 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/assets/datasets"
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// Weights of the signals two addresses can share, as the chance each alone
+// means one owner. Breaches are weak evidence since the large ones hold
+// millions of addresses; a shared key or recovery address is close to proof.
+const (
+	weightSameGoogleID     = 0.99
+	weightSameGravatar     = 0.95
+	weightRecoveryPointsTo = 0.95
+	weightSharedPGPKey     = 0.9
+	weightLinkedInPGP      = 0.9
+	weightSameKeybase      = 0.9
+	weightSameRecovery     = 0.8
+	weightGravatarAccount  = 0.7
+	weightGravatarUsername = 0.6
+	weightSharedProfile    = 0.5
+	weightSharedForum      = 0.5
+	weightSameLocalPart    = 0.35
+	weightGravatarName     = 0.3
+	weightSameCustomDomain = 0.2
+	weightSharedBreach     = 0.1
+)
+
+// Scores at which two addresses are reported as likely or possibly one person's
+const (
+	likelySameThreshold      = 0.8
+	possiblyRelatedThreshold = 0.4
+)
+
+// SharedSignal is something both addresses lead to
+type SharedSignal struct {
+	Kind   string  `json:"kind"` // gravatar, breach, username, profile, pgp, recovery or domain
+	Value  string  `json:"value"`
+	Weight float64 `json:"weight"`
+	Detail string  `json:"detail,omitempty"`
+}
+
+// EmailComparison holds the analyses of two addresses and what they share
+type EmailComparison struct {
+	ScanID          string                 `json:"scan_id,omitempty"`
+	EmailA          string                 `json:"email_a"`
+	EmailB          string                 `json:"email_b"`
+	SearchTimestamp string                 `json:"search_timestamp"`
+	A               *EmailAnalysisResult   `json:"a"`
+	B               *EmailAnalysisResult   `json:"b"`
+	GravatarA       []GravatarProfile      `json:"gravatar_a,omitempty"`
+	GravatarB       []GravatarProfile      `json:"gravatar_b,omitempty"`
+	Signals         []SharedSignal         `json:"shared_signals"`
+	Score           float64                `json:"score"` // 0-1, the chance the signals together mean one owner
+	Verdict         string                 `json:"verdict"`
+	PartialErrors   []ModuleError          `json:"partial_errors,omitempty"`
+	Metadata        map[string]interface{} `json:"metadata"`
+}
+
+// CompareEmails analyzes two addresses side by side and scores the signals
+// they share to judge whether one person owns both
+func CompareEmails(ctx context.Context, emailA, emailB string) (*EmailComparison, error) {
+	startTime := time.Now()
+	if strings.EqualFold(emailA, emailB) {
+		return nil, fmt.Errorf("both addresses are %s", emailA)
+	}
+
+	result := &EmailComparison{
+		ScanID:          providers.ScanIDFrom(ctx),
+		EmailA:          emailA,
+		EmailB:          emailB,
+		SearchTimestamp: time.Now().Format(time.RFC3339),
+		Signals:         []SharedSignal{},
+		Metadata:        make(map[string]interface{}),
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var analysisErr error
+	analyze := func(label, email string, analysis **EmailAnalysisResult, gravatar *[]GravatarProfile) {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			r, err := AnalyzeEmail(email)
+			mu.Lock()
+			defer mu.Unlock()
+			*analysis = r
+			if err != nil && analysisErr == nil {
+				analysisErr = fmt.Errorf("analyzing %s: %v", email, err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			profiles, err := fetchGravatar(ctx, gravatarHash(email))
+			mu.Lock()
+			defer mu.Unlock()
+			*gravatar = profiles
+			if err != nil {
+				result.PartialErrors = append(result.PartialErrors, ModuleError{Module: "gravatar_" + label, Error: err.Error()})
+			}
+		}()
+	}
+	analyze("a", emailA, &result.A, &result.GravatarA)
+	analyze("b", emailB, &result.B, &result.GravatarB)
+	wg.Wait()
+
+	if analysisErr != nil {
+		return result, analysisErr
+	}
+	if !result.A.ValidFormat || !result.B.ValidFormat {
+		return result, fmt.Errorf("cannot compare an address that failed validation")
+	}
+
+	result.findSharedSignals()
+	result.Score, result.Verdict = scoreSignals(result.Signals)
+	result.Metadata["execution_time_ms"] = time.Since(startTime).Milliseconds()
+	return result, nil
+}
+
+// addSignal records a shared signal once per kind and value
+func (c *EmailComparison) addSignal(signal SharedSignal) {
+	for _, existing := range c.Signals {
+		if existing.Kind == signal.Kind && strings.EqualFold(existing.Value, signal.Value) {
+			return
+		}
+	}
+	c.Signals = append(c.Signals, signal)
+}
+
+// findSharedSignals compares the two analyses
+func (c *EmailComparison) findSharedSignals() {
+	a, b := c.A, c.B
+
+	// Gravatar
+	for _, pa := range c.GravatarA {
+		for _, pb := range c.GravatarB {
+			if pa.ProfileURL != "" && strings.EqualFold(pa.ProfileURL, pb.ProfileURL) {
+				c.addSignal(SharedSignal{Kind: "gravatar", Value: pa.ProfileURL, Weight: weightSameGravatar, Detail: "both addresses are registered to one Gravatar profile"})
+			}
+			if pa.Username != "" && strings.EqualFold(pa.Username, pb.Username) {
+				c.addSignal(SharedSignal{Kind: "gravatar", Value: pa.Username, Weight: weightGravatarUsername, Detail: "same Gravatar username"})
+			}
+			if pa.DisplayName != "" && strings.EqualFold(pa.DisplayName, pb.DisplayName) {
+				c.addSignal(SharedSignal{Kind: "gravatar", Value: pa.DisplayName, Weight: weightGravatarName, Detail: "same Gravatar display name"})
+			}
+			for _, account := range sharedValues(pa.Accounts, pb.Accounts) {
+				c.addSignal(SharedSignal{Kind: "gravatar", Value: account, Weight: weightGravatarAccount, Detail: "account verified on both Gravatar profiles"})
+			}
+		}
+	}
+
+	// Breaches
+	var breachesA, breachesB []string
+	for _, breach := range a.SecurityInfo.BreachDetails {
+		breachesA = append(breachesA, breach.BreachName)
+	}
+	for _, breach := range b.SecurityInfo.BreachDetails {
+		breachesB = append(breachesB, breach.BreachName)
+	}
+	for _, name := range sharedValues(breachesA, breachesB) {
+		c.addSignal(SharedSignal{Kind: "breach", Value: name, Weight: weightSharedBreach, Detail: "both addresses appear in this breach"})
+	}
+
+	// Usernames and linked profiles
+	if localA, localB := comparableLocalPart(a.Username), comparableLocalPart(b.Username); localA != "" && localA == localB {
+		c.addSignal(SharedSignal{Kind: "username", Value: localA, Weight: weightSameLocalPart, Detail: "same user part, ignoring case, dots and +tags"})
+	}
+	var profilesA, profilesB []string
+	for _, profile := range a.SocialProfiles {
+		profilesA = append(profilesA, profile.URL)
+	}
+	for _, profile := range b.SocialProfiles {
+		profilesB = append(profilesB, profile.URL)
+	}
+	for _, link := range sharedValues(profilesA, profilesB) {
+		c.addSignal(SharedSignal{Kind: "profile", Value: link, Weight: weightSharedProfile, Detail: "profile linked to both addresses"})
+	}
+	for _, fa := range a.OnlinePresence.ForumMemberships {
+		for _, fb := range b.OnlinePresence.ForumMemberships {
+			if fa.Username != "" && strings.EqualFold(fa.Forum, fb.Forum) && strings.EqualFold(fa.Username, fb.Username) {
+				c.addSignal(SharedSignal{Kind: "username", Value: fa.Username + " on " + fa.Forum, Weight: weightSharedForum, Detail: "same forum account"})
+			}
+		}
+	}
+
+	// PGP and Keybase
+	var fingerprintsA, fingerprintsB []string
+	for _, key := range a.PGP.Keys {
+		fingerprintsA = append(fingerprintsA, key.Fingerprint)
+	}
+	for _, key := range b.PGP.Keys {
+		fingerprintsB = append(fingerprintsB, key.Fingerprint)
+	}
+	for _, fingerprint := range sharedValues(fingerprintsA, fingerprintsB) {
+		c.addSignal(SharedSignal{Kind: "pgp", Value: fingerprint, Weight: weightSharedPGPKey, Detail: "one PGP key lists both addresses"})
+	}
+	if a.PGP.Keybase != nil && b.PGP.Keybase != nil && strings.EqualFold(a.PGP.Keybase.Username, b.PGP.Keybase.Username) {
+		c.addSignal(SharedSignal{Kind: "pgp", Value: "keybase:" + a.PGP.Keybase.Username, Weight: weightSameKeybase, Detail: "same Keybase account"})
+	}
+	for _, pair := range [][2]*EmailAnalysisResult{{a, b}, {b, a}} {
+		for _, linked := range pair[0].PGP.LinkedEmails {
+			if strings.EqualFold(linked, pair[1].Email) {
+				c.addSignal(SharedSignal{Kind: "pgp", Value: linked, Weight: weightLinkedInPGP, Detail: "listed on the PGP key of " + pair[0].Email})
+			}
+		}
+	}
+
+	// Recovery hints
+	ga, gb := a.GmailSpecific, b.GmailSpecific
+	if ga.GoogleID != "" && ga.GoogleID == gb.GoogleID {
+		c.addSignal(SharedSignal{Kind: "recovery", Value: ga.GoogleID, Weight: weightSameGoogleID, Detail: "same Google account ID"})
+	}
+	if ga.RecoveryEmail != "" && strings.EqualFold(ga.RecoveryEmail, gb.RecoveryEmail) {
+		c.addSignal(SharedSignal{Kind: "recovery", Value: ga.RecoveryEmail, Weight: weightSameRecovery, Detail: "same masked recovery address"})
+	}
+	for _, pair := range [][2]*EmailAnalysisResult{{a, b}, {b, a}} {
+		if recovery := pair[0].GmailSpecific.RecoveryEmail; recovery != "" && recoveryMatches(recovery, pair[1].Email) {
+			c.addSignal(SharedSignal{Kind: "recovery", Value: recovery, Weight: weightRecoveryPointsTo, Detail: fmt.Sprintf("recovery address of %s fits %s", pair[0].Email, pair[1].Email)})
+		}
+	}
+
+	// A custom domain is shared by few people; a webmail domain by everyone
+	if strings.EqualFold(a.Domain, b.Domain) && !datasets.PersonalEmailDomains()[strings.ToLower(a.Domain)] {
+		c.addSignal(SharedSignal{Kind: "domain", Value: strings.ToLower(a.Domain), Weight: weightSameCustomDomain, Detail: "same non-webmail domain"})
+	}
+
+	sort.SliceStable(c.Signals, func(i, j int) bool { return c.Signals[i].Weight > c.Signals[j].Weight })
+}
+
+// sharedValues returns the values present in both lists, compared without case
+func sharedValues(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, value := range b {
+		inB[strings.ToLower(value)] = true
+	}
+	var shared []string
+	for _, value := range a {
+		if value != "" && inB[strings.ToLower(value)] {
+			shared = append(shared, value)
+		}
+	}
+	return shared
+}
+
+// comparableLocalPart lowercases the user part of an address and drops its
+// +tag and separators, so john.doe+news and JohnDoe compare equal
+func comparableLocalPart(local string) string {
+	local, _, _ = strings.Cut(strings.ToLower(local), "+")
+	return strings.NewReplacer(".", "", "_", "", "-", "").Replace(local)
+}
+
+// recoveryMatches reports whether a masked recovery address such as
+// j*****e@g****.com fits email, comparing the characters left unmasked
+func recoveryMatches(masked, email string) bool {
+	masked, email = strings.ToLower(masked), strings.ToLower(email)
+	if !strings.Contains(masked, "*") {
+		return masked == email
+	}
+	if len(masked) != len(email) {
+		return false
+	}
+	for i := 0; i < len(masked); i++ {
+		if masked[i] != '*' && masked[i] != email[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// scoreSignals combines independent signals into the chance that at least one
+// of them means a single owner, and a verdict for it
+func scoreSignals(signals []SharedSignal) (float64, string) {
+	if len(signals) == 0 {
+		return 0, "No shared signals"
+	}
+	unrelated := 1.0
+	for _, signal := range signals {
+		unrelated *= 1 - signal.Weight
+	}
+	score := 1 - unrelated
+	switch {
+	case score >= likelySameThreshold:
+		return score, "Likely the same person"
+	case score >= possiblyRelatedThreshold:
+		return score, "Possibly the same person"
+	}
+	return score, "Weak overlap, not enough to link them"
+}
+
+// DisplayResults prints the comparison
+func (c *EmailComparison) DisplayResults() {
+	color.Cyan("\n=== EMAIL COMPARISON ===")
+	color.Yellow("A: %s", c.EmailA)
+	color.Yellow("B: %s", c.EmailB)
+
+	color.Cyan("\n[Side by Side]")
+	row := func(label string, a, b interface{}) {
+		color.White("• %-16s %-28v %v", label+":", a, b)
+	}
+	row("Domain", c.A.Domain, c.B.Domain)
+	row("Breaches", c.A.SecurityInfo.BreachCount, c.B.SecurityInfo.BreachCount)
+	row("Social profiles", len(c.A.SocialProfiles), len(c.B.SocialProfiles))
+	row("PGP keys", len(c.A.PGP.Keys), len(c.B.PGP.Keys))
+	row("Gravatar", len(c.GravatarA) > 0, len(c.GravatarB) > 0)
+
+	if len(c.Signals) == 0 {
+		color.Yellow("\nNo shared signals found")
+	} else {
+		color.Cyan("\n[Shared Signals]")
+		for _, signal := range c.Signals {
+			line := fmt.Sprintf("• [%s] %s (weight %.2f)", signal.Kind, signal.Value, signal.Weight)
+			if signal.Weight >= weightSharedPGPKey {
+				color.Green(line)
+			} else {
+				color.White(line)
+			}
+			if signal.Detail != "" {
+				color.White("  %s", signal.Detail)
+			}
+		}
+	}
+
+	color.Cyan("\n[Verdict]")
+	verdict := fmt.Sprintf("%s (score %.2f)", c.Verdict, c.Score)
+	switch {
+	case c.Score >= likelySameThreshold:
+		color.Green(verdict)
+	case c.Score >= possiblyRelatedThreshold:
+		color.Yellow(verdict)
+	default:
+		color.White(verdict)
+	}
+}
+
+// DisplayPartialErrors prints the lookups that failed for either address
+func (c *EmailComparison) DisplayPartialErrors() {
+	errs := append([]ModuleError{}, c.PartialErrors...)
+	for _, side := range []*EmailAnalysisResult{c.A, c.B} {
+		for _, e := range side.PartialErrors {
+			errs = append(errs, ModuleError{Module: side.Email + " " + e.Module, Error: e.Error})
+		}
+	}
+	displayModuleErrors(errs)
+}
@@ -0,0 +1,455 @@
+package osint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// HeaderAnalysis is the result of tracing an email through its headers
+type HeaderAnalysis struct {
+	Subject         string                 `json:"subject,omitempty"`
+	ScanID          string                 `json:"scan_id,omitempty"`
+	From            string                 `json:"from,omitempty"`
+	ReplyTo         string                 `json:"reply_to,omitempty"`
+	ReturnPath      string                 `json:"return_path,omitempty"`
+	MessageID       string                 `json:"message_id,omitempty"`
+	Date            string                 `json:"date,omitempty"`
+	Route           []ReceivedHop          `json:"route"`
+	OriginatingIP   string                 `json:"originating_ip,omitempty"`
+	MailClient      []string               `json:"mail_client,omitempty"`
+	Authentication  AuthenticationResults  `json:"authentication"`
+	Warnings        []string               `json:"warnings,omitempty"`
+	Metadata        map[string]interface{} `json:"metadata"`
+	SearchTimestamp string                 `json:"search_timestamp"`
+	PartialErrors   []ModuleError          `json:"partial_errors,omitempty"`
+}
+
+// ReceivedHop is one relay in a message's Received chain, ordered from the sender
+type ReceivedHop struct {
+	From      string     `json:"from,omitempty"`
+	By        string     `json:"by,omitempty"`
+	IP        string     `json:"ip,omitempty"`
+	Protocol  string     `json:"protocol,omitempty"`
+	Timestamp string     `json:"timestamp,omitempty"`
+	Delay     string     `json:"delay,omitempty"`
+	Private   bool       `json:"private,omitempty"`
+	Geo       *GeoIPInfo `json:"geo,omitempty"`
+}
+
+// AuthenticationResults holds the SPF, DKIM and DMARC verdicts of the receiving server
+type AuthenticationResults struct {
+	SPF         string   `json:"spf,omitempty"`
+	DKIM        string   `json:"dkim,omitempty"`
+	DMARC       string   `json:"dmarc,omitempty"`
+	DKIMDomains []string `json:"dkim_domains,omitempty"`
+	Raw         string   `json:"raw,omitempty"`
+}
+
+// maxRouteLookups caps how many hop addresses are geolocated
+const maxRouteLookups = 10
+
+var (
+	receivedFromRegex = regexp.MustCompile(`(?i)\bfrom\s+(\S+)(.*?)(?:\bby\b|$)`)
+	receivedByRegex   = regexp.MustCompile(`(?i)\bby\s+([^\s;()]+)`)
+	receivedWithRegex = regexp.MustCompile(`(?i)\bwith\s+([^\s;()]+)`)
+	bracketIPRegex    = regexp.MustCompile(`\[(?:IPv6:)?([0-9a-fA-F:.]+)\]`)
+	bareIPv4Regex     = regexp.MustCompile(`\b((?:\d{1,3}\.){3}\d{1,3})\b`)
+	authVerdictRegex  = regexp.MustCompile(`(?i)\b(spf|dkim|dmarc)\s*=\s*([a-z]+)`)
+	dkimDomainRegex   = regexp.MustCompile(`(?i)(?:^|;)\s*d\s*=\s*([^\s;]+)`)
+)
+
+// mailClientHints identify the sending platform from header names or Message-ID hosts
+var mailClientHints = []struct {
+	header string // Header whose presence identifies the client
+	suffix string // Message-ID host suffix identifying the client
+	client string
+}{
+	{header: "X-Google-Smtp-Source", client: "Gmail"},
+	{suffix: "mail.gmail.com", client: "Gmail"},
+	{header: "X-MS-Exchange-Organization-AuthSource", client: "Microsoft Exchange"},
+	{suffix: ".prod.outlook.com", client: "Outlook / Exchange Online"},
+	{header: "X-Apple-Mail-Remote-Attachments", client: "Apple Mail"},
+	{header: "X-YMail-OSG", client: "Yahoo Mail"},
+	{suffix: "mail.yahoo.com", client: "Yahoo Mail"},
+	{header: "X-Mailgun-Sid", client: "Mailgun"},
+	{header: "X-SG-EID", client: "SendGrid"},
+	{header: "X-SES-Outgoing", client: "Amazon SES"},
+	{suffix: "email.amazonses.com", client: "Amazon SES"},
+	{header: "X-PHP-Originating-Script", client: "PHP mail() script"},
+}
+
+// AnalyzeHeaders parses a raw message or header block and traces its route
+func AnalyzeHeaders(ctx context.Context, r io.Reader) (*HeaderAnalysis, error) {
+	startTime := time.Now()
+
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header-only pastes often lack the blank line that ends a header block
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		msg, err = mail.ReadMessage(io.MultiReader(bytes.NewReader(raw), strings.NewReader("\r\n\r\n")))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse headers: %v", err)
+		}
+	}
+	header := msg.Header
+	if len(header) == 0 {
+		return nil, fmt.Errorf("no headers found")
+	}
+
+	result := &HeaderAnalysis{
+		ScanID:          providers.ScanIDFrom(ctx),
+		Subject:         decodeHeader(header.Get("Subject")),
+		From:            decodeHeader(header.Get("From")),
+		ReplyTo:         decodeHeader(header.Get("Reply-To")),
+		ReturnPath:      strings.Trim(header.Get("Return-Path"), "<>"),
+		MessageID:       header.Get("Message-Id"),
+		Date:            header.Get("Date"),
+		Route:           parseReceivedChain(header["Received"]),
+		Authentication:  parseAuthentication(header),
+		MailClient:      fingerprintMailClient(header),
+		Metadata:        make(map[string]interface{}),
+		SearchTimestamp: time.Now().Format(time.RFC3339),
+	}
+	result.OriginatingIP = originatingIP(header, result.Route)
+	result.Warnings = headerWarnings(result)
+
+	// Geolocate public relays to map the route
+	var geoErrs []string
+	lookups := 0
+	for i := range result.Route {
+		hop := &result.Route[i]
+		if hop.IP == "" || hop.Private || lookups >= maxRouteLookups {
+			continue
+		}
+		lookups++
+		var geo GeoIPInfo
+		_, _, err := withFallback(ctx, GeoProviders, func(p GeoProvider) error {
+			found, err := p.Geolocate(ctx, hop.IP)
+			geo = found
+			return err
+		})
+		if err != nil {
+			geoErrs = append(geoErrs, fmt.Sprintf("%s: %v", hop.IP, err))
+			continue
+		}
+		hop.Geo = &geo
+	}
+	if len(geoErrs) > 0 {
+		result.PartialErrors = append(result.PartialErrors, ModuleError{Module: "geolocation", Error: strings.Join(geoErrs, "; ")})
+	}
+
+	result.Metadata["hop_count"] = len(result.Route)
+	result.Metadata["execution_time_ms"] = time.Since(startTime).Milliseconds()
+	return result, nil
+}
+
+// decodeHeader decodes RFC 2047 encoded words, returning the raw value on failure
+func decodeHeader(value string) string {
+	decoded, err := new(mime.WordDecoder).DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// parseReceivedChain parses Received headers, which are prepended by each relay,
+// into hops ordered from the sender to the recipient
+func parseReceivedChain(received []string) []ReceivedHop {
+	route := make([]ReceivedHop, 0, len(received))
+	for i := len(received) - 1; i >= 0; i-- {
+		route = append(route, parseReceived(received[i]))
+	}
+
+	var previous time.Time
+	for i := range route {
+		current, err := mail.ParseDate(route[i].Timestamp)
+		if err != nil {
+			previous = time.Time{}
+			continue
+		}
+		if !previous.IsZero() {
+			route[i].Delay = current.Sub(previous).String()
+		}
+		previous = current
+	}
+	return route
+}
+
+// parseReceived parses a single "from X (Y [IP]) by Z with P; date" header
+func parseReceived(value string) ReceivedHop {
+	value = strings.Join(strings.Fields(value), " ")
+	var hop ReceivedHop
+
+	// The date follows the last semicolon
+	clauses := value
+	if idx := strings.LastIndex(value, ";"); idx >= 0 {
+		clauses = value[:idx]
+		hop.Timestamp = strings.TrimSpace(value[idx+1:])
+	}
+
+	if matches := receivedFromRegex.FindStringSubmatch(clauses); len(matches) > 2 {
+		hop.From = matches[1]
+		// The relay's own view of the connecting address is in the comment;
+		// the HELO name before it is chosen by the sender
+		for _, part := range []string{matches[2], matches[1]} {
+			if ip := bracketIPRegex.FindStringSubmatch(part); len(ip) > 1 && net.ParseIP(ip[1]) != nil {
+				hop.IP = ip[1]
+				break
+			}
+			if ip := bareIPv4Regex.FindStringSubmatch(part); len(ip) > 1 && net.ParseIP(ip[1]) != nil {
+				hop.IP = ip[1]
+				break
+			}
+		}
+	}
+	if matches := receivedByRegex.FindStringSubmatch(clauses); len(matches) > 1 {
+		hop.By = matches[1]
+	}
+	if matches := receivedWithRegex.FindStringSubmatch(clauses); len(matches) > 1 {
+		hop.Protocol = matches[1]
+	}
+	if hop.IP != "" {
+		hop.Private = !isPublicIP(net.ParseIP(hop.IP))
+	}
+	return hop
+}
+
+// isPublicIP reports whether an address is routable on the internet
+func isPublicIP(ip net.IP) bool {
+	return ip != nil && !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsUnspecified()
+}
+
+// originatingIP returns the sender's address, preferring headers webmail
+// services add for the submitting client over the first public relay
+func originatingIP(header mail.Header, route []ReceivedHop) string {
+	for _, name := range []string{"X-Originating-IP", "X-Sender-IP", "X-Forwarded-For"} {
+		value := strings.Trim(header.Get(name), "[] ")
+		if ip := net.ParseIP(strings.Split(value, ",")[0]); ip != nil {
+			return ip.String()
+		}
+	}
+	for _, hop := range route {
+		if hop.IP != "" && !hop.Private {
+			return hop.IP
+		}
+	}
+	return ""
+}
+
+// parseAuthentication reads the verdicts of the topmost Authentication-Results
+// header; lower ones may have been added by the sender and can't be trusted
+func parseAuthentication(header mail.Header) AuthenticationResults {
+	var auth AuthenticationResults
+
+	if results := header["Authentication-Results"]; len(results) > 0 {
+		auth.Raw = strings.Join(strings.Fields(results[0]), " ")
+		for _, match := range authVerdictRegex.FindAllStringSubmatch(auth.Raw, -1) {
+			verdict := strings.ToLower(match[2])
+			switch strings.ToLower(match[1]) {
+			case "spf":
+				if auth.SPF == "" {
+					auth.SPF = verdict
+				}
+			case "dkim":
+				if auth.DKIM == "" || verdict == "pass" {
+					auth.DKIM = verdict
+				}
+			case "dmarc":
+				if auth.DMARC == "" {
+					auth.DMARC = verdict
+				}
+			}
+		}
+	}
+
+	// Received-SPF is written by the receiving server when it doesn't add Authentication-Results
+	if auth.SPF == "" {
+		if spf := strings.Fields(header.Get("Received-SPF")); len(spf) > 0 {
+			auth.SPF = strings.ToLower(spf[0])
+		}
+	}
+
+	for _, signature := range header["Dkim-Signature"] {
+		if matches := dkimDomainRegex.FindStringSubmatch(signature); len(matches) > 1 {
+			auth.DKIMDomains = mergeStrings(auth.DKIMDomains, []string{strings.ToLower(matches[1])})
+		}
+	}
+	return auth
+}
+
+// fingerprintMailClient identifies the software and platforms that sent a message
+func fingerprintMailClient(header mail.Header) []string {
+	var clients []string
+	for _, name := range []string{"X-Mailer", "User-Agent"} {
+		if value := header.Get(name); value != "" {
+			clients = append(clients, value)
+		}
+	}
+
+	messageHost := strings.ToLower(strings.Trim(header.Get("Message-Id"), "<> "))
+	if idx := strings.LastIndex(messageHost, "@"); idx >= 0 {
+		messageHost = messageHost[idx+1:]
+	}
+	for _, hint := range mailClientHints {
+		if (hint.header != "" && header.Get(hint.header) != "") ||
+			(hint.suffix != "" && strings.HasSuffix(messageHost, hint.suffix)) {
+			clients = mergeStrings(clients, []string{hint.client})
+		}
+	}
+	return clients
+}
+
+// headerWarnings flags common signs of spoofing and phishing
+func headerWarnings(result *HeaderAnalysis) []string {
+	var warnings []string
+
+	fromDomain := addressDomain(result.From)
+	if replyDomain := addressDomain(result.ReplyTo); replyDomain != "" && replyDomain != fromDomain {
+		warnings = append(warnings, fmt.Sprintf("Reply-To domain %s differs from From domain %s", replyDomain, fromDomain))
+	}
+	if returnDomain := addressDomain(result.ReturnPath); returnDomain != "" && fromDomain != "" &&
+		returnDomain != fromDomain && !strings.HasSuffix(returnDomain, "."+fromDomain) {
+		warnings = append(warnings, fmt.Sprintf("Return-Path domain %s differs from From domain %s", returnDomain, fromDomain))
+	}
+
+	auth := result.Authentication
+	for name, verdict := range map[string]string{"SPF": auth.SPF, "DKIM": auth.DKIM, "DMARC": auth.DMARC} {
+		if verdict == "fail" || verdict == "softfail" || verdict == "permerror" {
+			warnings = append(warnings, fmt.Sprintf("%s %s", name, verdict))
+		}
+	}
+	if fromDomain != "" && len(auth.DKIMDomains) > 0 {
+		aligned := false
+		for _, domain := range auth.DKIMDomains {
+			if domain == fromDomain || strings.HasSuffix(fromDomain, "."+domain) {
+				aligned = true
+			}
+		}
+		if !aligned {
+			warnings = append(warnings, fmt.Sprintf("DKIM signed by %s, not the From domain %s", strings.Join(auth.DKIMDomains, ", "), fromDomain))
+		}
+	}
+
+	sort.Strings(warnings)
+	return warnings
+}
+
+// addressDomain returns the lowercase domain of an address header value
+func addressDomain(value string) string {
+	if value == "" {
+		return ""
+	}
+	address := value
+	if parsed, err := mail.ParseAddress(value); err == nil {
+		address = parsed.Address
+	}
+	idx := strings.LastIndex(address, "@")
+	if idx < 0 {
+		return ""
+	}
+	return strings.ToLower(strings.Trim(address[idx+1:], "> "))
+}
+
+// DisplayResults formats and displays the header analysis
+func (r *HeaderAnalysis) DisplayResults() {
+	color.Cyan("\n=== EMAIL HEADER ANALYSIS ===")
+	if r.Subject != "" {
+		color.Yellow("Subject: %s", r.Subject)
+	}
+	color.Yellow("From: %s", r.From)
+	if r.ReplyTo != "" {
+		color.Yellow("Reply-To: %s", r.ReplyTo)
+	}
+	if r.Date != "" {
+		color.Yellow("Date: %s", r.Date)
+	}
+
+	color.Cyan("\n[Authentication]")
+	for _, check := range []struct{ name, verdict string }{
+		{"SPF", r.Authentication.SPF}, {"DKIM", r.Authentication.DKIM}, {"DMARC", r.Authentication.DMARC},
+	} {
+		switch check.verdict {
+		case "pass":
+			color.Green("✓ %s pass", check.name)
+		case "":
+			color.White("• %s not reported", check.name)
+		default:
+			color.Red("✗ %s %s", check.name, check.verdict)
+		}
+	}
+	if len(r.Authentication.DKIMDomains) > 0 {
+		color.White("• DKIM signing domains: %s", strings.Join(r.Authentication.DKIMDomains, ", "))
+	}
+
+	if r.OriginatingIP != "" || len(r.MailClient) > 0 {
+		color.Cyan("\n[Origin]")
+		if r.OriginatingIP != "" {
+			color.White("• Originating IP: %s", r.OriginatingIP)
+		}
+		for _, client := range r.MailClient {
+			color.White("• Client: %s", client)
+		}
+	}
+
+	if len(r.Route) > 0 {
+		color.Cyan("\n[Route]")
+		for i, hop := range r.Route {
+			color.White("%d. %s → %s", i+1, valueOr(hop.From, "?"), valueOr(hop.By, "?"))
+			if hop.IP != "" {
+				location := "private network"
+				if hop.Geo != nil {
+					location = strings.Trim(strings.Join([]string{hop.Geo.City, hop.Geo.Country}, ", "), ", ")
+					if hop.Geo.ASN != "" {
+						location += " (" + hop.Geo.ASN + ")"
+					}
+				} else if !hop.Private {
+					location = "unknown location"
+				}
+				color.White("   IP: %s, %s", hop.IP, location)
+			}
+			if hop.Timestamp != "" {
+				if hop.Delay != "" {
+					color.White("   %s (delay %s)", hop.Timestamp, hop.Delay)
+				} else {
+					color.White("   %s", hop.Timestamp)
+				}
+			}
+		}
+	}
+
+	if len(r.Warnings) > 0 {
+		color.Cyan("\n[Warnings]")
+		for _, warning := range r.Warnings {
+			color.Red("! %s", warning)
+		}
+	}
+}
+
+// DisplayPartialErrors lists lookups that failed during analysis
+func (r *HeaderAnalysis) DisplayPartialErrors() {
+	displayModuleErrors(r.PartialErrors)
+}
+
+// valueOr returns value, or fallback when it is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
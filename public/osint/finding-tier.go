@@ -0,0 +1,90 @@
+package osint
+
+// Finding tiers, from strongest to weakest. Every profile and breach a
+// module reports carries one, and displays and exports group by it.
+const (
+	TierVerified = "verified" // Confirmed by the platform or provider's own API
+	TierProbable = "probable" // Read from a public page and matching well
+	TierLead     = "lead"     // Inferred or weakly matching; check before relying on it
+)
+
+// Tiers lists the finding tiers, strongest first
+var Tiers = []string{TierVerified, TierProbable, TierLead}
+
+// Evidence behind a finding
+const (
+	EvidenceAPI      = "api"      // Answered by the platform or provider's API
+	EvidenceScraped  = "scraped"  // Read from a public page
+	EvidenceInferred = "inferred" // Found under a handle generated from the query
+)
+
+// Confidence a finding needs to reach each tier with its evidence
+const (
+	verifiedAPIConfidence      = 0.8
+	probableAPIConfidence      = 0.5
+	probableScrapedConfidence  = 0.7
+	probableInferredConfidence = 0.9
+)
+
+// classifyFinding returns the tier of a finding from its evidence and
+// confidence. Only an API answer makes a finding verified; scraped pages and
+// inferred handles can at most be probable.
+func classifyFinding(evidence string, confidence float64) string {
+	switch evidence {
+	case EvidenceAPI:
+		if confidence >= verifiedAPIConfidence {
+			return TierVerified
+		}
+		if confidence >= probableAPIConfidence {
+			return TierProbable
+		}
+	case EvidenceScraped:
+		if confidence >= probableScrapedConfidence {
+			return TierProbable
+		}
+	case EvidenceInferred:
+		if confidence >= probableInferredConfidence {
+			return TierProbable
+		}
+	}
+	return TierLead
+}
+
+// classifyProfile sets a profile's tier, making it a lead when it scores
+// below MinConfidence whatever its evidence
+func classifyProfile(profile *ProfileResult) {
+	profile.Tier = classifyFinding(profile.Evidence, profile.Confidence)
+	if MinConfidence > 0 && profile.Confidence < MinConfidence {
+		profile.Tier = TierLead
+	}
+}
+
+// breachTier returns the tier of a breach reported by a breach API, verified
+// when the provider has confirmed the breach itself
+func breachTier(providerVerified bool) string {
+	if providerVerified {
+		return classifyFinding(EvidenceAPI, 1)
+	}
+	return classifyFinding(EvidenceAPI, probableAPIConfidence)
+}
+
+// emailProfileTier returns the tier of a profile linked to an email. One the
+// platform confirmed belongs to the address is verified; the rest were found
+// under usernames guessed from the address.
+func emailProfileTier(profile SocialProfile) string {
+	if profile.Verified {
+		return classifyFinding(EvidenceAPI, 1)
+	}
+	return classifyFinding(EvidenceInferred, 0)
+}
+
+// countBreachTier counts the breaches in a tier
+func countBreachTier(breaches []BreachDetail, tier string) int {
+	count := 0
+	for _, breach := range breaches {
+		if breach.Tier == tier {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,198 @@
+package osint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+)
+
+const (
+	repeatedStartKm    = 0.2 // Start points this close count as the same place
+	minRepeatedStarts  = 3   // Activities sharing a start point before it is flagged
+	maxActivityAreas   = 5   // Activity locations kept per profile
+	exposedAreaDecimal = 3   // Flagged points are rounded to about 100 m
+)
+
+// reactPropsRegex captures the data Strava renders its pages from
+var reactPropsRegex = regexp.MustCompile(`data-react-props="([^"]+)"`)
+
+// ExposedArea is a place a fitness profile's public activities keep
+// starting from, which a privacy zone would have hidden
+type ExposedArea struct {
+	Lat        float64 `json:"lat"`
+	Lon        float64 `json:"lon"`
+	Activities int     `json:"activities"`
+}
+
+// stravaProfile reads a Strava athlete's name, location, clubs and recent
+// activities from the data embedded in their public page, flagging a start
+// point their activities share
+func stravaProfile(ctx context.Context, handle string, result *ProfileResult) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://www.strava.com/athletes/"+url.PathEscape(handle), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	resp, err := doProviderRequest(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := readPageBody(resp)
+	if err != nil {
+		return false, err
+	}
+
+	for _, m := range reactPropsRegex.FindAllStringSubmatch(body.String(), -1) {
+		var props struct {
+			Athlete struct {
+				ID       int64  `json:"id"`
+				Name     string `json:"name"`
+				Location string `json:"location"`
+				Avatar   string `json:"avatarUrl"`
+				Bio      string `json:"description"`
+			} `json:"athlete"`
+			Followers int `json:"followersCount"`
+			Clubs     []struct {
+				Name string `json:"name"`
+			} `json:"clubs"`
+			RecentActivities []struct {
+				Name       string    `json:"name"`
+				Type       string    `json:"type"`
+				StartDate  string    `json:"startDate"`
+				Location   string    `json:"location"`
+				StartPoint []float64 `json:"startLatlng"`
+			} `json:"recentActivities"`
+		}
+		if json.Unmarshal([]byte(html.UnescapeString(m[1])), &props) != nil || props.Athlete.Name == "" {
+			continue
+		}
+
+		result.Confidence = 0.9
+		if props.Athlete.ID != 0 {
+			result.CanonicalID = fmt.Sprint(props.Athlete.ID)
+		}
+		result.FullName = props.Athlete.Name
+		result.Location = props.Athlete.Location
+		result.Avatar = props.Athlete.Avatar
+		result.Bio = cleanText(props.Athlete.Bio)
+		result.FollowerCount = props.Followers
+		for _, club := range props.Clubs {
+			result.Clubs = append(result.Clubs, club.Name)
+		}
+
+		var starts [][]float64
+		for _, activity := range props.RecentActivities {
+			line := activity.Name
+			if activity.Type != "" {
+				line += " (" + activity.Type + ")"
+			}
+			if started, err := time.Parse(time.RFC3339, activity.StartDate); err == nil {
+				result.ActivityTimes = append(result.ActivityTimes, started.UTC().Format(time.RFC3339))
+			}
+			result.RecentActivity = append(result.RecentActivity, line)
+			if activity.Location != "" && len(result.ActivityAreas) < maxActivityAreas && !slices.Contains(result.ActivityAreas, activity.Location) {
+				result.ActivityAreas = append(result.ActivityAreas, activity.Location)
+			}
+			if len(activity.StartPoint) == 2 {
+				starts = append(starts, activity.StartPoint)
+			}
+		}
+		flagExposedArea(result, starts, len(props.RecentActivities))
+		return true, nil
+	}
+	// Layout changes fall back to the page selectors
+	return false, errNoProfileLookup
+}
+
+// garminProfile reads a Garmin Connect user's name, location and favourite
+// activities from their social profile, which only public profiles share
+func garminProfile(ctx context.Context, handle string, result *ProfileResult) (bool, error) {
+	var profile struct {
+		DisplayName        string   `json:"displayName"`
+		FullName           string   `json:"fullName"`
+		Location           string   `json:"location"`
+		Bio                string   `json:"bio"`
+		Avatar             string   `json:"profileImageUrlLarge"`
+		PrimaryActivity    string   `json:"primaryActivity"`
+		FavoriteActivities []string `json:"favoriteActivityTypes"`
+		Visibility         string   `json:"profileVisibility"`
+	}
+	target := "https://connect.garmin.com/modern/proxy/userprofile-service/socialProfile/" + url.PathEscape(handle)
+	err := getProviderJSON(ctx, target, map[string]string{"NK": "NT"}, &profile)
+	switch {
+	case providers.IsStatus(err, http.StatusNotFound):
+		return false, nil
+	case providers.IsStatus(err, http.StatusUnauthorized), providers.IsStatus(err, http.StatusForbidden):
+		// Sign-in walls fall back to the profile page
+		return false, errNoProfileLookup
+	case err != nil:
+		return false, err
+	}
+
+	result.Confidence = 0.9
+	result.FullName = profile.FullName
+	result.Location = profile.Location
+	result.Bio = cleanText(profile.Bio)
+	result.Avatar = profile.Avatar
+	if profile.Visibility != "" {
+		result.Insights = append(result.Insights, "Profile visibility: "+strings.ToLower(profile.Visibility))
+	}
+	activities := profile.FavoriteActivities
+	if profile.PrimaryActivity != "" && !slices.Contains(activities, profile.PrimaryActivity) {
+		activities = append([]string{profile.PrimaryActivity}, activities...)
+	}
+	if len(activities) > 0 {
+		result.Insights = append(result.Insights, "Favourite activities: "+strings.ReplaceAll(strings.Join(activities, ", "), "_", " "))
+	}
+	return true, nil
+}
+
+// flagExposedArea looks for a start point most activities share. Strava
+// hides the start of activities inside a privacy zone, so a repeated start
+// point is usually a home or workplace left outside one.
+func flagExposedArea(result *ProfileResult, starts [][]float64, activities int) {
+	if activities > 0 && len(starts) == 0 {
+		result.Insights = append(result.Insights, "Activity start points are hidden (privacy zone or private maps)")
+		return
+	}
+
+	var best ExposedArea
+	for _, anchor := range starts {
+		here := &GeoLocation{Lat: anchor[0], Lon: anchor[1]}
+		count := 0
+		for _, start := range starts {
+			if here.DistanceKm(&GeoLocation{Lat: start[0], Lon: start[1]}) <= repeatedStartKm {
+				count++
+			}
+		}
+		if count > best.Activities {
+			best = ExposedArea{Lat: anchor[0], Lon: anchor[1], Activities: count}
+		}
+	}
+	if best.Activities < minRepeatedStarts {
+		return
+	}
+
+	scale := math.Pow(10, exposedAreaDecimal)
+	best.Lat = math.Round(best.Lat*scale) / scale
+	best.Lon = math.Round(best.Lon*scale) / scale
+	result.ExposedArea = &best
+	result.Insights = append(result.Insights, fmt.Sprintf("%d of %d public activities start near %.3f, %.3f, outside any privacy zone: likely home or work", best.Activities, activities, best.Lat, best.Lon))
+}
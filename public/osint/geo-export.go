@@ -0,0 +1,211 @@
+package osint
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GeoFeature is a finding placed on a map
+type GeoFeature struct {
+	Name        string  `json:"name"`
+	Source      string  `json:"source"` // Module or service the coordinates came from
+	Description string  `json:"description,omitempty"`
+	Lat         float64 `json:"lat"`
+	Lon         float64 `json:"lon"`
+}
+
+// geoFeature builds a feature from a [lat, lon] pair, rejecting missing and
+// null-island coordinates
+func geoFeature(name, source, description string, coordinates []float64) (GeoFeature, bool) {
+	if len(coordinates) != 2 || (coordinates[0] == 0 && coordinates[1] == 0) {
+		return GeoFeature{}, false
+	}
+	if coordinates[0] < -90 || coordinates[0] > 90 || coordinates[1] < -180 || coordinates[1] > 180 {
+		return GeoFeature{}, false
+	}
+	return GeoFeature{Name: name, Source: source, Description: description, Lat: coordinates[0], Lon: coordinates[1]}, true
+}
+
+// GeoFeatures places the IP's geolocation
+func (r *IPIntelResult) GeoFeatures() []GeoFeature {
+	var features []GeoFeature
+	if r.Geo != nil {
+		if f, ok := geoFeature(r.IP, "geoip", geoIPDescription(*r.Geo), r.Geo.Coordinates); ok {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+// GeoFeatures places the mail servers of the email's domain
+func (r *EmailAnalysisResult) GeoFeatures() []GeoFeature {
+	var features []GeoFeature
+	name := r.Domain
+	if len(r.DomainInfo.IPAddresses) > 0 {
+		name = r.DomainInfo.IPAddresses[0]
+	}
+	if f, ok := geoFeature(name, "geoip", geoIPDescription(r.DomainInfo.GeoIPInfo), r.DomainInfo.GeoIPInfo.Coordinates); ok {
+		features = append(features, f)
+	}
+	return features
+}
+
+// GeoFeatures places every relay of the message's route
+func (r *HeaderAnalysis) GeoFeatures() []GeoFeature {
+	var features []GeoFeature
+	for i, hop := range r.Route {
+		if hop.Geo == nil {
+			continue
+		}
+		name := fmt.Sprintf("Hop %d: %s", i+1, hop.IP)
+		if f, ok := geoFeature(name, "geoip", geoIPDescription(*hop.Geo), hop.Geo.Coordinates); ok {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+// GeoFeatures places the Maps reviews and photos of a Google account
+func (r *GoogleIDResult) GeoFeatures() []GeoFeature {
+	var features []GeoFeature
+	for _, review := range r.Reviews {
+		description := fmt.Sprintf("%d★ %s", review.Rating, review.ReviewDate)
+		if f, ok := geoFeature(review.Location, "google_maps_review", description, review.Coordinates); ok {
+			features = append(features, f)
+		}
+	}
+	for _, photo := range r.Photos {
+		if f, ok := geoFeature(photo.Location, "google_photo", photo.URL, photo.Coordinates); ok {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+// GeoFeatures places the profiles found at their geocoded locations, the
+// places they reviewed, and the start points fitness activities keep sharing
+func (r *SocialMediaResults) GeoFeatures() []GeoFeature {
+	var features []GeoFeature
+	for _, profile := range r.Profiles {
+		source := strings.ToLower(profile.Platform) + "_review"
+		for _, review := range profile.Reviews {
+			description := review.ReviewDate
+			if review.Rating > 0 {
+				description = fmt.Sprintf("%d★ %s", review.Rating, review.ReviewDate)
+			}
+			if f, ok := geoFeature(review.Location, source, description, review.Coordinates); ok {
+				features = append(features, f)
+			}
+		}
+		if area := profile.ExposedArea; area != nil {
+			name := fmt.Sprintf("%s: %s", profile.Platform, profile.URL)
+			description := fmt.Sprintf("%d public activities start here", area.Activities)
+			if f, ok := geoFeature(name, "activity_start", description, []float64{area.Lat, area.Lon}); ok {
+				features = append(features, f)
+			}
+		}
+		if profile.Geo == nil {
+			continue
+		}
+		name := fmt.Sprintf("%s: %s", profile.Platform, profile.URL)
+		description := fmt.Sprintf("Profile location %q, geocoded to %s", profile.Location, profile.Geo.Label())
+		if f, ok := geoFeature(name, "profile_location", description, []float64{profile.Geo.Lat, profile.Geo.Lon}); ok {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+func geoIPDescription(geo GeoIPInfo) string {
+	description := strings.Trim(strings.Join([]string{geo.City, geo.Region, geo.Country}, ", "), ", ")
+	if geo.ISP != "" {
+		description += " (" + geo.ISP + ")"
+	}
+	return description
+}
+
+// ExportGeo writes features as a KML file when path ends in .kml and as a
+// GeoJSON FeatureCollection otherwise
+func ExportGeo(path, title string, features []GeoFeature) error {
+	var data []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".kml") {
+		data, err = geoKML(title, features)
+	} else {
+		data, err = geoJSON(title, features)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func geoJSON(title string, features []GeoFeature) ([]byte, error) {
+	type feature struct {
+		Type     string `json:"type"`
+		Geometry struct {
+			Type        string     `json:"type"`
+			Coordinates [2]float64 `json:"coordinates"`
+		} `json:"geometry"`
+		Properties GeoFeature `json:"properties"`
+	}
+	collection := struct {
+		Type     string    `json:"type"`
+		Name     string    `json:"name"`
+		Features []feature `json:"features"`
+	}{Type: "FeatureCollection", Name: title, Features: []feature{}}
+
+	for _, f := range features {
+		var out feature
+		out.Type = "Feature"
+		out.Geometry.Type = "Point"
+		out.Geometry.Coordinates = [2]float64{f.Lon, f.Lat} // GeoJSON is longitude first
+		out.Properties = f
+		collection.Features = append(collection.Features, out)
+	}
+	return json.MarshalIndent(collection, "", "  ")
+}
+
+func geoKML(title string, features []GeoFeature) ([]byte, error) {
+	type placemark struct {
+		Name        string `xml:"name"`
+		Description string `xml:"description,omitempty"`
+		Coordinates string `xml:"Point>coordinates"`
+	}
+	type folder struct {
+		Name       string      `xml:"name"`
+		Placemarks []placemark `xml:"Placemark"`
+	}
+	doc := struct {
+		XMLName xml.Name `xml:"kml"`
+		XMLNS   string   `xml:"xmlns,attr"`
+		Name    string   `xml:"Document>name"`
+		Folders []folder `xml:"Document>Folder"`
+	}{XMLNS: "http://www.opengis.net/kml/2.2", Name: title}
+
+	// One folder per source, so layers can be toggled in the map tool
+	index := make(map[string]int)
+	for _, f := range features {
+		i, ok := index[f.Source]
+		if !ok {
+			i = len(doc.Folders)
+			index[f.Source] = i
+			doc.Folders = append(doc.Folders, folder{Name: f.Source})
+		}
+		doc.Folders[i].Placemarks = append(doc.Folders[i].Placemarks, placemark{
+			Name:        f.Name,
+			Description: f.Description,
+			Coordinates: fmt.Sprintf("%g,%g", f.Lon, f.Lat),
+		})
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,248 @@
+package osint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Geocoding options
+var (
+	GeocodeLocations = false                                          // Geocode the free-text locations of profiles found
+	GeocodeCacheFile = filepath.Join("results", "geocode-cache.json") // Resolved places kept across runs; empty disables it
+)
+
+// errNoPlace is returned for locations Nominatim does not know
+var errNoPlace = errors.New("no place found")
+
+// sameAreaKm is how close two places must be to count as the same area
+const sameAreaKm = 50
+
+// nonPlaces are locations people write that are not places
+var nonPlaces = map[string]bool{
+	"earth": true, "planet earth": true, "worldwide": true, "everywhere": true, "internet": true,
+	"the internet": true, "online": true, "remote": true, "global": true, "nowhere": true, "here": true,
+	"home": true, "mars": true, "moon": true, "the moon": true, "localhost": true,
+}
+
+// GeoLocation is a free-text place resolved to a normalized country, region
+// and coordinates
+type GeoLocation struct {
+	Query       string  `json:"query"`
+	DisplayName string  `json:"display_name"`
+	Country     string  `json:"country,omitempty"`
+	CountryCode string  `json:"country_code,omitempty"` // ISO 3166-1 alpha-2, upper case
+	Region      string  `json:"region,omitempty"`
+	City        string  `json:"city,omitempty"`
+	Lat         float64 `json:"lat"`
+	Lon         float64 `json:"lon"`
+}
+
+// LocationCluster groups profiles whose locations fall in the same area
+type LocationCluster struct {
+	Place    string   `json:"place"`
+	Lat      float64  `json:"lat"`
+	Lon      float64  `json:"lon"`
+	Profiles []string `json:"profiles"`
+}
+
+var (
+	geocodeMu     sync.Mutex
+	geocodeCache  map[string]*GeoLocation // nil entries record places Nominatim could not find
+	geocodeLoaded string
+)
+
+// normalizeLocation reduces a location to the key it is cached under
+func normalizeLocation(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// Geocode resolves a free-text location such as "SF Bay Area" with
+// Nominatim, answering repeated places from the cache. Nominatim's rate
+// limit is enforced by the shared provider client.
+func Geocode(ctx context.Context, text string) (*GeoLocation, error) {
+	key := normalizeLocation(text)
+	if key == "" || nonPlaces[key] {
+		return nil, fmt.Errorf("%q is not a place", text)
+	}
+
+	geocodeMu.Lock()
+	loadGeocodeCache()
+	place, cached := geocodeCache[key]
+	geocodeMu.Unlock()
+	if cached {
+		if place == nil {
+			return nil, fmt.Errorf("%w for %q", errNoPlace, text)
+		}
+		return place, nil
+	}
+
+	// Only "not found" answers are cached; failed requests are retried next time
+	place, err := nominatimSearch(ctx, strings.TrimSpace(text))
+	if err != nil && !errors.Is(err, errNoPlace) {
+		return nil, err
+	}
+
+	geocodeMu.Lock()
+	geocodeCache[key] = place
+	saveGeocodeCache()
+	geocodeMu.Unlock()
+	return place, err
+}
+
+// nominatimSearch asks Nominatim for the best match of a location
+func nominatimSearch(ctx context.Context, text string) (*GeoLocation, error) {
+	var places []struct {
+		Lat         string `json:"lat"`
+		Lon         string `json:"lon"`
+		DisplayName string `json:"display_name"`
+		Address     struct {
+			City        string `json:"city"`
+			Town        string `json:"town"`
+			Village     string `json:"village"`
+			State       string `json:"state"`
+			Region      string `json:"region"`
+			Country     string `json:"country"`
+			CountryCode string `json:"country_code"`
+		} `json:"address"`
+	}
+	target := "https://nominatim.openstreetmap.org/search?format=jsonv2&addressdetails=1&accept-language=en&limit=1&q=" + url.QueryEscape(text)
+	if err := getProviderJSON(ctx, target, nil, &places); err != nil {
+		return nil, fmt.Errorf("nominatim: %v", err)
+	}
+	if len(places) == 0 {
+		return nil, fmt.Errorf("%w for %q", errNoPlace, text)
+	}
+
+	found := places[0]
+	lat, errLat := strconv.ParseFloat(found.Lat, 64)
+	lon, errLon := strconv.ParseFloat(found.Lon, 64)
+	if errLat != nil || errLon != nil {
+		return nil, fmt.Errorf("nominatim returned invalid coordinates")
+	}
+	place := &GeoLocation{
+		Query:       text,
+		DisplayName: found.DisplayName,
+		Country:     found.Address.Country,
+		CountryCode: strings.ToUpper(found.Address.CountryCode),
+		Region:      found.Address.State,
+		City:        found.Address.City,
+		Lat:         lat,
+		Lon:         lon,
+	}
+	if place.Region == "" {
+		place.Region = found.Address.Region
+	}
+	for _, city := range []string{found.Address.Town, found.Address.Village} {
+		if place.City == "" {
+			place.City = city
+		}
+	}
+	return place, nil
+}
+
+// loadGeocodeCache reads the cache file once per configured path; callers
+// hold geocodeMu
+func loadGeocodeCache() {
+	if geocodeCache != nil && geocodeLoaded == GeocodeCacheFile {
+		return
+	}
+	geocodeCache = make(map[string]*GeoLocation)
+	geocodeLoaded = GeocodeCacheFile
+	if GeocodeCacheFile == "" {
+		return
+	}
+	if data, err := os.ReadFile(GeocodeCacheFile); err == nil {
+		json.Unmarshal(data, &geocodeCache)
+	}
+}
+
+// saveGeocodeCache writes the cache back; a cache that cannot be written
+// only costs repeated lookups. Callers hold geocodeMu.
+func saveGeocodeCache() {
+	if GeocodeCacheFile == "" {
+		return
+	}
+	data, err := json.MarshalIndent(geocodeCache, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(GeocodeCacheFile), 0755); err != nil {
+		return
+	}
+	tmp := GeocodeCacheFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err == nil {
+		os.Rename(tmp, GeocodeCacheFile)
+	}
+}
+
+// Label names a place by its normalized city, region and country
+func (g *GeoLocation) Label() string {
+	var parts []string
+	for _, part := range []string{g.City, g.Region, g.Country} {
+		if part != "" && (len(parts) == 0 || parts[len(parts)-1] != part) {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return g.DisplayName
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DistanceKm is the great-circle distance between two places
+func (g *GeoLocation) DistanceKm(other *GeoLocation) float64 {
+	const earthRadiusKm = 6371
+	rad := math.Pi / 180
+	dLat := (other.Lat - g.Lat) * rad
+	dLon := (other.Lon - g.Lon) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(g.Lat*rad)*math.Cos(other.Lat*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// SameArea reports whether two places are within sameAreaKm of each other,
+// so "SF Bay Area" and "San Francisco, CA" match
+func (g *GeoLocation) SameArea(other *GeoLocation) bool {
+	return g.DistanceKm(other) <= sameAreaKm
+}
+
+// ClusterProfileLocations groups geocoded profiles by area, largest group
+// first, so profiles placed in the same city under different spellings can
+// be linked
+func ClusterProfileLocations(profiles []ProfileResult) []LocationCluster {
+	var clusters []LocationCluster
+	var anchors []*GeoLocation
+	for _, profile := range profiles {
+		if profile.Geo == nil {
+			continue
+		}
+		joined := false
+		for i, anchor := range anchors {
+			if anchor.SameArea(profile.Geo) {
+				clusters[i].Profiles = append(clusters[i].Profiles, profile.URL)
+				joined = true
+				break
+			}
+		}
+		if !joined {
+			anchors = append(anchors, profile.Geo)
+			clusters = append(clusters, LocationCluster{
+				Place:    profile.Geo.Label(),
+				Lat:      profile.Geo.Lat,
+				Lon:      profile.Geo.Lon,
+				Profiles: []string{profile.URL},
+			})
+		}
+	}
+	sort.SliceStable(clusters, func(i, j int) bool { return len(clusters[i].Profiles) > len(clusters[j].Profiles) })
+	return clusters
+}
@@ -0,0 +1,428 @@
+package osint
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// DefaultRepoCount is how many recently pushed repositories ScanRepos reads
+const DefaultRepoCount = 10
+
+const (
+	// MaxRepoCount caps the repositories read in one scan
+	MaxRepoCount = 100
+	// maxRepoArchiveSize caps the download of one repository's files
+	maxRepoArchiveSize = int64(100 << 20)
+	// maxRepoFileSize skips files too large to be code or configuration
+	maxRepoFileSize = int64(1 << 20)
+	// maxFileSecrets caps the credentials reported from one file
+	maxFileSecrets = 5
+	// repoScanTimeout bounds the download and scan of one repository
+	repoScanTimeout = 3 * time.Minute
+	// minSecretEntropy is the Shannon entropy, in bits per character, a value
+	// assigned to a credential-like name needs before it is reported
+	minSecretEntropy = 3.5
+)
+
+var (
+	githubOwnerRegex = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,38})$`)
+	// secretAssignmentRegex matches a quoted value assigned to a name that
+	// suggests a credential, such as api_key = "..."
+	secretAssignmentRegex = regexp.MustCompile(`(?i)(?:api[_-]?key|secret|token|passw(?:or)?d|access[_-]?key|auth[_-]?key)[a-z0-9_-]*["']?\s*[:=]\s*["']([A-Za-z0-9+/=_\-.]{16,})["']`)
+	// placeholderSecretRegex matches the stand-ins documentation and tests use
+	// for real credentials
+	placeholderSecretRegex = regexp.MustCompile(`(?i)example|sample|dummy|placeholder|changeme|your|fake|redacted|x{6}|0{8}`)
+)
+
+// skippedRepoDirs hold third-party code or build output rather than the
+// repository's own files
+var skippedRepoDirs = []string{"node_modules/", "vendor/", "dist/", "third_party/", ".git/"}
+
+// GitRepo is a repository ScanRepos read
+type GitRepo struct {
+	Name         string `json:"name"`
+	URL          string `json:"url"`
+	Branch       string `json:"branch"`
+	PushedAt     string `json:"pushed_at"`
+	FilesScanned int    `json:"files_scanned"`
+	CommitsRead  int    `json:"commits_read"`
+}
+
+// LeakedSecret is a credential committed to a repository, masked
+type LeakedSecret struct {
+	Repo    string  `json:"repo"`
+	Path    string  `json:"path"`
+	Line    int     `json:"line"`
+	Kind    string  `json:"kind"`
+	Value   string  `json:"value"`
+	Entropy float64 `json:"entropy,omitempty"` // Of values found by their name rather than their format
+	URL     string  `json:"url"`
+}
+
+// RepoContributor is a commit author or committer, by email address
+type RepoContributor struct {
+	Email      string   `json:"email"`
+	Names      []string `json:"names"`
+	Commits    int      `json:"commits"`
+	Repos      []string `json:"repos"`
+	LastCommit string   `json:"last_commit,omitempty"`
+}
+
+// RepoScanResult is what the public repositories of a GitHub organization or
+// user expose: credentials in their files and the addresses they commit from
+type RepoScanResult struct {
+	Owner         string            `json:"owner"`
+	ScanID        string            `json:"scan_id,omitempty"`
+	Timestamp     string            `json:"timestamp"`
+	Repos         []GitRepo         `json:"repos"`
+	Secrets       []LeakedSecret    `json:"secrets"`
+	Contributors  []RepoContributor `json:"contributors"`
+	PartialErrors []ModuleError     `json:"partial_errors,omitempty"`
+	ExecutionTime string            `json:"execution_time"`
+}
+
+// githubRepo is the part of GitHub's repository API response that is used
+type githubRepo struct {
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	HTMLURL       string `json:"html_url"`
+	DefaultBranch string `json:"default_branch"`
+	PushedAt      string `json:"pushed_at"`
+	Fork          bool   `json:"fork"`
+}
+
+// githubCommit is the part of GitHub's commit API response that is used
+type githubCommit struct {
+	Commit struct {
+		Author    githubCommitPerson `json:"author"`
+		Committer githubCommitPerson `json:"committer"`
+	} `json:"commit"`
+}
+
+type githubCommitPerson struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Date  string `json:"date"`
+}
+
+// ScanRepos reads the most recently pushed repositories of a GitHub
+// organization or user, forks aside. The files on each default branch are
+// searched for credentials, by format or by a high-entropy value assigned to
+// a credential-like name, and the last 100 commits give the contributors'
+// email addresses.
+func ScanRepos(ctx context.Context, owner string, count int) (*RepoScanResult, error) {
+	startTime := time.Now()
+	owner = strings.TrimPrefix(strings.TrimSpace(owner), "@")
+	if !githubOwnerRegex.MatchString(owner) {
+		return nil, fmt.Errorf("%q is not a GitHub user or organization name", owner)
+	}
+	if count < 1 || count > MaxRepoCount {
+		return nil, fmt.Errorf("repository count must be between 1 and %d", MaxRepoCount)
+	}
+
+	var listed []githubRepo
+	err := getProviderJSON(ctx, fmt.Sprintf("https://api.github.com/users/%s/repos?sort=pushed&per_page=%d", url.PathEscape(owner), MaxRepoCount), githubHeaders(), &listed)
+	switch {
+	case providers.IsStatus(err, http.StatusNotFound):
+		return nil, fmt.Errorf("no GitHub user or organization named %s", owner)
+	case err != nil:
+		return nil, fmt.Errorf("listing repositories: %v", err)
+	}
+
+	result := &RepoScanResult{
+		Owner:        owner,
+		ScanID:       providers.ScanIDFrom(ctx),
+		Timestamp:    startTime.Format(time.RFC3339),
+		Repos:        []GitRepo{},
+		Secrets:      []LeakedSecret{},
+		Contributors: []RepoContributor{},
+	}
+	var repos []githubRepo
+	for _, repo := range listed {
+		if !repo.Fork && len(repos) < count {
+			repos = append(repos, repo)
+		}
+	}
+
+	var (
+		mu           sync.Mutex
+		wg           sync.WaitGroup
+		sem          = make(chan struct{}, 4)
+		contributors = make(map[string]*RepoContributor)
+	)
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repo githubRepo) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			scanned := GitRepo{Name: repo.Name, URL: repo.HTMLURL, Branch: repo.DefaultBranch, PushedAt: repo.PushedAt}
+			secrets, files, archiveErr := scanRepoArchive(ctx, repo)
+			commits, commitsErr := repoCommits(ctx, repo)
+			scanned.FilesScanned, scanned.CommitsRead = files, len(commits)
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Repos = append(result.Repos, scanned)
+			result.Secrets = append(result.Secrets, secrets...)
+			if archiveErr != nil {
+				result.PartialErrors = append(result.PartialErrors, ModuleError{Module: "repos." + repo.Name, Error: archiveErr.Error()})
+			}
+			if commitsErr != nil {
+				result.PartialErrors = append(result.PartialErrors, ModuleError{Module: "repos." + repo.Name + ".commits", Error: commitsErr.Error()})
+			}
+			for _, commit := range commits {
+				addContributor(contributors, repo.Name, commit.Commit.Author)
+				if commit.Commit.Committer.Email != commit.Commit.Author.Email {
+					addContributor(contributors, repo.Name, commit.Commit.Committer)
+				}
+			}
+		}(repo)
+	}
+	wg.Wait()
+
+	for _, contributor := range contributors {
+		sort.Strings(contributor.Repos)
+		result.Contributors = append(result.Contributors, *contributor)
+	}
+	sort.Slice(result.Contributors, func(i, j int) bool {
+		if result.Contributors[i].Commits != result.Contributors[j].Commits {
+			return result.Contributors[i].Commits > result.Contributors[j].Commits
+		}
+		return result.Contributors[i].Email < result.Contributors[j].Email
+	})
+	sort.Slice(result.Repos, func(i, j int) bool { return result.Repos[i].PushedAt > result.Repos[j].PushedAt })
+	sort.SliceStable(result.Secrets, func(i, j int) bool {
+		if result.Secrets[i].Repo != result.Secrets[j].Repo {
+			return result.Secrets[i].Repo < result.Secrets[j].Repo
+		}
+		return result.Secrets[i].Path < result.Secrets[j].Path
+	})
+
+	result.ExecutionTime = time.Since(startTime).String()
+	return result, ctx.Err()
+}
+
+// repoCommits returns the last 100 commits of a repository's default branch
+func repoCommits(ctx context.Context, repo githubRepo) ([]githubCommit, error) {
+	var commits []githubCommit
+	err := getProviderJSON(ctx, "https://api.github.com/repos/"+repo.FullName+"/commits?per_page=100", githubHeaders(), &commits)
+	// An empty repository has no branch to list
+	if providers.IsStatus(err, http.StatusConflict) {
+		return nil, nil
+	}
+	return commits, err
+}
+
+// addContributor counts a commit for its author or committer. GitHub's
+// noreply addresses stand in for hidden ones and identify no mailbox.
+func addContributor(contributors map[string]*RepoContributor, repo string, person githubCommitPerson) {
+	email := strings.ToLower(strings.TrimSpace(person.Email))
+	if !strings.Contains(email, "@") || strings.HasSuffix(email, "noreply.github.com") || email == "noreply@github.com" {
+		return
+	}
+	contributor, ok := contributors[email]
+	if !ok {
+		contributor = &RepoContributor{Email: email}
+		contributors[email] = contributor
+	}
+	contributor.Commits++
+	if person.Name != "" && !slices.Contains(contributor.Names, person.Name) {
+		contributor.Names = append(contributor.Names, person.Name)
+	}
+	if !slices.Contains(contributor.Repos, repo) {
+		contributor.Repos = append(contributor.Repos, repo)
+	}
+	if person.Date > contributor.LastCommit {
+		contributor.LastCommit = person.Date
+	}
+}
+
+// scanRepoArchive downloads a repository's default branch as a tarball and
+// searches its text files for credentials, returning how many it read
+func scanRepoArchive(ctx context.Context, repo githubRepo) ([]LeakedSecret, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, repoScanTimeout)
+	defer cancel()
+
+	archiveURL := fmt.Sprintf("https://codeload.github.com/%s/tar.gz/refs/heads/%s", repo.FullName, repo.DefaultBranch)
+	req, err := http.NewRequestWithContext(ctx, "GET", archiveURL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	// The download can outlast the per-request timeout, so only the
+	// repository's own deadline bounds it
+	resp, err := (&http.Client{Transport: providers.Transport}).Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("downloading files: status %d", resp.StatusCode)
+	}
+
+	limited := &io.LimitedReader{R: resp.Body, N: maxRepoArchiveSize}
+	gz, err := gzip.NewReader(limited)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading files: %v", err)
+	}
+	archive := tar.NewReader(gz)
+
+	var secrets []LeakedSecret
+	files := 0
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if limited.N <= 0 {
+				err = fmt.Errorf("larger than %d MB", maxRepoArchiveSize>>20)
+			}
+			return secrets, files, fmt.Errorf("reading files: %v, scanned %d", err, files)
+		}
+		// Entries sit under an owner-repo-commit directory
+		_, path, _ := strings.Cut(header.Name, "/")
+		if header.Typeflag != tar.TypeReg || header.Size > maxRepoFileSize || skippedRepoPath(path) {
+			continue
+		}
+		content, err := io.ReadAll(archive)
+		if err != nil {
+			return secrets, files, fmt.Errorf("reading %s: %v", path, err)
+		}
+		// A NUL byte early on marks a binary file
+		if bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0 {
+			continue
+		}
+		files++
+		fileURL := repo.HTMLURL + "/blob/" + repo.DefaultBranch + "/" + path
+		secrets = append(secrets, scanFileSecrets(repo.Name, path, fileURL, content)...)
+	}
+	return secrets, files, nil
+}
+
+// skippedRepoPath reports whether a file is third-party code or build output
+func skippedRepoPath(path string) bool {
+	for _, dir := range skippedRepoDirs {
+		if strings.HasPrefix(path, dir) || strings.Contains(path, "/"+dir) {
+			return true
+		}
+	}
+	return strings.HasSuffix(path, ".min.js") || strings.HasSuffix(path, ".map")
+}
+
+// scanFileSecrets looks for credentials in a file line by line, by their
+// format and by high-entropy values assigned to credential-like names
+func scanFileSecrets(repo, path, fileURL string, content []byte) []LeakedSecret {
+	var secrets []LeakedSecret
+	seen := make(map[string]bool)
+	add := func(line int, kind, value string, entropy float64) {
+		if seen[value] || len(secrets) >= maxFileSecrets || placeholderSecretRegex.MatchString(value) {
+			return
+		}
+		seen[value] = true
+		secrets = append(secrets, LeakedSecret{
+			Repo:    repo,
+			Path:    path,
+			Line:    line,
+			Kind:    kind,
+			Value:   maskSecret(value),
+			Entropy: math.Round(entropy*100) / 100,
+			URL:     fmt.Sprintf("%s#L%d", fileURL, line),
+		})
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), int(maxRepoFileSize))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		for _, pattern := range secretPatterns {
+			for _, match := range pattern.regex.FindAllString(text, maxFileSecrets) {
+				add(line, pattern.kind, match, 0)
+			}
+		}
+		for _, match := range secretAssignmentRegex.FindAllStringSubmatch(text, maxFileSecrets) {
+			if entropy := shannonEntropy(match[1]); entropy >= minSecretEntropy {
+				add(line, "High-entropy secret", match[1], entropy)
+			}
+		}
+	}
+	// A line longer than the buffer, such as minified code, ends the scan of
+	// the file with what was found before it
+	return secrets
+}
+
+// shannonEntropy returns the bits of information per character of a string
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// DisplayResults prints the credentials found, then the contributors
+func (r *RepoScanResult) DisplayResults() {
+	color.Cyan("\n=== GITHUB REPOSITORIES: %s ===", r.Owner)
+	files, commits := 0, 0
+	for _, repo := range r.Repos {
+		files += repo.FilesScanned
+		commits += repo.CommitsRead
+	}
+	color.Yellow("Repositories scanned: %d (%d files, %d commits)", len(r.Repos), files, commits)
+
+	if len(r.Secrets) == 0 {
+		color.Green("\nNo credentials found in the repositories' files")
+	} else {
+		color.Red("\nLeaked credentials: %d", len(r.Secrets))
+		for _, secret := range r.Secrets {
+			color.Red("  [%s] %s/%s:%d  %s", secret.Kind, secret.Repo, secret.Path, secret.Line, secret.Value)
+			fmt.Printf("      %s\n", secret.URL)
+		}
+	}
+
+	if len(r.Contributors) == 0 {
+		color.Yellow("\nNo contributor email addresses found in the commits")
+	} else {
+		color.Cyan("\nContributors: %d", len(r.Contributors))
+		for _, contributor := range r.Contributors {
+			color.Green("  %s (%s)", contributor.Email, strings.Join(contributor.Names, ", "))
+			fmt.Printf("      %d commits in %s, last %s\n", contributor.Commits, strings.Join(contributor.Repos, ", "), contributor.LastCommit)
+		}
+	}
+	fmt.Printf("\nExecution time: %s\n", r.ExecutionTime)
+}
+
+// DisplayPartialErrors prints the repositories that could not be read in full
+func (r *RepoScanResult) DisplayPartialErrors() {
+	displayModuleErrors(r.PartialErrors)
+}
@@ -6,9 +6,16 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/awion/MercuriesOST/public/parse"
+	"github.com/awion/MercuriesOST/public/providers"
+	"golang.org/x/sync/errgroup"
 )
 
 // LinkStatus represents the availability status of a resource
@@ -19,18 +26,23 @@ const (
 	StatusNotFound   LinkStatus = "NOT_FOUND"
 	StatusRestricted LinkStatus = "RESTRICTED"
 	StatusError      LinkStatus = "ERROR"
+	StatusUnchecked  LinkStatus = "UNCHECKED"
 )
 
 // ProfileURL represents a Google service URL with availability status
 type ProfileURL struct {
-	URL     string     `json:"url"`
-	Status  LinkStatus `json:"status"`
-	Message string     `json:"message,omitempty"`
+	URL         string     `json:"url"`
+	Status      LinkStatus `json:"status"`
+	Message     string     `json:"message,omitempty"`
+	DisplayName string     `json:"display_name,omitempty"`
 }
 
 // GoogleIDResult represents the collected data from a Google ID search
 type GoogleIDResult struct {
 	GoogleID      string                 `json:"google_id"`
+	ScanID        string                 `json:"scan_id,omitempty"`
+	DisplayName   string                 `json:"display_name,omitempty"`
+	AvatarURL     string                 `json:"avatar_url,omitempty"`
 	ProfileURLs   map[string]ProfileURL  `json:"profile_urls"`
 	Contributions ContributionInfo       `json:"contributions"`
 	Reviews       []ReviewInfo           `json:"reviews"`
@@ -38,6 +50,7 @@ type GoogleIDResult struct {
 	Photos        []PhotoInfo            `json:"photos"`
 	LastSeen      string                 `json:"last_seen"`
 	Metadata      map[string]interface{} `json:"metadata"`
+	Keywords      *KeywordReport         `json:"keyword_hits,omitempty"`
 }
 
 // ContributionInfo represents Google Maps contribution data
@@ -47,15 +60,18 @@ type ContributionInfo struct {
 	TotalRatings    int    `json:"total_ratings"`
 	ContributorRank string `json:"contributor_rank"`
 	LastActivity    string `json:"last_activity"`
+	DisplayName     string `json:"display_name,omitempty"`
+	AvatarURL       string `json:"avatar_url,omitempty"`
 }
 
 // ReviewInfo represents a Google review
 type ReviewInfo struct {
-	Location    string    `json:"location"`
-	Rating      int       `json:"rating"`
-	ReviewText  string    `json:"review_text"`
-	ReviewDate  string    `json:"review_date"`
-	Coordinates []float64 `json:"coordinates,omitempty"`
+	Location    string       `json:"location"`
+	Rating      int          `json:"rating"`
+	ReviewText  string       `json:"review_text"`
+	ReviewDate  string       `json:"review_date"`
+	Coordinates []float64    `json:"coordinates,omitempty"`
+	Translation *Translation `json:"translation,omitempty"` // The review in TranslateTo when written in another language
 }
 
 // ArchiveInfo represents archived Google+ data
@@ -82,23 +98,22 @@ type HTTPClient interface {
 
 // AnalyzeGoogleID performs comprehensive analysis of a Google ID
 func AnalyzeGoogleID(ctx context.Context, googleID string) (*GoogleIDResult, error) {
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			// Store redirect URLs for analysis
-			if len(via) >= 10 {
-				return fmt.Errorf("too many redirects")
-			}
-			return nil
-		},
-	}
+	return AnalyzeGoogleIDWithClient(ctx, googleID, providerRouter{})
+}
 
-	return AnalyzeGoogleIDWithClient(ctx, googleID, client)
+// providerRouter sends each request through the shared, rate-limited client
+// of the service it targets, so Google pages and Archive.org captures are
+// paced and retried like the APIs
+type providerRouter struct{}
+
+func (providerRouter) Do(req *http.Request) (*http.Response, error) {
+	return doProviderRequest(req)
 }
 
 // AnalyzeGoogleIDWithClient performs analysis with a custom HTTP client (useful for testing)
 func AnalyzeGoogleIDWithClient(ctx context.Context, googleID string, client HTTPClient) (*GoogleIDResult, error) {
 	result := &GoogleIDResult{
+		ScanID:      providers.ScanIDFrom(ctx),
 		GoogleID:    googleID,
 		ProfileURLs: make(map[string]ProfileURL),
 		Metadata:    make(map[string]interface{}),
@@ -114,6 +129,8 @@ func AnalyzeGoogleIDWithClient(ctx context.Context, googleID string, client HTTP
 		"scholar":      fmt.Sprintf("https://scholar.google.com/citations?user=%s", googleID),
 		"picasa":       fmt.Sprintf("https://picasaweb.google.com/%s", googleID),
 		"blogger":      fmt.Sprintf("https://www.blogger.com/profile/%s", googleID),
+		"play_games":   fmt.Sprintf("https://play.games.google.com/profile/%s", googleID),
+		"developers":   fmt.Sprintf("https://developers.google.com/profile/u/%s", googleID),
 	}
 
 	// Check each service URL concurrently
@@ -143,10 +160,12 @@ func AnalyzeGoogleIDWithClient(ctx context.Context, googleID string, client HTTP
 	for i := 0; i < len(services); i++ {
 		serviceResult := <-serviceChan
 		url := services[serviceResult.name]
+		status, displayName := evaluateServiceContent(serviceResult.name, serviceResult.result.Status, serviceResult.result.Message)
 		result.ProfileURLs[serviceResult.name] = ProfileURL{
-			URL:     url,
-			Status:  checkURLContent(serviceResult.result.Status, serviceResult.result.Message),
-			Message: sanitizeMessage(serviceResult.result.Message),
+			URL:         url,
+			Status:      status,
+			Message:     sanitizeMessage(serviceResult.result.Message),
+			DisplayName: displayName,
 		}
 	}
 
@@ -212,6 +231,20 @@ func AnalyzeGoogleIDWithClient(ctx context.Context, googleID string, client HTTP
 	// Set last seen timestamp
 	result.LastSeen = findLastActivity(result)
 
+	// Promote the public display name and avatar to the top level of the result
+	resolveIdentity(result)
+
+	translator := NewTranslator()
+	translateReviews(ctx, translator, result.Reviews)
+	if err := translator.Err(); err != nil {
+		errStrings = append(errStrings, err.Error())
+	}
+
+	if keywords := NewKeywordWatch(); keywords != nil {
+		keywords.Scan("", result)
+		result.Keywords = keywords.Report()
+	}
+
 	if len(errStrings) > 0 {
 		return result, fmt.Errorf("partial data collection completed with errors: %s", strings.Join(errStrings, "; "))
 	}
@@ -303,6 +336,89 @@ func checkURLContent(status LinkStatus, content string) LinkStatus {
 	return StatusAvailable
 }
 
+// serviceCheck holds service-specific markers used instead of the generic content heuristics
+type serviceCheck struct {
+	existMarkers    []string
+	notFoundMarkers []string
+	titleSuffixes   []string
+}
+
+// serviceChecks maps service names to their content-aware existence checks.
+// Services without an entry fall back to checkURLContent.
+var serviceChecks = map[string]serviceCheck{
+	"play_games": {
+		existMarkers:    []string{"play.games.google.com/profile", "gamer-profile", "Achievements"},
+		notFoundMarkers: []string{"profile is private", "profile not found", "couldn't find that profile"},
+		titleSuffixes:   []string{" - Google Play Games", " | Google Play Games"},
+	},
+	"developers": {
+		existMarkers:    []string{"devsite-profile", "Google Developer Profile", "badges"},
+		notFoundMarkers: []string{"profile not found", "this profile is private", "was not found on this server"},
+		titleSuffixes:   []string{" | Google Developers", " - Google Developers", " | Google for Developers"},
+	},
+}
+
+var (
+	ogTitleRegex   = regexp.MustCompile(`<meta[^>]+property=["']og:title["'][^>]+content=["']([^"']+)["']`)
+	pageTitleRegex = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+)
+
+// evaluateServiceContent determines the status of a service URL and extracts a display name when present
+func evaluateServiceContent(service string, status LinkStatus, content string) (LinkStatus, string) {
+	check, ok := serviceChecks[service]
+	if !ok {
+		return checkURLContent(status, content), ""
+	}
+
+	if status != StatusAvailable {
+		return status, ""
+	}
+
+	lowerContent := strings.ToLower(content)
+	for _, marker := range check.notFoundMarkers {
+		if strings.Contains(lowerContent, strings.ToLower(marker)) {
+			return StatusNotFound, ""
+		}
+	}
+
+	// Require at least one positive marker, generic 200 pages are common on these services
+	found := false
+	for _, marker := range check.existMarkers {
+		if strings.Contains(lowerContent, strings.ToLower(marker)) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return StatusNotFound, ""
+	}
+
+	return StatusAvailable, extractDisplayName(content, check.titleSuffixes)
+}
+
+// extractDisplayName pulls a display name from og:title or the page/feed title
+func extractDisplayName(content string, suffixes []string) string {
+	name := ""
+	if matches := ogTitleRegex.FindStringSubmatch(content); len(matches) > 1 {
+		name = matches[1]
+	} else if matches := pageTitleRegex.FindStringSubmatch(content); len(matches) > 1 {
+		name = matches[1]
+	}
+
+	name = cleanText(name)
+	for _, suffix := range suffixes {
+		name = strings.TrimSuffix(name, suffix)
+	}
+
+	// Ignore generic service titles that don't identify a person
+	switch strings.ToLower(name) {
+	case "google play games", "google developers", "google for developers":
+		return ""
+	}
+
+	return strings.TrimSpace(name)
+}
+
 // sanitizeMessage removes sensitive information from error messages
 func sanitizeMessage(message string) string {
 	if len(message) > 100 {
@@ -338,23 +454,19 @@ func analyzeMapsContributions(ctx context.Context, client HTTPClient, googleID s
 		return info, fmt.Errorf("maps profile returned status %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readPageBody(resp)
 	if err != nil {
 		return info, err
 	}
 
-	bodyStr := string(body)
+	bodyStr := body.String()
 
-	// Extract review count using regex
-	reviewCountRegex := regexp.MustCompile(`(\d+)\s+reviews`)
-	if matches := reviewCountRegex.FindStringSubmatch(bodyStr); len(matches) > 1 {
-		fmt.Sscanf(matches[1], "%d", &info.TotalReviews)
+	// Extract review and photo counts, written as "1,204 reviews" or "1.2K photos"
+	if count, err := parse.QuantityBefore(bodyStr, "reviews"); err == nil {
+		info.TotalReviews = int(count)
 	}
-
-	// Extract photo count using regex
-	photoCountRegex := regexp.MustCompile(`(\d+)\s+photos`)
-	if matches := photoCountRegex.FindStringSubmatch(bodyStr); len(matches) > 1 {
-		fmt.Sscanf(matches[1], "%d", &info.TotalPhotos)
+	if count, err := parse.QuantityBefore(bodyStr, "photos"); err == nil {
+		info.TotalPhotos = int(count)
 	}
 
 	// Extract contributor level (Local Guide level)
@@ -363,87 +475,227 @@ func analyzeMapsContributions(ctx context.Context, client HTTPClient, googleID s
 		info.ContributorRank = "Level " + matches[1]
 	}
 
+	// Extract public display name and avatar, often the only name tied to a GAIA ID
+	info.DisplayName = extractMapsDisplayName(bodyStr)
+	info.AvatarURL = extractMapsAvatar(bodyStr)
+
 	// Set last activity to current time as approximation since we can't reliably get it
 	info.LastActivity = time.Now().Format(time.RFC3339)
 
 	return info, nil
 }
 
+var (
+	mapsContribTitleRegex = regexp.MustCompile(`(?i)Contributions by ([^"<|]+)`)
+	mapsOGImageRegex      = regexp.MustCompile(`<meta[^>]+(?:property|itemprop)=["'](?:og:image|image)["'][^>]+content=["']([^"']+)["']`)
+	mapsAvatarRegex       = regexp.MustCompile(`"(https://lh[3-6]\.googleusercontent\.com/a[-/][^"\\]+)"`)
+	mapsTitleSuffixes     = []string{" - Google Maps", " – Google Maps"}
+)
+
+// extractMapsDisplayName parses the contributor's public name from the Maps contributor page
+func extractMapsDisplayName(body string) string {
+	if matches := mapsContribTitleRegex.FindStringSubmatch(body); len(matches) > 1 {
+		name := cleanText(matches[1])
+		for _, suffix := range mapsTitleSuffixes {
+			name = strings.TrimSuffix(name, suffix)
+		}
+		return strings.TrimSpace(name)
+	}
+
+	name := extractDisplayName(body, mapsTitleSuffixes)
+	if strings.EqualFold(name, "Google Maps") {
+		return ""
+	}
+	return name
+}
+
+// extractMapsAvatar parses the contributor's avatar URL from the Maps contributor page
+func extractMapsAvatar(body string) string {
+	if matches := mapsOGImageRegex.FindStringSubmatch(body); len(matches) > 1 &&
+		strings.Contains(matches[1], "googleusercontent.com") {
+		return matches[1]
+	}
+	if matches := mapsAvatarRegex.FindStringSubmatch(body); len(matches) > 1 {
+		return matches[1]
+	}
+	return ""
+}
+
+// resolveIdentity sets the result display name and avatar, preferring Maps data over other services
+func resolveIdentity(result *GoogleIDResult) {
+	result.DisplayName = result.Contributions.DisplayName
+	result.AvatarURL = result.Contributions.AvatarURL
+
+	if result.DisplayName == "" {
+		services := make([]string, 0, len(result.ProfileURLs))
+		for service := range result.ProfileURLs {
+			services = append(services, service)
+		}
+		sort.Strings(services)
+
+		for _, service := range services {
+			if name := result.ProfileURLs[service].DisplayName; name != "" {
+				result.DisplayName = name
+				break
+			}
+		}
+	}
+}
+
+// Archive.org CDX query settings
+var (
+	ArchiveCaptureLimit = 500   // Maximum number of captures kept, newest first
+	ArchiveStatusChecks = 10    // Number of most recent captures whose availability is verified
+	archivePageSize     = 100   // Captures requested per CDX page
+	archiveScanCeiling  = 10000 // Hard cap on rows read while paging through the index
+	archiveCheckWorkers = 5     // Concurrent availability checks
+)
+
 // analyzeArchiveData checks Archive.org for Google+ history
 func analyzeArchiveData(ctx context.Context, client HTTPClient, googleID string) ([]ArchiveInfo, error) {
 	archives := []ArchiveInfo{}
 
-	// Construct Archive.org API URL
-	url := fmt.Sprintf("https://web.archive.org/cdx/search/cdx?url=plus.google.com/%s&output=json", googleID)
+	// Prefix queries come back ordered by URL rather than date, so the whole
+	// index is read before choosing which captures are the most recent
+	resumeKey := ""
+	scanned := 0
+	for scanned < archiveScanCeiling {
+		rows, nextKey, err := fetchCDXPage(ctx, googleID, archivePageSize, resumeKey)
+		if err != nil {
+			if len(archives) > 0 {
+				break // Keep what earlier pages returned
+			}
+			return archives, err
+		}
+		scanned += len(rows)
+
+		for _, row := range rows {
+			if archive, ok := parseCDXRow(row); ok {
+				archives = append(archives, archive)
+			}
+		}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if nextKey == "" || len(rows) == 0 {
+			break
+		}
+		resumeKey = nextKey
+	}
+
+	// Most recent captures first
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].ArchiveDate > archives[j].ArchiveDate
+	})
+	if ArchiveCaptureLimit >= 0 && len(archives) > ArchiveCaptureLimit {
+		archives = archives[:ArchiveCaptureLimit]
+	}
+
+	// Only verify availability of the N most recent captures
+	g, checkCtx := errgroup.WithContext(ctx)
+	g.SetLimit(archiveCheckWorkers)
+	for i := range archives {
+		if i >= ArchiveStatusChecks {
+			archives[i].Status = StatusUnchecked
+			continue
+		}
+		i := i
+		g.Go(func() error {
+			archives[i].Status, _ = checkURLStatus(checkCtx, client, archives[i].URL)
+			return nil
+		})
+	}
+	g.Wait()
+
+	return archives, nil
+}
+
+// fetchCDXPage requests a single page of CDX results and returns the data rows and the resume key
+func fetchCDXPage(ctx context.Context, googleID string, limit int, resumeKey string) ([][]string, string, error) {
+	params := url.Values{}
+	params.Set("url", fmt.Sprintf("plus.google.com/%s", googleID))
+	params.Set("matchType", "prefix")
+	params.Set("output", "json")
+	params.Set("collapse", "digest")
+	params.Set("filter", "statuscode:200")
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("showResumeKey", "true")
+	if resumeKey != "" {
+		params.Set("resumeKey", resumeKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://web.archive.org/cdx/search/cdx?"+params.Encode(), nil)
 	if err != nil {
-		return archives, err
+		return nil, "", err
 	}
+	req.Header.Set("User-Agent", UserAgent)
 
-	resp, err := client.Do(req)
+	resp, err := providers.For("Archive.org").Do(req)
 	if err != nil {
-		return archives, err
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return archives, fmt.Errorf("archive.org API returned status %d", resp.StatusCode)
+		return nil, "", fmt.Errorf("archive.org API returned status %d", resp.StatusCode)
 	}
 
-	// Parse archive data
 	var rawData [][]string
 	if err := json.NewDecoder(resp.Body).Decode(&rawData); err != nil {
-		return archives, err
+		return nil, "", err
 	}
 
 	// The first row contains column headers, skip it
 	if len(rawData) <= 1 {
-		return archives, nil // No archive data found
+		return nil, "", nil // No archive data found
 	}
 
-	// Process archive entries (skip header row)
-	for i := 1; i < len(rawData); i++ {
-		if len(rawData[i]) < 5 {
-			continue // Skip invalid rows
-		}
-
-		timeStampStr := rawData[i][1]
-		originalURL := rawData[i][2]
-		// Removed unused mimeType variable
-
-		// Convert timestamp to readable date
-		timestamp, err := time.Parse("20060102150405", timeStampStr)
-		if err != nil {
-			continue // Skip invalid timestamps
-		}
-
-		archiveURL := fmt.Sprintf("https://web.archive.org/web/%s/%s", timeStampStr, originalURL)
-
-		// Determine content type
-		var contentType string
-		if strings.Contains(originalURL, "/posts/") {
-			contentType = "Post"
-		} else if strings.Contains(originalURL, "/photos/") {
-			contentType = "Photo"
-		} else if strings.Contains(originalURL, "/about") {
-			contentType = "Profile"
-		} else {
-			contentType = "Page"
+	// A resume key is appended after an empty separator row
+	rows := rawData[1:]
+	nextKey := ""
+	for i, row := range rows {
+		if len(row) == 0 {
+			if i+1 < len(rows) && len(rows[i+1]) > 0 {
+				nextKey = rows[i+1][0]
+			}
+			rows = rows[:i]
+			break
 		}
+	}
 
-		// Check if this archive URL is available
-		status, _ := checkURLStatus(ctx, client, archiveURL)
+	return rows, nextKey, nil
+}
 
-		archives = append(archives, ArchiveInfo{
-			URL:         archiveURL,
-			ArchiveDate: timestamp.Format(time.RFC3339),
-			Type:        contentType,
-			Status:      status,
-		})
+// parseCDXRow converts a CDX data row into an ArchiveInfo without checking its availability
+func parseCDXRow(row []string) (ArchiveInfo, bool) {
+	if len(row) < 5 {
+		return ArchiveInfo{}, false // Skip invalid rows
 	}
 
-	return archives, nil
+	timeStampStr := row[1]
+	originalURL := row[2]
+
+	// Convert timestamp to readable date
+	timestamp, err := time.Parse("20060102150405", timeStampStr)
+	if err != nil {
+		return ArchiveInfo{}, false // Skip invalid timestamps
+	}
+
+	// Determine content type
+	var contentType string
+	if strings.Contains(originalURL, "/posts/") {
+		contentType = "Post"
+	} else if strings.Contains(originalURL, "/photos/") {
+		contentType = "Photo"
+	} else if strings.Contains(originalURL, "/about") {
+		contentType = "Profile"
+	} else {
+		contentType = "Page"
+	}
+
+	return ArchiveInfo{
+		URL:         fmt.Sprintf("https://web.archive.org/web/%s/%s", timeStampStr, originalURL),
+		ArchiveDate: timestamp.Format(time.RFC3339),
+		Type:        contentType,
+	}, true
 }
 
 // analyzePhotoContributions gathers Google Photos/Albums data
@@ -470,12 +722,12 @@ func analyzePhotoContributions(ctx context.Context, client HTTPClient, googleID
 		return photos, fmt.Errorf("album archive returned status %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readPageBody(resp)
 	if err != nil {
 		return photos, err
 	}
 
-	bodyStr := string(body)
+	bodyStr := body.String()
 
 	// Extract photo URLs using regex
 	// This is a simple implementation - a real one would use proper HTML parsing
@@ -565,7 +817,14 @@ func findLastActivity(result *GoogleIDResult) string {
 // DisplayGoogleIDResults formats and displays the Google ID analysis results
 func (r *GoogleIDResult) DisplayResults() {
 	fmt.Printf("\n=== Google ID Analysis Results ===\n")
-	fmt.Printf("Google ID: %s\n\n", r.GoogleID)
+	fmt.Printf("Google ID: %s\n", r.GoogleID)
+	if r.DisplayName != "" {
+		fmt.Printf("Display Name: %s\n", r.DisplayName)
+	}
+	if r.AvatarURL != "" {
+		fmt.Printf("Avatar: %s\n", r.AvatarURL)
+	}
+	fmt.Println()
 
 	fmt.Println("Profile URLs:")
 	for service, profile := range r.ProfileURLs {
@@ -581,6 +840,9 @@ func (r *GoogleIDResult) DisplayResults() {
 			statusEmoji = "⚠️" // Error
 		}
 		fmt.Printf("• %s %s: %s\n", statusEmoji, strings.ReplaceAll(strings.Title(service), "_", " "), profile.URL)
+		if profile.DisplayName != "" {
+			fmt.Printf("    Name: %s\n", profile.DisplayName)
+		}
 	}
 
 	if r.Contributions.TotalReviews > 0 || r.Contributions.TotalPhotos > 0 {
@@ -599,6 +861,9 @@ func (r *GoogleIDResult) DisplayResults() {
 			if review.ReviewText != "" {
 				fmt.Printf("  \"%s\"\n", review.ReviewText)
 			}
+			if review.Translation != nil {
+				fmt.Printf("  ↳ %s: \"%s\"\n", review.Translation.Language, review.Translation.Translated)
+			}
 		}
 	}
 
@@ -612,7 +877,9 @@ func (r *GoogleIDResult) DisplayResults() {
 		for i := 0; i < showCount; i++ {
 			archive := r.ArchiveData[i]
 			statusEmoji := "✅"
-			if archive.Status != StatusAvailable {
+			if archive.Status == StatusUnchecked {
+				statusEmoji = "❓"
+			} else if archive.Status != StatusAvailable {
 				statusEmoji = "❌"
 			}
 			fmt.Printf("• %s %s (%s): %s\n",
@@ -654,7 +921,7 @@ func (r *GoogleIDResult) DisplayResults() {
 	}
 
 	fmt.Println("\nLegend:")
-	fmt.Println("✅ Available   ❌ Not Found   🔒 Restricted   ⚠️ Error")
+	fmt.Println("✅ Available   ❌ Not Found   🔒 Restricted   ⚠️ Error   ❓ Unchecked")
 }
 
 // ExportJSON exports the results to JSON
@@ -0,0 +1,61 @@
+package osint
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeArchiveDataPagesThroughCDX(t *testing.T) {
+	mock := useFixtures(t, "testdata/provider-fixtures.json")
+
+	archives, err := analyzeArchiveData(context.Background(), providerRouter{}, "104560124403688998123")
+	if err != nil {
+		t.Fatalf("analyzeArchiveData: %v", err)
+	}
+
+	// Both CDX pages are read and the captures sorted newest first
+	wantTypes := []string{"Post", "Photo", "Profile"}
+	if len(archives) != len(wantTypes) {
+		t.Fatalf("got %d captures, want %d: %+v", len(archives), len(wantTypes), archives)
+	}
+	for i, archive := range archives {
+		if archive.Type != wantTypes[i] {
+			t.Errorf("capture %d is a %s, want a %s", i, archive.Type, wantTypes[i])
+		}
+		if archive.Status != StatusAvailable {
+			t.Errorf("capture %d status = %s, want %s", i, archive.Status, StatusAvailable)
+		}
+	}
+	if archives[0].URL != "https://web.archive.org/web/20170815093000/https://plus.google.com/104560124403688998123/posts/x" {
+		t.Errorf("newest capture URL = %s", archives[0].URL)
+	}
+
+	var resumed bool
+	for _, request := range mock.Requests() {
+		resumed = resumed || strings.Contains(request, "resumeKey=")
+	}
+	if !resumed {
+		t.Errorf("second CDX page was never requested: %v", mock.Requests())
+	}
+}
+
+func TestEvaluateServiceContentDevelopers(t *testing.T) {
+	tests := []struct {
+		content    string
+		wantStatus LinkStatus
+		wantName   string
+	}{
+		{`<title>Jane Doe | Google Developers</title><div class="devsite-profile">404 badges</div>`, StatusAvailable, "Jane Doe"},
+		{`<div class="devsite-profile">Profile not found</div>`, StatusNotFound, ""},
+		{`<title>Google for Developers</title>`, StatusNotFound, ""},
+		{`<div class="devsite-profile"><p><b>404.</b> <ins>That’s an error.</ins><p>The requested URL /profile/u/1 was not found on this server.`, StatusNotFound, ""},
+		{`<title>Jane Doe | Google Developers</title><script>var id = 4041404;</script><div class="devsite-profile"></div>`, StatusAvailable, "Jane Doe"},
+	}
+	for _, tt := range tests {
+		status, name := evaluateServiceContent("developers", StatusAvailable, tt.content)
+		if status != tt.wantStatus || name != tt.wantName {
+			t.Errorf("evaluateServiceContent(%q) = %s, %q, want %s, %q", tt.content, status, name, tt.wantStatus, tt.wantName)
+		}
+	}
+}
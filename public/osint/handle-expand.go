@@ -0,0 +1,52 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/awion/MercuriesOST/public/variations"
+)
+
+// ExpandHandles scans near-variants of every handle confirmed by a social
+// media search, such as "johnd1987" and "johnd" for "johnd_1987"
+var ExpandHandles = false
+
+// maxHandleVariants caps how many variants of one handle are scanned, most
+// similar first
+const maxHandleVariants = 8
+
+// expandHandles scans the near-variants of the handles found so far on every
+// one of platforms, skipping terms already searched. Profiles found are
+// marked with the handle they vary.
+func expandHandles(ctx context.Context, client *http.Client, platforms []SocialPlatform, found []ProfileResult, searched map[string]bool) []ProfileResult {
+	var items []workItem
+	variantOf := make(map[string]string)
+	for _, profile := range found {
+		handle := strings.ToLower(profile.Username)
+		variants := variations.HandleVariants(handle)
+		if len(variants) > maxHandleVariants {
+			variants = variants[:maxHandleVariants]
+		}
+		for _, variant := range variants {
+			if searched[variant] {
+				continue
+			}
+			searched[variant] = true
+			variantOf[variant] = handle
+			items = append(items, scanItems(platforms, []string{variant})...)
+		}
+	}
+
+	var results []ProfileResult
+	newScanPool(client, scanWorkers()).run(ctx, items, func(item workItem, result ProfileResult) {
+		handle := variantOf[item.term]
+		result.VariantOf = handle
+		result.Confidence *= nearVariantPenalty
+		result.Insights = append(result.Insights, fmt.Sprintf("Near-variant of confirmed handle %s (similarity %.2f)",
+			handle, variations.HandleSimilarity(handle, item.term)))
+		results = append(results, result)
+	})
+	return results
+}
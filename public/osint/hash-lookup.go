@@ -0,0 +1,360 @@
+package osint
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// HashType is an algorithm a hash may have been produced with. Password
+// hashes are salted or built for storing credentials; they are identified
+// but never sent to lookup services.
+type HashType struct {
+	Name     string `json:"name"`
+	Password bool   `json:"password_hash,omitempty"`
+}
+
+// HashMatch is a value recovered for a hash
+type HashMatch struct {
+	Source    string `json:"source"` // "candidates", "md5db" or "gravatar"
+	Algorithm string `json:"algorithm,omitempty"`
+	Value     string `json:"value"`
+	Kind      string `json:"kind"` // "email", "username", "url" or "text"
+	URL       string `json:"url,omitempty"`
+}
+
+// HashResult holds what could be learned about a hash
+type HashResult struct {
+	Hash            string        `json:"hash"`
+	ScanID          string        `json:"scan_id,omitempty"`
+	SearchTimestamp string        `json:"search_timestamp"`
+	Types           []HashType    `json:"types"`
+	Sensitive       bool          `json:"sensitive"` // Only password hash formats fit
+	Matches         []HashMatch   `json:"matches,omitempty"`
+	PartialErrors   []ModuleError `json:"partial_errors,omitempty"`
+	ExecutionTime   string        `json:"execution_time"`
+
+	mu sync.Mutex
+}
+
+// hashPrefixes identifies password hash formats by their prefix
+var hashPrefixes = []struct {
+	prefix, name string
+}{
+	{"$2a$", "bcrypt"}, {"$2b$", "bcrypt"}, {"$2y$", "bcrypt"},
+	{"$argon2id$", "Argon2id"}, {"$argon2i$", "Argon2i"}, {"$argon2d$", "Argon2d"},
+	{"$1$", "md5crypt"}, {"$5$", "sha256crypt"}, {"$6$", "sha512crypt"}, {"$y$", "yescrypt"},
+	{"$apr1$", "Apache APR1"}, {"$P$", "phpass"}, {"$H$", "phpass"},
+	{"pbkdf2_sha256$", "Django PBKDF2-SHA256"}, {"{SSHA}", "LDAP salted SHA-1"}, {"{SHA}", "LDAP SHA-1"},
+}
+
+// hexHashTypes lists the algorithms producing each hex digest length, most
+// common first. Only the first of each length is computed for candidates.
+var hexHashTypes = map[int][]HashType{
+	8:   {{Name: "CRC32"}},
+	16:  {{Name: "MySQL323", Password: true}},
+	32:  {{Name: "MD5"}, {Name: "NTLM", Password: true}, {Name: "MD4"}},
+	40:  {{Name: "SHA-1"}, {Name: "RIPEMD-160"}},
+	56:  {{Name: "SHA-224"}, {Name: "SHA3-224"}},
+	64:  {{Name: "SHA-256"}, {Name: "SHA3-256"}, {Name: "BLAKE2s-256"}},
+	96:  {{Name: "SHA-384"}, {Name: "SHA3-384"}},
+	128: {{Name: "SHA-512"}, {Name: "SHA3-512"}, {Name: "BLAKE2b-512"}, {Name: "Whirlpool"}},
+}
+
+// hashFuncs computes candidates for the algorithms worth checking
+var hashFuncs = map[string]func() hash.Hash{
+	"MD5":     md5.New,
+	"SHA-1":   sha1.New,
+	"SHA-224": sha256.New224,
+	"SHA-256": sha256.New,
+	"SHA-384": sha512.New384,
+	"SHA-512": sha512.New,
+}
+
+var (
+	hexHashRegex = regexp.MustCompile(`^[0-9a-f]+$`)
+	md5dbRegex   = regexp.MustCompile(`^[\x20-\x7e]{1,256}$`)
+)
+
+// IdentifyHash lists the algorithms a hash may come from
+func IdentifyHash(value string) []HashType {
+	value = strings.TrimSpace(value)
+	for _, p := range hashPrefixes {
+		if strings.HasPrefix(value, p.prefix) {
+			return []HashType{{Name: p.name, Password: true}}
+		}
+	}
+	if strings.HasPrefix(value, "*") && len(value) == 41 && hexHashRegex.MatchString(strings.ToLower(value[1:])) {
+		return []HashType{{Name: "MySQL 4.1+", Password: true}}
+	}
+	if hexHashRegex.MatchString(strings.ToLower(value)) {
+		return hexHashTypes[len(value)]
+	}
+	return nil
+}
+
+// AnalyzeHash identifies a hash and, unless it is a password hash, tries to
+// recover what was hashed: against caller-supplied candidates such as known
+// emails and usernames, a public MD5 database, and Gravatar, which serves
+// profiles under the MD5 or SHA-256 of an email address
+func AnalyzeHash(ctx context.Context, value string, candidates []string) (*HashResult, error) {
+	startTime := time.Now()
+
+	value = strings.TrimSpace(value)
+	types := IdentifyHash(value)
+	if len(types) == 0 {
+		return nil, fmt.Errorf("unrecognized hash format")
+	}
+	result := &HashResult{
+		ScanID:          providers.ScanIDFrom(ctx),
+		Hash:            value,
+		SearchTimestamp: time.Now().Format(time.RFC3339),
+		Types:           types,
+	}
+	result.Sensitive = true
+	for _, t := range types {
+		result.Sensitive = result.Sensitive && t.Password
+	}
+	// A password hash says nothing useful to anyone but its owner
+	if result.Sensitive {
+		result.ExecutionTime = time.Since(startTime).String()
+		return result, nil
+	}
+	result.Hash = strings.ToLower(value)
+
+	graph := newTaskGraph(ConcurrentRequests)
+
+	if len(candidates) > 0 {
+		graph.add("candidates", RequestTimeout, func(ctx context.Context) error {
+			result.matchCandidates(candidates)
+			return nil
+		})
+	}
+
+	if types[0].Name == "MD5" {
+		graph.add("md5db", RequestTimeout, func(ctx context.Context) error {
+			return result.lookupMD5DB(ctx)
+		})
+	}
+
+	if types[0].Name == "MD5" || types[0].Name == "SHA-256" {
+		graph.add("gravatar", RequestTimeout, func(ctx context.Context) error {
+			return result.lookupGravatar(ctx)
+		})
+	}
+
+	errs, err := graph.run(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, name := range graph.order() {
+		if taskErr, ok := errs[name]; ok {
+			result.PartialErrors = append(result.PartialErrors, ModuleError{Module: name, Error: taskErr.Error()})
+		}
+	}
+
+	result.ExecutionTime = time.Since(startTime).String()
+	return result, nil
+}
+
+func (r *HashResult) addMatch(match HashMatch) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range r.Matches {
+		if m.Source == match.Source && m.Value == match.Value {
+			return
+		}
+	}
+	r.Matches = append(r.Matches, match)
+}
+
+// matchCandidates hashes each candidate as given and normalized the way
+// trackers and Gravatar normalize emails, trimmed and lowercased
+func (r *HashResult) matchCandidates(candidates []string) {
+	for _, t := range r.Types {
+		newHash, ok := hashFuncs[t.Name]
+		if !ok || t.Password {
+			continue
+		}
+		for _, candidate := range candidates {
+			forms := []string{candidate, strings.ToLower(strings.TrimSpace(candidate))}
+			for _, form := range forms {
+				h := newHash()
+				io.WriteString(h, form)
+				if hex.EncodeToString(h.Sum(nil)) == r.Hash {
+					r.addMatch(HashMatch{Source: "candidates", Algorithm: t.Name, Value: form, Kind: hashValueKind(form)})
+					break
+				}
+			}
+		}
+	}
+}
+
+// lookupMD5DB asks a public MD5 database for the plaintext of an email hash
+func (r *HashResult) lookupMD5DB(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://www.nitrxgen.net/md5db/"+r.Hash, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := doProviderRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("md5db returned status code %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return err
+	}
+
+	// An empty answer means the hash is not in the database. An unsalted
+	// MD5 is as likely to hide a password as an email, so only emails are kept.
+	plaintext := strings.TrimRight(string(body), "\r\n")
+	if plaintext == "" || !md5dbRegex.MatchString(plaintext) || hashValueKind(plaintext) != "email" {
+		return nil
+	}
+	r.addMatch(HashMatch{Source: "md5db", Algorithm: "MD5", Value: plaintext, Kind: hashValueKind(plaintext)})
+	return nil
+}
+
+// GravatarProfile is the public profile Gravatar serves for an email hash
+type GravatarProfile struct {
+	ProfileURL  string   `json:"profile_url"`
+	Username    string   `json:"username,omitempty"`
+	DisplayName string   `json:"display_name,omitempty"`
+	Accounts    []string `json:"accounts,omitempty"` // Verified accounts on other sites
+	URLs        []string `json:"urls,omitempty"`
+}
+
+// gravatarHash returns the MD5 hash Gravatar files an email address under
+func gravatarHash(email string) string {
+	sum := md5.Sum([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
+
+// fetchGravatar reads the public profiles registered under an email hash,
+// returning none when the hash has no Gravatar
+func fetchGravatar(ctx context.Context, hash string) ([]GravatarProfile, error) {
+	var profile struct {
+		Entry []struct {
+			ProfileURL        string `json:"profileUrl"`
+			PreferredUsername string `json:"preferredUsername"`
+			DisplayName       string `json:"displayName"`
+			Accounts          []struct {
+				URL string `json:"url"`
+			} `json:"accounts"`
+			URLs []struct {
+				Value string `json:"value"`
+			} `json:"urls"`
+		} `json:"entry"`
+	}
+	err := getProviderJSON(ctx, "https://en.gravatar.com/"+hash+".json", nil, &profile)
+	if providers.IsStatus(err, http.StatusNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []GravatarProfile
+	for _, entry := range profile.Entry {
+		p := GravatarProfile{ProfileURL: entry.ProfileURL, Username: entry.PreferredUsername, DisplayName: entry.DisplayName}
+		for _, account := range entry.Accounts {
+			p.Accounts = append(p.Accounts, account.URL)
+		}
+		for _, link := range entry.URLs {
+			p.URLs = append(p.URLs, link.Value)
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// lookupGravatar reads the public profile registered under an email hash
+func (r *HashResult) lookupGravatar(ctx context.Context) error {
+	profiles, err := fetchGravatar(ctx, r.Hash)
+	if err != nil {
+		return err
+	}
+
+	for _, profile := range profiles {
+		if profile.Username != "" {
+			r.addMatch(HashMatch{Source: "gravatar", Value: profile.Username, Kind: "username", URL: profile.ProfileURL})
+		}
+		if profile.DisplayName != "" && profile.DisplayName != profile.Username {
+			r.addMatch(HashMatch{Source: "gravatar", Value: profile.DisplayName, Kind: "text", URL: profile.ProfileURL})
+		}
+		for _, account := range profile.Accounts {
+			r.addMatch(HashMatch{Source: "gravatar", Value: account, Kind: "url", URL: profile.ProfileURL})
+		}
+		for _, link := range profile.URLs {
+			r.addMatch(HashMatch{Source: "gravatar", Value: link, Kind: "url", URL: profile.ProfileURL})
+		}
+	}
+	return nil
+}
+
+// hashValueKind classifies a recovered value
+func hashValueKind(value string) string {
+	switch {
+	case emailAddressRegex.MatchString(value) && !strings.ContainsAny(value, " \t"):
+		return "email"
+	case strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"):
+		return "url"
+	case !strings.ContainsAny(value, " \t"):
+		return "username"
+	}
+	return "text"
+}
+
+// DisplayResults prints the hash analysis
+func (r *HashResult) DisplayResults() {
+	color.Cyan("\n=== HASH ANALYSIS RESULTS ===")
+	color.Yellow("Hash: %s", r.Hash)
+
+	var names []string
+	for _, t := range r.Types {
+		names = append(names, t.Name)
+	}
+	color.White("• Possible types: %s", strings.Join(names, ", "))
+	if r.Sensitive {
+		color.Yellow("• Password hash format; not sent to lookup services")
+	}
+
+	if len(r.Matches) == 0 {
+		color.Yellow("\nNo values recovered")
+		return
+	}
+	color.Cyan("\n[Recovered Values]")
+	for _, m := range r.Matches {
+		line := fmt.Sprintf("• %s (%s, from %s)", m.Value, m.Kind, m.Source)
+		if m.Algorithm != "" {
+			line += " as " + m.Algorithm
+		}
+		color.Green(line)
+		if m.URL != "" {
+			color.White("  %s", m.URL)
+		}
+	}
+}
+
+// DisplayPartialErrors prints the lookups that failed
+func (r *HashResult) DisplayPartialErrors() {
+	displayModuleErrors(r.PartialErrors)
+}
@@ -0,0 +1,137 @@
+package osint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// Hook events, each run by the executable in HooksDir of the same name with
+// any extension, e.g. on_profile_found.sh
+const (
+	HookProfileFound = "on_profile_found" // A profile was found, below --min-confidence or not
+	HookAlert        = "on_alert"         // A module raised an alert
+	HookScanComplete = "on_scan_complete" // A module finished, with its full results
+)
+
+var (
+	// HooksDir holds the user's hook scripts; empty turns hooks off
+	HooksDir = defaultHooksDir()
+	// HookTimeout bounds one run of a hook script
+	HookTimeout = 30 * time.Second
+)
+
+// HookEvent is what a hook script reads on stdin
+type HookEvent struct {
+	Event   string      `json:"event"`
+	Module  string      `json:"module"`
+	ScanID  string      `json:"scan_id,omitempty"`
+	Finding interface{} `json:"finding"`
+}
+
+// hookRunner runs hooks one at a time in the order they were queued, so a
+// slow script never holds up the scan that triggered it
+type hookRunner struct {
+	once    sync.Once
+	queue   chan HookEvent
+	done    sync.WaitGroup
+	mu      sync.Mutex
+	scripts map[string]string // Script of each event, "" when there is none
+}
+
+var hooks hookRunner
+
+// defaultHooksDir returns ~/.mercuries/hooks
+func defaultHooksDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".mercuries", "hooks")
+}
+
+// RunHook queues a finding for the hook script of an event, if the user
+// has one. The script gets the HookEvent as JSON on stdin.
+func RunHook(event, module string, finding interface{}) {
+	if hooks.script(event) == "" {
+		return
+	}
+	hooks.once.Do(func() {
+		hooks.queue = make(chan HookEvent, 256)
+		go hooks.run()
+	})
+	hooks.done.Add(1)
+	hooks.queue <- HookEvent{Event: event, Module: module, ScanID: providers.ScanID, Finding: finding}
+}
+
+// WaitHooks waits for the queued hooks to finish
+func WaitHooks() {
+	hooks.done.Wait()
+}
+
+// script finds the executable run for an event, looking once per event
+func (h *hookRunner) script(event string) string {
+	if HooksDir == "" {
+		return ""
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if path, ok := h.scripts[event]; ok {
+		return path
+	}
+	if h.scripts == nil {
+		h.scripts = make(map[string]string)
+	}
+
+	entries, _ := os.ReadDir(HooksDir)
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.TrimSuffix(name, filepath.Ext(name)) != event {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && info.Mode().IsRegular() && info.Mode()&0111 != 0 {
+			h.scripts[event] = filepath.Join(HooksDir, name)
+			break
+		}
+	}
+	return h.scripts[event]
+}
+
+func (h *hookRunner) run() {
+	for event := range h.queue {
+		if err := runHookScript(h.script(event.Event), event); err != nil {
+			color.Yellow("Hook %s failed: %v", event.Event, err)
+		}
+		h.done.Done()
+	}
+}
+
+// runHookScript runs one script with the event on stdin. Its output goes to
+// stderr, keeping stdout for results.
+func runHookScript(path string, event HookEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), HookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	cmd.Env = append(os.Environ(),
+		"MERCURIES_EVENT="+event.Event,
+		"MERCURIES_MODULE="+event.Module,
+		"MERCURIES_SCAN_ID="+event.ScanID,
+	)
+	return cmd.Run()
+}
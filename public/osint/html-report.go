@@ -0,0 +1,540 @@
+package osint
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// maxAvatarSize caps the download of one embedded avatar
+const maxAvatarSize = 512 << 10
+
+// errNotReportable marks JSON files holding none of the results a report renders
+var errNotReportable = errors.New("not social, email, phone or Google ID results")
+
+// HTMLReport gathers saved results into one self-contained HTML page, with
+// the avatars of the profiles found embedded so it can be handed over as a
+// single file
+type HTMLReport struct {
+	Title        string
+	Investigator string
+	Generated    string
+	Sources      []string // Files the results were read from
+	Social       []*SocialMediaResults
+	Emails       []*EmailAnalysisResult
+	Phones       []*PhoneNumberResult
+	GoogleIDs    []*GoogleIDResult
+	Suppressed   int // Findings left out as known false positives
+
+	avatars map[string]template.URL // Avatar URL to its data URI
+}
+
+// NewHTMLReport starts an empty report
+func NewHTMLReport(title, investigator string) *HTMLReport {
+	return &HTMLReport{
+		Title:        title,
+		Investigator: investigator,
+		Generated:    time.Now().Format(time.RFC3339),
+		avatars:      make(map[string]template.URL),
+	}
+}
+
+// AddPath adds the results saved in a file, or in every JSON file under a
+// directory such as a case's. Files in a directory holding other results are
+// skipped; a file given by name must hold reportable results.
+func (r *HTMLReport) AddPath(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return r.addFile(path)
+	}
+	return filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(file) != ".json" {
+			return err
+		}
+		if err := r.addFile(file); err != nil && !errors.Is(err, errNotReportable) {
+			return err
+		}
+		return nil
+	})
+}
+
+// addFile adds a file's reportable results, leaving out the profiles on the
+// suppression lists
+func (r *HTMLReport) addFile(path string) error {
+	saved, err := readSavedResults(path)
+	if err != nil {
+		return err
+	}
+	for _, social := range saved.social {
+		r.suppressSocial(social)
+	}
+	for _, email := range saved.emails {
+		r.suppressEmail(email)
+	}
+	r.Social = append(r.Social, saved.social...)
+	r.Emails = append(r.Emails, saved.emails...)
+	r.Phones = append(r.Phones, saved.phones...)
+	r.GoogleIDs = append(r.GoogleIDs, saved.googleIDs...)
+	r.Sources = append(r.Sources, path)
+	return nil
+}
+
+// savedResults are the reportable results held in one saved file
+type savedResults struct {
+	social    []*SocialMediaResults
+	emails    []*EmailAnalysisResult
+	phones    []*PhoneNumberResult
+	googleIDs []*GoogleIDResult
+}
+
+// readSavedResults decodes a results file by the fields that tell the
+// modules' results apart. Combined reports give each module's results.
+func readSavedResults(path string) (*savedResults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return nil, fmt.Errorf("%s: %w", path, errNotReportable)
+	}
+	has := func(key string) bool { _, ok := fields[key]; return ok }
+
+	saved := &savedResults{}
+	switch {
+	case has("seed") && has("seed_kind"):
+		var report CombinedReport
+		err = json.Unmarshal(data, &report)
+		if report.Social != nil {
+			saved.social = append(saved.social, report.Social)
+		}
+		if report.Email != nil {
+			saved.emails = append(saved.emails, report.Email)
+		}
+		saved.phones = report.Phones
+		saved.googleIDs = report.GoogleIDs
+	case has("query") && has("profiles"):
+		var results SocialMediaResults
+		err = json.Unmarshal(data, &results)
+		saved.social = append(saved.social, &results)
+	case has("email") && has("valid_format"):
+		var results EmailAnalysisResult
+		err = json.Unmarshal(data, &results)
+		saved.emails = append(saved.emails, &results)
+	case has("e164_format"):
+		var results PhoneNumberResult
+		err = json.Unmarshal(data, &results)
+		saved.phones = append(saved.phones, &results)
+	case has("google_id") && has("contributions"):
+		var results GoogleIDResult
+		err = json.Unmarshal(data, &results)
+		saved.googleIDs = append(saved.googleIDs, &results)
+	default:
+		return nil, fmt.Errorf("%s: %w", path, errNotReportable)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %v", path, err)
+	}
+	return saved, nil
+}
+
+// suppressSocial leaves out the profiles put on the suppression lists since
+// the results were saved, counting those left out when they were
+func (r *HTMLReport) suppressSocial(social *SocialMediaResults) {
+	keep := func(profiles []ProfileResult) []ProfileResult {
+		var kept []ProfileResult
+		for _, profile := range profiles {
+			if rule := ActiveSuppressions.Profile(profile); rule != nil {
+				social.Suppressed = append(social.Suppressed, SuppressedProfile{Profile: profile, SuppressedBy: *rule})
+			} else {
+				kept = append(kept, profile)
+			}
+		}
+		return kept
+	}
+	social.Profiles, social.Leads = keep(social.Profiles), keep(social.Leads)
+	r.Suppressed += len(social.Suppressed)
+}
+
+// suppressEmail does the same for an email's linked accounts
+func (r *HTMLReport) suppressEmail(email *EmailAnalysisResult) {
+	var suppressed []SocialProfile
+	email.SocialProfiles, suppressed = ActiveSuppressions.SocialProfiles(email.SocialProfiles)
+	email.SuppressedProfiles = append(email.SuppressedProfiles, suppressed...)
+	r.Suppressed += len(email.SuppressedProfiles)
+}
+
+// Empty reports whether no results were added
+func (r *HTMLReport) Empty() bool {
+	return len(r.Social)+len(r.Emails)+len(r.Phones)+len(r.GoogleIDs) == 0
+}
+
+// avatarURLs lists every image the report shows
+func (r *HTMLReport) avatarURLs() []string {
+	seen := make(map[string]bool)
+	var urls []string
+	add := func(link string) {
+		if strings.HasPrefix(link, "http") && !seen[link] {
+			seen[link] = true
+			urls = append(urls, link)
+		}
+	}
+	for _, social := range r.Social {
+		for _, profile := range social.Profiles {
+			add(profile.Avatar)
+		}
+	}
+	for _, email := range r.Emails {
+		for _, profile := range email.SocialProfiles {
+			add(profile.ProfilePic)
+		}
+	}
+	for _, phone := range r.Phones {
+		for _, app := range phone.MessagingApps {
+			add(app.AvatarURL)
+		}
+	}
+	for _, gid := range r.GoogleIDs {
+		add(gid.AvatarURL)
+	}
+	return urls
+}
+
+// EmbedAvatars downloads the avatars the report shows so the page needs no
+// network access to display them. Avatars that cannot be fetched are left
+// out, showing the profile's initial instead.
+func (r *HTMLReport) EmbedAvatars(ctx context.Context) []ModuleError {
+	var mu sync.Mutex
+	var errs []ModuleError
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(ConcurrentRequests)
+	for _, link := range r.avatarURLs() {
+		g.Go(func() error {
+			uri, err := fetchDataURI(ctx, link)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, ModuleError{Module: "avatar " + link, Error: err.Error()})
+				return nil
+			}
+			r.avatars[link] = uri
+			return nil
+		})
+	}
+	g.Wait()
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Module < errs[j].Module })
+	return errs
+}
+
+// fetchDataURI downloads an image as a data: URI
+func fetchDataURI(ctx context.Context, link string) (template.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", link, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	resp, err := doProviderRequest(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	// SVG can carry scripts, so only raster images are embedded
+	if !strings.HasPrefix(mediaType, "image/") || mediaType == "image/svg+xml" {
+		return "", fmt.Errorf("not a raster image (%s)", mediaType)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAvatarSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxAvatarSize {
+		return "", fmt.Errorf("larger than %d KB", maxAvatarSize>>10)
+	}
+	// Only base64 image data goes into the URI, so it is safe to mark as a URL
+	return template.URL("data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// Render writes the report as one HTML page
+func (r *HTMLReport) Render(w io.Writer) error {
+	return reportTemplate.Execute(w, r)
+}
+
+// reportInitial is the first letter of the first name given, shown in
+// place of a missing avatar
+func reportInitial(names ...string) string {
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			return strings.ToUpper(string([]rune(name)[:1]))
+		}
+	}
+	return "?"
+}
+
+// reportPercent shows a 0-1 confidence as a percentage
+func reportPercent(f float64) string {
+	return fmt.Sprintf("%.0f%%", f*100)
+}
+
+// reportTemplate lays out the report. Avatars are only ever shown from the
+// data URIs embedded, so opening the report contacts no profile's host.
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"avatar":  func(r *HTMLReport, link string) template.URL { return r.avatars[link] },
+	"initial": reportInitial,
+	"percent": reportPercent,
+	"join":    strings.Join,
+	"stars": func(rating int) string {
+		rating = max(0, min(rating, 5))
+		return strings.Repeat("★", rating) + strings.Repeat("☆", 5-rating)
+	},
+	"riskClass": func(level string) string {
+		switch strings.ToLower(level) {
+		case "high", "critical":
+			return "high"
+		case "medium":
+			return "medium"
+		}
+		return "low"
+	},
+	"breachClass": func(count int) string {
+		if count > 0 {
+			return "high"
+		}
+		return "low"
+	},
+}).Parse(reportHTML))
+
+const reportHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="referrer" content="no-referrer">
+<title>{{.Title}}</title>
+<style>
+body{font-family:-apple-system,"Segoe UI",Roboto,Helvetica,Arial,sans-serif;margin:0;background:#f4f5f7;color:#1d2330;line-height:1.45}
+header{background:#1d2330;color:#fff;padding:28px 40px}
+header h1{margin:0 0 6px;font-size:26px}
+header p{margin:2px 0;color:#b8c0d0;font-size:14px}
+nav{background:#fff;border-bottom:1px solid #dde1e8;padding:10px 40px}
+nav a{margin-right:18px;color:#3257a8;text-decoration:none;font-weight:600}
+main{padding:24px 40px;max-width:1200px}
+section{margin-bottom:36px}
+h2{border-bottom:2px solid #1d2330;padding-bottom:6px}
+h3{margin:24px 0 10px}
+.cards{display:grid;grid-template-columns:repeat(auto-fill,minmax(320px,1fr));gap:16px}
+.card{background:#fff;border:1px solid #dde1e8;border-radius:8px;padding:16px}
+.card-head{display:flex;gap:12px;align-items:center;margin-bottom:8px}
+.avatar{width:56px;height:56px;border-radius:50%;object-fit:cover;flex:none}
+.initial{width:56px;height:56px;border-radius:50%;background:#c9d2e3;color:#1d2330;display:flex;align-items:center;justify-content:center;font-size:24px;font-weight:700;flex:none}
+.card h4{margin:0;font-size:17px}
+.muted{color:#667085;font-size:13px}
+.badge{display:inline-block;padding:1px 8px;border-radius:10px;font-size:12px;font-weight:600;background:#e7ebf3;margin-right:4px}
+.verified{background:#d7f0dd;color:#1b6b34}.probable{background:#fdf0c8;color:#7a5a00}.lead{background:#eceff4;color:#596275}
+.high{background:#fbd9d9;color:#9b1c1c}.medium{background:#fdf0c8;color:#7a5a00}.low{background:#d7f0dd;color:#1b6b34}
+dl{display:grid;grid-template-columns:max-content 1fr;gap:2px 12px;margin:8px 0;font-size:14px}
+dt{color:#667085}dd{margin:0;word-break:break-word}
+ul{margin:6px 0;padding-left:20px;font-size:14px}
+table{border-collapse:collapse;width:100%;background:#fff;font-size:14px}
+th,td{border:1px solid #dde1e8;padding:6px 8px;text-align:left;vertical-align:top}
+th{background:#eceff4}
+a{color:#3257a8}
+.bio{font-size:14px;white-space:pre-wrap}
+footer{padding:20px 40px;color:#667085;font-size:12px}
+@media print{nav{display:none}.card{break-inside:avoid}}
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+{{if .Investigator}}<p>Investigator: {{.Investigator}}</p>{{end}}
+<p>Generated {{.Generated}}</p>
+</header>
+<nav>
+{{if .Social}}<a href="#social">Social media</a>{{end}}
+{{if .Emails}}<a href="#email">Email</a>{{end}}
+{{if .Phones}}<a href="#phone">Phone</a>{{end}}
+{{if .GoogleIDs}}<a href="#gid">Google ID</a>{{end}}
+<a href="#sources">Sources</a>
+</nav>
+<main>
+{{$r := .}}
+{{if .Social}}
+<section id="social">
+<h2>Social media</h2>
+{{range .Social}}
+<h3>Search: {{.Query}}</h3>
+<p class="muted">{{len .Profiles}} profiles, searched {{.Timestamp}}{{if .Interrupted}}, stopped before every check ran{{end}}</p>
+<div class="cards">
+{{range .Profiles}}
+<div class="card">
+<div class="card-head">
+{{with avatar $r .Avatar}}<img class="avatar" src="{{.}}" alt="">{{else}}<div class="initial">{{initial .FullName .Username .Platform}}</div>{{end}}
+<div>
+<h4>{{if .FullName}}{{.FullName}}{{else}}{{.Username}}{{end}}</h4>
+<div class="muted">{{.Platform}} · <a href="{{.URL}}" rel="noreferrer">{{.Username}}</a></div>
+</div>
+</div>
+{{if .Tier}}<span class="badge {{.Tier}}">{{.Tier}}</span>{{end}}<span class="badge">confidence {{percent .Confidence}}</span>
+{{if .Bio}}<p class="bio">{{.Bio}}</p>{{end}}
+<dl>
+{{if .Location}}<dt>Location</dt><dd>{{.Location}}</dd>{{end}}
+{{if .FollowerCount}}<dt>Followers</dt><dd>{{.FollowerCount}}</dd>{{end}}
+{{if .JoinDate}}<dt>Joined</dt><dd>{{.JoinDate}}</dd>{{end}}
+{{if .CanonicalID}}<dt>Account ID</dt><dd>{{.CanonicalID}}{{if .IDCreated}} (created {{.IDCreated}}){{end}}</dd>{{end}}
+{{if .VariantOf}}<dt>Variant of</dt><dd>{{.VariantOf}}</dd>{{end}}
+<dt>Finding</dt><dd><code>{{.FindingHash}}</code></dd>
+</dl>
+{{if .Insights}}<ul>{{range .Insights}}<li>{{.}}</li>{{end}}</ul>{{end}}
+</div>
+{{end}}
+</div>
+{{if .Leads}}
+<h3>Leads</h3>
+<table><tr><th>Platform</th><th>Profile</th><th>Confidence</th></tr>
+{{range .Leads}}<tr><td>{{.Platform}}</td><td><a href="{{.URL}}" rel="noreferrer">{{.URL}}</a></td><td>{{percent .Confidence}}</td></tr>{{end}}
+</table>
+{{end}}
+{{if .Locations}}
+<h3>Locations</h3>
+<ul>{{range .Locations}}<li>{{.Place}}: {{len .Profiles}} profiles</li>{{end}}</ul>
+{{end}}
+{{end}}
+</section>
+{{end}}
+
+{{if .Emails}}
+<section id="email">
+<h2>Email</h2>
+{{range .Emails}}
+<div class="card">
+<h3>{{.Email}}</h3>
+<span class="badge {{if .ValidFormat}}low{{else}}high{{end}}">{{if .ValidFormat}}valid format{{else}}invalid format{{end}}</span>
+<span class="badge {{breachClass .SecurityInfo.BreachCount}}">{{.SecurityInfo.BreachCount}} breaches</span>
+<span class="badge">risk {{.SecurityInfo.RiskScore}}/100</span>
+<dl>
+<dt>Domain</dt><dd>{{.Domain}}{{if .DomainInfo.Registrar}} ({{.DomainInfo.Registrar}}){{end}}</dd>
+{{if .PatternAnalysis.Patterns}}<dt>Patterns</dt><dd>{{join .PatternAnalysis.Patterns ", "}}</dd>{{end}}
+{{if .SecurityInfo.ExposedDataTypes}}<dt>Exposed data</dt><dd>{{join .SecurityInfo.ExposedDataTypes ", "}}</dd>{{end}}
+{{if .SecurityInfo.LastBreachDate}}<dt>Last breach</dt><dd>{{.SecurityInfo.LastBreachDate}}</dd>{{end}}
+{{if .CommonServices}}<dt>Services</dt><dd>{{join .CommonServices ", "}}</dd>{{end}}
+</dl>
+{{if .SecurityInfo.BreachDetails}}
+<table><tr><th>Breach</th><th>Date</th><th>Data</th></tr>
+{{range .SecurityInfo.BreachDetails}}<tr><td>{{.BreachName}}</td><td>{{.BreachDate}}</td><td>{{join .CompromisedData ", "}}</td></tr>{{end}}
+</table>
+{{end}}
+</div>
+{{if .SocialProfiles}}
+<h3>Linked accounts</h3>
+<div class="cards">
+{{range .SocialProfiles}}
+<div class="card">
+<div class="card-head">
+{{with avatar $r .ProfilePic}}<img class="avatar" src="{{.}}" alt="">{{else}}<div class="initial">{{initial .DisplayName .Username .Platform}}</div>{{end}}
+<div>
+<h4>{{if .DisplayName}}{{.DisplayName}}{{else}}{{.Username}}{{end}}</h4>
+<div class="muted">{{.Platform}} · <a href="{{.URL}}" rel="noreferrer">{{.URL}}</a></div>
+</div>
+</div>
+{{if .Tier}}<span class="badge {{.Tier}}">{{.Tier}}</span>{{end}}{{if .Verified}}<span class="badge verified">verified</span>{{end}}
+{{if .Bio}}<p class="bio">{{.Bio}}</p>{{end}}
+<dl><dt>Finding</dt><dd><code>{{.FindingHash}}</code></dd></dl>
+</div>
+{{end}}
+</div>
+{{end}}
+{{end}}
+</section>
+{{end}}
+
+{{if .Phones}}
+<section id="phone">
+<h2>Phone</h2>
+{{range .Phones}}
+<div class="card">
+<h3>{{.E164Format}}</h3>
+{{if .RiskAssessment.Level}}<span class="badge {{riskClass .RiskAssessment.Level}}">{{.RiskAssessment.Level}} risk</span>{{end}}
+<dl>
+<dt>Country</dt><dd>{{.CountryName}}{{if .Region}}, {{.Region}}{{end}}</dd>
+{{if .Type}}<dt>Type</dt><dd>{{.Type}}</dd>{{end}}
+{{if .Carrier.Name}}<dt>Carrier</dt><dd>{{.Carrier.Name}}</dd>{{end}}
+{{if .TimeZones}}<dt>Time zones</dt><dd>{{join .TimeZones ", "}}</dd>{{end}}
+{{if .ReverseLookup.PossibleOwners}}<dt>Possible owners</dt><dd>{{join .ReverseLookup.PossibleOwners ", "}}</dd>{{end}}
+</dl>
+{{if .RiskAssessment.Indicators}}<ul>{{range .RiskAssessment.Indicators}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .MessagingApps}}
+<table><tr><th>Messaging app</th><th>Status</th><th>Last seen</th></tr>
+{{range .MessagingApps}}<tr><td>{{with avatar $r .AvatarURL}}<img class="avatar" src="{{.}}" alt=""> {{end}}{{.Name}}</td><td>{{.Status}}</td><td>{{.LastSeen}}</td></tr>{{end}}
+</table>
+{{end}}
+{{if .OnlinePresence}}
+<h3>Online presence</h3>
+<ul>{{range .OnlinePresence}}<li>{{.Platform}}: <a href="{{.URL}}" rel="noreferrer">{{.URL}}</a></li>{{end}}</ul>
+{{end}}
+</div>
+{{end}}
+</section>
+{{end}}
+
+{{if .GoogleIDs}}
+<section id="gid">
+<h2>Google ID</h2>
+{{range .GoogleIDs}}
+<div class="card">
+<div class="card-head">
+{{with avatar $r .AvatarURL}}<img class="avatar" src="{{.}}" alt="">{{else}}<div class="initial">{{initial .DisplayName "G"}}</div>{{end}}
+<div>
+<h4>{{if .DisplayName}}{{.DisplayName}}{{else}}{{.GoogleID}}{{end}}</h4>
+<div class="muted">Google ID {{.GoogleID}}</div>
+</div>
+</div>
+<dl>
+<dt>Contributions</dt><dd>{{.Contributions.TotalReviews}} reviews, {{.Contributions.TotalPhotos}} photos, {{.Contributions.TotalRatings}} ratings</dd>
+{{if .Contributions.ContributorRank}}<dt>Rank</dt><dd>{{.Contributions.ContributorRank}}</dd>{{end}}
+{{if .LastSeen}}<dt>Last seen</dt><dd>{{.LastSeen}}</dd>{{end}}
+</dl>
+{{if .Reviews}}
+<table><tr><th>Place</th><th>Rating</th><th>Date</th><th>Review</th></tr>
+{{range .Reviews}}<tr><td>{{.Location}}</td><td>{{stars .Rating}}</td><td>{{.ReviewDate}}</td><td>{{.ReviewText}}</td></tr>{{end}}
+</table>
+{{end}}
+{{if .Photos}}
+<h3>Photos</h3>
+<ul>{{range .Photos}}<li><a href="{{.URL}}" rel="noreferrer">{{if .Location}}{{.Location}}{{else}}{{.URL}}{{end}}</a>{{if .UploadDate}} ({{.UploadDate}}){{end}}</li>{{end}}</ul>
+{{end}}
+</div>
+{{end}}
+</section>
+{{end}}
+
+<section id="sources">
+<h2>Sources</h2>
+<ul>{{range .Sources}}<li>{{.}}</li>{{end}}</ul>
+{{if .Suppressed}}<p class="muted">{{.Suppressed}} findings on the suppression lists are left out; the result files still hold them.</p>{{end}}
+</section>
+</main>
+<footer>Compiled from public sources. Findings marked lead are unconfirmed.</footer>
+</body>
+</html>
+`
@@ -0,0 +1,166 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// IPIntelResult holds what is known about an IP address
+type IPIntelResult struct {
+	IP              string                 `json:"ip"`
+	ScanID          string                 `json:"scan_id,omitempty"`
+	SearchTimestamp string                 `json:"search_timestamp"`
+	Public          bool                   `json:"public"`
+	ReverseDNS      []string               `json:"reverse_dns,omitempty"`
+	Geo             *GeoIPInfo             `json:"geo,omitempty"`
+	Host            *HostIntel             `json:"host,omitempty"`
+	VirusTotal      *VTReport              `json:"virustotal,omitempty"`
+	Reputation      []IPVerdict            `json:"reputation,omitempty"`
+	Metadata        map[string]interface{} `json:"metadata"`
+	PartialErrors   []ModuleError          `json:"partial_errors,omitempty"`
+	ExecutionTime   string                 `json:"execution_time"`
+}
+
+// AnalyzeIP gathers reverse DNS, location, exposure and reputation for an IP address
+func AnalyzeIP(ctx context.Context, address string) (*IPIntelResult, error) {
+	startTime := time.Now()
+
+	ip := net.ParseIP(strings.TrimSpace(address))
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address: %q", address)
+	}
+
+	result := &IPIntelResult{
+		ScanID:          providers.ScanIDFrom(ctx),
+		IP:              ip.String(),
+		SearchTimestamp: time.Now().Format(time.RFC3339),
+		Public:          isPublicIP(ip),
+		Metadata:        make(map[string]interface{}),
+	}
+
+	graph := newTaskGraph(ConcurrentRequests)
+
+	graph.add("reverse_dns", RequestTimeout, func(ctx context.Context) error {
+		names, err := net.DefaultResolver.LookupAddr(ctx, result.IP)
+		for _, name := range names {
+			result.ReverseDNS = append(result.ReverseDNS, strings.TrimSuffix(name, "."))
+		}
+		if err != nil && !isNotFoundDNSError(err) {
+			return err
+		}
+		return nil
+	})
+
+	// Private addresses mean nothing to internet-facing services
+	if result.Public {
+		graph.add("geolocation", 2*RequestTimeout, func(ctx context.Context) error {
+			var geo GeoIPInfo
+			_, _, err := withFallback(ctx, GeoProviders, func(p GeoProvider) error {
+				found, err := p.Geolocate(ctx, result.IP)
+				geo = found
+				return err
+			})
+			if err == nil {
+				result.Geo = &geo
+			}
+			return err
+		})
+
+		graph.add("host", 2*RequestTimeout, func(ctx context.Context) error {
+			var intel HostIntel
+			_, _, err := withFallback(ctx, HostProviders, func(p HostProvider) error {
+				found, err := p.Host(ctx, result.IP)
+				intel = found
+				return err
+			})
+			if err == nil {
+				result.Host = &intel
+			}
+			return err
+		})
+
+		graph.add("reputation", 2*RequestTimeout, func(ctx context.Context) error {
+			verdicts, err := checkIPReputation(ctx, []string{result.IP})
+			result.Reputation = verdicts
+			return err
+		})
+
+		if vtConfigured() {
+			graph.add("virustotal", 4*RequestTimeout, func(ctx context.Context) error {
+				report, err := LookupVirusTotal(ctx, VTIP, result.IP)
+				result.VirusTotal = report
+				return err
+			})
+		}
+	}
+
+	errs, err := graph.run(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, name := range graph.order() {
+		if taskErr, ok := errs[name]; ok {
+			result.PartialErrors = append(result.PartialErrors, ModuleError{Module: name, Error: taskErr.Error()})
+		}
+	}
+
+	result.ExecutionTime = time.Since(startTime).String()
+	return result, nil
+}
+
+// DisplayResults prints the IP intelligence results
+func (r *IPIntelResult) DisplayResults() {
+	color.Cyan("\n=== IP ANALYSIS RESULTS ===")
+	color.Yellow("IP: %s", r.IP)
+	color.Yellow("Analysis Timestamp: %s\n", r.SearchTimestamp)
+
+	if !r.Public {
+		color.Yellow("Private or reserved address; only reverse DNS was checked")
+	}
+	for _, name := range r.ReverseDNS {
+		color.White("• Reverse DNS: %s", name)
+	}
+
+	if r.Geo != nil {
+		color.Cyan("\n[Location]")
+		location := strings.Trim(strings.Join([]string{r.Geo.City, r.Geo.Region, r.Geo.Country}, ", "), ", ")
+		color.White("• %s", location)
+		if r.Geo.ISP != "" {
+			color.White("• ISP: %s", r.Geo.ISP)
+		}
+		if r.Geo.ASN != "" {
+			color.White("• ASN: %s", r.Geo.ASN)
+		}
+		if r.Geo.TimeZone != "" {
+			color.White("• Time Zone: %s", r.Geo.TimeZone)
+		}
+	}
+
+	if r.Host != nil {
+		color.Cyan("\n[Host Exposure]")
+		color.White("• Ports (%s): %v", r.Host.Provider, r.Host.Ports)
+		if r.Host.Org != "" {
+			color.White("• Org: %s", r.Host.Org)
+		}
+		if len(r.Host.Hostnames) > 0 {
+			color.White("• Hostnames: %s", strings.Join(r.Host.Hostnames, ", "))
+		}
+		if len(r.Host.Vulns) > 0 {
+			color.Red("• Vulnerabilities: %s", strings.Join(r.Host.Vulns, ", "))
+		}
+	}
+
+	displayIPReputation(r.Reputation)
+	displayVTReport(r.VirusTotal)
+}
+
+// DisplayPartialErrors lists subtasks that failed during analysis
+func (r *IPIntelResult) DisplayPartialErrors() {
+	displayModuleErrors(r.PartialErrors)
+}
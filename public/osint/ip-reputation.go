@@ -0,0 +1,106 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// IPVerdict is a reputation service's classification of an IP address
+type IPVerdict struct {
+	Provider       string `json:"provider"`
+	IP             string `json:"ip"`
+	Classification string `json:"classification"` // benign service, malicious, suspicious, scanner or unknown
+	Malicious      bool   `json:"malicious"`
+	Score          *int   `json:"score,omitempty"` // Abuse confidence, 0-100
+	Details        string `json:"details,omitempty"`
+	Link           string `json:"link,omitempty"`
+}
+
+// maxIPReputationLookups caps how many addresses from a result are classified
+const maxIPReputationLookups = 5
+
+// checkIPReputation asks every configured reputation service about each public
+// address. Private and duplicate addresses are skipped.
+func checkIPReputation(ctx context.Context, ips []string) ([]IPVerdict, error) {
+	var targets []string
+	for _, ip := range ips {
+		parsed := net.ParseIP(ip)
+		if parsed == nil || !isPublicIP(parsed) {
+			continue
+		}
+		targets = mergeStrings(targets, []string{parsed.String()})
+	}
+	if len(targets) > maxIPReputationLookups {
+		targets = targets[:maxIPReputationLookups]
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	var verdicts []IPVerdict
+	var errs []string
+	for _, provider := range IPReputationProviders {
+		if err := checkHealth(ctx, provider); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: unhealthy: %v", provider.Name(), err))
+			continue
+		}
+		for _, ip := range targets {
+			verdict, err := provider.IPReputation(ctx, ip)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s: %v", provider.Name(), ip, err))
+				continue
+			}
+			verdicts = append(verdicts, verdict)
+		}
+	}
+
+	if len(errs) > 0 {
+		return verdicts, fmt.Errorf("IP reputation checks failed (%s)", strings.Join(errs, "; "))
+	}
+	return verdicts, nil
+}
+
+// resolveMXAddresses returns the IPv4 addresses of a domain's mail servers
+func resolveMXAddresses(ctx context.Context, records []MXRecord) []string {
+	var ips []string
+	for _, mx := range records {
+		addrs, err := net.DefaultResolver.LookupIP(ctx, "ip4", strings.TrimSuffix(mx.Host, "."))
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ips = mergeStrings(ips, []string{addr.String()})
+		}
+	}
+	return ips
+}
+
+// displayIPReputation prints reputation verdicts grouped under a heading
+func displayIPReputation(verdicts []IPVerdict) {
+	if len(verdicts) == 0 {
+		return
+	}
+
+	color.Cyan("\n[IP Reputation]")
+	for _, verdict := range verdicts {
+		line := fmt.Sprintf("%s (%s): %s", verdict.IP, verdict.Provider, verdict.Classification)
+		if verdict.Score != nil {
+			line += fmt.Sprintf(", confidence %d%%", *verdict.Score)
+		}
+		switch {
+		case verdict.Malicious:
+			color.Red("✗ %s", line)
+		case verdict.Classification == "benign service":
+			color.Green("✓ %s", line)
+		default:
+			color.White("• %s", line)
+		}
+		if verdict.Details != "" {
+			color.White("  - %s", verdict.Details)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package osint
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+// WatchKeywords are the case's keywords, such as project names, addresses or
+// phone fragments, looked for in everything a scan collects
+var WatchKeywords []string
+
+// keywordContext is how many characters around a hit are kept as its snippet
+const keywordContext = 40
+
+// keywordSeparators may appear between the digits of a phone fragment
+const keywordSeparators = `[\s.\-()/]*`
+
+// KeywordHit is one place a keyword was found
+type KeywordHit struct {
+	Keyword string `json:"keyword"`
+	Match   string `json:"match"`            // The text as it appeared
+	Field   string `json:"field"`            // Where in the results, e.g. profiles[2].bio
+	Source  string `json:"source,omitempty"` // The URL of the profile, review or capture holding it
+	Snippet string `json:"snippet"`
+}
+
+// KeywordSummary counts the hits of one keyword
+type KeywordSummary struct {
+	Keyword string   `json:"keyword"`
+	Hits    int      `json:"hits"`
+	Sources []string `json:"sources,omitempty"`
+}
+
+// KeywordReport is where a case's keywords appear in a scan's results
+type KeywordReport struct {
+	Keywords []string         `json:"keywords"`
+	Summary  []KeywordSummary `json:"summary"`
+	Hits     []KeywordHit     `json:"hits,omitempty"`
+}
+
+// keywordPattern finds one keyword
+type keywordPattern struct {
+	keyword string
+	re      *regexp.Regexp
+}
+
+// KeywordWatch collects the hits of WatchKeywords across scanned values
+type KeywordWatch struct {
+	patterns []keywordPattern
+	hits     []KeywordHit
+}
+
+// LoadKeywords reads keywords from a file, one per line, skipping blank lines
+// and lines starting with #
+func LoadKeywords(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var keywords []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			keywords = append(keywords, line)
+		}
+	}
+	return keywords, scanner.Err()
+}
+
+// NewKeywordWatch compiles WatchKeywords, or returns nil when there are none
+func NewKeywordWatch() *KeywordWatch {
+	watch := &KeywordWatch{}
+	seen := make(map[string]bool)
+	for _, keyword := range WatchKeywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" || seen[strings.ToLower(keyword)] {
+			continue
+		}
+		seen[strings.ToLower(keyword)] = true
+		watch.patterns = append(watch.patterns, keywordPattern{keyword, keywordRegex(keyword)})
+	}
+	if len(watch.patterns) == 0 {
+		return nil
+	}
+	return watch
+}
+
+// keywordRegex matches a keyword regardless of case and of how its words are
+// spaced. A phone fragment, a keyword of digits and separators only, also
+// matches however its digits are separated.
+func keywordRegex(keyword string) *regexp.Regexp {
+	phone := countDigits(keyword) >= 3 && strings.Trim(keyword, "0123456789+ -.()/") == ""
+
+	var pattern strings.Builder
+	pattern.WriteString("(?i)")
+	if phone {
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, keyword)
+		for i, digit := range digits {
+			if i > 0 {
+				pattern.WriteString(keywordSeparators)
+			}
+			pattern.WriteRune(digit)
+		}
+		return regexp.MustCompile(pattern.String())
+	}
+	for i, word := range strings.Fields(keyword) {
+		if i > 0 {
+			pattern.WriteString(`\s+`)
+		}
+		pattern.WriteString(regexp.QuoteMeta(word))
+	}
+	return regexp.MustCompile(pattern.String())
+}
+
+// Scan looks for the keywords in every string of value, a result struct or
+// anything else that encodes to JSON, naming fields from path
+func (w *KeywordWatch) Scan(path string, value interface{}) {
+	if w == nil {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	var tree interface{}
+	if json.Unmarshal(data, &tree) != nil {
+		return
+	}
+	w.walk(path, "", tree)
+}
+
+// walk visits the strings of a decoded JSON tree. The source of a hit is the
+// url of the closest object that has one.
+func (w *KeywordWatch) walk(path, source string, node interface{}) {
+	switch node := node.(type) {
+	case map[string]interface{}:
+		if link, ok := node["url"].(string); ok && link != "" {
+			source = link
+		}
+		keys := make([]string, 0, len(node))
+		for key := range node {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			// Reports already attached to a result would match their own snippets
+			if key == "keyword_hits" {
+				continue
+			}
+			w.walk(joinKeywordPath(path, key), source, node[key])
+		}
+	case []interface{}:
+		for i, item := range node {
+			w.walk(fmt.Sprintf("%s[%d]", path, i), source, item)
+		}
+	case string:
+		w.match(path, source, node)
+	}
+}
+
+func joinKeywordPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// match records the hits of every keyword in text
+func (w *KeywordWatch) match(field, source, text string) {
+	for _, pattern := range w.patterns {
+		for _, loc := range pattern.re.FindAllStringIndex(text, -1) {
+			w.hits = append(w.hits, KeywordHit{
+				Keyword: pattern.keyword,
+				Match:   text[loc[0]:loc[1]],
+				Field:   field,
+				Source:  source,
+				Snippet: keywordSnippet(text, loc[0], loc[1]),
+			})
+		}
+	}
+}
+
+// keywordSnippet returns the hit with up to keywordContext characters either
+// side, cut at rune boundaries and with whitespace collapsed
+func keywordSnippet(text string, start, end int) string {
+	from, to := start, end
+	for n := 0; n < keywordContext && from > 0; n++ {
+		from--
+		for from > 0 && !utf8.RuneStart(text[from]) {
+			from--
+		}
+	}
+	for n := 0; n < keywordContext && to < len(text); n++ {
+		to++
+		for to < len(text) && !utf8.RuneStart(text[to]) {
+			to++
+		}
+	}
+	snippet := strings.Join(strings.FieldsFunc(text[from:to], unicode.IsSpace), " ")
+	if from > 0 {
+		snippet = "…" + snippet
+	}
+	if to < len(text) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// Report summarizes the hits per keyword, listing keywords that were not
+// found with no hits
+func (w *KeywordWatch) Report() *KeywordReport {
+	if w == nil {
+		return nil
+	}
+	report := &KeywordReport{Hits: w.hits}
+	for _, pattern := range w.patterns {
+		summary := KeywordSummary{Keyword: pattern.keyword}
+		for _, hit := range w.hits {
+			if hit.Keyword != pattern.keyword {
+				continue
+			}
+			summary.Hits++
+			source := hit.Source
+			if source == "" {
+				source = hit.Field
+			}
+			if !slices.Contains(summary.Sources, source) {
+				summary.Sources = append(summary.Sources, source)
+			}
+		}
+		report.Keywords = append(report.Keywords, pattern.keyword)
+		report.Summary = append(report.Summary, summary)
+	}
+	sort.SliceStable(report.Summary, func(i, j int) bool { return report.Summary[i].Hits > report.Summary[j].Hits })
+	return report
+}
+
+// DisplayResults prints the hit summary and every hit with the keyword highlighted
+func (r *KeywordReport) DisplayResults() {
+	color.Green("\n=== KEYWORD HITS ===")
+	for _, summary := range r.Summary {
+		if summary.Hits == 0 {
+			color.White("  %s: no hits", summary.Keyword)
+			continue
+		}
+		color.Yellow("  %s: %d hit(s) in %d source(s)", summary.Keyword, summary.Hits, len(summary.Sources))
+	}
+
+	highlight := color.New(color.FgBlack, color.BgYellow).SprintFunc()
+	for _, hit := range r.Hits {
+		color.Cyan("\n  [%s] %s", hit.Keyword, hit.Field)
+		if hit.Source != "" {
+			color.White("    %s", hit.Source)
+		}
+		match := strings.Join(strings.Fields(hit.Match), " ")
+		fmt.Printf("    %s\n", strings.Replace(hit.Snippet, match, highlight(match), 1))
+	}
+}
@@ -0,0 +1,152 @@
+package osint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+var (
+	// SummaryURL is the base of the OpenAI-compatible API asked for
+	// summaries, by default a local Ollama so results stay on the machine
+	SummaryURL = "http://localhost:11434/v1"
+	// SummaryModel is the model the summary endpoint runs
+	SummaryModel = "llama3.1"
+	// SummaryTimeout bounds one answer, since local models are slow
+	SummaryTimeout = 5 * time.Minute
+)
+
+// maxSummaryInput caps the encoded results sent to the model, which would
+// otherwise overflow the context window of most local models
+const maxSummaryInput = 48 << 10
+
+// summaryNotice is kept with every summary, wherever it is shown or saved
+const summaryNotice = "AI-generated by a language model from the collected results. It can be wrong or invent details; verify every statement against the evidence before acting on it."
+
+const summarySystemPrompt = `You assist an OSINT analyst. You are given the JSON results of one MercuriesOST module run.
+Write a short executive summary of what the results establish, naming the evidence each point rests on, and say when the results are thin or inconclusive.
+Then suggest up to 5 next pivots: specific usernames, emails, domains, IPs or platforms worth checking next, each with the reason.
+Only use facts present in the results; never guess personal details that are not there.
+Answer with a JSON object: {"summary": "...", "pivots": ["...", "..."]}`
+
+// Summary is a language model's reading of a run's results. It is advisory
+// only and always marked as AI-generated.
+type Summary struct {
+	AIGenerated bool      `json:"ai_generated"`
+	Notice      string    `json:"notice"`
+	Module      string    `json:"module"`
+	Target      string    `json:"target"`
+	ScanID      string    `json:"scan_id,omitempty"`
+	Provider    string    `json:"provider"`
+	Endpoint    string    `json:"endpoint"`
+	Summary     string    `json:"executive_summary"`
+	Pivots      []string  `json:"suggested_pivots,omitempty"`
+	Truncated   bool      `json:"input_truncated,omitempty"` // Only the start of the results was sent
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// Summarize asks the configured language model for an executive summary and
+// next pivots from a run's results
+func Summarize(ctx context.Context, module, target string, results interface{}) (*Summary, error) {
+	data, err := json.Marshal(results)
+	if err != nil {
+		return nil, err
+	}
+	summary := &Summary{
+		AIGenerated: true,
+		Notice:      summaryNotice,
+		Module:      module,
+		Target:      target,
+		ScanID:      providers.ScanID,
+		Endpoint:    SummaryURL,
+		Timestamp:   time.Now(),
+	}
+	if len(data) > maxSummaryInput {
+		data, summary.Truncated = data[:maxSummaryInput], true
+	}
+
+	prompt := fmt.Sprintf("Module: %s\nTarget: %s\n", module, target)
+	if summary.Truncated {
+		prompt += "The results were cut off to fit; do not read meaning into the missing end.\n"
+	}
+	prompt += "Results:\n" + string(data)
+
+	var answer string
+	provider, _, err := withFallback(ctx, SummaryProviders, func(p SummaryProvider) error {
+		var err error
+		answer, err = p.Complete(ctx, summarySystemPrompt, prompt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	summary.Provider = provider
+	summary.Summary, summary.Pivots = parseSummaryAnswer(answer)
+	return summary, nil
+}
+
+// parseSummaryAnswer reads the JSON object the model was asked for, falling
+// back to the whole answer as the summary when a model ignores the format
+func parseSummaryAnswer(answer string) (string, []string) {
+	text := strings.TrimSpace(answer)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "```"), "```")
+
+	var parsed struct {
+		Summary string   `json:"summary"`
+		Pivots  []string `json:"pivots"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(text)), &parsed); err != nil || parsed.Summary == "" {
+		return strings.TrimSpace(answer), nil
+	}
+	var pivots []string
+	for _, pivot := range parsed.Pivots {
+		if pivot = strings.TrimSpace(pivot); pivot != "" {
+			pivots = append(pivots, pivot)
+		}
+	}
+	return strings.TrimSpace(parsed.Summary), pivots
+}
+
+// Markdown renders the summary as a document, notice first
+func (s *Summary) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# AI-generated summary: %s %s\n\n", s.Module, s.Target)
+	fmt.Fprintf(&b, "> **%s**\n>\n> Model: %s at %s, %s", s.Notice, s.Provider, s.Endpoint, s.Timestamp.Format(time.RFC3339))
+	if s.ScanID != "" {
+		fmt.Fprintf(&b, ", scan %s", s.ScanID)
+	}
+	b.WriteString("\n")
+	if s.Truncated {
+		b.WriteString(">\n> Only the start of the results fit in the model's input.\n")
+	}
+	fmt.Fprintf(&b, "\n## Executive summary\n\n%s\n", s.Summary)
+	if len(s.Pivots) > 0 {
+		b.WriteString("\n## Suggested next pivots\n\n")
+		for _, pivot := range s.Pivots {
+			fmt.Fprintf(&b, "- %s\n", pivot)
+		}
+	}
+	return b.String()
+}
+
+// DisplayResults prints the summary under its AI-generated notice
+func (s *Summary) DisplayResults() {
+	color.Green("\n=== AI-GENERATED SUMMARY (%s) ===", s.Provider)
+	color.Yellow("%s", s.Notice)
+	if s.Truncated {
+		color.Yellow("Only the start of the results fit in the model's input.")
+	}
+	fmt.Printf("\n%s\n", s.Summary)
+	if len(s.Pivots) > 0 {
+		color.Cyan("\nSuggested next pivots:")
+		for _, pivot := range s.Pivots {
+			fmt.Printf("  - %s\n", pivot)
+		}
+	}
+}
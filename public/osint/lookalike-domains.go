@@ -0,0 +1,136 @@
+package osint
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LookalikeDomain is a registered domain that imitates a monitored name
+type LookalikeDomain struct {
+	Domain     string   `json:"domain"`
+	Technique  string   `json:"technique"`
+	IPs        []string `json:"ips,omitempty"`
+	MXRecords  []string `json:"mx_records,omitempty"`
+	NameServer string   `json:"name_server,omitempty"`
+}
+
+// Lookalike generation settings
+var (
+	LookalikeTLDs     = []string{"com", "net", "org", "io", "co", "info", "app", "online", "shop"}
+	maxLookalikeNames = 400
+)
+
+// lookalikeHomoglyphs maps characters to visually similar ASCII replacements
+var lookalikeHomoglyphs = map[rune][]string{
+	'a': {"4"}, 'b': {"d"}, 'd': {"b", "cl"}, 'e': {"3"}, 'g': {"q"},
+	'i': {"1", "l"}, 'l': {"1", "i"}, 'm': {"rn", "nn"}, 'n': {"m"},
+	'o': {"0"}, 'q': {"g"}, 's': {"5"}, 'u': {"v"}, 'v': {"u"}, 'w': {"vv"},
+}
+
+// lookalikeCandidates generates typo, homoglyph and TLD-swap permutations of a
+// domain, keyed by domain and labelled with the technique that produced them
+func lookalikeCandidates(domain string) map[string]string {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	label, tld := domain, "com"
+	if dot := strings.Index(domain, "."); dot > 0 {
+		label, tld = domain[:dot], domain[dot+1:]
+	}
+
+	candidates := make(map[string]string)
+	add := func(name, technique string) {
+		if name == "" || name == label || strings.HasPrefix(name, "-") || strings.HasSuffix(name, "-") {
+			return
+		}
+		full := name + "." + tld
+		if _, seen := candidates[full]; !seen && full != domain {
+			candidates[full] = technique
+		}
+	}
+
+	for i := range label {
+		add(label[:i]+label[i+1:], "omission")
+		add(label[:i]+string(label[i])+label[i:], "repetition")
+		if i+1 < len(label) {
+			add(label[:i]+string(label[i+1])+string(label[i])+label[i+2:], "transposition")
+		}
+		for _, glyph := range lookalikeHomoglyphs[rune(label[i])] {
+			add(label[:i]+glyph+label[i+1:], "homoglyph")
+		}
+		if i > 0 {
+			add(label[:i]+"-"+label[i:], "hyphenation")
+		}
+	}
+	for _, affix := range []string{"login", "secure", "support", "account"} {
+		add(label+"-"+affix, "keyword")
+		add(affix+"-"+label, "keyword")
+	}
+	for _, other := range LookalikeTLDs {
+		if other != tld {
+			full := label + "." + other
+			if _, seen := candidates[full]; !seen {
+				candidates[full] = "tld swap"
+			}
+		}
+	}
+	return candidates
+}
+
+// FindLookalikeDomains resolves permutations of a domain and returns those that
+// are registered, either delegated to name servers or pointing at hosts
+func FindLookalikeDomains(ctx context.Context, domain string) ([]LookalikeDomain, error) {
+	candidates := lookalikeCandidates(domain)
+	names := make([]string, 0, len(candidates))
+	for name := range candidates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) > maxLookalikeNames {
+		names = names[:maxLookalikeNames]
+	}
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		found []LookalikeDomain
+		sem   = make(chan struct{}, ConcurrentRequests)
+	)
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			lookalike := LookalikeDomain{Domain: name, Technique: candidates[name]}
+			if ns, err := net.DefaultResolver.LookupNS(ctx, name); err == nil && len(ns) > 0 {
+				lookalike.NameServer = strings.TrimSuffix(ns[0].Host, ".")
+			}
+			if addrs, err := net.DefaultResolver.LookupHost(ctx, name); err == nil {
+				lookalike.IPs = addrs
+			}
+			if lookalike.NameServer == "" && len(lookalike.IPs) == 0 {
+				return
+			}
+			if mx, err := net.DefaultResolver.LookupMX(ctx, name); err == nil {
+				for _, record := range mx {
+					lookalike.MXRecords = append(lookalike.MXRecords, strings.TrimSuffix(record.Host, "."))
+				}
+			}
+
+			mu.Lock()
+			found = append(found, lookalike)
+			mu.Unlock()
+		}(name)
+	}
+	wg.Wait()
+
+	sort.Slice(found, func(i, j int) bool { return found[i].Domain < found[j].Domain })
+	return found, ctx.Err()
+}
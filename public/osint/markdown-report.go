@@ -0,0 +1,379 @@
+package osint
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report formats a run's results can be summarised in with --report
+const (
+	ReportMarkdown = "markdown"
+)
+
+// ReportFormats lists the formats of --report
+var ReportFormats = []string{ReportMarkdown}
+
+// ReportPath returns a new file for a module's report, <target>_<time>.md in
+// the case's module directory when a case is given, otherwise in OutputDir
+func ReportPath(caseName, module, target string) string {
+	dir := OutputDir
+	if caseName != "" {
+		dir = filepath.Join(CaseDir(caseName), module)
+	}
+	file := strings.Trim(unsafeFileChars.ReplaceAllString(target, "_"), "_.")
+	if file == "" {
+		file = module
+	}
+	if len(file) > 64 {
+		file = file[:64]
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s_%s_%s.md", file, module, time.Now().Format("20060102_150405")))
+}
+
+// markdown builds a GitHub-flavored Markdown document
+type markdown struct {
+	strings.Builder
+}
+
+// WriteMarkdownReport writes a GitHub-flavored Markdown summary of a module's
+// results: tables of the profiles found, the breaches and the domain's
+// records, ready to paste into an issue or wiki page. Social, email, domain,
+// phone, Google ID and combined results are summarised.
+func WriteMarkdownReport(w io.Writer, module, target string, results interface{}) error {
+	var md markdown
+	fmt.Fprintf(&md, "# %s report: %s\n\n", mdEscape(module), mdEscape(target))
+	generated := "Generated " + time.Now().Format(time.RFC3339)
+
+	switch r := results.(type) {
+	case *SocialMediaResults:
+		md.header(generated, r.ScanID)
+		md.social(r, "##")
+	case *EmailAnalysisResult:
+		md.header(generated, r.ScanID)
+		md.email(r, "##")
+	case *DomainIntelResult:
+		md.header(generated, r.ScanID)
+		md.domain(r)
+	case *PhoneNumberResult:
+		md.header(generated, r.ScanID)
+		md.phone(r, "##")
+	case *GoogleIDResult:
+		md.header(generated, r.ScanID)
+		md.googleID(r, "##")
+	case *CombinedReport:
+		md.header(generated, r.ScanID)
+		if r.Email != nil {
+			fmt.Fprintf(&md, "## Email: %s\n\n", mdEscape(r.Email.Email))
+			md.email(r.Email, "###")
+		}
+		if r.Social != nil {
+			fmt.Fprintf(&md, "## Social media: %s\n\n", mdEscape(r.Social.Query))
+			md.social(r.Social, "###")
+		}
+		for _, phone := range r.Phones {
+			fmt.Fprintf(&md, "## Phone: %s\n\n", mdEscape(phone.E164Format))
+			md.phone(phone, "###")
+		}
+		for _, gid := range r.GoogleIDs {
+			fmt.Fprintf(&md, "## Google ID: %s\n\n", mdEscape(gid.GoogleID))
+			md.googleID(gid, "###")
+		}
+		md.errors(r.PartialErrors)
+	default:
+		return fmt.Errorf("%s results have no Markdown report", module)
+	}
+	_, err := io.WriteString(w, md.String())
+	return err
+}
+
+// header notes when and under which scan the results were gathered
+func (md *markdown) header(generated, scanID string) {
+	md.WriteString("> " + generated)
+	if scanID != "" {
+		md.WriteString(", scan `" + scanID + "`")
+	}
+	md.WriteString("\n\n")
+}
+
+// table writes a table, or nothing when it has no rows
+func (md *markdown) table(headers []string, rows [][]string) {
+	if len(rows) == 0 {
+		return
+	}
+	md.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+	md.WriteString("|" + strings.Repeat(" --- |", len(headers)) + "\n")
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = mdCell(cell)
+		}
+		md.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	md.WriteString("\n")
+}
+
+// fields writes label and value pairs as a two-column table, leaving out
+// empty values
+func (md *markdown) fields(pairs ...string) {
+	var rows [][]string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			rows = append(rows, []string{"**" + pairs[i] + "**", pairs[i+1]})
+		}
+	}
+	md.table([]string{"Field", "Value"}, rows)
+}
+
+// errors lists the lookups that failed, so empty tables read as missing
+// data rather than nothing found
+func (md *markdown) errors(errs []ModuleError) {
+	if len(errs) == 0 {
+		return
+	}
+	md.WriteString("<details><summary>Lookups that failed</summary>\n\n")
+	for _, err := range errs {
+		fmt.Fprintf(md, "- `%s`: %s\n", err.Module, mdEscape(err.Error))
+	}
+	md.WriteString("\n</details>\n\n")
+}
+
+// social writes the profiles found, strongest tier first, and the leads
+func (md *markdown) social(r *SocialMediaResults, level string) {
+	fmt.Fprintf(md, "**%d profiles found**", r.ProfilesFound)
+	if r.Interrupted {
+		md.WriteString(" (the search was stopped before every check ran)")
+	}
+	if len(r.Suppressed) > 0 {
+		fmt.Fprintf(md, ", %d suppressed as known false positives", len(r.Suppressed))
+	}
+	md.WriteString("\n\n")
+
+	var rows [][]string
+	for _, tier := range Tiers {
+		for _, profile := range r.Profiles {
+			if profile.Tier != tier {
+				continue
+			}
+			rows = append(rows, []string{profile.Platform, mdLink(profile.Username, profile.URL), profile.FullName, tier, reportPercent(profile.Confidence), profile.Location})
+		}
+	}
+	if len(rows) > 0 {
+		fmt.Fprintf(md, "%s Profiles\n\n", level)
+		md.table([]string{"Platform", "Profile", "Name", "Tier", "Confidence", "Location"}, rows)
+	}
+	if r.OmittedProfiles > 0 {
+		fmt.Fprintf(md, "_%d more profiles are in the saved results._\n\n", r.OmittedProfiles)
+	}
+
+	if len(r.Leads) > 0 {
+		fmt.Fprintf(md, "%s Leads\n\n", level)
+		rows = nil
+		for _, lead := range r.Leads {
+			rows = append(rows, []string{lead.Platform, mdLink(lead.URL, lead.URL), reportPercent(lead.Confidence), lead.Evidence})
+		}
+		md.table([]string{"Platform", "Profile", "Confidence", "Evidence"}, rows)
+	}
+	if len(r.Unreachable) > 0 {
+		fmt.Fprintf(md, "%s Platforms not searched\n\n", level)
+		for _, platform := range r.Unreachable {
+			fmt.Fprintf(md, "- %s: %s\n", mdEscape(platform.Platform), mdEscape(platform.Reason))
+		}
+		md.WriteString("\n")
+	}
+}
+
+// email writes the address's checks, its breaches, its domain and the
+// accounts linked to it
+func (md *markdown) email(r *EmailAnalysisResult, level string) {
+	security := r.SecurityInfo
+	md.fields(
+		"Valid format", strconv.FormatBool(r.ValidFormat),
+		"Risk score", fmt.Sprintf("%d/100", security.RiskScore),
+		"Breaches", strconv.Itoa(security.BreachCount),
+		"Last breach", security.LastBreachDate,
+		"Exposed data", strings.Join(security.ExposedDataTypes, ", "),
+		"Patterns", strings.Join(r.PatternAnalysis.Patterns, ", "),
+		"Services", strings.Join(r.CommonServices, ", "),
+	)
+
+	if len(security.BreachDetails) > 0 {
+		fmt.Fprintf(md, "%s Breaches\n\n", level)
+		var rows [][]string
+		for _, breach := range security.BreachDetails {
+			rows = append(rows, []string{breach.BreachName, breach.BreachDate, strings.Join(breach.CompromisedData, ", ")})
+		}
+		md.table([]string{"Breach", "Date", "Data exposed"}, rows)
+	}
+
+	fmt.Fprintf(md, "%s Domain: %s\n\n", level, mdEscape(r.Domain))
+	md.domainInfo(r.DomainInfo)
+
+	if len(r.SocialProfiles) > 0 {
+		fmt.Fprintf(md, "%s Linked accounts\n\n", level)
+		var rows [][]string
+		for _, profile := range r.SocialProfiles {
+			verified := ""
+			if profile.Verified {
+				verified = "yes"
+			}
+			rows = append(rows, []string{profile.Platform, mdLink(profile.Username, profile.URL), profile.DisplayName, profile.Tier, verified})
+		}
+		md.table([]string{"Platform", "Account", "Name", "Tier", "Verified"}, rows)
+	}
+	md.errors(r.PartialErrors)
+}
+
+// domainInfo writes a domain's registration and mail records
+func (md *markdown) domainInfo(info DomainInfo) {
+	health := ""
+	if info.DNSHealthScore > 0 {
+		health = fmt.Sprintf("%d/100", info.DNSHealthScore)
+	}
+	var mx []string
+	for _, record := range info.MXRecords {
+		mx = append(mx, fmt.Sprintf("%s (%d)", record.Host, record.Priority))
+	}
+	md.fields(
+		"Registrar", info.Registrar,
+		"Created", info.CreationDate,
+		"Expires", info.ExpiryDate,
+		"IP addresses", strings.Join(info.IPAddresses, ", "),
+		"Mail servers", strings.Join(mx, ", "),
+		"SPF", info.SPFRecord,
+		"DMARC", info.DMARCRecord,
+		"Country", info.GeoIPInfo.Country,
+		"DNS health", health,
+	)
+}
+
+// domain writes a domain's records, the technologies and sites sharing its
+// tracking IDs, and what its checks found exposed
+func (md *markdown) domain(r *DomainIntelResult) {
+	md.WriteString("## Domain\n\n")
+	md.domainInfo(r.DNS)
+
+	if vt := r.VirusTotal; vt != nil {
+		md.WriteString("## Reputation\n\n")
+		md.fields(
+			"VirusTotal", fmt.Sprintf("%d malicious, %d suspicious of %d engines", vt.Malicious, vt.Suspicious, vt.Engines),
+			"Categories", strings.Join(vt.Categories, ", "),
+			"Link", vt.Link,
+		)
+	}
+	if len(r.Technologies) > 0 {
+		md.WriteString("## Technologies\n\n")
+		var rows [][]string
+		for _, tech := range r.Technologies {
+			rows = append(rows, []string{tech.Name, tech.Category, tech.Version})
+		}
+		md.table([]string{"Technology", "Category", "Version"}, rows)
+	}
+	if len(r.RelatedDomains) > 0 {
+		md.WriteString("## Related domains\n\n")
+		var rows [][]string
+		for _, related := range r.RelatedDomains {
+			rows = append(rows, []string{related.Domain, related.IDType, "`" + related.SharedID + "`"})
+		}
+		md.table([]string{"Domain", "Shared", "ID"}, rows)
+	}
+	if len(r.ExposedPaths) > 0 {
+		md.WriteString("## Exposed paths\n\n")
+		var rows [][]string
+		for _, probe := range r.ExposedPaths {
+			rows = append(rows, []string{mdLink(probe.Path, probe.URL), strconv.Itoa(probe.StatusCode), probe.ContentType})
+		}
+		md.table([]string{"Path", "Status", "Type"}, rows)
+	}
+	if len(r.Takeovers) > 0 {
+		md.WriteString("## Subdomain takeover\n\n")
+		var rows [][]string
+		for _, takeover := range r.Takeovers {
+			vulnerable := "no"
+			if takeover.Vulnerable {
+				vulnerable = "**yes**"
+			}
+			rows = append(rows, []string{takeover.Host, takeover.Record + " " + takeover.Target, takeover.Service, vulnerable})
+		}
+		md.table([]string{"Host", "Record", "Service", "Vulnerable"}, rows)
+	}
+	if len(r.Emails) > 0 {
+		md.WriteString("## Email addresses\n\n")
+		for _, email := range r.Emails {
+			fmt.Fprintf(md, "- %s\n", mdEscape(email))
+		}
+		md.WriteString("\n")
+	}
+	md.errors(r.PartialErrors)
+}
+
+// phone writes a number's carrier, region and risk
+func (md *markdown) phone(r *PhoneNumberResult, level string) {
+	md.fields(
+		"Country", r.CountryName,
+		"Region", r.Region,
+		"Type", r.Type,
+		"Carrier", r.Carrier.Name,
+		"Time zones", strings.Join(r.TimeZones, ", "),
+		"Risk", r.RiskAssessment.Level,
+		"Possible owners", strings.Join(r.ReverseLookup.PossibleOwners, ", "),
+	)
+	if len(r.MessagingApps) > 0 {
+		fmt.Fprintf(md, "%s Messaging apps\n\n", level)
+		var rows [][]string
+		for _, app := range r.MessagingApps {
+			rows = append(rows, []string{app.Name, app.Status, app.LastSeen})
+		}
+		md.table([]string{"App", "Status", "Last seen"}, rows)
+	}
+}
+
+// googleID writes a Google account's contributions and reviews
+func (md *markdown) googleID(r *GoogleIDResult, level string) {
+	md.fields(
+		"Name", r.DisplayName,
+		"Contributions", fmt.Sprintf("%d reviews, %d photos, %d ratings", r.Contributions.TotalReviews, r.Contributions.TotalPhotos, r.Contributions.TotalRatings),
+		"Last seen", r.LastSeen,
+	)
+	if len(r.Reviews) > 0 {
+		fmt.Fprintf(md, "%s Reviews\n\n", level)
+		var rows [][]string
+		for _, review := range r.Reviews {
+			rows = append(rows, []string{review.Location, fmt.Sprintf("%d/5", review.Rating), review.ReviewDate})
+		}
+		md.table([]string{"Place", "Rating", "Date"}, rows)
+	}
+}
+
+// mdEscape keeps text from being read as Markdown or HTML
+func mdEscape(s string) string {
+	return mdEscaper.Replace(strings.Join(strings.Fields(s), " "))
+}
+
+var mdEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", "&lt;", ">", "&gt;", "|", `\|`, "#", `\#`,
+)
+
+// mdCell escapes a table cell, keeping the bold labels and links the report
+// puts in cells
+func mdCell(s string) string {
+	if strings.HasPrefix(s, "**") || strings.HasPrefix(s, "[") || strings.HasPrefix(s, "`") {
+		return s
+	}
+	return mdEscape(s)
+}
+
+// mdLink writes a link, or the bare text when there is no URL
+func mdLink(text, link string) string {
+	if text == "" {
+		text = link
+	}
+	if link == "" {
+		return mdEscape(text)
+	}
+	return "[" + mdEscape(text) + "](" + strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(link) + ")"
+}
@@ -0,0 +1,252 @@
+package osint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+)
+
+// maxPlaylists caps the playlist names kept per profile
+const maxPlaylists = 10
+
+// spotifyToken caches the client credentials token of the Spotify Web API
+var spotifyToken struct {
+	sync.Mutex
+	value   string
+	expires time.Time
+}
+
+// spotifyAccessToken returns a Spotify Web API token, requesting a new one
+// with the configured client ID and secret when the cached one has expired
+func spotifyAccessToken(ctx context.Context) (string, error) {
+	spotifyToken.Lock()
+	defer spotifyToken.Unlock()
+	if spotifyToken.value != "" && time.Now().Before(spotifyToken.expires) {
+		return spotifyToken.value, nil
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	headers := map[string]string{
+		"Authorization": basicAuth(APIConfig.SpotifyClientID, APIConfig.SpotifyClientSecret),
+		"Content-Type":  "application/x-www-form-urlencoded",
+	}
+	body := strings.NewReader(url.Values{"grant_type": {"client_credentials"}}.Encode())
+	if err := postProviderJSON(ctx, "https://accounts.spotify.com/api/token", headers, body, &token); err != nil {
+		return "", fmt.Errorf("spotify token: %v", err)
+	}
+	// Renew a minute early so a token never expires mid-scan
+	spotifyToken.value = token.AccessToken
+	spotifyToken.expires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return spotifyToken.value, nil
+}
+
+// spotifyProfile reads a Spotify user's display name, followers and public
+// playlists from the Web API
+func spotifyProfile(ctx context.Context, handle string, result *ProfileResult) (bool, error) {
+	if !apiKeyConfigured(APIConfig.SpotifyClientID) || !apiKeyConfigured(APIConfig.SpotifyClientSecret) {
+		return false, errNoProfileLookup
+	}
+	token, err := spotifyAccessToken(ctx)
+	if err != nil {
+		return false, err
+	}
+	headers := map[string]string{"Authorization": "Bearer " + token}
+	base := "https://api.spotify.com/v1/users/" + url.PathEscape(handle)
+
+	var user struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"display_name"`
+		Followers   struct {
+			Total int `json:"total"`
+		} `json:"followers"`
+		Images []struct {
+			URL string `json:"url"`
+		} `json:"images"`
+	}
+	if err := getProviderJSON(ctx, base, headers, &user); err != nil {
+		if providers.IsStatus(err, http.StatusNotFound) || providers.IsStatus(err, http.StatusBadRequest) {
+			return false, nil
+		}
+		return false, err
+	}
+	result.Evidence = EvidenceAPI
+	result.Confidence = 1
+	result.CanonicalID = user.ID
+	result.FullName = user.DisplayName
+	result.FollowerCount = user.Followers.Total
+	if len(user.Images) > 0 {
+		result.Avatar = user.Images[0].URL
+	}
+
+	var playlists struct {
+		Total int `json:"total"`
+		Items []struct {
+			Name string `json:"name"`
+		} `json:"items"`
+	}
+	if err := getProviderJSON(ctx, base+"/playlists?limit="+strconv.Itoa(maxPlaylists), headers, &playlists); err != nil {
+		// The profile exists even when its playlists cannot be listed
+		result.Insights = append(result.Insights, fmt.Sprintf("Playlists not listed: %v", err))
+		return true, nil
+	}
+	result.PlaylistCount = playlists.Total
+	for _, playlist := range playlists.Items {
+		result.Playlists = append(result.Playlists, playlist.Name)
+	}
+	return true, nil
+}
+
+// lastFMProfile reads a Last.fm user's real name, country, registration
+// date and scrobble count from the Last.fm API
+func lastFMProfile(ctx context.Context, handle string, result *ProfileResult) (bool, error) {
+	if !apiKeyConfigured(APIConfig.LastFMKey) {
+		return false, errNoProfileLookup
+	}
+	query := url.Values{
+		"method":  {"user.getinfo"},
+		"user":    {handle},
+		"api_key": {APIConfig.LastFMKey},
+		"format":  {"json"},
+	}
+	var info struct {
+		Error int `json:"error"`
+		User  struct {
+			Name       string `json:"name"`
+			RealName   string `json:"realname"`
+			Country    string `json:"country"`
+			PlayCount  string `json:"playcount"`
+			Playlists  string `json:"playlists"`
+			Registered struct {
+				UnixTime string `json:"unixtime"`
+			} `json:"registered"`
+			Image []struct {
+				URL string `json:"#text"`
+			} `json:"image"`
+		} `json:"user"`
+	}
+	// Unknown users are error 6, answered with a 404
+	err := getProviderJSON(ctx, "https://ws.audioscrobbler.com/2.0/?"+query.Encode(), nil, &info)
+	if providers.IsStatus(err, http.StatusNotFound) || info.Error == 6 {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if info.Error != 0 {
+		return false, fmt.Errorf("last.fm error %d", info.Error)
+	}
+
+	user := info.User
+	result.Evidence = EvidenceAPI
+	result.Confidence = 1
+	result.FullName = user.RealName
+	if user.Country != "" && user.Country != "None" {
+		result.Location = user.Country
+	}
+	if seconds, err := strconv.ParseInt(user.Registered.UnixTime, 10, 64); err == nil && seconds > 0 {
+		result.JoinDate = time.Unix(seconds, 0).UTC().Format("2006-01-02")
+	}
+	if count, err := strconv.Atoi(user.Playlists); err == nil {
+		result.PlaylistCount = count
+	}
+	if user.PlayCount != "" && user.PlayCount != "0" {
+		result.Insights = append(result.Insights, fmt.Sprintf("Scrobbles: %s", user.PlayCount))
+	}
+	if n := len(user.Image); n > 0 {
+		result.Avatar = user.Image[n-1].URL
+	}
+	return true, nil
+}
+
+// soundCloudHydrationRegex captures the data a SoundCloud page is built from
+var soundCloudHydrationRegex = regexp.MustCompile(`(?s)window\.__sc_hydration\s*=\s*(\[.*?\]);\s*</script>`)
+
+// soundCloudProfile reads a SoundCloud user's name, city, followers and
+// track and playlist counts from the data embedded in their profile page,
+// since SoundCloud issues no new API keys
+func soundCloudProfile(ctx context.Context, handle string, result *ProfileResult) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://soundcloud.com/"+url.PathEscape(handle), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	resp, err := doProviderRequest(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := readPageBody(resp)
+	if err != nil {
+		return false, err
+	}
+
+	m := soundCloudHydrationRegex.FindSubmatch(body.Data)
+	if m == nil {
+		return false, errNoProfileLookup
+	}
+	// Entries other than the user carry data of other shapes
+	var hydration []struct {
+		Hydratable string          `json:"hydratable"`
+		Data       json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(m[1], &hydration); err != nil {
+		return false, errNoProfileLookup
+	}
+	for _, entry := range hydration {
+		if entry.Hydratable != "user" {
+			continue
+		}
+		var user struct {
+			ID          int64  `json:"id"`
+			FullName    string `json:"full_name"`
+			Description string `json:"description"`
+			City        string `json:"city"`
+			CountryCode string `json:"country_code"`
+			AvatarURL   string `json:"avatar_url"`
+			CreatedAt   string `json:"created_at"`
+			Followers   int    `json:"followers_count"`
+			Tracks      int    `json:"track_count"`
+			Playlists   int    `json:"playlist_count"`
+		}
+		if err := json.Unmarshal(entry.Data, &user); err != nil {
+			return false, errNoProfileLookup
+		}
+		result.Confidence = 0.9
+		if user.ID != 0 {
+			result.CanonicalID = strconv.FormatInt(user.ID, 10)
+		}
+		result.FullName = user.FullName
+		result.Bio = cleanText(user.Description)
+		result.Location = strings.Trim(strings.Join([]string{user.City, user.CountryCode}, ", "), ", ")
+		result.Avatar = user.AvatarURL
+		result.FollowerCount = user.Followers
+		result.PlaylistCount = user.Playlists
+		if created, err := time.Parse(time.RFC3339, user.CreatedAt); err == nil {
+			result.JoinDate = created.Format("2006-01-02")
+		}
+		if user.Tracks > 0 {
+			result.Insights = append(result.Insights, fmt.Sprintf("Tracks uploaded: %d", user.Tracks))
+		}
+		return true, nil
+	}
+	// Pages without a user are sign-up and error pages
+	return false, nil
+}
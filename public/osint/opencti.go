@@ -0,0 +1,221 @@
+package osint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// OpenCTI export settings
+var (
+	OpenCTIConfidence = 50 // Confidence given to relationships, 0-100
+	openCTIIOCScore   = 80 // x_opencti_score given to observables flagged as IOCs
+)
+
+// openCTIObservableTypes maps observable data types to the STIX type, mutation
+// argument and GraphQL input type of stixCyberObservableAdd
+var openCTIObservableTypes = map[string][3]string{
+	"mail":   {"Email-Addr", "EmailAddr", "EmailAddrAddInput"},
+	"domain": {"Domain-Name", "DomainName", "DomainNameAddInput"},
+	"fqdn":   {"Hostname", "Hostname", "HostnameAddInput"},
+	"url":    {"Url", "Url", "UrlAddInput"},
+	"ipv4":   {"IPv4-Addr", "IPv4Addr", "IPv4AddrAddInput"},
+	"ipv6":   {"IPv6-Addr", "IPv6Addr", "IPv6AddrAddInput"},
+	"phone":  {"Phone-Number", "PhoneNumber", "PhoneNumberAddInput"},
+	"hash":   {"StixFile", "StixFile", "StixFileAddInput"},
+}
+
+// openCTIHashAlgorithms names hex digests by length for StixFile hashes
+var openCTIHashAlgorithms = map[int]string{32: "MD5", 40: "SHA-1", 64: "SHA-256", 128: "SHA-512"}
+
+// OpenCTIExport summarizes what was created on an OpenCTI instance
+type OpenCTIExport struct {
+	Observables   int `json:"observables"`
+	Relationships int `json:"relationships"`
+}
+
+// PushOpenCTI creates the observables on an OpenCTI instance through its GraphQL
+// API and links each one to the first, the module's target, with a related-to
+// relationship. Pages backing an observable become external references.
+func PushOpenCTI(ctx context.Context, baseURL, module string, observables []Observable) (*OpenCTIExport, error) {
+	if !apiKeyConfigured(APIConfig.OpenCTIKey) {
+		return nil, fmt.Errorf("OpenCTI API key not configured")
+	}
+	if len(observables) == 0 {
+		return nil, fmt.Errorf("nothing to export")
+	}
+	endpoint := strings.TrimSuffix(baseURL, "/") + "/graphql"
+
+	// Every object cites the module run; observables checked against an
+	// outside page cite that page too
+	moduleRef, err := openCTIReference(ctx, endpoint, "Collected by the MercuriesOST "+module+" module", "")
+	if err != nil {
+		return nil, fmt.Errorf("create reference: %v", err)
+	}
+
+	export := &OpenCTIExport{}
+	var errs []string
+	var rootID string
+
+	for i, observable := range observables {
+		refs := []string{moduleRef}
+		if observable.Reference != "" {
+			ref, err := openCTIReference(ctx, endpoint, observable.Message, observable.Reference)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("reference for %s: %v", observable.Data, err))
+			} else {
+				refs = append(refs, ref)
+			}
+		}
+
+		id, err := openCTIAddObservable(ctx, endpoint, observable, refs)
+		if err != nil {
+			// Without the target there is nothing to relate the rest to
+			if i == 0 {
+				return export, fmt.Errorf("create %s: %v", observable.Data, err)
+			}
+			errs = append(errs, fmt.Sprintf("create %s: %v", observable.Data, err))
+			continue
+		}
+		export.Observables++
+
+		if i == 0 {
+			rootID = id
+			continue
+		}
+		if err := openCTIRelate(ctx, endpoint, rootID, id, observable.Message, refs); err != nil {
+			errs = append(errs, fmt.Sprintf("relate %s: %v", observable.Data, err))
+			continue
+		}
+		export.Relationships++
+	}
+
+	if len(errs) > 0 {
+		return export, fmt.Errorf("OpenCTI export incomplete (%s)", strings.Join(errs, "; "))
+	}
+	return export, nil
+}
+
+// openCTIAddObservable creates or updates an observable and returns its ID
+func openCTIAddObservable(ctx context.Context, endpoint string, observable Observable, refs []string) (string, error) {
+	dataType := observable.DataType
+	switch dataType {
+	case "ip":
+		dataType = "ipv4"
+		if ip := net.ParseIP(observable.Data); ip != nil && ip.To4() == nil {
+			dataType = "ipv6"
+		}
+	case "other":
+		// Free-form observables only come from the phone module
+		dataType = "phone"
+	}
+	types, ok := openCTIObservableTypes[dataType]
+	if !ok {
+		return "", fmt.Errorf("unsupported data type %q", observable.DataType)
+	}
+
+	query := fmt.Sprintf(`mutation AddObservable($type: String!, $score: Int, $description: String, $refs: [String], $labels: [String], $input: %s) {
+  stixCyberObservableAdd(type: $type, x_opencti_score: $score, x_opencti_description: $description, externalReferences: $refs, objectLabel: $labels, update: true, %s: $input) { id }
+}`, types[2], types[1])
+
+	variables := map[string]interface{}{
+		"type":        types[0],
+		"description": observable.Message,
+		"refs":        refs,
+		"labels":      observable.Tags,
+		"input":       map[string]interface{}{"value": observable.Data},
+	}
+	if dataType == "hash" {
+		algorithm, ok := openCTIHashAlgorithms[len(observable.Data)]
+		if !ok {
+			return "", fmt.Errorf("unsupported hash length %d", len(observable.Data))
+		}
+		variables["input"] = map[string]interface{}{
+			"hashes": []map[string]string{{"algorithm": algorithm, "hash": observable.Data}},
+		}
+	}
+	if observable.IOC {
+		variables["score"] = openCTIIOCScore
+	}
+
+	var data struct {
+		Add struct {
+			ID string `json:"id"`
+		} `json:"stixCyberObservableAdd"`
+	}
+	if err := openCTIQuery(ctx, endpoint, query, variables, &data); err != nil {
+		return "", err
+	}
+	return data.Add.ID, nil
+}
+
+// openCTIReference creates an external reference and returns its ID
+func openCTIReference(ctx context.Context, endpoint, description, link string) (string, error) {
+	const query = `mutation AddReference($input: ExternalReferenceAddInput!) {
+  externalReferenceAdd(input: $input) { id }
+}`
+	input := map[string]string{"source_name": "MercuriesOST", "description": description}
+	if link != "" {
+		input["url"] = link
+	}
+
+	var data struct {
+		Add struct {
+			ID string `json:"id"`
+		} `json:"externalReferenceAdd"`
+	}
+	if err := openCTIQuery(ctx, endpoint, query, map[string]interface{}{"input": input}, &data); err != nil {
+		return "", err
+	}
+	return data.Add.ID, nil
+}
+
+// openCTIRelate links two objects with a related-to relationship
+func openCTIRelate(ctx context.Context, endpoint, fromID, toID, description string, refs []string) error {
+	const query = `mutation Relate($input: StixCoreRelationshipAddInput!) {
+  stixCoreRelationshipAdd(input: $input) { id }
+}`
+	input := map[string]interface{}{
+		"fromId":             fromID,
+		"toId":               toID,
+		"relationship_type":  "related-to",
+		"confidence":         OpenCTIConfidence,
+		"description":        description,
+		"externalReferences": refs,
+	}
+	var data json.RawMessage
+	return openCTIQuery(ctx, endpoint, query, map[string]interface{}{"input": input}, &data)
+}
+
+// openCTIQuery runs a GraphQL request, surfacing GraphQL errors as Go errors
+func openCTIQuery(ctx context.Context, endpoint, query string, variables map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	headers := map[string]string{
+		"Authorization": "Bearer " + APIConfig.OpenCTIKey,
+		"Content-Type":  "application/json",
+	}
+	if err := postProviderJSON(ctx, endpoint, headers, bytes.NewReader(body), &response); err != nil {
+		return err
+	}
+	if len(response.Errors) > 0 {
+		var messages []string
+		for _, e := range response.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf("%s", strings.Join(messages, "; "))
+	}
+	return json.Unmarshal(response.Data, out)
+}
@@ -0,0 +1,202 @@
+package osint
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Output formats of a command's results
+const (
+	FormatTable = "table" // Colored text for a terminal
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+	FormatYAML  = "yaml"
+)
+
+// OutputFormats lists the formats, the default first
+var OutputFormats = []string{FormatTable, FormatJSON, FormatCSV, FormatYAML}
+
+// CSVTable is implemented by results that read best as one row per item
+// rather than one row per field
+type CSVTable interface {
+	CSVRows() [][]string // Header first
+}
+
+// yamlPlain matches strings safe to write unquoted in YAML
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9 _./@+()-]*$`)
+
+// WriteFormat writes results as a json, csv or yaml document
+func WriteFormat(w io.Writer, format string, results interface{}) error {
+	if format == FormatJSON {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	// Other formats walk the results as their JSON encoding, so field names
+	// and omitted fields match the JSON output
+	data, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatCSV:
+		var rows [][]string
+		if table, ok := results.(CSVTable); ok {
+			rows = table.CSVRows()
+		} else {
+			rows = [][]string{{"field", "value"}}
+			flattenFields("", tree, &rows)
+		}
+		out := csv.NewWriter(w)
+		out.WriteAll(rows)
+		return out.Error()
+	case FormatYAML:
+		var b strings.Builder
+		writeYAML(&b, tree, 0)
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	return fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join(OutputFormats, ", "))
+}
+
+// CSVRows lists one profile per row, leads after the profiles
+func (r *SocialMediaResults) CSVRows() [][]string {
+	rows := [][]string{{"platform", "url", "username", "full_name", "confidence", "follower_count", "location", "join_date", "lead", "tier", "evidence"}}
+	add := func(profile ProfileResult, lead bool) {
+		rows = append(rows, []string{
+			profile.Platform,
+			profile.URL,
+			profile.Username,
+			profile.FullName,
+			strconv.FormatFloat(profile.Confidence, 'f', 2, 64),
+			strconv.Itoa(profile.FollowerCount),
+			profile.Location,
+			profile.JoinDate,
+			strconv.FormatBool(lead),
+			profile.Tier,
+			profile.Evidence,
+		})
+	}
+	for _, profile := range r.Profiles {
+		add(profile, false)
+	}
+	for _, profile := range r.Leads {
+		add(profile, true)
+	}
+	return rows
+}
+
+// flattenFields lists every scalar under node as a path such as
+// profiles[0].url and its value
+func flattenFields(path string, node interface{}, rows *[][]string) {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(value) {
+			field := key
+			if path != "" {
+				field = path + "." + key
+			}
+			flattenFields(field, value[key], rows)
+		}
+	case []interface{}:
+		for i, item := range value {
+			flattenFields(fmt.Sprintf("%s[%d]", path, i), item, rows)
+		}
+	case nil:
+		*rows = append(*rows, []string{path, ""})
+	default:
+		*rows = append(*rows, []string{path, fmt.Sprint(value)})
+	}
+}
+
+func sortedKeys(mapping map[string]interface{}) []string {
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeYAML writes node as block YAML indented by indent spaces
+func writeYAML(b *strings.Builder, node interface{}, indent int) {
+	pad := strings.Repeat(" ", indent)
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(value) {
+			b.WriteString(pad + yamlScalar(key) + ":")
+			writeYAMLValue(b, value[key], indent)
+		}
+	case []interface{}:
+		for _, item := range value {
+			b.WriteString(pad + "-")
+			writeYAMLValue(b, item, indent)
+		}
+	default:
+		b.WriteString(pad + yamlScalar(value) + "\n")
+	}
+}
+
+// writeYAMLValue writes what follows a key or list dash: a scalar or empty
+// collection on the same line, or a nested block on the lines below
+func writeYAMLValue(b *strings.Builder, value interface{}, indent int) {
+	switch nested := value.(type) {
+	case map[string]interface{}:
+		if len(nested) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+	case []interface{}:
+		if len(nested) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+	default:
+		b.WriteString(" " + yamlScalar(value) + "\n")
+		return
+	}
+	b.WriteString("\n")
+	writeYAML(b, value, indent+2)
+}
+
+// yamlScalar writes a scalar plain when YAML reads it back as the same
+// string, and double-quoted otherwise
+func yamlScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		switch strings.ToLower(v) {
+		case "~", "null", "true", "false", "yes", "no", "on", "off", "y", "n":
+		default:
+			if yamlPlain.MatchString(v) && !strings.HasSuffix(v, " ") {
+				return v
+			}
+		}
+		quoted, _ := json.Marshal(v)
+		return string(quoted)
+	}
+	return fmt.Sprint(value)
+}
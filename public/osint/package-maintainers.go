@@ -0,0 +1,498 @@
+package osint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/input"
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultPackageCount is how many packages FindPackageMaintainers reads
+const DefaultPackageCount = 20
+
+const (
+	// MaxPackageCount caps the packages read in one scan
+	MaxPackageCount = 100
+	// maxGitHubOwners caps the repository owners looked up on GitHub, whose
+	// API allows 60 requests an hour without a token
+	maxGitHubOwners = 5
+)
+
+var (
+	// npmPersonRegex reads the "Name <email> (url)" form of package.json people
+	npmPersonRegex = regexp.MustCompile(`^([^<(]*?)\s*(?:<([^>]*)>)?\s*(?:\(([^)]*)\))?$`)
+	// githubRepoOwnerRegex finds the owner in the repository URLs and
+	// shorthands package.json allows
+	githubRepoOwnerRegex = regexp.MustCompile(`^(?:(?:git\+)?(?:https?|git|ssh)://(?:git@)?github\.com[/:]|git@github\.com:|github:)?([A-Za-z0-9](?:-?[A-Za-z0-9])*)/[A-Za-z0-9._-]+?(?:\.git)?/?$`)
+	// githubProfileRegex finds the user in a github.com profile URL
+	githubProfileRegex = regexp.MustCompile(`^https?://(?:www\.)?github\.com/([A-Za-z0-9](?:-?[A-Za-z0-9])*)/?$`)
+)
+
+// NpmPackage is a package the target maintains, with the people its
+// package.json and the registry name
+type NpmPackage struct {
+	Name         string   `json:"name"`
+	Version      string   `json:"version"`
+	URL          string   `json:"url"`
+	Repository   string   `json:"repository,omitempty"`
+	Maintainers  []string `json:"maintainers"`
+	Publisher    string   `json:"publisher,omitempty"` // npm account that published the version read
+	Author       string   `json:"author,omitempty"`
+	Contributors []string `json:"contributors,omitempty"`
+}
+
+// CoMaintainer is another npm account maintaining some of the target's packages
+type CoMaintainer struct {
+	Handle   string   `json:"handle"`
+	Email    string   `json:"email,omitempty"`
+	Packages []string `json:"packages"`
+	Identity int      `json:"identity,omitempty"` // ID of the identity cluster holding the account
+}
+
+// PackageGraphResult is what a developer's npm packages reveal: who they
+// maintain them with, and the handles and emails that link into identities
+type PackageGraphResult struct {
+	Target        string         `json:"target"`
+	ScanID        string         `json:"scan_id,omitempty"`
+	Timestamp     string         `json:"timestamp"`
+	Packages      []NpmPackage   `json:"packages"`
+	CoMaintainers []CoMaintainer `json:"co_maintainers"`
+	Identities    []AliasCluster `json:"identities"`
+	Identity      int            `json:"identity,omitempty"` // ID of the identity cluster holding the target
+	Unlinked      []Identifier   `json:"unlinked,omitempty"`
+	PartialErrors []ModuleError  `json:"partial_errors,omitempty"`
+	ExecutionTime string         `json:"execution_time"`
+}
+
+// npmSearchResult is the part of the registry's search response that is used
+type npmSearchResult struct {
+	Objects []struct {
+		Package struct {
+			Name        string      `json:"name"`
+			Version     string      `json:"version"`
+			Maintainers []npmPerson `json:"maintainers"`
+			Links       struct {
+				NPM string `json:"npm"`
+			} `json:"links"`
+		} `json:"package"`
+	} `json:"objects"`
+}
+
+// npmPerson is a maintainer or publisher as the registry lists them
+type npmPerson struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+}
+
+// handle returns the account name, which search results and package
+// documents keep under different keys
+func (p npmPerson) handle() string {
+	if p.Username != "" {
+		return strings.ToLower(p.Username)
+	}
+	return strings.ToLower(p.Name)
+}
+
+// npmVersion is the part of a published package.json that is used
+type npmVersion struct {
+	Author       json.RawMessage   `json:"author"`
+	Contributors []json.RawMessage `json:"contributors"`
+	Maintainers  []npmPerson       `json:"maintainers"`
+	NPMUser      npmPerson         `json:"_npmUser"`
+	Repository   json.RawMessage   `json:"repository"`
+}
+
+// packagePerson is a package.json author or contributor
+type packagePerson struct {
+	Name  string
+	Email string
+	URL   string
+}
+
+// String formats the person the way package.json writes them
+func (p packagePerson) String() string {
+	s := p.Name
+	if p.Email != "" {
+		s = strings.TrimSpace(s + " <" + p.Email + ">")
+	}
+	if p.URL != "" {
+		s = strings.TrimSpace(s + " (" + p.URL + ")")
+	}
+	return s
+}
+
+// parsePackagePerson reads a package.json person, written either as an
+// object or as "Name <email> (url)"
+func parsePackagePerson(raw json.RawMessage) (packagePerson, bool) {
+	var person struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+		URL   string `json:"url"`
+	}
+	if json.Unmarshal(raw, &person) != nil {
+		var text string
+		if json.Unmarshal(raw, &text) != nil {
+			return packagePerson{}, false
+		}
+		m := npmPersonRegex.FindStringSubmatch(strings.TrimSpace(text))
+		if m == nil {
+			return packagePerson{}, false
+		}
+		person.Name, person.Email, person.URL = m[1], m[2], m[3]
+	}
+	p := packagePerson{Name: strings.TrimSpace(person.Name), Email: strings.TrimSpace(person.Email), URL: strings.TrimSpace(person.URL)}
+	return p, p != packagePerson{}
+}
+
+// repositoryURL reads package.json's repository, an object or a string
+func repositoryURL(raw json.RawMessage) string {
+	var repo struct {
+		URL string `json:"url"`
+	}
+	if json.Unmarshal(raw, &repo) == nil {
+		return repo.URL
+	}
+	var text string
+	json.Unmarshal(raw, &text)
+	return text
+}
+
+// identityGraph collects the handles and emails of a scan and the evidence
+// linking them, for clusterLinks
+type identityGraph struct {
+	ids   []*Identifier
+	byKey map[string]*Identifier
+	links map[[2]string]AliasLink
+}
+
+func newIdentityGraph() *identityGraph {
+	return &identityGraph{byKey: make(map[string]*Identifier), links: make(map[[2]string]AliasLink)}
+}
+
+// handle adds an account on a platform, written "platform:handle"
+func (g *identityGraph) handle(platform, handle string) *Identifier {
+	handle = strings.ToLower(handle)
+	value := platform + ":" + handle
+	if id := g.byKey[value]; id != nil {
+		return id
+	}
+	id := &Identifier{Value: value, Kind: IdentifierHandle, key: comparableLocalPart(handle)}
+	g.byKey[value] = id
+	g.ids = append(g.ids, id)
+	return id
+}
+
+// email adds an email address, or returns nil when it is not one
+func (g *identityGraph) email(address string) *Identifier {
+	parsed, err := ParseIdentifier(IdentifierEmail + ":" + address)
+	if err != nil {
+		return nil
+	}
+	if id := g.byKey[parsed.Value]; id != nil {
+		return id
+	}
+	id := &parsed
+	g.byKey[id.Value] = id
+	g.ids = append(g.ids, id)
+	return id
+}
+
+// link records evidence between two identifiers, keeping the strongest
+func (g *identityGraph) link(a, b *Identifier, reason string, weight float64) {
+	if a == nil || b == nil || a == b {
+		return
+	}
+	pair := [2]string{a.Value, b.Value}
+	if pair[0] > pair[1] {
+		pair[0], pair[1] = pair[1], pair[0]
+	}
+	if existing, ok := g.links[pair]; !ok || weight > existing.Weight {
+		g.links[pair] = AliasLink{A: pair[0], B: pair[1], Reason: reason, Weight: weight}
+	}
+}
+
+// cluster adds the handle and email evidence of aliasLinks and joins the
+// identifiers into identities
+func (g *identityGraph) cluster() ([]AliasCluster, []Identifier) {
+	for _, l := range aliasLinks(g.ids) {
+		g.link(g.byKey[l.A], g.byKey[l.B], l.Reason, l.Weight)
+	}
+	links := make([]AliasLink, 0, len(g.links))
+	for _, l := range g.links {
+		links = append(links, l)
+	}
+	return clusterLinks(g.ids, links)
+}
+
+// FindPackageMaintainers lists the npm packages a developer maintains and
+// the accounts maintaining them alongside. The registry's maintainer emails,
+// the authors and contributors in each package.json and the GitHub owners of
+// their repositories are joined into identities, which show both the
+// developer's own alternate handles and emails and those of collaborators.
+func FindPackageMaintainers(ctx context.Context, target string, count int) (*PackageGraphResult, error) {
+	startTime := time.Now()
+	target = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(target), "~"))
+	if err := input.PlatformHandle("npm", target); err != nil {
+		return nil, err
+	}
+	if count < 1 || count > MaxPackageCount {
+		return nil, fmt.Errorf("package count must be between 1 and %d", MaxPackageCount)
+	}
+
+	var search npmSearchResult
+	query := url.Values{"text": {"maintainer:" + target}, "size": {fmt.Sprint(count)}}
+	if err := getProviderJSON(ctx, "https://registry.npmjs.org/-/v1/search?"+query.Encode(), nil, &search); err != nil {
+		return nil, fmt.Errorf("searching the npm registry: %v", err)
+	}
+	if len(search.Objects) == 0 {
+		return nil, fmt.Errorf("no npm packages maintained by %s", target)
+	}
+
+	result := &PackageGraphResult{
+		Target:        target,
+		ScanID:        providers.ScanIDFrom(ctx),
+		Timestamp:     startTime.Format(time.RFC3339),
+		Packages:      make([]NpmPackage, len(search.Objects)),
+		CoMaintainers: []CoMaintainer{},
+		Identities:    []AliasCluster{},
+	}
+	versions := make([]*npmVersion, len(search.Objects))
+	var mu sync.Mutex
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(ConcurrentRequests)
+	for i, object := range search.Objects {
+		pkg := object.Package
+		result.Packages[i] = NpmPackage{Name: pkg.Name, Version: pkg.Version, URL: pkg.Links.NPM}
+		g.Go(func() error {
+			var version npmVersion
+			err := getProviderJSON(gctx, "https://registry.npmjs.org/"+url.PathEscape(pkg.Name)+"/"+url.PathEscape(pkg.Version), nil, &version)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.PartialErrors = append(result.PartialErrors, ModuleError{Module: "npm." + pkg.Name, Error: err.Error()})
+				// The search result still names the maintainers
+				version.Maintainers = pkg.Maintainers
+			}
+			versions[i] = &version
+			return nil
+		})
+	}
+	g.Wait()
+
+	graph := newIdentityGraph()
+	self := graph.handle("npm", target)
+	coMaintainers := make(map[string]*CoMaintainer)
+	owners := make(map[string][]string) // GitHub owner to the packages only they and one npm account hold
+	for i, version := range versions {
+		pkg := &result.Packages[i]
+		pkg.Repository = repositoryURL(version.Repository)
+		pkg.Publisher = version.NPMUser.handle()
+
+		var maintainers []*Identifier
+		for _, maintainer := range version.Maintainers {
+			handle := maintainer.handle()
+			if handle == "" {
+				continue
+			}
+			pkg.Maintainers = append(pkg.Maintainers, handle)
+			account := graph.handle("npm", handle)
+			maintainers = append(maintainers, account)
+			graph.link(account, graph.email(maintainer.Email), "email npm lists for npm:"+handle, linkAccountEmail)
+			if handle != target {
+				co := coMaintainers[handle]
+				if co == nil {
+					co = &CoMaintainer{Handle: handle}
+					coMaintainers[handle] = co
+				}
+				if co.Email == "" {
+					co.Email = strings.ToLower(maintainer.Email)
+				}
+				co.Packages = append(co.Packages, pkg.Name)
+			}
+		}
+		if pkg.Publisher != "" {
+			graph.link(graph.handle("npm", pkg.Publisher), graph.email(version.NPMUser.Email), "email npm lists for npm:"+pkg.Publisher, linkAccountEmail)
+		}
+
+		// Authors and repositories say little about which maintainer they
+		// are unless there is only one
+		var sole *Identifier
+		if len(maintainers) == 1 {
+			sole = maintainers[0]
+		}
+		people := append([]json.RawMessage{version.Author}, version.Contributors...)
+		for n, raw := range people {
+			person, ok := parsePackagePerson(raw)
+			if !ok {
+				continue
+			}
+			if n == 0 {
+				pkg.Author = person.String()
+			} else {
+				pkg.Contributors = append(pkg.Contributors, person.String())
+			}
+			email := graph.email(person.Email)
+			if m := githubProfileRegex.FindStringSubmatch(person.URL); m != nil {
+				graph.link(email, graph.handle("github", m[1]), "URL given with "+person.Email+" in "+pkg.Name, linkAuthorURL)
+			}
+			if n == 0 && sole != nil {
+				graph.link(email, sole, fmt.Sprintf("author of %s, which only %s maintains", pkg.Name, sole.Value), linkSoleMaintainer)
+			}
+		}
+		if m := githubRepoOwnerRegex.FindStringSubmatch(pkg.Repository); m != nil {
+			owner := strings.ToLower(m[1])
+			if sole != nil {
+				owners[owner] = append(owners[owner], pkg.Name+"\x00"+sole.Value)
+			} else if _, ok := owners[owner]; !ok {
+				owners[owner] = nil
+			}
+		}
+	}
+
+	result.PartialErrors = append(result.PartialErrors, linkGitHubOwners(ctx, graph, owners)...)
+
+	result.Identities, result.Unlinked = graph.cluster()
+	for _, identity := range result.Identities {
+		for _, member := range identity.Members {
+			if member.Value == self.Value {
+				result.Identity = identity.ID
+			}
+			if handle, ok := strings.CutPrefix(member.Value, "npm:"); ok && coMaintainers[handle] != nil {
+				coMaintainers[handle].Identity = identity.ID
+			}
+		}
+	}
+	for _, co := range coMaintainers {
+		result.CoMaintainers = append(result.CoMaintainers, *co)
+	}
+	sort.Slice(result.CoMaintainers, func(i, j int) bool {
+		if len(result.CoMaintainers[i].Packages) != len(result.CoMaintainers[j].Packages) {
+			return len(result.CoMaintainers[i].Packages) > len(result.CoMaintainers[j].Packages)
+		}
+		return result.CoMaintainers[i].Handle < result.CoMaintainers[j].Handle
+	})
+	sort.SliceStable(result.PartialErrors, func(i, j int) bool { return result.PartialErrors[i].Module < result.PartialErrors[j].Module })
+
+	result.ExecutionTime = time.Since(startTime).String()
+	return result, ctx.Err()
+}
+
+// linkGitHubOwners looks up the GitHub owners of the packages' repositories.
+// A user, unlike an organization, is linked to the account that alone
+// maintains a package kept in their repository, and to their public email.
+func linkGitHubOwners(ctx context.Context, graph *identityGraph, owners map[string][]string) []ModuleError {
+	names := make([]string, 0, len(owners))
+	for owner := range owners {
+		names = append(names, owner)
+	}
+	// Owners of solely maintained packages say the most
+	sort.Slice(names, func(i, j int) bool {
+		if len(owners[names[i]]) != len(owners[names[j]]) {
+			return len(owners[names[i]]) > len(owners[names[j]])
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > maxGitHubOwners {
+		names = names[:maxGitHubOwners]
+	}
+
+	var errs []ModuleError
+	for _, owner := range names {
+		var user struct {
+			Type  string `json:"type"`
+			Email string `json:"email"`
+		}
+		err := getProviderJSON(ctx, "https://api.github.com/users/"+url.PathEscape(owner), githubHeaders(), &user)
+		switch {
+		case providers.IsStatus(err, http.StatusNotFound):
+			continue
+		case err != nil:
+			errs = append(errs, ModuleError{Module: "github." + owner, Error: err.Error()})
+			continue
+		case user.Type != "User":
+			continue
+		}
+
+		account := graph.handle("github", owner)
+		graph.link(account, graph.email(user.Email), "public email of github:"+owner, linkAccountEmail)
+		var linked []string
+		for _, entry := range owners[owner] {
+			pkg, maintainer, _ := strings.Cut(entry, "\x00")
+			if slices.Contains(linked, maintainer) {
+				continue
+			}
+			linked = append(linked, maintainer)
+			graph.link(account, graph.byKey[maintainer], fmt.Sprintf("owns the repository of %s, which only %s maintains", pkg, maintainer), linkSoleMaintainer)
+		}
+	}
+	return errs
+}
+
+// DisplayResults prints the packages, the co-maintainers and the identities
+// their handles and emails form
+func (r *PackageGraphResult) DisplayResults() {
+	color.Cyan("\n=== NPM PACKAGES: %s ===", r.Target)
+	color.Yellow("Packages maintained: %d", len(r.Packages))
+	for _, pkg := range r.Packages {
+		color.Green("  %s@%s", pkg.Name, pkg.Version)
+		if len(pkg.Maintainers) > 1 {
+			fmt.Printf("      Maintainers: %s\n", strings.Join(pkg.Maintainers, ", "))
+		}
+		if pkg.Author != "" {
+			fmt.Printf("      Author: %s\n", pkg.Author)
+		}
+		if pkg.Repository != "" {
+			fmt.Printf("      Repository: %s\n", pkg.Repository)
+		}
+	}
+
+	if len(r.CoMaintainers) == 0 {
+		color.Yellow("\nNo co-maintainers: %s maintains these packages alone", r.Target)
+	} else {
+		color.Cyan("\nCo-maintainers: %d", len(r.CoMaintainers))
+		for _, co := range r.CoMaintainers {
+			line := "  " + co.Handle
+			if co.Email != "" {
+				line += " <" + co.Email + ">"
+			}
+			color.Green("%s", line)
+			fmt.Printf("      %d shared: %s\n", len(co.Packages), strings.Join(co.Packages, ", "))
+		}
+	}
+
+	for _, identity := range r.Identities {
+		if identity.ID == r.Identity {
+			color.Cyan("\n[Identity %d] %s, confidence %.2f", identity.ID, r.Target, identity.Confidence)
+		} else {
+			color.Cyan("\n[Identity %d] collaborator, confidence %.2f", identity.ID, identity.Confidence)
+		}
+		for _, member := range identity.Members {
+			color.Green("• %s", member.Value)
+		}
+		for _, l := range identity.Evidence {
+			color.White("  %s ↔ %s: %s (%.2f)", l.A, l.B, l.Reason, l.Weight)
+		}
+	}
+	if r.Identity == 0 {
+		color.Yellow("\nNothing links other handles or emails to %s", r.Target)
+	}
+	fmt.Printf("\nExecution time: %s\n", r.ExecutionTime)
+}
+
+// DisplayPartialErrors prints the packages and owners that could not be read
+func (r *PackageGraphResult) DisplayPartialErrors() {
+	displayModuleErrors(r.PartialErrors)
+}
@@ -0,0 +1,78 @@
+package osint
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/awion/MercuriesOST/public/providers"
+)
+
+// replayCassette answers every provider request from a recorded cassette for
+// the rest of the test
+func replayCassette(t *testing.T, path string) {
+	t.Helper()
+	cassette, err := providers.LoadCassette(path)
+	if err != nil {
+		t.Fatalf("load cassette: %v", err)
+	}
+	restore := providers.Use(cassette)
+	limits := providers.RateLimits
+	providers.RateLimits = false
+	t.Cleanup(func() {
+		restore()
+		providers.RateLimits = limits
+	})
+}
+
+func TestFindPackageMaintainersFromCassette(t *testing.T) {
+	replayCassette(t, "testdata/cassettes/npm-packages.json")
+
+	result, err := FindPackageMaintainers(context.Background(), "~JaneDev", 2)
+	if err != nil {
+		t.Fatalf("FindPackageMaintainers: %v", err)
+	}
+	if len(result.PartialErrors) > 0 {
+		t.Fatalf("partial errors: %+v", result.PartialErrors)
+	}
+
+	if len(result.Packages) != 2 {
+		t.Fatalf("got %d packages, want 2", len(result.Packages))
+	}
+	leftPad, tiny := result.Packages[0], result.Packages[1]
+	if leftPad.Author != "Jane Dev <jane@example.com> (https://github.com/jdev)" || leftPad.Publisher != "janedev" {
+		t.Errorf("left-pad-x author %q published by %q", leftPad.Author, leftPad.Publisher)
+	}
+	if !reflect.DeepEqual(tiny.Maintainers, []string{"janedev", "bob"}) || tiny.Publisher != "bob" {
+		t.Errorf("tiny-util maintained by %v and published by %q", tiny.Maintainers, tiny.Publisher)
+	}
+
+	want := []CoMaintainer{{Handle: "bob", Email: "bob@example.net", Packages: []string{"tiny-util"}}}
+	got := result.CoMaintainers
+	for i := range got {
+		got[i].Identity = 0
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("co-maintainers = %+v, want %+v", got, want)
+	}
+
+	// The GitHub user owning the solely maintained package, and their public
+	// email, join the developer's identity; the organization does not
+	members := map[string]bool{}
+	for _, identity := range result.Identities {
+		if identity.ID != result.Identity {
+			continue
+		}
+		for _, member := range identity.Members {
+			members[member.Value] = true
+		}
+	}
+	for _, value := range []string{"npm:janedev", "github:jdev", "jane@example.com", "jane.dev@example.org"} {
+		if !members[value] {
+			t.Errorf("identity of janedev lacks %s: %v", value, members)
+		}
+	}
+	if members["github:acme"] {
+		t.Errorf("organization github:acme joined the identity: %v", members)
+	}
+}
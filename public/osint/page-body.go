@@ -0,0 +1,148 @@
+package osint
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/transform"
+)
+
+// MaxBodySize caps how many decoded bytes of a fetched page are read
+var MaxBodySize = int64(5 << 20)
+
+// truncationMarker ends page bodies cut at MaxBodySize, so extracted text shows the gap
+const truncationMarker = "\n<!-- truncated by MercuriesOST -->"
+
+// pageContentTypes are the media types worth parsing as a page
+var pageContentTypes = []string{
+	"text/html",
+	"text/plain",
+	"text/xml",
+	"application/xhtml+xml",
+	"application/xml",
+	"application/json",
+	"application/ld+json",
+}
+
+// pageBody is a decoded response body converted to UTF-8, possibly cut short
+type pageBody struct {
+	Data      []byte
+	Charset   string
+	Truncated bool
+}
+
+// String returns the body as text
+func (b pageBody) String() string {
+	return string(b.Data)
+}
+
+// readPageBody decodes a response body and reads at most MaxBodySize bytes of it.
+// Media that is not a page, like video or event streams, is refused unread.
+func readPageBody(resp *http.Response) (pageBody, error) {
+	if err := checkPageContentType(resp.Header.Get("Content-Type")); err != nil {
+		return pageBody{}, err
+	}
+
+	body, err := decodeContent(resp.Body, resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		return pageBody{}, err
+	}
+
+	// Read one byte past the limit to tell a full page from a cut one
+	data, err := io.ReadAll(io.LimitReader(body, MaxBodySize+1))
+	if err != nil {
+		return pageBody{}, err
+	}
+	page := pageBody{Data: data}
+	if int64(len(data)) > MaxBodySize {
+		page.Data, page.Truncated = data[:MaxBodySize], true
+
+		// A UTF-8 character cut in half would make the page look like windows-1252
+		if trimmed := trimPartialRune(page.Data); utf8.Valid(trimmed) {
+			page.Data = trimmed
+		}
+	}
+
+	page.Data, page.Charset = toUTF8(page.Data, resp.Header.Get("Content-Type"))
+	if page.Truncated {
+		page.Data = append(page.Data, truncationMarker...)
+	}
+	return page, nil
+}
+
+// toUTF8 converts a page to UTF-8, taking its charset from a byte order mark,
+// the Content-Type header or a <meta> tag. Pages declaring nothing are read as
+// UTF-8 when valid, otherwise as windows-1252 the way browsers do.
+func toUTF8(data []byte, contentType string) ([]byte, string) {
+	encoding, name, _ := charset.DetermineEncoding(data, contentType)
+	if name == "utf-8" {
+		return data, name
+	}
+	decoded, _, err := transform.Bytes(encoding.NewDecoder(), data)
+	if err != nil {
+		return data, "utf-8"
+	}
+	return decoded, name
+}
+
+// trimPartialRune drops a multi-byte character cut in half by truncation
+func trimPartialRune(data []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
+
+// checkPageContentType rejects media types that are not text pages; a missing type is allowed
+func checkPageContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("unreadable content type %q", contentType)
+	}
+	for _, allowed := range pageContentTypes {
+		if mediaType == allowed {
+			return nil
+		}
+	}
+	if strings.HasSuffix(mediaType, "+xml") || strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+	return fmt.Errorf("not a page: content type %s", mediaType)
+}
+
+// decodeContent unwraps a body the transport left encoded. Brotli has no
+// decoder in the standard library, so it is reported rather than parsed as garbage.
+func decodeContent(body io.Reader, encoding string) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(body)
+	case "deflate":
+		// Servers disagree on whether deflate means zlib-wrapped or raw
+		buffered := bufio.NewReader(body)
+		if header, err := buffered.Peek(2); err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(buffered)
+		}
+		return flate.NewReader(buffered), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+}
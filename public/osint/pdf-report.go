@@ -0,0 +1,533 @@
+package osint
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	pdfLabelWidth = 95.0 // Width of the labels of a profile's fields
+	pdfAvatarSize = 36.0
+	pdfCellLines  = 10 // Lines shown of one table cell, the rest cut
+)
+
+// pdfEntry is a line of the table of contents
+type pdfEntry struct {
+	title string
+	level int // 0 for a module's section, 1 for a result in it
+	dest  pdfDest
+}
+
+// pdfReport lays the results of an HTMLReport out as a PDF
+type pdfReport struct {
+	*pdfDocument
+	report  *HTMLReport
+	entries []pdfEntry
+	avatars map[string]int // Avatar URL to its image, -1 when it could not be decoded
+}
+
+// RenderPDF writes the report as a PDF: a cover page, a table of contents
+// linking to the results and a section per module, with the avatars that
+// were embedded drawn on the profiles
+func (r *HTMLReport) RenderPDF(w io.Writer) error {
+	p := &pdfReport{pdfDocument: newPDFDocument(r.Title), report: r, avatars: make(map[string]int)}
+	p.footer = r.Title
+
+	if len(r.Social) > 0 {
+		p.section("Social media")
+		for _, social := range r.Social {
+			p.social(social)
+		}
+	}
+	if len(r.Emails) > 0 {
+		p.section("Email")
+		for _, email := range r.Emails {
+			p.email(email)
+		}
+	}
+	if len(r.Phones) > 0 {
+		p.section("Phone")
+		for _, phone := range r.Phones {
+			p.phone(phone)
+		}
+	}
+	if len(r.GoogleIDs) > 0 {
+		p.section("Google ID")
+		for _, gid := range r.GoogleIDs {
+			p.googleID(gid)
+		}
+	}
+	p.section("Sources")
+	for _, source := range r.Sources {
+		p.bullet(source)
+	}
+	if r.Suppressed > 0 {
+		p.space(6)
+		p.paragraph(pdfMargin, fmt.Sprintf("%d findings on the suppression lists are left out; the result files still hold them.", r.Suppressed), fontRegular, 9, pdfMuted)
+	}
+
+	// The cover and contents go before the sections, whose page numbers the
+	// contents list, so they are laid out last. A first pass counts the
+	// pages the contents take.
+	scratch := &pdfReport{pdfDocument: newPDFDocument(r.Title), report: r}
+	scratch.front(p.entries, 0, nil)
+	front := &pdfReport{pdfDocument: newPDFDocument(r.Title), report: r}
+	front.front(p.entries, len(scratch.pages), p.pages)
+
+	contents := &pdfOutline{title: "Contents", dest: pdfDest{page: front.pages[1], y: pdfPageHeight - pdfMargin}}
+	p.outlines = append([]*pdfOutline{contents}, p.outlines...)
+	p.pages = append(front.pages, p.pages...)
+	return p.write(w)
+}
+
+// front lays out the cover page and the table of contents. Section pages
+// are numbered from after the offset front pages.
+func (p *pdfReport) front(entries []pdfEntry, offset int, body []*pdfPage) {
+	r := p.report
+	p.addPage()
+	p.fillRect(0, pdfPageHeight-250, pdfPageWidth, 250, pdfInk)
+	p.y = pdfPageHeight - 110
+	p.paragraph(pdfMargin, r.Title, fontBold, 28, pdfWhite)
+	p.space(6)
+	p.paragraph(pdfMargin, "Open source intelligence report", fontRegular, 13, pdfShade)
+
+	p.y = pdfPageHeight - 290
+	if r.Investigator != "" {
+		p.field("Investigator", r.Investigator)
+	}
+	p.field("Generated", r.Generated)
+	profiles := 0
+	for _, social := range r.Social {
+		profiles += len(social.Profiles)
+	}
+	if len(r.Social) > 0 {
+		p.field("Social media", fmt.Sprintf("%d searches, %d profiles", len(r.Social), profiles))
+	}
+	if len(r.Emails) > 0 {
+		p.field("Emails", strconv.Itoa(len(r.Emails)))
+	}
+	if len(r.Phones) > 0 {
+		p.field("Phone numbers", strconv.Itoa(len(r.Phones)))
+	}
+	if len(r.GoogleIDs) > 0 {
+		p.field("Google IDs", strconv.Itoa(len(r.GoogleIDs)))
+	}
+	p.field("Sources", fmt.Sprintf("%d result files", len(r.Sources)))
+	p.y = pdfMargin + pdfFooter + 20
+	p.paragraph(pdfMargin, "Compiled from public sources. Findings marked lead are unconfirmed.", fontRegular, 9, pdfMuted)
+
+	p.addPage()
+	p.heading("Contents", 20)
+	numbers := make(map[*pdfPage]int)
+	for i, page := range body {
+		numbers[page] = offset + i + 1
+	}
+	for _, entry := range entries {
+		size, font, indent := 11.0, fontBold, 0.0
+		if entry.level > 0 {
+			size, font, indent = 10, fontRegular, 16
+		}
+		lineHeight := size * 1.6
+		p.ensure(lineHeight)
+		p.y -= lineHeight
+		baseline := p.y + size*0.4
+
+		number := pdfText(strconv.Itoa(numbers[entry.dest.page]))
+		numberX := pdfPageWidth - pdfMargin - pdfTextWidth(number, font, size)
+		title := pdfFit(pdfText(entry.title), font, size, numberX-pdfMargin-indent-24)
+		x := pdfMargin + indent
+		p.page().text(x, baseline, title, font, size, pdfInk)
+		dotsX := x + pdfTextWidth(title, font, size) + 6
+		if dots := int((numberX - 6 - dotsX) / pdfTextWidth([]byte("."), fontRegular, size)); dots > 0 {
+			p.page().text(dotsX, baseline, []byte(strings.Repeat(".", dots)), fontRegular, size, pdfMuted)
+		}
+		p.page().text(numberX, baseline, number, font, size, pdfInk)
+
+		dest := entry.dest
+		p.page().annots = append(p.page().annots, pdfAnnotation{rect: [4]float64{x, p.y, pdfPageWidth - pdfMargin, p.y + lineHeight}, dest: &dest})
+	}
+}
+
+// section starts a module's section on a new page
+func (p *pdfReport) section(title string) {
+	p.addPage()
+	dest := p.here()
+	p.heading(title, 20)
+	p.entries = append(p.entries, pdfEntry{title: title, dest: dest})
+	p.outlines = append(p.outlines, &pdfOutline{title: title, dest: dest})
+}
+
+// result starts the part of a section on one search, email or number
+func (p *pdfReport) result(title string) {
+	p.ensure(80)
+	p.space(10)
+	dest := p.here()
+	p.paragraph(pdfMargin, title, fontBold, 14, pdfInk)
+	p.space(4)
+	p.entries = append(p.entries, pdfEntry{title: title, level: 1, dest: dest})
+	section := p.outlines[len(p.outlines)-1]
+	section.children = append(section.children, &pdfOutline{title: title, dest: dest})
+}
+
+// heading lays out a title underlined with a rule
+func (p *pdfReport) heading(title string, size float64) {
+	p.paragraph(pdfMargin, title, fontBold, size, pdfInk)
+	p.space(6)
+	p.rule(p.y, 1.5, pdfInk)
+	p.space(10)
+}
+
+// subheading lays out a title inside a result, kept with the lines after it
+func (p *pdfReport) subheading(title string) {
+	p.ensure(50)
+	p.space(8)
+	p.paragraph(pdfMargin, title, fontBold, 11, pdfInk)
+	p.space(2)
+}
+
+// field lays out a labelled value, the value wrapping beside its label
+func (p *pdfReport) field(label, value string) {
+	p.fieldIn(label, value, fontRegular)
+}
+
+// fieldIn lays out a labelled value in a font, such as fixed-width for hashes
+func (p *pdfReport) fieldIn(label, value string, font pdfFont) {
+	const size = 9.5
+	p.ensure(size * 1.35)
+	page, top := p.page(), p.y
+	p.paragraph(pdfMargin+pdfLabelWidth, value, font, size, pdfInk)
+	page.text(pdfMargin, top-size*1.35+size*0.3, pdfText(label), fontRegular, size, pdfMuted)
+}
+
+// bullet lays out one item of a list
+func (p *pdfReport) bullet(item string) {
+	const size = 9.5
+	p.ensure(size * 1.35)
+	page, top := p.page(), p.y
+	p.paragraph(pdfMargin+12, item, fontRegular, size, pdfInk)
+	page.text(pdfMargin+2, top-size*1.35+size*0.3, pdfText("•"), fontRegular, size, pdfInk)
+}
+
+// card lays out a profile's avatar, or its initial when it has none, beside
+// its name and a line under it
+func (p *pdfReport) card(avatar, initial, name, subtitle string) {
+	p.ensure(pdfAvatarSize + 30)
+	p.space(6)
+	top := p.y
+	x, y := pdfMargin, top-pdfAvatarSize
+	if index := p.avatar(avatar); index >= 0 {
+		p.roundImage(index, x, y, pdfAvatarSize)
+	} else {
+		p.fillCircle(x+pdfAvatarSize/2, y+pdfAvatarSize/2, pdfAvatarSize/2, pdfRule)
+		letter := pdfText(initial)
+		p.page().text(x+(pdfAvatarSize-pdfTextWidth(letter, fontBold, 16))/2, y+pdfAvatarSize/2-6, letter, fontBold, 16, pdfInk)
+	}
+	textX := x + pdfAvatarSize + 10
+	p.page().text(textX, top-15, pdfFit(pdfText(name), fontBold, 12, pdfPageWidth-pdfMargin-textX), fontBold, 12, pdfInk)
+	p.page().text(textX, top-29, pdfFit(pdfText(subtitle), fontRegular, 9, pdfPageWidth-pdfMargin-textX), fontRegular, 9, pdfMuted)
+	p.y = y - 6
+}
+
+// avatar returns the image of an avatar the report embedded, or -1
+func (p *pdfReport) avatar(link string) int {
+	uri, ok := p.report.avatars[link]
+	if !ok {
+		return -1
+	}
+	if index, ok := p.avatars[link]; ok {
+		return index
+	}
+	index, err := p.addDataURI(string(uri))
+	if err != nil {
+		index = -1
+	}
+	p.avatars[link] = index
+	return index
+}
+
+// divider separates the profiles of a result
+func (p *pdfReport) divider() {
+	p.space(6)
+	p.rule(p.y, 0.5, pdfRule)
+}
+
+// table lays out rows under a shaded header, repeating the header on each
+// page the rows run onto. Columns take the given shares of the width.
+func (p *pdfReport) table(headers []string, shares []float64, rows [][]string) {
+	const size, pad = 9.0, 4.0
+	lineHeight := size * 1.35
+	width := pdfPageWidth - 2*pdfMargin
+	xs := make([]float64, len(shares)+1)
+	xs[0] = pdfMargin
+	for i, share := range shares {
+		xs[i+1] = xs[i] + share*width
+	}
+
+	layout := func(cells []string, font pdfFont) ([][][]byte, float64) {
+		lines := make([][][]byte, len(cells))
+		most := 1
+		for i, cell := range cells {
+			lines[i] = pdfWrap(pdfText(cell), font, size, xs[i+1]-xs[i]-2*pad)
+			if len(lines[i]) > pdfCellLines {
+				lines[i] = append(lines[i][:pdfCellLines-1], pdfFit(append(append([]byte(nil), lines[i][pdfCellLines-1]...), " ..."...), font, size, xs[i+1]-xs[i]-2*pad))
+			}
+			most = max(most, len(lines[i]))
+		}
+		return lines, float64(most)*lineHeight + 2*pad
+	}
+	draw := func(lines [][][]byte, height float64, font pdfFont, shade bool) {
+		if shade {
+			p.fillRect(pdfMargin, p.y-height, width, height, pdfShade)
+		}
+		for i, cell := range lines {
+			for j, line := range cell {
+				p.page().text(xs[i]+pad, p.y-pad-float64(j+1)*lineHeight+size*0.3, line, font, size, pdfInk)
+			}
+		}
+		p.y -= height
+		p.rule(p.y, 0.5, pdfRule)
+	}
+
+	header, headerHeight := layout(headers, fontBold)
+	p.ensure(headerHeight + lineHeight + 2*pad)
+	p.space(4)
+	draw(header, headerHeight, fontBold, true)
+	for _, row := range rows {
+		lines, height := layout(row, fontRegular)
+		if p.y-height < pdfMargin+pdfFooter {
+			p.addPage()
+			draw(header, headerHeight, fontBold, true)
+		}
+		draw(lines, height, fontRegular, false)
+	}
+	p.space(6)
+}
+
+// social lays out one social media search
+func (p *pdfReport) social(results *SocialMediaResults) {
+	p.result("Search: " + results.Query)
+	summary := fmt.Sprintf("%d profiles, searched %s", len(results.Profiles), results.Timestamp)
+	if results.Interrupted {
+		summary += ", stopped before every check ran"
+	}
+	p.paragraph(pdfMargin, summary, fontRegular, 9, pdfMuted)
+
+	for _, profile := range results.Profiles {
+		name := profile.FullName
+		if name == "" {
+			name = profile.Username
+		}
+		p.card(profile.Avatar, reportInitial(profile.FullName, profile.Username, profile.Platform), name, profile.Platform+" · "+profile.Username)
+		p.link(pdfMargin, profile.URL, profile.URL, 9.5)
+		badges := "confidence " + reportPercent(profile.Confidence)
+		if profile.Tier != "" {
+			badges = profile.Tier + " · " + badges
+		}
+		p.paragraph(pdfMargin, badges, fontBold, 9, pdfMuted)
+		if bio := strings.TrimSpace(profile.Bio); bio != "" {
+			p.space(2)
+			p.paragraph(pdfMargin, bio, fontRegular, 9.5, pdfInk)
+		}
+		p.space(3)
+		if profile.Location != "" {
+			p.field("Location", profile.Location)
+		}
+		if profile.FollowerCount > 0 {
+			p.field("Followers", strconv.Itoa(profile.FollowerCount))
+		}
+		if profile.JoinDate != "" {
+			p.field("Joined", profile.JoinDate)
+		}
+		if profile.CanonicalID != "" {
+			id := profile.CanonicalID
+			if profile.IDCreated != "" {
+				id += " (created " + profile.IDCreated + ")"
+			}
+			p.field("Account ID", id)
+		}
+		if profile.VariantOf != "" {
+			p.field("Variant of", profile.VariantOf)
+		}
+		p.fieldIn("Finding", profile.FindingHash(), fontMono)
+		for _, insight := range profile.Insights {
+			p.bullet(insight)
+		}
+		p.divider()
+	}
+
+	if len(results.Leads) > 0 {
+		p.subheading("Leads")
+		rows := make([][]string, len(results.Leads))
+		for i, lead := range results.Leads {
+			rows[i] = []string{lead.Platform, lead.URL, reportPercent(lead.Confidence)}
+		}
+		p.table([]string{"Platform", "Profile", "Confidence"}, []float64{0.2, 0.62, 0.18}, rows)
+	}
+	if len(results.Locations) > 0 {
+		p.subheading("Locations")
+		for _, cluster := range results.Locations {
+			p.bullet(fmt.Sprintf("%s: %d profiles", cluster.Place, len(cluster.Profiles)))
+		}
+	}
+}
+
+// email lays out one email's analysis
+func (p *pdfReport) email(result *EmailAnalysisResult) {
+	p.result(result.Email)
+	format := "invalid format"
+	if result.ValidFormat {
+		format = "valid format"
+	}
+	p.paragraph(pdfMargin, fmt.Sprintf("%s · %d breaches · risk %d/100", format, result.SecurityInfo.BreachCount, result.SecurityInfo.RiskScore), fontBold, 9, pdfMuted)
+	p.space(3)
+	domain := result.Domain
+	if result.DomainInfo.Registrar != "" {
+		domain += " (" + result.DomainInfo.Registrar + ")"
+	}
+	p.field("Domain", domain)
+	if len(result.PatternAnalysis.Patterns) > 0 {
+		p.field("Patterns", strings.Join(result.PatternAnalysis.Patterns, ", "))
+	}
+	if len(result.SecurityInfo.ExposedDataTypes) > 0 {
+		p.field("Exposed data", strings.Join(result.SecurityInfo.ExposedDataTypes, ", "))
+	}
+	if result.SecurityInfo.LastBreachDate != "" {
+		p.field("Last breach", result.SecurityInfo.LastBreachDate)
+	}
+	if len(result.CommonServices) > 0 {
+		p.field("Services", strings.Join(result.CommonServices, ", "))
+	}
+
+	if breaches := result.SecurityInfo.BreachDetails; len(breaches) > 0 {
+		p.subheading("Breaches")
+		rows := make([][]string, len(breaches))
+		for i, breach := range breaches {
+			rows[i] = []string{breach.BreachName, breach.BreachDate, strings.Join(breach.CompromisedData, ", ")}
+		}
+		p.table([]string{"Breach", "Date", "Data"}, []float64{0.3, 0.18, 0.52}, rows)
+	}
+	if len(result.SocialProfiles) > 0 {
+		p.subheading("Linked accounts")
+		for _, profile := range result.SocialProfiles {
+			name := profile.DisplayName
+			if name == "" {
+				name = profile.Username
+			}
+			p.card(profile.ProfilePic, reportInitial(profile.DisplayName, profile.Username, profile.Platform), name, profile.Platform)
+			p.link(pdfMargin, profile.URL, profile.URL, 9.5)
+			var badges []string
+			if profile.Tier != "" {
+				badges = append(badges, profile.Tier)
+			}
+			if profile.Verified {
+				badges = append(badges, "verified")
+			}
+			if len(badges) > 0 {
+				p.paragraph(pdfMargin, strings.Join(badges, " · "), fontBold, 9, pdfMuted)
+			}
+			if bio := strings.TrimSpace(profile.Bio); bio != "" {
+				p.paragraph(pdfMargin, bio, fontRegular, 9.5, pdfInk)
+			}
+			p.fieldIn("Finding", profile.FindingHash(), fontMono)
+			p.divider()
+		}
+	}
+}
+
+// phone lays out one number's analysis
+func (p *pdfReport) phone(result *PhoneNumberResult) {
+	p.result(result.E164Format)
+	if result.RiskAssessment.Level != "" {
+		p.paragraph(pdfMargin, result.RiskAssessment.Level+" risk", fontBold, 9, pdfMuted)
+		p.space(3)
+	}
+	country := result.CountryName
+	if result.Region != "" {
+		country += ", " + result.Region
+	}
+	p.field("Country", country)
+	if result.Type != "" {
+		p.field("Type", result.Type)
+	}
+	if result.Carrier.Name != "" {
+		p.field("Carrier", result.Carrier.Name)
+	}
+	if len(result.TimeZones) > 0 {
+		p.field("Time zones", strings.Join(result.TimeZones, ", "))
+	}
+	if owners := result.ReverseLookup.PossibleOwners; len(owners) > 0 {
+		p.field("Possible owners", strings.Join(owners, ", "))
+	}
+	for _, indicator := range result.RiskAssessment.Indicators {
+		p.bullet(indicator)
+	}
+
+	if len(result.MessagingApps) > 0 {
+		p.subheading("Messaging apps")
+		rows := make([][]string, len(result.MessagingApps))
+		for i, app := range result.MessagingApps {
+			rows[i] = []string{app.Name, app.Status, app.LastSeen}
+		}
+		p.table([]string{"Messaging app", "Status", "Last seen"}, []float64{0.35, 0.35, 0.3}, rows)
+	}
+	if len(result.OnlinePresence) > 0 {
+		p.subheading("Online presence")
+		for _, presence := range result.OnlinePresence {
+			p.paragraph(pdfMargin, presence.Platform, fontRegular, 9.5, pdfInk)
+			p.link(pdfMargin+12, presence.URL, presence.URL, 9.5)
+		}
+	}
+}
+
+// googleID lays out one Google account's contributions
+func (p *pdfReport) googleID(result *GoogleIDResult) {
+	name := result.DisplayName
+	if name == "" {
+		name = result.GoogleID
+	}
+	p.result(name)
+	p.card(result.AvatarURL, reportInitial(result.DisplayName, "G"), name, "Google ID "+result.GoogleID)
+	contributions := result.Contributions
+	p.field("Contributions", fmt.Sprintf("%d reviews, %d photos, %d ratings", contributions.TotalReviews, contributions.TotalPhotos, contributions.TotalRatings))
+	if contributions.ContributorRank != "" {
+		p.field("Rank", contributions.ContributorRank)
+	}
+	if result.LastSeen != "" {
+		p.field("Last seen", result.LastSeen)
+	}
+
+	if len(result.Reviews) > 0 {
+		p.subheading("Reviews")
+		rows := make([][]string, len(result.Reviews))
+		for i, review := range result.Reviews {
+			rows[i] = []string{review.Location, fmt.Sprintf("%d/5", review.Rating), review.ReviewDate, review.ReviewText}
+		}
+		p.table([]string{"Place", "Rating", "Date", "Review"}, []float64{0.28, 0.1, 0.16, 0.46}, rows)
+	}
+	if len(result.Photos) > 0 {
+		p.subheading("Photos")
+		for _, photo := range result.Photos {
+			label := photo.Location
+			if label == "" {
+				label = photo.URL
+			}
+			if photo.UploadDate != "" {
+				label += " (" + photo.UploadDate + ")"
+			}
+			p.link(pdfMargin, label, photo.URL, 9.5)
+		}
+	}
+}
+
+// pdfFit cuts encoded text to fit a width, marking the cut with dots
+func pdfFit(s []byte, font pdfFont, size, width float64) []byte {
+	if pdfTextWidth(s, font, size) <= width {
+		return s
+	}
+	for len(s) > 0 && pdfTextWidth(append(append([]byte(nil), s...), "..."...), font, size) > width {
+		s = s[:len(s)-1]
+	}
+	return append(append([]byte(nil), s...), "..."...)
+}
@@ -0,0 +1,498 @@
+package osint
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif" // Avatar formats the report can embed
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// A4 pages, in points
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+	pdfMargin     = 50.0
+	pdfFooter     = 30.0 // Space kept for the page footer
+	pdfImageSize  = 96   // Largest side of an embedded avatar, in pixels
+)
+
+// pdfFont is one of the standard fonts every PDF viewer has, so none are
+// embedded
+type pdfFont int
+
+const (
+	fontRegular pdfFont = iota
+	fontBold
+	fontMono
+)
+
+var pdfFontNames = [...]string{"Helvetica", "Helvetica-Bold", "Courier"}
+
+// Widths of the printable ASCII characters, in thousandths of the font
+// size, from the fonts' metrics. Other characters are taken as 556.
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// pdfColor is an RGB color, each component from 0 to 1
+type pdfColor [3]float64
+
+var (
+	pdfInk   = pdfColor{0.114, 0.137, 0.188} // #1d2330
+	pdfMuted = pdfColor{0.4, 0.439, 0.522}   // #667085
+	pdfLink  = pdfColor{0.196, 0.341, 0.659} // #3257a8
+	pdfRule  = pdfColor{0.867, 0.882, 0.91}  // #dde1e8
+	pdfShade = pdfColor{0.925, 0.937, 0.957} // #eceff4
+	pdfWhite = pdfColor{1, 1, 1}
+)
+
+// pdfDest is a place in the document links and bookmarks point to
+type pdfDest struct {
+	page *pdfPage
+	y    float64
+}
+
+// pdfAnnotation is a clickable area, opening a URL or a place in the document
+type pdfAnnotation struct {
+	rect [4]float64
+	uri  string
+	dest *pdfDest
+}
+
+// pdfPage is one page's drawing operations
+type pdfPage struct {
+	content bytes.Buffer
+	annots  []pdfAnnotation
+	images  []int // Images drawn on the page
+}
+
+// pdfImage is an RGB image, compressed
+type pdfImage struct {
+	width, height int
+	data          []byte
+}
+
+// pdfOutline is a bookmark, with the bookmarks nested under it
+type pdfOutline struct {
+	title    string
+	dest     pdfDest
+	children []*pdfOutline
+}
+
+// pdfDocument lays text, rules and images out top to bottom over as many
+// pages as they take, and writes them as a PDF
+type pdfDocument struct {
+	title    string
+	footer   string // Printed at the foot of every page but the first
+	pages    []*pdfPage
+	images   []pdfImage
+	outlines []*pdfOutline
+	y        float64 // Where the next line goes, measured up from the page's foot as PDF does
+}
+
+// newPDFDocument starts an empty document
+func newPDFDocument(title string) *pdfDocument {
+	return &pdfDocument{title: title}
+}
+
+// page returns the page being laid out
+func (d *pdfDocument) page() *pdfPage {
+	return d.pages[len(d.pages)-1]
+}
+
+// addPage starts a new page, laying out from its top margin
+func (d *pdfDocument) addPage() {
+	d.pages = append(d.pages, &pdfPage{})
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// ensure starts a new page unless height points are left on this one
+func (d *pdfDocument) ensure(height float64) {
+	if len(d.pages) == 0 || d.y-height < pdfMargin+pdfFooter {
+		d.addPage()
+	}
+}
+
+// here is the place the next line goes
+func (d *pdfDocument) here() pdfDest {
+	return pdfDest{page: d.page(), y: d.y}
+}
+
+// space moves down, without carrying over to another page
+func (d *pdfDocument) space(height float64) {
+	d.y -= height
+}
+
+// text draws one line whose baseline is at y
+func (p *pdfPage) text(x, y float64, s []byte, font pdfFont, size float64, c pdfColor) {
+	fmt.Fprintf(&p.content, "BT /F%d %.2f Tf %.3f %.3f %.3f rg %.2f %.2f Td (%s) Tj ET\n",
+		font+1, size, c[0], c[1], c[2], x, y, pdfEscape(s))
+}
+
+// paragraph wraps text into the width between x and the right margin and
+// lays it out, returning the lines' areas so they can be made links
+func (d *pdfDocument) paragraph(x float64, s string, font pdfFont, size float64, c pdfColor) [][4]float64 {
+	lineHeight := size * 1.35
+	var areas [][4]float64
+	for _, line := range pdfWrap(pdfText(s), font, size, pdfPageWidth-pdfMargin-x) {
+		d.ensure(lineHeight)
+		d.y -= lineHeight
+		d.page().text(x, d.y+size*0.3, line, font, size, c)
+		areas = append(areas, [4]float64{x, d.y, x + pdfTextWidth(line, font, size), d.y + lineHeight})
+	}
+	return areas
+}
+
+// link lays out text opening a URL when clicked
+func (d *pdfDocument) link(x float64, s, uri string, size float64) {
+	for _, area := range d.paragraph(x, s, fontRegular, size, pdfLink) {
+		d.page().annots = append(d.page().annots, pdfAnnotation{rect: area, uri: uri})
+	}
+}
+
+// fillRect fills a rectangle whose lower left corner is at x, y
+func (d *pdfDocument) fillRect(x, y, w, h float64, c pdfColor) {
+	fmt.Fprintf(&d.page().content, "%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f\n", c[0], c[1], c[2], x, y, w, h)
+}
+
+// rule draws a horizontal line across the text width at y
+func (d *pdfDocument) rule(y, width float64, c pdfColor) {
+	fmt.Fprintf(&d.page().content, "%.3f %.3f %.3f RG %.2f w %.2f %.2f m %.2f %.2f l S\n",
+		c[0], c[1], c[2], width, pdfMargin, y, pdfPageWidth-pdfMargin, y)
+}
+
+// circle appends a circle's path, to fill or clip to
+func (d *pdfDocument) circle(cx, cy, r float64) {
+	k := r * 0.5523 // Places the Bézier control points on a quarter circle
+	fmt.Fprintf(&d.page().content, "%.2f %.2f m %.2f %.2f %.2f %.2f %.2f %.2f c %.2f %.2f %.2f %.2f %.2f %.2f c %.2f %.2f %.2f %.2f %.2f %.2f c %.2f %.2f %.2f %.2f %.2f %.2f c h\n",
+		cx+r, cy,
+		cx+r, cy+k, cx+k, cy+r, cx, cy+r,
+		cx-k, cy+r, cx-r, cy+k, cx-r, cy,
+		cx-r, cy-k, cx-k, cy-r, cx, cy-r,
+		cx+k, cy-r, cx+r, cy-k, cx+r, cy)
+}
+
+// fillCircle fills a circle centred on cx, cy
+func (d *pdfDocument) fillCircle(cx, cy, r float64, c pdfColor) {
+	fmt.Fprintf(&d.page().content, "%.3f %.3f %.3f rg\n", c[0], c[1], c[2])
+	d.circle(cx, cy, r)
+	d.page().content.WriteString("f\n")
+}
+
+// roundImage draws an image cropped to the circle of diameter size whose
+// lower left corner is at x, y
+func (d *pdfDocument) roundImage(index int, x, y, size float64) {
+	page := d.page()
+	page.content.WriteString("q\n")
+	d.circle(x+size/2, y+size/2, size/2)
+	fmt.Fprintf(&page.content, "W n %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", size, size, x, y, index+1)
+	for _, used := range page.images {
+		if used == index {
+			return
+		}
+	}
+	page.images = append(page.images, index)
+}
+
+// addDataURI adds an image from a data: URI, scaled down to at most
+// pdfImageSize pixels a side. Formats Go cannot decode, such as WebP, are
+// reported rather than embedded.
+func (d *pdfDocument) addDataURI(uri string) (int, error) {
+	_, encoded, found := strings.Cut(uri, ";base64,")
+	if !found {
+		return 0, fmt.Errorf("not a base64 data URI")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return 0, fmt.Errorf("empty image")
+	}
+	if width > pdfImageSize || height > pdfImageSize {
+		scale := float64(pdfImageSize) / float64(max(width, height))
+		width, height = max(1, int(float64(width)*scale)), max(1, int(float64(height)*scale))
+	}
+	// Transparent pixels are laid on white, the page's color
+	rgb := make([]byte, 0, width*height*3)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, a := img.At(bounds.Min.X+x*bounds.Dx()/width, bounds.Min.Y+y*bounds.Dy()/height).RGBA()
+			white := 255 - a>>8
+			rgb = append(rgb, byte(r>>8+white), byte(g>>8+white), byte(b>>8+white))
+		}
+	}
+	d.images = append(d.images, pdfImage{width: width, height: height, data: pdfCompress(rgb)})
+	return len(d.images) - 1, nil
+}
+
+// write lays out the page footers and writes the document
+func (d *pdfDocument) write(w io.Writer) error {
+	var objects [][]byte // Object n is objects[n-1]
+	reserve := func() int {
+		objects = append(objects, nil)
+		return len(objects)
+	}
+	set := func(id int, format string, args ...interface{}) {
+		objects[id-1] = fmt.Appendf(nil, format, args...)
+	}
+	stream := func(id int, dict string, data []byte) {
+		objects[id-1] = append(fmt.Appendf(nil, "<< %s /Length %d >>\nstream\n", dict, len(data)), append(data, "\nendstream"...)...)
+	}
+
+	catalog, pagesRoot, info := reserve(), reserve(), reserve()
+	var fonts strings.Builder
+	for i, name := range pdfFontNames {
+		id := reserve()
+		set(id, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name)
+		fmt.Fprintf(&fonts, " /F%d %d 0 R", i+1, id)
+	}
+	images := make([]int, len(d.images))
+	for i, img := range d.images {
+		images[i] = reserve()
+		stream(images[i], fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode", img.width, img.height), img.data)
+	}
+
+	pageIDs := make(map[*pdfPage]int)
+	kids := make([]string, len(d.pages))
+	for i, page := range d.pages {
+		pageIDs[page] = reserve()
+		kids[i] = fmt.Sprintf("%d 0 R", pageIDs[page])
+	}
+	dest := func(to pdfDest) string {
+		return fmt.Sprintf("[%d 0 R /XYZ 0 %.2f null]", pageIDs[to.page], to.y)
+	}
+
+	for i, page := range d.pages {
+		if i > 0 && d.footer != "" {
+			number := pdfText(fmt.Sprintf("Page %d of %d", i+1, len(kids)))
+			page.text(pdfMargin, pdfMargin-pdfFooter/2, pdfText(d.footer), fontRegular, 8, pdfMuted)
+			page.text(pdfPageWidth-pdfMargin-pdfTextWidth(number, fontRegular, 8), pdfMargin-pdfFooter/2, number, fontRegular, 8, pdfMuted)
+		}
+		content := reserve()
+		stream(content, "/Filter /FlateDecode", pdfCompress(page.content.Bytes()))
+
+		var resources strings.Builder
+		fmt.Fprintf(&resources, "<< /Font <<%s >>", fonts.String())
+		if len(page.images) > 0 {
+			resources.WriteString(" /XObject <<")
+			for _, index := range page.images {
+				fmt.Fprintf(&resources, " /Im%d %d 0 R", index+1, images[index])
+			}
+			resources.WriteString(" >>")
+		}
+		resources.WriteString(" >>")
+
+		var annots strings.Builder
+		for _, annot := range page.annots {
+			rect := fmt.Sprintf("[%.2f %.2f %.2f %.2f]", annot.rect[0], annot.rect[1], annot.rect[2], annot.rect[3])
+			if annot.dest != nil {
+				fmt.Fprintf(&annots, " << /Type /Annot /Subtype /Link /Rect %s /Border [0 0 0] /Dest %s >>", rect, dest(*annot.dest))
+			} else {
+				fmt.Fprintf(&annots, " << /Type /Annot /Subtype /Link /Rect %s /Border [0 0 0] /A << /S /URI /URI (%s) >> >>", rect, pdfEscape([]byte(annot.uri)))
+			}
+		}
+		set(pageIDs[page], "<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources %s /Contents %d 0 R /Annots [%s ] >>",
+			pagesRoot, pdfPageWidth, pdfPageHeight, resources.String(), content, annots.String())
+	}
+	set(pagesRoot, "<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids))
+
+	var addOutlines func(parent int, items []*pdfOutline) (first, last, count int)
+	addOutlines = func(parent int, items []*pdfOutline) (first, last, count int) {
+		ids := make([]int, len(items))
+		for i := range items {
+			ids[i] = reserve()
+		}
+		for i, item := range items {
+			dict := fmt.Sprintf("/Title %s /Parent %d 0 R /Dest %s", pdfUTF16(item.title), parent, dest(item.dest))
+			if i > 0 {
+				dict += fmt.Sprintf(" /Prev %d 0 R", ids[i-1])
+			}
+			if i < len(ids)-1 {
+				dict += fmt.Sprintf(" /Next %d 0 R", ids[i+1])
+			}
+			count++
+			if len(item.children) > 0 {
+				f, l, c := addOutlines(ids[i], item.children)
+				dict += fmt.Sprintf(" /First %d 0 R /Last %d 0 R /Count %d", f, l, c)
+				count += c
+			}
+			set(ids[i], "<< %s >>", dict)
+		}
+		return ids[0], ids[len(ids)-1], count
+	}
+	if len(d.outlines) > 0 {
+		outlines := reserve()
+		first, last, count := addOutlines(outlines, d.outlines)
+		set(outlines, "<< /Type /Outlines /First %d 0 R /Last %d 0 R /Count %d >>", first, last, count)
+		set(catalog, "<< /Type /Catalog /Pages %d 0 R /Outlines %d 0 R /PageMode /UseOutlines >>", pagesRoot, outlines)
+	} else {
+		set(catalog, "<< /Type /Catalog /Pages %d 0 R >>", pagesRoot)
+	}
+	set(info, "<< /Title %s /Producer (MercuriesOST) /CreationDate (D:%s) >>", pdfUTF16(d.title), time.Now().UTC().Format("20060102150405Z"))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n", i+1)
+		out.Write(object)
+		out.WriteString("\nendobj\n")
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, catalog, info, xref)
+	_, err := out.WriteTo(w)
+	return err
+}
+
+// pdfText encodes text in the Windows-1252 encoding the fonts are set to.
+// Characters it lacks print as question marks, and control characters other
+// than tabs are dropped.
+func pdfText(s string) []byte {
+	encoded := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			r = ' '
+		case r < ' ':
+			continue
+		}
+		if b, ok := charmap.Windows1252.EncodeRune(r); ok {
+			encoded = append(encoded, b)
+		} else {
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
+
+// pdfTextWidth measures encoded text, in points
+func pdfTextWidth(s []byte, font pdfFont, size float64) float64 {
+	if font == fontMono {
+		return float64(len(s)) * 600 * size / 1000
+	}
+	widths := &helveticaWidths
+	if font == fontBold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for _, b := range s {
+		if b >= 32 && b < 127 {
+			total += widths[b-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// pdfWrap breaks encoded text into lines no wider than width, between words
+// where it can and inside words too long for a line
+func pdfWrap(s []byte, font pdfFont, size, width float64) [][]byte {
+	var lines [][]byte
+	var line []byte
+	for _, word := range bytes.Fields(s) {
+		candidate := append(append(append([]byte(nil), line...), ' '), word...)
+		if len(line) == 0 {
+			candidate = word
+		}
+		if pdfTextWidth(candidate, font, size) <= width {
+			line = candidate
+			continue
+		}
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+		line = nil
+		for len(word) > 0 {
+			n := len(word)
+			for n > 1 && pdfTextWidth(word[:n], font, size) > width {
+				n--
+			}
+			if n == len(word) {
+				line = word
+				break
+			}
+			lines = append(lines, word[:n])
+			word = word[n:]
+		}
+	}
+	if len(line) > 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// pdfEscape escapes a string for the inside of a PDF literal string
+func pdfEscape(s []byte) []byte {
+	var escaped []byte
+	for _, b := range s {
+		switch {
+		case b == '(' || b == ')' || b == '\\':
+			escaped = append(escaped, '\\', b)
+		case b < ' ' || b > '~':
+			escaped = fmt.Appendf(escaped, "\\%03o", b)
+		default:
+			escaped = append(escaped, b)
+		}
+	}
+	return escaped
+}
+
+// pdfUTF16 writes text as a PDF text string, in UTF-16 so bookmarks and the
+// title show every script
+func pdfUTF16(s string) string {
+	var hex strings.Builder
+	hex.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&hex, "%04X", unit)
+	}
+	hex.WriteString(">")
+	return hex.String()
+}
+
+// pdfCompress deflates a stream, as its /FlateDecode filter expects
+func pdfCompress(data []byte) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
@@ -0,0 +1,299 @@
+package osint
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/assets/datasets"
+)
+
+// PGPInfo holds public keys and Keybase identities linked to an email address
+type PGPInfo struct {
+	Keys          []PGPKey        `json:"keys,omitempty"`
+	Keybase       *KeybaseProfile `json:"keybase,omitempty"`
+	LinkedEmails  []string        `json:"linked_emails,omitempty"`
+	LinkedDomains []string        `json:"linked_domains,omitempty"`
+}
+
+// PGPKey is a public key published to a keyserver
+type PGPKey struct {
+	Fingerprint string   `json:"fingerprint"`
+	Algorithm   string   `json:"algorithm,omitempty"`
+	Bits        int      `json:"bits,omitempty"`
+	Created     string   `json:"created,omitempty"`
+	Expires     string   `json:"expires,omitempty"`
+	Revoked     bool     `json:"revoked,omitempty"`
+	Identities  []string `json:"identities"`
+	Keyservers  []string `json:"keyservers"`
+}
+
+// KeybaseProfile is a Keybase account and the identity proofs attached to it
+type KeybaseProfile struct {
+	Username  string         `json:"username"`
+	FullName  string         `json:"full_name,omitempty"`
+	Location  string         `json:"location,omitempty"`
+	Created   string         `json:"created,omitempty"`
+	MatchedBy string         `json:"matched_by"` // key fingerprint or username
+	Proofs    []KeybaseProof `json:"proofs,omitempty"`
+}
+
+// KeybaseProof is a verified link between a Keybase account and another identity
+type KeybaseProof struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Keyservers are queried over HKP with machine-readable index output
+var Keyservers = []struct {
+	Name string
+	URL  string
+}{
+	{"keys.openpgp.org", "https://keys.openpgp.org"},
+	{"keyserver.ubuntu.com", "https://keyserver.ubuntu.com"},
+}
+
+// pgpAlgorithms names the OpenPGP public key algorithm IDs
+var pgpAlgorithms = map[int]string{
+	1: "RSA", 2: "RSA", 3: "RSA", 16: "Elgamal", 17: "DSA", 18: "ECDH", 19: "ECDSA", 22: "EdDSA",
+}
+
+// lookupPGP searches keyservers for the email and Keybase for the resulting keys,
+// falling back to the username when no key is linked to an account
+func lookupPGP(ctx context.Context, email, username string) (PGPInfo, error) {
+	var info PGPInfo
+	var errs []string
+
+	keys := make(map[string]*PGPKey)
+	var order []string
+	for _, server := range Keyservers {
+		found, err := searchKeyserver(ctx, server.URL, email)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", server.Name, err))
+			continue
+		}
+		for _, key := range found {
+			existing, ok := keys[key.Fingerprint]
+			if !ok {
+				key.Keyservers = []string{server.Name}
+				keys[key.Fingerprint] = &key
+				order = append(order, key.Fingerprint)
+				continue
+			}
+			existing.Keyservers = append(existing.Keyservers, server.Name)
+			existing.Identities = mergeStrings(existing.Identities, key.Identities)
+			existing.Revoked = existing.Revoked || key.Revoked
+		}
+	}
+	for _, fingerprint := range order {
+		info.Keys = append(info.Keys, *keys[fingerprint])
+	}
+
+	// A key fingerprint ties a Keybase account to the address; a username only suggests it
+	for _, key := range info.Keys {
+		if len(key.Fingerprint) != 40 {
+			continue
+		}
+		profile, err := lookupKeybase(ctx, "key_fingerprint", strings.ToLower(key.Fingerprint))
+		if err != nil {
+			errs = append(errs, "Keybase: "+err.Error())
+			break
+		}
+		if profile != nil {
+			profile.MatchedBy = "key fingerprint " + key.Fingerprint
+			info.Keybase = profile
+			break
+		}
+	}
+	if info.Keybase == nil && username != "" {
+		profile, err := lookupKeybase(ctx, "usernames", username)
+		if err != nil {
+			errs = append(errs, "Keybase: "+err.Error())
+		} else if profile != nil {
+			profile.MatchedBy = "username"
+			info.Keybase = profile
+		}
+	}
+
+	info.LinkedEmails, info.LinkedDomains = linkedIdentities(info, email)
+
+	if len(errs) > 0 {
+		return info, fmt.Errorf("PGP lookup failed (%s)", strings.Join(errs, "; "))
+	}
+	return info, nil
+}
+
+// searchKeyserver runs an HKP index search and parses the machine-readable output
+func searchKeyserver(ctx context.Context, server, search string) ([]PGPKey, error) {
+	target := server + "/pks/lookup?op=index&options=mr&search=" + url.QueryEscape(search)
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := doProviderRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// HKP servers answer 404 when nothing matches
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	var keys []PGPKey
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), ":")
+		switch fields[0] {
+		case "pub":
+			keys = append(keys, parseHKPKey(fields))
+		case "uid":
+			if len(keys) == 0 || len(fields) < 2 {
+				continue
+			}
+			uid, err := url.PathUnescape(fields[1])
+			if err != nil {
+				uid = fields[1]
+			}
+			last := &keys[len(keys)-1]
+			last.Identities = append(last.Identities, uid)
+		}
+	}
+	return keys, scanner.Err()
+}
+
+// parseHKPKey parses a "pub:keyid:algo:keylen:created:expires:flags" index line
+func parseHKPKey(fields []string) PGPKey {
+	field := func(i int) string {
+		if i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+
+	key := PGPKey{Fingerprint: strings.ToUpper(field(1)), Identities: []string{}}
+	if algo, err := strconv.Atoi(field(2)); err == nil {
+		key.Algorithm = pgpAlgorithms[algo]
+	}
+	key.Bits, _ = strconv.Atoi(field(3))
+	key.Created = hkpTime(field(4))
+	key.Expires = hkpTime(field(5))
+	key.Revoked = strings.Contains(field(6), "r")
+	return key
+}
+
+// hkpTime converts an HKP Unix timestamp to a date
+func hkpTime(value string) string {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return ""
+	}
+	return time.Unix(seconds, 0).UTC().Format("2006-01-02")
+}
+
+// lookupKeybase fetches a Keybase user by fingerprint or username; a nil
+// profile means no account matched
+func lookupKeybase(ctx context.Context, param, value string) (*KeybaseProfile, error) {
+	var payload struct {
+		Status struct {
+			Code int    `json:"code"`
+			Name string `json:"name"`
+		} `json:"status"`
+		Them []*struct {
+			Basics struct {
+				Username string `json:"username"`
+				CTime    int64  `json:"ctime"`
+			} `json:"basics"`
+			Profile *struct {
+				FullName string `json:"full_name"`
+				Location string `json:"location"`
+			} `json:"profile"`
+			ProofsSummary struct {
+				All []struct {
+					ProofType  string `json:"proof_type"`
+					Nametag    string `json:"nametag"`
+					ServiceURL string `json:"service_url"`
+				} `json:"all"`
+			} `json:"proofs_summary"`
+		} `json:"them"`
+	}
+
+	target := fmt.Sprintf("https://keybase.io/_/api/1.0/user/lookup.json?%s=%s&fields=basics,profile,proofs_summary",
+		param, url.QueryEscape(value))
+	if err := getProviderJSON(ctx, target, nil, &payload); err != nil {
+		return nil, err
+	}
+
+	switch payload.Status.Name {
+	case "OK":
+	case "NOT_FOUND", "BAD_LOOKUP", "INPUT_ERROR":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("status %s", payload.Status.Name)
+	}
+	if len(payload.Them) == 0 || payload.Them[0] == nil {
+		return nil, nil
+	}
+
+	user := payload.Them[0]
+	profile := &KeybaseProfile{Username: user.Basics.Username}
+	if user.Basics.CTime > 0 {
+		profile.Created = time.Unix(user.Basics.CTime, 0).UTC().Format("2006-01-02")
+	}
+	if user.Profile != nil {
+		profile.FullName = user.Profile.FullName
+		profile.Location = user.Profile.Location
+	}
+	for _, proof := range user.ProofsSummary.All {
+		profile.Proofs = append(profile.Proofs, KeybaseProof{Type: proof.ProofType, Name: proof.Nametag, URL: proof.ServiceURL})
+	}
+	return profile, nil
+}
+
+// linkedIdentities collects the other addresses on the target's keys and the
+// domains behind them and behind Keybase website proofs
+func linkedIdentities(info PGPInfo, email string) ([]string, []string) {
+	var emails, domains []string
+	email = strings.ToLower(email)
+	personal := datasets.PersonalEmailDomains()
+
+	for _, key := range info.Keys {
+		for _, identity := range key.Identities {
+			for _, found := range extractEmails(identity) {
+				if found == email {
+					continue
+				}
+				emails = mergeStrings(emails, []string{found})
+				domain := found[strings.LastIndex(found, "@")+1:]
+				if !personal[domain] {
+					domains = mergeStrings(domains, []string{domain})
+				}
+			}
+		}
+	}
+
+	if info.Keybase != nil {
+		for _, proof := range info.Keybase.Proofs {
+			if proof.Type == "dns" || proof.Type == "generic_web_site" {
+				domains = mergeStrings(domains, []string{strings.ToLower(proof.Name)})
+			}
+		}
+	}
+
+	sort.Strings(emails)
+	sort.Strings(domains)
+	return emails, domains
+}
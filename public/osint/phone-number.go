@@ -8,6 +8,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/awion/MercuriesOST/public/assets/datasets"
+	"github.com/awion/MercuriesOST/public/providers"
 	"github.com/fatih/color"
 	"github.com/nyaruka/phonenumbers"
 )
@@ -15,6 +17,7 @@ import (
 // PhoneNumberResult represents complete phone number analysis results
 type PhoneNumberResult struct {
 	Number          string                `json:"number"`
+	ScanID          string                `json:"scan_id,omitempty"`
 	E164Format      string                `json:"e164_format"`
 	CountryCode     int32                 `json:"country_code"`
 	NationalNumber  uint64                `json:"national_number"`
@@ -179,6 +182,7 @@ type Report struct {
 func AnalyzePhoneNumber(ctx context.Context, phoneNumber string) (*PhoneNumberResult, error) {
 	// Initialize result
 	result := &PhoneNumberResult{
+		ScanID:          providers.ScanIDFrom(ctx),
 		Number:          phoneNumber,
 		SearchTimestamp: time.Now().Format(time.RFC3339),
 	}
@@ -351,36 +355,14 @@ func AnalyzePhoneNumber(ctx context.Context, phoneNumber string) (*PhoneNumberRe
 // Helper functions
 
 func getCountryName(region string) string {
-	countries := map[string]string{
-		"ID": "Indonesia",
-		"US": "United States",
-		"GB": "United Kingdom",
-		"MY": "Malaysia",
-		"SG": "Singapore",
-		"AU": "Australia",
-		"JP": "Japan",
-		"KR": "South Korea",
-		"CN": "China",
-		"IN": "India",
-		"TH": "Thailand",
-		"VN": "Vietnam",
-		"PH": "Philippines",
-	}
-	if name, ok := countries[region]; ok {
+	if name, ok := datasets.CountryNames()[region]; ok {
 		return name
 	}
 	return fmt.Sprintf("Unknown (%s)", region)
 }
 
 func getTimeZones(region string) []string {
-	timeZones := map[string][]string{
-		"ID": {"Asia/Jakarta", "Asia/Makassar", "Asia/Jayapura"},
-		"MY": {"Asia/Kuala_Lumpur"},
-		"SG": {"Asia/Singapore"},
-		"US": {"America/New_York", "America/Chicago", "America/Denver", "America/Los_Angeles"},
-		"GB": {"Europe/London"},
-	}
-	if zones, ok := timeZones[region]; ok {
+	if zones, ok := datasets.TimeZones()[region]; ok {
 		return zones
 	}
 	return []string{"Unknown"}
@@ -440,44 +422,6 @@ func validateNumber(num *phonenumbers.PhoneNumber) PhoneValidationResult {
 }
 
 func lookupCarrier(ctx context.Context, num *phonenumbers.PhoneNumber) CarrierInfo {
-	// Indonesian carriers mapping with more detailed info
-	indonesianCarriers := map[string]struct {
-		name     string
-		network  string
-		services []string
-		regions  []string
-		mcc      string
-		mnc      string
-	}{
-		"811": {"Telkomsel", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "10"},
-		"812": {"Telkomsel", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "10"},
-		"813": {"Telkomsel", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "10"},
-		"821": {"Indosat", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "21"},
-		"822": {"Indosat", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "21"},
-		"823": {"Indosat", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "21"},
-		"851": {"XL", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "11"},
-		"852": {"XL", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "11"},
-		"853": {"XL", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "11"},
-		"895": {"Three", "GSM/4G", []string{"Voice", "SMS", "MMS", "Data", "VoLTE"}, []string{"National"}, "510", "89"},
-		"896": {"Three", "GSM/4G", []string{"Voice", "SMS", "MMS", "Data", "VoLTE"}, []string{"National"}, "510", "89"},
-		"897": {"Three", "GSM/4G", []string{"Voice", "SMS", "MMS", "Data", "VoLTE"}, []string{"National"}, "510", "89"},
-		"898": {"Three", "GSM/4G", []string{"Voice", "SMS", "MMS", "Data", "VoLTE"}, []string{"National"}, "510", "89"},
-		"899": {"Three", "GSM/4G", []string{"Voice", "SMS", "MMS", "Data", "VoLTE"}, []string{"National"}, "510", "89"},
-		"817": {"XL", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "11"},
-		"818": {"XL", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "11"},
-		"819": {"XL", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "11"},
-		"859": {"XL", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "11"},
-		"877": {"XL", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "11"},
-		"878": {"XL", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "11"},
-		"814": {"Indosat", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "21"},
-		"815": {"Indosat", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "21"},
-		"816": {"Indosat", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "21"},
-		"855": {"Indosat", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "21"},
-		"856": {"Indosat", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "21"},
-		"857": {"Indosat", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "21"},
-		"858": {"Indosat", "GSM/4G/5G", []string{"Voice", "SMS", "MMS", "Data", "5G", "VoLTE"}, []string{"National"}, "510", "21"},
-	}
-
 	// Get the national number as string
 	nationalNum := fmt.Sprintf("%d", num.GetNationalNumber())
 
@@ -487,16 +431,15 @@ func lookupCarrier(ctx context.Context, num *phonenumbers.PhoneNumber) CarrierIn
 		prefix = nationalNum[:3]
 	}
 
-	// Check if it's an Indonesian carrier
-	if num.GetCountryCode() == 62 { // Indonesia
-		if carrier, ok := indonesianCarriers[prefix]; ok {
-			return CarrierInfo{
-				Name:          carrier.name,
-				Type:          carrier.network,
-				MobileCountry: "Indonesia",
-				MobileNetwork: fmt.Sprintf("%s/%s", carrier.mcc, carrier.mnc),
-				Services:      carrier.services,
-			}
+	// Check the carrier table for the number's calling code
+	countryCode := fmt.Sprintf("%d", num.GetCountryCode())
+	if carrier, ok := datasets.Carriers()[countryCode][prefix]; ok {
+		return CarrierInfo{
+			Name:          carrier.Name,
+			Type:          carrier.Network,
+			MobileCountry: getCountryFromCode(num.GetCountryCode()),
+			MobileNetwork: fmt.Sprintf("%s/%s", carrier.MCC, carrier.MNC),
+			Services:      carrier.Services,
 		}
 	}
 
@@ -514,36 +457,7 @@ func lookupCarrier(ctx context.Context, num *phonenumbers.PhoneNumber) CarrierIn
 }
 
 func getCountryFromCode(code int32) string {
-	// Add more country codes
-	countries := map[int32]string{
-		62:  "Indonesia",
-		60:  "Malaysia",
-		65:  "Singapore",
-		66:  "Thailand",
-		84:  "Vietnam",
-		63:  "Philippines",
-		81:  "Japan",
-		82:  "South Korea",
-		86:  "China",
-		91:  "India",
-		61:  "Australia",
-		64:  "New Zealand",
-		1:   "United States/Canada",
-		44:  "United Kingdom",
-		49:  "Germany",
-		33:  "France",
-		39:  "Italy",
-		34:  "Spain",
-		351: "Portugal",
-		55:  "Brazil",
-		52:  "Mexico",
-		54:  "Argentina",
-		20:  "Egypt",
-		27:  "South Africa",
-		971: "United Arab Emirates",
-	}
-
-	if name, ok := countries[code]; ok {
+	if name, ok := datasets.DialingCodes()[code]; ok {
 		return name
 	}
 	return fmt.Sprintf("Country Code %d", code)
@@ -910,3 +824,46 @@ func (r *PhoneNumberResult) DisplayResults() {
 		}
 	}
 }
+
+// DisplayDetails prints what DisplayResults leaves out: the carrier's network
+// codes, fraud warnings and the lookups that found nothing
+func (r *PhoneNumberResult) DisplayDetails() {
+	color.Cyan("\n[Details]")
+	if r.Carrier.MobileCountry != "" {
+		color.White("• Carrier Country: %s", r.Carrier.MobileCountry)
+	}
+	if r.Carrier.MobileNetwork != "" {
+		color.White("• Network Code (MCC/MNC): %s", r.Carrier.MobileNetwork)
+	}
+	if r.ValidationInfo.Format != "" {
+		color.White("• Format: %s", r.ValidationInfo.Format)
+	}
+	for _, warning := range r.RiskAssessment.FraudWarnings {
+		color.Yellow("• Warning: %s", warning)
+	}
+	for _, activity := range r.RiskAssessment.ReportedActivity {
+		color.Yellow("• Reported: %s", activity)
+	}
+
+	empty := []struct {
+		name  string
+		found bool
+	}{
+		{"Online presence", len(r.OnlinePresence) > 0},
+		{"Messaging apps", len(r.MessagingApps) > 0},
+		{"Reverse lookup", len(r.ReverseLookup.PossibleOwners) > 0},
+		{"Activity history", len(r.ActivityHistory) > 0},
+		{"Device information", r.DeviceInfo.Model != ""},
+		{"Location history", len(r.LocationHistory) > 0},
+		{"Registration", r.Registration.Date != ""},
+		{"Porting history", len(r.PortingHistory) > 0},
+		{"Network usage", r.NetworkUsage.LastActive != ""},
+		{"Social footprint", len(r.SocialFootprint.Platforms) > 0},
+		{"Reputation", r.Reputation.Score > 0},
+	}
+	for _, lookup := range empty {
+		if !lookup.found {
+			color.White("• %s: nothing found", lookup.name)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/awion/MercuriesOST/public/providers"
+)
+
+// UnreachablePlatform is a platform left out of a scan because the account
+// known to exist on it could not be found, so a missing profile there says
+// nothing about the target
+type UnreachablePlatform struct {
+	Platform string `json:"platform"`
+	Reason   string `json:"reason"`
+}
+
+// probe checks the known account of every platform that has one before the
+// scan. Platforms that are down, blocking the scanner or have changed their
+// pages fail the check and are returned as unreachable rather than reporting
+// every profile on them as missing.
+func (p *scanPool) probe(ctx context.Context, platforms []SocialPlatform) []UnreachablePlatform {
+	var (
+		mu          sync.Mutex
+		wg          sync.WaitGroup
+		unreachable []UnreachablePlatform
+	)
+	for _, platform := range platforms {
+		if platform.KnownAccount == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(platform SocialPlatform) {
+			defer wg.Done()
+			if err := p.limiter.Wait(ctx); err != nil {
+				return
+			}
+			result := p.check(ctx, p.client, workItem{platform: platform, term: platform.KnownAccount})
+			// A dry run plans the scan of every platform
+			if result.Exists || providers.Planning() {
+				return
+			}
+			reason := fmt.Sprintf("known account %s was not found", platform.KnownAccount)
+			if result.Error != "" {
+				reason = fmt.Sprintf("known account %s could not be checked: %s", platform.KnownAccount, result.Error)
+			}
+			mu.Lock()
+			unreachable = append(unreachable, UnreachablePlatform{Platform: platform.Name, Reason: reason})
+			mu.Unlock()
+		}(platform)
+	}
+	wg.Wait()
+
+	sort.Slice(unreachable, func(i, j int) bool { return unreachable[i].Platform < unreachable[j].Platform })
+	return unreachable
+}
+
+// reachablePlatforms returns the platforms not listed as unreachable
+func reachablePlatforms(platforms []SocialPlatform, unreachable []UnreachablePlatform) []SocialPlatform {
+	if len(unreachable) == 0 {
+		return platforms
+	}
+	down := make(map[string]bool, len(unreachable))
+	for _, platform := range unreachable {
+		down[platform.Platform] = true
+	}
+	var reachable []SocialPlatform
+	for _, platform := range platforms {
+		if !down[platform.Name] {
+			reachable = append(reachable, platform)
+		}
+	}
+	return reachable
+}
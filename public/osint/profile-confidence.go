@@ -0,0 +1,25 @@
+package osint
+
+import "strings"
+
+// MinConfidence moves profiles scoring below it from a search's results to
+// its leads, whatever their tier would be
+var MinConfidence = 0.0
+
+// Confidence penalties for profiles found under a handle other than the one
+// searched. A generated variation such as "john" for "John Doe", or a
+// near-variant of a found handle, exists for many people besides the target.
+const (
+	variationPenalty   = 0.8
+	nearVariantPenalty = 0.8
+)
+
+// scoreProfile turns a profile's validation confidence into its match
+// confidence for the queried handle, given lowercased without spaces. A
+// profile under another handle was only inferred to be the target's.
+func scoreProfile(queried string, profile *ProfileResult) {
+	if strings.ToLower(strings.ReplaceAll(profile.Username, " ", "")) != queried {
+		profile.Confidence *= variationPenalty
+		profile.Evidence = EvidenceInferred
+	}
+}
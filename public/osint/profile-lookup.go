@@ -0,0 +1,58 @@
+package osint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// errNoProfileLookup sends checkProfile back to reading the profile page,
+// for lookups without a key or pages without the data they read
+var errNoProfileLookup = errors.New("no profile lookup available")
+
+// profileLookup fills in a profile from a platform's API or page data,
+// reporting whether the profile exists
+type profileLookup func(ctx context.Context, handle string, result *ProfileResult) (bool, error)
+
+// profileLookups maps platform names to the lookups checkProfile tries
+// before reading the profile page with selectors
+var profileLookups = map[string]profileLookup{
+	"Spotify":       spotifyProfile,
+	"SoundCloud":    soundCloudProfile,
+	"LastFM":        lastFMProfile,
+	"Strava":        stravaProfile,
+	"GarminConnect": garminProfile,
+}
+
+// lookupProfile runs the platform's lookup for a profile URL, returning
+// errNoProfileLookup when the page has to be read instead
+func lookupProfile(platform SocialPlatform, profileURL string, result *ProfileResult) error {
+	lookup, ok := profileLookups[platform.Name]
+	if !ok {
+		return errNoProfileLookup
+	}
+	u, err := url.Parse(profileURL)
+	if err != nil {
+		return err
+	}
+	handle, err := profileHandle(platform, u)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), RequestTimeout)
+	defer cancel()
+	exists, err := lookup(ctx, handle, result)
+	if err != nil || !exists {
+		return err
+	}
+	result.Exists = true
+	if result.Evidence == EvidenceAPI {
+		result.Insights = append(result.Insights, fmt.Sprintf("Profile confirmed by the %s API", platform.Name))
+	}
+	resolveCanonicalProfile(result, platform, "")
+	extractInsights(result)
+	result.BioLinks = expandShortLinks(ctx, result.Bio, maxBioLinks)
+	return nil
+}
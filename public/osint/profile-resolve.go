@@ -0,0 +1,187 @@
+package osint
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// profileIDRegexes and profileNameRegexes cache the compiled IDPatterns and
+// NamePatterns of each platform
+var (
+	profileIDRegexes   = map[string][]*regexp.Regexp{}
+	profileNameRegexes = map[string][]*regexp.Regexp{}
+)
+
+func init() {
+	for _, platform := range platforms {
+		for _, pattern := range platform.IDPatterns {
+			profileIDRegexes[platform.Name] = append(profileIDRegexes[platform.Name], regexp.MustCompile(pattern))
+		}
+		for _, pattern := range platform.NamePatterns {
+			profileNameRegexes[platform.Name] = append(profileNameRegexes[platform.Name], regexp.MustCompile(pattern))
+		}
+	}
+}
+
+// resolveCanonicalProfile records the platform's own ID for a profile found
+// under a vanity name or alias URL, and the URL that keeps pointing at the
+// account after a rename
+func resolveCanonicalProfile(result *ProfileResult, platform SocialPlatform, page string) {
+	page = html.UnescapeString(page)
+	for _, re := range profileIDRegexes[platform.Name] {
+		if m := re.FindStringSubmatch(page); m != nil {
+			result.CanonicalID = m[1]
+			break
+		}
+	}
+
+	result.IDCreated = IDCreated(platform.IDFormat, result.CanonicalID)
+
+	switch {
+	case result.CanonicalID != "" && platform.CanonicalURL != "":
+		result.CanonicalURL = fmt.Sprintf(platform.CanonicalURL, result.CanonicalID)
+	case result.CanonicalURL == "":
+		result.CanonicalURL = result.URL
+	}
+}
+
+// pageDisplayName returns the display name a platform's NamePatterns find in
+// a profile page, with JSON escapes decoded, or ""
+func pageDisplayName(platform SocialPlatform, page string) string {
+	page = html.UnescapeString(page)
+	for _, re := range profileNameRegexes[platform.Name] {
+		m := re.FindStringSubmatch(page)
+		if m == nil {
+			continue
+		}
+		name := m[1]
+		if unquoted, err := strconv.Unquote(`"` + name + `"`); err == nil {
+			name = unquoted
+		}
+		if name = cleanText(name); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// platformForHost returns the platform serving profiles on a host
+func platformForHost(host string) (SocialPlatform, bool) {
+	host = strings.ToLower(host)
+	for _, platform := range platforms {
+		if main, err := url.Parse(platform.URL); err == nil && main.Hostname() == host {
+			return platform, true
+		}
+		for _, alias := range platform.Hosts {
+			if alias == host {
+				return platform, true
+			}
+		}
+	}
+	return SocialPlatform{}, false
+}
+
+// profileHandle extracts what goes into a platform's ProfilePattern from a
+// profile URL on any of its hosts: "x.com/name", "reddit.com/u/name",
+// "m.facebook.com/profile.php?id=4" and "t.me/joinchat/HASH" all resolve
+func profileHandle(platform SocialPlatform, u *url.URL) (string, error) {
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+
+	switch platform.Name {
+	case "Facebook":
+		if len(segments) > 0 && segments[0] == "profile.php" && u.Query().Get("id") != "" {
+			return "profile.php?id=" + u.Query().Get("id"), nil
+		}
+	case "Twitter":
+		if len(segments) == 2 && segments[0] == "intent" && u.Query().Get("user_id") != "" {
+			return "intent/user?user_id=" + u.Query().Get("user_id"), nil
+		}
+	case "PayPal":
+		// paypal.com serves PayPal.Me pages under /paypalme/
+		if len(segments) > 1 && strings.EqualFold(segments[0], "paypalme") {
+			segments = segments[1:]
+		}
+	case "GarminConnect":
+		// Profiles are app routes: "/modern/profile/name"
+		if len(segments) == 3 && segments[1] == "profile" {
+			return segments[2], nil
+		}
+	case "Airbnb":
+		if len(segments) == 3 && segments[0] == "users" && segments[1] == "show" {
+			return segments[2], nil
+		}
+	case "TripAdvisor":
+		if len(segments) == 2 && strings.EqualFold(segments[0], "Profile") {
+			return segments[1], nil
+		}
+	case "CashApp":
+		if len(segments) > 0 {
+			return strings.TrimPrefix(segments[0], "$"), nil
+		}
+	case "Telegram":
+		// Old-style invite links become the "+HASH" form; "/s/name" is a
+		// channel preview
+		if len(segments) == 2 && segments[0] == "joinchat" {
+			return "+" + segments[1], nil
+		}
+		if len(segments) == 2 && segments[0] == "s" {
+			segments = segments[1:]
+		}
+	}
+
+	if len(segments) > 1 {
+		switch segments[0] {
+		case "u", "user", "users", "in", "athletes":
+			segments = segments[1:]
+		}
+	}
+	if len(segments) == 0 {
+		return "", fmt.Errorf("no profile in %s", u.String())
+	}
+	return strings.TrimPrefix(segments[0], "@"), nil
+}
+
+// ResolveProfileURL takes a profile URL on any known platform host, vanity
+// or alias, fetches the profile and returns it with its canonical ID and URL
+func ResolveProfileURL(raw string) (*ProfileResult, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	platform, ok := platformForHost(u.Hostname())
+	if !ok {
+		return nil, fmt.Errorf("no known platform serves profiles on %s", u.Hostname())
+	}
+	handle, err := profileHandle(platform, u)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Transport: providers.Transport}
+	profileURL := platform.URL + fmt.Sprintf(platform.ProfilePattern, handle)
+	result := checkProfile(client, platform, profileURL, handle)
+	if result.Error != "" {
+		return &result, fmt.Errorf("%s", result.Error)
+	}
+	if !result.Exists {
+		return &result, fmt.Errorf("%s profile %s not found", platform.Name, handle)
+	}
+	return &result, nil
+}
+
+// DisplayResults prints a resolved profile
+func (r *ProfileResult) DisplayResults() {
+	color.Cyan("\n%s profile: %s", r.Platform, r.URL)
+	printProfileDetails(r)
+}
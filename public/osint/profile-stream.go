@@ -0,0 +1,156 @@
+package osint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// maxInMemoryProfiles caps the profiles a streamed search keeps for display
+// and analysis; the output file receives all of them
+const maxInMemoryProfiles = 5000
+
+// ProfileStream writes a search's results file as profiles are found, one
+// encoded profile at a time, so huge scans never hold the whole result set
+// or its encoding in memory. Leads are spilled to a temporary file and
+// copied in after the profiles. The file only replaces outputPath once
+// closed, so a failed scan leaves no partial results behind.
+type ProfileStream struct {
+	path      string
+	out       *os.File
+	leads     *os.File
+	written   int
+	leadCount int
+	err       error
+}
+
+// NewProfileStream starts the results file of a search
+func NewProfileStream(path string, results *SocialMediaResults) (*ProfileStream, error) {
+	out, err := os.CreateTemp(filepath.Dir(path), ".profiles-*.json")
+	if err != nil {
+		return nil, err
+	}
+	s := &ProfileStream{path: path, out: out}
+	query, _ := json.Marshal(results.Query)
+	timestamp, _ := json.Marshal(results.Timestamp)
+	head := fmt.Sprintf("{\n  \"query\": %s,\n", query)
+	if results.ScanID != "" {
+		scanID, _ := json.Marshal(results.ScanID)
+		head += fmt.Sprintf("  \"scan_id\": %s,\n", scanID)
+	}
+	s.write(s.out, head+fmt.Sprintf("  \"timestamp\": %s,\n  \"profiles\": [", timestamp))
+	return s, s.err
+}
+
+func (s *ProfileStream) write(w io.Writer, text string) {
+	if s.err == nil {
+		_, s.err = io.WriteString(w, text)
+	}
+}
+
+// Add appends a profile to the profiles, or to the leads when lead is set
+func (s *ProfileStream) Add(profile ProfileResult, lead bool) error {
+	w, count := s.out, &s.written
+	if lead {
+		if s.leads == nil && s.err == nil {
+			s.leads, s.err = os.CreateTemp(filepath.Dir(s.path), ".leads-*.json")
+		}
+		w, count = s.leads, &s.leadCount
+	}
+	if s.err != nil {
+		return s.err
+	}
+
+	data, err := json.MarshalIndent(profile, "    ", "  ")
+	if err != nil {
+		return err
+	}
+	if *count > 0 {
+		s.write(w, ",")
+	}
+	s.write(w, "\n    "+string(data))
+	*count++
+	return s.err
+}
+
+// Close writes the search-wide results after the profiles and moves the
+// file into place
+func (s *ProfileStream) Close(results *SocialMediaResults) error {
+	defer s.cleanup()
+	s.closeArray(s.written)
+
+	if s.leads != nil && s.err == nil {
+		s.write(s.out, ",\n  \"leads\": [")
+		if _, err := s.leads.Seek(0, io.SeekStart); err != nil && s.err == nil {
+			s.err = err
+		}
+		if s.err == nil {
+			_, s.err = io.Copy(s.out, s.leads)
+		}
+		s.closeArray(s.leadCount)
+	}
+
+	trailer := []struct {
+		key   string
+		value interface{}
+		set   bool
+	}{
+		{"profiles_found", results.ProfilesFound, true},
+		{"omitted_profiles", results.OmittedProfiles, results.OmittedProfiles > 0},
+		{"style_links", results.StyleLinks, len(results.StyleLinks) > 0},
+		{"activity_heatmap", results.Heatmap, results.Heatmap != nil},
+		{"location_clusters", results.Locations, len(results.Locations) > 0},
+		{"account_history", results.History, len(results.History) > 0},
+		{"social_graph", results.Graph, results.Graph != nil},
+		{"keyword_hits", results.Keywords, results.Keywords != nil},
+		{"unreachable_platforms", results.Unreachable, len(results.Unreachable) > 0},
+		{"translation_error", results.TranslationError, results.TranslationError != ""},
+		{"checkpoint", results.Checkpoint, results.Checkpoint != ""},
+		{"interrupted", results.Interrupted, results.Interrupted},
+		{"suppressed", results.Suppressed, len(results.Suppressed) > 0},
+	}
+	for _, field := range trailer {
+		if !field.set {
+			continue
+		}
+		data, err := json.MarshalIndent(field.value, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		s.write(s.out, fmt.Sprintf(",\n  %q: %s", field.key, data))
+	}
+	s.write(s.out, "\n}\n")
+
+	if s.err == nil {
+		s.err = s.out.Close()
+	}
+	if s.err == nil {
+		s.err = os.Rename(s.out.Name(), s.path)
+	}
+	return s.err
+}
+
+// closeArray ends a JSON array, on its own line when it has elements
+func (s *ProfileStream) closeArray(count int) {
+	if count > 0 {
+		s.write(s.out, "\n  ]")
+	} else {
+		s.write(s.out, "]")
+	}
+}
+
+// Abort discards the file being written
+func (s *ProfileStream) Abort() {
+	s.cleanup()
+}
+
+func (s *ProfileStream) cleanup() {
+	if s.leads != nil {
+		s.leads.Close()
+		os.Remove(s.leads.Name())
+	}
+	s.out.Close()
+	os.Remove(s.out.Name()) // Fails harmlessly once renamed
+}
@@ -0,0 +1,256 @@
+// Code generated by providermocks from providers.go. DO NOT EDIT.
+
+package osint
+
+import (
+	"context"
+	"fmt"
+)
+
+// MockBreachProvider is a BreachProvider whose methods are set per test
+type MockBreachProvider struct {
+	NameFunc        func() string
+	HealthCheckFunc func(ctx context.Context) error
+	BreachesFunc    func(ctx context.Context, email string) ([]Breach, error)
+}
+
+func (m *MockBreachProvider) Name() (r0 string) {
+	if m.NameFunc == nil {
+		return "MockBreachProvider"
+	}
+	return m.NameFunc()
+}
+
+func (m *MockBreachProvider) HealthCheck(ctx context.Context) (r0 error) {
+	if m.HealthCheckFunc == nil {
+		r0 = fmt.Errorf("MockBreachProvider.HealthCheck not set")
+		return
+	}
+	return m.HealthCheckFunc(ctx)
+}
+
+func (m *MockBreachProvider) Breaches(ctx context.Context, email string) (r0 []Breach, r1 error) {
+	if m.BreachesFunc == nil {
+		r1 = fmt.Errorf("MockBreachProvider.Breaches not set")
+		return
+	}
+	return m.BreachesFunc(ctx, email)
+}
+
+// MockGeoProvider is a GeoProvider whose methods are set per test
+type MockGeoProvider struct {
+	NameFunc        func() string
+	HealthCheckFunc func(ctx context.Context) error
+	GeolocateFunc   func(ctx context.Context, ip string) (GeoIPInfo, error)
+}
+
+func (m *MockGeoProvider) Name() (r0 string) {
+	if m.NameFunc == nil {
+		return "MockGeoProvider"
+	}
+	return m.NameFunc()
+}
+
+func (m *MockGeoProvider) HealthCheck(ctx context.Context) (r0 error) {
+	if m.HealthCheckFunc == nil {
+		r0 = fmt.Errorf("MockGeoProvider.HealthCheck not set")
+		return
+	}
+	return m.HealthCheckFunc(ctx)
+}
+
+func (m *MockGeoProvider) Geolocate(ctx context.Context, ip string) (r0 GeoIPInfo, r1 error) {
+	if m.GeolocateFunc == nil {
+		r1 = fmt.Errorf("MockGeoProvider.Geolocate not set")
+		return
+	}
+	return m.GeolocateFunc(ctx, ip)
+}
+
+// MockHostProvider is a HostProvider whose methods are set per test
+type MockHostProvider struct {
+	NameFunc        func() string
+	HealthCheckFunc func(ctx context.Context) error
+	HostFunc        func(ctx context.Context, ip string) (HostIntel, error)
+}
+
+func (m *MockHostProvider) Name() (r0 string) {
+	if m.NameFunc == nil {
+		return "MockHostProvider"
+	}
+	return m.NameFunc()
+}
+
+func (m *MockHostProvider) HealthCheck(ctx context.Context) (r0 error) {
+	if m.HealthCheckFunc == nil {
+		r0 = fmt.Errorf("MockHostProvider.HealthCheck not set")
+		return
+	}
+	return m.HealthCheckFunc(ctx)
+}
+
+func (m *MockHostProvider) Host(ctx context.Context, ip string) (r0 HostIntel, r1 error) {
+	if m.HostFunc == nil {
+		r1 = fmt.Errorf("MockHostProvider.Host not set")
+		return
+	}
+	return m.HostFunc(ctx, ip)
+}
+
+// MockIPReputationProvider is a IPReputationProvider whose methods are set per test
+type MockIPReputationProvider struct {
+	NameFunc         func() string
+	HealthCheckFunc  func(ctx context.Context) error
+	IPReputationFunc func(ctx context.Context, ip string) (IPVerdict, error)
+}
+
+func (m *MockIPReputationProvider) Name() (r0 string) {
+	if m.NameFunc == nil {
+		return "MockIPReputationProvider"
+	}
+	return m.NameFunc()
+}
+
+func (m *MockIPReputationProvider) HealthCheck(ctx context.Context) (r0 error) {
+	if m.HealthCheckFunc == nil {
+		r0 = fmt.Errorf("MockIPReputationProvider.HealthCheck not set")
+		return
+	}
+	return m.HealthCheckFunc(ctx)
+}
+
+func (m *MockIPReputationProvider) IPReputation(ctx context.Context, ip string) (r0 IPVerdict, r1 error) {
+	if m.IPReputationFunc == nil {
+		r1 = fmt.Errorf("MockIPReputationProvider.IPReputation not set")
+		return
+	}
+	return m.IPReputationFunc(ctx, ip)
+}
+
+// MockReverseIDProvider is a ReverseIDProvider whose methods are set per test
+type MockReverseIDProvider struct {
+	NameFunc        func() string
+	HealthCheckFunc func(ctx context.Context) error
+	SupportsFunc    func(idType string) bool
+	ReverseIDFunc   func(ctx context.Context, id TrackingID) ([]string, error)
+}
+
+func (m *MockReverseIDProvider) Name() (r0 string) {
+	if m.NameFunc == nil {
+		return "MockReverseIDProvider"
+	}
+	return m.NameFunc()
+}
+
+func (m *MockReverseIDProvider) HealthCheck(ctx context.Context) (r0 error) {
+	if m.HealthCheckFunc == nil {
+		r0 = fmt.Errorf("MockReverseIDProvider.HealthCheck not set")
+		return
+	}
+	return m.HealthCheckFunc(ctx)
+}
+
+func (m *MockReverseIDProvider) Supports(idType string) (r0 bool) {
+	if m.SupportsFunc == nil {
+		return
+	}
+	return m.SupportsFunc(idType)
+}
+
+func (m *MockReverseIDProvider) ReverseID(ctx context.Context, id TrackingID) (r0 []string, r1 error) {
+	if m.ReverseIDFunc == nil {
+		r1 = fmt.Errorf("MockReverseIDProvider.ReverseID not set")
+		return
+	}
+	return m.ReverseIDFunc(ctx, id)
+}
+
+// MockSummaryProvider is a SummaryProvider whose methods are set per test
+type MockSummaryProvider struct {
+	NameFunc        func() string
+	HealthCheckFunc func(ctx context.Context) error
+	CompleteFunc    func(ctx context.Context, system string, prompt string) (string, error)
+}
+
+func (m *MockSummaryProvider) Name() (r0 string) {
+	if m.NameFunc == nil {
+		return "MockSummaryProvider"
+	}
+	return m.NameFunc()
+}
+
+func (m *MockSummaryProvider) HealthCheck(ctx context.Context) (r0 error) {
+	if m.HealthCheckFunc == nil {
+		r0 = fmt.Errorf("MockSummaryProvider.HealthCheck not set")
+		return
+	}
+	return m.HealthCheckFunc(ctx)
+}
+
+func (m *MockSummaryProvider) Complete(ctx context.Context, system string, prompt string) (r0 string, r1 error) {
+	if m.CompleteFunc == nil {
+		r1 = fmt.Errorf("MockSummaryProvider.Complete not set")
+		return
+	}
+	return m.CompleteFunc(ctx, system, prompt)
+}
+
+// MockTranslationProvider is a TranslationProvider whose methods are set per test
+type MockTranslationProvider struct {
+	NameFunc        func() string
+	HealthCheckFunc func(ctx context.Context) error
+	TranslateFunc   func(ctx context.Context, texts []string, target string) ([]TranslatedText, error)
+}
+
+func (m *MockTranslationProvider) Name() (r0 string) {
+	if m.NameFunc == nil {
+		return "MockTranslationProvider"
+	}
+	return m.NameFunc()
+}
+
+func (m *MockTranslationProvider) HealthCheck(ctx context.Context) (r0 error) {
+	if m.HealthCheckFunc == nil {
+		r0 = fmt.Errorf("MockTranslationProvider.HealthCheck not set")
+		return
+	}
+	return m.HealthCheckFunc(ctx)
+}
+
+func (m *MockTranslationProvider) Translate(ctx context.Context, texts []string, target string) (r0 []TranslatedText, r1 error) {
+	if m.TranslateFunc == nil {
+		r1 = fmt.Errorf("MockTranslationProvider.Translate not set")
+		return
+	}
+	return m.TranslateFunc(ctx, texts, target)
+}
+
+// MockURLReputationProvider is a URLReputationProvider whose methods are set per test
+type MockURLReputationProvider struct {
+	NameFunc        func() string
+	HealthCheckFunc func(ctx context.Context) error
+	CheckURLFunc    func(ctx context.Context, target string) (URLVerdict, error)
+}
+
+func (m *MockURLReputationProvider) Name() (r0 string) {
+	if m.NameFunc == nil {
+		return "MockURLReputationProvider"
+	}
+	return m.NameFunc()
+}
+
+func (m *MockURLReputationProvider) HealthCheck(ctx context.Context) (r0 error) {
+	if m.HealthCheckFunc == nil {
+		r0 = fmt.Errorf("MockURLReputationProvider.HealthCheck not set")
+		return
+	}
+	return m.HealthCheckFunc(ctx)
+}
+
+func (m *MockURLReputationProvider) CheckURL(ctx context.Context, target string) (r0 URLVerdict, r1 error) {
+	if m.CheckURLFunc == nil {
+		r1 = fmt.Errorf("MockURLReputationProvider.CheckURL not set")
+		return
+	}
+	return m.CheckURLFunc(ctx, target)
+}
@@ -0,0 +1,1047 @@
+package osint
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+)
+
+//go:generate go run ../../tools/providermocks -in providers.go -out provider-mocks.go
+
+// Provider is an external data source that can report whether it is usable
+type Provider interface {
+	Name() string
+	HealthCheck(ctx context.Context) error
+}
+
+// BreachProvider looks up data breaches for an email address
+type BreachProvider interface {
+	Provider
+	Breaches(ctx context.Context, email string) ([]Breach, error)
+}
+
+// HostProvider looks up internet exposure data for an IP address
+type HostProvider interface {
+	Provider
+	Host(ctx context.Context, ip string) (HostIntel, error)
+}
+
+// ReverseIDProvider finds other domains sharing an analytics or advertising ID
+type ReverseIDProvider interface {
+	Provider
+	Supports(idType string) bool
+	ReverseID(ctx context.Context, id TrackingID) ([]string, error)
+}
+
+// GeoProvider geolocates an IP address
+type GeoProvider interface {
+	Provider
+	Geolocate(ctx context.Context, ip string) (GeoIPInfo, error)
+}
+
+// URLReputationProvider reports whether a URL is known to be malicious
+type URLReputationProvider interface {
+	Provider
+	CheckURL(ctx context.Context, target string) (URLVerdict, error)
+}
+
+// IPReputationProvider classifies an IP address as benign, noisy or malicious
+type IPReputationProvider interface {
+	Provider
+	IPReputation(ctx context.Context, ip string) (IPVerdict, error)
+}
+
+// TranslationProvider translates texts into a target language, detecting
+// the language of each
+type TranslationProvider interface {
+	Provider
+	Translate(ctx context.Context, texts []string, target string) ([]TranslatedText, error)
+}
+
+// SummaryProvider answers a prompt with a language model
+type SummaryProvider interface {
+	Provider
+	Complete(ctx context.Context, system, prompt string) (string, error)
+}
+
+// HostIntel describes the exposed services of a host
+type HostIntel struct {
+	IP        string   `json:"ip"`
+	Ports     []int    `json:"ports,omitempty"`
+	Hostnames []string `json:"hostnames,omitempty"`
+	Org       string   `json:"org,omitempty"`
+	OS        string   `json:"os,omitempty"`
+	Vulns     []string `json:"vulns,omitempty"`
+	Provider  string   `json:"provider"`
+}
+
+// ProviderAttempt records the outcome of trying one provider in a fallback chain
+type ProviderAttempt struct {
+	Provider string `json:"provider"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Fallback chains, tried in order until one provider succeeds
+var (
+	BreachProviders  = []BreachProvider{hibpProvider{}, leakCheckProvider{}}
+	HostProviders    = []HostProvider{shodanProvider{}, censysProvider{}}
+	ReverseProviders = []ReverseIDProvider{hackerTargetProvider{}, spyOnWebProvider{}}
+	GeoProviders     = []GeoProvider{ipAPIProvider{}, ipWhoisProvider{}}
+
+	// URL reputation services are all consulted rather than used as fallbacks
+	URLReputationProviders = []URLReputationProvider{safeBrowsingProvider{}, phishTankProvider{}, urlscanProvider{}}
+	IPReputationProviders  = []IPReputationProvider{greyNoiseProvider{}, abuseIPDBProvider{}}
+
+	TranslationProviders = []TranslationProvider{deepLProvider{}, libreTranslateProvider{}}
+	SummaryProviders     = []SummaryProvider{chatCompletionsProvider{}}
+)
+
+const (
+	healthCacheTTL      = time.Minute // How long a health check result is reused
+	maxHostIntelLookups = 3           // Domain IPs enriched with host exposure data
+)
+
+var (
+	healthMu    sync.Mutex
+	healthCache = map[string]struct {
+		err       error
+		checkedAt time.Time
+	}{}
+)
+
+// checkHealth runs a provider health check, caching the result for healthCacheTTL
+func checkHealth(ctx context.Context, p Provider) error {
+	healthMu.Lock()
+	cached, ok := healthCache[p.Name()]
+	healthMu.Unlock()
+	if ok && time.Since(cached.checkedAt) < healthCacheTTL {
+		return cached.err
+	}
+
+	err := p.HealthCheck(ctx)
+
+	// A cancelled or expired caller context says nothing about the provider
+	if ctx.Err() != nil {
+		return err
+	}
+
+	healthMu.Lock()
+	healthCache[p.Name()] = struct {
+		err       error
+		checkedAt time.Time
+	}{err, time.Now()}
+	healthMu.Unlock()
+
+	return err
+}
+
+// withFallback calls each healthy provider in turn until one succeeds and returns
+// the name of the provider that served the request along with every attempt made
+func withFallback[P Provider](ctx context.Context, providers []P, call func(P) error) (string, []ProviderAttempt, error) {
+	var attempts []ProviderAttempt
+
+	for _, p := range providers {
+		if err := checkHealth(ctx, p); err != nil {
+			attempts = append(attempts, ProviderAttempt{Provider: p.Name(), Error: "unhealthy: " + err.Error()})
+			continue
+		}
+
+		if err := call(p); err != nil {
+			attempts = append(attempts, ProviderAttempt{Provider: p.Name(), Error: err.Error()})
+			continue
+		}
+
+		attempts = append(attempts, ProviderAttempt{Provider: p.Name()})
+		return p.Name(), attempts, nil
+	}
+
+	var errs []string
+	for _, attempt := range attempts {
+		errs = append(errs, fmt.Sprintf("%s: %s", attempt.Provider, attempt.Error))
+	}
+	return "", attempts, fmt.Errorf("all providers failed (%s)", strings.Join(errs, "; "))
+}
+
+// configuredProviders drops the providers missing the credentials they need,
+// so leaving a key unset is not reported as a failed lookup. Providers
+// without a Configured method need none.
+func configuredProviders[P Provider](providers []P) []P {
+	var configured []P
+	for _, p := range providers {
+		if c, ok := any(p).(interface{ Configured() bool }); ok && !c.Configured() {
+			continue
+		}
+		configured = append(configured, p)
+	}
+	return configured
+}
+
+// apiKeyConfigured reports whether a key has been set to something other than a placeholder
+func apiKeyConfigured(key string) bool {
+	return key != "" && !strings.HasPrefix(key, "your-")
+}
+
+// pingURL performs a lightweight request and fails on server errors
+func pingURL(ctx context.Context, target string, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	// Pings go through their own client so they don't spend a service's quota
+	resp, err := providers.For("health checks").Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// hibpProvider queries Have I Been Pwned
+type hibpProvider struct{}
+
+func (hibpProvider) Name() string { return "Have I Been Pwned" }
+
+func (hibpProvider) HealthCheck(ctx context.Context) error {
+	if !apiKeyConfigured(APIConfig.HIBPKey) {
+		return fmt.Errorf("API key not configured")
+	}
+	return pingURL(ctx, "https://haveibeenpwned.com/api/v3/latestbreach", nil)
+}
+
+func (hibpProvider) Breaches(ctx context.Context, email string) ([]Breach, error) {
+	return checkHaveIBeenPwned(ctx, email)
+}
+
+// leakCheckProvider queries the LeakCheck public API
+type leakCheckProvider struct{}
+
+func (leakCheckProvider) Name() string { return "LeakCheck" }
+
+func (leakCheckProvider) HealthCheck(ctx context.Context) error {
+	// The public API is rate limited per lookup, so only check the site is up
+	return pingURL(ctx, "https://leakcheck.io/", nil)
+}
+
+func (leakCheckProvider) Breaches(ctx context.Context, email string) ([]Breach, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET",
+		"https://leakcheck.io/api/public?check="+url.QueryEscape(email), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := doProviderRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("LeakCheck API returned status code %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Success bool     `json:"success"`
+		Found   int      `json:"found"`
+		Fields  []string `json:"fields"`
+		Sources []struct {
+			Name string `json:"name"`
+			Date string `json:"date"`
+		} `json:"sources"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	// "Not found" is reported as an unsuccessful response
+	if !payload.Success {
+		if strings.Contains(strings.ToLower(payload.Error), "not found") {
+			return []Breach{}, nil
+		}
+		return nil, fmt.Errorf("LeakCheck error: %s", payload.Error)
+	}
+
+	breaches := make([]Breach, 0, len(payload.Sources))
+	for _, source := range payload.Sources {
+		breachDate := source.Date
+		if len(breachDate) == 7 { // YYYY-MM
+			breachDate += "-01"
+		}
+		breaches = append(breaches, Breach{
+			Name:        source.Name,
+			BreachDate:  breachDate,
+			DataClasses: payload.Fields,
+		})
+	}
+	return breaches, nil
+}
+
+// shodanProvider queries the Shodan host API
+type shodanProvider struct{}
+
+func (shodanProvider) Name() string { return "Shodan" }
+
+func (shodanProvider) Configured() bool { return apiKeyConfigured(APIConfig.ShodanKey) }
+
+func (p shodanProvider) HealthCheck(ctx context.Context) error {
+	if !p.Configured() {
+		return fmt.Errorf("API key not configured")
+	}
+	return pingURL(ctx, "https://api.shodan.io/api-info?key="+url.QueryEscape(APIConfig.ShodanKey), nil)
+}
+
+func (shodanProvider) Host(ctx context.Context, ip string) (HostIntel, error) {
+	intel := HostIntel{IP: ip, Provider: "Shodan"}
+
+	var payload struct {
+		Ports     []int    `json:"ports"`
+		Hostnames []string `json:"hostnames"`
+		Org       string   `json:"org"`
+		OS        string   `json:"os"`
+		Vulns     []string `json:"vulns"`
+	}
+	target := fmt.Sprintf("https://api.shodan.io/shodan/host/%s?minify=true&key=%s", url.PathEscape(ip), url.QueryEscape(APIConfig.ShodanKey))
+	if err := getProviderJSON(ctx, target, nil, &payload); err != nil {
+		return intel, err
+	}
+
+	intel.Ports = payload.Ports
+	intel.Hostnames = payload.Hostnames
+	intel.Org = payload.Org
+	intel.OS = payload.OS
+	intel.Vulns = payload.Vulns
+	return intel, nil
+}
+
+// censysProvider queries the Censys hosts API
+type censysProvider struct{}
+
+func (censysProvider) Name() string { return "Censys" }
+
+func (censysProvider) Configured() bool {
+	return apiKeyConfigured(APIConfig.CensysID) && apiKeyConfigured(APIConfig.CensysSecret)
+}
+
+func (p censysProvider) HealthCheck(ctx context.Context) error {
+	if !p.Configured() {
+		return fmt.Errorf("API credentials not configured")
+	}
+	return nil
+}
+
+func (censysProvider) Host(ctx context.Context, ip string) (HostIntel, error) {
+	intel := HostIntel{IP: ip, Provider: "Censys"}
+
+	var payload struct {
+		Result struct {
+			Services []struct {
+				Port int `json:"port"`
+			} `json:"services"`
+			DNS struct {
+				Names []string `json:"names"`
+			} `json:"dns"`
+			AutonomousSystem struct {
+				Name string `json:"name"`
+			} `json:"autonomous_system"`
+			OperatingSystem struct {
+				Product string `json:"product"`
+			} `json:"operating_system"`
+		} `json:"result"`
+	}
+	headers := map[string]string{"Authorization": basicAuth(APIConfig.CensysID, APIConfig.CensysSecret)}
+	if err := getProviderJSON(ctx, "https://search.censys.io/api/v2/hosts/"+url.PathEscape(ip), headers, &payload); err != nil {
+		return intel, err
+	}
+
+	for _, service := range payload.Result.Services {
+		intel.Ports = append(intel.Ports, service.Port)
+	}
+	intel.Hostnames = payload.Result.DNS.Names
+	intel.Org = payload.Result.AutonomousSystem.Name
+	intel.OS = payload.Result.OperatingSystem.Product
+	return intel, nil
+}
+
+// getProviderJSON performs a GET request through the shared client of the
+// service it targets and decodes a JSON response
+func getProviderJSON(ctx context.Context, target string, headers map[string]string, out interface{}) error {
+	return providers.ForURL(target).GetJSON(ctx, target, withUserAgent(headers), out)
+}
+
+// postProviderJSON performs a POST request through the shared client of the
+// service it targets and decodes a JSON response
+func postProviderJSON(ctx context.Context, target string, headers map[string]string, body io.Reader, out interface{}) error {
+	return providers.ForURL(target).PostJSON(ctx, target, withUserAgent(headers), body, out)
+}
+
+// doProviderRequest sends an API request through the shared, rate-limited and
+// retrying client of the service it targets
+func doProviderRequest(req *http.Request) (*http.Response, error) {
+	return providers.ForURL(req.URL.String()).Do(req)
+}
+
+// withUserAgent adds the tool's User-Agent to a set of request headers
+func withUserAgent(headers map[string]string) map[string]string {
+	merged := map[string]string{"User-Agent": UserAgent}
+	for k, v := range headers {
+		merged[k] = v
+	}
+	return merged
+}
+
+// basicAuth builds an HTTP basic authorization header value
+func basicAuth(user, pass string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+}
+
+// hackerTargetProvider queries the HackerTarget analytics lookup, which covers
+// Google Analytics and AdSense IDs without an API key
+type hackerTargetProvider struct{}
+
+func (hackerTargetProvider) Name() string { return "HackerTarget" }
+
+func (hackerTargetProvider) HealthCheck(ctx context.Context) error {
+	return pingURL(ctx, "https://hackertarget.com/", nil)
+}
+
+func (hackerTargetProvider) Supports(idType string) bool {
+	return idType == "Google Analytics" || idType == "Google AdSense"
+}
+
+func (hackerTargetProvider) ReverseID(ctx context.Context, id TrackingID) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET",
+		"https://api.hackertarget.com/analyticslookup/?q="+url.QueryEscape(id.ID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := doProviderRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HackerTarget returned status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	// Errors come back as plain text with a 200 status
+	text := strings.TrimSpace(string(body))
+	lower := strings.ToLower(text)
+	if strings.HasPrefix(lower, "error") || strings.Contains(lower, "api count exceeded") {
+		return nil, fmt.Errorf("HackerTarget: %s", text)
+	}
+	if strings.Contains(lower, "no results") || text == "" {
+		return []string{}, nil
+	}
+
+	var domains []string
+	for _, line := range strings.Split(text, "\n") {
+		for _, field := range strings.Split(line, ",") {
+			field = strings.ToLower(strings.TrimSpace(field))
+			if strings.Contains(field, ".") && !strings.HasPrefix(field, "ua-") && !strings.HasPrefix(field, "pub-") {
+				domains = append(domains, field)
+			}
+		}
+	}
+	return domains, nil
+}
+
+// spyOnWebProvider queries the SpyOnWeb API for shared Analytics and AdSense IDs
+type spyOnWebProvider struct{}
+
+func (spyOnWebProvider) Name() string { return "SpyOnWeb" }
+
+func (spyOnWebProvider) HealthCheck(ctx context.Context) error {
+	if !apiKeyConfigured(APIConfig.SpyOnWebToken) {
+		return fmt.Errorf("API token not configured")
+	}
+	return nil
+}
+
+func (spyOnWebProvider) Supports(idType string) bool {
+	return idType == "Google Analytics" || idType == "Google AdSense"
+}
+
+func (spyOnWebProvider) ReverseID(ctx context.Context, id TrackingID) ([]string, error) {
+	// SpyOnWeb keys Analytics IDs by account, without the property suffix
+	endpoint, lookup := "analytics", id.ID
+	if id.Type == "Google AdSense" {
+		endpoint = "adsense"
+	} else if parts := strings.Split(id.ID, "-"); len(parts) == 3 {
+		lookup = parts[0] + "-" + parts[1]
+	}
+
+	var payload struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Result  map[string]map[string]struct {
+			Items map[string]string `json:"items"`
+		} `json:"result"`
+	}
+	target := fmt.Sprintf("https://api.spyonweb.com/v1/%s/%s?access_token=%s",
+		endpoint, url.PathEscape(lookup), url.QueryEscape(APIConfig.SpyOnWebToken))
+	if err := getProviderJSON(ctx, target, nil, &payload); err != nil {
+		return nil, err
+	}
+
+	switch payload.Status {
+	case "found":
+	case "not_found":
+		return []string{}, nil
+	default:
+		return nil, fmt.Errorf("SpyOnWeb error: %s", payload.Message)
+	}
+
+	var domains []string
+	for _, byID := range payload.Result {
+		for _, entry := range byID {
+			for domain := range entry.Items {
+				domains = append(domains, strings.ToLower(domain))
+			}
+		}
+	}
+	sort.Strings(domains)
+	return domains, nil
+}
+
+// ipAPIProvider queries the free ip-api.com endpoint, which is only served over HTTP
+type ipAPIProvider struct{}
+
+func (ipAPIProvider) Name() string { return "ip-api" }
+
+func (ipAPIProvider) HealthCheck(ctx context.Context) error {
+	return pingURL(ctx, "http://ip-api.com/json/?fields=status", nil)
+}
+
+func (ipAPIProvider) Geolocate(ctx context.Context, ip string) (GeoIPInfo, error) {
+	var payload struct {
+		Status     string  `json:"status"`
+		Message    string  `json:"message"`
+		Country    string  `json:"country"`
+		RegionName string  `json:"regionName"`
+		City       string  `json:"city"`
+		Lat        float64 `json:"lat"`
+		Lon        float64 `json:"lon"`
+		ISP        string  `json:"isp"`
+		AS         string  `json:"as"`
+		Timezone   string  `json:"timezone"`
+	}
+	target := "http://ip-api.com/json/" + url.PathEscape(ip) + "?fields=status,message,country,regionName,city,lat,lon,isp,as,timezone"
+	if err := getProviderJSON(ctx, target, nil, &payload); err != nil {
+		return GeoIPInfo{}, err
+	}
+	if payload.Status != "success" {
+		return GeoIPInfo{}, fmt.Errorf("ip-api: %s", payload.Message)
+	}
+
+	return GeoIPInfo{
+		Country:     payload.Country,
+		Region:      payload.RegionName,
+		City:        payload.City,
+		Coordinates: []float64{payload.Lat, payload.Lon},
+		ISP:         payload.ISP,
+		ASN:         payload.AS,
+		TimeZone:    payload.Timezone,
+	}, nil
+}
+
+// ipWhoisProvider queries ipwho.is
+type ipWhoisProvider struct{}
+
+func (ipWhoisProvider) Name() string { return "ipwho.is" }
+
+func (ipWhoisProvider) HealthCheck(ctx context.Context) error {
+	return pingURL(ctx, "https://ipwho.is/", nil)
+}
+
+func (ipWhoisProvider) Geolocate(ctx context.Context, ip string) (GeoIPInfo, error) {
+	var payload struct {
+		Success    bool    `json:"success"`
+		Message    string  `json:"message"`
+		Country    string  `json:"country"`
+		Region     string  `json:"region"`
+		City       string  `json:"city"`
+		Latitude   float64 `json:"latitude"`
+		Longitude  float64 `json:"longitude"`
+		Connection struct {
+			ASN int    `json:"asn"`
+			Org string `json:"org"`
+			ISP string `json:"isp"`
+		} `json:"connection"`
+		Timezone struct {
+			ID string `json:"id"`
+		} `json:"timezone"`
+	}
+	if err := getProviderJSON(ctx, "https://ipwho.is/"+url.PathEscape(ip), nil, &payload); err != nil {
+		return GeoIPInfo{}, err
+	}
+	if !payload.Success {
+		return GeoIPInfo{}, fmt.Errorf("ipwho.is: %s", payload.Message)
+	}
+
+	info := GeoIPInfo{
+		Country:     payload.Country,
+		Region:      payload.Region,
+		City:        payload.City,
+		Coordinates: []float64{payload.Latitude, payload.Longitude},
+		ISP:         payload.Connection.ISP,
+		TimeZone:    payload.Timezone.ID,
+	}
+	if payload.Connection.ASN != 0 {
+		info.ASN = fmt.Sprintf("AS%d %s", payload.Connection.ASN, payload.Connection.Org)
+	}
+	return info, nil
+}
+
+// safeBrowsingProvider queries the Google Safe Browsing lookup API
+type safeBrowsingProvider struct{}
+
+func (safeBrowsingProvider) Name() string { return "Google Safe Browsing" }
+
+func (safeBrowsingProvider) HealthCheck(ctx context.Context) error {
+	if !apiKeyConfigured(APIConfig.SafeBrowsingKey) {
+		return fmt.Errorf("API key not configured")
+	}
+	return nil
+}
+
+func (safeBrowsingProvider) CheckURL(ctx context.Context, target string) (URLVerdict, error) {
+	verdict := URLVerdict{Provider: "Google Safe Browsing", URL: target}
+
+	request := map[string]interface{}{
+		"client": map[string]string{"clientId": "mercuriesost", "clientVersion": "2.0"},
+		"threatInfo": map[string]interface{}{
+			"threatTypes":      []string{"MALWARE", "SOCIAL_ENGINEERING", "UNWANTED_SOFTWARE", "POTENTIALLY_HARMFUL_APPLICATION"},
+			"platformTypes":    []string{"ANY_PLATFORM"},
+			"threatEntryTypes": []string{"URL"},
+			"threatEntries":    []map[string]string{{"url": target}},
+		},
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return verdict, err
+	}
+
+	var payload struct {
+		Matches []struct {
+			ThreatType string `json:"threatType"`
+		} `json:"matches"`
+	}
+	endpoint := "https://safebrowsing.googleapis.com/v4/threatMatches:find?key=" + url.QueryEscape(APIConfig.SafeBrowsingKey)
+	headers := map[string]string{"Content-Type": "application/json"}
+	if err := postProviderJSON(ctx, endpoint, headers, strings.NewReader(string(body)), &payload); err != nil {
+		return verdict, err
+	}
+
+	var threats []string
+	for _, match := range payload.Matches {
+		threats = append(threats, strings.ToLower(strings.ReplaceAll(match.ThreatType, "_", " ")))
+	}
+	verdict.Malicious = len(threats) > 0
+	verdict.Category = strings.Join(threats, ", ")
+	return verdict, nil
+}
+
+// phishTankProvider queries the PhishTank URL check API; an app key is optional
+// but raises the rate limit
+type phishTankProvider struct{}
+
+func (phishTankProvider) Name() string { return "PhishTank" }
+
+func (phishTankProvider) HealthCheck(ctx context.Context) error {
+	return pingURL(ctx, "https://checkurl.phishtank.com/", nil)
+}
+
+func (phishTankProvider) CheckURL(ctx context.Context, target string) (URLVerdict, error) {
+	verdict := URLVerdict{Provider: "PhishTank", URL: target}
+
+	form := url.Values{"url": {target}, "format": {"json"}}
+	if apiKeyConfigured(APIConfig.PhishTankKey) {
+		form.Set("app_key", APIConfig.PhishTankKey)
+	}
+
+	var payload struct {
+		Results struct {
+			InDatabase bool   `json:"in_database"`
+			Verified   bool   `json:"verified"`
+			Valid      bool   `json:"valid"`
+			DetailPage string `json:"phish_detail_page"`
+		} `json:"results"`
+	}
+	// PhishTank rejects requests without a descriptive agent
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+		"User-Agent":   "phishtank/" + UserAgent,
+	}
+	if err := postProviderJSON(ctx, "https://checkurl.phishtank.com/checkurl/", headers, strings.NewReader(form.Encode()), &payload); err != nil {
+		return verdict, err
+	}
+
+	switch {
+	case !payload.Results.InDatabase:
+		verdict.Details = "not in database"
+	case payload.Results.Verified && payload.Results.Valid:
+		verdict.Malicious = true
+		verdict.Category = "verified phish"
+	case payload.Results.Verified:
+		verdict.Details = "reported, verified not a phish"
+	default:
+		verdict.Category = "reported, unverified"
+	}
+	verdict.Link = payload.Results.DetailPage
+	return verdict, nil
+}
+
+// urlscanProvider looks up earlier public scans of a URL on urlscan.io and the
+// verdict and screenshot of the most recent one
+type urlscanProvider struct{}
+
+func (urlscanProvider) Name() string { return "urlscan.io" }
+
+func (urlscanProvider) HealthCheck(ctx context.Context) error {
+	return pingURL(ctx, "https://urlscan.io/", nil)
+}
+
+func (urlscanProvider) CheckURL(ctx context.Context, target string) (URLVerdict, error) {
+	verdict := URLVerdict{Provider: "urlscan.io", URL: target}
+
+	var headers map[string]string
+	if apiKeyConfigured(APIConfig.URLScanKey) {
+		headers = map[string]string{"API-Key": APIConfig.URLScanKey}
+	}
+
+	var search struct {
+		Total   int `json:"total"`
+		Results []struct {
+			ID         string `json:"_id"`
+			Screenshot string `json:"screenshot"`
+			Task       struct {
+				Time string `json:"time"`
+			} `json:"task"`
+		} `json:"results"`
+	}
+	query := `task.url:"` + strings.ReplaceAll(target, `"`, `\"`) + `"`
+	if err := getProviderJSON(ctx, "https://urlscan.io/api/v1/search/?size=1&q="+url.QueryEscape(query), headers, &search); err != nil {
+		return verdict, err
+	}
+	if len(search.Results) == 0 {
+		verdict.Details = "no previous scans"
+		return verdict, nil
+	}
+
+	latest := search.Results[0]
+	verdict.Link = "https://urlscan.io/result/" + latest.ID + "/"
+	verdict.Screenshot = latest.Screenshot
+	verdict.Details = fmt.Sprintf("%d previous scans, latest %s", search.Total, latest.Task.Time)
+
+	var scan struct {
+		Verdicts struct {
+			Overall struct {
+				Malicious  bool     `json:"malicious"`
+				Score      int      `json:"score"`
+				Categories []string `json:"categories"`
+				Brands     []string `json:"brands"`
+			} `json:"overall"`
+		} `json:"verdicts"`
+	}
+	if err := getProviderJSON(ctx, "https://urlscan.io/api/v1/result/"+url.PathEscape(latest.ID)+"/", headers, &scan); err != nil {
+		return verdict, nil
+	}
+	overall := scan.Verdicts.Overall
+	verdict.Malicious = overall.Malicious
+	verdict.Category = strings.Join(append(overall.Categories, overall.Brands...), ", ")
+	return verdict, nil
+}
+
+// greyNoiseProvider queries the GreyNoise community API, which tells internet-wide
+// scanners (noise) and known business services (RIOT) apart from everything else
+type greyNoiseProvider struct{}
+
+func (greyNoiseProvider) Name() string { return "GreyNoise" }
+
+func (greyNoiseProvider) HealthCheck(ctx context.Context) error {
+	return pingURL(ctx, "https://api.greynoise.io/ping", nil)
+}
+
+func (greyNoiseProvider) IPReputation(ctx context.Context, ip string) (IPVerdict, error) {
+	verdict := IPVerdict{Provider: "GreyNoise", IP: ip, Classification: "unknown"}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.greynoise.io/v3/community/"+url.PathEscape(ip), nil)
+	if err != nil {
+		return verdict, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Accept", "application/json")
+	if apiKeyConfigured(APIConfig.GreyNoiseKey) {
+		req.Header.Set("key", APIConfig.GreyNoiseKey)
+	}
+
+	resp, err := doProviderRequest(req)
+	if err != nil {
+		return verdict, err
+	}
+	defer resp.Body.Close()
+
+	var payload struct {
+		Noise          bool   `json:"noise"`
+		RIOT           bool   `json:"riot"`
+		Classification string `json:"classification"`
+		Name           string `json:"name"`
+		Link           string `json:"link"`
+		LastSeen       string `json:"last_seen"`
+		Message        string `json:"message"`
+	}
+	// Addresses GreyNoise has never seen come back as 404 with a message
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return verdict, fmt.Errorf("API returned status code %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return verdict, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		verdict.Details = payload.Message
+		return verdict, nil
+	}
+
+	switch {
+	case payload.RIOT:
+		verdict.Classification = "benign service"
+	case payload.Noise && payload.Classification != "":
+		verdict.Classification = payload.Classification + " scanner"
+	case payload.Classification != "":
+		verdict.Classification = payload.Classification
+	}
+	verdict.Malicious = payload.Classification == "malicious"
+	verdict.Details = payload.Name
+	if payload.LastSeen != "" {
+		verdict.Details = strings.TrimSpace(verdict.Details + ", last seen " + payload.LastSeen)
+	}
+	verdict.Link = payload.Link
+	return verdict, nil
+}
+
+// abuseIPDBProvider queries AbuseIPDB for community abuse reports
+type abuseIPDBProvider struct{}
+
+func (abuseIPDBProvider) Name() string { return "AbuseIPDB" }
+
+func (abuseIPDBProvider) HealthCheck(ctx context.Context) error {
+	if !apiKeyConfigured(APIConfig.AbuseIPDBKey) {
+		return fmt.Errorf("API key not configured")
+	}
+	return nil
+}
+
+func (abuseIPDBProvider) IPReputation(ctx context.Context, ip string) (IPVerdict, error) {
+	verdict := IPVerdict{
+		Provider: "AbuseIPDB",
+		IP:       ip,
+		Link:     "https://www.abuseipdb.com/check/" + url.PathEscape(ip),
+	}
+
+	var payload struct {
+		Data struct {
+			AbuseConfidenceScore int    `json:"abuseConfidenceScore"`
+			TotalReports         int    `json:"totalReports"`
+			UsageType            string `json:"usageType"`
+			ISP                  string `json:"isp"`
+			IsWhitelisted        bool   `json:"isWhitelisted"`
+			LastReportedAt       string `json:"lastReportedAt"`
+		} `json:"data"`
+	}
+	target := "https://api.abuseipdb.com/api/v2/check?maxAgeInDays=90&ipAddress=" + url.QueryEscape(ip)
+	if err := getProviderJSON(ctx, target, map[string]string{"Key": APIConfig.AbuseIPDBKey}, &payload); err != nil {
+		return verdict, err
+	}
+
+	data := payload.Data
+	score := data.AbuseConfidenceScore
+	verdict.Score = &score
+	switch {
+	case data.IsWhitelisted:
+		verdict.Classification = "benign service"
+	case score >= 75:
+		verdict.Classification = "malicious"
+		verdict.Malicious = true
+	case score > 0:
+		verdict.Classification = "suspicious"
+	default:
+		verdict.Classification = "unknown"
+	}
+	verdict.Details = fmt.Sprintf("%d reports in 90 days", data.TotalReports)
+	if data.UsageType != "" {
+		verdict.Details += ", " + data.UsageType
+	}
+	if data.ISP != "" {
+		verdict.Details += ", " + data.ISP
+	}
+	return verdict, nil
+}
+
+// deepLProvider translates with the DeepL API. Free plan keys end in :fx and
+// are served from their own host.
+type deepLProvider struct{}
+
+func (deepLProvider) Name() string { return "DeepL" }
+
+func (deepLProvider) HealthCheck(ctx context.Context) error {
+	if !apiKeyConfigured(APIConfig.DeepLKey) {
+		return fmt.Errorf("API key not configured")
+	}
+	return nil
+}
+
+func (deepLProvider) Translate(ctx context.Context, texts []string, target string) ([]TranslatedText, error) {
+	endpoint := "https://api.deepl.com/v2/translate"
+	if strings.HasSuffix(APIConfig.DeepLKey, ":fx") {
+		endpoint = "https://api-free.deepl.com/v2/translate"
+	}
+	body, err := json.Marshal(map[string]interface{}{"text": texts, "target_lang": strings.ToUpper(target)})
+	if err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		Translations []struct {
+			DetectedSourceLanguage string `json:"detected_source_language"`
+			Text                   string `json:"text"`
+		} `json:"translations"`
+	}
+	headers := map[string]string{
+		"Authorization": "DeepL-Auth-Key " + APIConfig.DeepLKey,
+		"Content-Type":  "application/json",
+	}
+	if err := postProviderJSON(ctx, endpoint, headers, strings.NewReader(string(body)), &payload); err != nil {
+		return nil, err
+	}
+	if len(payload.Translations) != len(texts) {
+		return nil, fmt.Errorf("got %d translations for %d texts", len(payload.Translations), len(texts))
+	}
+
+	translated := make([]TranslatedText, len(texts))
+	for i, t := range payload.Translations {
+		translated[i] = TranslatedText{Text: t.Text, Language: strings.ToLower(t.DetectedSourceLanguage)}
+	}
+	return translated, nil
+}
+
+// libreTranslateProvider translates with a self-hosted LibreTranslate
+// instance at LibreTranslateURL; an API key is only needed if the instance
+// requires one
+type libreTranslateProvider struct{}
+
+func (libreTranslateProvider) Name() string { return "LibreTranslate" }
+
+func (libreTranslateProvider) HealthCheck(ctx context.Context) error {
+	if LibreTranslateURL == "" {
+		return fmt.Errorf("no instance configured")
+	}
+	return pingURL(ctx, strings.TrimSuffix(LibreTranslateURL, "/")+"/languages", nil)
+}
+
+func (libreTranslateProvider) Translate(ctx context.Context, texts []string, target string) ([]TranslatedText, error) {
+	endpoint := strings.TrimSuffix(LibreTranslateURL, "/") + "/translate"
+	headers := map[string]string{"Content-Type": "application/json"}
+
+	translated := make([]TranslatedText, len(texts))
+	for i, text := range texts {
+		request := map[string]string{"q": text, "source": "auto", "target": strings.ToLower(target), "format": "text"}
+		if apiKeyConfigured(APIConfig.LibreTranslateKey) {
+			request["api_key"] = APIConfig.LibreTranslateKey
+		}
+		body, err := json.Marshal(request)
+		if err != nil {
+			return nil, err
+		}
+
+		var payload struct {
+			TranslatedText   string `json:"translatedText"`
+			DetectedLanguage struct {
+				Language string `json:"language"`
+			} `json:"detectedLanguage"`
+		}
+		if err := postProviderJSON(ctx, endpoint, headers, strings.NewReader(string(body)), &payload); err != nil {
+			return nil, err
+		}
+		translated[i] = TranslatedText{Text: payload.TranslatedText, Language: strings.ToLower(payload.DetectedLanguage.Language)}
+	}
+	return translated, nil
+}
+
+// chatCompletionsProvider asks the OpenAI-compatible chat completions API at
+// SummaryURL, which Ollama, llama.cpp, vLLM and OpenAI itself all serve. The
+// API key is only sent when one is configured.
+type chatCompletionsProvider struct{}
+
+func (chatCompletionsProvider) Name() string { return "LLM (" + SummaryModel + ")" }
+
+func (chatCompletionsProvider) HealthCheck(ctx context.Context) error {
+	if SummaryURL == "" || SummaryModel == "" {
+		return fmt.Errorf("no endpoint or model configured")
+	}
+	return nil
+}
+
+func (chatCompletionsProvider) Complete(ctx context.Context, system, prompt string) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": SummaryModel,
+		"messages": []map[string]string{
+			{"role": "system", "content": system},
+			{"role": "user", "content": prompt},
+		},
+		"temperature":     0.2,
+		"response_format": map[string]string{"type": "json_object"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	headers := map[string]string{"Content-Type": "application/json", "User-Agent": UserAgent}
+	if apiKeyConfigured(APIConfig.LLMKey) {
+		headers["Authorization"] = "Bearer " + APIConfig.LLMKey
+	}
+	var payload struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	endpoint := strings.TrimSuffix(SummaryURL, "/") + "/chat/completions"
+	// Local models can take minutes to answer, far longer than API calls
+	client := &providers.Client{Name: "LLM", Timeout: SummaryTimeout}
+	if err := client.PostJSON(ctx, endpoint, headers, strings.NewReader(string(body)), &payload); err != nil {
+		return "", err
+	}
+	if len(payload.Choices) == 0 || strings.TrimSpace(payload.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("empty answer")
+	}
+	return payload.Choices[0].Message.Content, nil
+}
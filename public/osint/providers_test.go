@@ -0,0 +1,118 @@
+package osint
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/awion/MercuriesOST/public/providers"
+)
+
+// useFixtures serves every provider request from a fixture file for the
+// rest of the test, without rate limits, and returns the mock to inspect
+func useFixtures(t *testing.T, path string) *providers.Mock {
+	t.Helper()
+	fixtures, err := providers.LoadFixtures(path)
+	if err != nil {
+		t.Fatalf("load fixtures: %v", err)
+	}
+	mock := &providers.Mock{Fixtures: fixtures}
+	restore := providers.Use(mock)
+	limits := providers.RateLimits
+	providers.RateLimits = false
+	t.Cleanup(func() {
+		restore()
+		providers.RateLimits = limits
+	})
+	return mock
+}
+
+func TestWithFallbackSkipsUnhealthyProviders(t *testing.T) {
+	down := &MockBreachProvider{
+		NameFunc:        func() string { return "fallback-test-down" },
+		HealthCheckFunc: func(ctx context.Context) error { return errors.New("no key") },
+	}
+	failing := &MockBreachProvider{
+		NameFunc:        func() string { return "fallback-test-failing" },
+		HealthCheckFunc: func(ctx context.Context) error { return nil },
+		BreachesFunc: func(ctx context.Context, email string) ([]Breach, error) {
+			return nil, errors.New("status 500")
+		},
+	}
+	working := &MockBreachProvider{
+		NameFunc:        func() string { return "fallback-test-working" },
+		HealthCheckFunc: func(ctx context.Context) error { return nil },
+		BreachesFunc: func(ctx context.Context, email string) ([]Breach, error) {
+			return []Breach{{Name: "Adobe"}}, nil
+		},
+	}
+
+	var breaches []Breach
+	served, attempts, err := withFallback(context.Background(), []BreachProvider{down, failing, working}, func(p BreachProvider) error {
+		var err error
+		breaches, err = p.Breaches(context.Background(), "jane@example.com")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("withFallback: %v", err)
+	}
+	if served != "fallback-test-working" || len(breaches) != 1 {
+		t.Fatalf("served by %q with %v, want fallback-test-working with one breach", served, breaches)
+	}
+	if len(attempts) != 3 || attempts[0].Error != "unhealthy: no key" || attempts[1].Error != "status 500" || attempts[2].Error != "" {
+		t.Fatalf("attempts = %+v", attempts)
+	}
+}
+
+func TestWithFallbackFailsWhenEveryProviderFails(t *testing.T) {
+	// Unset methods of a generated mock fail, so only the name is stubbed
+	unset := &MockBreachProvider{NameFunc: func() string { return "fallback-test-unset" }}
+
+	_, attempts, err := withFallback(context.Background(), []BreachProvider{unset}, func(p BreachProvider) error {
+		_, err := p.Breaches(context.Background(), "jane@example.com")
+		return err
+	})
+	if err == nil || len(attempts) != 1 {
+		t.Fatalf("got %v with attempts %+v, want one failed attempt", err, attempts)
+	}
+}
+
+func TestConfiguredProvidersDropsUnkeyedProviders(t *testing.T) {
+	saved := APIConfig
+	t.Cleanup(func() { APIConfig = saved })
+	APIConfig.ShodanKey, APIConfig.CensysID, APIConfig.CensysSecret = "", "", ""
+
+	// Mocks have no Configured method, so they need no credentials
+	mock := &MockHostProvider{NameFunc: func() string { return "configured-test-mock" }}
+	got := configuredProviders([]HostProvider{shodanProvider{}, censysProvider{}, mock})
+	if len(got) != 1 || got[0] != HostProvider(mock) {
+		t.Fatalf("configuredProviders = %v, want only the mock", got)
+	}
+
+	APIConfig.ShodanKey = "shodan-test-key"
+	got = configuredProviders([]HostProvider{shodanProvider{}, censysProvider{}})
+	if len(got) != 1 || got[0].Name() != "Shodan" {
+		t.Fatalf("configuredProviders = %v, want Shodan", got)
+	}
+}
+
+func TestHIBPBreachesFromFixtures(t *testing.T) {
+	mock := useFixtures(t, "testdata/provider-fixtures.json")
+
+	breaches, err := hibpProvider{}.Breaches(context.Background(), "jane@example.com")
+	if err != nil {
+		t.Fatalf("Breaches: %v", err)
+	}
+	if len(breaches) != 2 || breaches[0].Name != "Adobe" || breaches[1].BreachDate != "2019-05-24" {
+		t.Fatalf("breaches = %+v", breaches)
+	}
+
+	// A 404 means the address is in no breach rather than an error
+	breaches, err = hibpProvider{}.Breaches(context.Background(), "nobody@example.com")
+	if err != nil || len(breaches) != 0 {
+		t.Fatalf("got %+v, %v for an unbreached address", breaches, err)
+	}
+	if requests := mock.Requests(); len(requests) != 2 {
+		t.Fatalf("sent %v, want two requests", requests)
+	}
+}
@@ -0,0 +1,210 @@
+package osint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxProfileReviews  = 20 // Reviews kept per profile
+	maxGeocodedReviews = 5  // Reviews geocoded per profile, Nominatim allowing one a second
+)
+
+// embeddedJSONRegex captures the JSON-LD and JSON data blocks pages are
+// rendered from
+var embeddedJSONRegex = regexp.MustCompile(`(?s)<script[^>]+type="application/(?:ld\+)?json"[^>]*>(.*?)</script>`)
+
+// embeddedReviews reads the reviews a profile page carries in its
+// schema.org markup or page data. Review sites lay these out differently,
+// so any object naming a place along with a rating or date counts.
+func embeddedReviews(page string) []ReviewInfo {
+	var reviews []ReviewInfo
+	seen := make(map[string]bool)
+	for _, m := range embeddedJSONRegex.FindAllStringSubmatch(page, -1) {
+		var data interface{}
+		if json.Unmarshal([]byte(html.UnescapeString(m[1])), &data) != nil {
+			continue
+		}
+		collectReviews(data, &reviews, seen)
+		if len(reviews) >= maxProfileReviews {
+			break
+		}
+	}
+	// Newest first; ISO dates sort as text
+	sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].ReviewDate > reviews[j].ReviewDate })
+	return reviews
+}
+
+// collectReviews walks decoded JSON for review-shaped objects
+func collectReviews(node interface{}, reviews *[]ReviewInfo, seen map[string]bool) {
+	if len(*reviews) >= maxProfileReviews {
+		return
+	}
+	switch v := node.(type) {
+	case []interface{}:
+		for _, item := range v {
+			collectReviews(item, reviews, seen)
+		}
+	case map[string]interface{}:
+		if review, ok := reviewFromObject(v); ok {
+			key := review.Location + "|" + review.ReviewDate
+			if !seen[key] {
+				seen[key] = true
+				*reviews = append(*reviews, review)
+			}
+			return
+		}
+		for _, value := range v {
+			collectReviews(value, reviews, seen)
+		}
+	}
+}
+
+// reviewFromObject reads a review from schema.org Review fields
+// (itemReviewed, reviewRating, datePublished) or the plainer names review
+// sites use in their page data (location, rating, publishedDate)
+func reviewFromObject(object map[string]interface{}) (ReviewInfo, bool) {
+	place := firstObject(object, "itemReviewed", "location", "listing", "place")
+	name := firstString(place, "name", "title")
+	if name == "" {
+		name = firstString(object, "locationName", "placeName")
+	}
+	rating := firstNumber(firstObject(object, "reviewRating"), "ratingValue")
+	if rating == 0 {
+		rating = firstNumber(object, "rating", "ratingValue", "bubbleRating")
+	}
+	date := firstString(object, "datePublished", "publishedDate", "createdAt", "localizedDate")
+	if name == "" || (rating == 0 && date == "") {
+		return ReviewInfo{}, false
+	}
+
+	address := firstObject(place, "address")
+	locality := firstString(address, "addressLocality")
+	if locality == "" {
+		locality = firstString(place, "city", "localizedCityName", "localizedLocation")
+	}
+	country := firstString(address, "addressCountry")
+	review := ReviewInfo{
+		Location:   strings.Join(nonEmpty(name, locality, country), ", "),
+		Rating:     int(rating),
+		ReviewText: cleanText(firstString(object, "reviewBody", "text", "comments")),
+		ReviewDate: date,
+	}
+	geo := firstObject(place, "geo")
+	if geo == nil {
+		geo = place
+	}
+	lat, lon := firstNumber(geo, "latitude", "lat"), firstNumber(geo, "longitude", "lng", "lon")
+	if lat != 0 || lon != 0 {
+		review.Coordinates = []float64{lat, lon}
+	}
+	return review, true
+}
+
+// geocodeReviews places reviews whose pages gave no coordinates
+func geocodeReviews(ctx context.Context, reviews []ReviewInfo) {
+	geocoded := 0
+	for i := range reviews {
+		if len(reviews[i].Coordinates) == 2 || geocoded >= maxGeocodedReviews {
+			continue
+		}
+		geocoded++
+		if place, err := Geocode(ctx, reviews[i].Location); err == nil {
+			reviews[i].Coordinates = []float64{place.Lat, place.Lon}
+		}
+	}
+}
+
+// travelInsight summarises where and over what time a profile's reviews
+// were written, like "Reviewed 9 places in 4 areas between 2019 and 2024"
+func travelInsight(reviews []ReviewInfo) string {
+	if len(reviews) == 0 {
+		return ""
+	}
+	areas := make(map[string]bool)
+	var years []string
+	for _, review := range reviews {
+		if parts := strings.SplitN(review.Location, ", ", 2); len(parts) == 2 {
+			areas[parts[1]] = true
+		}
+		if len(review.ReviewDate) >= 4 {
+			if _, err := strconv.Atoi(review.ReviewDate[:4]); err == nil {
+				years = append(years, review.ReviewDate[:4])
+			}
+		}
+	}
+	insight := fmt.Sprintf("Reviewed %d places", len(reviews))
+	if len(areas) > 0 {
+		insight += fmt.Sprintf(" in %d areas", len(areas))
+	}
+	if len(years) > 0 {
+		sort.Strings(years)
+		if years[0] == years[len(years)-1] {
+			insight += " in " + years[0]
+		} else {
+			insight += fmt.Sprintf(" between %s and %s", years[0], years[len(years)-1])
+		}
+	}
+	return insight
+}
+
+// firstObject returns the first of keys holding an object. schema.org
+// values may also be lists of objects, whose first entry is taken.
+func firstObject(object map[string]interface{}, keys ...string) map[string]interface{} {
+	for _, key := range keys {
+		switch v := object[key].(type) {
+		case map[string]interface{}:
+			return v
+		case []interface{}:
+			if len(v) > 0 {
+				if first, ok := v[0].(map[string]interface{}); ok {
+					return first
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// firstString returns the first of keys holding a non-empty string
+func firstString(object map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if s, ok := object[key].(string); ok && strings.TrimSpace(s) != "" {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
+
+// firstNumber returns the first of keys holding a number, or a string
+// holding one
+func firstNumber(object map[string]interface{}, keys ...string) float64 {
+	for _, key := range keys {
+		switch v := object[key].(type) {
+		case float64:
+			return v
+		case string:
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// nonEmpty drops empty strings and repeats of the one before
+func nonEmpty(values ...string) []string {
+	var kept []string
+	for _, value := range values {
+		if value != "" && (len(kept) == 0 || kept[len(kept)-1] != value) {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
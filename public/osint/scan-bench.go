@@ -0,0 +1,192 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/variations"
+	"github.com/fatih/color"
+)
+
+// BenchOptions sizes a scanning engine benchmark
+type BenchOptions struct {
+	Query     string        `json:"query"`      // Name whose variations are scanned
+	Platforms int           `json:"platforms"`  // Synthetic platforms served by the mock server
+	Latency   time.Duration `json:"latency_ns"` // Delay before the mock server answers
+	HitRate   float64       `json:"hit_rate"`   // Share of profiles that exist, 0 to 1
+}
+
+// BenchResult reports how the scanning engine behaved
+type BenchResult struct {
+	Options           BenchOptions `json:"options"`
+	Terms             int          `json:"terms"`
+	Requests          int64        `json:"requests"`
+	ProfilesFound     int          `json:"profiles_found"`
+	Elapsed           string       `json:"elapsed"`
+	RequestsPerSecond float64      `json:"requests_per_second"`
+	ProfilesPerSecond float64      `json:"profiles_per_second"`
+	TotalAllocMB      float64      `json:"total_alloc_mb"`
+	PeakHeapMB        float64      `json:"peak_heap_mb"`
+	PeakGoroutines    int          `json:"peak_goroutines"`
+	NumGC             uint32       `json:"num_gc"`
+	GCPauseTotal      string       `json:"gc_pause_total"`
+	MaxGCPause        string       `json:"max_gc_pause"`
+}
+
+// benchProfilePage is served for profiles that exist; it passes validation
+// and gives the extractors something to parse
+const benchProfilePage = `<html><head><title>Bench profile</title></head><body>
+<h1 class="name">Bench User</h1><p class="bio">Posts, Followers and a Bio</p>
+<span class="followers">1,234 followers</span><span class="location">Lisbon</span>
+<article class="post"><time datetime="2024-01-02T10:00:00Z"></time>Just a post</article>
+</body></html>`
+
+// BenchmarkScan runs the social media scanning engine against a local mock
+// server, so changes to the worker pool, caches or limiters can be measured
+// without touching real platforms. The engine's dump files are written to a
+// temporary directory, and the scan-wide options are disabled for the run.
+func BenchmarkScan(opts BenchOptions) (*BenchResult, error) {
+	if opts.Platforms < 1 || opts.HitRate < 0 || opts.HitRate > 1 {
+		return nil, fmt.Errorf("need at least one platform and a hit rate between 0 and 1")
+	}
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(opts.Latency)
+		h := fnv.New32a()
+		h.Write([]byte(r.URL.Path))
+		if float64(h.Sum32()%1000) >= opts.HitRate*1000 {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, benchProfilePage)
+	}))
+	defer server.Close()
+
+	// Swap in synthetic platforms and switch off everything that would
+	// reach beyond the mock server
+	savedPlatforms, savedExpand, savedGeocode, savedMin := platforms, ExpandHandles, GeocodeLocations, MinConfidence
+	savedPhone, savedIP, savedHistory, savedGraph := TimeZoneHintPhone, TimeZoneHintIP, CheckAccountHistory, SampleSocialGraph
+	savedEnabled, savedTranslate, savedHooks := EnabledPlatforms, TranslateTo, HooksDir
+	defer func() {
+		platforms, ExpandHandles, GeocodeLocations, MinConfidence = savedPlatforms, savedExpand, savedGeocode, savedMin
+		TimeZoneHintPhone, TimeZoneHintIP, CheckAccountHistory, SampleSocialGraph = savedPhone, savedIP, savedHistory, savedGraph
+		EnabledPlatforms, TranslateTo, HooksDir = savedEnabled, savedTranslate, savedHooks
+	}()
+	ExpandHandles, GeocodeLocations, MinConfidence, TimeZoneHintPhone, TimeZoneHintIP = false, false, 0, "", ""
+	CheckAccountHistory, SampleSocialGraph, EnabledPlatforms, TranslateTo, HooksDir = false, false, nil, "", ""
+	platforms = nil
+	for i := 0; i < opts.Platforms; i++ {
+		platforms = append(platforms, SocialPlatform{
+			Name:              fmt.Sprintf("Bench%d", i),
+			URL:               fmt.Sprintf("%s/p%d/", server.URL, i),
+			ProfilePattern:    "%s",
+			NameSelector:      "h1.name",
+			BioSelector:       "p.bio",
+			FollowersSelector: "span.followers",
+			LocationSelector:  "span.location",
+			ActivitySelector:  "article.post",
+		})
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	tempDir, err := os.MkdirTemp("", "mercuries-bench")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+	if err := os.Mkdir(tempDir+"/dump", 0755); err != nil {
+		return nil, err
+	}
+	if err := os.Chdir(tempDir); err != nil {
+		return nil, err
+	}
+	defer os.Chdir(workDir)
+
+	// Sample the heap and goroutines while the scan runs
+	var peakHeap uint64
+	var peakGoroutines int
+	stop := make(chan struct{})
+	var sampler sync.WaitGroup
+	sampler.Add(1)
+	go func() {
+		defer sampler.Done()
+		ticker := time.NewTicker(50 * time.Millisecond)
+		defer ticker.Stop()
+		var stats runtime.MemStats
+		for {
+			runtime.ReadMemStats(&stats)
+			peakHeap = max(peakHeap, stats.HeapInuse)
+			peakGoroutines = max(peakGoroutines, runtime.NumGoroutine())
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	results, err := SearchProfilesSequentially(context.Background(), opts.Query, "", false)
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	close(stop)
+	sampler.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BenchResult{
+		Options:        opts,
+		Terms:          len(variations.GetNameVariations(opts.Query)),
+		Requests:       requests.Load(),
+		ProfilesFound:  results.ProfilesFound + len(results.Leads), // Every tier, since the bench measures throughput
+		Elapsed:        elapsed.Round(time.Millisecond).String(),
+		TotalAllocMB:   float64(after.TotalAlloc-before.TotalAlloc) / (1 << 20),
+		PeakHeapMB:     float64(peakHeap) / (1 << 20),
+		PeakGoroutines: peakGoroutines,
+		NumGC:          after.NumGC - before.NumGC,
+		GCPauseTotal:   time.Duration(after.PauseTotalNs - before.PauseTotalNs).String(),
+	}
+	// PauseNs is a ring of the last 256 pauses
+	var maxPause time.Duration
+	for i := max(before.NumGC, after.NumGC-min(after.NumGC, 256)); i < after.NumGC; i++ {
+		maxPause = max(maxPause, time.Duration(after.PauseNs[i%256]))
+	}
+	result.MaxGCPause = maxPause.String()
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		result.RequestsPerSecond = float64(result.Requests) / seconds
+		result.ProfilesPerSecond = float64(result.ProfilesFound) / seconds
+	}
+	return result, nil
+}
+
+// DisplayResults prints the benchmark report
+func (r *BenchResult) DisplayResults() {
+	color.Cyan("\n=== SCAN BENCHMARK ===")
+	color.Yellow("%d platforms x %d terms, %s latency, %.0f%% hit rate", r.Options.Platforms, r.Terms, r.Options.Latency, r.Options.HitRate*100)
+	color.White("• Elapsed: %s", r.Elapsed)
+	color.White("• Requests: %d (%.1f/s)", r.Requests, r.RequestsPerSecond)
+	color.White("• Profiles found: %d (%.1f/s)", r.ProfilesFound, r.ProfilesPerSecond)
+	color.White("• Allocated: %.1f MB, peak heap %.1f MB", r.TotalAllocMB, r.PeakHeapMB)
+	color.White("• Peak goroutines: %d", r.PeakGoroutines)
+	color.White("• GC: %d cycles, %s total pause, %s longest", r.NumGC, r.GCPauseTotal, r.MaxGCPause)
+}
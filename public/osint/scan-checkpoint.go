@@ -0,0 +1,172 @@
+package osint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+)
+
+// ResumeCheckpoint is the checkpoint file of an interrupted scan to resume.
+// SearchProfilesSequentially skips the checks it records and reports the
+// profiles they found again instead of starting over.
+var ResumeCheckpoint string
+
+// checkpointInterval is how often a running scan saves its progress
+const checkpointInterval = 5 * time.Second
+
+// ScanCheckpoint is the progress of a social media scan: the search terms
+// already checked on each platform and the profiles they found. Checks that
+// failed are not recorded, so a resumed scan tries them again.
+type ScanCheckpoint struct {
+	Query   string              `json:"query"`
+	ScanID  string              `json:"scan_id,omitempty"`
+	Updated string              `json:"updated"`
+	Checked map[string][]string `json:"checked"`
+	Hits    []ProfileResult     `json:"hits,omitempty"`
+
+	path  string
+	mu    sync.Mutex
+	done  map[string]bool
+	dirty bool
+}
+
+// LoadScanCheckpoint reads a checkpoint file written by an earlier scan
+func LoadScanCheckpoint(path string) (*ScanCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint: %v", err)
+	}
+	checkpoint := &ScanCheckpoint{path: path}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("reading checkpoint %s: %v", path, err)
+	}
+	if checkpoint.Checked == nil {
+		checkpoint.Checked = make(map[string][]string)
+	}
+	checkpoint.done = make(map[string]bool)
+	for platform, terms := range checkpoint.Checked {
+		for _, term := range terms {
+			checkpoint.done[platform+"\x00"+term] = true
+		}
+	}
+	return checkpoint, nil
+}
+
+// openCheckpoint resumes ResumeCheckpoint when one is set, or starts a new
+// checkpoint under OutputDir/checkpoints
+func openCheckpoint(query string) (*ScanCheckpoint, error) {
+	if ResumeCheckpoint == "" {
+		name := fmt.Sprintf("%s_%s.json", strings.ToLower(strings.ReplaceAll(query, " ", "-")), time.Now().Format("20060102_150405"))
+		return &ScanCheckpoint{
+			Query:   query,
+			ScanID:  providers.ScanID,
+			Checked: make(map[string][]string),
+			path:    filepath.Join(OutputDir, "checkpoints", name),
+			done:    make(map[string]bool),
+		}, nil
+	}
+
+	checkpoint, err := LoadScanCheckpoint(ResumeCheckpoint)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(checkpoint.Query, query) {
+		return nil, fmt.Errorf("checkpoint %s is a scan of %q, not %q", ResumeCheckpoint, checkpoint.Query, query)
+	}
+	return checkpoint, nil
+}
+
+// Path returns the file the checkpoint is saved to
+func (c *ScanCheckpoint) Path() string {
+	return c.path
+}
+
+// record marks an item checked unless the check failed, keeping the profile
+// it found
+func (c *ScanCheckpoint) record(item workItem, result ProfileResult) {
+	if result.Error != "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := item.platform.Name + "\x00" + item.term
+	if c.done[key] {
+		return
+	}
+	c.done[key] = true
+	c.Checked[item.platform.Name] = append(c.Checked[item.platform.Name], item.term)
+	if result.Exists {
+		c.Hits = append(c.Hits, result)
+	}
+	c.dirty = true
+}
+
+// pending returns the items not checked yet
+func (c *ScanCheckpoint) pending(items []workItem) []workItem {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var left []workItem
+	for _, item := range items {
+		if !c.done[item.platform.Name+"\x00"+item.term] {
+			left = append(left, item)
+		}
+	}
+	return left
+}
+
+// autosave saves the checkpoint every checkpointInterval until ctx is done.
+// A checkpoint that cannot be written does not stop the scan.
+func (c *ScanCheckpoint) autosave(ctx context.Context) {
+	ticker := time.NewTicker(checkpointInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.save()
+		}
+	}
+}
+
+// save writes the checkpoint unless it is already saved and nothing was
+// checked since
+func (c *ScanCheckpoint) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := os.Stat(c.path); err == nil && !c.dirty {
+		return nil
+	}
+	c.Updated = time.Now().Format(time.RFC3339)
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// remove deletes the checkpoint once its scan has checked everything
+func (c *ScanCheckpoint) remove() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dirty = false
+	os.Remove(c.path)
+}
@@ -0,0 +1,147 @@
+package osint
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/input"
+	"github.com/awion/MercuriesOST/public/providers"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+)
+
+// scanPool checks profiles on a fixed number of workers sharing one HTTP
+// client and rate limiter. The pool owns its channels: the feeder closes the
+// work channel, and the results channel is closed only once every worker has
+// returned, so no goroutine outlives run.
+type scanPool struct {
+	client  *http.Client
+	limiter *rate.Limiter
+	workers int
+
+	// check looks up one work item, processSingleProfile by default
+	check func(ctx context.Context, client *http.Client, item workItem) ProfileResult
+	// progress is called by a worker after each item, found or not
+	progress func(item workItem)
+}
+
+// newScanPool creates a pool of workers limited to ScanRateLimit checks a
+// second, unless rate limits are off, bursting to one check per worker.
+// Rates below a check a second allow no burst.
+func newScanPool(client *http.Client, workers int) *scanPool {
+	limit := rate.Limit(ScanRateLimit)
+	if !providers.RateLimits {
+		limit = rate.Inf
+	}
+	return &scanPool{
+		client:  client,
+		limiter: rate.NewLimiter(limit, max(min(workers, int(ScanRateLimit)), 1)),
+		workers: max(workers, 1),
+		check: func(ctx context.Context, client *http.Client, item workItem) ProfileResult {
+			return processSingleProfile(ctx, client, item.platform, item.term)
+		},
+	}
+}
+
+// scanItems pairs every platform with every search term it allows as a
+// username, so terms no profile URL could hold are never requested
+func scanItems(platforms []SocialPlatform, terms []string) []workItem {
+	items := make([]workItem, 0, len(platforms)*len(terms))
+	for _, platform := range platforms {
+		for _, term := range terms {
+			if input.PlatformHandle(platform.Name, term) != nil {
+				continue
+			}
+			items = append(items, workItem{platform: platform, term: term})
+		}
+	}
+	return items
+}
+
+// run checks every item and hands each existing profile to collect. collect
+// is only called from the calling goroutine, so it needs no locking. run
+// returns once all workers have stopped; cancelling ctx stops the scan early
+// and returns the context's error.
+func (p *scanPool) run(ctx context.Context, items []workItem, collect func(item workItem, result ProfileResult)) error {
+	type hit struct {
+		item   workItem
+		result ProfileResult
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	work := make(chan workItem)
+	hits := make(chan hit, p.workers)
+
+	g.Go(func() error {
+		defer close(work)
+		for _, item := range items {
+			// Work left when the request budget runs out is skipped, not failed
+			if providers.OverBudget(platformHost(item.platform)) {
+				continue
+			}
+			select {
+			case work <- item:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+
+	for i := 0; i < p.workers; i++ {
+		g.Go(func() error {
+			for item := range work {
+				if err := p.limiter.Wait(ctx); err != nil {
+					return err
+				}
+
+				result := p.check(ctx, p.client, item)
+				if p.progress != nil {
+					p.progress(item)
+				}
+				if !result.Exists {
+					continue
+				}
+
+				// The collector drains hits until they are closed, so this never blocks for good
+				hits <- hit{item: item, result: result}
+			}
+			return nil
+		})
+	}
+
+	// Close hits after the last worker returns; the error is read after the
+	// channel closes, which orders the write before the read
+	var err error
+	go func() {
+		err = g.Wait()
+		close(hits)
+	}()
+
+	for h := range hits {
+		collect(h.item, h.result)
+	}
+	return err
+}
+
+// platformHost returns the host a platform's profiles are requested from
+func platformHost(platform SocialPlatform) string {
+	if u, err := url.Parse(platform.URL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return platform.Name
+}
+
+// sleepContext waits for d, returning early with the context's error when it is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
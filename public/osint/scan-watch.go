@@ -0,0 +1,195 @@
+package osint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+)
+
+// watchRunTimeout bounds one run of a watched scan
+const watchRunTimeout = 30 * time.Minute
+
+// WatchDefinition is a saved scan the watch command re-runs on a schedule.
+// Each run scans the seed like the all command and is compared with the
+// findings of the runs before it, kept under WatchlistDir/scans.
+type WatchDefinition struct {
+	Name           string `json:"name"`                      // Defaults to the file name
+	Seed           string `json:"seed"`                      // Email address or name
+	Schedule       string `json:"schedule"`                  // Cron expression, e.g. "0 */6 * * *"
+	AccountHistory bool   `json:"account_history,omitempty"` // Also look for archived, deleted and renamed accounts
+
+	schedule *CronSchedule
+}
+
+// LoadWatchDefinition reads a scan definition from a JSON file
+func LoadWatchDefinition(path string) (*WatchDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var def WatchDefinition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("parse scan definition %s: %v", path, err)
+	}
+
+	if def.Name == "" {
+		def.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if !watchlistNameRegex.MatchString(def.Name) {
+		return nil, fmt.Errorf("invalid scan definition name %q, use letters, digits, - and _", def.Name)
+	}
+	if strings.TrimSpace(def.Seed) == "" {
+		return nil, fmt.Errorf("scan definition %s has no seed", path)
+	}
+	if def.schedule, err = ParseCron(def.Schedule); err != nil {
+		return nil, fmt.Errorf("scan definition %s: %v", path, err)
+	}
+	return &def, nil
+}
+
+// Next returns when the definition is next due after t
+func (d *WatchDefinition) Next(t time.Time) time.Time {
+	return d.schedule.Next(t)
+}
+
+// Watch runs the definition each time it is due until ctx is cancelled,
+// handing every run's result to report
+func (d *WatchDefinition) Watch(ctx context.Context, report func(*WatchRunResult, error)) error {
+	for {
+		if err := sleepContext(ctx, time.Until(d.Next(time.Now()))); err != nil {
+			return err
+		}
+		runCtx, cancel := context.WithTimeout(ctx, watchRunTimeout)
+		result, err := d.Run(runCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		report(result, err)
+	}
+}
+
+// Run scans the seed once under a scan ID of its own and returns the
+// profiles, breaches and archives not seen by earlier runs. The first run
+// records what it finds as the baseline without reporting any of it as new.
+func (d *WatchDefinition) Run(ctx context.Context) (*WatchRunResult, error) {
+	state, err := d.loadState()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = providers.WithScanID(ctx, providers.NewScanID())
+	result := &WatchRunResult{Watchlist: d.Name, ScanID: providers.ScanIDFrom(ctx), Timestamp: time.Now().Format(time.RFC3339)}
+
+	savedHistory := CheckAccountHistory
+	CheckAccountHistory = d.AccountHistory
+	report, err := ScanAll(ctx, d.Seed, false)
+	CheckAccountHistory = savedHistory
+	if err != nil {
+		return nil, err
+	}
+
+	run := WatchItemRun{Kind: state.Kind, Value: state.Value, Baseline: state.LastRun == ""}
+	var errs []string
+	for _, moduleErr := range report.PartialErrors {
+		errs = append(errs, moduleErr.Module+": "+moduleErr.Error)
+	}
+	run.Error = strings.Join(errs, "; ")
+
+	fresh := state.merge(scanWatchFindings(state, report), result.Timestamp, result.ScanID)
+	if !run.Baseline {
+		run.New = fresh
+	}
+	state.LastRun = result.Timestamp
+	result.Items = []WatchItemRun{run}
+	return result, d.saveState(state)
+}
+
+func (d *WatchDefinition) statePath() string {
+	return filepath.Join(WatchlistDir, "scans", d.Name+".json")
+}
+
+// loadState reads the findings of earlier runs, starting afresh when the
+// definition has not run yet or its seed changed
+func (d *WatchDefinition) loadState() (*WatchItem, error) {
+	fresh := &WatchItem{Kind: WatchPerson, Value: d.Seed, Added: time.Now().Format(time.RFC3339)}
+	data, err := os.ReadFile(d.statePath())
+	if os.IsNotExist(err) {
+		return fresh, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state WatchItem
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parse watch state %s: %v", d.statePath(), err)
+	}
+	if !strings.EqualFold(state.Value, d.Seed) {
+		return fresh, nil
+	}
+	return &state, nil
+}
+
+func (d *WatchDefinition) saveState(state *WatchItem) error {
+	if err := os.MkdirAll(filepath.Dir(d.statePath()), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := d.statePath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, d.statePath())
+}
+
+// scanWatchFindings turns the profiles, breaches and archived captures of a
+// combined scan into watch findings
+func scanWatchFindings(item *WatchItem, report *CombinedReport) []WatchFinding {
+	var findings []WatchFinding
+	if report.Email != nil {
+		for _, breach := range report.Email.SecurityInfo.BreachDetails {
+			details := breach.BreachDate
+			if len(breach.CompromisedData) > 0 {
+				details += ", exposed " + strings.Join(breach.CompromisedData, ", ")
+			}
+			findings = append(findings, newWatchFinding(item, "breach", breach.BreachName,
+				"Email found in breach: "+breach.BreachName, "", strings.TrimPrefix(details, ", ")))
+		}
+		for _, profile := range report.Email.SocialProfiles {
+			findings = append(findings, newWatchFinding(item, "social", profile.URL,
+				fmt.Sprintf("%s account linked to the email: %s", profile.Platform, profile.Username), profile.URL, profile.DisplayName))
+		}
+	}
+
+	if report.Social != nil {
+		for _, profile := range report.Social.Profiles {
+			findings = append(findings, newWatchFinding(item, "social", profile.URL,
+				fmt.Sprintf("%s profile: %s", profile.Platform, profile.Username), profile.URL, profile.FullName))
+		}
+		// A new capture of the handle's profile is a new finding
+		for _, history := range report.Social.History {
+			if history.LastCaptured == "" {
+				continue
+			}
+			findings = append(findings, newWatchFinding(item, "archive", history.Platform+":"+history.Handle+":"+history.LastCaptured,
+				fmt.Sprintf("%s profile %s archived %s", history.Platform, history.Handle, history.LastCaptured), "", history.Status))
+		}
+	}
+
+	for _, gid := range report.GoogleIDs {
+		for _, archive := range gid.ArchiveData {
+			findings = append(findings, newWatchFinding(item, "archive", archive.URL,
+				fmt.Sprintf("Archived %s of Google ID %s", archive.Type, gid.GoogleID), archive.URL, archive.ArchiveDate))
+		}
+	}
+	return findings
+}
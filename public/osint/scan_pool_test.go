@@ -0,0 +1,124 @@
+package osint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// testScanPool builds an unthrottled pool around a fake check
+func testScanPool(workers int, check func(ctx context.Context, item workItem) ProfileResult) *scanPool {
+	pool := newScanPool(http.DefaultClient, workers)
+	pool.limiter = rate.NewLimiter(rate.Inf, 0)
+	pool.check = func(ctx context.Context, _ *http.Client, item workItem) ProfileResult {
+		return check(ctx, item)
+	}
+	return pool
+}
+
+// waitForGoroutines fails the test if the goroutine count does not settle back to want
+func waitForGoroutines(t *testing.T, want int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > want {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines still running, want %d", runtime.NumGoroutine(), want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestScanPoolCollectsFoundProfiles(t *testing.T) {
+	before := runtime.NumGoroutine()
+	items := scanItems([]SocialPlatform{{Name: "A"}, {Name: "B"}, {Name: "C"}}, []string{"x", "y", "z", "w"})
+
+	var checked, reported atomic.Int32
+	pool := testScanPool(4, func(ctx context.Context, item workItem) ProfileResult {
+		checked.Add(1)
+		return ProfileResult{Platform: item.platform.Name, Username: item.term, Exists: item.term != "w"}
+	})
+	pool.progress = func(workItem) { reported.Add(1) }
+
+	// collect runs on this goroutine only, so plain state is safe under -race
+	found := make(map[string]bool)
+	err := pool.run(context.Background(), items, func(item workItem, result ProfileResult) {
+		found[item.platform.Name+"/"+result.Username] = true
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if int(checked.Load()) != len(items) || int(reported.Load()) != len(items) {
+		t.Fatalf("checked %d and reported %d of %d items", checked.Load(), reported.Load(), len(items))
+	}
+	if len(found) != 9 || found["A/w"] {
+		t.Fatalf("collected %v, want the 9 existing profiles", found)
+	}
+	waitForGoroutines(t, before)
+}
+
+func TestScanPoolBoundsConcurrency(t *testing.T) {
+	const workers = 3
+	var running, peak atomic.Int32
+	pool := testScanPool(workers, func(ctx context.Context, item workItem) ProfileResult {
+		now := running.Add(1)
+		for {
+			old := peak.Load()
+			if now <= old || peak.CompareAndSwap(old, now) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return ProfileResult{Exists: true}
+	})
+
+	items := scanItems([]SocialPlatform{{Name: "A"}}, []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"})
+	if err := pool.run(context.Background(), items, func(workItem, ProfileResult) {}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if peak.Load() > workers {
+		t.Fatalf("%d checks ran at once, want at most %d", peak.Load(), workers)
+	}
+}
+
+func TestScanPoolStopsOnCancel(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Every check blocks until cancelled; the first one to start cancels the scan
+	var started atomic.Int32
+	pool := testScanPool(4, func(ctx context.Context, item workItem) ProfileResult {
+		if started.Add(1) == 1 {
+			cancel()
+		}
+		<-ctx.Done()
+		return ProfileResult{Exists: true}
+	})
+
+	items := scanItems([]SocialPlatform{{Name: "A"}}, make([]string, 100))
+	done := make(chan error, 1)
+	go func() {
+		// A slow collector must not keep blocked workers alive
+		done <- pool.run(ctx, items, func(workItem, ProfileResult) { time.Sleep(time.Millisecond) })
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("run returned %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("run did not return after cancel")
+	}
+	if int(started.Load()) == len(items) {
+		t.Fatal("every item was checked despite the cancel")
+	}
+	waitForGoroutines(t, before)
+}
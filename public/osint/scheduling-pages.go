@@ -0,0 +1,327 @@
+package osint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// Scheduling services FindSchedulingPages checks
+const (
+	SchedulingCalendly = "calendly"
+	SchedulingCal      = "cal.com"
+)
+
+// maxSchedulingSlugs caps the page names tried for one target
+const maxSchedulingSlugs = 4
+
+var (
+	slugWordRegex  = regexp.MustCompile(`[a-z0-9]+`)
+	nextDataRegex  = regexp.MustCompile(`(?s)<script id="__NEXT_DATA__" type="application/json"[^>]*>(.*?)</script>`)
+	pageTimeZoneRe = regexp.MustCompile(`"time[zZ]one":"([A-Za-z_]+/[A-Za-z0-9_/+-]+)"`)
+	calTitleSuffix = regexp.MustCompile(`\s*\|\s*Cal(\.com)?\s*$`)
+)
+
+// MeetingType is a kind of meeting a scheduling page offers
+type MeetingType struct {
+	Name        string `json:"name"`
+	Slug        string `json:"slug,omitempty"`
+	Minutes     int    `json:"minutes,omitempty"`
+	Description string `json:"description,omitempty"`
+	Location    string `json:"location,omitempty"`
+}
+
+// SchedulingPage is a public booking page found for the target
+type SchedulingPage struct {
+	Service     string        `json:"service"`
+	Slug        string        `json:"slug"`
+	URL         string        `json:"url"`
+	DisplayName string        `json:"display_name,omitempty"`
+	TimeZone    string        `json:"timezone,omitempty"`
+	UTCOffset   string        `json:"utc_offset,omitempty"`
+	NameMatches bool          `json:"name_matches"`
+	Meetings    []MeetingType `json:"meetings,omitempty"`
+}
+
+// SchedulingResult holds the scheduling pages found for a name or handle
+type SchedulingResult struct {
+	Target        string           `json:"target"`
+	ScanID        string           `json:"scan_id,omitempty"`
+	Slugs         []string         `json:"slugs"`
+	Pages         []SchedulingPage `json:"pages"`
+	PartialErrors []ModuleError    `json:"partial_errors,omitempty"`
+}
+
+// FindSchedulingPages probes Calendly and cal.com for booking pages named
+// after a handle or a full name ("john-smith", "johnsmith", ...), reading the
+// owner's display name, timezone and the meetings offered
+func FindSchedulingPages(ctx context.Context, target string) (*SchedulingResult, error) {
+	result := &SchedulingResult{Target: target, ScanID: providers.ScanIDFrom(ctx), Slugs: schedulingSlugs(target)}
+	if len(result.Slugs) == 0 {
+		return nil, fmt.Errorf("no page name can be made from %q", target)
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, ConcurrentRequests)
+	)
+	for _, slug := range result.Slugs {
+		for _, service := range []string{SchedulingCalendly, SchedulingCal} {
+			wg.Add(1)
+			go func(service, slug string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				var page *SchedulingPage
+				var err error
+				if service == SchedulingCalendly {
+					page, err = calendlyPage(ctx, slug)
+				} else {
+					page, err = calPage(ctx, slug)
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					result.PartialErrors = append(result.PartialErrors, ModuleError{Module: "scheduling." + service + "." + slug, Error: err.Error()})
+				}
+				if page != nil {
+					page.NameMatches = page.DisplayName != "" && strings.EqualFold(strings.Join(strings.Fields(page.DisplayName), " "), strings.Join(strings.Fields(target), " "))
+					page.UTCOffset = utcOffset(page.TimeZone)
+					result.Pages = append(result.Pages, *page)
+				}
+			}(service, slug)
+		}
+	}
+	wg.Wait()
+
+	// Keep the order pages were tried in rather than the order they answered
+	order := make(map[string]int)
+	for i, slug := range result.Slugs {
+		order[SchedulingCalendly+"/"+slug] = 2 * i
+		order[SchedulingCal+"/"+slug] = 2*i + 1
+	}
+	sort.Slice(result.Pages, func(i, j int) bool {
+		return order[result.Pages[i].Service+"/"+result.Pages[i].Slug] < order[result.Pages[j].Service+"/"+result.Pages[j].Slug]
+	})
+	return result, ctx.Err()
+}
+
+// schedulingSlugs returns the page names worth trying: a handle as it is,
+// or a full name joined with hyphens, run together and as initial plus surname
+func schedulingSlugs(target string) []string {
+	words := slugWordRegex.FindAllString(strings.ToLower(target), -1)
+	if len(words) == 0 {
+		return nil
+	}
+	var slugs []string
+	add := func(slug string) {
+		if len(slug) >= 3 && len(slugs) < maxSchedulingSlugs && !slices.Contains(slugs, slug) {
+			slugs = append(slugs, slug)
+		}
+	}
+	if !strings.ContainsAny(strings.TrimSpace(target), " \t") {
+		add(strings.ToLower(strings.TrimSpace(target)))
+	}
+	add(strings.Join(words, "-"))
+	add(strings.Join(words, ""))
+	if len(words) > 1 {
+		add(words[0][:1] + words[len(words)-1])
+		add(words[0] + "-" + words[len(words)-1])
+	}
+	return slugs
+}
+
+// calendlyPage reads a Calendly booking page through the API its booking
+// site uses. A missing page is nil without an error.
+func calendlyPage(ctx context.Context, slug string) (*SchedulingPage, error) {
+	var profile struct {
+		Name     string `json:"name"`
+		Slug     string `json:"slug"`
+		Timezone string `json:"timezone"`
+	}
+	base := "https://calendly.com/api/booking/profiles/" + url.PathEscape(slug)
+	if err := getProviderJSON(ctx, base, nil, &profile); err != nil {
+		if providers.IsStatus(err, http.StatusNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	page := &SchedulingPage{
+		Service:     SchedulingCalendly,
+		Slug:        slug,
+		URL:         "https://calendly.com/" + slug,
+		DisplayName: profile.Name,
+		TimeZone:    profile.Timezone,
+	}
+
+	var events []struct {
+		Name        string `json:"name"`
+		Slug        string `json:"slug"`
+		Duration    int    `json:"duration"`
+		Description string `json:"description_plain"`
+		Locations   []struct {
+			Kind string `json:"kind"`
+		} `json:"location_configurations"`
+	}
+	if err := getProviderJSON(ctx, base+"/event_types", nil, &events); err != nil {
+		// The page exists even when its meetings cannot be listed
+		return page, fmt.Errorf("meeting types: %v", err)
+	}
+	for _, event := range events {
+		meeting := MeetingType{Name: event.Name, Slug: event.Slug, Minutes: event.Duration, Description: shortDescription(event.Description)}
+		if len(event.Locations) > 0 {
+			meeting.Location = strings.ReplaceAll(event.Locations[0].Kind, "_", " ")
+		}
+		page.Meetings = append(page.Meetings, meeting)
+	}
+	return page, nil
+}
+
+// calPage reads a cal.com booking page from the profile data embedded in
+// it, falling back to its title and timezone when the layout changes. A
+// missing page is nil without an error.
+func calPage(ctx context.Context, slug string) (*SchedulingPage, error) {
+	target := "https://cal.com/" + url.PathEscape(slug)
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	resp, err := doProviderRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := readPageBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	text := body.String()
+
+	page := &SchedulingPage{Service: SchedulingCal, Slug: slug, URL: target}
+	if m := nextDataRegex.FindStringSubmatch(text); m != nil {
+		var data struct {
+			Props struct {
+				PageProps struct {
+					Profile struct {
+						Name string `json:"name"`
+					} `json:"profile"`
+					Users []struct {
+						Name     string `json:"name"`
+						TimeZone string `json:"timeZone"`
+					} `json:"users"`
+					EventTypes []struct {
+						Title       string `json:"title"`
+						Slug        string `json:"slug"`
+						Length      int    `json:"length"`
+						Description string `json:"description"`
+					} `json:"eventTypes"`
+				} `json:"pageProps"`
+			} `json:"props"`
+		}
+		if json.Unmarshal([]byte(m[1]), &data) == nil {
+			props := data.Props.PageProps
+			page.DisplayName = props.Profile.Name
+			if len(props.Users) > 0 {
+				if page.DisplayName == "" {
+					page.DisplayName = props.Users[0].Name
+				}
+				page.TimeZone = props.Users[0].TimeZone
+			}
+			for _, event := range props.EventTypes {
+				page.Meetings = append(page.Meetings, MeetingType{Name: event.Title, Slug: event.Slug, Minutes: event.Length, Description: shortDescription(event.Description)})
+			}
+		}
+	}
+	if page.DisplayName == "" {
+		if m := ogTitleRegex.FindStringSubmatch(text); m != nil {
+			page.DisplayName = calTitleSuffix.ReplaceAllString(html.UnescapeString(m[1]), "")
+		}
+	}
+	if page.TimeZone == "" {
+		if m := pageTimeZoneRe.FindStringSubmatch(text); m != nil {
+			page.TimeZone = m[1]
+		}
+	}
+	return page, nil
+}
+
+// shortDescription cuts a meeting description to its first 200 characters
+func shortDescription(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > 200 {
+		return string(runes[:200]) + "…"
+	}
+	return text
+}
+
+// utcOffset returns a timezone's current offset from UTC, like "UTC-05:00"
+func utcOffset(zone string) string {
+	if zone == "" {
+		return ""
+	}
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		return ""
+	}
+	return "UTC" + time.Now().In(location).Format("-07:00")
+}
+
+// DisplayResults prints the scheduling pages found
+func (r *SchedulingResult) DisplayResults() {
+	color.Cyan("\n=== SCHEDULING PAGES: %s ===", r.Target)
+	color.White("Page names tried: %s", strings.Join(r.Slugs, ", "))
+	if len(r.Pages) == 0 {
+		color.Yellow("No Calendly or cal.com pages found")
+		return
+	}
+	for _, page := range r.Pages {
+		color.Green("\n%s", page.URL)
+		if page.DisplayName != "" {
+			line := fmt.Sprintf("  Name: %s", page.DisplayName)
+			if page.NameMatches {
+				line += " (matches the target)"
+			}
+			fmt.Println(line)
+		}
+		if page.TimeZone != "" {
+			fmt.Printf("  Timezone: %s %s\n", page.TimeZone, page.UTCOffset)
+		}
+		for _, meeting := range page.Meetings {
+			line := "  • " + meeting.Name
+			if meeting.Minutes > 0 {
+				line += fmt.Sprintf(" (%d min)", meeting.Minutes)
+			}
+			if meeting.Location != "" {
+				line += ", " + meeting.Location
+			}
+			fmt.Println(line)
+		}
+	}
+}
+
+// DisplayPartialErrors prints the pages that could not be checked
+func (r *SchedulingResult) DisplayPartialErrors() {
+	displayModuleErrors(r.PartialErrors)
+}
@@ -0,0 +1,290 @@
+package osint
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Secret stores, chosen with secret_store in the config file
+const (
+	SecretStoreAuto     = ""         // The OS keychain when there is one, else the encrypted file
+	SecretStoreKeychain = "keychain" // macOS Keychain, libsecret or Windows DPAPI
+	SecretStoreFile     = "file"     // A file encrypted with $MERCURIES_SECRETS_PASSPHRASE
+)
+
+var (
+	// SecretStoreKind picks where secrets are kept
+	SecretStoreKind = SecretStoreAuto
+	// SecretsFile is the encrypted fallback store
+	SecretsFile = defaultSecretsFile()
+	// SecretsPassphrase returns the passphrase of SecretsFile. Commands
+	// replace it to prompt for one when $MERCURIES_SECRETS_PASSPHRASE is unset.
+	SecretsPassphrase = func() (string, error) {
+		if passphrase := os.Getenv("MERCURIES_SECRETS_PASSPHRASE"); passphrase != "" {
+			return passphrase, nil
+		}
+		return "", errors.New("set $MERCURIES_SECRETS_PASSPHRASE to unlock " + SecretsFile)
+	}
+)
+
+// defaultSecretsFile returns ~/.mercuries/secrets.enc
+func defaultSecretsFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".mercuries", "secrets.enc")
+}
+
+// secretsKDFRounds is the PBKDF2-SHA256 work factor of the encrypted file
+const secretsKDFRounds = 600000
+
+// secretBackend keeps every secret as one JSON document, so the keychain
+// asks for access once per run rather than once per key
+type secretBackend interface {
+	Name() string
+	Load() ([]byte, error) // nil when nothing is stored yet
+	Save(data []byte) error
+}
+
+// secretStore returns the backend SecretStoreKind selects
+func secretStore() (secretBackend, error) {
+	switch SecretStoreKind {
+	case SecretStoreFile:
+		return fileSecrets{}, nil
+	case SecretStoreKeychain:
+		if keychain := platformKeychain(); keychain != nil {
+			return keychain, nil
+		}
+		return nil, errors.New("no OS keychain is available here; use secret_store: file")
+	}
+	if keychain := platformKeychain(); keychain != nil {
+		return keychain, nil
+	}
+	return fileSecrets{}, nil
+}
+
+// SecretNames lists the names a secret can be stored under: the API keys, as
+// named in the config file
+func SecretNames() []string {
+	var names []string
+	keys := reflect.TypeOf(APIKeys{})
+	for i := 0; i < keys.NumField(); i++ {
+		names = append(names, keys.Field(i).Tag.Get("json"))
+	}
+	return names
+}
+
+// apiKeyField returns the API key stored under a secret name
+func apiKeyField(keys *APIKeys, name string) *string {
+	value := reflect.ValueOf(keys).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).Tag.Get("json") == name {
+			return value.Field(i).Addr().Interface().(*string)
+		}
+	}
+	return nil
+}
+
+// readSecrets returns the stored secrets and the store holding them
+func readSecrets() (map[string]string, secretBackend, error) {
+	store, err := secretStore()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := store.Load()
+	if err != nil {
+		return nil, store, fmt.Errorf("%s: %v", store.Name(), err)
+	}
+	secrets := make(map[string]string)
+	if data != nil {
+		if err := json.Unmarshal(data, &secrets); err != nil {
+			return nil, store, fmt.Errorf("%s: unreadable secrets: %v", store.Name(), err)
+		}
+	}
+	return secrets, store, nil
+}
+
+// LoadSecrets fills in the API keys that neither the config file nor the
+// environment set from the secret store
+func LoadSecrets() error {
+	// The encrypted file is only opened when it exists, so runs without
+	// stored secrets never ask for a passphrase
+	if store, err := secretStore(); err == nil && store == secretBackend(fileSecrets{}) {
+		if _, err := os.Stat(SecretsFile); err != nil {
+			return nil
+		}
+	}
+	secrets, _, err := readSecrets()
+	if err != nil {
+		return err
+	}
+	for name, value := range secrets {
+		if key := apiKeyField(&APIConfig, name); key != nil && !apiKeyConfigured(*key) {
+			*key = value
+		}
+	}
+	return nil
+}
+
+// StoredSecrets lists the names of the stored secrets and the store's name
+func StoredSecrets() ([]string, string, error) {
+	secrets, store, err := readSecrets()
+	if err != nil {
+		return nil, "", err
+	}
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, store.Name(), nil
+}
+
+// SetSecret stores a secret, or removes it when value is empty, and returns
+// the store's name
+func SetSecret(name, value string) (string, error) {
+	if apiKeyField(&APIKeys{}, name) == nil {
+		return "", fmt.Errorf("unknown secret %q, expected one of %s", name, strings.Join(SecretNames(), ", "))
+	}
+	secrets, store, err := readSecrets()
+	if err != nil {
+		return "", err
+	}
+	if value == "" {
+		if _, ok := secrets[name]; !ok {
+			return "", fmt.Errorf("no secret %q in %s", name, store.Name())
+		}
+		delete(secrets, name)
+	} else {
+		secrets[name] = value
+	}
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return "", err
+	}
+	if err := store.Save(data); err != nil {
+		return "", fmt.Errorf("%s: %v", store.Name(), err)
+	}
+	return store.Name(), nil
+}
+
+// fileSecrets keeps the secrets in SecretsFile, encrypted with AES-256-GCM
+// under a key derived from the passphrase
+type fileSecrets struct{}
+
+// secretsEnvelope is the encrypted file's contents
+type secretsEnvelope struct {
+	Rounds int    `json:"rounds"`
+	Salt   []byte `json:"salt"`
+	Nonce  []byte `json:"nonce"`
+	Data   []byte `json:"data"`
+}
+
+func (fileSecrets) Name() string { return "encrypted file" }
+
+func (fileSecrets) Load() ([]byte, error) {
+	data, err := os.ReadFile(SecretsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var envelope secretsEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("%s is damaged: %v", SecretsFile, err)
+	}
+	passphrase, err := SecretsPassphrase()
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := secretsCipher(passphrase, envelope.Salt, envelope.Rounds)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, envelope.Nonce, envelope.Data, nil)
+	if err != nil {
+		return nil, errors.New("wrong passphrase, or the file was changed")
+	}
+	return plain, nil
+}
+
+func (fileSecrets) Save(data []byte) error {
+	passphrase, err := SecretsPassphrase()
+	if err != nil {
+		return err
+	}
+	envelope := secretsEnvelope{Rounds: secretsKDFRounds, Salt: make([]byte, 16)}
+	if _, err := rand.Read(envelope.Salt); err != nil {
+		return err
+	}
+	gcm, err := secretsCipher(passphrase, envelope.Salt, envelope.Rounds)
+	if err != nil {
+		return err
+	}
+	envelope.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(envelope.Nonce); err != nil {
+		return err
+	}
+	envelope.Data = gcm.Seal(nil, envelope.Nonce, data, nil)
+
+	encoded, err := json.MarshalIndent(envelope, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(SecretsFile), 0700); err != nil {
+		return err
+	}
+	tmp := SecretsFile + ".tmp"
+	if err := os.WriteFile(tmp, encoded, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, SecretsFile)
+}
+
+// secretsCipher derives the file's AES-256-GCM cipher from a passphrase
+func secretsCipher(passphrase string, salt []byte, rounds int) (cipher.AEAD, error) {
+	if passphrase == "" {
+		return nil, errors.New("empty passphrase")
+	}
+	if rounds < 1 {
+		return nil, errors.New("invalid key derivation rounds")
+	}
+	block, err := aes.NewCipher(pbkdf2SHA256([]byte(passphrase), salt, rounds))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 derives a 32-byte key as in RFC 8018, which fits in a single
+// PBKDF2 block
+func pbkdf2SHA256(password, salt []byte, rounds int) []byte {
+	prf := hmac.New(sha256.New, password)
+	prf.Write(salt)
+	prf.Write(binary.BigEndian.AppendUint32(nil, 1))
+	u := prf.Sum(nil)
+	key := append([]byte(nil), u...)
+	for i := 1; i < rounds; i++ {
+		prf.Reset()
+		prf.Write(u)
+		u = prf.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
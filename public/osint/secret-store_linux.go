@@ -0,0 +1,48 @@
+package osint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// libsecretKeychain keeps the secrets in the desktop keyring (GNOME Keyring,
+// KWallet) through libsecret's secret-tool, which reads the secret on stdin
+type libsecretKeychain struct{}
+
+func platformKeychain() secretBackend {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return nil
+	}
+	return libsecretKeychain{}
+}
+
+func (libsecretKeychain) Name() string { return "libsecret keyring" }
+
+func (libsecretKeychain) Load() ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "lookup", "service", "MercuriesOST", "account", "secrets")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && stderr.Len() == 0 && len(out) == 0 {
+		return nil, nil // Nothing stored
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+func (libsecretKeychain) Save(data []byte) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "store", "--label=MercuriesOST secrets", "service", "MercuriesOST", "account", "secrets")
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !darwin && !linux && !windows
+
+package osint
+
+// platformKeychain returns nil where no OS keychain is supported, leaving
+// the encrypted file
+func platformKeychain() secretBackend {
+	return nil
+}
@@ -0,0 +1,64 @@
+package osint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// dpapiKeychain keeps the secrets in a file encrypted with DPAPI, which only
+// the current Windows user can decrypt
+type dpapiKeychain struct{}
+
+func platformKeychain() secretBackend {
+	return dpapiKeychain{}
+}
+
+func (dpapiKeychain) Name() string { return "Windows DPAPI" }
+
+// dpapiFile returns where the DPAPI-encrypted secrets are kept
+func dpapiFile() string {
+	return filepath.Join(filepath.Dir(SecretsFile), "secrets.dpapi")
+}
+
+func (dpapiKeychain) Load() ([]byte, error) {
+	sealed, err := os.ReadFile(dpapiFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil || len(sealed) == 0 {
+		return nil, err
+	}
+	in := windows.DataBlob{Size: uint32(len(sealed)), Data: &sealed[0]}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...), nil
+}
+
+func (dpapiKeychain) Save(data []byte) error {
+	if len(data) == 0 {
+		data = []byte("{}")
+	}
+	in := windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+	var out windows.DataBlob
+	if err := windows.CryptProtectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	sealed := append([]byte(nil), unsafe.Slice(out.Data, out.Size)...)
+
+	if err := os.MkdirAll(filepath.Dir(dpapiFile()), 0700); err != nil {
+		return err
+	}
+	tmp := dpapiFile() + ".tmp"
+	if err := os.WriteFile(tmp, sealed, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dpapiFile())
+}
@@ -0,0 +1,375 @@
+package osint
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/input"
+	"github.com/awion/MercuriesOST/public/providers"
+	"golang.org/x/time/rate"
+)
+
+// serveModule is a module mercuries serve runs for GET /scan/<name>
+type serveModule struct {
+	kind string // Target kind the target is checked as
+	run  func(ctx context.Context, target string) (interface{}, error)
+}
+
+var serveModules = map[string]serveModule{
+	"email": {input.KindEmail, func(ctx context.Context, target string) (interface{}, error) {
+		return AnalyzeEmail(target)
+	}},
+	"domain": {input.KindDomain, func(ctx context.Context, target string) (interface{}, error) {
+		return AnalyzeDomain(ctx, target)
+	}},
+	"ip": {input.KindIP, func(ctx context.Context, target string) (interface{}, error) {
+		return AnalyzeIP(ctx, target)
+	}},
+	"phone": {input.KindPhone, func(ctx context.Context, target string) (interface{}, error) {
+		return AnalyzePhoneNumber(ctx, target)
+	}},
+	"url": {"", func(ctx context.Context, target string) (interface{}, error) {
+		return TriageURL(ctx, target)
+	}},
+}
+
+// ServeModuleNames lists the modules mercuries serve runs, sorted
+func ServeModuleNames() []string {
+	names := make([]string, 0, len(serveModules))
+	for name := range serveModules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AuditEntry is one line of the audit log: who asked for what, and the answer
+type AuditEntry struct {
+	Time   string `json:"time"`
+	Token  string `json:"token,omitempty"` // The token's name, never the token
+	Remote string `json:"remote"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Module string `json:"module,omitempty"`
+	Target string `json:"target,omitempty"`
+	ScanID string `json:"scan_id,omitempty"` // Set once a scan counted against the quota
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ScanServer answers scan and feed requests for holders of API tokens,
+// enforcing each token's scopes, rate limit and daily quota and writing
+// every request to the audit log
+type ScanServer struct {
+	mu       sync.Mutex
+	tokens   map[string]*APIToken // By hash
+	modified time.Time            // Of TokensFile when tokens were read
+	limiters map[string]*rate.Limiter
+	day      string         // UTC date the counts in used are for
+	used     map[string]int // Scans run today, by token name
+	audit    *os.File
+}
+
+// NewScanServer reads TokensFile and opens AuditLogFile, counting the scans
+// already logged today against the quotas
+func NewScanServer() (*ScanServer, error) {
+	s := &ScanServer{limiters: make(map[string]*rate.Limiter)}
+	if err := s.reloadTokens(); err != nil {
+		return nil, err
+	}
+	if err := s.countUsage(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(AuditLogFile), 0700); err != nil {
+		return nil, err
+	}
+	audit, err := os.OpenFile(AuditLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	s.audit = audit
+	return s, nil
+}
+
+// Tokens returns the number of tokens the server accepts
+func (s *ScanServer) Tokens() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.tokens)
+}
+
+// Close closes the audit log
+func (s *ScanServer) Close() error {
+	return s.audit.Close()
+}
+
+// reloadTokens reads TokensFile again when it changed, so added and revoked
+// tokens apply without a restart
+func (s *ScanServer) reloadTokens() error {
+	info, err := os.Stat(TokensFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if info != nil && info.ModTime().Equal(s.modified) && s.tokens != nil {
+		return nil
+	}
+	list, err := LoadAPITokens()
+	if err != nil {
+		return err
+	}
+	s.tokens = make(map[string]*APIToken, len(list))
+	for _, token := range list {
+		s.tokens[token.SHA256] = token
+	}
+	if info != nil {
+		s.modified = info.ModTime()
+	}
+	return nil
+}
+
+// countUsage reads today's scans from the audit log, so restarting the
+// server does not reset the quotas
+func (s *ScanServer) countUsage() error {
+	s.day = time.Now().UTC().Format("2006-01-02")
+	s.used = make(map[string]int)
+	file, err := os.Open(AuditLogFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+		if entry.ScanID != "" && strings.HasPrefix(entry.Time, s.day) {
+			s.used[entry.Token]++
+		}
+	}
+	return scanner.Err()
+}
+
+// Handler serves GET /scan/<module>?target=... and the watchlist feeds under
+// /feeds/, for requests carrying "Authorization: Bearer <token>"
+func (s *ScanServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/scan/", s.serveScan)
+	feeds := http.StripPrefix("/feeds", FeedHandler())
+	mux.HandleFunc("/feeds/", func(w http.ResponseWriter, r *http.Request) {
+		entry := s.newEntry(r)
+		token, status, err := s.authorize(r, ScopeFeeds)
+		if token != nil {
+			entry.Token = token.Name
+		}
+		if err != nil {
+			s.fail(w, entry, status, err)
+			return
+		}
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		feeds.ServeHTTP(recorder, r)
+		entry.Status = recorder.status
+		s.log(entry)
+	})
+	return mux
+}
+
+func (s *ScanServer) serveScan(w http.ResponseWriter, r *http.Request) {
+	entry := s.newEntry(r)
+	entry.Module = strings.TrimPrefix(r.URL.Path, "/scan/")
+	entry.Target = strings.TrimSpace(r.URL.Query().Get("target"))
+
+	token, status, err := s.authorize(r, entry.Module)
+	if token != nil {
+		entry.Token = token.Name
+	}
+	if err != nil {
+		s.fail(w, entry, status, err)
+		return
+	}
+	module, ok := serveModules[entry.Module]
+	if !ok {
+		s.fail(w, entry, http.StatusNotFound, fmt.Errorf("unknown module %q, expected one of %s", entry.Module, strings.Join(ServeModuleNames(), ", ")))
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		s.fail(w, entry, http.StatusMethodNotAllowed, errors.New("use GET"))
+		return
+	}
+	if entry.Target == "" {
+		s.fail(w, entry, http.StatusBadRequest, errors.New("missing target parameter"))
+		return
+	}
+	if module.kind != "" {
+		if entry.Target, err = input.Target(module.kind, entry.Target); err != nil {
+			s.fail(w, entry, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if !TouchCanaries {
+		if match := MatchCanary(entry.Target); match != nil {
+			s.fail(w, entry, http.StatusForbidden, fmt.Errorf("%s looks like a canary (%s); scanning it would alert its owner", entry.Target, match.Name))
+			return
+		}
+	}
+	remaining, err := s.takeQuota(token)
+	if err != nil {
+		s.fail(w, entry, http.StatusTooManyRequests, err)
+		return
+	}
+	if remaining >= 0 {
+		w.Header().Set("X-Quota-Remaining", strconv.Itoa(remaining))
+	}
+
+	// Each request is its own scan, traced and logged under its own ID
+	entry.ScanID = providers.NewScanID()
+	ctx := providers.WithModule(providers.WithScanID(r.Context(), entry.ScanID), entry.Module)
+	w.Header().Set("X-Scan-ID", entry.ScanID)
+
+	decision, err := CheckPolicy(ctx, entry.Module, entry.Target)
+	if err != nil {
+		s.fail(w, entry, http.StatusServiceUnavailable, fmt.Errorf("%v; the scan is not allowed without a policy decision", err))
+		return
+	}
+	if !decision.Allow {
+		reason := decision.Reason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		s.fail(w, entry, http.StatusForbidden, fmt.Errorf("denied by policy: %s", reason))
+		return
+	}
+
+	results, err := module.run(ctx, entry.Target)
+	if err != nil {
+		s.fail(w, entry, http.StatusBadGateway, err)
+		return
+	}
+	RunHook(HookScanComplete, entry.Module, results)
+	entry.Status = http.StatusOK
+	s.log(entry)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// authorize finds the request's token and checks it may use scope and is
+// within its rate limit. The token is returned even when it is refused, for
+// the audit log.
+func (s *ScanServer) authorize(r *http.Request, scope string) (*APIToken, int, error) {
+	secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || secret == "" {
+		return nil, http.StatusUnauthorized, errors.New("missing bearer token")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reloadTokens(); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	token := s.tokens[hashToken(strings.TrimSpace(secret))]
+	if token == nil {
+		return nil, http.StatusUnauthorized, errors.New("unknown or revoked token")
+	}
+	if !token.Allows(scope) {
+		return token, http.StatusForbidden, fmt.Errorf("token %s is not allowed to use %s", token.Name, scope)
+	}
+	if token.RatePerMinute > 0 {
+		limiter := s.limiters[token.Name]
+		if limiter == nil || limiter.Burst() != token.RatePerMinute {
+			limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(token.RatePerMinute)), token.RatePerMinute)
+			s.limiters[token.Name] = limiter
+		}
+		if !limiter.Allow() {
+			return token, http.StatusTooManyRequests, fmt.Errorf("rate limit of %d requests per minute reached", token.RatePerMinute)
+		}
+	}
+	return token, http.StatusOK, nil
+}
+
+// takeQuota counts a scan against the token's daily quota and returns the
+// scans left today, or -1 when the token has no quota
+func (s *ScanServer) takeQuota(token *APIToken) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if today := time.Now().UTC().Format("2006-01-02"); today != s.day {
+		s.day, s.used = today, make(map[string]int)
+	}
+	if token.DailyQuota == 0 {
+		s.used[token.Name]++
+		return -1, nil
+	}
+	if s.used[token.Name] >= token.DailyQuota {
+		return 0, fmt.Errorf("daily quota of %d scans used up", token.DailyQuota)
+	}
+	s.used[token.Name]++
+	return token.DailyQuota - s.used[token.Name], nil
+}
+
+func (s *ScanServer) newEntry(r *http.Request) AuditEntry {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+	return AuditEntry{
+		Time:   time.Now().UTC().Format(time.RFC3339),
+		Remote: remote,
+		Method: r.Method,
+		Path:   r.URL.Path,
+	}
+}
+
+// fail answers with a JSON error and logs the refusal
+func (s *ScanServer) fail(w http.ResponseWriter, entry AuditEntry, status int, err error) {
+	entry.Status, entry.Error = status, err.Error()
+	s.log(entry)
+	switch status {
+	case http.StatusUnauthorized:
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mercuries"`)
+	case http.StatusTooManyRequests:
+		w.Header().Set("Retry-After", "60")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// log appends an entry to the audit log
+func (s *ScanServer) log(entry AuditEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.audit.Write(append(data, '\n')); err != nil {
+		fmt.Fprintf(os.Stderr, "Audit log write failed: %v\n", err)
+	}
+}
+
+// statusRecorder keeps the status a wrapped handler answered with
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
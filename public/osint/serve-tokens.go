@@ -0,0 +1,169 @@
+package osint
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Token scopes besides the module names of ServeModuleNames
+const (
+	ScopeAll   = "*"     // Every module and the feeds
+	ScopeFeeds = "feeds" // The watchlist feeds
+)
+
+var (
+	// TokensFile lists the API tokens mercuries serve accepts
+	TokensFile = defaultServeFile("tokens.json")
+	// AuditLogFile records every request made to mercuries serve, one JSON
+	// object per line
+	AuditLogFile = defaultServeFile("audit.log")
+)
+
+// tokenPrefix marks MercuriesOST tokens so secret scanners can spot leaks
+const tokenPrefix = "mos_"
+
+// APIToken lets one user or team call mercuries serve. Only a hash of the
+// token is kept; the token itself is shown once, when it is added.
+type APIToken struct {
+	Name          string   `json:"name"`
+	SHA256        string   `json:"sha256"`
+	Scopes        []string `json:"scopes"`
+	DailyQuota    int      `json:"daily_quota,omitempty"`     // Scans per UTC day, 0 for no limit
+	RatePerMinute int      `json:"rate_per_minute,omitempty"` // Requests per minute, 0 for no limit
+	Created       string   `json:"created"`
+}
+
+// defaultServeFile returns a file under ~/.mercuries
+func defaultServeFile(name string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".mercuries", name)
+}
+
+// hashToken returns the hex SHA-256 a token is stored as
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Allows reports whether the token's scopes cover scope
+func (t *APIToken) Allows(scope string) bool {
+	for _, allowed := range t.Scopes {
+		if allowed == ScopeAll || allowed == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadAPITokens reads TokensFile, returning no tokens when it does not exist
+func LoadAPITokens() ([]*APIToken, error) {
+	data, err := os.ReadFile(TokensFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tokens []*APIToken
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("%s is damaged: %v", TokensFile, err)
+	}
+	return tokens, nil
+}
+
+// saveAPITokens writes TokensFile, readable by its owner only
+func saveAPITokens(tokens []*APIToken) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(TokensFile), 0700); err != nil {
+		return err
+	}
+	tmp := TokensFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, TokensFile)
+}
+
+// checkScopes rejects scopes that name no module
+func checkScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return errors.New("a token needs at least one scope")
+	}
+	for _, scope := range scopes {
+		if scope == ScopeAll || scope == ScopeFeeds {
+			continue
+		}
+		if _, ok := serveModules[scope]; !ok {
+			return fmt.Errorf("unknown scope %q, expected %s, %s or one of %s", scope, ScopeAll, ScopeFeeds, strings.Join(ServeModuleNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// AddAPIToken creates a token and returns it. It cannot be recovered later.
+func AddAPIToken(name string, scopes []string, dailyQuota, ratePerMinute int) (string, error) {
+	if !watchlistNameRegex.MatchString(name) {
+		return "", fmt.Errorf("invalid token name %q: use letters, digits, - and _", name)
+	}
+	if err := checkScopes(scopes); err != nil {
+		return "", err
+	}
+	if dailyQuota < 0 || ratePerMinute < 0 {
+		return "", errors.New("quota and rate cannot be negative")
+	}
+	tokens, err := LoadAPITokens()
+	if err != nil {
+		return "", err
+	}
+	for _, token := range tokens {
+		if token.Name == name {
+			return "", fmt.Errorf("a token named %q already exists; revoke it first", name)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	token := tokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	tokens = append(tokens, &APIToken{
+		Name:          name,
+		SHA256:        hashToken(token),
+		Scopes:        scopes,
+		DailyQuota:    dailyQuota,
+		RatePerMinute: ratePerMinute,
+		Created:       time.Now().UTC().Format(time.RFC3339),
+	})
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Name < tokens[j].Name })
+	return token, saveAPITokens(tokens)
+}
+
+// RevokeAPIToken removes the token named name
+func RevokeAPIToken(name string) error {
+	tokens, err := LoadAPITokens()
+	if err != nil {
+		return err
+	}
+	for i, token := range tokens {
+		if token.Name == name {
+			return saveAPITokens(append(tokens[:i], tokens[i+1:]...))
+		}
+	}
+	return fmt.Errorf("no token named %q in %s", name, TokensFile)
+}
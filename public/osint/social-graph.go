@@ -0,0 +1,334 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+var (
+	// SampleSocialGraph makes profile searches sample the followers and
+	// following of confirmed profiles on platforms with an API for it
+	SampleSocialGraph = false
+	// GraphSampleSize caps the followers, and separately the following,
+	// sampled per profile
+	GraphSampleSize = 200
+)
+
+// maxGraphProfiles caps the profiles sampled per search, since follower APIs
+// are tightly rate limited (GitHub allows 60 requests an hour without a token)
+const maxGraphProfiles = 3
+
+// graphSampler lists up to limit accounts on one side of a profile's network
+// ("followers" or "following")
+type graphSampler func(ctx context.Context, username, side string, limit int) ([]string, error)
+
+// graphSamplers maps platform names to their follower APIs. Twitter's is only
+// used with an API token.
+var graphSamplers = map[string]graphSampler{
+	"GitHub":  githubNetwork,
+	"Twitter": twitterNetwork,
+}
+
+// GraphSample is the part of a profile's network that was read
+type GraphSample struct {
+	Platform  string   `json:"platform"`
+	Profile   string   `json:"profile"`
+	Username  string   `json:"username"`
+	Followers []string `json:"followers,omitempty"`
+	Following []string `json:"following,omitempty"`
+	Truncated bool     `json:"truncated,omitempty"` // A side filled the sample, so may hold more
+}
+
+// GraphOverlap is how much two sampled networks share
+type GraphOverlap struct {
+	ProfileA string  `json:"profile_a"`
+	ProfileB string  `json:"profile_b"`
+	Shared   int     `json:"shared"`
+	Jaccard  float64 `json:"jaccard"` // Shared accounts over all accounts in either network
+}
+
+// MutualAccount is an account in the networks of several of the target's
+// profiles, which makes it likely to know the target
+type MutualAccount struct {
+	Account   string   `json:"account"`   // Comparable handle
+	Handles   []string `json:"handles"`   // As written on each platform
+	Relations []string `json:"relations"` // e.g. "follower of https://github.com/jdoe"
+	Platforms []string `json:"platforms"`
+}
+
+// SocialGraph is the sampled networks of a search's profiles and where they
+// overlap
+type SocialGraph struct {
+	Samples       []GraphSample   `json:"samples"`
+	Overlaps      []GraphOverlap  `json:"overlaps,omitempty"`
+	Mutuals       []MutualAccount `json:"mutual_accounts,omitempty"`
+	PartialErrors []ModuleError   `json:"partial_errors,omitempty"`
+}
+
+// SampleProfileNetworks reads a bounded sample of the followers and following
+// of the most confident profiles whose platform has an API for it, and finds
+// the accounts their networks share
+func SampleProfileNetworks(ctx context.Context, profiles []ProfileResult) *SocialGraph {
+	graph := &SocialGraph{}
+	if GraphSampleSize <= 0 {
+		return graph
+	}
+
+	candidates := make([]ProfileResult, 0, len(profiles))
+	for _, profile := range profiles {
+		if graphSamplers[profile.Platform] != nil && graphSamplerAvailable(profile.Platform) {
+			candidates = append(candidates, profile)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Confidence > candidates[j].Confidence })
+	if len(candidates) > maxGraphProfiles {
+		candidates = candidates[:maxGraphProfiles]
+	}
+
+	for _, profile := range candidates {
+		username := accountHandleRaw(profile.URL)
+		if username == "" {
+			username = profile.Username
+		}
+		sample := GraphSample{Platform: profile.Platform, Profile: profile.URL, Username: username}
+		sampler := graphSamplers[profile.Platform]
+
+		for _, side := range []string{"followers", "following"} {
+			accounts, err := sampler(ctx, username, side, GraphSampleSize)
+			if err != nil {
+				graph.PartialErrors = append(graph.PartialErrors, ModuleError{
+					Module: strings.ToLower(profile.Platform) + " " + side,
+					Error:  err.Error(),
+				})
+				continue
+			}
+			if len(accounts) >= GraphSampleSize {
+				accounts = accounts[:GraphSampleSize]
+				sample.Truncated = true
+			}
+			if side == "followers" {
+				sample.Followers = accounts
+			} else {
+				sample.Following = accounts
+			}
+		}
+		if len(sample.Followers) > 0 || len(sample.Following) > 0 {
+			graph.Samples = append(graph.Samples, sample)
+		}
+	}
+
+	own := make(map[string]bool)
+	for _, profile := range profiles {
+		if handle := accountHandle(profile.URL); handle != "" {
+			own[handle] = true
+		}
+	}
+	graph.Overlaps, graph.Mutuals = networkOverlap(graph.Samples, own)
+	return graph
+}
+
+// graphSamplerAvailable reports whether a platform's follower API can be
+// called with the configured keys
+func graphSamplerAvailable(platform string) bool {
+	if platform == "Twitter" {
+		return apiKeyConfigured(APIConfig.TwitterBearerToken)
+	}
+	return true
+}
+
+// networkOverlap compares every pair of samples and lists the accounts found
+// in more than one, leaving out the target's own accounts
+func networkOverlap(samples []GraphSample, own map[string]bool) ([]GraphOverlap, []MutualAccount) {
+	networks := make([]map[string]bool, len(samples))
+	mutuals := make(map[string]*MutualAccount)
+	seenIn := make(map[string]map[int]bool)
+
+	for i, sample := range samples {
+		networks[i] = make(map[string]bool)
+		for _, side := range []struct {
+			relation string
+			accounts []string
+		}{{"follower of", sample.Followers}, {"followed by", sample.Following}} {
+			for _, account := range side.accounts {
+				key := comparableLocalPart(account)
+				if key == "" || own[key] {
+					continue
+				}
+				networks[i][key] = true
+				if seenIn[key] == nil {
+					seenIn[key] = make(map[int]bool)
+					mutuals[key] = &MutualAccount{Account: key}
+				}
+				seenIn[key][i] = true
+				m := mutuals[key]
+				m.Relations = append(m.Relations, side.relation+" "+sample.Profile)
+				if !slices.Contains(m.Handles, account) {
+					m.Handles = append(m.Handles, account)
+				}
+				if !slices.Contains(m.Platforms, sample.Platform) {
+					m.Platforms = append(m.Platforms, sample.Platform)
+				}
+			}
+		}
+	}
+
+	var overlaps []GraphOverlap
+	for i := range samples {
+		for j := i + 1; j < len(samples); j++ {
+			shared := 0
+			for key := range networks[i] {
+				if networks[j][key] {
+					shared++
+				}
+			}
+			if shared == 0 {
+				continue
+			}
+			overlaps = append(overlaps, GraphOverlap{
+				ProfileA: samples[i].Profile,
+				ProfileB: samples[j].Profile,
+				Shared:   shared,
+				Jaccard:  float64(shared) / float64(len(networks[i])+len(networks[j])-shared),
+			})
+		}
+	}
+	sort.Slice(overlaps, func(i, j int) bool { return overlaps[i].Jaccard > overlaps[j].Jaccard })
+
+	var result []MutualAccount
+	for key, m := range mutuals {
+		if len(seenIn[key]) > 1 {
+			result = append(result, *m)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i].Relations) != len(result[j].Relations) {
+			return len(result[i].Relations) > len(result[j].Relations)
+		}
+		return result[i].Account < result[j].Account
+	})
+	return overlaps, result
+}
+
+// accountHandleRaw returns the handle at the end of a profile URL as written
+func accountHandleRaw(link string) string {
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	return strings.TrimPrefix(parts[len(parts)-1], "@")
+}
+
+// githubPageSize is the most users GitHub returns per page
+const githubPageSize = 100
+
+// githubNetwork pages through GitHub's followers or following API
+func githubNetwork(ctx context.Context, username, side string, limit int) ([]string, error) {
+	var accounts []string
+	for page := 1; len(accounts) < limit; page++ {
+		var users []githubUser
+		target := fmt.Sprintf("https://api.github.com/users/%s/%s?per_page=%d&page=%d", url.PathEscape(username), side, githubPageSize, page)
+		if err := getProviderJSON(ctx, target, githubHeaders(), &users); err != nil {
+			return accounts, fmt.Errorf("github: %v", err)
+		}
+		for _, user := range users {
+			accounts = append(accounts, user.Login)
+		}
+		if len(users) < githubPageSize {
+			break
+		}
+	}
+	return accounts, nil
+}
+
+// twitterNetwork pages through the followers or following of a user on
+// Twitter's v2 API, which needs a bearer token
+func twitterNetwork(ctx context.Context, username, side string, limit int) ([]string, error) {
+	headers := map[string]string{"Authorization": "Bearer " + APIConfig.TwitterBearerToken}
+
+	var user struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := getProviderJSON(ctx, "https://api.twitter.com/2/users/by/username/"+url.PathEscape(username), headers, &user); err != nil {
+		return nil, fmt.Errorf("twitter: %v", err)
+	}
+	if user.Data.ID == "" {
+		return nil, fmt.Errorf("twitter: no user %s", username)
+	}
+
+	var accounts []string
+	token := ""
+	for len(accounts) < limit {
+		params := url.Values{}
+		params.Set("max_results", fmt.Sprint(min(1000, max(limit-len(accounts), 10))))
+		if token != "" {
+			params.Set("pagination_token", token)
+		}
+		var page struct {
+			Data []struct {
+				Username string `json:"username"`
+			} `json:"data"`
+			Meta struct {
+				NextToken string `json:"next_token"`
+			} `json:"meta"`
+		}
+		target := fmt.Sprintf("https://api.twitter.com/2/users/%s/%s?%s", user.Data.ID, side, params.Encode())
+		if err := getProviderJSON(ctx, target, headers, &page); err != nil {
+			return accounts, fmt.Errorf("twitter: %v", err)
+		}
+		for _, account := range page.Data {
+			accounts = append(accounts, account.Username)
+		}
+		if token = page.Meta.NextToken; token == "" {
+			break
+		}
+	}
+	return accounts, nil
+}
+
+// DisplayResults prints the overlaps and mutual accounts of the sampled networks
+func (g *SocialGraph) DisplayResults() {
+	color.Green("\n=== SOCIAL GRAPH ===")
+	if len(g.Samples) == 0 {
+		color.Yellow("No follower lists could be sampled")
+		return
+	}
+	for _, sample := range g.Samples {
+		note := ""
+		if sample.Truncated {
+			note = " (sampled)"
+		}
+		color.White("  %s: %d followers, %d following%s", sample.Profile, len(sample.Followers), len(sample.Following), note)
+	}
+
+	if len(g.Overlaps) > 0 {
+		color.Cyan("\n[Network Overlap]")
+		for _, overlap := range g.Overlaps {
+			color.White("  %d shared (%.2f)  %s ↔ %s", overlap.Shared, overlap.Jaccard, overlap.ProfileA, overlap.ProfileB)
+		}
+	}
+
+	if len(g.Mutuals) > 0 {
+		color.Cyan("\n[Mutual Accounts]")
+		color.Yellow("In the networks of more than one profile; likely to know the target")
+		for _, mutual := range g.Mutuals {
+			color.Yellow("  %s (%s)", strings.Join(mutual.Handles, ", "), strings.Join(mutual.Platforms, ", "))
+			for _, relation := range mutual.Relations {
+				color.White("    - %s", relation)
+			}
+		}
+	}
+}
+
+// DisplayPartialErrors prints the follower lists that could not be read
+func (g *SocialGraph) DisplayPartialErrors() {
+	displayModuleErrors(g.PartialErrors)
+}
@@ -1,9 +1,11 @@
 package osint
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"html"
 	"net/http"
 	"os"
 	"regexp"
@@ -16,13 +18,20 @@ import (
 	"sync"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/awion/MercuriesOST/public/parse"
+	"github.com/awion/MercuriesOST/public/providers"
 	"github.com/awion/MercuriesOST/public/variations"
 	"github.com/schollz/progressbar/v3"
-	"golang.org/x/sync/errgroup"
-	"golang.org/x/time/rate"
 )
 
-// SocialPlatform represents a social media platform to search
+// SocialPlatform represents a social media platform to search. Hosts lists
+// other hosts serving the same profiles, IDPatterns capture the platform's own
+// account ID from a profile page, and CanonicalURL builds a profile URL from
+// that ID where the platform has one. IDFormat names the DecodeID format of
+// IDs embedding their creation time. NamePatterns capture the display name
+// from pages that only carry it in embedded data or meta tags. KnownAccount
+// is a long-lived account checked before each scan to tell an outage from a
+// missing profile.
 type SocialPlatform struct {
 	Name                string
 	URL                 string
@@ -37,34 +46,81 @@ type SocialPlatform struct {
 	LocationSelector    string
 	ActivitySelector    string
 	ConnectionsSelector string
+	Hosts               []string
+	IDPatterns          []string
+	IDFormat            string
+	NamePatterns        []string
+	CanonicalURL        string
+	KnownAccount        string
 }
 
 // ProfileResult stores the result of a profile search
 type ProfileResult struct {
-	Platform       string   `json:"platform"`
-	URL            string   `json:"url"`
-	Exists         bool     `json:"exists"`
-	Username       string   `json:"username"`
-	FullName       string   `json:"full_name,omitempty"`
-	Bio            string   `json:"bio,omitempty"`
-	FollowerCount  int      `json:"follower_count,omitempty"`
-	JoinDate       string   `json:"join_date,omitempty"`
-	Avatar         string   `json:"avatar_url,omitempty"`
-	Location       string   `json:"location,omitempty"`
-	Connections    []string `json:"connections,omitempty"`
-	RecentActivity []string `json:"recent_activity,omitempty"`
-	Insights       []string `json:"insights,omitempty"`
-	Error          string   `json:"error,omitempty"`
+	Platform       string         `json:"platform"`
+	URL            string         `json:"url"`
+	Exists         bool           `json:"exists"`
+	Confidence     float64        `json:"confidence"` // Validation confidence, lowered for handles other than the query
+	Evidence       string         `json:"evidence,omitempty"`
+	Tier           string         `json:"tier,omitempty"`
+	Username       string         `json:"username"`
+	FullName       string         `json:"full_name,omitempty"`
+	Bio            string         `json:"bio,omitempty"`
+	FollowerCount  int            `json:"follower_count,omitempty"`
+	PlaylistCount  int            `json:"playlist_count,omitempty"`
+	Playlists      []string       `json:"playlists,omitempty"`
+	Clubs          []string       `json:"clubs,omitempty"`
+	ActivityAreas  []string       `json:"activity_areas,omitempty"` // General places of recent fitness activities
+	ExposedArea    *ExposedArea   `json:"exposed_area,omitempty"`
+	Reviews        []ReviewInfo   `json:"reviews,omitempty"` // Places reviewed, from the page's review markup
+	JoinDate       string         `json:"join_date,omitempty"`
+	Avatar         string         `json:"avatar_url,omitempty"`
+	Location       string         `json:"location,omitempty"`
+	Geo            *GeoLocation   `json:"geo,omitempty"`
+	Connections    []string       `json:"connections,omitempty"`
+	RecentActivity []string       `json:"recent_activity,omitempty"`
+	ActivityTimes  []string       `json:"activity_times,omitempty"`
+	Insights       []string       `json:"insights,omitempty"`
+	BioLinks       []URLExpansion `json:"bio_links,omitempty"`
+	Translations   []Translation  `json:"translations,omitempty"` // Bio and posts in another language, translated
+	CanonicalID    string         `json:"canonical_id,omitempty"`
+	CanonicalURL   string         `json:"canonical_url,omitempty"`
+	IDCreated      string         `json:"id_created,omitempty"`
+	VariantOf      string         `json:"variant_of,omitempty"`
+	Error          string         `json:"error,omitempty"`
 }
 
 // SocialMediaResults stores all results from a search
 type SocialMediaResults struct {
-	Query         string          `json:"query"`
-	Timestamp     string          `json:"timestamp"`
-	ProfilesFound int             `json:"profiles_found"`
-	Profiles      []ProfileResult `json:"profiles"`
+	Query         string            `json:"query"`
+	ScanID        string            `json:"scan_id,omitempty"`
+	Timestamp     string            `json:"timestamp"`
+	ProfilesFound int               `json:"profiles_found"`
+	Profiles      []ProfileResult   `json:"profiles"`
+	StyleLinks    []StyleSimilarity `json:"style_links,omitempty"` // Heuristic writing-style matches
+	Heatmap       *ActivityHeatmap  `json:"activity_heatmap,omitempty"`
+	Locations     []LocationCluster `json:"location_clusters,omitempty"`
+	Leads         []ProfileResult   `json:"leads,omitempty"`           // Profiles in the lead tier
+	History       []AccountHistory  `json:"account_history,omitempty"` // Deleted and renamed accounts under the handle
+	Graph         *SocialGraph      `json:"social_graph,omitempty"`
+	Keywords      *KeywordReport    `json:"keyword_hits,omitempty"`
+	// Platforms left out because their known account could not be found
+	Unreachable []UnreachablePlatform `json:"unreachable_platforms,omitempty"`
+	// Checkpoint of the checks that failed or were skipped, to finish them with --resume
+	Checkpoint string `json:"checkpoint,omitempty"`
+	// Why translation stopped before every profile was translated
+	TranslationError string `json:"translation_error,omitempty"`
+	// Profiles written to the output file but not kept in memory
+	OmittedProfiles int `json:"omitted_profiles,omitempty"`
+	// Set when the search was stopped before every check ran
+	Interrupted bool `json:"interrupted,omitempty"`
+	// Profiles on the suppression lists, left out of the results above
+	Suppressed []SuppressedProfile `json:"suppressed,omitempty"`
 }
 
+// ErrInterrupted is returned with the partial results of a profile search
+// whose context was cancelled, as on Ctrl-C
+var ErrInterrupted = errors.New("scan interrupted")
+
 // workItem represents a single work unit for processing
 type workItem struct {
 	platform SocialPlatform
@@ -87,6 +143,11 @@ var platforms = []SocialPlatform{
 		LocationSelector:    "[data-testid='UserLocation'], .location",
 		ActivitySelector:    "[data-testid='tweet'], .timeline-item",
 		ConnectionsSelector: ".follows-recommendations, .follows-you",
+		Hosts:               []string{"www.twitter.com", "mobile.twitter.com", "x.com", "www.x.com"},
+		IDPatterns:          []string{`"rest_id":"(\d+)"`, `data-user-id="(\d+)"`, `"user_id":"(\d+)"`},
+		IDFormat:            IDTwitter,
+		CanonicalURL:        "https://twitter.com/intent/user?user_id=%s",
+		KnownAccount:        "twitter",
 	},
 	{
 		Name:                "Instagram",
@@ -102,6 +163,9 @@ var platforms = []SocialPlatform{
 		LocationSelector:    "", // Instagram doesn't consistently show location
 		ActivitySelector:    "article, .post",
 		ConnectionsSelector: ".followed-by, .follows-you",
+		Hosts:               []string{"instagram.com", "instagr.am"},
+		IDPatterns:          []string{`"profile_id":"(\d+)"`, `"profilePage_(\d+)"`, `"user":\{"biography":".*?","id":"(\d+)"`},
+		KnownAccount:        "instagram",
 	},
 	{
 		Name:                "Facebook",
@@ -117,6 +181,10 @@ var platforms = []SocialPlatform{
 		LocationSelector:    "[data-pagelet='ProfileTilesLocation'], .location",
 		ActivitySelector:    "[data-pagelet='ProfileTimeline'] article, .timeline-item",
 		ConnectionsSelector: "[data-pagelet='ProfileFriendsCard'], .friend-card",
+		Hosts:               []string{"facebook.com", "m.facebook.com", "web.facebook.com", "fb.com"},
+		IDPatterns:          []string{`"userID":"(\d+)"`, `fb://profile/(\d+)`, `"entity_id":"(\d+)"`, `profile\.php\?id=(\d+)`},
+		CanonicalURL:        "https://www.facebook.com/profile.php?id=%s",
+		KnownAccount:        "facebook",
 	},
 	{
 		Name:                "LinkedIn",
@@ -132,6 +200,9 @@ var platforms = []SocialPlatform{
 		LocationSelector:    ".pv-top-card--list-bullet li, .location",
 		ActivitySelector:    ".activity-section article, .activity-item",
 		ConnectionsSelector: ".pv-browsemap-section__member, .connection-card",
+		Hosts:               []string{"linkedin.com"},
+		IDPatterns:          []string{`urn:li:fsd_profile:([A-Za-z0-9_-]+)`, `urn:li:member:(\d+)`},
+		KnownAccount:        "williamhgates",
 	},
 	{
 		Name:                "GitHub",
@@ -147,6 +218,10 @@ var platforms = []SocialPlatform{
 		LocationSelector:    "li[itemprop='homeLocation'], .location",
 		ActivitySelector:    ".contribution-activity-listing article, .activity-item",
 		ConnectionsSelector: ".js-org-members, .connection-card",
+		Hosts:               []string{"www.github.com"},
+		IDPatterns:          []string{`octolytics-dimension-user_id" content="(\d+)"`},
+		CanonicalURL:        "https://api.github.com/user/%s",
+		KnownAccount:        "torvalds",
 	},
 	{
 		Name:                "Reddit",
@@ -162,6 +237,9 @@ var platforms = []SocialPlatform{
 		LocationSelector:    "", // Reddit doesn't show location
 		ActivitySelector:    "div.Profile__posts article, .post",
 		ConnectionsSelector: "", // Reddit doesn't show connections prominently
+		Hosts:               []string{"reddit.com", "old.reddit.com", "new.reddit.com", "np.reddit.com"},
+		IDPatterns:          []string{`"id":\s*"(t2_[a-z0-9]+)"`, `"authorId":\s*"(t2_[a-z0-9]+)"`},
+		KnownAccount:        "spez",
 	},
 	{
 		Name:                "TikTok",
@@ -177,19 +255,225 @@ var platforms = []SocialPlatform{
 		LocationSelector:    "", // TikTok doesn't consistently show location
 		ActivitySelector:    "div.video-feed-item, .post",
 		ConnectionsSelector: "", // TikTok doesn't show connections prominently
+		Hosts:               []string{"tiktok.com", "m.tiktok.com"},
+		IDPatterns:          []string{`"userId":"(\d+)"`, `"id":"(\d{10,})","shortId"`},
+		IDFormat:            IDTikTok,
+		KnownAccount:        "tiktok",
+	},
+	{
+		Name:                "Telegram",
+		URL:                 "https://t.me/",
+		ProfilePattern:      "%s",
+		ExistMarkers:        []string{"tgme_page_title"},
+		NotExistMarkers:     []string{}, // t.me answers every name, see ValidateProfile
+		NameSelector:        ".tgme_page_title",
+		BioSelector:         ".tgme_page_description",
+		AvatarSelector:      "img.tgme_page_photo_image",
+		FollowersSelector:   ".tgme_page_extra",
+		JoinDateSelector:    "", // Telegram doesn't show join date
+		LocationSelector:    "", // Telegram doesn't show location
+		ActivitySelector:    "", // Only public channels have a preview
+		ConnectionsSelector: "", // Telegram doesn't show connections
+		Hosts:               []string{"telegram.me", "telegram.dog"},
+		IDPatterns:          []string{`tg://resolve\?domain=(\w+)`},
+		CanonicalURL:        "https://t.me/%s",
+		KnownAccount:        "telegram",
+	},
+	// Payment handles are mostly registered under real names, and the
+	// public pages show the name people pay
+	{
+		Name:                "Venmo",
+		URL:                 "https://venmo.com/u/",
+		ProfilePattern:      "%s",
+		ExistMarkers:        []string{"displayName"},
+		NotExistMarkers:     []string{}, // Venmo answers every name, see ValidateProfile
+		NameSelector:        "",         // The name is only in the page data
+		BioSelector:         "",
+		AvatarSelector:      "",
+		FollowersSelector:   "",
+		JoinDateSelector:    "",
+		LocationSelector:    "",
+		ActivitySelector:    "", // Transactions are private by default
+		ConnectionsSelector: "",
+		Hosts:               []string{"www.venmo.com", "account.venmo.com"},
+		NamePatterns:        []string{`"displayName":"((?:[^"\\]|\\.){1,80})"`, `"display_name":"((?:[^"\\]|\\.){1,80})"`},
+	},
+	{
+		Name:                "PayPal",
+		URL:                 "https://paypal.me/",
+		ProfilePattern:      "%s",
+		ExistMarkers:        []string{"PayPal.Me"},
+		NotExistMarkers:     []string{}, // Unknown names land on the PayPal.Me home page, see ValidateProfile
+		NameSelector:        "",         // The name is only in the page data
+		BioSelector:         "",
+		AvatarSelector:      "",
+		FollowersSelector:   "",
+		JoinDateSelector:    "",
+		LocationSelector:    "",
+		ActivitySelector:    "",
+		ConnectionsSelector: "",
+		Hosts:               []string{"www.paypal.me", "www.paypal.com", "paypal.com"},
+		NamePatterns:        []string{`"displayName":"((?:[^"\\]|\\.){1,80})"`, `"fullName":"((?:[^"\\]|\\.){1,80})"`, `<meta[^>]+property="og:title"[^>]+content="Pay ([^"]{1,80}?) using PayPal\.Me"`},
+	},
+	{
+		Name:                "CashApp",
+		URL:                 "https://cash.app/$",
+		ProfilePattern:      "%s",
+		ExistMarkers:        []string{"display_name"},
+		NotExistMarkers:     []string{}, // Cash App answers every cashtag, see ValidateProfile
+		NameSelector:        "",         // The name is only in the page data
+		BioSelector:         "",
+		AvatarSelector:      "",
+		FollowersSelector:   "",
+		JoinDateSelector:    "",
+		LocationSelector:    "",
+		ActivitySelector:    "",
+		ConnectionsSelector: "",
+		Hosts:               []string{"www.cash.app"},
+		NamePatterns:        []string{`"display_name":"((?:[^"\\]|\\.){1,80})"`},
+	},
+	// Music profiles are rarely cleaned up when other accounts are, and
+	// playlists and scrobbles tie them to a person; see profileLookups
+	{
+		Name:                "Spotify",
+		URL:                 "https://open.spotify.com/user/",
+		ProfilePattern:      "%s",
+		ExistMarkers:        []string{"og:title"},
+		NotExistMarkers:     []string{"Page not found", "Couldn't find that page"},
+		NameSelector:        "",
+		BioSelector:         "", // Spotify profiles have no bio
+		AvatarSelector:      "",
+		FollowersSelector:   "",
+		JoinDateSelector:    "",
+		LocationSelector:    "",
+		ActivitySelector:    "",
+		ConnectionsSelector: "",
+		Hosts:               []string{"play.spotify.com"},
+		NamePatterns:        []string{`<meta[^>]+property="og:title"[^>]+content="([^"]{1,80})"`},
+		KnownAccount:        "spotify",
+	},
+	{
+		Name:                "SoundCloud",
+		URL:                 "https://soundcloud.com/",
+		ProfilePattern:      "%s",
+		ExistMarkers:        []string{"soundcloud://users:"},
+		NotExistMarkers:     []string{"We can't find that user"},
+		NameSelector:        "",
+		BioSelector:         "",
+		AvatarSelector:      "",
+		FollowersSelector:   "",
+		JoinDateSelector:    "",
+		LocationSelector:    "",
+		ActivitySelector:    "article h2 a",
+		ConnectionsSelector: "",
+		Hosts:               []string{"www.soundcloud.com", "m.soundcloud.com"},
+		IDPatterns:          []string{`soundcloud://users:(\d+)`},
+		NamePatterns:        []string{`"full_name":"((?:[^"\\]|\\.){1,80})"`},
+		KnownAccount:        "soundcloud",
+	},
+	{
+		Name:                "LastFM",
+		URL:                 "https://www.last.fm/user/",
+		ProfilePattern:      "%s",
+		ExistMarkers:        []string{"header-title-display-name"},
+		NotExistMarkers:     []string{"Page Not Found"},
+		NameSelector:        ".header-title-display-name",
+		BioSelector:         ".about-me-sidebar p",
+		AvatarSelector:      ".header-avatar img",
+		FollowersSelector:   "",
+		JoinDateSelector:    "",
+		LocationSelector:    "",
+		ActivitySelector:    ".chartlist-name a",
+		ConnectionsSelector: "",
+		Hosts:               []string{"last.fm", "www.lastfm.com", "lastfm.com"},
+		KnownAccount:        "rj",
+	},
+	// Review histories show where someone travels and when; the places
+	// reviewed are read from the page markup, see embeddedReviews
+	{
+		Name:                "TripAdvisor",
+		URL:                 "https://www.tripadvisor.com/Profile/",
+		ProfilePattern:      "%s",
+		ExistMarkers:        []string{"Contributions"},
+		NotExistMarkers:     []string{"This page is not available", "Page not found"},
+		NameSelector:        "h1",
+		BioSelector:         "",
+		AvatarSelector:      "",
+		FollowersSelector:   "",
+		JoinDateSelector:    "span:contains('Joined')",
+		LocationSelector:    "",
+		ActivitySelector:    "",
+		ConnectionsSelector: "",
+		Hosts:               []string{"tripadvisor.com", "www.tripadvisor.co.uk", "www.tripadvisor.ca", "www.tripadvisor.com.au"},
+		NamePatterns:        []string{`"displayName":"((?:[^"\\]|\\.){1,80})"`},
+	},
+	{
+		Name:                "Airbnb",
+		URL:                 "https://www.airbnb.com/users/show/",
+		ProfilePattern:      "%s",
+		ExistMarkers:        []string{"smartName"},
+		NotExistMarkers:     []string{"Page not found", "We can't seem to find"},
+		NameSelector:        "",
+		BioSelector:         "",
+		AvatarSelector:      "",
+		FollowersSelector:   "",
+		JoinDateSelector:    "",
+		LocationSelector:    "",
+		ActivitySelector:    "",
+		ConnectionsSelector: "",
+		Hosts:               []string{"airbnb.com", "www.airbnb.co.uk", "www.airbnb.ca", "www.airbnb.com.au"},
+		NamePatterns:        []string{`"smartName":"((?:[^"\\]|\\.){1,80})"`},
+	},
+	// Fitness profiles give away clubs, training areas and, without a
+	// privacy zone, where activities start; see flagExposedArea
+	{
+		Name:                "Strava",
+		URL:                 "https://www.strava.com/athletes/",
+		ProfilePattern:      "%s",
+		ExistMarkers:        []string{"athlete-name"},
+		NotExistMarkers:     []string{"Page Not Found"},
+		NameSelector:        "h1.athlete-name",
+		BioSelector:         ".athlete-description",
+		AvatarSelector:      ".avatar-img",
+		FollowersSelector:   "",
+		JoinDateSelector:    "",
+		LocationSelector:    ".location",
+		ActivitySelector:    ".activity-name",
+		ConnectionsSelector: "",
+		Hosts:               []string{"strava.com", "strava.app.link"},
+	},
+	{
+		Name:                "GarminConnect",
+		URL:                 "https://connect.garmin.com/modern/profile/",
+		ProfilePattern:      "%s",
+		ExistMarkers:        []string{"fullName"},
+		NotExistMarkers:     []string{}, // Garmin Connect serves its app for every name, see ValidateProfile
+		NameSelector:        "",         // The name is only in the page data
+		BioSelector:         "",
+		AvatarSelector:      "",
+		FollowersSelector:   "",
+		JoinDateSelector:    "",
+		LocationSelector:    "",
+		ActivitySelector:    "", // Activities load after sign-in
+		ConnectionsSelector: "",
+		NamePatterns:        []string{`"fullName":"((?:[^"\\]|\\.){1,80})"`},
 	},
 }
 
 // Configure scanning parameters - optimized for low-end systems
 const (
-	maxConcurrentScans = 5               // Reduced from 10 to prevent overwhelming
-	scanRateLimit      = 10              // Reduced from 20 to prevent rate limits
-	batchSize          = 3               // Reduced batch size for memory efficiency
-	maxRetries         = 2               // Reduced retries to save resources
-	updateInterval     = 2 * time.Second // Reduced update frequency
-	maxWorkers         = 3               // Maximum number of workers for low-end systems
+	updateInterval = 2 * time.Second // How often the progress bar is redrawn
+	maxWorkers     = 3               // Maximum number of workers for low-end systems
+	maxBioLinks    = 3               // Shortened bio links expanded per profile
 )
 
+// ShowProgress draws the progress bar of a scan; --quiet turns it off
+var ShowProgress = true
+
+// ProfileRetries is how many times a profile check that failed is tried
+// again, waiting providers.RetryBackoff longer before each
+var ProfileRetries = 1
+
 // Add this struct for rate tracking
 type rateTracker struct {
 	mu              sync.Mutex
@@ -219,6 +503,12 @@ func (rt *rateTracker) increment() {
 	rt.mu.Unlock()
 }
 
+func (rt *rateTracker) platform() string {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.currentPlatform
+}
+
 func (rt *rateTracker) getRate() float64 {
 	rt.mu.Lock()
 	defer rt.mu.Unlock()
@@ -262,11 +552,24 @@ func (mm *memoryManager) flush() {
 	if len(mm.items) > 0 {
 		tempFile := fmt.Sprintf("dump/temp_%d.json", time.Now().UnixNano())
 		data, _ := json.Marshal(mm.items)
-		ioutil.WriteFile(tempFile, data, 0644)
+		os.WriteFile(tempFile, data, 0644)
 		mm.items = mm.items[:0] // Clear slice while preserving capacity
 	}
 }
 
+// Profile search tuning, set with --workers, --rate and --batch-size
+var (
+	// ScanWorkers is how many profile checks run at once; 0 picks it from
+	// the hardware detected
+	ScanWorkers = 0
+	// ScanRateLimit caps the profile checks made a second across every
+	// worker, low enough that most platforms do not throttle a single IP
+	ScanRateLimit = 10.0
+	// ScanBatchSize is how many profiles found are held in memory before
+	// being spilled to dump/
+	ScanBatchSize = 100
+)
+
 // Update hardware acceleration settings with combined constants
 const (
 	// Hardware acceleration settings for GPU
@@ -323,10 +626,27 @@ func detectHardware() hardwareAccelerator {
 	return acc
 }
 
-// SearchProfilesSequentially searches for a username across platforms one by one
-func SearchProfilesSequentially(username string, outputPath string, verbose bool) (*SocialMediaResults, error) {
+// scanWorkers is how many checks a scan runs at once: ScanWorkers when set,
+// otherwise what the hardware detected allows
+func scanWorkers() int {
+	if ScanWorkers > 0 {
+		return ScanWorkers
+	}
+	return detectHardware().maxWorkers
+}
+
+// SearchProfilesSequentially searches for a username across platforms one by
+// one. Cancelling ctx stops new checks, lets those in flight finish and
+// returns what was found with ErrInterrupted, saved to outputPath and to a
+// checkpoint to resume from.
+func SearchProfilesSequentially(ctx context.Context, username string, outputPath string, verbose bool) (*SocialMediaResults, error) {
+	if ScanWorkers < 0 || ScanRateLimit <= 0 || ScanBatchSize < 1 {
+		return nil, fmt.Errorf("workers cannot be negative, and the rate and batch size must be positive")
+	}
+
 	// Detect hardware capabilities
 	acc := detectHardware()
+	acc.maxWorkers = scanWorkers()
 	if verbose && (acc.hasGPU || acc.hasTPU) {
 		fmt.Printf("Hardware acceleration enabled: %s (Batch: %d, Workers: %d)\n",
 			acc.deviceName, acc.maxBatch, acc.maxWorkers)
@@ -345,21 +665,15 @@ func SearchProfilesSequentially(username string, outputPath string, verbose bool
 		ReadBufferSize:      64 * 1024,
 	}
 
-	// Create connection pool with hardware-optimized settings
-	connPool := &sync.Pool{
-		New: func() interface{} {
-			return &http.Client{
-				Timeout:   time.Second * 30, // Increased timeout
-				Transport: transport,
-			}
-		},
+	// One client is shared by every worker; http.Client is safe for concurrent use
+	client := &http.Client{
+		Timeout:   2 * RequestTimeout,
+		Transport: providers.Wrap(transport),
 	}
 
-	// Optimize rate limiter based on hardware
-	limiter := rate.NewLimiter(rate.Limit(acc.maxWorkers*2), acc.maxWorkers)
-
 	// Initialize results only once at the start
 	results := &SocialMediaResults{
+		ScanID:    providers.ScanID,
 		Query:     username,
 		Timestamp: time.Now().Format(time.RFC3339),
 		Profiles:  make([]ProfileResult, 0),
@@ -374,28 +688,43 @@ func SearchProfilesSequentially(username string, outputPath string, verbose bool
 			strings.ToLower(strings.ReplaceAll(username, " ", "-")))
 	}
 
-	// Initialize rate limiter and error group
-	limiter = rate.NewLimiter(rate.Limit(scanRateLimit), maxConcurrentScans)
-	g, ctx := errgroup.WithContext(context.Background())
-
-	// Create result channels
-	resultsChan := make(chan ProfileResult, len(platforms)*len(searchTerms))
-	errorsChan := make(chan error, maxConcurrentScans)
-
-	// Initialize work pool
-	var wg sync.WaitGroup
-
-	// Create a single work channel
-	workChan := make(chan workItem, acc.maxWorkers*2)
-
 	// Create rate tracker
 	tracker := &rateTracker{lastUpdate: time.Now()}
-	memManager := newMemoryManager(100) // Create memory manager instance
+	memManager := newMemoryManager(ScanBatchSize) // Create memory manager instance
+
+	// Platforms whose known account cannot be found are left out rather
+	// than reporting every profile on them as missing
+	pool := newScanPool(client, acc.maxWorkers)
+	results.Unreachable = pool.probe(ctx, scanPlatforms())
+	scanned := reachablePlatforms(scanPlatforms(), results.Unreachable)
+	if verbose {
+		for _, platform := range results.Unreachable {
+			fmt.Printf("Skipping %s: %s\n", platform.Platform, platform.Reason)
+		}
+	}
+
+	// Progress is saved as items are checked, and a resumed scan only runs
+	// the items its checkpoint has not recorded
+	checkpoint, err := openCheckpoint(username)
+	if err != nil {
+		return nil, err
+	}
+	items := checkpoint.pending(scanItems(scanned, searchTerms))
+	if verbose && ResumeCheckpoint != "" {
+		fmt.Printf("Resuming %s: %d checks left, %d profiles already found\n",
+			checkpoint.Path(), len(items), len(checkpoint.Hits))
+	}
+	check := pool.check
+	pool.check = func(ctx context.Context, client *http.Client, item workItem) ProfileResult {
+		result := check(ctx, client, item)
+		checkpoint.record(item, result)
+		return result
+	}
 
 	// Progress bar setup with rate display
-	totalOperations := len(platforms) * len(searchTerms)
-	bar := progressbar.NewOptions(totalOperations,
+	bar := progressbar.NewOptions(len(items),
 		progressbar.OptionSetDescription("Starting scan..."),
+		progressbar.OptionSetVisibility(ShowProgress),
 		progressbar.OptionEnableColorCodes(true),
 		progressbar.OptionShowCount(),
 		progressbar.OptionSetTheme(progressbar.Theme{
@@ -407,134 +736,232 @@ func SearchProfilesSequentially(username string, outputPath string, verbose bool
 		}),
 	)
 
-	// Start workers before feeding work items
-	for i := 0; i < acc.maxWorkers; i++ {
-		wg.Add(1)
-		g.Go(func() error {
-			defer wg.Done()
-			client := connPool.Get().(*http.Client)
-			defer connPool.Put(client)
-
-			for work := range workChan {
-				tracker.setCurrentPlatform(work.platform.Name)
-
-				if err := limiter.Wait(ctx); err != nil {
-					return err
-				}
-
-				result := processSingleProfile(client, work.platform, work.term)
-				if result.Exists {
-					resultsChan <- result
-				}
-
-				tracker.increment()
-				bar.Add(1)
-			}
-			return nil
-		})
+	pool.progress = func(item workItem) {
+		tracker.setCurrentPlatform(item.platform.Name)
+		tracker.increment()
+		bar.Add(1)
 	}
 
-	// Feed work items after workers are started
-	go func() {
-		for _, platform := range platforms {
-			for _, term := range searchTerms {
-				select {
-				case workChan <- workItem{platform: platform, term: term}:
-				case <-ctx.Done():
-					return
-				}
-			}
-		}
-		close(workChan)
-	}()
-
-	// Start rate display updater with platform information
+	// Start rate display updater with platform information, stopped once the scan ends
+	displayCtx, stopDisplay := context.WithCancel(context.Background())
+	defer stopDisplay()
 	go func() {
+		ticker := time.NewTicker(updateInterval)
+		defer ticker.Stop()
 		for {
 			select {
-			case <-ctx.Done():
+			case <-displayCtx.Done():
 				return
-			default:
-				time.Sleep(updateInterval)
+			case <-ticker.C:
 				tracker.update()
-				platform := tracker.currentPlatform
-				if platform != "" {
+				if platform := tracker.platform(); platform != "" {
 					bar.Describe(fmt.Sprintf("[cyan]Scanning %s[reset] (%.1f profiles/s)",
 						platform, tracker.getRate()))
 				}
 			}
 		}
 	}()
+	go checkpoint.autosave(displayCtx)
 
-	// Wait for all workers to complete
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-		close(errorsChan)
-	}()
-
-	// Wait for error group completion
-	if err := g.Wait(); err != nil {
-		return nil, fmt.Errorf("worker error: %v", err)
+	// Results are written as they are collected when saving, so the file
+	// holds every profile even when memory only keeps the first ones
+	var stream *ProfileStream
+	if outputPath != "" {
+		if stream, err = NewProfileStream(outputPath, results); err != nil {
+			return nil, fmt.Errorf("error saving results: %v", err)
+		}
+		defer stream.Abort()
 	}
 
-	// Collect results
 	processedProfiles := make(map[string]bool)
-	for result := range resultsChan {
+	keywords := NewKeywordWatch()
+	translator := NewTranslator()
+	queried := strings.ToLower(strings.ReplaceAll(username, " ", ""))
+	keep := func(result ProfileResult) {
+		scoreProfile(queried, &result)
+		translateProfile(context.Background(), translator, &result)
+
+		classifyProfile(&result)
+		if rule := ActiveSuppressions.Profile(result); rule != nil {
+			results.Suppressed = append(results.Suppressed, SuppressedProfile{Profile: result, SuppressedBy: *rule})
+			return
+		}
+		lead := result.Tier == TierLead
+		if stream != nil {
+			stream.Add(result, lead)
+		}
+		RunHook(HookProfileFound, "social", result)
+		switch {
+		case lead:
+			if stream == nil || len(results.Leads) < maxInMemoryProfiles {
+				results.Leads = append(results.Leads, result)
+			}
+		case stream == nil || len(results.Profiles) < maxInMemoryProfiles:
+			results.ProfilesFound++
+			results.Profiles = append(results.Profiles, result)
+		default:
+			results.ProfilesFound++
+			results.OmittedProfiles++
+		}
+		memManager.add(result) // Now memManager is defined
+		keywords.Scan("profile", result)
+
+		if verbose {
+			printProfileDetails(&result)
+		}
+	}
+
+	// Nominatim allows one request a second, so profiles with places to
+	// geocode are held back until the scan is done rather than stalling the
+	// workers behind the collector
+	var toGeocode []ProfileResult
+	collect := func(result ProfileResult) {
 		// Skip duplicate profiles
-		if processedProfiles[result.URL] {
-			continue
+		if !result.Exists || processedProfiles[result.URL] {
+			return
 		}
 		processedProfiles[result.URL] = true
 
-		if result.Exists {
-			results.ProfilesFound++
-			memManager.add(result) // Now memManager is defined
-			results.Profiles = append(results.Profiles, result)
+		if GeocodeLocations && (result.Location != "" || len(result.Reviews) > 0) {
+			toGeocode = append(toGeocode, result)
+			return
+		}
+		keep(result)
+	}
 
-			if verbose {
-				printProfileDetails(&result)
+	// keepGeocoded geocodes and keeps the held-back profiles. An interrupted
+	// scan keeps them without their places.
+	keepGeocoded := func() {
+		for _, result := range toGeocode {
+			if ctx.Err() == nil && result.Location != "" {
+				if place, err := Geocode(ctx, result.Location); err == nil {
+					result.Geo = place
+				}
+			}
+			if ctx.Err() == nil {
+				geocodeReviews(ctx, result.Reviews)
 			}
+			keep(result)
 		}
+		toGeocode = nil
 	}
 
-	// Flush any remaining results before returning
-	memManager.flush() // Now memManager is defined
+	// Profiles found before the scan was interrupted are collected again
+	for _, result := range checkpoint.Hits {
+		collect(result)
+	}
 
-	// Check for errors
-	if len(errorsChan) > 0 {
-		return results, fmt.Errorf("encountered %d errors during scanning", len(errorsChan))
+	// Collect results while the workers run
+	err = pool.run(ctx, items, func(_ workItem, result ProfileResult) {
+		collect(result)
+	})
+	stopDisplay()
+	results.Interrupted = ctx.Err() != nil
+	if err != nil && !results.Interrupted {
+		checkpoint.save()
+		return nil, fmt.Errorf("worker error: %v (resume with --resume %s)", err, checkpoint.Path())
 	}
 
+	// The checkpoint is kept while any check failed, was skipped or was on an
+	// unreachable platform. A dry run checked nothing worth resuming.
+	if !providers.Planning() && len(checkpoint.pending(scanItems(scanPlatforms(), searchTerms))) > 0 {
+		if checkpoint.save() == nil {
+			results.Checkpoint = checkpoint.Path()
+		}
+	} else {
+		checkpoint.remove()
+	}
+
+	keepGeocoded()
+
+	// Scan near-variants of the handles found. An interrupted scan skips
+	// this and the other lookups made after the search.
+	if ExpandHandles && !results.Interrupted && len(results.Profiles) > 0 {
+		searched := make(map[string]bool)
+		for _, term := range searchTerms {
+			searched[strings.ToLower(strings.ReplaceAll(term, " ", ""))] = true
+		}
+		variants := expandHandles(context.Background(), client, scanned, results.Profiles, searched)
+		if verbose {
+			fmt.Printf("\nFound %d profiles under near-variant handles\n", len(variants))
+		}
+		for _, result := range variants {
+			collect(result)
+		}
+		keepGeocoded()
+	}
+
+	// Flush any remaining results before returning
+	memManager.flush() // Now memManager is defined
+
 	// Sort profiles by platform name for consistent output
 	sort.Slice(results.Profiles, func(i, j int) bool {
 		return results.Profiles[i].Platform < results.Profiles[j].Platform
 	})
+	sort.SliceStable(results.Leads, func(i, j int) bool { return results.Leads[i].Confidence > results.Leads[j].Confidence })
+
+	results.StyleLinks = CompareWritingStyles(results.Profiles)
+
+	if GeocodeLocations {
+		results.Locations = ClusterProfileLocations(results.Profiles)
+	}
+
+	if SampleSocialGraph && !results.Interrupted && len(results.Profiles) > 0 {
+		results.Graph = SampleProfileNetworks(context.Background(), results.Profiles)
+	}
+
+	if CheckAccountHistory && !results.Interrupted {
+		results.History = accountHistoryChecks(context.Background(), username, results.Profiles)
+	}
+
+	if err := translator.Err(); err != nil {
+		results.TranslationError = err.Error()
+	}
+
+	if keywords != nil {
+		keywords.Scan("account_history", results.History)
+		keywords.Scan("social_graph", results.Graph)
+		results.Keywords = keywords.Report()
+	}
+
+	if times := ProfileActivityTimes(results.Profiles); len(times) > 0 {
+		results.Heatmap = BuildActivityHeatmap(username, times)
+		for _, err := range results.Heatmap.compareHints(context.Background()) {
+			if verbose {
+				fmt.Printf("Timezone %v\n", err)
+			}
+		}
+	}
 
 	// Save results
-	if outputPath != "" {
-		if err := saveResults(results, outputPath); err != nil {
+	if stream != nil {
+		if err := stream.Close(results); err != nil {
 			return results, fmt.Errorf("error saving results: %v", err)
 		}
 	}
 
+	if results.Interrupted {
+		return results, ErrInterrupted
+	}
 	return results, nil
 }
 
 // Update processSingleProfile to remove verbose parameter in checkProfile call
-func processSingleProfile(client *http.Client, platform SocialPlatform, term string) ProfileResult {
+func processSingleProfile(ctx context.Context, client *http.Client, platform SocialPlatform, term string) ProfileResult {
 	var result ProfileResult
 
-	for retry := 0; retry < maxRetries; retry++ {
+	for retry := 0; retry <= ProfileRetries; retry++ {
 		urlTerm := strings.ToLower(strings.ReplaceAll(term, " ", ""))
 		profileURL := platform.URL + fmt.Sprintf(platform.ProfilePattern, urlTerm)
 
 		result = checkProfile(client, platform, profileURL, term) // Remove verbose parameter
-		if result.Error == "" {
+		if result.Error == "" || providers.Spent() {
 			break
 		}
 
-		time.Sleep(time.Second * time.Duration(retry+1))
+		if retry < ProfileRetries && sleepContext(ctx, providers.RetryBackoff*time.Duration(retry+1)) != nil {
+			break
+		}
 	}
 
 	return result
@@ -547,11 +974,20 @@ func checkProfile(client *http.Client, platform SocialPlatform, url string, user
 		URL:            url,
 		Username:       username,
 		Exists:         false,
+		Evidence:       EvidenceScraped,
 		Connections:    []string{},
 		RecentActivity: []string{},
 		Insights:       []string{},
 	}
 
+	// Platforms with a lookup are read from their API or page data first
+	if err := lookupProfile(platform, url, &result); !errors.Is(err, errNoProfileLookup) {
+		if err != nil {
+			result.Error = err.Error()
+		}
+		return result
+	}
+
 	// Validate the profile
 	validation := ValidateProfile(client, platform, url, "")
 
@@ -562,6 +998,7 @@ func checkProfile(client *http.Client, platform SocialPlatform, url string, user
 
 	if validation.IsValid {
 		result.Exists = true
+		result.Confidence = min(validation.Confidence, 1)
 		result.Insights = append(result.Insights, fmt.Sprintf("Profile validation confidence: %.2f", validation.Confidence))
 		for _, marker := range validation.Markers {
 			result.Insights = append(result.Insights, fmt.Sprintf("Validation marker: %s", marker))
@@ -588,7 +1025,15 @@ func checkProfile(client *http.Client, platform SocialPlatform, url string, user
 		defer resp.Body.Close()
 
 		// Parse the HTML response
-		doc, err := goquery.NewDocumentFromReader(resp.Body)
+		body, err := readPageBody(resp)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		if body.Truncated {
+			result.Insights = append(result.Insights, fmt.Sprintf("Page truncated at %d bytes, details past that point were not extracted", MaxBodySize))
+		}
+		doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body.Data))
 		if err != nil {
 			result.Error = err.Error()
 			return result
@@ -597,10 +1042,20 @@ func checkProfile(client *http.Client, platform SocialPlatform, url string, user
 		// Extract profile information
 		extractProfileInfo(doc, &result, platform)
 		extractRecentActivity(doc, &result, platform)
+		extractActivityTimes(doc, &result)
 		extractConnections(doc, &result, platform)
 
+		// Vanity names change; the platform's own ID does not
+		if page, err := doc.Html(); err == nil {
+			resolveCanonicalProfile(&result, platform, page)
+			result.Reviews = embeddedReviews(page)
+		}
+
 		// Add insights after extracting profile information
 		extractInsights(&result)
+
+		// Shortened links in bios hide where the user sends visitors
+		result.BioLinks = expandShortLinks(ctx, result.Bio, maxBioLinks)
 	}
 
 	return result
@@ -609,6 +1064,12 @@ func checkProfile(client *http.Client, platform SocialPlatform, url string, user
 // Helper function to print profile details
 func printProfileDetails(result *ProfileResult) {
 	fmt.Printf("  Username: %s\n", result.Username)
+	if result.CanonicalID != "" {
+		fmt.Printf("  Account ID: %s (%s)\n", result.CanonicalID, result.CanonicalURL)
+	}
+	if result.IDCreated != "" {
+		fmt.Printf("  Created (from ID): %s\n", result.IDCreated)
+	}
 	if result.FullName != "" {
 		fmt.Printf("  Full Name: %s\n", result.FullName)
 	}
@@ -621,6 +1082,37 @@ func printProfileDetails(result *ProfileResult) {
 	if result.Location != "" {
 		fmt.Printf("  Location: %s\n", result.Location)
 	}
+	if result.PlaylistCount > 0 {
+		fmt.Printf("  Playlists: %d\n", result.PlaylistCount)
+	}
+	for _, playlist := range result.Playlists {
+		fmt.Printf("   - %s\n", playlist)
+	}
+	if len(result.Clubs) > 0 {
+		fmt.Printf("  Clubs: %s\n", strings.Join(result.Clubs, ", "))
+	}
+	if len(result.ActivityAreas) > 0 {
+		fmt.Printf("  Activity areas: %s\n", strings.Join(result.ActivityAreas, ", "))
+	}
+	if area := result.ExposedArea; area != nil {
+		fmt.Printf("  Exposed area: %d activities start near %.3f, %.3f\n", area.Activities, area.Lat, area.Lon)
+	}
+	if len(result.Reviews) > 0 {
+		fmt.Printf("  Reviews: %d\n", len(result.Reviews))
+		for _, review := range result.Reviews {
+			line := "   - " + review.Location
+			if review.Rating > 0 {
+				line += fmt.Sprintf(" (%d★)", review.Rating)
+			}
+			if review.ReviewDate != "" {
+				line += ", " + review.ReviewDate
+			}
+			fmt.Println(line)
+		}
+	}
+	for _, link := range result.BioLinks {
+		fmt.Printf("  Bio link: %s -> %s\n", link.URL, link.FinalURL)
+	}
 	if len(result.Insights) > 0 {
 		fmt.Println("  Insights:")
 		for _, insight := range result.Insights {
@@ -640,6 +1132,12 @@ func extractProfileInfo(doc *goquery.Document, result *ProfileResult, platform S
 		})
 	}
 
+	if result.FullName == "" && len(platform.NamePatterns) > 0 {
+		if page, err := doc.Html(); err == nil {
+			result.FullName = pageDisplayName(platform, page)
+		}
+	}
+
 	// Extract bio
 	if platform.BioSelector != "" {
 		doc.Find(platform.BioSelector).Each(func(i int, s *goquery.Selection) {
@@ -665,16 +1163,8 @@ func extractProfileInfo(doc *goquery.Document, result *ProfileResult, platform S
 		doc.Find(platform.FollowersSelector).Each(func(i int, s *goquery.Selection) {
 			text := s.Text()
 			if strings.Contains(strings.ToLower(text), "follower") {
-				// Extract numbers from the text
-				re := regexp.MustCompile(`(\d+(?:[,.]\d+)?)`)
-				matches := re.FindStringSubmatch(text)
-				if len(matches) > 0 {
-					// Remove commas and convert to int
-					numStr := strings.ReplaceAll(matches[1], ",", "")
-					numStr = strings.ReplaceAll(numStr, ".", "")
-					var num int
-					fmt.Sscanf(numStr, "%d", &num)
-					result.FollowerCount = num
+				if count, err := parse.Quantity(text); err == nil {
+					result.FollowerCount = int(count)
 				}
 			}
 		})
@@ -687,9 +1177,13 @@ func extractProfileInfo(doc *goquery.Document, result *ProfileResult, platform S
 			if strings.Contains(strings.ToLower(text), "join") ||
 				strings.Contains(strings.ToLower(text), "creat") || // Fixed from contains to Contains
 				s.Is("relative-time") {
-				// Save the join date text or timestamp attribute
+				// Prefer the timestamp attribute, then a date read from the
+				// text, keeping the text itself when it cannot be read
 				if timestamp, exists := s.Attr("datetime"); exists {
-					result.JoinDate = timestamp
+					text = timestamp
+				}
+				if joined, err := parse.Date(text, time.Now()); err == nil {
+					result.JoinDate = joined.Format("2006-01-02")
 				} else {
 					result.JoinDate = cleanText(text)
 				}
@@ -793,6 +1287,10 @@ func extractInsights(result *ProfileResult) {
 		result.Insights = append(result.Insights, "Has professional online presence")
 	}
 
+	if insight := travelInsight(result.Reviews); insight != "" {
+		result.Insights = append(result.Insights, insight)
+	}
+
 	// Check for social influence
 	if result.FollowerCount > 1000 {
 		result.Insights = append(result.Insights, fmt.Sprintf("Social influence: %d+ followers on %s", result.FollowerCount, result.Platform))
@@ -833,8 +1331,14 @@ func extractInsights(result *ProfileResult) {
 
 // cleanText removes extra whitespace and cleans up text
 func cleanText(text string) string {
-	// Replace newlines with spaces
+	// Decode entities, twice for pages that escape their own markup again
+	for i := 0; i < 2 && strings.Contains(text, "&"); i++ {
+		text = html.UnescapeString(text)
+	}
+
+	// Replace newlines and non-breaking spaces with spaces
 	text = strings.ReplaceAll(text, "\n", " ")
+	text = strings.ReplaceAll(text, "\u00a0", " ")
 
 	// Replace multiple spaces with a single space
 	re := regexp.MustCompile(`\s+`)
@@ -844,16 +1348,6 @@ func cleanText(text string) string {
 	return strings.TrimSpace(text)
 }
 
-// saveResults saves the search results to a JSON file
-func saveResults(results *SocialMediaResults, outputPath string) error {
-	resultsJSON, err := json.MarshalIndent(results, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return ioutil.WriteFile(outputPath, resultsJSON, 0644)
-}
-
 // Add these helper functions
 func getSystemMemory() uint64 {
 	var memStats runtime.MemStats
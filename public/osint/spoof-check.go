@@ -0,0 +1,507 @@
+package osint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+	"golang.org/x/net/publicsuffix"
+)
+
+// SpoofSenders are the sending IPs and domains the domain module simulates
+// mail from, claiming to be the target domain. A domain stands for a sending
+// service, such as a mail provider's SPF domain, and its servers are read
+// from its SPF record. Nothing is sent; every check is a DNS lookup.
+var SpoofSenders []string
+
+// spfLookupLimit is the number of DNS-querying terms an SPF evaluation may
+// use before it fails with permerror (RFC 7208, section 4.6.4)
+const spfLookupLimit = 10
+
+// maxSenderAddresses caps the addresses of a sending service tested against
+// the target's SPF record
+const maxSenderAddresses = 20
+
+// SPF results, best first
+var spfResultRank = map[string]int{"pass": 0, "neutral": 1, "none": 2, "softfail": 3, "fail": 4, "temperror": 5, "permerror": 6}
+
+// SpoofCheck is whether mail from one sender claiming to be the target
+// domain would pass SPF, DKIM alignment and DMARC
+type SpoofCheck struct {
+	Sender      string   `json:"sender"`
+	SenderIPs   []string `json:"sender_ips,omitempty"`
+	SPF         string   `json:"spf"` // The target's SPF result for the sender's servers
+	SPFAligned  bool     `json:"spf_aligned"`
+	DKIMAligned bool     `json:"dkim_aligned"`
+	DMARCPolicy string   `json:"dmarc_policy,omitempty"` // none, quarantine or reject; empty without DMARC
+	PassesDMARC bool     `json:"passes_dmarc"`
+	Exposure    string   `json:"exposure"` // high, medium or low
+	Reasons     []string `json:"reasons"`
+}
+
+// spfResolver is the DNS lookups spoofing checks need
+type spfResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// newSPFResolver returns the resolver the email module uses
+func newSPFResolver() spfResolver {
+	return providers.NewResolver("8.8.8.8:53")
+}
+
+// CheckSpoofing simulates mail from every sender claiming to be domain
+func CheckSpoofing(ctx context.Context, domain string, senders []string) ([]SpoofCheck, error) {
+	resolver := newSPFResolver()
+	policy, err := lookupDMARC(ctx, resolver, domain)
+	if err != nil {
+		return nil, err
+	}
+	var checks []SpoofCheck
+	for _, sender := range senders {
+		checks = append(checks, checkSpoofSender(ctx, resolver, domain, policy, sender))
+	}
+	return checks, nil
+}
+
+// dmarcPolicy is the parsed DMARC record governing a domain
+type dmarcPolicy struct {
+	policy string // Empty when there is no record
+	strictSPF,
+	strictDKIM bool
+	percent int
+}
+
+// lookupDMARC reads the DMARC record of domain, falling back to its
+// organizational domain's subdomain policy
+func lookupDMARC(ctx context.Context, resolver spfResolver, domain string) (dmarcPolicy, error) {
+	tags, err := dmarcRecord(ctx, resolver, domain)
+	if err != nil {
+		return dmarcPolicy{}, err
+	}
+	policyTag := "p"
+	if tags == nil {
+		if org := organizationalDomain(domain); org != domain {
+			if tags, err = dmarcRecord(ctx, resolver, org); err != nil {
+				return dmarcPolicy{}, err
+			}
+			if tags["sp"] != "" {
+				policyTag = "sp"
+			}
+		}
+	}
+	if tags == nil {
+		return dmarcPolicy{}, nil
+	}
+	policy := dmarcPolicy{
+		policy:     strings.ToLower(tags[policyTag]),
+		strictSPF:  strings.EqualFold(tags["aspf"], "s"),
+		strictDKIM: strings.EqualFold(tags["adkim"], "s"),
+		percent:    100,
+	}
+	if pct, err := strconv.Atoi(tags["pct"]); err == nil && pct >= 0 && pct < 100 {
+		policy.percent = pct
+	}
+	switch policy.policy {
+	case "none", "quarantine", "reject":
+	default:
+		// An unknown policy is treated as none by receivers
+		policy.policy = "none"
+	}
+	return policy, nil
+}
+
+// dmarcRecord returns the tags of the DMARC record at _dmarc.domain, or nil
+// when there is none
+func dmarcRecord(ctx context.Context, resolver spfResolver, domain string) (map[string]string, error) {
+	records, err := resolver.LookupTXT(ctx, "_dmarc."+domain)
+	if err != nil {
+		if isNotFoundDNSError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("DMARC lookup failed: %v", err)
+	}
+	for _, record := range records {
+		if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(record)), "v=dmarc1") {
+			continue
+		}
+		tags := make(map[string]string)
+		for _, tag := range strings.Split(record, ";") {
+			if key, value, ok := strings.Cut(tag, "="); ok {
+				tags[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+			}
+		}
+		return tags, nil
+	}
+	return nil, nil
+}
+
+// organizationalDomain returns the registered domain DMARC aligns on
+func organizationalDomain(domain string) string {
+	if org, err := publicsuffix.EffectiveTLDPlusOne(domain); err == nil {
+		return org
+	}
+	return domain
+}
+
+// aligned reports whether an authenticated domain aligns with the From
+// domain, exactly in strict mode or by organizational domain in relaxed mode
+func aligned(authenticated, from string, strict bool) bool {
+	if strict {
+		return strings.EqualFold(authenticated, from)
+	}
+	return strings.EqualFold(organizationalDomain(authenticated), organizationalDomain(from))
+}
+
+// checkSpoofSender simulates mail from one sender, an IP or a sending
+// service's domain, with the target in the From header
+func checkSpoofSender(ctx context.Context, resolver spfResolver, domain string, policy dmarcPolicy, sender string) SpoofCheck {
+	check := SpoofCheck{Sender: sender, DMARCPolicy: policy.policy}
+	sender = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(sender), "."))
+
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(sender); err == nil {
+		addrs = []netip.Addr{addr.Unmap()}
+	} else {
+		eval := &spfEval{ctx: ctx, resolver: resolver}
+		addrs = eval.addresses(sender, maxSenderAddresses)
+		if len(addrs) == 0 {
+			check.Reasons = append(check.Reasons, fmt.Sprintf("%s lists no sending servers in SPF", sender))
+		}
+		// The sender can use its own domain as envelope sender and DKIM signer
+		check.SPFAligned = aligned(sender, domain, policy.strictSPF)
+		check.DKIMAligned = aligned(sender, domain, policy.strictDKIM)
+		if check.SPFAligned || check.DKIMAligned {
+			check.Reasons = append(check.Reasons, fmt.Sprintf("%s aligns with %s, so its own SPF and DKIM count for DMARC", sender, domain))
+		}
+	}
+	for _, addr := range addrs {
+		check.SenderIPs = append(check.SenderIPs, addr.String())
+	}
+
+	// With the target as envelope sender, the target's own SPF record decides
+	check.SPF = "none"
+	for _, addr := range addrs {
+		eval := &spfEval{ctx: ctx, resolver: resolver}
+		result, err := eval.check(domain, addr)
+		if err != nil && len(check.Reasons) < 5 {
+			check.Reasons = append(check.Reasons, err.Error())
+		}
+		if spfResultRank[result] < spfResultRank[check.SPF] || check.SPF == "none" && result != "none" {
+			check.SPF = result
+		}
+		if result == "pass" {
+			check.Reasons = append(check.Reasons, fmt.Sprintf("%s's SPF record authorizes %s", domain, addr))
+			break
+		}
+	}
+	if check.SPF == "pass" {
+		check.SPFAligned = true
+	}
+	check.PassesDMARC = check.SPFAligned || check.DKIMAligned
+
+	switch {
+	case check.PassesDMARC:
+		check.Exposure = "high"
+		check.Reasons = append(check.Reasons, "Mail claiming to be "+domain+" would pass DMARC")
+	case policy.policy == "" || policy.policy == "none":
+		check.Exposure = "medium"
+		check.Reasons = append(check.Reasons, "Mail would fail DMARC, but no policy tells receivers to reject it")
+	case policy.percent < 100:
+		check.Exposure = "medium"
+		check.Reasons = append(check.Reasons, fmt.Sprintf("Mail would fail DMARC, and the %s policy applies to only %d%% of it", policy.policy, policy.percent))
+	default:
+		check.Exposure = "low"
+		check.Reasons = append(check.Reasons, fmt.Sprintf("Mail would fail DMARC and receivers are told to %s it", policy.policy))
+	}
+	return check
+}
+
+// spfEval evaluates SPF records within one lookup budget. Macros, exists and
+// ptr cannot be simulated and never match.
+type spfEval struct {
+	ctx      context.Context
+	resolver spfResolver
+	lookups  int
+}
+
+// errSPFLookupLimit is returned when a record needs more lookups than allowed
+var errSPFLookupLimit = errors.New("SPF record needs more than 10 DNS lookups")
+
+func (e *spfEval) lookup() error {
+	e.lookups++
+	if e.lookups > spfLookupLimit {
+		return errSPFLookupLimit
+	}
+	return nil
+}
+
+// record returns the SPF record of domain, or "" when it has none
+func (e *spfEval) record(domain string) (string, error) {
+	records, err := e.resolver.LookupTXT(e.ctx, domain)
+	if err != nil {
+		if isNotFoundDNSError(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	var found []string
+	for _, record := range records {
+		if lower := strings.ToLower(record); lower == "v=spf1" || strings.HasPrefix(lower, "v=spf1 ") {
+			found = append(found, record)
+		}
+	}
+	if len(found) > 1 {
+		return "", fmt.Errorf("%s has %d SPF records", domain, len(found))
+	}
+	if len(found) == 0 {
+		return "", nil
+	}
+	return found[0], nil
+}
+
+// check returns the SPF result for addr sending with domain as envelope
+// sender: pass, fail, softfail, neutral, none, temperror or permerror
+func (e *spfEval) check(domain string, addr netip.Addr) (string, error) {
+	record, err := e.record(domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) {
+			return "temperror", err
+		}
+		return "permerror", err
+	}
+	if record == "" {
+		return "none", nil
+	}
+
+	redirect := ""
+	for _, term := range strings.Fields(record)[1:] {
+		if name, value, ok := strings.Cut(term, "="); ok && !strings.ContainsAny(name, ":/") {
+			if strings.EqualFold(name, "redirect") {
+				redirect = value
+			}
+			continue
+		}
+		qualifier := "+"
+		if strings.ContainsAny(term[:1], "+-~?") {
+			qualifier, term = term[:1], term[1:]
+		}
+		matched, err := e.matches(domain, term, addr)
+		if err != nil {
+			if errors.Is(err, errSPFLookupLimit) {
+				return "permerror", err
+			}
+			return "temperror", err
+		}
+		if matched {
+			return map[string]string{"+": "pass", "-": "fail", "~": "softfail", "?": "neutral"}[qualifier], nil
+		}
+	}
+	if redirect != "" && !strings.Contains(redirect, "%") {
+		if err := e.lookup(); err != nil {
+			return "permerror", err
+		}
+		result, err := e.check(redirect, addr)
+		if result == "none" {
+			return "permerror", fmt.Errorf("redirect to %s, which has no SPF record", redirect)
+		}
+		return result, err
+	}
+	return "neutral", nil
+}
+
+// matches reports whether one mechanism matches addr
+func (e *spfEval) matches(domain, term string, addr netip.Addr) (bool, error) {
+	mechanism, arg, _ := strings.Cut(term, ":")
+	mechanism, cidr, _ := strings.Cut(mechanism, "/")
+	mechanism = strings.ToLower(mechanism)
+	if cidr != "" {
+		arg += "/" + cidr
+	}
+	if strings.Contains(arg, "%") {
+		return false, nil
+	}
+
+	switch mechanism {
+	case "all":
+		return true, nil
+	case "ip4", "ip6":
+		prefix, ok := parseSPFNetwork(arg)
+		return ok && prefix.Contains(addr), nil
+	case "a", "mx":
+		if err := e.lookup(); err != nil {
+			return false, err
+		}
+		host, v4, v6 := splitDualCIDR(arg, domain)
+		hosts := []string{host}
+		if mechanism == "mx" {
+			mxs, err := e.resolver.LookupMX(e.ctx, host)
+			if err != nil && !isNotFoundDNSError(err) {
+				return false, err
+			}
+			hosts = hosts[:0]
+			for _, mx := range mxs {
+				hosts = append(hosts, strings.TrimSuffix(mx.Host, "."))
+			}
+		}
+		for _, host := range hosts {
+			addrs, err := e.resolver.LookupNetIP(e.ctx, "ip", host)
+			if err != nil && !isNotFoundDNSError(err) {
+				return false, err
+			}
+			for _, a := range addrs {
+				bits := v6
+				if a.Unmap().Is4() {
+					bits = v4
+				}
+				if prefix, err := a.Unmap().Prefix(bits); err == nil && prefix.Contains(addr) {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	case "include":
+		if err := e.lookup(); err != nil {
+			return false, err
+		}
+		result, err := e.check(arg, addr)
+		switch result {
+		case "pass":
+			return true, nil
+		case "none":
+			return false, fmt.Errorf("include:%s has no SPF record", arg)
+		case "permerror", "temperror":
+			return false, err
+		}
+		return false, nil
+	case "exists", "ptr":
+		return false, e.lookup()
+	}
+	return false, fmt.Errorf("unknown SPF mechanism %q", term)
+}
+
+// addresses lists up to limit addresses a sending service's SPF record
+// authorizes, the first of each network it lists
+func (e *spfEval) addresses(domain string, limit int) []netip.Addr {
+	var addrs []netip.Addr
+	var walk func(domain string)
+	walk = func(domain string) {
+		record, err := e.record(domain)
+		if err != nil || record == "" {
+			return
+		}
+		for _, term := range strings.Fields(record)[1:] {
+			if len(addrs) >= limit {
+				return
+			}
+			term = strings.TrimLeft(term, "+~?")
+			if strings.HasPrefix(term, "-") {
+				continue
+			}
+			if name, value, ok := strings.Cut(term, "="); ok && !strings.ContainsAny(name, ":/") {
+				if strings.EqualFold(name, "redirect") && e.lookup() == nil {
+					walk(value)
+				}
+				continue
+			}
+			mechanism, arg, _ := strings.Cut(term, ":")
+			mechanism, cidr, _ := strings.Cut(mechanism, "/")
+			switch strings.ToLower(mechanism) {
+			case "ip4", "ip6":
+				if prefix, ok := parseSPFNetwork(arg); ok {
+					addrs = append(addrs, prefix.Addr())
+				}
+			case "include":
+				if e.lookup() == nil {
+					walk(arg)
+				}
+			case "a":
+				if e.lookup() != nil {
+					continue
+				}
+				if cidr != "" {
+					arg += "/" + cidr
+				}
+				host, _, _ := splitDualCIDR(arg, domain)
+				if found, err := e.resolver.LookupNetIP(e.ctx, "ip", host); err == nil {
+					for _, a := range found {
+						addrs = append(addrs, a.Unmap())
+					}
+				}
+			}
+		}
+	}
+	walk(domain)
+	if len(addrs) > limit {
+		addrs = addrs[:limit]
+	}
+	return addrs
+}
+
+// parseSPFNetwork parses the address or network of an ip4 or ip6 mechanism
+func parseSPFNetwork(arg string) (netip.Prefix, bool) {
+	if prefix, err := netip.ParsePrefix(arg); err == nil {
+		return prefix.Masked(), true
+	}
+	if addr, err := netip.ParseAddr(arg); err == nil {
+		return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), true
+	}
+	return netip.Prefix{}, false
+}
+
+// splitDualCIDR splits the argument of an a or mx mechanism, such as
+// "example.com/24//64", into its host and IPv4 and IPv6 prefix lengths
+func splitDualCIDR(arg, domain string) (string, int, int) {
+	host, v4, v6 := arg, 32, 128
+	if i := strings.Index(arg, "/"); i >= 0 {
+		host = arg[:i]
+		v4part, v6part, _ := strings.Cut(arg[i+1:], "//")
+		if strings.HasPrefix(arg[i:], "//") {
+			v4part, v6part = "", arg[i+2:]
+		}
+		if n, err := strconv.Atoi(v4part); err == nil && n >= 0 && n <= 32 {
+			v4 = n
+		}
+		if n, err := strconv.Atoi(v6part); err == nil && n >= 0 && n <= 128 {
+			v6 = n
+		}
+	}
+	if host == "" {
+		host = domain
+	}
+	return host, v4, v6
+}
+
+// DisplaySpoofChecks prints the spoofing simulation of a domain
+func DisplaySpoofChecks(domain string, checks []SpoofCheck) {
+	if len(checks) == 0 {
+		return
+	}
+	color.Cyan("\n[Spoofing Exposure]")
+	for _, check := range checks {
+		dmarc := "fail"
+		if check.PassesDMARC {
+			dmarc = "pass"
+		}
+		line := fmt.Sprintf("• %s as %s: SPF %s, DMARC %s", check.Sender, domain, check.SPF, dmarc)
+		switch check.Exposure {
+		case "high":
+			color.Red("%s (exposure high)", line)
+		case "medium":
+			color.Yellow("%s (exposure medium)", line)
+		default:
+			color.Green("%s (exposure low)", line)
+		}
+		for _, reason := range check.Reasons {
+			color.White("  - %s", reason)
+		}
+	}
+}
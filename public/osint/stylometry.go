@@ -0,0 +1,157 @@
+package osint
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Stylometry needs enough text to mean anything
+const (
+	minStylePosts = 3
+	minStyleWords = 30
+)
+
+// styleFunctionWords are frequent words whose rates are a habit of the writer
+// rather than of the topic
+var styleFunctionWords = []string{
+	"the", "a", "an", "and", "but", "or", "so", "of", "to", "in", "on", "at", "for", "with",
+	"is", "are", "was", "be", "have", "just", "really", "very", "i", "you", "we", "it",
+	"this", "that", "not", "lol", "like", "what", "my", "your",
+}
+
+// StyleSimilarity is a heuristic writing-style match between two profiles.
+// Short or topical posts easily produce false matches, so it is a hint to
+// check by hand, never proof the profiles share an owner.
+type StyleSimilarity struct {
+	ProfileA   string  `json:"profile_a"`
+	ProfileB   string  `json:"profile_b"`
+	Similarity float64 `json:"similarity"` // 0 to 1
+	Heuristic  bool    `json:"heuristic"`
+}
+
+// styleFeatures describes how a profile writes
+type styleFeatures struct {
+	functionWords  []float64 // per-word rates of styleFunctionWords
+	sentenceLength float64   // mean words per sentence
+	emojiRate      float64   // emojis per word
+	capsRate       float64   // share of letters in upper case
+	punctRate      float64   // '!' and '?' per word
+}
+
+// extractStyle computes the style features of a profile's posts, or false
+// when they are too few or too short
+func extractStyle(posts []string) (styleFeatures, bool) {
+	if len(posts) < minStylePosts {
+		return styleFeatures{}, false
+	}
+
+	index := make(map[string]int, len(styleFunctionWords))
+	for i, word := range styleFunctionWords {
+		index[word] = i
+	}
+	f := styleFeatures{functionWords: make([]float64, len(styleFunctionWords))}
+
+	var words, sentences, emojis, letters, upper, punct int
+	for _, post := range posts {
+		for _, r := range post {
+			switch {
+			case r >= 0x1F300 && r <= 0x1FAFF, r >= 0x2600 && r <= 0x27BF:
+				emojis++
+			case unicode.IsLetter(r):
+				letters++
+				if unicode.IsUpper(r) {
+					upper++
+				}
+			case r == '!' || r == '?':
+				punct++
+			}
+		}
+		postSentences := 0
+		for _, sentence := range strings.FieldsFunc(post, func(r rune) bool { return r == '.' || r == '!' || r == '?' || r == '\n' }) {
+			if strings.TrimSpace(sentence) != "" {
+				postSentences++
+			}
+		}
+		sentences += max(postSentences, 1)
+		for _, word := range strings.FieldsFunc(strings.ToLower(post), func(r rune) bool { return !unicode.IsLetter(r) && r != '\'' }) {
+			words++
+			if i, ok := index[word]; ok {
+				f.functionWords[i]++
+			}
+		}
+	}
+	if words < minStyleWords {
+		return styleFeatures{}, false
+	}
+
+	for i := range f.functionWords {
+		f.functionWords[i] /= float64(words)
+	}
+	f.sentenceLength = float64(words) / float64(sentences)
+	f.emojiRate = float64(emojis) / float64(words)
+	f.punctRate = float64(punct) / float64(words)
+	if letters > 0 {
+		f.capsRate = float64(upper) / float64(letters)
+	}
+	return f, true
+}
+
+// similarity weighs function-word usage above the coarser habits
+func (f styleFeatures) similarity(g styleFeatures) float64 {
+	var dot, na, nb float64
+	for i := range f.functionWords {
+		dot += f.functionWords[i] * g.functionWords[i]
+		na += f.functionWords[i] * f.functionWords[i]
+		nb += g.functionWords[i] * g.functionWords[i]
+	}
+	cosine := 0.0
+	if na > 0 && nb > 0 {
+		cosine = dot / math.Sqrt(na*nb)
+	}
+
+	closeness := func(a, b float64) float64 {
+		if a == 0 && b == 0 {
+			return 1
+		}
+		return 1 - math.Abs(a-b)/math.Max(a, b)
+	}
+	habits := (closeness(f.sentenceLength, g.sentenceLength) + closeness(f.emojiRate, g.emojiRate) +
+		closeness(f.capsRate, g.capsRate) + closeness(f.punctRate, g.punctRate)) / 4
+
+	return math.Round((0.6*cosine+0.4*habits)*100) / 100
+}
+
+// CompareWritingStyles scores every pair of profiles on different platforms
+// that have enough collected posts, most similar first
+func CompareWritingStyles(profiles []ProfileResult) []StyleSimilarity {
+	type styled struct {
+		profile  ProfileResult
+		features styleFeatures
+	}
+	var candidates []styled
+	for _, profile := range profiles {
+		if features, ok := extractStyle(profile.RecentActivity); ok {
+			candidates = append(candidates, styled{profile, features})
+		}
+	}
+
+	var pairs []StyleSimilarity
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			a, b := candidates[i], candidates[j]
+			if a.profile.Platform == b.profile.Platform {
+				continue
+			}
+			pairs = append(pairs, StyleSimilarity{
+				ProfileA:   a.profile.URL,
+				ProfileB:   b.profile.URL,
+				Similarity: a.features.similarity(b.features),
+				Heuristic:  true,
+			})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Similarity > pairs[j].Similarity })
+	return pairs
+}
@@ -0,0 +1,329 @@
+package osint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Kinds of suppression rules
+const (
+	SuppressURL     = "url"     // A profile or alert URL
+	SuppressProfile = "profile" // An account, as platform:username
+	SuppressHash    = "hash"    // A finding hash, as runs and reports show them
+)
+
+// findingHashRegex matches the finding hashes FindingHash returns
+var findingHashRegex = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// ActiveSuppressions hides the findings analysts marked as false positives
+// from runs and reports. Nil hides nothing.
+var ActiveSuppressions *Suppressions
+
+// Suppression is a known false positive, kept out of future runs and reports
+type Suppression struct {
+	Kind   string `json:"kind"`
+	Value  string `json:"value"`
+	Reason string `json:"reason,omitempty"`
+	Author string `json:"author,omitempty"`
+	Added  string `json:"added"`
+	Scope  string `json:"scope,omitempty"` // global, or the case the rule belongs to
+}
+
+// SuppressedProfile is a profile a suppression rule hid from a search. It is
+// still saved, so the case's index finds it.
+type SuppressedProfile struct {
+	Profile      ProfileResult `json:"profile"`
+	SuppressedBy Suppression   `json:"suppressed_by"`
+}
+
+// SuppressionList is one suppression file: the global one in
+// ~/.mercuries/suppressions.json, or a case's in
+// <OutputDir>/cases/<name>/suppressions.json
+type SuppressionList struct {
+	Suppressions []Suppression `json:"suppressions"`
+
+	scope string
+	path  string
+}
+
+// Suppressions are the rules of the global list and of a case's together
+type Suppressions struct {
+	rules []Suppression
+}
+
+// GlobalSuppressionsPath returns ~/.mercuries/suppressions.json
+func GlobalSuppressionsPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".mercuries", "suppressions.json")
+}
+
+// LoadSuppressionList reads a case's suppression list, or the global one
+// when the case is empty. A missing file is an empty list.
+func LoadSuppressionList(caseName string) (*SuppressionList, error) {
+	list := &SuppressionList{scope: "global", path: GlobalSuppressionsPath()}
+	if caseName != "" {
+		if err := ValidateCaseName(caseName); err != nil {
+			return nil, err
+		}
+		list.scope, list.path = caseName, filepath.Join(CaseDir(caseName), "suppressions.json")
+	}
+	return loadSuppressionList(list)
+}
+
+// loadSuppressionList reads the rules of a list from its file
+func loadSuppressionList(list *SuppressionList) (*SuppressionList, error) {
+	if list.path == "" {
+		return list, nil
+	}
+	data, err := os.ReadFile(list.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return list, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, list); err != nil {
+		return nil, fmt.Errorf("reading %s: %v", list.path, err)
+	}
+	for i := range list.Suppressions {
+		list.Suppressions[i].Scope = list.scope
+	}
+	return list, nil
+}
+
+// LoadSuppressions reads the global suppression list and, when a case is
+// given, the case's
+func LoadSuppressions(caseName string) (*Suppressions, error) {
+	global, err := LoadSuppressionList("")
+	if err != nil {
+		return nil, err
+	}
+	suppressions := &Suppressions{rules: global.Suppressions}
+	if caseName != "" {
+		list, err := LoadSuppressionList(caseName)
+		if err != nil {
+			return nil, err
+		}
+		suppressions.rules = append(suppressions.rules, list.Suppressions...)
+	}
+	return suppressions, nil
+}
+
+// ParseSuppression reads a rule from a URL, platform:username or finding
+// hash
+func ParseSuppression(value string) (Suppression, error) {
+	value = strings.TrimSpace(value)
+	lower := strings.ToLower(value)
+	switch {
+	case strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://"):
+		return Suppression{Kind: SuppressURL, Value: value}, nil
+	case findingHashRegex.MatchString(lower):
+		return Suppression{Kind: SuppressHash, Value: lower}, nil
+	}
+	if platform, username, found := strings.Cut(value, ":"); found && platform != "" && username != "" && !strings.ContainsAny(value, "/ ") {
+		return Suppression{Kind: SuppressProfile, Value: platform + ":" + username}, nil
+	}
+	return Suppression{}, fmt.Errorf("%q is neither a URL, platform:username nor a finding hash", value)
+}
+
+// Add puts a rule on the list, reporting false when an equal one is there
+func (l *SuppressionList) Add(rule Suppression) bool {
+	if l.find(rule) >= 0 {
+		return false
+	}
+	rule.Scope = l.scope
+	if rule.Added == "" {
+		rule.Added = time.Now().Format(time.RFC3339)
+	}
+	l.Suppressions = append(l.Suppressions, rule)
+	return true
+}
+
+// Remove takes a rule off the list, reporting whether it was there
+func (l *SuppressionList) Remove(rule Suppression) bool {
+	i := l.find(rule)
+	if i < 0 {
+		return false
+	}
+	l.Suppressions = slices.Delete(l.Suppressions, i, i+1)
+	return true
+}
+
+// find returns the index of a rule of the same kind and value, or -1
+func (l *SuppressionList) find(rule Suppression) int {
+	return slices.IndexFunc(l.Suppressions, func(s Suppression) bool {
+		return s.Kind == rule.Kind && s.key() == rule.key()
+	})
+}
+
+// Save writes the list, replacing the previous file only once it is complete
+func (l *SuppressionList) Save() error {
+	if l.path == "" {
+		return fmt.Errorf("no home directory to keep the global suppression list in")
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	sort.SliceStable(l.Suppressions, func(i, j int) bool { return l.Suppressions[i].Added < l.Suppressions[j].Added })
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
+
+// DisplayResults prints the rules of the list
+func (l *SuppressionList) DisplayResults() {
+	title := "Global suppressions"
+	if l.scope != "global" {
+		title = "Suppressions of case " + l.scope
+	}
+	if len(l.Suppressions) == 0 {
+		color.Yellow("\n%s: none", title)
+		return
+	}
+	color.Green("\n%s: %d", title, len(l.Suppressions))
+	for _, rule := range l.Suppressions {
+		color.Cyan("  %-7s %s", rule.Kind, rule.Value)
+		line := "    Added " + rule.Added
+		if rule.Author != "" {
+			line += " by " + rule.Author
+		}
+		if rule.Reason != "" {
+			line += ": " + rule.Reason
+		}
+		fmt.Println(line)
+	}
+}
+
+// key is the rule's value in the form findings are compared in
+func (s Suppression) key() string {
+	if s.Kind == SuppressURL {
+		return comparableURL(s.Value)
+	}
+	return strings.ToLower(s.Value)
+}
+
+// Len returns the number of rules
+func (s *Suppressions) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.rules)
+}
+
+// Match returns the first rule hiding an account, by its URL, platform and
+// username or finding hash, or nil when none does. Empty fields match
+// nothing.
+func (s *Suppressions) Match(platform, username, link, hash string) *Suppression {
+	if s == nil {
+		return nil
+	}
+	account := strings.ToLower(platform + ":" + username)
+	link = comparableURL(link)
+	for i, rule := range s.rules {
+		var value string
+		switch rule.Kind {
+		case SuppressURL:
+			value = link
+		case SuppressProfile:
+			if platform != "" && username != "" {
+				value = account
+			}
+		case SuppressHash:
+			value = hash
+		}
+		if value != "" && value == rule.key() {
+			return &s.rules[i]
+		}
+	}
+	return nil
+}
+
+// Profile returns the rule hiding a profile, matching its canonical URL too
+func (s *Suppressions) Profile(profile ProfileResult) *Suppression {
+	if rule := s.Match(profile.Platform, profile.Username, profile.URL, profile.FindingHash()); rule != nil {
+		return rule
+	}
+	if profile.CanonicalURL != "" {
+		return s.Match("", "", profile.CanonicalURL, "")
+	}
+	return nil
+}
+
+// SocialProfiles splits linked accounts into those kept and those hidden
+func (s *Suppressions) SocialProfiles(profiles []SocialProfile) (kept, suppressed []SocialProfile) {
+	for _, profile := range profiles {
+		if s.Match(profile.Platform, profile.Username, profile.URL, profile.FindingHash()) != nil {
+			suppressed = append(suppressed, profile)
+		} else {
+			kept = append(kept, profile)
+		}
+	}
+	return kept, suppressed
+}
+
+// Alerts drops the alerts a rule hides, by URL or finding hash
+func (s *Suppressions) Alerts(alerts []Alert) []Alert {
+	if s.Len() == 0 {
+		return alerts
+	}
+	var kept []Alert
+	for _, alert := range alerts {
+		if s.Match("", "", alert.URL, alert.FindingHash()) == nil {
+			kept = append(kept, alert)
+		}
+	}
+	return kept
+}
+
+// FindingHash identifies a finding across runs and cases by the parts that
+// name it
+func FindingHash(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.Join(parts, "\x00"))))
+	return hex.EncodeToString(sum[:8])
+}
+
+// FindingHash identifies the profile by its platform and URL
+func (p ProfileResult) FindingHash() string {
+	return FindingHash("profile", p.Platform, comparableURL(p.URL))
+}
+
+// FindingHash identifies the linked account as a profile search would
+func (p SocialProfile) FindingHash() string {
+	return FindingHash("profile", p.Platform, comparableURL(p.URL))
+}
+
+// FindingHash identifies the alert by its ID
+func (a Alert) FindingHash() string {
+	return FindingHash("alert", a.ID)
+}
+
+// comparableURL drops a URL's scheme, www. and trailing slash and lowercases
+// it, so the forms a site links an account under compare equal
+func comparableURL(link string) string {
+	link = strings.ToLower(strings.TrimSpace(link))
+	if _, rest, found := strings.Cut(link, "://"); found {
+		link = rest
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(link, "www."), "/")
+}
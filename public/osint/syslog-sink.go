@@ -0,0 +1,218 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+)
+
+// Syslog message formats
+const (
+	SyslogRFC5424 = "rfc5424"
+	SyslogCEF     = "cef"
+	SyslogLEEF    = "leef"
+)
+
+// syslogFacility is local0, the facility SIEM collectors usually reserve for
+// third-party security tools
+const syslogFacility = 16
+
+// SyslogSink forwards alerts to a syslog collector as RFC 5424 messages, with
+// CEF or LEEF payloads when the collector expects them
+type SyslogSink struct {
+	Network string // udp or tcp
+	Address string
+	Format  string
+	Version string // Product version reported in CEF and LEEF headers
+
+	hostname string
+	conn     net.Conn
+}
+
+// NewSyslogSink connects to a collector given as udp://host:port or
+// tcp://host:port. A bare host:port means UDP.
+func NewSyslogSink(target, format, version string) (*SyslogSink, error) {
+	switch format {
+	case SyslogRFC5424, SyslogCEF, SyslogLEEF:
+	default:
+		return nil, fmt.Errorf("unknown syslog format %q, expected rfc5424, cef or leef", format)
+	}
+
+	network, address := "udp", target
+	if strings.Contains(target, "://") {
+		parsed, err := url.Parse(target)
+		if err != nil {
+			return nil, err
+		}
+		network, address = parsed.Scheme, parsed.Host
+	}
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("unsupported syslog transport %q, expected udp or tcp", network)
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "514")
+	}
+
+	conn, err := providers.DialContext(context.Background(), network, address, RequestTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	return &SyslogSink{
+		Network:  network,
+		Address:  address,
+		Format:   format,
+		Version:  version,
+		hostname: hostname,
+		conn:     conn,
+	}, nil
+}
+
+// Send writes one message per alert. TCP messages use octet-counting framing
+// (RFC 6587) so payloads may contain newlines.
+func (s *SyslogSink) Send(alerts []Alert) error {
+	for _, alert := range alerts {
+		if alert.ScanID == "" {
+			alert.ScanID = providers.ScanID
+		}
+		message := s.format(alert, time.Now())
+		if s.Network == "tcp" {
+			message = fmt.Sprintf("%d %s", len(message), message)
+		}
+		s.conn.SetWriteDeadline(time.Now().Add(RequestTimeout))
+		if _, err := s.conn.Write([]byte(message)); err != nil {
+			return fmt.Errorf("send alert %s: %v", alert.ID, err)
+		}
+	}
+	return nil
+}
+
+// Close closes the connection to the collector
+func (s *SyslogSink) Close() error {
+	return s.conn.Close()
+}
+
+// format renders an alert as an RFC 5424 message
+func (s *SyslogSink) format(alert Alert, now time.Time) string {
+	priority := syslogFacility*8 + syslogSeverity(alert.Severity)
+	header := fmt.Sprintf("<%d>1 %s %s MercuriesOST %d %s", priority,
+		now.UTC().Format(time.RFC3339), s.hostname, os.Getpid(), syslogMsgID(alert.Module))
+
+	switch s.Format {
+	case SyslogCEF:
+		return header + " - " + s.cef(alert)
+	case SyslogLEEF:
+		return header + " - " + s.leef(alert)
+	default:
+		data := fmt.Sprintf(`[mercuries@32473 id="%s" module="%s" target="%s" severity="%d"`,
+			sdEscape(alert.ID), sdEscape(alert.Module), sdEscape(alert.Target), alert.Severity)
+		if alert.URL != "" {
+			data += fmt.Sprintf(` url="%s"`, sdEscape(alert.URL))
+		}
+		if alert.ScanID != "" {
+			data += fmt.Sprintf(` scan="%s"`, sdEscape(alert.ScanID))
+		}
+		message := alert.Name
+		if alert.Details != "" {
+			message += ": " + alert.Details
+		}
+		return header + " " + data + "] " + message
+	}
+}
+
+// cef renders an alert in ArcSight Common Event Format
+func (s *SyslogSink) cef(alert Alert) string {
+	extensions := []string{
+		"cs1Label=target", "cs1=" + cefExtEscape(alert.Target),
+		"externalId=" + cefExtEscape(alert.ID),
+	}
+	if alert.URL != "" {
+		extensions = append(extensions, "request="+cefExtEscape(alert.URL))
+	}
+	if alert.ScanID != "" {
+		extensions = append(extensions, "cs2Label=scanId", "cs2="+cefExtEscape(alert.ScanID))
+	}
+	if alert.Details != "" {
+		extensions = append(extensions, "msg="+cefExtEscape(alert.Details))
+	}
+	return fmt.Sprintf("CEF:0|awiones|MercuriesOST|%s|%s|%s|%d|%s",
+		cefHeaderEscape(s.Version), cefHeaderEscape(alert.Module), cefHeaderEscape(alert.Name),
+		alert.Severity, strings.Join(extensions, " "))
+}
+
+// leef renders an alert in IBM QRadar Log Event Extended Format 1.0
+func (s *SyslogSink) leef(alert Alert) string {
+	attributes := []string{
+		"sev=" + fmt.Sprint(max(alert.Severity, 1)),
+		"cat=" + leefEscape(alert.Module),
+		"name=" + leefEscape(alert.Name),
+		"target=" + leefEscape(alert.Target),
+		"externalId=" + leefEscape(alert.ID),
+	}
+	if alert.URL != "" {
+		attributes = append(attributes, "url="+leefEscape(alert.URL))
+	}
+	if alert.ScanID != "" {
+		attributes = append(attributes, "scanId="+leefEscape(alert.ScanID))
+	}
+	if alert.Details != "" {
+		attributes = append(attributes, "msg="+leefEscape(alert.Details))
+	}
+	return fmt.Sprintf("LEEF:1.0|awiones|MercuriesOST|%s|%s|%s",
+		cefHeaderEscape(s.Version), cefHeaderEscape(alert.Module), strings.Join(attributes, "\t"))
+}
+
+// syslogSeverity maps the 0-10 alert scale onto syslog severities
+func syslogSeverity(severity int) int {
+	switch {
+	case severity >= 9:
+		return 2 // critical
+	case severity >= 7:
+		return 3 // error
+	case severity >= 5:
+		return 4 // warning
+	case severity >= 3:
+		return 5 // notice
+	default:
+		return 6 // informational
+	}
+}
+
+// syslogMsgID turns a module name into a MSGID, which allows no spaces and 32 characters
+func syslogMsgID(module string) string {
+	id := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, module)
+	if len(id) > 32 {
+		id = id[:32]
+	}
+	if id == "" {
+		return "-"
+	}
+	return id
+}
+
+var (
+	sdEscaper        = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+	cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefExtEscaper    = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+	leefEscaper      = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+)
+
+func sdEscape(value string) string        { return sdEscaper.Replace(value) }
+func cefHeaderEscape(value string) string { return cefHeaderEscaper.Replace(value) }
+func cefExtEscape(value string) string    { return cefExtEscaper.Replace(value) }
+func leefEscape(value string) string      { return leefEscaper.Replace(value) }
@@ -0,0 +1,165 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// analysisTask is a single unit of work inside a taskGraph
+type analysisTask struct {
+	name    string
+	deps    []string
+	timeout time.Duration
+	run     func(ctx context.Context) error
+}
+
+// taskGraph runs analysis tasks concurrently, starting each one only after its
+// dependencies have finished. A failed dependency does not block its dependents;
+// tasks are expected to cope with partially filled results.
+type taskGraph struct {
+	tasks       []analysisTask
+	concurrency int
+}
+
+// newTaskGraph creates a task graph running at most concurrency tasks at once
+func newTaskGraph(concurrency int) *taskGraph {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &taskGraph{concurrency: concurrency}
+}
+
+// add registers a task with its timeout and the names of the tasks it depends on
+func (g *taskGraph) add(name string, timeout time.Duration, run func(ctx context.Context) error, deps ...string) {
+	g.tasks = append(g.tasks, analysisTask{
+		name:    name,
+		deps:    deps,
+		timeout: timeout,
+		run:     run,
+	})
+}
+
+// validate checks for duplicate names, unknown dependencies and cycles
+func (g *taskGraph) validate() error {
+	byName := make(map[string]analysisTask, len(g.tasks))
+	for _, task := range g.tasks {
+		if _, exists := byName[task.name]; exists {
+			return fmt.Errorf("duplicate task %q", task.name)
+		}
+		byName[task.name] = task
+	}
+
+	for _, task := range g.tasks {
+		for _, dep := range task.deps {
+			if _, exists := byName[dep]; !exists {
+				return fmt.Errorf("task %q depends on unknown task %q", task.name, dep)
+			}
+		}
+	}
+
+	// Depth-first search for cycles
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(g.tasks))
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("dependency cycle detected at task %q", name)
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range byName[name].deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+	for _, task := range g.tasks {
+		if err := visit(task.name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// run executes all tasks and returns the errors keyed by task name
+func (g *taskGraph) run(ctx context.Context) (map[string]error, error) {
+	if err := g.validate(); err != nil {
+		return nil, err
+	}
+
+	done := make(map[string]chan struct{}, len(g.tasks))
+	for _, task := range g.tasks {
+		done[task.name] = make(chan struct{})
+	}
+
+	errs := make(map[string]error)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, g.concurrency)
+
+	for _, task := range g.tasks {
+		wg.Add(1)
+		go func(task analysisTask) {
+			defer wg.Done()
+			defer close(done[task.name])
+
+			setErr := func(err error) {
+				mu.Lock()
+				errs[task.name] = err
+				mu.Unlock()
+			}
+
+			// Wait for dependencies to finish
+			for _, dep := range task.deps {
+				select {
+				case <-done[dep]:
+				case <-ctx.Done():
+					setErr(ctx.Err())
+					return
+				}
+			}
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				setErr(ctx.Err())
+				return
+			}
+
+			taskCtx := ctx
+			if task.timeout > 0 {
+				var cancel context.CancelFunc
+				taskCtx, cancel = context.WithTimeout(ctx, task.timeout)
+				defer cancel()
+			}
+
+			if err := task.run(taskCtx); err != nil {
+				setErr(err)
+			}
+		}(task)
+	}
+
+	wg.Wait()
+	return errs, nil
+}
+
+// order returns task names in registration order
+func (g *taskGraph) order() []string {
+	names := make([]string, 0, len(g.tasks))
+	for _, task := range g.tasks {
+		names = append(names, task.name)
+	}
+	return names
+}
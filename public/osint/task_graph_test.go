@@ -0,0 +1,91 @@
+package osint
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func noop(ctx context.Context) error { return nil }
+
+func TestTaskGraphDetectsCycle(t *testing.T) {
+	g := newTaskGraph(2)
+	g.add("a", 0, noop, "c")
+	g.add("b", 0, noop, "a")
+	g.add("c", 0, noop, "b")
+
+	if _, err := g.run(context.Background()); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+}
+
+func TestTaskGraphRejectsUnknownDependency(t *testing.T) {
+	g := newTaskGraph(2)
+	g.add("a", 0, noop, "missing")
+
+	if _, err := g.run(context.Background()); err == nil || !strings.Contains(err.Error(), "unknown task") {
+		t.Fatalf("expected unknown dependency error, got %v", err)
+	}
+}
+
+func TestTaskGraphRunsDependenciesFirst(t *testing.T) {
+	var mu sync.Mutex
+	var finished []string
+	record := func(name string) func(context.Context) error {
+		return func(ctx context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			finished = append(finished, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	g := newTaskGraph(4)
+	g.add("last", 0, record("last"), "middle")
+	g.add("middle", 0, record("middle"), "first")
+	g.add("first", 0, record("first"))
+
+	if _, err := g.run(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	want := []string{"first", "middle", "last"}
+	if strings.Join(finished, ",") != strings.Join(want, ",") {
+		t.Fatalf("finished in order %v, want %v", finished, want)
+	}
+}
+
+func TestTaskGraphFailedDependencyDoesNotBlock(t *testing.T) {
+	ran := false
+	g := newTaskGraph(2)
+	g.add("a", 0, func(ctx context.Context) error { return errors.New("boom") })
+	g.add("b", 0, func(ctx context.Context) error { ran = true; return nil }, "a")
+
+	errs, err := g.run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if errs["a"] == nil || errs["b"] != nil || !ran {
+		t.Fatalf("unexpected result: errs=%v ran=%v", errs, ran)
+	}
+}
+
+func TestTaskGraphAppliesTimeout(t *testing.T) {
+	g := newTaskGraph(1)
+	g.add("slow", 20*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	errs, err := g.run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !errors.Is(errs["slow"], context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", errs["slow"])
+	}
+}
@@ -0,0 +1,291 @@
+package osint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/awion/MercuriesOST/public/providers"
+)
+
+// Observable is an indicator in TheHive's data type vocabulary, shared by case
+// exports and Cortex analyzer artifacts
+type Observable struct {
+	DataType string   `json:"dataType"`
+	Data     string   `json:"data"`
+	Message  string   `json:"message,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	IOC      bool     `json:"ioc"`
+
+	// Reference is a page backing the observable, kept out of TheHive's schema
+	Reference string `json:"-"`
+}
+
+// TheHiveCase is a case in the shape TheHive's /api/v1/case endpoint accepts
+type TheHiveCase struct {
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	Severity    int          `json:"severity"` // 1 low, 2 medium, 3 high, 4 critical
+	TLP         int          `json:"tlp"`
+	PAP         int          `json:"pap"`
+	Tags        []string     `json:"tags"`
+	Observables []Observable `json:"observables,omitempty"`
+}
+
+// TheHive export settings
+var (
+	TheHiveTLP = 2 // TLP:AMBER
+	TheHivePAP = 2 // PAP:AMBER
+)
+
+// observableSet collects observables, ignoring empty and repeated values
+type observableSet struct {
+	seen  map[string]bool
+	items []Observable
+}
+
+func (s *observableSet) add(dataType, data, message string, ioc bool) {
+	s.addRef(dataType, data, message, "", ioc)
+}
+
+// addRef adds an observable along with the page it was found on or checked against
+func (s *observableSet) addRef(dataType, data, message, reference string, ioc bool) {
+	data = strings.TrimSpace(data)
+	if data == "" {
+		return
+	}
+	key := dataType + "\x00" + strings.ToLower(data)
+	if s.seen == nil {
+		s.seen = make(map[string]bool)
+	}
+	if s.seen[key] {
+		return
+	}
+	s.seen[key] = true
+	s.items = append(s.items, Observable{DataType: dataType, Data: data, Message: message, Tags: []string{"MercuriesOST"}, IOC: ioc, Reference: reference})
+}
+
+// Observables lists the email, its infrastructure and linked identities
+func (r *EmailAnalysisResult) Observables() []Observable {
+	var set observableSet
+	set.add("mail", r.Email, "Target email", false)
+	set.add("domain", r.Domain, "Email domain", false)
+	for _, mx := range r.DomainInfo.MXRecords {
+		set.add("fqdn", strings.TrimSuffix(mx.Host, "."), "Mail server", false)
+	}
+	for _, ip := range r.DomainInfo.IPAddresses {
+		set.add("ip", ip, "Email domain address", false)
+	}
+	for _, verdict := range r.IPReputation {
+		set.addRef("ip", verdict.IP, fmt.Sprintf("%s: %s", verdict.Provider, verdict.Classification), verdict.Link, verdict.Malicious)
+	}
+	for _, email := range r.PGP.LinkedEmails {
+		set.add("mail", email, "Linked through PGP keys", false)
+	}
+	for _, domain := range r.PGP.LinkedDomains {
+		set.add("domain", domain, "Linked through PGP keys", false)
+	}
+	for _, profile := range r.SocialProfiles {
+		set.addRef("url", profile.URL, fmt.Sprintf("%s profile (%s)", profile.Platform, profile.Tier), profile.URL, false)
+	}
+	return set.items
+}
+
+// Observables lists the domain, its addresses, contacts and related domains
+func (r *DomainIntelResult) Observables() []Observable {
+	var set observableSet
+	malicious, reference := false, ""
+	if r.VirusTotal != nil {
+		malicious, reference = r.VirusTotal.Malicious > 0, r.VirusTotal.Link
+	}
+	set.addRef("domain", r.Domain, "Target domain", reference, malicious)
+	for _, ip := range r.DNS.IPAddresses {
+		set.add("ip", ip, "Resolved address", false)
+	}
+	for _, mx := range r.DNS.MXRecords {
+		set.add("fqdn", strings.TrimSuffix(mx.Host, "."), "Mail server", false)
+	}
+	for _, email := range r.Emails {
+		set.add("mail", email, "Found on site", false)
+	}
+	for _, related := range r.RelatedDomains {
+		set.add("domain", related.Domain, fmt.Sprintf("Shares %s %s", related.IDType, related.SharedID), false)
+	}
+	return set.items
+}
+
+// Observables lists the IP and the names pointing at it
+func (r *IPIntelResult) Observables() []Observable {
+	var set observableSet
+	malicious, reference := false, ""
+	for _, verdict := range r.Reputation {
+		malicious = malicious || verdict.Malicious
+	}
+	if r.VirusTotal != nil {
+		malicious, reference = malicious || r.VirusTotal.Malicious > 0, r.VirusTotal.Link
+	}
+	set.addRef("ip", r.IP, "Target address", reference, malicious)
+	for _, name := range r.ReverseDNS {
+		set.add("fqdn", name, "Reverse DNS", false)
+	}
+	return set.items
+}
+
+// Observables lists the link, each redirect and the landing host
+func (r *TriageResult) Observables() []Observable {
+	var set observableSet
+	malicious := false
+	for _, verdict := range r.Verdicts {
+		malicious = malicious || verdict.Malicious
+	}
+	set.add("url", r.URL, "Triaged link", malicious)
+	for _, hop := range r.Redirects {
+		set.add("url", hop.URL, "Redirect hop", false)
+	}
+	set.add("url", r.FinalURL, "Landing page", malicious)
+	if landing, err := url.Parse(r.FinalURL); err == nil {
+		set.add("fqdn", landing.Hostname(), "Landing host", false)
+	}
+	if r.Hosting != nil {
+		for _, ip := range r.Hosting.DNS.IPAddresses {
+			set.add("ip", ip, "Landing host address", false)
+		}
+	}
+	return set.items
+}
+
+// Observables lists the phone number
+func (r *PhoneNumberResult) Observables() []Observable {
+	var set observableSet
+	set.add("other", r.E164Format, "Target phone number", false)
+	for _, presence := range r.OnlinePresence {
+		set.addRef("url", presence.URL, "Seen on "+presence.Platform, presence.URL, false)
+	}
+	return set.items
+}
+
+// NewTheHiveCase builds a case for a module result, taking its severity from
+// the most severe alert
+func NewTheHiveCase(module, target, description string, alerts []Alert, observables []Observable) *TheHiveCase {
+	severity := 1
+	for _, alert := range alerts {
+		severity = max(severity, theHiveSeverity(alert.Severity))
+	}
+
+	lines := []string{description}
+	if providers.ScanID != "" {
+		lines = append(lines, "", "Scan ID: "+providers.ScanID)
+	}
+	if AuthorizedBy != "" {
+		lines = append(lines, "Authorized by: "+AuthorizedBy)
+	}
+	if len(alerts) > 0 {
+		lines = append(lines, "", "Alerts:")
+	}
+	for _, alert := range alerts {
+		line := "- " + alert.Name
+		if alert.Details != "" {
+			line += ": " + alert.Details
+		}
+		lines = append(lines, line)
+	}
+
+	return &TheHiveCase{
+		Title:       fmt.Sprintf("MercuriesOST %s: %s", module, target),
+		Description: strings.Join(lines, "\n"),
+		Severity:    severity,
+		TLP:         TheHiveTLP,
+		PAP:         TheHivePAP,
+		Tags:        []string{"MercuriesOST", "osint", module},
+		Observables: observables,
+	}
+}
+
+// theHiveSeverity maps the 0-10 alert scale onto TheHive's four levels
+func theHiveSeverity(severity int) int {
+	switch {
+	case severity >= 9:
+		return 4
+	case severity >= 7:
+		return 3
+	case severity >= 4:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Export writes the case as JSON for import into TheHive
+func (c *TheHiveCase) Export(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Push creates the case on a TheHive instance and attaches its observables,
+// returning the new case ID
+func (c *TheHiveCase) Push(ctx context.Context, baseURL string) (string, error) {
+	if !apiKeyConfigured(APIConfig.TheHiveKey) {
+		return "", fmt.Errorf("TheHive API key not configured")
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	headers := map[string]string{
+		"Authorization": "Bearer " + APIConfig.TheHiveKey,
+		"Content-Type":  "application/json",
+	}
+
+	caseOnly := *c
+	caseOnly.Observables = nil
+	body, err := json.Marshal(caseOnly)
+	if err != nil {
+		return "", err
+	}
+	var created struct {
+		ID string `json:"_id"`
+	}
+	if err := postProviderJSON(ctx, baseURL+"/api/v1/case", headers, bytes.NewReader(body), &created); err != nil {
+		return "", fmt.Errorf("create case: %v", err)
+	}
+	if created.ID == "" {
+		return "", fmt.Errorf("create case: no case ID in response")
+	}
+
+	var failed []string
+	for _, observable := range c.Observables {
+		body, err := json.Marshal(observable)
+		if err != nil {
+			return created.ID, err
+		}
+		var ignored json.RawMessage
+		target := baseURL + "/api/v1/case/" + url.PathEscape(created.ID) + "/observable"
+		if err := postProviderJSON(ctx, target, headers, bytes.NewReader(body), &ignored); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", observable.Data, err))
+		}
+	}
+	if len(failed) > 0 {
+		return created.ID, fmt.Errorf("%d observables not added (%s)", len(failed), strings.Join(failed, "; "))
+	}
+	return created.ID, nil
+}
+
+// Observables lists the hash and the emails and links recovered for it
+func (r *HashResult) Observables() []Observable {
+	var set observableSet
+	set.add("hash", r.Hash, "Target hash", false)
+	for _, m := range r.Matches {
+		message := fmt.Sprintf("Recovered for %s from %s", r.Hash, m.Source)
+		switch m.Kind {
+		case "email":
+			set.addRef("mail", m.Value, message, m.URL, false)
+		case "url":
+			set.addRef("url", m.Value, message, m.URL, false)
+		}
+	}
+	return set.items
+}
@@ -0,0 +1,49 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+var (
+	// TorPorts are where Tor, then Tor Browser, listen for SOCKS connections
+	TorPorts = []int{9050, 9150}
+	// TorCheckURL tells whether a request came through Tor, and from which exit
+	TorCheckURL = "https://check.torproject.org/api/ip"
+)
+
+// TorExit is what TorCheckURL saw of a request
+type TorExit struct {
+	IsTor bool   `json:"IsTor"`
+	IP    string `json:"IP"`
+}
+
+// FindTor returns the address of the first local SOCKS listener on TorPorts
+func FindTor(ctx context.Context) (string, error) {
+	var dialer net.Dialer
+	for _, port := range TorPorts {
+		address := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err == nil {
+			conn.Close()
+			return address, nil
+		}
+	}
+	return "", fmt.Errorf("no Tor SOCKS listener on 127.0.0.1 ports %v; start tor or Tor Browser", TorPorts)
+}
+
+// CheckTorExit asks TorCheckURL, through the proxy in use, whether requests
+// leave through Tor. It fails when they do not, so a scan never starts on a
+// connection that would reveal the investigator.
+func CheckTorExit(ctx context.Context) (*TorExit, error) {
+	var exit TorExit
+	if err := getProviderJSON(ctx, TorCheckURL, nil, &exit); err != nil {
+		return nil, fmt.Errorf("could not verify the Tor exit: %v", err)
+	}
+	if !exit.IsTor {
+		return nil, fmt.Errorf("requests leave from %s, which is not a Tor exit", exit.IP)
+	}
+	return &exit, nil
+}
@@ -0,0 +1,196 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/fatih/color"
+)
+
+var (
+	// TranslateTo makes scans translate bios, posts and reviews written in
+	// another language into this one, e.g. "en"; empty turns it off
+	TranslateTo = ""
+	// LibreTranslateURL is a self-hosted LibreTranslate instance, used when
+	// no DeepL key is configured
+	LibreTranslateURL = ""
+)
+
+// maxTranslatedTexts bounds the texts sent for translation per run, since
+// every one costs a paid or self-hosted API call
+const maxTranslatedTexts = 300
+
+// englishFunctionWords mark a text as already English without asking a
+// translation service
+var englishFunctionWords = map[string]bool{
+	"the": true, "and": true, "is": true, "are": true, "was": true, "to": true, "of": true,
+	"in": true, "for": true, "with": true, "you": true, "my": true, "this": true, "that": true,
+	"it": true, "at": true, "on": true, "i": true, "we": true, "a": true, "an": true,
+}
+
+// TranslatedText is one text as a translation service returned it
+type TranslatedText struct {
+	Text     string `json:"text"`
+	Language string `json:"language"` // Detected language of the original, e.g. de
+}
+
+// Translation is a foreign-language text kept beside its translation
+type Translation struct {
+	Field      string `json:"field"`    // e.g. bio, recent_activity[1] or review_text
+	Language   string `json:"language"` // Detected language of the original
+	Original   string `json:"original"`
+	Translated string `json:"translated"`
+	Provider   string `json:"provider"`
+}
+
+// Translator translates the texts of one run into TranslateTo, reusing
+// translations of repeated texts and giving up after the first failure
+type Translator struct {
+	target   string
+	language string                  // The target without its region, e.g. en for en-GB
+	cache    map[string]*Translation // nil entries are texts already in the target language
+	sent     int
+	err      error
+}
+
+// NewTranslator returns a translator, or nil when TranslateTo is unset
+func NewTranslator() *Translator {
+	if TranslateTo == "" {
+		return nil
+	}
+	target := strings.ToLower(TranslateTo)
+	language, _, _ := strings.Cut(target, "-")
+	return &Translator{target: target, language: language, cache: make(map[string]*Translation)}
+}
+
+// Err returns why translation stopped, if it did
+func (t *Translator) Err() error {
+	if t == nil {
+		return nil
+	}
+	return t.err
+}
+
+// Translate returns the translation of each text, or nil for texts already
+// in the target language or left untranslated
+func (t *Translator) Translate(ctx context.Context, texts []string) []*Translation {
+	if t == nil {
+		return make([]*Translation, len(texts))
+	}
+
+	var pending []string
+	for _, text := range texts {
+		if _, done := t.cache[text]; done || slices.Contains(pending, text) || !t.needsTranslation(text) {
+			continue
+		}
+		pending = append(pending, text)
+	}
+	if len(pending) > maxTranslatedTexts-t.sent {
+		pending = pending[:max(maxTranslatedTexts-t.sent, 0)]
+	}
+
+	if len(pending) > 0 && t.err == nil {
+		t.sent += len(pending)
+		var translated []TranslatedText
+		provider, _, err := withFallback(ctx, TranslationProviders, func(p TranslationProvider) error {
+			var err error
+			translated, err = p.Translate(ctx, pending, t.target)
+			return err
+		})
+		if err != nil {
+			t.err = fmt.Errorf("translation stopped: %v", err)
+		} else {
+			for i, text := range pending {
+				t.cache[text] = nil
+				if language := translated[i].Language; language != "" && language != t.language && !strings.EqualFold(translated[i].Text, text) {
+					t.cache[text] = &Translation{Language: language, Original: text, Translated: translated[i].Text, Provider: provider}
+				}
+			}
+		}
+	}
+
+	translations := make([]*Translation, len(texts))
+	for i, text := range texts {
+		if translation := t.cache[text]; translation != nil {
+			found := *translation
+			translations[i] = &found
+		}
+	}
+	return translations
+}
+
+// needsTranslation skips texts without words and, when translating into
+// English, plain ASCII texts that read as English
+func (t *Translator) needsTranslation(text string) bool {
+	letters, ascii := 0, true
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+		if r > unicode.MaxASCII && unicode.IsLetter(r) {
+			ascii = false
+		}
+	}
+	if letters < 3 {
+		return false
+	}
+	if t.language != "en" || !ascii {
+		return true
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) })
+	common := 0
+	for _, word := range words {
+		if englishFunctionWords[word] {
+			common++
+		}
+	}
+	// Short ASCII texts such as "Go dev" are rarely worth a lookup
+	return len(words) >= 4 && float64(common)/float64(len(words)) < 0.2
+}
+
+// translateProfile translates a profile's bio and recent posts
+func translateProfile(ctx context.Context, t *Translator, profile *ProfileResult) {
+	if t == nil {
+		return
+	}
+	fields, texts := []string{"bio"}, []string{profile.Bio}
+	for i, post := range profile.RecentActivity {
+		fields = append(fields, fmt.Sprintf("recent_activity[%d]", i))
+		texts = append(texts, post)
+	}
+	for i, translation := range t.Translate(ctx, texts) {
+		if translation != nil {
+			translation.Field = fields[i]
+			profile.Translations = append(profile.Translations, *translation)
+		}
+	}
+	translateReviews(ctx, t, profile.Reviews)
+}
+
+// translateReviews translates the text of Google Maps and profile reviews
+func translateReviews(ctx context.Context, t *Translator, reviews []ReviewInfo) {
+	if t == nil {
+		return
+	}
+	texts := make([]string, len(reviews))
+	for i, review := range reviews {
+		texts[i] = review.ReviewText
+	}
+	for i, translation := range t.Translate(ctx, texts) {
+		if translation != nil {
+			translation.Field = "review_text"
+			reviews[i].Translation = translation
+		}
+	}
+}
+
+// DisplayTranslations prints translated texts beside their originals
+func DisplayTranslations(translations []Translation) {
+	for _, translation := range translations {
+		color.Magenta("    ↳ %s (%s, %s): %s", translation.Field, translation.Language, translation.Provider, translation.Translated)
+	}
+}
@@ -0,0 +1,238 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// URLExpansion is the redirect chain behind a link
+type URLExpansion struct {
+	URL       string        `json:"url"`
+	ScanID    string        `json:"scan_id,omitempty"`
+	FinalURL  string        `json:"final_url"`
+	Shortened bool          `json:"shortened"`
+	Hops      []RedirectHop `json:"hops"`
+	Title     string        `json:"title,omitempty"`
+}
+
+// RedirectHop is one response in a redirect chain
+type RedirectHop struct {
+	URL      string   `json:"url"`
+	Host     string   `json:"host"`
+	Status   int      `json:"status"`
+	Location string   `json:"location,omitempty"`
+	Via      string   `json:"via,omitempty"` // header, meta refresh or script
+	Cookies  []string `json:"cookies,omitempty"`
+}
+
+// maxRedirects caps how many hops are followed before giving up
+const maxRedirects = 10
+
+// shortenerHosts are link shortening services whose links hide the destination
+var shortenerHosts = map[string]bool{
+	"bit.ly": true, "bitly.com": true, "t.co": true, "tinyurl.com": true, "goo.gl": true,
+	"ow.ly": true, "is.gd": true, "buff.ly": true, "rebrand.ly": true, "cutt.ly": true,
+	"shorturl.at": true, "rb.gy": true, "t.ly": true, "tiny.cc": true, "lnkd.in": true,
+	"s.id": true, "v.gd": true, "bl.ink": true, "short.io": true, "qrco.de": true,
+}
+
+var (
+	metaRefreshRegex = regexp.MustCompile(`(?i)<meta[^>]+http-equiv\s*=\s*["']?refresh["']?[^>]*content\s*=\s*["']?\s*\d+\s*;\s*url\s*=\s*['"]?([^"'>\s]+)`)
+	jsRedirectRegex  = regexp.MustCompile(`(?i)(?:window\.|document\.|top\.)?location(?:\.href)?\s*=\s*["']([^"']+)["']|location\.replace\(\s*["']([^"']+)["']`)
+	linkRegex        = regexp.MustCompile(`(?i)\b(?:https?://)?(?:[a-z0-9-]+\.)+[a-z]{2,}/[^\s"'<>]+`)
+)
+
+// ExpandURL follows a link one request at a time through Location headers, meta
+// refresh and simple script redirects, recording every hop. No cookies are sent
+// back, nothing is executed, and hops to private addresses are refused. The
+// expansion is returned with whatever hops were made even when following fails.
+func ExpandURL(ctx context.Context, rawURL string) (*URLExpansion, error) {
+	start, err := normalizeLink(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	expansion := &URLExpansion{URL: start, FinalURL: start, Shortened: isShortenedLink(start), ScanID: providers.ScanIDFrom(ctx)}
+	client := &http.Client{
+		Timeout:   RequestTimeout,
+		Transport: providers.Transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	seen := make(map[string]bool)
+	current, via := start, ""
+
+	for len(expansion.Hops) < maxRedirects {
+		if seen[current] {
+			return expansion, fmt.Errorf("redirect loop at %s", current)
+		}
+		seen[current] = true
+
+		target, err := url.Parse(current)
+		if err != nil {
+			return expansion, err
+		}
+		if target.Scheme != "http" && target.Scheme != "https" {
+			return expansion, fmt.Errorf("refusing to follow %s URL", target.Scheme)
+		}
+		if err := checkPublicHost(ctx, target.Hostname()); err != nil {
+			return expansion, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", current, nil)
+		if err != nil {
+			return expansion, err
+		}
+		req.Header.Set("User-Agent", UserAgent)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return expansion, err
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxProbeBodySize))
+		resp.Body.Close()
+
+		hop := RedirectHop{URL: current, Host: target.Hostname(), Status: resp.StatusCode, Via: via}
+		for _, cookie := range resp.Cookies() {
+			hop.Cookies = append(hop.Cookies, cookie.Name)
+		}
+		expansion.FinalURL = current
+
+		next, nextVia := "", ""
+		if location := resp.Header.Get("Location"); resp.StatusCode >= 300 && resp.StatusCode < 400 && location != "" {
+			next, nextVia = location, "header"
+		} else if resp.StatusCode == http.StatusOK {
+			if match := metaRefreshRegex.FindSubmatch(body); match != nil {
+				next, nextVia = string(match[1]), "meta refresh"
+			} else if match := jsRedirectRegex.FindSubmatch(body); match != nil && len(body) < 4096 {
+				// Only tiny pages are treated as redirect stubs; full pages mention location for other reasons
+				next, nextVia = string(match[1])+string(match[2]), "script"
+			}
+		}
+
+		if next == "" {
+			expansion.Hops = append(expansion.Hops, hop)
+			if match := pageTitleRegex.FindSubmatch(body); match != nil {
+				expansion.Title = strings.Join(strings.Fields(string(match[1])), " ")
+			}
+			return expansion, nil
+		}
+
+		resolved, err := target.Parse(next)
+		if err != nil {
+			expansion.Hops = append(expansion.Hops, hop)
+			return expansion, fmt.Errorf("bad redirect target %q", next)
+		}
+		hop.Location = resolved.String()
+		expansion.Hops = append(expansion.Hops, hop)
+		current, via = resolved.String(), nextVia
+	}
+
+	return expansion, fmt.Errorf("stopped after %d redirects", maxRedirects)
+}
+
+// normalizeLink adds a missing scheme and checks the link has a host
+func normalizeLink(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("invalid URL: %s", rawURL)
+	}
+	return parsed.String(), nil
+}
+
+// isShortenedLink reports whether a link points at a known shortening service
+func isShortenedLink(link string) bool {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	return shortenerHosts[strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")]
+}
+
+// expandShortLinks expands the shortened links mentioned in free text such as a
+// profile bio, skipping links that fail to resolve
+func expandShortLinks(ctx context.Context, text string, limit int) []URLExpansion {
+	var expansions []URLExpansion
+	for _, link := range linkRegex.FindAllString(text, -1) {
+		if len(expansions) >= limit {
+			break
+		}
+		link = strings.TrimRight(link, ".,;:!?)")
+		if !isShortenedLink(link) && !isShortenedLink("http://"+link) {
+			continue
+		}
+		if expansion, err := ExpandURL(ctx, link); err == nil {
+			expansions = append(expansions, *expansion)
+		}
+	}
+	return expansions
+}
+
+// checkPublicHost refuses hosts that resolve to private or local addresses, so a
+// crafted link can't make the tool probe the analyst's own network
+func checkPublicHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicIP(ip) {
+			return fmt.Errorf("refusing to follow link to private address %s", host)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return fmt.Errorf("refusing to follow link to %s, which resolves to private address %s", host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// DisplayResults formats and displays the redirect chain
+func (e *URLExpansion) DisplayResults() {
+	color.Cyan("\n=== URL EXPANSION ===")
+	color.Yellow("URL: %s", e.URL)
+	if e.Shortened {
+		color.Yellow("Shortened link")
+	}
+	color.Yellow("Lands on: %s", e.FinalURL)
+	if e.Title != "" {
+		color.Yellow("Page title: %s", e.Title)
+	}
+
+	if len(e.Hops) > 0 {
+		color.Cyan("\n[Redirect Chain]")
+		displayRedirectHops(e.Hops)
+	}
+}
+
+// displayRedirectHops prints each hop of a redirect chain
+func displayRedirectHops(hops []RedirectHop) {
+	for i, hop := range hops {
+		if hop.Via != "" {
+			color.White("%d. [%d] %s (via %s)", i+1, hop.Status, hop.URL, hop.Via)
+		} else {
+			color.White("%d. [%d] %s", i+1, hop.Status, hop.URL)
+		}
+		if len(hop.Cookies) > 0 {
+			color.White("   sets cookies: %s", strings.Join(hop.Cookies, ", "))
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// TriageResult is the assessment of a suspicious link
+type TriageResult struct {
+	URL             string                 `json:"url"`
+	ScanID          string                 `json:"scan_id,omitempty"`
+	FinalURL        string                 `json:"final_url"`
+	Shortened       bool                   `json:"shortened"`
+	Redirects       []RedirectHop          `json:"redirects"`
+	PageTitle       string                 `json:"page_title,omitempty"`
+	Screenshot      string                 `json:"screenshot,omitempty"`
+	Verdicts        []URLVerdict           `json:"verdicts,omitempty"`
+	URLScan         *URLScanSubmission     `json:"urlscan,omitempty"`
+	Hosting         *DomainIntelResult     `json:"hosting,omitempty"`
+	Metadata        map[string]interface{} `json:"metadata"`
+	SearchTimestamp string                 `json:"search_timestamp"`
+	PartialErrors   []ModuleError          `json:"partial_errors,omitempty"`
+}
+
+// URLVerdict is a reputation service's opinion of a URL
+type URLVerdict struct {
+	Provider   string `json:"provider"`
+	URL        string `json:"url"`
+	Malicious  bool   `json:"malicious"`
+	Category   string `json:"category,omitempty"`
+	Details    string `json:"details,omitempty"`
+	Link       string `json:"link,omitempty"`
+	Screenshot string `json:"screenshot,omitempty"`
+}
+
+// TriageURL expands a link and checks where it leads against reputation services
+func TriageURL(ctx context.Context, rawURL string) (*TriageResult, error) {
+	startTime := time.Now()
+
+	parsed, err := normalizeLink(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TriageResult{
+		ScanID:          providers.ScanIDFrom(ctx),
+		URL:             parsed,
+		FinalURL:        parsed,
+		Shortened:       isShortenedLink(parsed),
+		Metadata:        make(map[string]interface{}),
+		SearchTimestamp: time.Now().Format(time.RFC3339),
+	}
+
+	graph := newTaskGraph(ConcurrentRequests)
+
+	graph.add("redirects", 3*RequestTimeout, func(ctx context.Context) error {
+		expansion, err := ExpandURL(ctx, result.URL)
+		if expansion != nil {
+			result.Redirects = expansion.Hops
+			result.PageTitle = expansion.Title
+			result.FinalURL = expansion.FinalURL
+		}
+		return err
+	})
+
+	// Reputation is checked for the link as given and where it ends up
+	graph.add("reputation", 2*RequestTimeout, func(ctx context.Context) error {
+		targets := []string{result.URL}
+		if result.FinalURL != result.URL {
+			targets = append(targets, result.FinalURL)
+		}
+		verdicts, err := checkURLReputation(ctx, targets)
+		result.Verdicts = verdicts
+		for _, verdict := range verdicts {
+			if verdict.Screenshot != "" && result.Screenshot == "" {
+				result.Screenshot = verdict.Screenshot
+			}
+		}
+		return err
+	}, "redirects")
+
+	// A fresh urlscan.io scan follows the link itself from urlscan's side
+	if URLScanSubmit {
+		graph.add("urlscan_submit", urlscanMaxWait+2*RequestTimeout, func(ctx context.Context) error {
+			submission, err := SubmitURLScan(ctx, result.URL, URLScanVisibility)
+			result.URLScan = submission
+			if submission != nil && err == nil {
+				result.Screenshot = submission.Screenshot
+			}
+			return err
+		})
+	}
+
+	// Correlate the landing page's infrastructure with the domain module
+	graph.add("hosting", 4*RequestTimeout, func(ctx context.Context) error {
+		landing, err := url.Parse(result.FinalURL)
+		if err != nil {
+			return err
+		}
+		if err := checkPublicHost(ctx, landing.Hostname()); err != nil {
+			return err
+		}
+		hosting, err := AnalyzeDomain(ctx, strings.ToLower(landing.Hostname()))
+		result.Hosting = hosting
+		return err
+	}, "redirects")
+
+	taskErrs, err := graph.run(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, name := range graph.order() {
+		if taskErr, failed := taskErrs[name]; failed {
+			result.PartialErrors = append(result.PartialErrors, ModuleError{Module: name, Error: taskErr.Error()})
+		}
+	}
+
+	result.Metadata["hop_count"] = len(result.Redirects)
+	result.Metadata["execution_time_ms"] = time.Since(startTime).Milliseconds()
+	return result, nil
+}
+
+// checkURLReputation asks every configured reputation service about each URL.
+// Unlike fallback chains, each service adds its own verdict.
+func checkURLReputation(ctx context.Context, targets []string) ([]URLVerdict, error) {
+	var verdicts []URLVerdict
+	var errs []string
+
+	for _, provider := range URLReputationProviders {
+		if err := checkHealth(ctx, provider); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: unhealthy: %v", provider.Name(), err))
+			continue
+		}
+		for _, target := range targets {
+			verdict, err := provider.CheckURL(ctx, target)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", provider.Name(), err))
+				continue
+			}
+			verdicts = append(verdicts, verdict)
+		}
+	}
+
+	if len(errs) > 0 {
+		return verdicts, fmt.Errorf("reputation checks failed (%s)", strings.Join(errs, "; "))
+	}
+	return verdicts, nil
+}
+
+// DisplayResults formats and displays the triage results
+func (r *TriageResult) DisplayResults() {
+	color.Cyan("\n=== URL TRIAGE RESULTS ===")
+	color.Yellow("URL: %s", r.URL)
+	if r.Shortened {
+		color.Yellow("Shortened link")
+	}
+	color.Yellow("Lands on: %s", r.FinalURL)
+	if r.PageTitle != "" {
+		color.Yellow("Page title: %s", r.PageTitle)
+	}
+
+	if len(r.Redirects) > 0 {
+		color.Cyan("\n[Redirect Chain]")
+		displayRedirectHops(r.Redirects)
+	}
+
+	if len(r.Verdicts) > 0 {
+		color.Cyan("\n[Reputation]")
+		for _, verdict := range r.Verdicts {
+			line := fmt.Sprintf("%s: %s", verdict.Provider, verdict.URL)
+			if verdict.Category != "" {
+				line += " (" + verdict.Category + ")"
+			}
+			if verdict.Malicious {
+				color.Red("✗ %s", line)
+			} else {
+				color.Green("✓ %s", line)
+			}
+			if verdict.Details != "" {
+				color.White("  - %s", verdict.Details)
+			}
+			if verdict.Link != "" {
+				color.White("  - %s", verdict.Link)
+			}
+		}
+	}
+	if scan := r.URLScan; scan != nil {
+		color.Cyan("\n[urlscan.io Scan]")
+		color.White("• Result: %s (%s)", scan.ResultURL, scan.Visibility)
+		if scan.Verdict.Malicious {
+			color.Red("✗ Malicious: %s", scan.Verdict.Category)
+		}
+		if scan.PageIP != "" {
+			color.White("• Served from %s (%s)", scan.PageIP, scan.PageCountry)
+		}
+		color.White("• Contacted %d domains, %d IPs, %d URLs", len(scan.IOCs.Domains), len(scan.IOCs.IPs), len(scan.IOCs.URLs))
+		if scan.ScreenshotFile != "" {
+			color.White("• Screenshot saved to %s", scan.ScreenshotFile)
+		}
+		if scan.DOMFile != "" {
+			color.White("• DOM saved to %s", scan.DOMFile)
+		}
+	}
+	if r.Screenshot != "" {
+		color.White("\nScreenshot: %s", r.Screenshot)
+	}
+
+	if r.Hosting != nil {
+		color.Cyan("\n[Hosting]")
+		color.White("• Domain: %s", r.Hosting.Domain)
+		for _, ip := range r.Hosting.DNS.IPAddresses {
+			color.White("• IP: %s", ip)
+		}
+		for _, host := range r.Hosting.DNS.HostIntel {
+			if host.Org != "" {
+				color.White("• %s is run by %s", host.IP, host.Org)
+			}
+		}
+		for _, tech := range r.Hosting.Technologies {
+			color.White("• %s (%s)", tech.Name, tech.Category)
+		}
+	}
+	if r.Hosting != nil {
+		displayVTReport(r.Hosting.VirusTotal)
+	}
+}
+
+// DisplayPartialErrors lists checks that failed during triage
+func (r *TriageResult) DisplayPartialErrors() {
+	displayModuleErrors(r.PartialErrors)
+}
@@ -0,0 +1,240 @@
+package osint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// URLScanSubmission is a fresh urlscan.io scan of a URL and what it found
+type URLScanSubmission struct {
+	UUID           string      `json:"uuid"`
+	Visibility     string      `json:"visibility"`
+	ResultURL      string      `json:"result_url"`
+	Finished       bool        `json:"finished"`
+	Screenshot     string      `json:"screenshot"`
+	DOM            string      `json:"dom"`
+	PageTitle      string      `json:"page_title,omitempty"`
+	PageIP         string      `json:"page_ip,omitempty"`
+	PageCountry    string      `json:"page_country,omitempty"`
+	Verdict        URLVerdict  `json:"verdict"`
+	IOCs           URLScanIOCs `json:"iocs"`
+	ScreenshotFile string      `json:"screenshot_file,omitempty"`
+	DOMFile        string      `json:"dom_file,omitempty"`
+}
+
+// URLScanIOCs are the indicators urlscan.io observed while loading the page
+type URLScanIOCs struct {
+	IPs          []string `json:"ips,omitempty"`
+	Domains      []string `json:"domains,omitempty"`
+	URLs         []string `json:"urls,omitempty"`
+	Hashes       []string `json:"hashes,omitempty"`
+	ASNs         []string `json:"asns,omitempty"`
+	Certificates []string `json:"certificates,omitempty"`
+}
+
+// urlscan.io submission settings
+var (
+	URLScanSubmit     = false      // Submit triaged URLs for a new scan
+	URLScanVisibility = "unlisted" // public, unlisted or private
+	urlscanPollDelay  = 5 * time.Second
+	urlscanMaxWait    = 2 * time.Minute
+)
+
+// urlscanVisibilities are the visibility levels urlscan.io accepts
+var urlscanVisibilities = map[string]bool{"public": true, "unlisted": true, "private": true}
+
+// SubmitURLScan submits a URL to urlscan.io and waits for the scan to finish
+func SubmitURLScan(ctx context.Context, target, visibility string) (*URLScanSubmission, error) {
+	if !apiKeyConfigured(APIConfig.URLScanKey) {
+		return nil, fmt.Errorf("urlscan.io API key not configured")
+	}
+	if !urlscanVisibilities[visibility] {
+		return nil, fmt.Errorf("invalid visibility %q, expected public, unlisted or private", visibility)
+	}
+
+	body, err := json.Marshal(map[string]string{"url": target, "visibility": visibility})
+	if err != nil {
+		return nil, err
+	}
+
+	var submitted struct {
+		UUID       string `json:"uuid"`
+		Result     string `json:"result"`
+		Visibility string `json:"visibility"`
+		Message    string `json:"message"`
+	}
+	headers := map[string]string{"API-Key": APIConfig.URLScanKey, "Content-Type": "application/json"}
+	if err := postProviderJSON(ctx, "https://urlscan.io/api/v1/scan/", headers, strings.NewReader(string(body)), &submitted); err != nil {
+		return nil, fmt.Errorf("submission failed: %v", err)
+	}
+	if submitted.UUID == "" {
+		return nil, fmt.Errorf("submission failed: %s", submitted.Message)
+	}
+
+	submission := &URLScanSubmission{
+		UUID:       submitted.UUID,
+		Visibility: submitted.Visibility,
+		ResultURL:  submitted.Result,
+		Screenshot: "https://urlscan.io/screenshots/" + submitted.UUID + ".png",
+		DOM:        "https://urlscan.io/dom/" + submitted.UUID + "/",
+		Verdict:    URLVerdict{Provider: "urlscan.io", URL: target, Link: submitted.Result},
+	}
+
+	if err := pollURLScanResult(ctx, submission); err != nil {
+		return submission, err
+	}
+	return submission, nil
+}
+
+// pollURLScanResult waits for a submitted scan and fills in its verdict and IOCs.
+// The result endpoint answers 404 until the scan has finished.
+func pollURLScanResult(ctx context.Context, submission *URLScanSubmission) error {
+	deadline := time.Now().Add(urlscanMaxWait)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(urlscanPollDelay):
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", "https://urlscan.io/api/v1/result/"+url.PathEscape(submission.UUID)+"/", nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("User-Agent", UserAgent)
+		req.Header.Set("API-Key", APIConfig.URLScanKey)
+
+		resp, err := doProviderRequest(req)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			if time.Now().After(deadline) {
+				return fmt.Errorf("scan %s did not finish within %s", submission.UUID, urlscanMaxWait)
+			}
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("result returned status code %d", resp.StatusCode)
+		}
+
+		var result struct {
+			Page struct {
+				Title   string `json:"title"`
+				IP      string `json:"ip"`
+				Country string `json:"country"`
+			} `json:"page"`
+			Verdicts struct {
+				Overall struct {
+					Malicious  bool     `json:"malicious"`
+					Score      int      `json:"score"`
+					Categories []string `json:"categories"`
+					Brands     []string `json:"brands"`
+				} `json:"overall"`
+			} `json:"verdicts"`
+			Lists struct {
+				IPs          []string `json:"ips"`
+				Domains      []string `json:"domains"`
+				URLs         []string `json:"urls"`
+				Hashes       []string `json:"hashes"`
+				ASNs         []string `json:"asns"`
+				Certificates []struct {
+					SubjectName string `json:"subjectName"`
+					Issuer      string `json:"issuer"`
+				} `json:"certificates"`
+			} `json:"lists"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		overall := result.Verdicts.Overall
+		submission.Finished = true
+		submission.PageTitle = result.Page.Title
+		submission.PageIP = result.Page.IP
+		submission.PageCountry = result.Page.Country
+		submission.Verdict.Malicious = overall.Malicious
+		submission.Verdict.Category = strings.Join(append(overall.Categories, overall.Brands...), ", ")
+		submission.Verdict.Details = fmt.Sprintf("new %s scan, score %d", submission.Visibility, overall.Score)
+		submission.Verdict.Screenshot = submission.Screenshot
+
+		submission.IOCs = URLScanIOCs{
+			IPs:     result.Lists.IPs,
+			Domains: result.Lists.Domains,
+			URLs:    result.Lists.URLs,
+			Hashes:  result.Lists.Hashes,
+			ASNs:    result.Lists.ASNs,
+		}
+		for _, cert := range result.Lists.Certificates {
+			submission.IOCs.Certificates = append(submission.IOCs.Certificates, fmt.Sprintf("%s (issued by %s)", cert.SubjectName, cert.Issuer))
+		}
+		return nil
+	}
+}
+
+// SaveArtifacts downloads the scan's screenshot and DOM snapshot into dir
+func (s *URLScanSubmission) SaveArtifacts(ctx context.Context, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	// Private scans are only served to the submitting key
+	headers := map[string]string{"API-Key": APIConfig.URLScanKey}
+
+	screenshot := filepath.Join(dir, "urlscan-"+s.UUID+".png")
+	if err := downloadFile(ctx, s.Screenshot, screenshot, headers); err != nil {
+		return fmt.Errorf("screenshot: %v", err)
+	}
+	s.ScreenshotFile = screenshot
+
+	dom := filepath.Join(dir, "urlscan-"+s.UUID+".html")
+	if err := downloadFile(ctx, s.DOM, dom, headers); err != nil {
+		return fmt.Errorf("DOM: %v", err)
+	}
+	s.DOMFile = dom
+	return nil
+}
+
+// downloadFile saves the body of a GET request to path
+func downloadFile(ctx context.Context, source, path string, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", source, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := doProviderRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, io.LimitReader(resp.Body, maxSourceBodySize))
+	return err
+}
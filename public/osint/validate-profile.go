@@ -2,12 +2,12 @@ package osint
 
 import (
 	"fmt"
-	"io"
 	"math"
 	"net/http"
 	"regexp"
 	"strings"
-	"time"
+
+	"github.com/awion/MercuriesOST/public/parse"
 )
 
 // ValidationResult stores the validation status and details
@@ -51,8 +51,8 @@ func ValidateProfile(client *http.Client, platform SocialPlatform, url string, u
 	req.Header.Set("Sec-Fetch-User", "?1")
 	req.Header.Set("Upgrade-Insecure-Requests", "1")
 
-	// Perform request with timeout
-	client.Timeout = 15 * time.Second
+	// Perform request with timeout, on a copy since the caller's client is shared between workers
+	client = &http.Client{Transport: client.Transport, Jar: client.Jar, Timeout: RequestTimeout}
 
 	// Enable cookie jar and follow redirects, but track them
 	var finalURL string
@@ -94,12 +94,15 @@ func ValidateProfile(client *http.Client, platform SocialPlatform, url string, u
 	}
 
 	// Read body content for analysis
-	bodyBytes, err := io.ReadAll(resp.Body)
+	body, err := readPageBody(resp)
 	if err != nil {
 		result.ErrorReason = fmt.Sprintf("Error reading response body: %v", err)
 		return result
 	}
-	bodyContent := string(bodyBytes)
+	if body.Truncated {
+		result.Markers = append(result.Markers, fmt.Sprintf("Page truncated at %d bytes", MaxBodySize))
+	}
+	bodyContent := body.String()
 
 	// Generic error phrases that indicate a profile doesn't exist
 	nonExistentPhrases := []string{
@@ -204,6 +207,44 @@ func ValidateProfile(client *http.Client, platform SocialPlatform, url string, u
 				result.Markers = append(result.Markers, "Personal profile detected")
 			}
 
+		case "Telegram":
+			// t.me renders a page for any name; only existing accounts,
+			// channels and invites have a title
+			if !strings.Contains(bodyContent, "tgme_page_title") {
+				result.IsValid = false
+				result.Confidence = 0.9
+				result.ErrorReason = "No account, channel or invite behind this link"
+				return result
+			}
+			if strings.Contains(bodyContent, "tg://join?invite=") {
+				result.ProfileType = "invite"
+				result.Markers = append(result.Markers, "Group or channel invite link")
+			}
+
+		case "Venmo", "PayPal", "CashApp":
+			// Payment sites answer unknown handles with a sign-up or home
+			// page; only profiles carry the name people pay
+			if pageDisplayName(platform, bodyContent) == "" {
+				result.IsValid = false
+				result.Confidence = 0.9
+				result.ErrorReason = "No public payment profile behind this handle"
+				return result
+			}
+			result.ProfileType = "payment"
+			result.Markers = append(result.Markers, "Payment profile with a display name")
+
+		case "GarminConnect":
+			// The app shell loads for every name; public profiles embed
+			// the owner's name
+			if pageDisplayName(platform, bodyContent) == "" {
+				result.IsValid = false
+				result.Confidence = 0.6
+				result.ErrorReason = "No public Garmin Connect profile behind this name"
+				return result
+			}
+			result.ProfileType = "fitness"
+			result.Markers = append(result.Markers, "Public fitness profile with a name")
+
 		case "LinkedIn":
 			// Check for LinkedIn-specific indicators
 			if strings.Contains(bodyContent, "page not found") ||
@@ -237,8 +278,7 @@ func ValidateProfile(client *http.Client, platform SocialPlatform, url string, u
 			}
 
 			// Check for karma indicators - strong sign of real account
-			karmaRe := regexp.MustCompile(`(\d+) karma`)
-			if karmaRe.MatchString(bodyContent) {
+			if _, err := parse.QuantityBefore(bodyContent, "karma"); err == nil {
 				result.Confidence = 0.9
 				result.Markers = append(result.Markers, "Karma count found - active account")
 			}
@@ -0,0 +1,224 @@
+package osint
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// VirusTotal indicator types
+const (
+	VTDomain = "domain"
+	VTIP     = "ip"
+	VTFile   = "file"
+)
+
+// VTReport summarizes what VirusTotal knows about an indicator
+type VTReport struct {
+	Indicator      string         `json:"indicator"`
+	Type           string         `json:"type"`
+	Link           string         `json:"link"`
+	Malicious      int            `json:"malicious"`
+	Suspicious     int            `json:"suspicious"`
+	Engines        int            `json:"engines"`
+	Reputation     int            `json:"reputation"`
+	Categories     []string       `json:"categories,omitempty"`
+	FileName       string         `json:"file_name,omitempty"`
+	FileType       string         `json:"file_type,omitempty"`
+	PassiveDNS     []VTResolution `json:"passive_dns,omitempty"`
+	RelatedSamples []VTSample     `json:"related_samples,omitempty"`
+}
+
+// VTResolution is a passive DNS record seen by VirusTotal
+type VTResolution struct {
+	Host string `json:"host"`
+	IP   string `json:"ip"`
+	Date string `json:"date"`
+}
+
+// VTSample is a file VirusTotal has seen communicating with an indicator
+type VTSample struct {
+	SHA256    string `json:"sha256"`
+	Name      string `json:"name,omitempty"`
+	Malicious int    `json:"malicious"`
+	Engines   int    `json:"engines"`
+	Link      string `json:"link"`
+}
+
+// vtRelationLimit caps passive DNS records and samples fetched per indicator
+const vtRelationLimit = 10
+
+// vtAnalysisStats is the engine verdict count VirusTotal attaches to every object
+type vtAnalysisStats struct {
+	Malicious  int `json:"malicious"`
+	Suspicious int `json:"suspicious"`
+	Harmless   int `json:"harmless"`
+	Undetected int `json:"undetected"`
+	Timeout    int `json:"timeout"`
+}
+
+func (s vtAnalysisStats) engines() int {
+	return s.Malicious + s.Suspicious + s.Harmless + s.Undetected + s.Timeout
+}
+
+// vtConfigured reports whether a VirusTotal API key has been set
+func vtConfigured() bool {
+	return apiKeyConfigured(APIConfig.VirusTotalKey)
+}
+
+// LookupVirusTotal fetches the detection ratio of a domain, IP or file hash along
+// with its passive DNS and, for domains and IPs, the samples seen contacting it.
+// Relationship failures are returned alongside the partial report.
+func LookupVirusTotal(ctx context.Context, indicatorType, value string) (*VTReport, error) {
+	if !vtConfigured() {
+		return nil, fmt.Errorf("VirusTotal API key not configured")
+	}
+
+	var collection, gui string
+	switch indicatorType {
+	case VTDomain:
+		collection, gui = "domains", "domain"
+	case VTIP:
+		collection, gui = "ip_addresses", "ip-address"
+	case VTFile:
+		collection, gui = "files", "file"
+	default:
+		return nil, fmt.Errorf("unsupported indicator type %q", indicatorType)
+	}
+
+	report := &VTReport{
+		Indicator: value,
+		Type:      indicatorType,
+		Link:      fmt.Sprintf("https://www.virustotal.com/gui/%s/%s", gui, url.PathEscape(value)),
+	}
+
+	var object struct {
+		Data struct {
+			Attributes struct {
+				Stats          vtAnalysisStats   `json:"last_analysis_stats"`
+				Reputation     int               `json:"reputation"`
+				Categories     map[string]string `json:"categories"`
+				MeaningfulName string            `json:"meaningful_name"`
+				TypeDesc       string            `json:"type_description"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	base := "https://www.virustotal.com/api/v3/" + collection + "/" + url.PathEscape(value)
+	if err := getVirusTotal(ctx, base, &object); err != nil {
+		return nil, err
+	}
+
+	attrs := object.Data.Attributes
+	report.Malicious = attrs.Stats.Malicious
+	report.Suspicious = attrs.Stats.Suspicious
+	report.Engines = attrs.Stats.engines()
+	report.Reputation = attrs.Reputation
+	report.FileName = attrs.MeaningfulName
+	report.FileType = attrs.TypeDesc
+	for _, category := range attrs.Categories {
+		report.Categories = mergeStrings(report.Categories, []string{strings.ToLower(category)})
+	}
+	sort.Strings(report.Categories)
+
+	if indicatorType == VTFile {
+		return report, nil
+	}
+
+	var errs []string
+
+	var resolutions struct {
+		Data []struct {
+			Attributes struct {
+				Date      int64  `json:"date"`
+				HostName  string `json:"host_name"`
+				IPAddress string `json:"ip_address"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := getVirusTotal(ctx, fmt.Sprintf("%s/resolutions?limit=%d", base, vtRelationLimit), &resolutions); err != nil {
+		errs = append(errs, "passive DNS: "+err.Error())
+	}
+	for _, resolution := range resolutions.Data {
+		report.PassiveDNS = append(report.PassiveDNS, VTResolution{
+			Host: resolution.Attributes.HostName,
+			IP:   resolution.Attributes.IPAddress,
+			Date: time.Unix(resolution.Attributes.Date, 0).UTC().Format("2006-01-02"),
+		})
+	}
+
+	var samples struct {
+		Data []struct {
+			ID         string `json:"id"`
+			Attributes struct {
+				MeaningfulName string          `json:"meaningful_name"`
+				Stats          vtAnalysisStats `json:"last_analysis_stats"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := getVirusTotal(ctx, fmt.Sprintf("%s/communicating_files?limit=%d", base, vtRelationLimit), &samples); err != nil {
+		errs = append(errs, "related samples: "+err.Error())
+	}
+	for _, sample := range samples.Data {
+		report.RelatedSamples = append(report.RelatedSamples, VTSample{
+			SHA256:    sample.ID,
+			Name:      sample.Attributes.MeaningfulName,
+			Malicious: sample.Attributes.Stats.Malicious,
+			Engines:   sample.Attributes.Stats.engines(),
+			Link:      "https://www.virustotal.com/gui/file/" + sample.ID,
+		})
+	}
+
+	if len(errs) > 0 {
+		return report, fmt.Errorf("VirusTotal %s", strings.Join(errs, "; "))
+	}
+	return report, nil
+}
+
+// getVirusTotal performs an authenticated API request. The shared VirusTotal
+// client keeps lookups within the public quota of 4 requests per minute.
+func getVirusTotal(ctx context.Context, target string, out interface{}) error {
+	return getProviderJSON(ctx, target, map[string]string{"x-apikey": APIConfig.VirusTotalKey}, out)
+}
+
+// displayVTReport prints a VirusTotal summary with links instead of raw data
+func displayVTReport(report *VTReport) {
+	if report == nil {
+		return
+	}
+
+	color.Cyan("\n[VirusTotal]")
+	line := fmt.Sprintf("%s: %d/%d engines flag it as malicious", report.Indicator, report.Malicious, report.Engines)
+	if report.Malicious > 0 {
+		color.Red("✗ %s", line)
+	} else {
+		color.Green("✓ %s", line)
+	}
+	if report.Suspicious > 0 {
+		color.Yellow("• %d engines flag it as suspicious", report.Suspicious)
+	}
+	if len(report.Categories) > 0 {
+		color.White("• Categories: %s", strings.Join(report.Categories, ", "))
+	}
+	if report.FileName != "" {
+		color.White("• File: %s (%s)", report.FileName, report.FileType)
+	}
+	if len(report.PassiveDNS) > 0 {
+		color.White("• Passive DNS: %d resolutions, latest %s → %s (%s)", len(report.PassiveDNS),
+			report.PassiveDNS[0].Host, report.PassiveDNS[0].IP, report.PassiveDNS[0].Date)
+	}
+	flagged := 0
+	for _, sample := range report.RelatedSamples {
+		if sample.Malicious > 0 {
+			flagged++
+		}
+	}
+	if len(report.RelatedSamples) > 0 {
+		color.White("• Related samples: %d communicating files, %d flagged", len(report.RelatedSamples), flagged)
+	}
+	color.White("• Details: %s", report.Link)
+}
@@ -0,0 +1,216 @@
+package osint
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Feed formats for watchlist findings
+const (
+	FeedAtom = "atom"
+	FeedRSS  = "rss"
+)
+
+// maxFeedEntries caps how many findings a feed carries, newest first
+const maxFeedEntries = 100
+
+// feedEntry is a finding paired with the watchlist item it belongs to
+type feedEntry struct {
+	item    *WatchItem
+	finding WatchFinding
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID       string     `xml:"id"`
+	Title    string     `xml:"title"`
+	Updated  string     `xml:"updated"`
+	Link     *atomLink  `xml:"link,omitempty"`
+	Category atomTerm   `xml:"category"`
+	Summary  string     `xml:"summary,omitempty"`
+	Author   atomAuthor `xml:"author"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomTerm struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Category    string  `xml:"category"`
+	Description string  `xml:"description,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// entries returns the watchlist's findings across all items, newest first
+func (w *Watchlist) entries() []feedEntry {
+	var entries []feedEntry
+	for _, item := range w.Items {
+		for _, finding := range item.Findings {
+			entries = append(entries, feedEntry{item: item, finding: finding})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].finding.FirstSeen > entries[j].finding.FirstSeen
+	})
+	if len(entries) > maxFeedEntries {
+		entries = entries[:maxFeedEntries]
+	}
+	return entries
+}
+
+// Feed renders the watchlist's findings as an Atom or RSS 2.0 document. Entries
+// are dated by when a finding was first seen so readers only flag new ones.
+func (w *Watchlist) Feed(format string) ([]byte, error) {
+	entries := w.entries()
+	title := "MercuriesOST watchlist: " + w.Name
+	updated := w.Created
+	if len(entries) > 0 {
+		updated = entries[0].finding.FirstSeen
+	}
+
+	var doc interface{}
+	switch format {
+	case FeedAtom:
+		feed := atomFeed{
+			ID:      "urn:mercuries:watchlist:" + w.Name,
+			Title:   title,
+			Updated: updated,
+		}
+		for _, entry := range entries {
+			atom := atomEntry{
+				ID:       "urn:mercuries:finding:" + entry.finding.ID,
+				Title:    fmt.Sprintf("[%s] %s", entry.item.Value, entry.finding.Title),
+				Updated:  entry.finding.FirstSeen,
+				Category: atomTerm{Term: entry.finding.Module},
+				Summary:  entry.finding.Details,
+				Author:   atomAuthor{Name: "MercuriesOST"},
+			}
+			if entry.finding.URL != "" {
+				atom.Link = &atomLink{Href: entry.finding.URL}
+			}
+			feed.Entries = append(feed.Entries, atom)
+		}
+		doc = feed
+
+	case FeedRSS:
+		feed := rssFeed{Version: "2.0", Channel: rssChannel{
+			Title:       title,
+			Link:        "https://github.com/awiones/MercuriesOST",
+			Description: fmt.Sprintf("New findings for the %d items on watchlist %s", len(w.Items), w.Name),
+		}}
+		for _, entry := range entries {
+			pubDate := entry.finding.FirstSeen
+			if parsed, err := time.Parse(time.RFC3339, pubDate); err == nil {
+				pubDate = parsed.Format(time.RFC1123Z)
+			}
+			feed.Channel.Items = append(feed.Channel.Items, rssItem{
+				Title:       fmt.Sprintf("[%s] %s", entry.item.Value, entry.finding.Title),
+				Link:        entry.finding.URL,
+				GUID:        rssGUID{Value: entry.finding.ID},
+				PubDate:     pubDate,
+				Category:    entry.finding.Module,
+				Description: entry.finding.Details,
+			})
+		}
+		doc = feed
+
+	default:
+		return nil, fmt.Errorf("unknown feed format %q, expected atom or rss", format)
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// WriteFeed saves the watchlist's feed to a file
+func (w *Watchlist) WriteFeed(path, format string) error {
+	data, err := w.Feed(format)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// FeedHandler serves /<name>.atom and /<name>.rss for watchlists in WatchlistDir,
+// reading them on every request so feeds reflect the latest run
+func FeedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Base(r.URL.Path)
+		format := strings.TrimPrefix(path.Ext(name), ".")
+		name = strings.TrimSuffix(name, path.Ext(name))
+
+		contentType := map[string]string{
+			FeedAtom: "application/atom+xml; charset=utf-8",
+			FeedRSS:  "application/rss+xml; charset=utf-8",
+		}[format]
+		if contentType == "" || !watchlistNameRegex.MatchString(name) {
+			http.NotFound(w, r)
+			return
+		}
+		if _, err := os.Stat(watchlistPath(name)); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		list, err := LoadWatchlist(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data, err := list.Feed(format)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		if list.LastScanID != "" {
+			w.Header().Set("X-Scan-ID", list.LastScanID)
+		}
+		w.Write(data)
+	})
+}
@@ -0,0 +1,399 @@
+package osint
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awion/MercuriesOST/public/input"
+	"github.com/awion/MercuriesOST/public/providers"
+	"github.com/fatih/color"
+)
+
+// Watchlist item kinds
+const (
+	WatchBrand  = "brand"
+	WatchPerson = "person"
+	WatchDomain = "domain"
+)
+
+// Watchlist is a named set of brands, people and domains monitored together
+type Watchlist struct {
+	Name       string       `json:"name"`
+	Created    string       `json:"created"`
+	LastScanID string       `json:"last_scan_id,omitempty"`
+	Items      []*WatchItem `json:"items"`
+}
+
+// WatchItem is a monitored name and its rolling feed of findings, newest first
+type WatchItem struct {
+	Kind     string         `json:"kind"`
+	Value    string         `json:"value"`
+	Added    string         `json:"added"`
+	LastRun  string         `json:"last_run,omitempty"`
+	Findings []WatchFinding `json:"findings,omitempty"`
+}
+
+// WatchFinding is something observed for a watchlist item. ID is stable across
+// runs so repeat sightings update LastSeen instead of raising a new finding.
+type WatchFinding struct {
+	ID        string `json:"id"`
+	Module    string `json:"module"`
+	Title     string `json:"title"`
+	URL       string `json:"url,omitempty"`
+	Details   string `json:"details,omitempty"`
+	FirstSeen string `json:"first_seen"`
+	LastSeen  string `json:"last_seen"`
+	ScanID    string `json:"scan_id,omitempty"` // Run that first saw it
+}
+
+// WatchRunResult lists the findings that are new since the previous run
+type WatchRunResult struct {
+	Watchlist string         `json:"watchlist"`
+	ScanID    string         `json:"scan_id,omitempty"`
+	Timestamp string         `json:"timestamp"`
+	Items     []WatchItemRun `json:"items"`
+	Unchanged int64          `json:"unchanged_pages,omitempty"` // Answered 304 Not Modified since the last run
+	Changed   int64          `json:"changed_pages,omitempty"`   // Fetched again because they changed
+}
+
+// WatchItemRun is the outcome of checking one watchlist item
+type WatchItemRun struct {
+	Kind  string         `json:"kind"`
+	Value string         `json:"value"`
+	New   []WatchFinding `json:"new"`
+	Error string         `json:"error,omitempty"`
+	// First run of a watched scan; its findings are recorded, not reported as new
+	Baseline bool `json:"baseline,omitempty"`
+}
+
+// Watchlist storage settings
+var (
+	WatchlistDir     = filepath.Join("results", "watchlists")
+	maxWatchFindings = 200
+)
+
+var watchlistNameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+var (
+	watchCacheOnce sync.Once
+	watchCache     *providers.PageCache
+)
+
+// watchPageCache keeps the pages watchlist runs fetch with their ETag and
+// Last-Modified, so the next run asks for them conditionally and unchanged
+// pages cost a 304 instead of a download
+func watchPageCache() *providers.PageCache {
+	watchCacheOnce.Do(func() {
+		watchCache = providers.Revalidate(filepath.Join(WatchlistDir, "http-cache"))
+	})
+	return watchCache
+}
+
+// LoadWatchlist reads a watchlist from WatchlistDir. A watchlist that has not
+// been saved yet is returned empty.
+func LoadWatchlist(name string) (*Watchlist, error) {
+	if !watchlistNameRegex.MatchString(name) {
+		return nil, fmt.Errorf("invalid watchlist name %q, use letters, digits, - and _", name)
+	}
+
+	data, err := os.ReadFile(watchlistPath(name))
+	if os.IsNotExist(err) {
+		return &Watchlist{Name: name, Created: time.Now().Format(time.RFC3339)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list Watchlist
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("parse watchlist %s: %v", name, err)
+	}
+	list.Name = name
+	return &list, nil
+}
+
+// ListWatchlists returns the names of saved watchlists
+func ListWatchlists() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(WatchlistDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, path := range paths {
+		names = append(names, strings.TrimSuffix(filepath.Base(path), ".json"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func watchlistPath(name string) string {
+	return filepath.Join(WatchlistDir, name+".json")
+}
+
+// Save writes the watchlist and its findings feeds to WatchlistDir
+func (w *Watchlist) Save() error {
+	if err := os.MkdirAll(WatchlistDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(watchlistPath(w.Name), data, 0644)
+}
+
+// Add registers a brand, person or domain on the watchlist
+func (w *Watchlist) Add(kind, value string) error {
+	value = strings.TrimSpace(value)
+	switch kind {
+	case WatchBrand, WatchPerson:
+	case WatchDomain:
+		domain, err := input.Domain(value)
+		if err != nil {
+			return err
+		}
+		value = domain
+	default:
+		return fmt.Errorf("unknown watchlist kind %q, expected brand, person or domain", kind)
+	}
+	if value == "" {
+		return fmt.Errorf("empty %s", kind)
+	}
+
+	if w.find(value) != nil {
+		return fmt.Errorf("%q is already on watchlist %s", value, w.Name)
+	}
+	w.Items = append(w.Items, &WatchItem{Kind: kind, Value: value, Added: time.Now().Format(time.RFC3339)})
+	return nil
+}
+
+// Remove drops an item and its findings, reporting whether it was present
+func (w *Watchlist) Remove(value string) bool {
+	for i, item := range w.Items {
+		if strings.EqualFold(item.Value, strings.TrimSpace(value)) {
+			w.Items = append(w.Items[:i], w.Items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Watchlist) find(value string) *WatchItem {
+	for _, item := range w.Items {
+		if strings.EqualFold(item.Value, value) {
+			return item
+		}
+	}
+	return nil
+}
+
+// Run checks every item with the modules suited to its kind and merges what it
+// finds into the item's feed. Items are checked one at a time so a long
+// watchlist does not multiply the load on upstream services.
+func (w *Watchlist) Run(ctx context.Context) *WatchRunResult {
+	result := &WatchRunResult{Watchlist: w.Name, ScanID: providers.ScanIDFrom(ctx), Timestamp: time.Now().Format(time.RFC3339)}
+	w.LastScanID = result.ScanID
+	cache := watchPageCache()
+	unchanged, changed := cache.Stats()
+
+	for _, item := range w.Items {
+		run := WatchItemRun{Kind: item.Kind, Value: item.Value}
+		findings, err := collectWatchFindings(ctx, item)
+		if err != nil {
+			run.Error = err.Error()
+		}
+		run.New = item.merge(findings, result.Timestamp, result.ScanID)
+		item.LastRun = result.Timestamp
+		result.Items = append(result.Items, run)
+	}
+	result.Unchanged, result.Changed = cache.Stats()
+	result.Unchanged -= unchanged
+	result.Changed -= changed
+	return result
+}
+
+// merge adds findings to the feed, returning the ones not seen before, and
+// trims the feed to the newest maxWatchFindings entries
+func (item *WatchItem) merge(findings []WatchFinding, now, scanID string) []WatchFinding {
+	known := make(map[string]int, len(item.Findings))
+	for i, finding := range item.Findings {
+		known[finding.ID] = i
+	}
+
+	var fresh []WatchFinding
+	for _, finding := range findings {
+		if i, ok := known[finding.ID]; ok {
+			item.Findings[i].LastSeen = now
+			continue
+		}
+		finding.FirstSeen, finding.LastSeen = now, now
+		finding.ScanID = scanID
+		known[finding.ID] = -1
+		fresh = append(fresh, finding)
+	}
+
+	item.Findings = append(fresh, item.Findings...)
+	if len(item.Findings) > maxWatchFindings {
+		item.Findings = item.Findings[:maxWatchFindings]
+	}
+	return fresh
+}
+
+// collectWatchFindings runs the modules for an item's kind. Findings gathered
+// before a module fails are still returned.
+func collectWatchFindings(ctx context.Context, item *WatchItem) ([]WatchFinding, error) {
+	var findings []WatchFinding
+	var errs []string
+
+	switch item.Kind {
+	case WatchDomain:
+		info, err := getDomainInfo(ctx, item.Value)
+		if err != nil {
+			errs = append(errs, "dns: "+err.Error())
+		}
+		for _, ip := range info.IPAddresses {
+			findings = append(findings, newWatchFinding(item, "dns", ip, "Resolves to "+ip, "", ""))
+		}
+		for _, mx := range info.MXRecords {
+			findings = append(findings, newWatchFinding(item, "dns", "mx:"+mx.Host, "Mail handled by "+mx.Host, "", ""))
+		}
+		lookalikes, err := watchLookalikes(ctx, item, item.Value)
+		findings = append(findings, lookalikes...)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+
+	case WatchBrand:
+		label := brandLabel(item.Value)
+		if label == "" {
+			return nil, fmt.Errorf("brand %q has no usable characters", item.Value)
+		}
+		lookalikes, err := watchLookalikes(ctx, item, label+".com")
+		findings = append(findings, lookalikes...)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		profiles, err := watchProfiles(ctx, item, label)
+		findings = append(findings, profiles...)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+
+	case WatchPerson:
+		profiles, err := watchProfiles(ctx, item, item.Value)
+		findings = append(findings, profiles...)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return findings, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return findings, nil
+}
+
+// watchLookalikes reports registered domains imitating domain
+func watchLookalikes(ctx context.Context, item *WatchItem, domain string) ([]WatchFinding, error) {
+	lookalikes, err := FindLookalikeDomains(ctx, domain)
+
+	var findings []WatchFinding
+	for _, lookalike := range lookalikes {
+		details := lookalike.Technique
+		if len(lookalike.IPs) > 0 {
+			details += ", resolves to " + strings.Join(lookalike.IPs, ", ")
+		}
+		// A lookalike that accepts mail can be used for phishing replies
+		if len(lookalike.MXRecords) > 0 {
+			details += ", accepts mail via " + strings.Join(lookalike.MXRecords, ", ")
+		}
+		findings = append(findings, newWatchFinding(item, "lookalike", lookalike.Domain,
+			"Lookalike domain registered: "+lookalike.Domain, "http://"+lookalike.Domain, details))
+	}
+	if err != nil {
+		return findings, fmt.Errorf("lookalike: %v", err)
+	}
+	return findings, nil
+}
+
+// watchProfiles reports social profiles claiming a name or handle
+func watchProfiles(ctx context.Context, item *WatchItem, query string) ([]WatchFinding, error) {
+	results, err := SearchProfilesSequentially(ctx, query, "", false)
+	if err != nil {
+		return nil, fmt.Errorf("social: %v", err)
+	}
+
+	var findings []WatchFinding
+	for _, profile := range results.Profiles {
+		if !profile.Exists {
+			continue
+		}
+		findings = append(findings, newWatchFinding(item, "social", profile.URL,
+			fmt.Sprintf("%s profile: %s", profile.Platform, profile.Username), profile.URL, profile.FullName))
+	}
+	return findings, nil
+}
+
+// newWatchFinding builds a finding whose ID is derived from the item, module and key
+func newWatchFinding(item *WatchItem, module, key, title, link, details string) WatchFinding {
+	sum := sha1.Sum([]byte(item.Kind + "\x00" + strings.ToLower(item.Value) + "\x00" + module + "\x00" + strings.ToLower(key)))
+	return WatchFinding{
+		ID:      hex.EncodeToString(sum[:8]),
+		Module:  module,
+		Title:   title,
+		URL:     link,
+		Details: details,
+	}
+}
+
+// brandLabel reduces a brand name to the label its domains would use
+func brandLabel(brand string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(brand) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// DisplayResults prints the findings that are new since the previous run
+func (r *WatchRunResult) DisplayResults() {
+	color.Cyan("\n=== WATCHLIST RESULTS: %s ===", r.Watchlist)
+	color.Yellow("Run Timestamp: %s", r.Timestamp)
+
+	total := 0
+	for _, item := range r.Items {
+		color.Cyan("\n[%s: %s]", item.Kind, item.Value)
+		switch {
+		case item.Baseline:
+			color.White("• First run, findings recorded as the baseline")
+		case len(item.New) == 0:
+			color.White("• No new findings")
+		}
+		for _, finding := range item.New {
+			color.Red("• %s", finding.Title)
+			if finding.Details != "" {
+				color.White("  - %s", finding.Details)
+			}
+		}
+		if item.Error != "" {
+			color.Yellow("  ! %s", item.Error)
+		}
+		total += len(item.New)
+	}
+	color.Yellow("\n%d new findings across %d items", total, len(r.Items))
+	if r.Unchanged > 0 || r.Changed > 0 {
+		color.White("%d pages unchanged since the last run (304 Not Modified), %d changed", r.Unchanged, r.Changed)
+	}
+}
@@ -0,0 +1,283 @@
+package parse
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrNoDate is returned when text holds no readable date
+var ErrNoDate = errors.New("no date found")
+
+// maxAgo bounds relative counts so "99999999 years ago" cannot overflow
+const maxAgo = 10000
+
+// dateLayouts are tried against the whole text before looking for a date
+// inside it
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// months maps month names and abbreviations in English, Spanish, French,
+// German, Portuguese and Italian to their number
+var months = map[string]time.Month{}
+
+func init() {
+	names := [12][]string{
+		{"january", "jan", "enero", "ene", "janvier", "janv", "januar", "janeiro", "gennaio", "gen"},
+		{"february", "feb", "febrero", "février", "fevrier", "févr", "fevr", "februar", "fevereiro", "fev", "febbraio"},
+		{"march", "mar", "marzo", "mars", "märz", "marz", "mär", "março", "marco"},
+		{"april", "apr", "abril", "abr", "avril", "avr", "aprile"},
+		{"may", "mayo", "mai", "maio", "maggio", "mag"},
+		{"june", "jun", "junio", "juin", "juni", "junho", "giugno", "giu"},
+		{"july", "jul", "julio", "juillet", "juil", "juli", "julho", "luglio", "lug"},
+		{"august", "aug", "agosto", "ago", "août", "aout"},
+		{"september", "sep", "sept", "septiembre", "septembre", "setembro", "set", "settembre"},
+		{"october", "oct", "octubre", "octobre", "oktober", "okt", "outubro", "out", "ottobre", "ott"},
+		{"november", "nov", "noviembre", "novembre", "novembro"},
+		{"december", "dec", "diciembre", "dic", "décembre", "decembre", "déc", "dezember", "dez", "dezembro", "dicembre"},
+	}
+	for i, list := range names {
+		for _, name := range list {
+			months[name] = time.Month(i + 1)
+		}
+	}
+}
+
+// agoUnits maps the time units of relative dates, including the short forms
+// of "3y" and "5d", to a unit name
+var agoUnits = map[string]string{}
+
+func init() {
+	units := map[string][]string{
+		"year":   {"year", "years", "yr", "yrs", "y", "año", "años", "ano", "anos", "an", "ans", "année", "années", "jahr", "jahre", "jahren", "anno", "anni"},
+		"month":  {"month", "months", "mo", "mos", "mes", "meses", "mês", "mois", "monat", "monate", "monaten", "mese", "mesi"},
+		"week":   {"week", "weeks", "w", "wk", "wks", "semana", "semanas", "semaine", "semaines", "woche", "wochen", "settimana", "settimane"},
+		"day":    {"day", "days", "d", "día", "días", "dia", "dias", "jour", "jours", "tag", "tage", "tagen", "giorno", "giorni"},
+		"hour":   {"hour", "hours", "h", "hr", "hrs", "hora", "horas", "heure", "heures", "stunde", "stunden", "ora", "ore"},
+		"minute": {"minute", "minutes", "m", "min", "mins", "minuto", "minutos", "minuten", "minuti"},
+		"second": {"second", "seconds", "s", "sec", "secs", "segundo", "segundos", "seconde", "secondes", "sekunde", "sekunden", "secondo", "secondi"},
+	}
+	for unit, words := range units {
+		for _, word := range words {
+			agoUnits[word] = unit
+		}
+	}
+}
+
+// agoArticles are the words standing for "one" in "a year ago", "hace un año"
+var agoArticles = map[string]bool{
+	"a": true, "an": true, "one": true, "un": true, "una": true, "uno": true,
+	"une": true, "um": true, "uma": true, "ein": true, "einem": true, "einer": true,
+}
+
+// agoMarkers are the words that make "3 years" a date in the past: "ago",
+// "hace", "il y a", "vor", "há", "fa", and "for"/"depuis"/"seit" as in
+// "Redditor for 3 years"
+var agoMarkers = map[string]bool{
+	"ago": true, "hace": true, "vor": true, "há": true, "ha": true, "fa": true,
+	"for": true, "depuis": true, "seit": true,
+}
+
+// Relative day words
+var (
+	todayWords     = map[string]bool{"today": true, "hoy": true, "aujourd'hui": true, "heute": true, "hoje": true, "oggi": true}
+	yesterdayWords = map[string]bool{"yesterday": true, "ayer": true, "hier": true, "gestern": true, "ontem": true, "ieri": true}
+)
+
+var (
+	isoDateRegex       = regexp.MustCompile(`\b(\d{4})-(\d{1,2})-(\d{1,2})\b`)
+	dayMonthYearRegex  = regexp.MustCompile(`\b(\d{1,2})(?:st|nd|rd|th|er|º|\.)?\s+(?:de\s+)?(\p{L}+)\.?,?\s+(?:de\s+)?(\d{4})\b`)
+	monthDayYearRegex  = regexp.MustCompile(`(\p{L}+)\.?\s+(\d{1,2})(?:st|nd|rd|th)?,?\s+(\d{4})\b`)
+	monthYearRegex     = regexp.MustCompile(`(\p{L}+)\.?\s+(?:de\s+)?(\d{4})\b`)
+	numericDateRegex   = regexp.MustCompile(`\b(\d{1,2})([/.])(\d{1,2})[/.](\d{4})\b`)
+	yearRegex          = regexp.MustCompile(`\b((?:19|20)\d{2})\b`)
+	unixTimestampRegex = regexp.MustCompile(`^\d{10}(\d{3})?$`)
+)
+
+// Date reads a date written for people, such as "Joined March 2015", "3 years
+// ago", "hace 2 meses", "le 3 février 2021", "2021-02-03T10:00:00Z" or a Unix
+// timestamp. Relative dates are resolved against now. Dates that only give a
+// month or a year resolve to its first day.
+func Date(text string, now time.Time) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+	if unixTimestampRegex.MatchString(text) {
+		value, _ := strconv.ParseInt(text, 10, 64)
+		if len(text) == 13 {
+			return time.UnixMilli(value).UTC(), nil
+		}
+		return time.Unix(value, 0).UTC(), nil
+	}
+
+	normalized := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	if t, ok := relativeDate(normalized, now); ok {
+		return t, nil
+	}
+	if t, ok := absoluteDate(normalized, now); ok {
+		return t, nil
+	}
+	return time.Time{}, ErrNoDate
+}
+
+// relativeDate resolves "today", "yesterday" and "<count> <unit> ago" forms
+func relativeDate(text string, now time.Time) (time.Time, bool) {
+	words := dateWords(text)
+
+	marked := strings.Contains(text, "il y a")
+	for _, word := range words {
+		if todayWords[word] {
+			return now, true
+		}
+		if yesterdayWords[word] {
+			return now.AddDate(0, 0, -1), true
+		}
+		marked = marked || agoMarkers[word]
+	}
+	if !marked {
+		return time.Time{}, false
+	}
+
+	for i := 0; i+1 < len(words); i++ {
+		unit, ok := agoUnits[words[i+1]]
+		if !ok {
+			continue
+		}
+		count := 1
+		if !agoArticles[words[i]] {
+			n, err := strconv.Atoi(words[i])
+			if err != nil || n < 0 || n > maxAgo {
+				continue
+			}
+			count = n
+		}
+
+		switch unit {
+		case "year":
+			return now.AddDate(-count, 0, 0), true
+		case "month":
+			return now.AddDate(0, -count, 0), true
+		case "week":
+			return now.AddDate(0, 0, -7*count), true
+		case "day":
+			return now.AddDate(0, 0, -count), true
+		case "hour":
+			return now.Add(-time.Duration(count) * time.Hour), true
+		case "minute":
+			return now.Add(-time.Duration(count) * time.Minute), true
+		case "second":
+			return now.Add(-time.Duration(count) * time.Second), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// dateWords splits text into words and numbers, separating counts glued to
+// their unit as in "3y" or "5d"
+func dateWords(text string) []string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = current[:0]
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsDigit(r):
+			if len(current) > 0 && !unicode.IsDigit(current[len(current)-1]) {
+				flush()
+			}
+			current = append(current, r)
+		case unicode.IsLetter(r) || r == '\'':
+			if len(current) > 0 && unicode.IsDigit(current[len(current)-1]) {
+				flush()
+			}
+			current = append(current, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+// absoluteDate looks for a written-out date inside text, most precise form
+// first
+func absoluteDate(text string, now time.Time) (time.Time, bool) {
+	if m := isoDateRegex.FindStringSubmatch(text); m != nil {
+		if t, ok := makeDate(m[1], m[2], m[3], now); ok {
+			return t, true
+		}
+	}
+	if m := dayMonthYearRegex.FindStringSubmatch(text); m != nil {
+		if month, ok := months[m[2]]; ok {
+			if t, ok := makeDate(m[3], strconv.Itoa(int(month)), m[1], now); ok {
+				return t, true
+			}
+		}
+	}
+	for _, m := range monthDayYearRegex.FindAllStringSubmatch(text, -1) {
+		if month, ok := months[m[1]]; ok {
+			if t, ok := makeDate(m[3], strconv.Itoa(int(month)), m[2], now); ok {
+				return t, true
+			}
+		}
+	}
+	if m := numericDateRegex.FindStringSubmatch(text); m != nil {
+		// Dotted dates are day first; slashed ones are month first unless
+		// the first number cannot be a month
+		first, _ := strconv.Atoi(m[1])
+		day, month := m[3], m[1]
+		if m[2] == "." || first > 12 {
+			day, month = m[1], m[3]
+		}
+		if t, ok := makeDate(m[4], month, day, now); ok {
+			return t, true
+		}
+	}
+	for _, m := range monthYearRegex.FindAllStringSubmatch(text, -1) {
+		if month, ok := months[m[1]]; ok {
+			if t, ok := makeDate(m[2], strconv.Itoa(int(month)), "1", now); ok {
+				return t, true
+			}
+		}
+	}
+	if m := yearRegex.FindStringSubmatch(text); m != nil {
+		return makeDate(m[1], "1", "1", now)
+	}
+	return time.Time{}, false
+}
+
+// makeDate builds a UTC date, rejecting impossible days such as February 30
+// and years no profile could date from
+func makeDate(year, month, day string, now time.Time) (time.Time, bool) {
+	y, errY := strconv.Atoi(year)
+	m, errM := strconv.Atoi(month)
+	d, errD := strconv.Atoi(day)
+	if errY != nil || errM != nil || errD != nil {
+		return time.Time{}, false
+	}
+	if y < 1970 || y > now.Year()+1 || m < 1 || m > 12 || d < 1 || d > 31 {
+		return time.Time{}, false
+	}
+	t := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	if t.Day() != d {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,92 @@
+package parse
+
+import (
+	"testing"
+	"time"
+)
+
+// testNow is the time relative dates resolve against
+var testNow = time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDate(t *testing.T) {
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{"2021-02-03T10:00:00Z", time.Date(2021, time.February, 3, 10, 0, 0, 0, time.UTC)},
+		{"2021-02-03", day(2021, time.February, 3)},
+		{"1612346400", time.Unix(1612346400, 0).UTC()},
+		{"1612346400000", time.UnixMilli(1612346400000).UTC()},
+		{"Joined March 2015", day(2015, time.March, 1)},
+		{"Joined March 4, 2015", day(2015, time.March, 4)},
+		{"4th March 2015", day(2015, time.March, 4)},
+		{"le 3 février 2021", day(2021, time.February, 3)},
+		{"3 de marzo de 2019", day(2019, time.March, 3)},
+		{"Mitglied seit 12. Dezember 2018", day(2018, time.December, 12)},
+		{"03.02.2021", day(2021, time.February, 3)},
+		{"02/03/2021", day(2021, time.February, 3)},
+		{"25/12/2020", day(2020, time.December, 25)},
+		{"Member since 2012", day(2012, time.January, 1)},
+		{"February 30, 2021", day(2021, time.January, 1)},
+		{"3 years ago", testNow.AddDate(-3, 0, 0)},
+		{"a month ago", testNow.AddDate(0, -1, 0)},
+		{"Redditor for 5y", testNow.AddDate(-5, 0, 0)},
+		{"hace 2 meses", testNow.AddDate(0, -2, 0)},
+		{"il y a 3 jours", testNow.AddDate(0, 0, -3)},
+		{"vor 2 Wochen", testNow.AddDate(0, 0, -14)},
+		{"há 4 horas", testNow.Add(-4 * time.Hour)},
+		{"yesterday", testNow.AddDate(0, 0, -1)},
+		{"Heute", testNow},
+	}
+	for _, tt := range tests {
+		got, err := Date(tt.text, testNow)
+		if err != nil {
+			t.Errorf("Date(%q): %v", tt.text, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("Date(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDateRejects(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"no date here",
+		"February 30",
+		"Joined 1850",
+		"3 years",
+		"99999999 years ago",
+		"31/31",
+	} {
+		if got, err := Date(text, testNow); err == nil {
+			t.Errorf("Date(%q) = %v, want an error", text, got)
+		}
+	}
+}
+
+func FuzzDate(f *testing.F) {
+	for _, seed := range []string{
+		"Joined March 2015", "3 years ago", "a month ago", "Redditor for 5y",
+		"hace 2 meses", "il y a 3 jours", "vor 2 Wochen", "há 4 horas",
+		"le 3 février 2021", "3 de marzo de 2019", "12. Dezember 2018",
+		"03.02.2021", "02/03/2021", "2021-02-03T10:00:00Z", "1612346400",
+		"February 30, 2021", "99999999 years ago", "", "\xff\xfe",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		got, err := Date(text, testNow)
+		if err == nil && got.IsZero() {
+			t.Fatalf("Date(%q) returned the zero time without an error", text)
+		}
+		if err != nil && !got.IsZero() {
+			t.Fatalf("Date(%q) returned %v with error %v", text, got, err)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+// Package parse turns the numbers and dates scraped from profile pages into
+// values. Pages format them for people ("1.2K followers", "1.234.567",
+// "Joined 3 years ago", "hace 2 meses"), so the parsers accept the common
+// locale variants and never panic on arbitrary input.
+package parse
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrNoQuantity is returned when text holds no readable number
+var ErrNoQuantity = errors.New("no quantity found")
+
+// maxQuantity bounds parsed values well inside int64
+const maxQuantity = 1e15
+
+// quantityPattern matches a number, with space-grouped thousands tried first,
+// and an optional magnitude suffix. The leading class stops a match starting
+// in the middle of another number.
+const quantityPattern = `(?:^|[^\d.,'])(\d{1,3}(?:[ \x{a0}\x{202f}]\d{3})+\b(?:[.,]\d+)?|\d+(?:[.,']\d+)*)(?:\s*(thousand|million|billion|mil|tsd|mio|mrd|mn|bn|k|m|b)\b)?`
+
+var quantityRegex = regexp.MustCompile(`(?i)` + quantityPattern)
+
+// magnitudes maps suffixes to multipliers. "mil" is the Spanish and
+// Portuguese thousand, "Tsd", "Mio" and "Mrd" the German abbreviations.
+var magnitudes = map[string]float64{
+	"k": 1e3, "thousand": 1e3, "mil": 1e3, "tsd": 1e3,
+	"m": 1e6, "million": 1e6, "mio": 1e6, "mn": 1e6,
+	"b": 1e9, "billion": 1e9, "mrd": 1e9, "bn": 1e9,
+}
+
+// Quantity returns the first number in text, expanding magnitude suffixes:
+// "1,234", "1.234" and "1 234" are 1234, "1.2K" and "1,2 k" are 1200
+func Quantity(text string) (int64, error) {
+	return quantityMatch(quantityRegex.FindStringSubmatch(text))
+}
+
+// QuantityBefore returns the number written just before one of words, so a
+// count can be picked out of text holding several: QuantityBefore("1.2K
+// followers · 300 following", "following") is 300
+func QuantityBefore(text string, words ...string) (int64, error) {
+	if len(words) == 0 {
+		return Quantity(text)
+	}
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+	re, err := regexp.Compile(`(?i)` + quantityPattern + `\s*(?:` + strings.Join(quoted, "|") + `)`)
+	if err != nil {
+		return 0, err
+	}
+	return quantityMatch(re.FindStringSubmatch(text))
+}
+
+func quantityMatch(match []string) (int64, error) {
+	if match == nil {
+		return 0, ErrNoQuantity
+	}
+	multiplier := 1.0
+	if match[2] != "" {
+		multiplier = magnitudes[strings.ToLower(match[2])]
+	}
+
+	value, ok := number(match[1], multiplier != 1)
+	if !ok {
+		return 0, ErrNoQuantity
+	}
+	value = math.Round(value * multiplier)
+	if value > maxQuantity {
+		return 0, ErrNoQuantity
+	}
+	return int64(value), nil
+}
+
+// number reads digits grouped with spaces, apostrophes, commas or dots.
+// When both commas and dots appear the last one is the decimal mark; a
+// single separator followed by three digits groups thousands unless a
+// magnitude suffix makes a fraction likely ("1.234" vs "1.234K").
+func number(digits string, scaled bool) (float64, bool) {
+	digits = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "", "'", "").Replace(digits)
+
+	commas, dots := strings.Count(digits, ","), strings.Count(digits, ".")
+	switch {
+	case commas > 0 && dots > 0:
+		if strings.LastIndex(digits, ",") > strings.LastIndex(digits, ".") {
+			digits = strings.ReplaceAll(digits, ".", "")
+			digits = strings.Replace(digits, ",", ".", 1)
+		} else {
+			digits = strings.ReplaceAll(digits, ",", "")
+		}
+		// Anything left over was not a number a page would print
+		if strings.Count(digits, ".") > 1 || strings.Contains(digits, ",") {
+			return 0, false
+		}
+	case commas+dots > 1:
+		digits = strings.NewReplacer(",", "", ".", "").Replace(digits)
+	case commas+dots == 1:
+		sep := strings.IndexAny(digits, ",.")
+		if len(digits)-sep-1 == 3 && !scaled {
+			digits = digits[:sep] + digits[sep+1:]
+		} else {
+			digits = digits[:sep] + "." + digits[sep+1:]
+		}
+	}
+
+	value, err := strconv.ParseFloat(digits, 64)
+	if err != nil || math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, false
+	}
+	return value, true
+}
@@ -0,0 +1,120 @@
+package parse
+
+import "testing"
+
+func TestQuantity(t *testing.T) {
+	tests := []struct {
+		text string
+		want int64
+	}{
+		{"42", 42},
+		{"3,456 followers", 3456},
+		{"3.456 Follower", 3456},
+		{"1 234 567", 1234567},
+		{"1 234", 1234},
+		{"1'234'567", 1234567},
+		{"1,234,567", 1234567},
+		{"1.234.567", 1234567},
+		{"1,234.5", 1235},
+		{"1.234,5", 1235},
+		{"1.2K", 1200},
+		{"1,2 k", 1200},
+		{"12.5M followers", 12500000},
+		{"3 mil seguidores", 3000},
+		{"2,5 Mio.", 2500000},
+		{"1.5 billion", 1500000000},
+		{"0.5", 1},
+	}
+	for _, tt := range tests {
+		got, err := Quantity(tt.text)
+		if err != nil {
+			t.Errorf("Quantity(%q): %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Quantity(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestQuantityRejects(t *testing.T) {
+	for _, text := range []string{"", "followers", "no numbers", "9999999999999999999", "5000000B"} {
+		if got, err := Quantity(text); err == nil {
+			t.Errorf("Quantity(%q) = %d, want an error", text, got)
+		}
+	}
+}
+
+func TestQuantityBefore(t *testing.T) {
+	tests := []struct {
+		text  string
+		words []string
+		want  int64
+	}{
+		{"1.2K followers · 300 following", []string{"following"}, 300},
+		{"1.2K followers · 300 following", []string{"followers"}, 1200},
+		{"Local Guide · 87 reviews · 1,024 photos", []string{"photos"}, 1024},
+		{"Local Guide · 87 reviews · 1,024 photos", []string{"reviews"}, 87},
+		{"12,345 post karma", []string{"karma", "post karma"}, 12345},
+		{"7 (a+b) matches", []string{"(a+b) matches"}, 7},
+		{"no words given 5", nil, 5},
+	}
+	for _, tt := range tests {
+		got, err := QuantityBefore(tt.text, tt.words...)
+		if err != nil {
+			t.Errorf("QuantityBefore(%q, %q): %v", tt.text, tt.words, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("QuantityBefore(%q, %q) = %d, want %d", tt.text, tt.words, got, tt.want)
+		}
+	}
+
+	if got, err := QuantityBefore("300 following", "followers"); err == nil {
+		t.Errorf("QuantityBefore without the word = %d, want an error", got)
+	}
+}
+
+// quantitySeeds are the counts the profile extractors see
+var quantitySeeds = []string{
+	"1.2K", "3,456", "1.234", "1,2 k", "12.5M followers", "1 234 567",
+	"1'234", "1.234,5", "3 mil seguidores", "2,5 Mio.", "1.2K followers · 300 following",
+	"87 reviews · 1,024 photos", "12,345 karma", "9999999999999999999", "1e308", "",
+}
+
+func FuzzQuantity(f *testing.F) {
+	for _, seed := range quantitySeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		got, err := Quantity(text)
+		checkQuantity(t, text, got, err)
+	})
+}
+
+func FuzzQuantityBefore(f *testing.F) {
+	for _, seed := range quantitySeeds {
+		f.Add(seed, "followers")
+		f.Add(seed, "photos")
+	}
+	f.Add("5 (x", "(x")
+	f.Fuzz(func(t *testing.T, text, word string) {
+		got, err := QuantityBefore(text, word)
+		checkQuantity(t, text, got, err)
+	})
+}
+
+// checkQuantity fails on results no caller could use: a value with an
+// error, or a value outside the range the parser promises
+func checkQuantity(t *testing.T, text string, got int64, err error) {
+	t.Helper()
+	if err != nil {
+		if got != 0 {
+			t.Fatalf("%q returned %d with error %v", text, got, err)
+		}
+		return
+	}
+	if got < 0 || got > maxQuantity {
+		t.Fatalf("%q returned %d, outside 0..%d", text, got, int64(maxQuantity))
+	}
+}
@@ -0,0 +1,149 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Module names the command making requests, recorded in the request log.
+// Requests whose context carries a module of their own are logged under it.
+var Module string
+
+type moduleKey struct{}
+type serviceKey struct{}
+
+// WithModule returns a context whose requests are logged under module
+func WithModule(ctx context.Context, module string) context.Context {
+	return context.WithValue(ctx, moduleKey{}, module)
+}
+
+// ModuleFrom returns the module carried by ctx, or Module when it has none
+func ModuleFrom(ctx context.Context) string {
+	if ctx != nil {
+		if module, ok := ctx.Value(moduleKey{}).(string); ok && module != "" {
+			return module
+		}
+	}
+	return Module
+}
+
+// RequestRecord is one line of the request log
+type RequestRecord struct {
+	Time          string `json:"time"`
+	ScanID        string `json:"scan_id,omitempty"`
+	Module        string `json:"module,omitempty"`
+	Service       string `json:"service,omitempty"` // The API a Client called; empty for page fetches
+	Method        string `json:"method"`
+	Host          string `json:"host"`
+	Status        int    `json:"status,omitempty"`
+	BytesSent     int64  `json:"bytes_sent"`
+	BytesReceived int64  `json:"bytes_received"`
+	Error         string `json:"error,omitempty"`
+}
+
+// RequestLog appends a record of every request to a file, which it never
+// truncates or rewrites
+type RequestLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenRequestLog opens path for appending, creating it readable by its
+// owner only
+func OpenRequestLog(path string) (*RequestLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &RequestLog{file: file}, nil
+}
+
+// Write appends a record as one JSON line
+func (l *RequestLog) Write(record RequestRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the log file
+func (l *RequestLog) Close() error {
+	return l.file.Close()
+}
+
+// Auditor records every request sent through Base in Log once its response
+// has been read, or when it fails
+type Auditor struct {
+	Base http.RoundTripper
+	Log  *RequestLog
+}
+
+// RoundTrip sends the request, counting the bytes of the response body as it
+// is read
+func (a *Auditor) RoundTrip(req *http.Request) (*http.Response, error) {
+	record := RequestRecord{
+		Time:   time.Now().UTC().Format(time.RFC3339Nano),
+		ScanID: ScanIDFrom(req.Context()),
+		Module: ModuleFrom(req.Context()),
+		Method: req.Method,
+		Host:   req.URL.Host,
+	}
+	record.Service, _ = req.Context().Value(serviceKey{}).(string)
+	if req.ContentLength > 0 {
+		record.BytesSent = req.ContentLength
+	}
+
+	resp, err := a.Base.RoundTrip(req)
+	if err != nil {
+		record.Error = err.Error()
+		a.Log.Write(record)
+		return nil, err
+	}
+	record.Status = resp.StatusCode
+	resp.Body = &countingBody{ReadCloser: resp.Body, done: func(n int64) {
+		record.BytesReceived = n
+		a.Log.Write(record)
+	}}
+	return resp, nil
+}
+
+// countingBody counts the bytes read from a response body and reports them
+// once, at the end of the body or when it is closed
+type countingBody struct {
+	io.ReadCloser
+	n    int64
+	once sync.Once
+	done func(n int64)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if err == io.EOF {
+		b.once.Do(func() { b.done(b.n) })
+	}
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	b.once.Do(func() { b.done(b.n) })
+	return b.ReadCloser.Close()
+}
+
+// Audit installs an Auditor writing to log over the current transport
+func Audit(log *RequestLog) {
+	Use(&Auditor{Base: Transport, Log: log})
+}
@@ -0,0 +1,155 @@
+package providers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// BudgetError is returned for a request that was not sent because the run's
+// request or bandwidth budget is used up
+type BudgetError struct {
+	Limit string
+}
+
+func (e *BudgetError) Error() string {
+	return "skipped: " + e.Limit + " used up"
+}
+
+// BudgetUsage counts a run's requests and bytes against its caps. It is
+// shared by every transport built with Wrap.
+type BudgetUsage struct {
+	MaxRequests int64 // 0 for no cap
+	MaxBytes    int64 // Sent and received; 0 for no cap
+
+	mu       sync.Mutex
+	requests int64
+	bytes    int64
+	skipped  map[string]int // Requests not sent, by host
+}
+
+// Budget refuses requests once Usage reaches a cap. The request crossing the
+// byte cap completes, since its size is only known once it has been read.
+type Budget struct {
+	Base  http.RoundTripper
+	Usage *BudgetUsage
+}
+
+// RoundTrip sends the request when the budget allows it, counting the bytes
+// of its response body as they are read
+func (b *Budget) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := b.Usage.take(req); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	resp, err := b.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, done: b.Usage.addBytes}
+	return resp, nil
+}
+
+// take counts a request, or refuses it when a cap is reached
+func (u *BudgetUsage) take(req *http.Request) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var err error
+	switch {
+	case u.MaxRequests > 0 && u.requests >= u.MaxRequests:
+		err = &BudgetError{Limit: fmt.Sprintf("request budget of %d", u.MaxRequests)}
+	case u.MaxBytes > 0 && u.bytes >= u.MaxBytes:
+		err = &BudgetError{Limit: fmt.Sprintf("bandwidth budget of %d bytes", u.MaxBytes)}
+	}
+	if err != nil {
+		u.skip(req.URL.Host)
+		return err
+	}
+	u.requests++
+	if req.ContentLength > 0 {
+		u.bytes += req.ContentLength
+	}
+	return nil
+}
+
+// spent reports whether a cap is reached
+func (u *BudgetUsage) spent() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return (u.MaxRequests > 0 && u.requests >= u.MaxRequests) || (u.MaxBytes > 0 && u.bytes >= u.MaxBytes)
+}
+
+func (u *BudgetUsage) skip(host string) {
+	if u.skipped == nil {
+		u.skipped = make(map[string]int)
+	}
+	u.skipped[host]++
+}
+
+func (u *BudgetUsage) addBytes(n int64) {
+	u.mu.Lock()
+	u.bytes += n
+	u.mu.Unlock()
+}
+
+// Used returns the requests sent and the bytes sent and received so far
+func (u *BudgetUsage) Used() (requests, bytes int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.requests, u.bytes
+}
+
+// SkippedHost is a host some requests to were not sent
+type SkippedHost struct {
+	Host     string
+	Requests int
+}
+
+// Skipped lists the hosts of the requests the budget refused, most first
+func (u *BudgetUsage) Skipped() []SkippedHost {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	skipped := make([]SkippedHost, 0, len(u.skipped))
+	for host, requests := range u.skipped {
+		skipped = append(skipped, SkippedHost{Host: host, Requests: requests})
+	}
+	sort.Slice(skipped, func(i, j int) bool {
+		if skipped[i].Requests != skipped[j].Requests {
+			return skipped[i].Requests > skipped[j].Requests
+		}
+		return skipped[i].Host < skipped[j].Host
+	})
+	return skipped
+}
+
+// budget is the run's budget, set by Limit
+var budget *BudgetUsage
+
+// Limit installs a Budget over the current transport and returns its usage
+func Limit(maxRequests, maxBytes int64) *BudgetUsage {
+	budget = &BudgetUsage{MaxRequests: maxRequests, MaxBytes: maxBytes}
+	Use(&Budget{Base: Transport, Usage: budget})
+	return budget
+}
+
+// Spent reports whether the run's budget is used up, so retry loops can stop
+func Spent() bool {
+	return budget != nil && budget.spent()
+}
+
+// OverBudget reports whether the run's budget is used up, counting a request
+// to host as skipped when it is. Scans check it to stop queueing work whose
+// requests would be refused.
+func OverBudget(host string) bool {
+	if !Spent() {
+		return false
+	}
+	budget.mu.Lock()
+	budget.skip(host)
+	budget.mu.Unlock()
+	return true
+}
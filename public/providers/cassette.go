@@ -0,0 +1,203 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// RateLimits can be turned off when replaying, where no service is called
+var RateLimits = true
+
+// secretParams are query parameters blanked out of recorded URLs so cassettes
+// can be attached to bug reports
+var secretParams = []string{"key", "apikey", "api_key", "token", "access_token", "client_secret"}
+
+// Interaction is one recorded request and its response. Bodies that are not
+// valid UTF-8 are stored base64-encoded in BodyBase64.
+type Interaction struct {
+	Method      string            `json:"method"`
+	URL         string            `json:"url"`
+	RequestBody string            `json:"request_body,omitempty"`
+	Status      int               `json:"status"`
+	Header      map[string]string `json:"header,omitempty"`
+	Body        string            `json:"body,omitempty"`
+	BodyBase64  string            `json:"body_base64,omitempty"`
+}
+
+// Cassette is a RoundTripper that either records the traffic it forwards to
+// the network or replays a previous recording without touching it
+type Cassette struct {
+	Path         string        `json:"-"`
+	Recorded     time.Time     `json:"recorded"`
+	Interactions []Interaction `json:"interactions"`
+
+	next      http.RoundTripper
+	mu        sync.Mutex
+	replayed  map[string]int
+	recording bool
+}
+
+// NewRecorder returns a cassette forwarding requests to next and writing every
+// interaction to path as it completes, so a run that exits early still leaves
+// a usable recording
+func NewRecorder(path string, next http.RoundTripper) *Cassette {
+	return &Cassette{Path: path, Recorded: time.Now().UTC(), next: next, recording: true}
+}
+
+// LoadCassette reads a recording for replay
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cassette := &Cassette{Path: path, replayed: map[string]int{}}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("parse cassette %s: %v", path, err)
+	}
+	return cassette, nil
+}
+
+// RoundTrip records or replays a request
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if requestBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+	target := redactURL(req.URL)
+
+	if !c.recording {
+		return c.replay(req, target, string(requestBody))
+	}
+
+	resp, err := c.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction := Interaction{
+		Method:      req.Method,
+		URL:         target,
+		RequestBody: string(requestBody),
+		Status:      resp.StatusCode,
+		Header:      map[string]string{},
+	}
+	for _, name := range []string{"Content-Type", "Location", "Retry-After"} {
+		if value := resp.Header.Get(name); value != "" {
+			interaction.Header[name] = value
+		}
+	}
+	if utf8.Valid(body) {
+		interaction.Body = string(body)
+	} else {
+		interaction.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Interactions = append(c.Interactions, interaction)
+	if err := c.save(); err != nil {
+		return nil, fmt.Errorf("write cassette: %v", err)
+	}
+	return resp, nil
+}
+
+// replay serves recorded interactions for the same method, URL and body in
+// the order they were recorded, repeating the last one once they run out
+func (c *Cassette) replay(req *http.Request, target, requestBody string) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := req.Method + " " + target + "\n" + requestBody
+	var matches []*Interaction
+	for i := range c.Interactions {
+		interaction := &c.Interactions[i]
+		if interaction.Method == req.Method && interaction.URL == target && interaction.RequestBody == requestBody {
+			matches = append(matches, interaction)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%s %s not in cassette %s", req.Method, target, c.Path)
+	}
+	interaction := matches[min(c.replayed[key], len(matches)-1)]
+	c.replayed[key]++
+
+	body := []byte(interaction.Body)
+	if interaction.BodyBase64 != "" {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(interaction.BodyBase64); err != nil {
+			return nil, fmt.Errorf("cassette body for %s: %v", target, err)
+		}
+	}
+	header := make(http.Header)
+	for k, v := range interaction.Header {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		StatusCode:    interaction.Status,
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// save rewrites the cassette file; callers hold c.mu
+func (c *Cassette) save() error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(c); err != nil {
+		return err
+	}
+	tmp := c.Path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.Path)
+}
+
+// redactURL blanks API keys passed in the query string
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	changed := false
+	for name := range query {
+		for _, secret := range secretParams {
+			if strings.EqualFold(name, secret) {
+				query.Set(name, "REDACTED")
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return u.String()
+	}
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
@@ -0,0 +1,107 @@
+package providers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedactURL(t *testing.T) {
+	tests := []struct {
+		raw, want string
+	}{
+		{"https://api.example.com/v1/user?name=jane", "https://api.example.com/v1/user?name=jane"},
+		{"https://api.example.com/v1?key=s3cret&q=jane", "https://api.example.com/v1?key=REDACTED&q=jane"},
+		{"https://api.example.com/v1?APIKEY=a&Access_Token=b&client_secret=c", "https://api.example.com/v1?APIKEY=REDACTED&Access_Token=REDACTED&client_secret=REDACTED"},
+		{"https://api.example.com/v1?api_key=a&api_key=b", "https://api.example.com/v1?api_key=REDACTED"},
+		{"https://api.example.com/v1?token=", "https://api.example.com/v1?token=REDACTED"},
+		{"https://api.example.com/v1?tokens=a", "https://api.example.com/v1?tokens=a"},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := redactURL(u); got != tt.want {
+			t.Errorf("redactURL(%s) = %s, want %s", tt.raw, got, tt.want)
+		}
+	}
+}
+
+// roundTripFunc answers requests with a function
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestCassetteRecordsAndReplays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	calls := 0
+	network := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		body := []byte(fmt.Sprintf("answer %d", calls))
+		if req.URL.Path == "/avatar" {
+			body = []byte{0x89, 'P', 'N', 'G', 0xff}
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"text/plain"}, "Set-Cookie": {"session=1"}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}, nil
+	})
+
+	recorder := NewRecorder(path, network)
+	get := func(rt http.RoundTripper, target string) (*http.Response, string) {
+		t.Helper()
+		req, _ := http.NewRequest("GET", target, nil)
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("GET %s: %v", target, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+	for _, target := range []string{
+		"https://api.example.com/lookup?key=s3cret&q=jane",
+		"https://api.example.com/lookup?key=s3cret&q=jane",
+		"https://api.example.com/avatar",
+	} {
+		get(recorder, target)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read cassette: %v", err)
+	}
+	if strings.Contains(string(data), "s3cret") || strings.Contains(string(data), "session=1") {
+		t.Fatalf("cassette kept a secret:\n%s", data)
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette: %v", err)
+	}
+	// Repeated requests replay in recorded order, then repeat the last answer
+	for _, want := range []string{"answer 1", "answer 2", "answer 2"} {
+		if _, body := get(cassette, "https://api.example.com/lookup?key=other&q=jane"); body != want {
+			t.Errorf("replayed %q, want %q", body, want)
+		}
+	}
+	resp, body := get(cassette, "https://api.example.com/avatar")
+	if body != "\x89PNG\xff" || resp.Header.Get("Content-Type") != "text/plain" {
+		t.Errorf("replayed binary body %q with type %q", body, resp.Header.Get("Content-Type"))
+	}
+	if calls != 3 {
+		t.Errorf("network was called %d times, want 3 while recording only", calls)
+	}
+
+	req, _ := http.NewRequest("GET", "https://api.example.com/unrecorded", nil)
+	if _, err := cassette.RoundTrip(req); err == nil || !strings.Contains(err.Error(), "not in cassette") {
+		t.Errorf("unrecorded request returned %v, want a not in cassette error", err)
+	}
+}
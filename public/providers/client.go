@@ -0,0 +1,196 @@
+// Package providers holds the HTTP plumbing shared by every third-party API
+// the osint modules call: per-service rate limits, retries on throttling and
+// transient failures, and a swappable transport for mocks and fixtures.
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Client settings shared by every service
+var (
+	Timeout      = 15 * time.Second
+	MaxRetries   = 2
+	RetryBackoff = time.Second
+	maxRetryWait = 30 * time.Second
+
+	// Transport carries every provider request. Tests and demos replace it
+	// with a Mock or a recording transport.
+	Transport http.RoundTripper = http.DefaultTransport
+)
+
+// Client calls one service, waiting on its rate limit before each attempt
+type Client struct {
+	Name    string
+	Timeout time.Duration // Overrides the shared Timeout when set
+	limiter *rate.Limiter
+}
+
+// StatusError is returned when a service answers with an unexpected status
+type StatusError struct {
+	Service    string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned status code %d", e.Service, e.StatusCode)
+}
+
+// IsStatus reports whether err is a StatusError with the given code
+func IsStatus(err error, code int) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == code
+}
+
+// retryable reports whether a status is worth another attempt
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Do sends a request, retrying throttled and transient failures with
+// exponential backoff. A Retry-After header overrides the backoff. The
+// response of the last attempt is returned whatever its status.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	// The request log names the service each request was for
+	req = req.WithContext(context.WithValue(ctx, serviceKey{}, c.Name))
+	timeout := Timeout
+	if c.Timeout > 0 {
+		timeout = c.Timeout
+	}
+	client := &http.Client{Timeout: timeout, Transport: Transport}
+	canRetry := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil && RateLimits {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := client.Do(req)
+		var budgetErr *BudgetError
+		last := attempt >= MaxRetries || !canRetry || errors.As(err, &budgetErr)
+		if err == nil && (!retryable(resp.StatusCode) || last) {
+			return resp, nil
+		}
+		if err != nil && (last || ctx.Err() != nil) {
+			return nil, err
+		}
+
+		wait := RetryBackoff << attempt
+		if resp != nil {
+			if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && seconds >= 0 {
+				wait = time.Duration(seconds) * time.Second
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if wait > maxRetryWait {
+			wait = maxRetryWait
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// GetJSON performs a GET request and decodes a JSON response
+func (c *Client) GetJSON(ctx context.Context, target string, headers map[string]string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return err
+	}
+	return c.doJSON(req, headers, out)
+}
+
+// PostJSON performs a POST request and decodes a JSON response. The body is
+// buffered so it can be resent on retries.
+func (c *Client) PostJSON(ctx context.Context, target string, headers map[string]string, body io.Reader, out interface{}) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	return c.doJSON(req, headers, out)
+}
+
+func (c *Client) doJSON(req *http.Request, headers map[string]string, out interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Creation endpoints answer 201
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return &StatusError{Service: c.Name, StatusCode: resp.StatusCode}
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Wrap returns base, a transport tuned by the caller, unless Transport has
+// been replaced by a cassette or mock, which then carries that traffic too.
+// Tracers, guards, auditors, budgets and revalidators installed over the default transport are kept, layered
+// over base instead, and base goes through the proxy set with SetProxy.
+func Wrap(base http.RoundTripper) http.RoundTripper {
+	if transport, ok := base.(*http.Transport); ok && proxyURL != nil {
+		transport = transport.Clone()
+		transport.Proxy = proxyFor
+		base = transport
+	}
+	return rebase(Transport, base)
+}
+
+// rebase swaps the default transport under rt's layers for base
+func rebase(rt, base http.RoundTripper) http.RoundTripper {
+	switch layer := rt.(type) {
+	case *Tracer:
+		return &Tracer{Base: rebase(layer.Base, base), Header: layer.Header}
+	case *Guard:
+		return &Guard{Base: rebase(layer.Base, base), Check: layer.Check}
+	case *Auditor:
+		return &Auditor{Base: rebase(layer.Base, base), Log: layer.Log}
+	case *Budget:
+		return &Budget{Base: rebase(layer.Base, base), Usage: layer.Usage}
+	case *Revalidator:
+		return &Revalidator{Base: rebase(layer.Base, base), Cache: layer.Cache}
+	}
+	if rt == http.DefaultTransport {
+		return base
+	}
+	return rt
+}
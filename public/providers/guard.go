@@ -0,0 +1,20 @@
+package providers
+
+import "net/http"
+
+// Guard refuses the requests Check rejects before they reach Base
+type Guard struct {
+	Base  http.RoundTripper
+	Check func(req *http.Request) error
+}
+
+// RoundTrip returns Check's error without sending a rejected request
+func (g *Guard) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := g.Check(req); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	return g.Base.RoundTrip(req)
+}
@@ -0,0 +1,105 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Fixture is a canned response served by Mock. URL matches as a prefix of
+// the request URL; an empty Method matches any method.
+type Fixture struct {
+	Method string            `json:"method,omitempty"`
+	URL    string            `json:"url"`
+	Status int               `json:"status"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   string            `json:"body"`
+}
+
+// Mock is a RoundTripper that answers provider requests from fixtures and
+// records what was asked, so module code can run without the network
+type Mock struct {
+	Fixtures []Fixture
+
+	mu       sync.Mutex
+	requests []string
+}
+
+// LoadFixtures reads a JSON array of fixtures
+func LoadFixtures(path string) ([]Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixtures []Fixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("parse fixtures %s: %v", path, err)
+	}
+	return fixtures, nil
+}
+
+// Use installs rt as the provider transport and returns a function restoring
+// the previous one
+func Use(rt http.RoundTripper) (restore func()) {
+	previous := Transport
+	Transport = rt
+	return func() { Transport = previous }
+}
+
+// RoundTrip serves the longest matching fixture. Unmatched requests fail so
+// missing fixtures are noticed instead of silently reaching the network.
+func (m *Mock) RoundTrip(req *http.Request) (*http.Response, error) {
+	target := req.URL.String()
+
+	m.mu.Lock()
+	m.requests = append(m.requests, req.Method+" "+target)
+	m.mu.Unlock()
+
+	var match *Fixture
+	for i := range m.Fixtures {
+		fixture := &m.Fixtures[i]
+		if fixture.Method != "" && !strings.EqualFold(fixture.Method, req.Method) {
+			continue
+		}
+		if strings.HasPrefix(target, fixture.URL) && (match == nil || len(fixture.URL) > len(match.URL)) {
+			match = fixture
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("no fixture for %s %s", req.Method, target)
+	}
+
+	status := match.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	header := make(http.Header)
+	for k, v := range match.Header {
+		header.Set(k, v)
+	}
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "application/json")
+	}
+	return &http.Response{
+		StatusCode:    status,
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(match.Body)),
+		ContentLength: int64(len(match.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Requests lists the requests the mock has received, as "METHOD URL"
+func (m *Mock) Requests() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.requests...)
+}
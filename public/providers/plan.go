@@ -0,0 +1,149 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// ErrDryRun is returned for every request a dry run plans instead of sending
+var ErrDryRun = errors.New("dry run: not sent")
+
+// PlannedRequest is a request a dry run would have sent. Connections made
+// outside HTTP, such as DNS queries, have the network as their method and
+// the address as their URL.
+type PlannedRequest struct {
+	Method  string `json:"method"`
+	URL     string `json:"url"`
+	Host    string `json:"host"`
+	Service string `json:"service,omitempty"`
+	Count   int    `json:"count"`
+}
+
+// Planner records the requests of a dry run and sends none of them
+type Planner struct {
+	mu       sync.Mutex
+	requests []*PlannedRequest
+	index    map[string]*PlannedRequest
+}
+
+// RoundTrip records the request and fails it with ErrDryRun
+func (p *Planner) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	service, _ := req.Context().Value(serviceKey{}).(string)
+	p.add(req.Method, req.URL.String(), req.URL.Hostname(), service)
+	return nil, ErrDryRun
+}
+
+func (p *Planner) add(method, target, host, service string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := method + " " + target
+	if planned, ok := p.index[key]; ok {
+		planned.Count++
+		return
+	}
+	if p.index == nil {
+		p.index = make(map[string]*PlannedRequest)
+	}
+	planned := &PlannedRequest{Method: method, URL: target, Host: host, Service: service, Count: 1}
+	p.index[key] = planned
+	p.requests = append(p.requests, planned)
+}
+
+// Requests returns the requests planned, each once, in the order first made
+func (p *Planner) Requests() []PlannedRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	requests := make([]PlannedRequest, len(p.requests))
+	for i, planned := range p.requests {
+		requests[i] = *planned
+	}
+	return requests
+}
+
+// planner is the run's Planner, set by Plan
+var planner *Planner
+
+// Plan starts a dry run: requests through the current transport, and DNS
+// lookups and connections opened with DialContext, are recorded instead of
+// sent. Retries and rate limits are switched off, since nothing can fail
+// differently on a second try. Plan must run before other layers are added.
+func Plan() *Planner {
+	planner = &Planner{}
+	Use(planner)
+	MaxRetries = 0
+	RateLimits = false
+	net.DefaultResolver = &net.Resolver{PreferGo: true, Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+		return DialContext(ctx, network, address, 0)
+	}}
+	return planner
+}
+
+// Planning reports whether the run is a dry run
+func Planning() bool {
+	return planner != nil
+}
+
+// DialContext opens the connections checks make outside HTTP, like DNS
+// queries to a chosen server and SMTP probes. A dry run records them instead,
+// with the name and type of each DNS query, and RouteDialsThroughProxy sends
+// them through the proxy.
+func DialContext(ctx context.Context, network, address string, timeout time.Duration) (net.Conn, error) {
+	if planner != nil {
+		host, port, _ := net.SplitHostPort(address)
+		if port == "53" {
+			return &plannedDNS{server: host}, nil
+		}
+		planner.add(strings.ToUpper(network), address, host, "")
+		return nil, ErrDryRun
+	}
+	if proxyDials && !localAddress(address) {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return dialProxy(ctx, network, address)
+	}
+	dialer := net.Dialer{Timeout: timeout}
+	return dialer.DialContext(ctx, network, address)
+}
+
+// plannedDNS is a connection to a DNS server in a dry run. It records the
+// queries written to it and answers none.
+type plannedDNS struct {
+	server string
+}
+
+func (c *plannedDNS) Write(b []byte) (int, error) {
+	query := b
+	// Streams, which is what a resolver takes plannedDNS for, prefix each
+	// message with its length
+	if len(query) > 2 && int(query[0])<<8|int(query[1]) == len(query)-2 {
+		query = query[2:]
+	}
+	var parser dnsmessage.Parser
+	if _, err := parser.Start(query); err == nil {
+		if question, err := parser.Question(); err == nil {
+			planner.add("DNS", strings.TrimSuffix(question.Name.String(), ".")+" "+strings.TrimPrefix(question.Type.String(), "Type"), c.server, "")
+		}
+	}
+	return len(b), nil
+}
+
+func (c *plannedDNS) Read([]byte) (int, error)         { return 0, ErrDryRun }
+func (c *plannedDNS) Close() error                     { return nil }
+func (c *plannedDNS) LocalAddr() net.Addr              { return &net.UDPAddr{} }
+func (c *plannedDNS) RemoteAddr() net.Addr             { return &net.UDPAddr{} }
+func (c *plannedDNS) SetDeadline(time.Time) error      { return nil }
+func (c *plannedDNS) SetReadDeadline(time.Time) error  { return nil }
+func (c *plannedDNS) SetWriteDeadline(time.Time) error { return nil }
@@ -0,0 +1,185 @@
+package providers
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// proxyURL is the proxy every request is sent through, nil to connect
+// directly or through $HTTPS_PROXY
+var proxyURL *url.URL
+
+// IsolateCircuits sends each host's requests to a socks5 proxy under its own
+// credentials. Tor keeps streams with different credentials on different
+// circuits, so the sites of a scan see different exits.
+var IsolateCircuits = false
+
+// proxyFor returns the proxy of a request
+func proxyFor(req *http.Request) (*url.URL, error) {
+	if !IsolateCircuits || (proxyURL.Scheme != "socks5" && proxyURL.Scheme != "socks5h") {
+		return proxyURL, nil
+	}
+	isolated := *proxyURL
+	isolated.User = url.UserPassword(req.URL.Hostname(), "mercuries")
+	return &isolated, nil
+}
+
+// SetProxy sends every request through an http, https or socks5 proxy, such
+// as socks5://127.0.0.1:9050. Hostnames are resolved by a socks5 proxy, not
+// locally.
+func SetProxy(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid proxy %q: %v", raw, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("invalid proxy %q: expected an http://, https:// or socks5:// URL", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid proxy %q: no host", raw)
+	}
+	proxyURL = u
+	if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport.Proxy = proxyFor
+	}
+	return nil
+}
+
+// ProxyURL returns the proxy set with SetProxy, with any password masked
+func ProxyURL() string {
+	if proxyURL == nil {
+		return ""
+	}
+	return proxyURL.Redacted()
+}
+
+// DNSServer answers the DNS queries RouteDialsThroughProxy sends through the
+// proxy, over TCP since socks5 carries no UDP
+var DNSServer = "8.8.8.8:53"
+
+// proxyDials sends the connections DialContext opens through the socks5 proxy
+var proxyDials = false
+
+// RouteDialsThroughProxy sends the connections checks open outside HTTP
+// through the socks5 proxy set with SetProxy, so a run through Tor resolves
+// no name locally: DNS queries, the system resolver's included, go over TCP
+// through the proxy, and other UDP is refused. Addresses on this machine and
+// its private networks, which the proxy cannot reach, are still dialed
+// directly.
+func RouteDialsThroughProxy() error {
+	if proxyURL == nil || (proxyURL.Scheme != "socks5" && proxyURL.Scheme != "socks5h") {
+		return fmt.Errorf("routing DNS through a proxy needs a socks5 proxy")
+	}
+	proxyDials = true
+	net.DefaultResolver = &net.Resolver{PreferGo: true, Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+		return DialContext(ctx, network, DNSServer, 0)
+	}}
+	return nil
+}
+
+// NewResolver returns a resolver asking a DNS server, through DialContext so
+// dry runs and proxied runs see its queries
+func NewResolver(server string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return DialContext(ctx, network, server, 5*time.Second)
+		},
+	}
+}
+
+// dialProxy opens a connection through the socks5 proxy. DNS over UDP is
+// carried as DNS over TCP.
+func dialProxy(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	dns := strings.HasPrefix(network, "udp") && port == "53"
+	if strings.HasPrefix(network, "udp") && !dns {
+		return nil, fmt.Errorf("%s to %s cannot go through the socks5 proxy", network, address)
+	}
+
+	var auth *proxy.Auth
+	switch {
+	case IsolateCircuits:
+		auth = &proxy.Auth{User: host, Password: "mercuries"}
+	case proxyURL.User != nil:
+		password, _ := proxyURL.User.Password()
+		auth = &proxy.Auth{User: proxyURL.User.Username(), Password: password}
+	}
+	dialer, err := proxy.SOCKS5("tcp", proxyURL.Host, auth, proxy.Direct)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := dialer.(proxy.ContextDialer).DialContext(ctx, "tcp", address)
+	if err != nil || !dns {
+		return conn, err
+	}
+	return &tcpDNS{Conn: conn}, nil
+}
+
+// localAddress reports whether an address is an IP on this machine or a
+// private network
+func localAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast())
+}
+
+// tcpDNS carries DNS messages written as datagrams over TCP, prefixing each
+// with its length (RFC 1035 4.2.2). It is a PacketConn, so resolvers write
+// and read whole messages; one too long for the reader's buffer is cut and
+// flagged truncated, and resolvers retry it over plain TCP.
+type tcpDNS struct {
+	net.Conn
+}
+
+func (c *tcpDNS) Write(b []byte) (int, error) {
+	message := make([]byte, 2+len(b))
+	binary.BigEndian.PutUint16(message, uint16(len(b)))
+	copy(message[2:], b)
+	if _, err := c.Conn.Write(message); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *tcpDNS) Read(b []byte) (int, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(c.Conn, length[:]); err != nil {
+		return 0, err
+	}
+	message := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(c.Conn, message); err != nil {
+		return 0, err
+	}
+	n := copy(b, message)
+	if n < len(message) && n > 2 {
+		b[2] |= 0x02 // TC
+	}
+	return n, nil
+}
+
+func (c *tcpDNS) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, err := c.Read(b)
+	return n, c.RemoteAddr(), err
+}
+
+func (c *tcpDNS) WriteTo(b []byte, _ net.Addr) (int, error) {
+	return c.Write(b)
+}
@@ -0,0 +1,180 @@
+package providers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// maxCachedBody caps the pages a PageCache stores; larger ones are always
+// fetched in full
+const maxCachedBody = 4 << 20
+
+// PageCache keeps pages fetched with an ETag or Last-Modified in a directory,
+// one file each, so the next run can ask whether they changed
+type PageCache struct {
+	Dir string
+
+	revalidated atomic.Int64 // Answered 304 and served from the cache
+	fetched     atomic.Int64 // Cached pages that had changed
+}
+
+// cachedPage is one file of a PageCache
+type cachedPage struct {
+	URL          string      `json:"url"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	Stored       string      `json:"stored"`
+}
+
+// path returns the file a URL is cached in
+func (c *PageCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+func (c *PageCache) load(url string) *cachedPage {
+	data, err := os.ReadFile(c.path(url))
+	if err != nil {
+		return nil
+	}
+	var page cachedPage
+	if json.Unmarshal(data, &page) != nil || page.URL != url {
+		return nil
+	}
+	return &page
+}
+
+func (c *PageCache) store(page *cachedPage) {
+	data, err := json.Marshal(page)
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(c.Dir, 0700) != nil {
+		return
+	}
+	path := c.path(page.URL)
+	if os.WriteFile(path+".tmp", data, 0600) == nil {
+		os.Rename(path+".tmp", path)
+	}
+}
+
+// Stats returns the cached pages that were unchanged, answered with 304
+// Not Modified, and those that had changed
+func (c *PageCache) Stats() (unchanged, changed int64) {
+	return c.revalidated.Load(), c.fetched.Load()
+}
+
+// Revalidator sends GET requests for cached pages as conditional requests.
+// A 304 Not Modified answer is replaced by the cached page, so callers see
+// the same 200 response as when the page was stored.
+type Revalidator struct {
+	Base  http.RoundTripper
+	Cache *PageCache
+}
+
+// RoundTrip revalidates a cached page, or fetches the page and caches it
+func (r *Revalidator) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Answers to authenticated or partial requests are not shared
+	if req.Method != http.MethodGet || req.Header.Get("Authorization") != "" || req.Header.Get("Range") != "" {
+		return r.Base.RoundTrip(req)
+	}
+	url := req.URL.String()
+	cached := r.Cache.load(url)
+	if cached != nil {
+		req = req.Clone(req.Context())
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := r.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+		r.Cache.revalidated.Add(1)
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        cached.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+			ContentLength: int64(len(cached.Body)),
+			Request:       req,
+		}, nil
+	}
+	if cached != nil {
+		r.Cache.fetched.Add(1)
+	}
+
+	page := &cachedPage{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if resp.StatusCode != http.StatusOK || (page.ETag == "" && page.LastModified == "") || resp.ContentLength > maxCachedBody {
+		return resp, nil
+	}
+	page.Header = resp.Header.Clone()
+	page.Header.Del("Content-Length")
+	resp.Body = &teeBody{ReadCloser: resp.Body, done: func(body []byte) {
+		page.Body = body
+		page.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		page.Stored = time.Now().UTC().Format(time.RFC3339)
+		r.Cache.store(page)
+	}}
+	return resp, nil
+}
+
+// teeBody copies a response body as it is read and hands the copy to done
+// once the whole body has been read. Bodies closed early or larger than
+// maxCachedBody are dropped.
+type teeBody struct {
+	io.ReadCloser
+	buf      bytes.Buffer
+	overflow bool
+	done     func(body []byte)
+}
+
+func (b *teeBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if !b.overflow {
+		if b.buf.Len()+n > maxCachedBody {
+			b.overflow = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF && !b.overflow && b.done != nil {
+		b.done(b.buf.Bytes())
+		b.done = nil
+	}
+	return n, err
+}
+
+// Revalidate installs a Revalidator over the current transport, caching
+// pages in dir, and returns its cache
+func Revalidate(dir string) *PageCache {
+	cache := &PageCache{Dir: dir}
+	Use(&Revalidator{Base: Transport, Cache: cache})
+	return cache
+}
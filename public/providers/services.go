@@ -0,0 +1,119 @@
+package providers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Service is a third-party API and the rate limit it publishes for the tier
+// the tool is expected to run on. A zero Rate means no client-side limit.
+type Service struct {
+	Name  string
+	Hosts []string
+	Rate  rate.Limit
+	Burst int
+}
+
+// Services lists the APIs the osint modules call. Requests to hosts not
+// listed here share an unlimited client.
+var Services = []Service{
+	{Name: "Have I Been Pwned", Hosts: []string{"haveibeenpwned.com"}, Rate: rate.Every(6 * time.Second), Burst: 1},
+	{Name: "LeakCheck", Hosts: []string{"leakcheck.io"}, Rate: rate.Every(time.Second), Burst: 1},
+	{Name: "Shodan", Hosts: []string{"api.shodan.io"}, Rate: rate.Every(time.Second), Burst: 1},
+	{Name: "Censys", Hosts: []string{"search.censys.io"}, Rate: rate.Every(2500 * time.Millisecond), Burst: 1},
+	{Name: "Hunter", Hosts: []string{"api.hunter.io"}, Rate: rate.Every(100 * time.Millisecond), Burst: 5},
+	{Name: "FullContact", Hosts: []string{"api.fullcontact.com"}, Rate: rate.Every(time.Second), Burst: 1},
+	{Name: "HackerTarget", Hosts: []string{"api.hackertarget.com", "hackertarget.com"}, Rate: rate.Every(time.Second), Burst: 2},
+	{Name: "SpyOnWeb", Hosts: []string{"api.spyonweb.com"}, Rate: rate.Every(time.Second), Burst: 1},
+	{Name: "ip-api", Hosts: []string{"ip-api.com"}, Rate: rate.Every(1500 * time.Millisecond), Burst: 5},
+	{Name: "ipwho.is", Hosts: []string{"ipwho.is"}, Rate: rate.Every(time.Second), Burst: 2},
+	{Name: "Safe Browsing", Hosts: []string{"safebrowsing.googleapis.com"}, Rate: rate.Every(100 * time.Millisecond), Burst: 5},
+	{Name: "PhishTank", Hosts: []string{"checkurl.phishtank.com"}, Rate: rate.Every(2 * time.Second), Burst: 1},
+	{Name: "urlscan.io", Hosts: []string{"urlscan.io"}, Rate: rate.Every(2 * time.Second), Burst: 2},
+	{Name: "VirusTotal", Hosts: []string{"www.virustotal.com"}, Rate: rate.Every(15 * time.Second), Burst: 4},
+	{Name: "GreyNoise", Hosts: []string{"api.greynoise.io"}, Rate: rate.Every(2 * time.Second), Burst: 1},
+	{Name: "AbuseIPDB", Hosts: []string{"api.abuseipdb.com"}, Rate: rate.Every(time.Second), Burst: 2},
+	{Name: "Keybase", Hosts: []string{"keybase.io"}, Rate: rate.Every(time.Second), Burst: 2},
+	{Name: "OpenPGP keyservers", Hosts: []string{"keys.openpgp.org", "keyserver.ubuntu.com"}, Rate: rate.Every(time.Second), Burst: 2},
+	{Name: "GitHub", Hosts: []string{"api.github.com"}, Rate: rate.Every(time.Minute), Burst: 10},
+	{Name: "npm registry", Hosts: []string{"registry.npmjs.org"}, Rate: rate.Every(100 * time.Millisecond), Burst: 5},
+	{Name: "Twitter API", Hosts: []string{"api.twitter.com"}, Rate: rate.Every(time.Minute), Burst: 1},
+	{Name: "Spotify", Hosts: []string{"api.spotify.com", "accounts.spotify.com"}, Rate: rate.Every(200 * time.Millisecond), Burst: 5},
+	{Name: "Last.fm", Hosts: []string{"ws.audioscrobbler.com"}, Rate: rate.Every(250 * time.Millisecond), Burst: 4},
+	{Name: "SoundCloud", Hosts: []string{"soundcloud.com"}, Rate: rate.Every(time.Second), Burst: 2},
+	{Name: "Strava", Hosts: []string{"www.strava.com"}, Rate: rate.Every(time.Second), Burst: 2},
+	{Name: "Garmin Connect", Hosts: []string{"connect.garmin.com"}, Rate: rate.Every(time.Second), Burst: 2},
+	{Name: "Amazon", Hosts: []string{"www.amazon.com"}, Rate: rate.Every(2 * time.Second), Burst: 1},
+	{Name: "Reddit", Hosts: []string{"www.reddit.com"}, Rate: rate.Every(time.Second), Burst: 2},
+	{Name: "Gravatar", Hosts: []string{"en.gravatar.com", "www.gravatar.com", "gravatar.com"}, Rate: rate.Every(time.Second), Burst: 2},
+	{Name: "MD5 database", Hosts: []string{"www.nitrxgen.net"}, Rate: rate.Every(2 * time.Second), Burst: 1},
+	{Name: "Nominatim", Hosts: []string{"nominatim.openstreetmap.org"}, Rate: rate.Every(time.Second), Burst: 1},
+	{Name: "DeepL", Hosts: []string{"api.deepl.com", "api-free.deepl.com"}, Rate: rate.Every(200 * time.Millisecond), Burst: 5},
+	{Name: "Archive.org", Hosts: []string{"web.archive.org", "archive.org"}, Rate: rate.Every(time.Second), Burst: 3},
+}
+
+var (
+	clientsMu sync.Mutex
+	clients   = map[string]*Client{}
+)
+
+// For returns the shared client of a service listed in Services. Unknown
+// names get an unlimited client under that name.
+func For(name string) *Client {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	if client, ok := clients[name]; ok {
+		return client
+	}
+	client := &Client{Name: name}
+	for _, service := range Services {
+		if service.Name == name && service.Rate > 0 {
+			client.limiter = rate.NewLimiter(service.Rate, max(service.Burst, 1))
+		}
+	}
+	clients[name] = client
+	return client
+}
+
+// ForURL returns the client of the service serving target, matched on host
+func ForURL(target string) *Client {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return For("default")
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, service := range Services {
+		for _, serviceHost := range service.Hosts {
+			if host == serviceHost {
+				return For(service.Name)
+			}
+		}
+	}
+	return For(host)
+}
+
+// SetLimit replaces the rate limit of a service listed in Services, matched
+// case-insensitively. A zero limit removes the client-side limit.
+func SetLimit(name string, limit rate.Limit, burst int) error {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	for i, service := range Services {
+		if strings.EqualFold(service.Name, name) {
+			Services[i].Rate, Services[i].Burst = limit, burst
+			delete(clients, service.Name)
+			return nil
+		}
+	}
+	names := make([]string, len(Services))
+	for i, service := range Services {
+		names[i] = service.Name
+	}
+	return fmt.Errorf("unknown service %q, expected one of: %s", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,65 @@
+package providers
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// ScanID identifies the current run. Requests whose context carries no scan
+// ID of its own are traced under it.
+var ScanID string
+
+// TraceHeader names the request header the scan ID is sent in. It is empty by
+// default, since the ID would otherwise reach every site being investigated.
+var TraceHeader string
+
+type scanIDKey struct{}
+
+// NewScanID returns a random RFC 4122 version 4 UUID
+func NewScanID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// WithScanID returns a context whose requests are traced under id, for work
+// that runs as its own scan inside a longer process
+func WithScanID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, scanIDKey{}, id)
+}
+
+// ScanIDFrom returns the scan ID carried by ctx, or ScanID when it has none
+func ScanIDFrom(ctx context.Context) string {
+	if ctx != nil {
+		if id, ok := ctx.Value(scanIDKey{}).(string); ok && id != "" {
+			return id
+		}
+	}
+	return ScanID
+}
+
+// Tracer sends the scan ID of every request in Header before handing it to Base
+type Tracer struct {
+	Base   http.RoundTripper
+	Header string
+}
+
+// RoundTrip sets the trace header on a copy of the request
+func (t *Tracer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := ScanIDFrom(req.Context()); id != "" && req.Header.Get(t.Header) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(t.Header, id)
+	}
+	return t.Base.RoundTrip(req)
+}
+
+// Trace installs a Tracer over the current transport when TraceHeader is set
+func Trace() {
+	if TraceHeader != "" {
+		Use(&Tracer{Base: Transport, Header: TraceHeader})
+	}
+}
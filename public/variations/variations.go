@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
@@ -189,3 +190,120 @@ func SaveVariationsToJSON(originalName string, variations []string) error {
 	// Write to file
 	return os.WriteFile(filename, jsonData, 0644)
 }
+
+// handleSeparators are the characters people swap between handles
+const handleSeparators = "_.-"
+
+// HandleVariants returns near-variants of a confirmed handle, as people reuse
+// almost the same handle across platforms: "johnd_1987" gives "johnd1987",
+// "johnd.1987", "johnd-1987", "johnd_87", "johnd" and so on. The handle
+// itself is not included.
+func HandleVariants(handle string) []string {
+	handle = strings.ToLower(strings.TrimSpace(handle))
+	if handle == "" {
+		return nil
+	}
+
+	// Split into words on separators and on letter/digit boundaries
+	var words []string
+	var current []rune
+	for _, r := range handle {
+		if strings.ContainsRune(handleSeparators, r) {
+			if len(current) > 0 {
+				words = append(words, string(current))
+				current = nil
+			}
+			continue
+		}
+		if len(current) > 0 && isDigit(current[len(current)-1]) != isDigit(r) {
+			words = append(words, string(current))
+			current = nil
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+
+	// Letter-only words, and the words with four-digit years shortened to two
+	var letters, shortYears []string
+	for _, word := range words {
+		if !isDigit(rune(word[0])) {
+			letters = append(letters, word)
+			shortYears = append(shortYears, word)
+		} else if len(word) == 4 && (strings.HasPrefix(word, "19") || strings.HasPrefix(word, "20")) {
+			shortYears = append(shortYears, word[2:])
+		} else {
+			shortYears = append(shortYears, word)
+		}
+	}
+
+	variants := make(map[string]bool)
+	for _, parts := range [][]string{words, letters, shortYears} {
+		if len(parts) == 0 {
+			continue
+		}
+		variants[strings.Join(parts, "")] = true
+		if len(parts) > 1 {
+			for _, sep := range handleSeparators {
+				variants[strings.Join(parts, string(sep))] = true
+			}
+		}
+	}
+	delete(variants, handle)
+
+	// Very short handles are taken on every platform and say nothing
+	result := make([]string, 0, len(variants))
+	for v := range variants {
+		if len(v) >= 3 {
+			result = append(result, v)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return HandleSimilarity(handle, result[i]) > HandleSimilarity(handle, result[j]) ||
+			HandleSimilarity(handle, result[i]) == HandleSimilarity(handle, result[j]) && result[i] < result[j]
+	})
+	return result
+}
+
+// HandleSimilarity scores how alike two handles are from 0 to 1, ignoring
+// case and separators: "johnd_1987" and "JohnD.1987" score 1
+func HandleSimilarity(a, b string) float64 {
+	strip := func(s string) []rune {
+		var out []rune
+		for _, r := range strings.ToLower(s) {
+			if !strings.ContainsRune(handleSeparators, r) {
+				out = append(out, r)
+			}
+		}
+		return out
+	}
+	ra, rb := strip(a), strip(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	// Levenshtein distance over two rows
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
@@ -0,0 +1,139 @@
+// Command datasign regenerates and signs the dataset update manifest.
+//
+// Usage (from the repository root):
+//
+//	go run ./tools/datasign -generate ~/.mercuries-signing/datasets.key
+//	go run ./tools/datasign -key ~/.mercuries-signing/datasets.key -version 2
+//
+// -generate makes a key pair, writing the seed to the file and printing the
+// public key to pin as updatePublicKey in public/assets/datasets/update.go.
+// The key file holds the base64 ed25519 seed. It must never be committed.
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/awion/MercuriesOST/public/assets/datasets"
+)
+
+func main() {
+	keyFlag := flag.String("key", "", "Path to the base64 ed25519 signing seed")
+	versionFlag := flag.Int("version", 0, "Manifest version, must increase with every release")
+	dirFlag := flag.String("dir", "public/assets/datasets", "Dataset package directory")
+	generateFlag := flag.String("generate", "", "Write a new signing seed to this file and print its public key")
+	flag.Parse()
+
+	if *generateFlag != "" {
+		if err := generate(*generateFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *keyFlag == "" || *versionFlag < 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if err := run(*keyFlag, *versionFlag, *dirFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(keyPath string, version int, dir string) error {
+	seedData, err := os.ReadFile(keyPath)
+	if err != nil {
+		return err
+	}
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(seedData)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return fmt.Errorf("invalid signing seed")
+	}
+	key := ed25519.NewKeyFromSeed(seed)
+
+	paths, err := filepath.Glob(filepath.Join(dir, "data", "*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	manifest := datasets.Manifest{Version: version}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var probe interface{}
+		if err := json.Unmarshal(data, &probe); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		sum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, datasets.ManifestFile{
+			Name:   filepath.Base(path),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+	publicKey := base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+	switch err := datasets.VerifyManifest(data, []byte(sig)); {
+	case errors.Is(err, datasets.ErrNoUpdateKey):
+		fmt.Printf("No update key is pinned yet; pin %s as updatePublicKey in update.go\n", publicKey)
+	case err != nil:
+		return fmt.Errorf("signing key %s does not match the pinned public key: %v", publicKey, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), data, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json.sig"), []byte(sig+"\n"), 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("Signed manifest version %d with %d files\n", version, len(manifest.Files))
+	return nil
+}
+
+// generate writes a new signing seed, refusing to replace an existing one,
+// and prints the public key to pin
+func generate(path string) error {
+	publicKey, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(file, base64.StdEncoding.EncodeToString(private.Seed())); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Signing seed written to %s; keep it out of the repository\n", path)
+	fmt.Printf("Public key to pin as updatePublicKey: %s\n", base64.StdEncoding.EncodeToString(publicKey))
+	return nil
+}
@@ -0,0 +1,196 @@
+// Command providermocks generates function-field mocks for the provider
+// interfaces declared in public/osint/providers.go.
+//
+// It runs through go generate from the osint package:
+//
+//	go generate ./public/osint
+//
+// Every interface embedding Provider gets a Mock<Name> struct with one
+// <Method>Func field per method. Unset fields return zero values, with an
+// error when the method returns one, so tests only stub what they exercise.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strings"
+)
+
+// method is an interface method with its parameter and result types rendered
+type method struct {
+	name    string
+	params  []field
+	results []string
+}
+
+type field struct {
+	name, typ string
+}
+
+func main() {
+	inFlag := flag.String("in", "providers.go", "File declaring the provider interfaces")
+	outFlag := flag.String("out", "provider-mocks.go", "Generated file")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, *inFlag, nil, 0)
+	if err != nil {
+		fail(err)
+	}
+
+	interfaces := map[string]*ast.InterfaceType{}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if iface, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+				interfaces[typeSpec.Name.Name] = iface
+			}
+		}
+	}
+	base, ok := interfaces["Provider"]
+	if !ok {
+		fail(fmt.Errorf("%s declares no Provider interface", *inFlag))
+	}
+
+	var names []string
+	for name, iface := range interfaces {
+		if name != "Provider" && embeds(iface, "Provider") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by providermocks from %s. DO NOT EDIT.\n\n", *inFlag)
+	fmt.Fprintf(&buf, "package %s\n\n", file.Name.Name)
+	buf.WriteString("import (\n\t\"context\"\n\t\"fmt\"\n)\n")
+
+	for _, name := range names {
+		methods := append(methodsOf(base), methodsOf(interfaces[name])...)
+		writeMock(&buf, name, methods)
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		fail(fmt.Errorf("format generated code: %v", err))
+	}
+	if err := os.WriteFile(*outFlag, source, 0644); err != nil {
+		fail(err)
+	}
+}
+
+// embeds reports whether an interface embeds the named interface
+func embeds(iface *ast.InterfaceType, name string) bool {
+	for _, f := range iface.Methods.List {
+		if ident, ok := f.Type.(*ast.Ident); ok && len(f.Names) == 0 && ident.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// methodsOf lists the methods an interface declares itself
+func methodsOf(iface *ast.InterfaceType) []method {
+	var methods []method
+	for _, f := range iface.Methods.List {
+		fn, ok := f.Type.(*ast.FuncType)
+		if !ok || len(f.Names) == 0 {
+			continue
+		}
+		m := method{name: f.Names[0].Name}
+		for i, param := range fn.Params.List {
+			typ := types.ExprString(param.Type)
+			if len(param.Names) == 0 {
+				m.params = append(m.params, field{fmt.Sprintf("p%d", i), typ})
+			}
+			for _, n := range param.Names {
+				m.params = append(m.params, field{n.Name, typ})
+			}
+		}
+		if fn.Results != nil {
+			for _, result := range fn.Results.List {
+				for range max(len(result.Names), 1) {
+					m.results = append(m.results, types.ExprString(result.Type))
+				}
+			}
+		}
+		methods = append(methods, m)
+	}
+	return methods
+}
+
+func writeMock(buf *bytes.Buffer, iface string, methods []method) {
+	mock := "Mock" + iface
+
+	fmt.Fprintf(buf, "\n// %s is a %s whose methods are set per test\n", mock, iface)
+	fmt.Fprintf(buf, "type %s struct {\n", mock)
+	for _, m := range methods {
+		fmt.Fprintf(buf, "\t%sFunc func(%s) %s\n", m.name, paramList(m.params), resultList(m.results, false))
+	}
+	buf.WriteString("}\n")
+
+	for _, m := range methods {
+		var args []string
+		for _, p := range m.params {
+			args = append(args, p.name)
+		}
+
+		fmt.Fprintf(buf, "\nfunc (m *%s) %s(%s) %s {\n", mock, m.name, paramList(m.params), resultList(m.results, true))
+		fmt.Fprintf(buf, "\tif m.%sFunc == nil {\n", m.name)
+		switch {
+		case m.name == "Name" && len(m.results) == 1 && m.results[0] == "string":
+			// Health results are cached by name, so unnamed mocks still need one
+			fmt.Fprintf(buf, "\t\treturn %q\n", mock)
+		case len(m.results) > 0 && m.results[len(m.results)-1] == "error":
+			fmt.Fprintf(buf, "\t\tr%d = fmt.Errorf(\"%s.%s not set\")\n\t\treturn\n", len(m.results)-1, mock, m.name)
+		default:
+			buf.WriteString("\t\treturn\n")
+		}
+		buf.WriteString("\t}\n")
+		fmt.Fprintf(buf, "\treturn m.%sFunc(%s)\n}\n", m.name, strings.Join(args, ", "))
+	}
+}
+
+func paramList(params []field) string {
+	var parts []string
+	for _, p := range params {
+		parts = append(parts, p.name+" "+p.typ)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// resultList renders results, naming them r0, r1... when named is set so
+// unset methods can return zero values
+func resultList(results []string, named bool) string {
+	if len(results) == 0 {
+		return ""
+	}
+	var parts []string
+	for i, r := range results {
+		if named {
+			r = fmt.Sprintf("r%d %s", i, r)
+		}
+		parts = append(parts, r)
+	}
+	if len(parts) == 1 && !named {
+		return parts[0]
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "providermocks: %v\n", err)
+	os.Exit(1)
+}